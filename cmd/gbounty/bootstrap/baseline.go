@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"context"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// compareBaseline compares the findings stored into the given [scan.FileSystem]
+// against those from the baseline (see [scan.Config.Baseline]), and returns
+// the [scan.BaselineDiff].
+func compareBaseline(ctx context.Context, cfg scan.Config, fs scan.FileSystem) (scan.BaselineDiff, error) {
+	logger.For(ctx).Infof("Comparing scan findings against baseline: %s", cfg.Baseline)
+
+	baseline, err := writer.ReadBaseline(cfg.Baseline)
+	if err != nil {
+		return scan.BaselineDiff{}, err
+	}
+
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return scan.BaselineDiff{}, err
+	}
+	defer closeIt()
+
+	var current []scan.Match
+	for m := range ch {
+		// Requests and responses aren't needed to compare findings.
+		m.Requests, m.Responses = nil, nil
+		current = append(current, m)
+	}
+
+	diff := scan.DiffBaseline(baseline, current)
+	logger.For(ctx).Infof("Baseline comparison: %d new, %d resolved, %d unchanged finding(s)", len(diff.New), len(diff.Resolved), len(diff.Unchanged))
+
+	return diff, nil
+}
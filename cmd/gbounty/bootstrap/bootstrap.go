@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +34,7 @@ import (
 	"github.com/bountysecurity/gbounty/internal/response"
 	"github.com/bountysecurity/gbounty/kit/blindhost"
 	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/osext"
 	"github.com/bountysecurity/gbounty/kit/panics"
 	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
 	"github.com/bountysecurity/gbounty/kit/ulid"
@@ -40,10 +43,76 @@ import (
 const (
 	debugServerAddr         = "localhost:6060"
 	debugServerShutdownTime = 5 * time.Second
+	defaultGracePeriod      = 15 * time.Second
 )
 
+const (
+	// ExitCodeInterrupted is the exit code used when the execution
+	// has been interrupted (see [ErrInterrupted]), but gracefully.
+	ExitCodeInterrupted = 130
+	// ExitCodeForced is the exit code used when the execution has been
+	// forced to exit, either by a second signal or after the grace period.
+	ExitCodeForced = 137
+	// ExitCodeNewFindings is the exit code used when the execution has
+	// failed because of new findings compared to the baseline (see [ErrNewFindings]).
+	ExitCodeNewFindings = 3
+	// ExitCodeStoppedOnFinding is the exit code used when the scan has been
+	// stopped as soon as the first finding was found (see [ErrStoppedOnFinding]).
+	ExitCodeStoppedOnFinding = 4
+	// ExitCodeTooManyErrors is the exit code used when the scan has been
+	// aborted because too many requests failed (--max-errors).
+	ExitCodeTooManyErrors = 5
+)
+
+// ExitCode returns the exit code the execution must end with, according to
+// the given error returned by [Run] (e.g. [ExitCodeInterrupted]), if any.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrInterrupted):
+		return ExitCodeInterrupted
+	case errors.Is(err, ErrNewFindings):
+		return ExitCodeNewFindings
+	case errors.Is(err, ErrStoppedOnFinding):
+		return ExitCodeStoppedOnFinding
+	case errors.Is(err, ErrTooManyErrors):
+		return ExitCodeTooManyErrors
+	default:
+		return 1
+	}
+}
+
+// ErrInterrupted is the error returned by [Run] when the execution has been
+// interrupted by a signal (e.g. SIGINT), once the output has been flushed.
+var ErrInterrupted = errors.New("scan interrupted manually")
+
+// ErrNewFindings is the error returned by [Run] when the scan has found new
+// findings compared to the baseline (--baseline), and --fail-on-new is set.
+var ErrNewFindings = errors.New("new findings compared to the baseline")
+
+// ErrStoppedOnFinding is the error returned by [Run] when the scan has been stopped as soon
+// as the first finding was found, and --stop-on-first-finding is set, once the output is written.
+var ErrStoppedOnFinding = errors.New("scan stopped on first finding")
+
+// ErrTooManyErrors is the error returned by [Run] when the scan has been aborted because too
+// many requests failed, and --max-errors is set, once the output is written, with the diagnostic.
+var ErrTooManyErrors = errors.New("scan aborted, too many errors")
+
 // Run is the main entrypoint of the `gbounty` command-line interface.
 func Run() error {
+	if len(os.Args) > 1 && os.Args[1] == validateCommand {
+		return runValidate(os.Args[1:])
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == mergeCommand {
+		return runMerge(os.Args[1:])
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == rematchCommand {
+		return runRematch(os.Args[1:])
+	}
+
 	cfg, err := parseCLIArgs()
 	if err != nil || cfg.ShowHelp || cfg.AnyUpdate() {
 		return err
@@ -81,6 +150,25 @@ func Run() error {
 		return nil
 	}
 
+	if len(cfg.Replay) > 0 {
+		logger.For(ctx).Infof("Replaying finding (id=%s) from scan (storage=%s)", cfg.Replay, scanStoragePath(cfg))
+		return runReplay(ctx, cfg, profilesProvider)
+	}
+
+	if cfg.Count {
+		logger.For(ctx).Info("Count (--count) flag is enabled, no requests will be sent...")
+		return runCount(ctx, cfg, profilesProvider)
+	}
+
+	if cfg.PrepareOnly {
+		logger.For(ctx).Info("Prepare only (--prepare-only) flag is enabled, no requests will be sent...")
+		return runPrepareOnly(ctx, cfg)
+	}
+
+	if cfg.ScanTimeout > 0 {
+		ctx = timeoutContext(ctx, cfg.ScanTimeout)
+	}
+
 	updatesChan := make(chan *scan.Stats)
 
 	// We set everything up,
@@ -103,6 +191,10 @@ func Run() error {
 		logger.For(ctx).Debugf("Debug server shutdown error: %s", err.Error())
 	}
 
+	if errors.Is(context.Cause(ctx), ErrInterrupted) {
+		return ErrInterrupted
+	}
+
 	if errors.Is(err, context.Canceled) {
 		return nil
 	}
@@ -143,10 +235,30 @@ func parseCLIArgs() (cli.Config, error) {
 		return cliConfig, nil
 	}
 
+	if len(cliConfig.Replay) > 0 {
+		if err := cliConfig.ValidateReplay(); err != nil {
+			return cli.Config{}, err
+		}
+
+		return cliConfig, nil
+	}
+
 	if err := cliConfig.Validate(); err != nil {
 		return cli.Config{}, err
 	}
 
+	if cliConfig.NoEntrypoints && len(cliConfig.ParamsFile) > 0 {
+		pterm.Warning.Println("The params file (-pf/--params-file) is ignored, as entrypoints are disabled (-noep/--no-entrypoints)")
+	}
+
+	if cliConfig.Passive && len(cliConfig.ParamsFile) > 0 {
+		pterm.Warning.Println("The params file (-pf/--params-file) is ignored, as the scan is passive (--passive-scan)")
+	}
+
+	if len(cliConfig.ResolveAllTo) > 0 {
+		pterm.Warning.Printf("Sinkhole mode (--resolve-all-to) is active: every host is resolved to %s\n", cliConfig.ResolveAllTo)
+	}
+
 	return cliConfig, nil
 }
 
@@ -214,6 +326,22 @@ func runScan(
 
 		actives, passiveReqs, passiveRes := loadProfiles(ctx, cfg, profilesProvider)
 
+		if err := checkSeverityOverrides(cfg, profilesProvider, actives, passiveReqs, passiveRes); err != nil {
+			close(updatesChan)
+			logger.For(ctx).Errorf("Invalid severity overrides: %s", err)
+
+			return err
+		}
+
+		if cfg.StrictPlaceholders {
+			if err := checkPlaceholders(actives, len(cfg.BlindHost) > 0); err != nil {
+				close(updatesChan)
+				logger.For(ctx).Errorf("Invalid placeholders (--strict-placeholders): %s", err)
+
+				return err
+			}
+		}
+
 		id := ulid.New()
 		if len(cfg.Continue) > 0 {
 			id = cfg.Continue
@@ -240,17 +368,7 @@ func runScan(
 			return err
 		}
 
-		var opts []client.Opt
-
-		if len(cfg.ProxyAddress) > 0 {
-			opts = append(opts, client.WithProxyAddr(cfg.ProxyAddress))
-			logger.For(ctx).Debugf("The HTTP client is using a proxy address: %s", cfg.ProxyAddress)
-		}
-
-		if len(cfg.ProxyAuth) > 0 {
-			opts = append(opts, client.WithProxyAuth(cfg.ProxyAuth))
-			logger.For(ctx).Debugf("The HTTP client is using a proxy auth: %s", cfg.ProxyAuth)
-		}
+		opts := clientOptsFromConfig(ctx, cfg)
 
 		maxConcurrentRequests := 1_000
 		if stringVal, defined := os.LookupEnv("GBOUNTY_MAX_CONCURRENT_REQUESTS"); defined {
@@ -261,8 +379,90 @@ func runScan(
 		getClient := client.NewPool(ctx, uint32(maxConcurrentRequests), opts...)
 		newClientFn := func() (scan.Requester, error) { return getClient() }
 
+		// Every request is piped through the request hook, if specified, right before being sent,
+		// so the hook gets the request as decorated (e.g. mutated) and re-run on every retry.
+		// The request hook is already validated, see [cli.Config.Validate].
+		if hook, _ := cfg.Hook(); hook != nil {
+			logger.For(ctx).Warnf("Request hook is enabled, every request is piped through: %s (timeout: %s, on failure: %s)",
+				cfg.RequestHook, hook.Timeout, hook.FailureMode)
+			newClientFn = scan.WithRequestHook(newClientFn, *hook)
+		}
+
+		// Every request sent carries a unique identifier, if specified.
+		if len(cfg.RequestIDHeader) > 0 {
+			gen, err := scan.RequestIDGeneratorFrom(cfg.RequestIDGenerator)
+			if err != nil {
+				close(updatesChan)
+				logger.For(ctx).Errorf("Could not initialize request id generator: %s", err)
+
+				return err
+			}
+
+			logger.For(ctx).Infof("Request id header is set to: %s (%s)", cfg.RequestIDHeader, cfg.RequestIDGenerator)
+			newClientFn = scan.WithRequestID(newClientFn, cfg.RequestIDHeader, gen)
+		}
+
+		// Every request sent carries the attacker-controlled origin, if specified, so CORS
+		// misconfigurations can be detected. The origin is already validated, see [cli.Config.Validate].
+		if len(cfg.CORSOrigin) > 0 {
+			logger.For(ctx).Infof("Origin header is set to: %s", cfg.CORSOrigin)
+			newClientFn = scan.WithCORSOrigin(newClientFn, cfg.CORSOrigin)
+		}
+
+		// The values captured from the responses are set into the following requests to the same host,
+		// including those captured by the login sequence, if any. The rules are already validated, see
+		// [cli.Config.Validate].
+		if rules, _ := cfg.HeaderPropagations(); len(rules) > 0 {
+			logger.For(ctx).Infof("Header propagation is enabled: %s", strings.Join(cfg.HeaderFromResponse, ", "))
+			newClientFn = scan.WithHeaderPropagation(newClientFn, scan.NewHeaderPropagator(rules...))
+		}
+
+		// The requests per second sent to each host are adjusted dynamically, if enabled.
+		if cfg.AdaptiveThrottle {
+			logger.For(ctx).Infof("Adaptive throttle is enabled, target latency: %s, req/s per host: %d-%d",
+				cfg.AdaptiveTargetLatency, cfg.AdaptiveMinRps, cfg.AdaptiveMaxRPS())
+			throttle := scan.NewAdaptiveThrottle(cfg.AdaptiveTargetLatency, cfg.AdaptiveMinRps, cfg.AdaptiveMaxRPS())
+			newClientFn = scan.WithAdaptiveThrottle(newClientFn, throttle)
+		}
+
+		// The requests whose responses are transient (by status) are retried, if enabled, so
+		// each retry goes through the throttle. The status retry is already validated,
+		// see [cli.Config.Validate].
+		if retryCfg, _ := cfg.StatusRetry(); retryCfg.Enabled() {
+			logger.For(ctx).Infof("Retry on status is enabled, status codes: %s, max retries: %d", cfg.RetryOnStatus, retryCfg.MaxRetries)
+			newClientFn = scan.WithStatusRetry(newClientFn, retryCfg)
+		}
+
+		// Every request sent, along with the response got, is stored, if enabled.
+		if cfg.StoreAllResponses {
+			logger.For(ctx).Infof("Store all responses is enabled, max body size: %d bytes", cfg.StoreMaxBodySize)
+			newClientFn = scan.WithExchangeStore(newClientFn, fs, cfg.StoreMaxBodySize)
+		}
+
+		// The requests sent by the scan are mutated, if enabled, but those sent
+		// by the login sequence, which are sent as is, see [login].
+		scanClientFn := newClientFn
+		if mutators, _ := cfg.Mutators(); len(mutators) > 0 {
+			if cfg.JitterHeaders {
+				logger.For(ctx).Info("Jitter headers are enabled")
+			}
+			if len(cfg.RequestMutators) > 0 {
+				logger.For(ctx).Infof("Request mutators are enabled: %s", cfg.RequestMutators)
+			}
+			scanClientFn = scan.WithRequestMutators(newClientFn, mutators)
+		}
+
+		// The requests that produced findings are replayed through the replay proxy, if specified,
+		// once stored. The replay proxy address is already validated, see [cli.Config.Validate].
+		var scanFS scan.FileSystem = fs
+		if addr, _ := cfg.ReplayProxyAddress(); len(addr) > 0 {
+			logger.For(ctx).Infof("Requests that produce findings are replayed through proxy: %s", addr)
+			scanFS = scan.WithMatchesReplay(fs, client.New(replayClientOptsFromConfig(ctx, cfg, addr)...))
+		}
+
 		// Initialize scan configuration from CLI arguments.
 		scanCfg := configFromArgs(cfg)
+		scanCfg.Profiles = profileNames(actives, passiveReqs, passiveRes)
 
 		// Set up modifiers (including blind host interactions).
 		var (
@@ -289,6 +489,10 @@ func runScan(
 		modifiers = modifiersFromConfig(ctx, cfg, modifiers)
 		// End of modifiers section
 
+		// The amount of new findings compared to the baseline, if any,
+		// set once the scan has finished (see finalizeScan).
+		var newFindings int
+
 		runnerOpts := new(scan.RunnerOpts).
 			WithContext(ctx).
 			WithConfiguration(scanCfg).
@@ -298,13 +502,13 @@ func runScan(
 			WithActiveProfiles(actives).
 			WithPassiveReqProfiles(passiveReqs).
 			WithPassiveResProfiles(passiveRes).
-			WithRequesterBuilder(newClientFn).
+			WithRequesterBuilder(scanClientFn).
 			WithOnUpdated(func(stats *scan.Stats) { updatesChan <- stats }).
-			WithOnFinished(finalizeScan(ctx, updatesChan, scanCfg, fs, id)).
+			WithOnFinished(finalizeScan(ctx, updatesChan, scanCfg, fs, id, &newFindings)).
 			WithSaveAllRequests(cfg.ShowAll || cfg.ShowAllRequests).
 			WithSaveResponses(cfg.ShowResponses).
 			WithSaveAllResponses(cfg.ShowAll || cfg.ShowAllResponses).
-			WithFileSystem(fs)
+			WithFileSystem(scanFS)
 
 		w := writer.NewConsole(os.Stdout)
 
@@ -314,12 +518,12 @@ func runScan(
 			runnerOpts.WithOnError(func(ctx context.Context, url string, reqs []*request.Request, res []*response.Response, err error) {
 				if writeErr := w.WriteError(
 					ctx,
-					scan.Error{
+					scanCfg.Redaction.Error(scan.Error{
 						URL:       url,
 						Requests:  reqs,
 						Responses: res,
 						Err:       err.Error(),
-					},
+					}),
 				); writeErr != nil {
 					logger.For(ctx).Errorf("Error while streaming scan error: %s", writeErr.Error())
 				}
@@ -347,40 +551,106 @@ func runScan(
 					param = ep.Param(payload)
 				}
 
-				if err = w.WriteMatch(
-					ctx,
-					scan.Match{
-						URL:                   url,
-						Requests:              reqs,
-						Responses:             res,
-						ProfileName:           prof.GetName(),
-						ProfileTags:           prof.GetTags(),
-						IssueName:             issue.GetIssueName(),
-						IssueSeverity:         issue.GetIssueSeverity(),
-						IssueConfidence:       issue.GetIssueConfidence(),
-						IssueDetail:           issue.GetIssueDetail(),
-						IssueBackground:       issue.GetIssueBackground(),
-						RemediationDetail:     issue.GetRemediationDetail(),
-						RemediationBackground: issue.GetRemediationBackground(),
-						IssueParam:            param,
-						Payload:               payload,
-						Occurrences:           occ,
-						ProfileType:           prof.GetType().String(),
-						At:                    time.Now().UTC(),
-					}, cfg.ShowResponses,
-				); err != nil {
+				match := scan.Match{
+					URL:                   url,
+					Requests:              reqs,
+					Responses:             res,
+					ProfileName:           prof.GetName(),
+					ProfileTags:           prof.GetTags(),
+					IssueName:             issue.GetIssueName(),
+					IssueSeverity:         scanCfg.SeverityOverrides.Severity(prof.GetName(), issue.GetIssueSeverity()),
+					IssueConfidence:       issue.GetIssueConfidence(),
+					IssueDetail:           issue.GetIssueDetail(),
+					IssueBackground:       issue.GetIssueBackground(),
+					RemediationDetail:     issue.GetRemediationDetail(),
+					RemediationBackground: issue.GetRemediationBackground(),
+					IssueParam:            param,
+					Payload:               payload,
+					Occurrences:           occ,
+					ProfileType:           prof.GetType().String(),
+					Metadata:              scan.MatchMetadata(ctx, scanCfg.Metadata, prof, reqs, res, payload),
+					Origin:                scan.MatchOriginOf(ctx, ep),
+					ResponseDiff:          scan.ResponseDiffOf(ctx),
+					At:                    time.Now().UTC(),
+				}
+				match.ID = scan.MatchID(match)
+
+				if len(scanCfg.RequestIDHeader) > 0 {
+					match.RequestIDs = scan.RequestIDs(reqs, scanCfg.RequestIDHeader)
+				}
+
+				if err = w.WriteMatch(ctx, scanCfg.Redaction.Match(match), cfg.ShowResponses); err != nil {
 					logger.For(ctx).Errorf("Error while streaming scan match: %s", err.Error())
 				}
 			})
 		}
 
+		if cfg.StopOnFirstFinding.Enabled {
+			// The severity is already validated, see [cli.Config.Validate].
+			severity, _ := cfg.StopOnFirstFindingSeverity()
+			logger.For(ctx).Infof("Scan is stopped on first finding, with severity: %q (or higher)", severity)
+			runnerOpts.WithStopOnMatch(func(m scan.Match) bool { return scan.SeverityAtLeast(m.IssueSeverity, severity) })
+		}
+
+		// The error threshold is already validated, see [cli.Config.Validate].
+		if threshold, _ := cfg.ErrorThreshold(); threshold.Enabled() {
+			logger.For(ctx).Infof("Scan is aborted on too many errors: %s", threshold)
+			runnerOpts.WithErrorThreshold(threshold)
+		}
+
+		if len(cfg.Continue) > 0 && len(cfg.LoginSequenceFile) > 0 {
+			logger.For(ctx).Warn("Login sequence (--login-sequence) ignored: scan templates are continued as stored")
+		}
+
+		if len(cfg.Continue) > 0 && len(cfg.SeenHostsFile) > 0 {
+			logger.For(ctx).Warn("Seen hosts file (--seen-hosts-file) ignored: scan templates are continued as stored")
+		}
+
+		var seen *cli.SeenHosts
 		if len(cfg.Continue) == 0 {
-			err = cli.PrepareTemplates(ctx, fs, cfg)
+			session, err := login(ctx, cfg, newClientFn)
+			if err != nil {
+				logger.For(ctx).Errorf("Error while performing login sequence: %s", err.Error())
+				close(updatesChan)
+				return err
+			}
+
+			seen, err = cfg.SeenHosts()
+			if err != nil {
+				logger.For(ctx).Errorf("Error while reading seen hosts file: %s", err.Error())
+				close(updatesChan)
+				return err
+			}
+
+			dropped, err := prepareTemplates(ctx, fs, cfg, session, seen, runnerOpts)
 			if err != nil {
 				logger.For(ctx).Errorf("Error while preparing scan templates: %s", err.Error())
 				close(updatesChan)
 				return err
 			}
+
+			runnerOpts.WithDroppedInputs(dropped.ByURLMatch, dropped.ByURLReject, dropped.ByExtension)
+			if dropped.ByExtension > 0 {
+				pterm.Info.Printf("Inputs excluded by extension (--exclude-extensions): %d\n", dropped.ByExtension)
+			}
+
+			runnerOpts.WithSkippedTemplates(dropped.BySkipIf)
+			if dropped.BySkipIf > 0 {
+				pterm.Info.Printf("Templates skipped by predicate (--skip-if): %d\n", dropped.BySkipIf)
+			}
+
+			runnerOpts.WithSkippedHosts(seen.Skipped())
+			if seen.Skipped() > 0 {
+				pterm.Info.Printf("Hosts skipped as already seen (--only-new-hosts): %d\n", seen.Skipped())
+			}
+
+			if len(cfg.SaveTemplateBundle) > 0 {
+				if err := saveTemplateBundle(ctx, fs, cfg.SaveTemplateBundle); err != nil {
+					logger.For(ctx).Errorf("Error while saving scan templates bundle: %s", err.Error())
+					close(updatesChan)
+					return err
+				}
+			}
 		}
 
 		if err := writeConfig(ctx, w, scanCfg); err != nil {
@@ -390,8 +660,173 @@ func runScan(
 			return err
 		}
 
-		return scan.NewRunner(runnerOpts).Start()
+		err = scan.NewRunner(runnerOpts).Start()
+
+		// The hosts are only recorded once scanned, so those of an aborted scan are scanned again.
+		if err == nil || errors.Is(err, scan.ErrStoppedOnMatch) {
+			recordSeenHosts(ctx, seen)
+		}
+
+		if errors.Is(err, scan.ErrStoppedOnMatch) {
+			logger.For(ctx).Infof("Scan stopped (--stop-on-first-finding): %s", err.Error())
+			return ErrStoppedOnFinding
+		}
+
+		var tooManyErrs *scan.TooManyErrorsError
+		if errors.As(err, &tooManyErrs) {
+			logger.For(ctx).Infof("Scan aborted (--max-errors): %s", tooManyErrs.Error())
+			return fmt.Errorf("%w: %s", ErrTooManyErrors, tooManyErrs.Error())
+		}
+
+		if err == nil && cfg.FailOnNew && newFindings > 0 {
+			return fmt.Errorf("%w: %d", ErrNewFindings, newFindings)
+		}
+
+		return err
+	}
+}
+
+// login performs the login sequence (see [cli.Config.LoginSequence]), if any, through
+// the [scan.Requester] built with the given builder, and returns the resulting session.
+func login(ctx context.Context, cfg cli.Config, fn scan.RequesterBuilder) (*scan.LoginSession, error) {
+	seq, err := cfg.LoginSequence()
+	if err != nil || seq == nil {
+		return nil, err
+	}
+
+	// Variables are already validated, see [cli.Config.Validate].
+	vars, _ := cfg.Variables()
+
+	session, err := seq.Run(ctx, fn, vars)
+	if err != nil {
+		return nil, err
 	}
+
+	pterm.Info.Printf("Login sequence performed (--login-sequence): %d step(s), %d value(s) extracted\n", len(seq.Steps), len(session.Variables))
+
+	return session, nil
+}
+
+// prepareTemplates prepares the scan templates (see [cli.PrepareTemplates]), unless these are streamed
+// (see [cli.Config.Stream]), in which case these are scanned as these arrive (see [cli.StreamTemplates]),
+// so no inputs are reported as dropped upfront.
+func prepareTemplates(ctx context.Context, fs scan.FileSystem, cfg cli.Config, session *scan.LoginSession, seen *cli.SeenHosts, runnerOpts *scan.RunnerOpts) (cli.InputsDropped, error) {
+	if !cfg.Stream {
+		return cli.PrepareTemplates(ctx, fs, cfg, session, seen)
+	}
+
+	logger.For(ctx).Info("Scan templates are streamed from the standard input (--stream)")
+	runnerOpts.WithTemplatesStream(cli.StreamTemplates(ctx, fs, cfg, session, seen))
+
+	return cli.InputsDropped{}, nil
+}
+
+// recordSeenHosts records the hosts scanned into the seen hosts file (see [cli.SeenHosts.Record]), if any.
+// Failing to do so doesn't fail the scan, as its results are already there.
+func recordSeenHosts(ctx context.Context, seen *cli.SeenHosts) {
+	if seen == nil {
+		return
+	}
+
+	n, err := seen.Record(ctx)
+	if err != nil {
+		logger.For(ctx).Errorf("Error while recording seen hosts: %s", err.Error())
+		pterm.Warning.Printf("Seen hosts could not be recorded (--seen-hosts-file): %s\n", err.Error())
+		return
+	}
+
+	logger.For(ctx).Infof("Seen hosts recorded (--seen-hosts-file): %d new host(s)", n)
+}
+
+func clientOptsFromConfig(ctx context.Context, cfg cli.Config) []client.Opt {
+	var opts []client.Opt
+
+	if len(cfg.ProxyAddress) > 0 {
+		opts = append(opts, client.WithProxyAddr(cfg.ProxyAddress))
+		logger.For(ctx).Debugf("The HTTP client is using a proxy address: %s", cfg.ProxyAddress)
+	}
+
+	if len(cfg.ProxyAuth) > 0 {
+		opts = append(opts, client.WithProxyAuth(cfg.ProxyAuth))
+		logger.For(ctx).Debugf("The HTTP client is using a proxy auth: %s", cfg.ProxyAuth)
+	}
+
+	if len(cfg.UnixSocket) > 0 {
+		opts = append(opts, client.WithUnixSocket(cfg.UnixSocket))
+		logger.For(ctx).Debugf("The HTTP client is using a unix socket: %s", cfg.UnixSocket)
+	}
+
+	// The dns cache is enabled with warmup too, as that's what it primes.
+	if ttl, pin := cfg.DNSCache(); ttl > 0 || pin {
+		opts = append(opts, client.WithDNSCache(client.NewDNSCache(ttl, pin, nil)))
+		logger.For(ctx).Debugf("The HTTP client is caching resolved addresses (ttl: %s, pinned: %t)", ttl, pin)
+	}
+
+	if len(cfg.ResolveAllTo) > 0 {
+		opts = append(opts, client.WithResolveAllTo(cfg.ResolveAllTo))
+		logger.For(ctx).Debugf("The HTTP client is resolving every host to: %s (sinkhole)", cfg.ResolveAllTo)
+	}
+
+	if rootCAs, _ := cfg.RootCAs(); rootCAs != nil {
+		opts = append(opts, client.WithRootCAs(rootCAs))
+		logger.For(ctx).Debugf("The HTTP client is verifying certificates against the CA bundle(s): %s (dir: %s)", strings.Join(cfg.CABundle, ", "), cfg.CABundleDir)
+	}
+
+	if creds, _ := cfg.NTLMCredentials(); creds != nil {
+		opts = append(opts, client.WithNTLM(*creds))
+		logger.For(ctx).Debugf("The HTTP client is authenticating through NTLM as: %s\\%s", creds.Domain, creds.User)
+	}
+
+	if signer, _ := cfg.SigV4Signer(); signer != nil {
+		opts = append(opts, client.WithSigV4(*signer))
+		logger.For(ctx).Debugf("The HTTP client is signing requests with AWS SigV4 (%s:%s) as: %s", signer.Region, signer.Service, signer.Credentials.AccessKeyID)
+	}
+
+	if cfg.HTTP2 || cfg.HTTP2PriorKnowledge {
+		opts = append(opts, client.WithHTTP2(cfg.HTTP2PriorKnowledge))
+		logger.For(ctx).Debugf("The HTTP client is sending requests over HTTP/2 (prior knowledge: %t)", cfg.HTTP2PriorKnowledge)
+	}
+
+	if cfg.AllowRawHeaders {
+		opts = append(opts, client.WithRawHeaders())
+		logger.For(ctx).Debug("The HTTP client is sending raw (ambiguous) framing headers verbatim")
+	}
+
+	if cfg.MaxDecompressedSize > 0 {
+		opts = append(opts, client.WithMaxDecompressedSize(int64(cfg.MaxDecompressedSize)))
+		logger.For(ctx).Debugf("The HTTP client is truncating response bodies decompressed beyond: %d bytes", cfg.MaxDecompressedSize)
+	}
+
+	if headerOrder, _ := cfg.HeaderOrderKeys(); len(headerOrder) > 0 {
+		opts = append(opts, client.WithHeaderOrder(headerOrder))
+		logger.For(ctx).Debugf("The HTTP client is sending headers in the order: %s", strings.Join(headerOrder, ", "))
+	}
+
+	return opts
+}
+
+// replayClientOptsFromConfig returns the options of the HTTP client used to replay the requests
+// that produced findings through the given proxy address (see [cli.Config.ReplayProxy]).
+// Only those options that affect how requests are written are kept, so these are replayed as
+// sent, while the connection ones (e.g. --unix-socket or --ca-bundle) are left to the proxy.
+func replayClientOptsFromConfig(ctx context.Context, cfg cli.Config, addr string) []client.Opt {
+	opts := []client.Opt{client.WithProxyAddr(addr)}
+	logger.For(ctx).Debugf("The replay HTTP client is using a proxy address: %s", addr)
+
+	if cfg.AllowRawHeaders {
+		opts = append(opts, client.WithRawHeaders())
+	}
+
+	if headerOrder, _ := cfg.HeaderOrderKeys(); len(headerOrder) > 0 {
+		opts = append(opts, client.WithHeaderOrder(headerOrder))
+	}
+
+	// Replayed requests are signed again, as the former signatures expire.
+	if signer, _ := cfg.SigV4Signer(); signer != nil {
+		opts = append(opts, client.WithSigV4(*signer))
+	}
+
+	return opts
 }
 
 func modifiersFromConfig(ctx context.Context, cfg cli.Config, given []scan.Modifier) []scan.Modifier {
@@ -403,6 +838,11 @@ func modifiersFromConfig(ctx context.Context, cfg cli.Config, given []scan.Modif
 		logger.For(ctx).Infof("Email address request modifier is set to: %s", cfg.EmailAddress)
 	}
 
+	if len(cfg.JWTSignKey) > 0 {
+		modifiers = append(modifiers, modifier.NewJWTSigner(cfg.JWTSignKey))
+		logger.For(ctx).Info("JWT signer request modifier is set")
+	}
+
 	if len(cfg.CustomTokens) > 0 {
 		modifiers = append(modifiers, modifier.NewCustomTokens(cfg.CustomTokens))
 		logger.For(ctx).Infof("Custom tokens configured: %v", cfg.CustomTokens)
@@ -411,30 +851,144 @@ func modifiersFromConfig(ctx context.Context, cfg cli.Config, given []scan.Modif
 	return modifiers
 }
 
+// gracefulContext returns a context that is cancelled on the first received signal
+// (see listenFor), so the scan stops dispatching requests, and the output is flushed.
+//
+// Once cancelled, the execution has a bounded grace period (see gracePeriod) to finish,
+// otherwise it is forced to exit (see ExitCodeForced), as it is on a second signal.
 func gracefulContext(ctx context.Context) context.Context {
-	done := make(chan os.Signal, 1)
+	done := make(chan os.Signal, 2) //nolint:mnd
 
 	signal.Notify(done, listenFor()...)
 
+	return gracefulContextWith(ctx, done, gracePeriod(), os.Exit)
+}
+
+// gracefulContextWith is like gracefulContext, but with the signals received through
+// the given channel, and the given grace period and function to force the exit with.
+func gracefulContextWith(ctx context.Context, done <-chan os.Signal, grace time.Duration, exit func(int)) context.Context {
 	ctx, cancel := context.WithCancelCause(ctx)
 
 	go func() {
 		sign := <-done
+
 		logger.For(ctx).Infof("Scan interrupted manually, signal: %s", sign.String())
-		cancel(fmt.Errorf("scan interrupted manually, signal: %s", sign.String())) //nolint:goerr113
+		pterm.Warning.Printf("Scan interrupted, waiting up to %s for the output to be flushed... (interrupt again to force exit)\n", grace.String())
+		cancel(fmt.Errorf("%w, signal: %s", ErrInterrupted, sign.String()))
+
+		select {
+		case sign = <-done:
+			logger.For(ctx).Infof("Forced exit, signal: %s", sign.String())
+			pterm.Error.WithShowLineNumber(false).Println("Forced exit, the output might be incomplete")
+		case <-time.After(grace):
+			logger.For(ctx).Infof("Forced exit, grace period (%s) exceeded", grace.String())
+			pterm.Error.WithShowLineNumber(false).Printf("Forced exit, grace period (%s) exceeded, the output might be incomplete\n", grace.String())
+		}
+
+		exit(ExitCodeForced)
 	}()
 
 	return ctx
 }
 
+// gracePeriod returns the maximum duration the execution is given to finish
+// once interrupted, which can be customized with the GBOUNTY_SHUTDOWN_GRACE_PERIOD
+// environment variable (e.g. 30s).
+func gracePeriod() time.Duration {
+	if stringVal, defined := os.LookupEnv("GBOUNTY_SHUTDOWN_GRACE_PERIOD"); defined {
+		if d, err := time.ParseDuration(stringVal); err == nil && d > 0 {
+			return d
+		}
+	}
+
+	return defaultGracePeriod
+}
+
+// timeoutContext returns a context that is cancelled once the given timeout is reached,
+// so the scan is stopped the same way as when it is interrupted manually (see gracefulContext).
+func timeoutContext(ctx context.Context, timeout time.Duration) context.Context {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	time.AfterFunc(timeout, func() {
+		logger.For(ctx).Infof("Scan timeout reached: %s", timeout.String())
+		pterm.Warning.Printf("Scan timeout (%s) reached, stopping the scan...\n", timeout.String())
+		cancel(fmt.Errorf("scan timeout reached: %s", timeout.String())) //nolint:goerr113
+	})
+
+	return ctx
+}
+
 func configFromArgs(cfg cli.Config) scan.Config {
+	// Metadata pairs are already validated, see [cli.Config.Validate].
+	metadata, _ := cfg.MetadataPairs()
+	// Same for the mime filter, see [cli.Config.Validate].
+	mimeFilter, _ := cfg.MimeFilter()
+	responseFilter, _ := cfg.ResponseFilter()
+	// Same for the shard, see [cli.Config.Validate].
+	shard, _ := cfg.ScanShard()
+	// Same for the template filter, see [cli.Config.Validate].
+	templateFilter, _ := cfg.ScanTemplateFilter()
+	// Same for the sensitive data (file, cloud, debug and technology) signatures, see [cli.Config.Validate].
+	signatures, _ := cfg.Signatures()
+	fileSignatures, _ := cfg.FileSignatures()
+	cloudSignatures, _ := cfg.CloudSignatures()
+	debugSignatures, _ := cfg.DebugSignatures()
+	techSignatures, _ := cfg.TechnologySignatures()
+	// Same for the redaction, see [cli.Config.Validate].
+	redaction, _ := cfg.Redaction()
+	// Same for the severity overrides, see [cli.Config.Validate].
+	severityOverrides, _ := cfg.SeverityOverrides()
+	// Same for the findings grouping, see [cli.Config.Validate].
+	grouping, _ := cfg.FindingsGrouping()
+	// Same for the response diff, see [cli.Config.Validate].
+	responseDiff, _ := cfg.ResponseDiff()
+	// Same for the rate limit probe, see [cli.Config.Validate].
+	rateLimit, _ := cfg.RateLimit()
+	// Same for the mass assignment probe, see [cli.Config.Validate].
+	massAssignment, _ := cfg.MassAssignmentProbe()
+	// Same for the cache poisoning probe, see [cli.Config.Validate].
+	cachePoisoning, _ := cfg.CachePoisoningProbe()
+
 	return scan.Config{
-		RPS:          cfg.Rps,
-		Concurrency:  cfg.Concurrency,
-		Version:      gbounty.Version,
-		SaveOnStop:   cfg.SaveOnStop,
-		InMemory:     cfg.InMemory,
-		EmailAddress: len(cfg.EmailAddress) > 0,
+		RPS:                cfg.Rps,
+		Concurrency:        cfg.Concurrency,
+		ConcurrencyPerHost: cfg.ConcurrencyPerHost,
+		TimeBudgetPerHost:  cfg.TimeBudgetPerHost,
+		Warmup:             cfg.Warmup,
+		Version:            gbounty.Version,
+		SaveOnStop:         cfg.SaveOnStop,
+		InMemory:           cfg.InMemory,
+		KeepStorage:        cfg.KeepStorage || cfg.StoreAllResponses,
+		StoreAllResponses:  cfg.StoreAllResponses,
+		NoEntrypoints:      cfg.NoEntrypoints || cfg.Discover || cfg.Passive, // Discovery and passive requests are sent as is.
+		Passive:            cfg.Passive,
+		EmailAddress:       len(cfg.EmailAddress) > 0,
+		Metadata:           metadata,
+		MimeFilter:         mimeFilter,
+		ResponseFilter:     responseFilter,
+		Shard:              shard,
+		Prioritized:        cfg.Prioritized(),
+		TemplateFilter:     templateFilter,
+		Redirects: scan.RedirectPolicy{
+			SendReferer:          cfg.SendReferer,
+			KeepSensitiveHeaders: cfg.KeepAuthOnRedirect,
+		},
+		MatchTimeout:      cfg.ProfileTimeout,
+		Signatures:        signatures,
+		FileSignatures:    fileSignatures,
+		CloudSignatures:   cloudSignatures,
+		DebugSignatures:   debugSignatures,
+		TechSignatures:    techSignatures,
+		SeverityOverrides: severityOverrides,
+		RequestIDHeader:   cfg.RequestIDHeader,
+		CanaryPrefix:      cfg.CanaryPrefix,
+		ResponseDiff:      responseDiff,
+		RateLimit:         rateLimit,
+		MassAssignment:    massAssignment,
+		ResolveAllTo:      cfg.ResolveAllTo,
+
+		GraphQLIntrospection: cfg.GraphQLIntrospection,
+		CachePoisoning:       cachePoisoning,
 
 		Silent:           cfg.Silent,
 		StreamErrors:     cfg.StreamErrors,
@@ -444,9 +998,89 @@ func configFromArgs(cfg cli.Config) scan.Config {
 		ShowAll:          cfg.ShowAll,
 		ShowAllRequests:  cfg.ShowAllRequests,
 		ShowAllResponses: cfg.ShowAllResponses,
-		OutPath:          cfg.OutPath,
-		OutFormat:        cfg.OutFormat,
+		Outputs:          cfg.Outputs(),
+		OutTemplate:      cfg.OutTemplate,
+		OutAppend:        cfg.OutAppend,
+		Baseline:         cfg.Baseline,
+		Redaction:        redaction,
+		Grouping:         grouping,
+	}
+}
+
+// checkSeverityOverrides returns an error if the severity of any unknown profile is overridden
+// (see [cli.Config.SeverityOverrides]), where known profiles are those available from the given
+// provider, even if filtered out (e.g. by tags), plus those loaded (e.g. the built-in ones).
+func checkSeverityOverrides(
+	cfg cli.Config,
+	provider profile.Provider,
+	actives []*profile.Active,
+	passiveReqs []*profile.Request,
+	passiveRes []*profile.Response,
+) error {
+	// The severity overrides are already validated, see [cli.Config.Validate].
+	overrides, _ := cfg.SeverityOverrides()
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, a := range append(provider.Actives(), actives...) {
+		names = append(names, a.GetName())
+	}
+	for _, r := range append(provider.PassiveReqs(), passiveReqs...) {
+		names = append(names, r.GetName())
+	}
+	for _, r := range append(provider.PassiveRes(), passiveRes...) {
+		names = append(names, r.GetName())
+	}
+
+	return overrides.Check(names)
+}
+
+// profileNames returns the (distinct) names of the given profiles, in order.
+func profileNames(actives []*profile.Active, passiveReqs []*profile.Request, passiveRes []*profile.Response) []string {
+	names := make([]string, 0, len(actives)+len(passiveReqs)+len(passiveRes))
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, a := range actives {
+		add(a.GetName())
 	}
+	for _, r := range passiveReqs {
+		add(r.GetName())
+	}
+	for _, r := range passiveRes {
+		add(r.GetName())
+	}
+
+	return names
+}
+
+var errUnknownPlaceholders = errors.New("unknown placeholders")
+
+// checkPlaceholders returns an error if any of the given active profiles contains unknown
+// placeholders (see [modifier.UnknownPlaceholders]) within its (enabled) payloads, raw requests or
+// custom headers. The {{callback}} placeholder is only known with a blind host.
+func checkPlaceholders(actives []*profile.Active, withCallback bool) error {
+	for _, a := range actives {
+		for _, step := range a.Steps {
+			sources := append([]string{step.RawRequest}, step.CustomHeaders...)
+			for idx := range step.Payloads {
+				if enabled, payload, _ := step.PayloadAt(idx); enabled {
+					sources = append(sources, payload)
+				}
+			}
+
+			if unknown := modifier.UnknownPlaceholders(strings.Join(sources, "\n"), withCallback); len(unknown) > 0 {
+				return fmt.Errorf("%w: %s (profile: %s)", errUnknownPlaceholders, strings.Join(unknown, ", "), a.GetName())
+			}
+		}
+	}
+
+	return nil
 }
 
 func loadProfiles(
@@ -454,13 +1088,24 @@ func loadProfiles(
 	cfg cli.Config,
 	provider profile.Provider,
 ) ([]*profile.Active, []*profile.Request, []*profile.Response) {
+	if cfg.Discover {
+		// The discovery profile is already validated, see [cli.Config.Validate].
+		discovery, _ := cfg.DiscoveryProfile()
+
+		logger.For(ctx).Infof("Content discovery is enabled, with wordlist: %s", cfg.Wordlist)
+		pterm.Info.Printf("Content discovery enabled, reading words from: %s\n", cfg.Wordlist)
+
+		return nil, nil, withWebSocketProfile(ctx, cfg, withFingerprintProfile(ctx, cfg, withSensitiveDataProfile(ctx, cfg, withDebugExposureProfile(ctx, cfg, withExposureProfile(ctx, cfg, []*profile.Response{discovery})))))
+	}
+
 	var (
 		actives     []*profile.Active
 		passiveReqs []*profile.Request
 		passiveRes  []*profile.Response
 	)
 
-	if cfg.OnlyActive || cfg.ScanAllProfiles() {
+	// Passive scans never inject, so active profiles are never used.
+	if !cfg.Passive && (cfg.OnlyActive || cfg.ScanAllProfiles()) {
 		actives = provider.ActivesEnabled()
 	}
 
@@ -479,7 +1124,7 @@ func loadProfiles(
 	loadingFrom := provider.From()
 	if len(loadingFrom) == 1 {
 		pterm.Info.Printf("Loading profiles from: %s\n", loadingFrom[0])
-	} else {
+	} else if len(loadingFrom) > 1 {
 		pterm.Info.Printf(
 			`Loading profiles from... 
 	- %s
@@ -508,7 +1153,113 @@ func loadProfiles(
 		)
 	}
 
-	return actives, passiveReqs, passiveRes
+	if cfg.Passive {
+		// The curated passive profiles are static, so they are always valid.
+		curated, _ := cli.PassiveProfiles()
+		passiveRes = append(passiveRes, curated...)
+
+		logger.For(ctx).Infof("Passive scan is enabled, with %d curated passive response profile(s)", len(curated))
+		pterm.Info.Printf("Passive scan enabled, no payloads will be injected, curated passive profile(s): %d\n", len(curated))
+
+		passiveRes = withDebugExposureProfile(ctx, cfg, withExposureProfile(ctx, cfg, passiveRes))
+	}
+
+	// With no --rate-limit-match, the burst is only sent if any active profile asks for it.
+	if cfg.RateLimitBurst > 0 && len(strings.TrimSpace(cfg.RateLimitMatch)) == 0 && !scan.RateLimitRequested(actives) {
+		logger.For(ctx).Warnf("Rate limit burst (--rate-limit-burst) won't be sent: no --rate-limit-match, nor active profile tagged: %s", scan.RateLimitTag)
+		pterm.Warning.Printfln("No rate limit burst will be sent, use --rate-limit-match, or active profiles tagged: %s", scan.RateLimitTag)
+	}
+
+	return withMutatedProfiles(ctx, cfg, actives), passiveReqs, withWebSocketProfile(ctx, cfg, withFingerprintProfile(ctx, cfg, withSensitiveDataProfile(ctx, cfg, passiveRes)))
+}
+
+// withMutatedProfiles returns the given profiles plus those derived from them with
+// their payloads mutated (see [scan.PayloadMutationsCfg.Apply]), if any mutation is defined.
+func withMutatedProfiles(ctx context.Context, cfg cli.Config, profiles []*profile.Active) []*profile.Active {
+	// The payload mutations are already validated, see [cli.Config.Validate].
+	mutations, _ := cfg.PayloadMutations()
+	if !mutations.Enabled() {
+		return profiles
+	}
+
+	mutated := mutations.Apply(profiles)
+
+	logger.For(ctx).Infof("Payload mutations are enabled: %s (depth: %d, seed: %d), mutated profile(s): %d", cfg.Mutations, mutations.Depth, mutations.Seed, len(mutated)-len(profiles))
+	pterm.Info.Printf("Payload mutations enabled (%s), mutated profile(s): %d\n", cfg.Mutations, len(mutated)-len(profiles))
+
+	return mutated
+}
+
+// withExposureProfile returns the given profiles plus the exposure profile
+// (see [cli.Config.ExposureProfile]), if enabled (--exposures).
+func withExposureProfile(ctx context.Context, cfg cli.Config, profiles []*profile.Response) []*profile.Response {
+	// The exposure profile is already validated, see [cli.Config.Validate].
+	exposure, _ := cfg.ExposureProfile()
+	if exposure == nil {
+		logger.For(ctx).Info("Exposed content is not looked for (see --exposures)")
+		return profiles
+	}
+
+	logger.For(ctx).Infof("Exposed content is looked for: %s", exposure.Greps[0])
+
+	return append(profiles, exposure)
+}
+
+// withDebugExposureProfile returns the given profiles plus the debug exposure profile
+// (see [cli.Config.DebugExposureProfile]), unless disabled (--debug-exposures none).
+func withDebugExposureProfile(ctx context.Context, cfg cli.Config, profiles []*profile.Response) []*profile.Response {
+	// The debug exposure profile is already validated, see [cli.Config.Validate].
+	debug, _ := cfg.DebugExposureProfile()
+	if debug == nil {
+		logger.For(ctx).Info("Debug surfaces are not looked for (--debug-exposures none)")
+		return profiles
+	}
+
+	logger.For(ctx).Infof("Debug surfaces are looked for: %s", debug.Greps[0])
+
+	return append(profiles, debug)
+}
+
+// withSensitiveDataProfile returns the given profiles plus the sensitive data
+// profile (see [cli.Config.SensitiveDataProfile]), if enabled (--sensitive-data).
+func withSensitiveDataProfile(ctx context.Context, cfg cli.Config, profiles []*profile.Response) []*profile.Response {
+	// The sensitive data profile is already validated, see [cli.Config.Validate].
+	sensitive, _ := cfg.SensitiveDataProfile()
+	if sensitive == nil {
+		return profiles
+	}
+
+	logger.For(ctx).Infof("Sensitive data is looked for: %s", sensitive.Greps[0])
+
+	return append(profiles, sensitive)
+}
+
+// withFingerprintProfile returns the given profiles plus the technology fingerprint
+// profile (see [cli.Config.FingerprintProfile]), if enabled (--fingerprint).
+func withFingerprintProfile(ctx context.Context, cfg cli.Config, profiles []*profile.Response) []*profile.Response {
+	// The fingerprint profile is static, so it is always valid.
+	fingerprint, _ := cfg.FingerprintProfile()
+	if fingerprint == nil {
+		return profiles
+	}
+
+	logger.For(ctx).Info("Technology stack is fingerprinted")
+
+	return append(profiles, fingerprint)
+}
+
+// withWebSocketProfile returns the given profiles plus the WebSocket upgrade
+// profile (see [cli.Config.WebSocketProfile]), if enabled (--websocket).
+func withWebSocketProfile(ctx context.Context, cfg cli.Config, profiles []*profile.Response) []*profile.Response {
+	// The WebSocket profile is static, so it is always valid.
+	websocket, _ := cfg.WebSocketProfile()
+	if websocket == nil {
+		return profiles
+	}
+
+	logger.For(ctx).Info("WebSocket upgrades are looked for")
+
+	return append(profiles, websocket)
 }
 
 func filter[P profile.Profile](ctx context.Context, profiles []P, tags []string) []P {
@@ -539,7 +1290,7 @@ func shouldBeIncluded[P profile.Profile](prof P, tags []string) bool {
 	return false
 }
 
-func finalizeScan(ctx context.Context, updatesChan chan *scan.Stats, cfg scan.Config, fs scan.FileSystem, id string) func(*scan.Stats, error) {
+func finalizeScan(ctx context.Context, updatesChan chan *scan.Stats, cfg scan.Config, fs scan.FileSystem, id string, newFindings *int) func(*scan.Stats, error) {
 	return func(stats *scan.Stats, err error) {
 		logger.For(ctx).Info("Finalizing scan...")
 
@@ -551,6 +1302,15 @@ func finalizeScan(ctx context.Context, updatesChan chan *scan.Stats, cfg scan.Co
 				return
 			}
 
+			if cfg.KeepStorage {
+				logger.For(ctx).Info("Scan finished with 'keep storage' enabled, not cleaning up...")
+				pterm.Info.Printf("Scan storage kept, to replay findings use: --from %s --replay <finding-id>\n", id)
+				if cfg.StoreAllResponses {
+					pterm.Info.Printf("All responses stored, to run profiles against them use: gbounty %s --from %s\n", rematchCommand, id)
+				}
+				return
+			}
+
 			logger.For(ctx).Info("Cleaning up scan temporary files...")
 			err := fs.Cleanup(ctx)
 			if err != nil {
@@ -582,23 +1342,56 @@ func finalizeScan(ctx context.Context, updatesChan chan *scan.Stats, cfg scan.Co
 			return
 		}
 
+		// The outputs are redacted and grouped (if enabled) when written, so the storage
+		// is kept as is (e.g. to replay findings). So is the baseline comparison, as the
+		// baseline is a previous (redacted and grouped) output.
+		outFS := scan.WithFindingsGrouping(scan.WithRedaction(fs, cfg.Redaction), cfg.Grouping)
+
+		// We compare the findings against the baseline, if any.
+		var diff *scan.BaselineDiff
+		if len(cfg.Baseline) > 0 {
+			baselineDiff, err := compareBaseline(ctx, cfg, outFS)
+			if err != nil {
+				logger.For(ctx).Errorf("Error while comparing findings against baseline: %s", err.Error())
+				pterm.Error.WithShowLineNumber(false).Printf("Error while comparing findings against baseline: %s\n", err)
+			} else {
+				diff = &baselineDiff
+				*newFindings = len(diff.New)
+			}
+		}
+
 		// We declare the console writer that
 		// will (most likely) be used below later.
 		consoleWriter := writer.NewConsole(os.Stdout)
 
+		defer func() {
+			if diff == nil || cfg.Silent {
+				return
+			}
+
+			if err := consoleWriter.WriteBaselineDiff(ctx, *diff); err != nil {
+				pterm.Error.WithShowLineNumber(false).Printf(`Error while printing baseline comparison: %s`, err)
+				logger.For(ctx).Errorf("Error while printing baseline comparison: %s", err)
+			}
+		}()
+
 		// We write the results to the specified output.
-		if len(cfg.OutPath) > 0 {
-			logger.For(ctx).Infof("Storing scan output to: %s", cfg.OutPath)
-			storeOutput(ctx, cfg, fs)
+		if len(cfg.Outputs) > 0 {
+			// All the outputs are written from the same results,
+			// so an error on any of them doesn't affect the rest.
+			for _, out := range cfg.Outputs {
+				logger.For(ctx).Infof("Storing scan output to: %s", out.Path)
+				storeOutput(ctx, cfg, out, outFS, diff)
+			}
 
 			// If no silent, we print the summary as well.
 			if !cfg.Silent {
-				if err := consoleWriter.WriteStats(ctx, fs); err != nil {
+				if err := consoleWriter.WriteStats(ctx, outFS); err != nil {
 					pterm.Error.WithShowLineNumber(false).Printf(`Error while printing scan stats: %s`, err)
 					logger.For(ctx).Errorf("Error while printing scan stats: %s", err)
 				}
 
-				if err := consoleWriter.WriteMatchesSummary(ctx, fs); err != nil {
+				if err := consoleWriter.WriteMatchesSummary(ctx, outFS); err != nil {
 					pterm.Error.WithShowLineNumber(false).Printf(`Error while printing matches summary: %s`, err)
 					logger.For(ctx).Errorf("Error while printing matches summary: %s", err)
 				}
@@ -606,45 +1399,135 @@ func finalizeScan(ctx context.Context, updatesChan chan *scan.Stats, cfg scan.Co
 			// Otherwise, in case there's no output nor
 			// silent mode, we print the results in the console.
 		} else if !cfg.Silent {
-			err := writeScanFromFs(ctx, consoleWriter, cfg, fs)
+			err := writeScanFromFs(ctx, consoleWriter, cfg, outFS)
 			if err != nil {
 				pterm.Error.WithShowLineNumber(false).Printf(`Error while printing scan results: %s`, err)
 				logger.For(ctx).Errorf("Error while printing scan results: %s", err)
 			}
 		}
+
+		// The sinkhole mode is reminded along with the summary, as the
+		// findings come from the sinkhole, not from the actual hosts.
+		if len(cfg.ResolveAllTo) > 0 && !cfg.Silent {
+			pterm.Warning.Printf("Sinkhole mode (--resolve-all-to) was active: every request was sent to %s, not to the actual hosts\n", cfg.ResolveAllTo)
+		}
 	}
 }
 
-func storeOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem) {
-	logger.For(ctx).Debugf("Creating file to save scan output: %s", cfg.OutPath)
-	file, err := os.Create(cfg.OutPath)
+func storeOutput(ctx context.Context, cfg scan.Config, out scan.Output, fs scan.FileSystem, diff *scan.BaselineDiff) {
+	// When appending, the previous output is read before anything is written,
+	// so it is kept as is if anything fails.
+	var previous []byte
+	if cfg.OutAppend {
+		var err error
+		previous, err = os.ReadFile(out.Path)
+		if err != nil && !os.IsNotExist(err) {
+			logger.For(ctx).Errorf("Error while reading existing scan output file: %s", err.Error())
+			handleStoreOutputErr(out.Path, err)
+			return
+		}
+	}
+
+	// The output is written atomically, so it is never left half-written (e.g. forced exit).
+	err := osext.WriteFileAtomically(out.Path, 0o644, func(file io.Writer) error { //nolint:mnd
+		return writeOutput(ctx, cfg, out, fs, diff, previous, file)
+	})
 	if err != nil {
-		logger.For(ctx).Errorf("Error while creating file to save scan output: %s", err.Error())
-		handleStoreOutputErr(cfg, err)
-		return
+		logger.For(ctx).Errorf("Error while storing scan output: %s", err.Error())
+		handleStoreOutputErr(out.Path, err)
+	}
+}
+
+// writeOutput writes the scan output, with the format of the given [scan.Output], into the
+// given writer, either appended to or merged with the previous output (if any), if possible.
+func writeOutput(ctx context.Context, cfg scan.Config, out scan.Output, fs scan.FileSystem, diff *scan.BaselineDiff, previous []byte, file io.Writer) error {
+	logger.For(ctx).Infof("Storing scan output in %s format", out.Format)
+
+	// JUnit and HTML reports are whole documents, so these are overwritten instead.
+	if len(previous) > 0 && out.Format == "junit" {
+		logger.For(ctx).Warnf("JUnit output cannot be appended, overwriting existing file: %s", out.Path)
 	}
 
-	logger.For(ctx).Infof("Storing scan output in %s format", cfg.OutFormat)
+	if len(previous) > 0 && out.Format == "html" {
+		logger.For(ctx).Warnf("HTML output cannot be appended, overwriting existing file: %s", out.Path)
+	}
+
+	if len(previous) > 0 && out.Format != "json" && out.Format != "junit" && out.Format != "html" {
+		logger.For(ctx).Debugf("Appending scan output to existing file: %s", out.Path)
+		if err := appendPrevious(file, previous, out.Format != "ndjson"); err != nil {
+			return err
+		}
+	}
 
-	switch cfg.OutFormat {
+	switch out.Format {
 	case "json":
 		logger.For(ctx).Debug("Storing scan output as json")
-		err = storeJSONOutput(ctx, cfg, fs, file)
+		if len(previous) > 0 {
+			logger.For(ctx).Debugf("Merging scan output with existing file: %s", out.Path)
+			return storeMergedJSONOutput(ctx, cfg, fs, diff, previous, file)
+		}
+		return storeJSONOutput(ctx, cfg, fs, diff, file)
+	case "ndjson":
+		logger.For(ctx).Debug("Storing scan output as ndjson")
+		return writeScanFromFs(ctx, writer.NewNDJSON(file), cfg, fs)
 	case "markdown":
 		logger.For(ctx).Debug("Storing scan output as markdown")
-		err = writeScanFromFs(ctx, writer.NewMarkdown(file), cfg, fs)
+		return writeScanFromFs(ctx, writer.NewMarkdown(file), cfg, fs)
+	case "junit":
+		logger.For(ctx).Debug("Storing scan output as junit")
+		return writeScanFromFs(ctx, writer.NewJUnit(file, cfg), cfg, fs)
+	case "html":
+		logger.For(ctx).Debug("Storing scan output as html")
+		return writeScanFromFs(ctx, writer.NewHTML(file, cfg), cfg, fs)
+	case "template":
+		logger.For(ctx).Debugf("Storing scan output with template: %s", cfg.OutTemplate)
+		return storeTemplateOutput(ctx, cfg, fs, file)
 	default:
 		logger.For(ctx).Debug("Storing scan output as plain text")
-		err = writeScanFromFs(ctx, writer.NewPlain(file), cfg, fs)
+		return writeScanFromFs(ctx, writer.NewPlain(file), cfg, fs)
+	}
+}
+
+// appendPrevious writes the previous (text) output, followed by a blank line
+// that separates it from the one written next, if blankLine is true. Otherwise
+// (e.g. ndjson), the previous output is only guaranteed to end with a newline.
+func appendPrevious(to io.Writer, previous []byte, blankLine bool) error {
+	if _, err := to.Write(previous); err != nil {
+		return err
+	}
+
+	var separator string
+	if !bytes.HasSuffix(previous, []byte("\n")) {
+		separator = "\n"
+	}
+	if blankLine {
+		separator += "\n"
+	}
+
+	_, err := io.WriteString(to, separator)
+
+	return err
+}
+
+// storeMergedJSONOutput writes the scan output as JSON, merged with
+// the previous one (see [writer.MergeJSON]).
+func storeMergedJSONOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem, diff *scan.BaselineDiff, previous []byte, to io.Writer) error {
+	latest := new(bytes.Buffer)
+	if err := storeJSONOutput(ctx, cfg, fs, diff, latest); err != nil {
+		return err
 	}
 
+	merged, err := writer.MergeJSON(previous, latest.Bytes())
 	if err != nil {
-		logger.For(ctx).Errorf("Error while storing scan output: %s", err.Error())
-		handleStoreOutputErr(cfg, err)
+		return err
 	}
+
+	_, err = to.Write(merged)
+
+	return err
 }
 
-func storeJSONOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem, to io.Writer) error {
+func storeJSONOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem, diff *scan.BaselineDiff, to io.Writer) error {
 	_, err := fmt.Fprintf(to, "{")
 	if err != nil {
 		return err
@@ -655,12 +1538,29 @@ func storeJSONOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem, t
 		return err
 	}
 
+	if diff != nil {
+		err = writer.NewJSON(to).WriteBaselineDiff(ctx, *diff)
+		if err != nil {
+			return err
+		}
+	}
+
 	_, err = fmt.Fprintf(to, `
 }`)
 
 	return err
 }
 
+func storeTemplateOutput(ctx context.Context, cfg scan.Config, fs scan.FileSystem, to io.Writer) error {
+	// Already validated (see [cli.Config.Validate]), but the file may have changed since then.
+	tmpl, err := writer.ParseTemplate(cfg.OutTemplate)
+	if err != nil {
+		return err
+	}
+
+	return writeScanFromFs(ctx, writer.NewTemplate(to, tmpl), cfg, fs)
+}
+
 func writeScanFromFs(ctx context.Context, w scan.Writer, cfg scan.Config, fs scan.FileSystem) error {
 	_, isConsole := w.(writer.Console)
 	if !isConsole {
@@ -714,19 +1614,19 @@ func writeConfig(ctx context.Context, writer scan.Writer, cfg scan.Config) error
 	return writer.WriteConfig(ctx, cfg)
 }
 
-func handleStoreOutputErr(cfg scan.Config, err error) {
+func handleStoreOutputErr(path string, err error) {
 	var pathErr *os.PathError
 
 	if errors.As(err, &pathErr) {
 		pterm.Error.WithShowLineNumber(false).Printf(
-			`Error while storing output(%s): %s`, cfg.OutPath, pathErr.Err,
+			`Error while storing output(%s): %s`, path, pathErr.Err,
 		)
 
 		return
 	}
 
 	pterm.Error.WithShowLineNumber(false).Printf(
-		`Error while storing output(%s): %s`, cfg.OutPath, err,
+		`Error while storing output(%s): %s`, path, err,
 	)
 }
 
@@ -0,0 +1,288 @@
+//nolint:testpackage
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+)
+
+func Test_appendPrevious(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		previous  string
+		blankLine bool
+		expected  string
+	}{
+		"text":                   {previous: "previous", blankLine: true, expected: "previous\n\n"},
+		"text, ending with line": {previous: "previous\n", blankLine: true, expected: "previous\n\n"},
+		"ndjson":                 {previous: `{"id":"a1"}`, expected: "{\"id\":\"a1\"}\n"},
+		"ndjson, ending w/ line": {previous: "{\"id\":\"a1\"}\n", expected: "{\"id\":\"a1\"}\n"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := new(bytes.Buffer)
+			require.NoError(t, appendPrevious(buf, []byte(tc.previous), tc.blankLine))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func Test_storeOutput_Append(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		format   string
+		previous string
+		prefix   string
+	}{
+		"plain":    {format: "plain", previous: "previous output", prefix: "previous output\n\n"},
+		"markdown": {format: "markdown", previous: "# previous output\n", prefix: "# previous output\n\n"},
+		"ndjson":   {format: "ndjson", previous: "{\"id\":\"a1\"}\n", prefix: "{\"id\":\"a1\"}\n{"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "output")
+			require.NoError(t, os.WriteFile(path, []byte(tc.previous), 0o600))
+
+			out := scan.Output{Path: path, Format: tc.format}
+			storeOutput(context.Background(), scan.Config{OutAppend: true}, out, newOutputTestFs(t, testMatch("/a")), nil)
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(b, []byte(tc.prefix)), string(b))
+			assert.Greater(t, len(b), len(tc.prefix))
+		})
+	}
+}
+
+func Test_storeOutput_AppendJSON(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	out := scan.Output{Path: filepath.Join(t.TempDir(), "output.json"), Format: "json"}
+
+	storeOutput(ctx, scan.Config{}, out, newOutputTestFs(t, testMatch("/a")), nil)
+	storeOutput(ctx, scan.Config{OutAppend: true}, out, newOutputTestFs(t, testMatch("/a"), testMatch("/b")), nil)
+
+	b, err := os.ReadFile(out.Path)
+	require.NoError(t, err)
+
+	var merged struct {
+		Matches []struct {
+			ID string `json:"id"`
+		} `json:"matches"`
+		Summary []struct {
+			Count int      `json:"count"`
+			URLs  []string `json:"urls"`
+		} `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(b, &merged), string(b))
+
+	// The finding from the previous scan (/a) is found again, so it is deduplicated.
+	require.Len(t, merged.Matches, 2)
+	assert.NotEqual(t, merged.Matches[0].ID, merged.Matches[1].ID)
+
+	require.Len(t, merged.Summary, 1)
+	assert.Equal(t, 2, merged.Summary[0].Count)
+	assert.Equal(t, []string{"https://example.org/a", "https://example.org/b"}, merged.Summary[0].URLs)
+}
+
+func Test_storeOutput_Multiple(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	fs := newOutputTestFs(t, testMatch("/a"), testMatch("/b"))
+
+	cfg := cli.Config{OutPaths: cli.MultiValue{
+		filepath.Join(dir, "out.json"),
+		filepath.Join(dir, "out.ndjson"),
+		filepath.Join(dir, "out.md"),
+		filepath.Join(dir, "out.xml"),
+		filepath.Join(dir, "out.html"),
+		filepath.Join(dir, "missing", "out.txt"),
+		filepath.Join(dir, "out.txt"),
+	}}
+
+	// Each output is written (and closed) on its own, from the same results,
+	// so the one that cannot be written doesn't affect the rest.
+	for _, out := range cfg.Outputs() {
+		storeOutput(ctx, scan.Config{}, out, fs, nil)
+	}
+
+	read := func(name string) []byte {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		require.NotEmpty(t, b, name)
+		return b
+	}
+
+	var report struct {
+		Matches []json.RawMessage `json:"matches"`
+	}
+	require.NoError(t, json.Unmarshal(read("out.json"), &report))
+	assert.Len(t, report.Matches, 2)
+
+	for _, line := range bytes.Split(bytes.TrimSpace(read("out.ndjson")), []byte("\n")) {
+		assert.True(t, json.Valid(line), string(line))
+	}
+
+	assert.Contains(t, string(read("out.md")), "https://example.org/b")
+	require.NoError(t, xml.Unmarshal(read("out.xml"), new(struct{})))
+	assert.Contains(t, string(read("out.html")), "</html>")
+	assert.Contains(t, string(read("out.txt")), "https://example.org/a")
+
+	// Neither the failed output, nor the temporary files, are left behind.
+	assert.NoDirExists(t, filepath.Join(dir, "missing"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 6)
+}
+
+func testMatch(path string) scan.Match {
+	m := scan.Match{
+		URL:             "https://example.org" + path,
+		ProfileName:     "Reflected XSS",
+		ProfileType:     "active",
+		IssueName:       "Cross-Site Scripting",
+		IssueSeverity:   "High",
+		IssueConfidence: "Firm",
+	}
+	m.ID = scan.MatchID(m)
+
+	return m
+}
+
+func newOutputTestFs(t *testing.T, matches ...scan.Match) scan.FileSystem {
+	t.Helper()
+
+	ctx := context.Background()
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	require.NoError(t, fs.StoreStats(ctx, scan.NewStats()))
+	for _, m := range matches {
+		require.NoError(t, fs.StoreMatch(ctx, m))
+	}
+
+	return fs
+}
+
+//nolint:paralleltest // It modifies the environment.
+func Test_gracePeriod(t *testing.T) {
+	tcs := map[string]struct {
+		value    string
+		expected time.Duration
+	}{
+		"valid":    {value: "30s", expected: 30 * time.Second},
+		"invalid":  {value: "thirty", expected: defaultGracePeriod},
+		"zero":     {value: "0s", expected: defaultGracePeriod},
+		"negative": {value: "-5s", expected: defaultGracePeriod},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("GBOUNTY_SHUTDOWN_GRACE_PERIOD", tc.value)
+			assert.Equal(t, tc.expected, gracePeriod())
+		})
+	}
+
+	t.Run("undefined", func(t *testing.T) {
+		t.Setenv("GBOUNTY_SHUTDOWN_GRACE_PERIOD", "")
+		require.NoError(t, os.Unsetenv("GBOUNTY_SHUTDOWN_GRACE_PERIOD"))
+		assert.Equal(t, defaultGracePeriod, gracePeriod())
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, ExitCodeInterrupted, ExitCode(fmt.Errorf("%w, signal: interrupt", ErrInterrupted)))
+	assert.Equal(t, 130, ExitCodeInterrupted)
+	assert.Equal(t, ExitCodeNewFindings, ExitCode(ErrNewFindings))
+	assert.Equal(t, ExitCodeStoppedOnFinding, ExitCode(ErrStoppedOnFinding))
+	assert.Equal(t, ExitCodeTooManyErrors, ExitCode(ErrTooManyErrors))
+	assert.Equal(t, 1, ExitCode(errors.New("unexpected")))
+}
+
+func Test_gracefulContextWith(t *testing.T) {
+	t.Parallel()
+
+	interrupt := func(t *testing.T, grace time.Duration) (chan os.Signal, <-chan int) {
+		t.Helper()
+
+		var (
+			done   = make(chan os.Signal, 2)
+			exited = make(chan int, 1)
+		)
+
+		ctx := gracefulContextWith(context.Background(), done, grace, func(code int) { exited <- code })
+		require.NoError(t, ctx.Err())
+
+		// The first signal cancels the context, but doesn't force the exit.
+		done <- os.Interrupt
+		<-ctx.Done()
+		require.ErrorIs(t, context.Cause(ctx), ErrInterrupted)
+
+		return done, exited
+	}
+
+	t.Run("second signal", func(t *testing.T) {
+		t.Parallel()
+
+		done, exited := interrupt(t, time.Hour)
+		select {
+		case <-exited:
+			require.Fail(t, "forced exit before the second signal")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		done <- os.Interrupt
+		select {
+		case code := <-exited:
+			assert.Equal(t, ExitCodeForced, code)
+		case <-time.After(time.Second):
+			require.Fail(t, "no forced exit on the second signal")
+		}
+	})
+
+	t.Run("grace period exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		_, exited := interrupt(t, 10*time.Millisecond)
+		select {
+		case code := <-exited:
+			assert.Equal(t, ExitCodeForced, code)
+		case <-time.After(time.Second):
+			require.Fail(t, "no forced exit once the grace period exceeded")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/ulid"
+)
+
+// runPrepareOnly prepares the scan templates (in memory) and saves them into the
+// templates bundle (see [cli.Config.SaveTemplateBundle]), with no requests sent.
+func runPrepareOnly(ctx context.Context, cfg cli.Config) error {
+	fs, err := filesystem.New(afero.NewMemMapFs(), filepath.Join(os.TempDir(), ulid.New()))
+	if err != nil {
+		logger.For(ctx).Errorf("Could not initialize filesystem storage for scan metadata: %s", err)
+		return err
+	}
+
+	// The templates targeting hosts already seen (if so) are left out of the bundle.
+	seen, err := cfg.SeenHosts()
+	if err != nil {
+		logger.For(ctx).Errorf("Error while reading seen hosts file: %s", err.Error())
+		return err
+	}
+
+	if _, err := cli.PrepareTemplates(ctx, fs, cfg, nil, seen); err != nil {
+		logger.For(ctx).Errorf("Error while preparing scan templates: %s", err.Error())
+		return err
+	}
+
+	if err := saveTemplateBundle(ctx, fs, cfg.SaveTemplateBundle); err != nil {
+		logger.For(ctx).Errorf("Error while saving scan templates bundle: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// saveTemplateBundle saves the scan templates stored in the given [scan.FileSystemTemplates]
+// into the zipped (.zip) file at the given path (see [scan.WriteTemplateBundle]).
+func saveTemplateBundle(ctx context.Context, fs scan.FileSystemTemplates, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	written, err := scan.WriteTemplateBundle(ctx, fs, f)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	logger.For(ctx).Infof("Scan templates bundle saved: %s (templates: %d)", path, written)
+	pterm.Success.Printf("Scan templates (%d) saved into bundle: %s\n", written, path)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/ulid"
+)
+
+// runCount prepares the scan templates (in memory) and calculates the amount
+// of requests the scan would send (see [scan.CountRequests]), with no requests
+// sent, and prints it, broken down by host and by profile.
+func runCount(ctx context.Context, cfg cli.Config, provider profile.Provider) error {
+	actives, _, _ := loadProfiles(ctx, cfg, provider)
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), filepath.Join(os.TempDir(), ulid.New()))
+	if err != nil {
+		logger.For(ctx).Errorf("Could not initialize filesystem storage for scan metadata: %s", err)
+		return err
+	}
+
+	// Hosts already seen are skipped (if so), but none is recorded, as no requests are sent.
+	seen, err := cfg.SeenHosts()
+	if err != nil {
+		logger.For(ctx).Errorf("Error while reading seen hosts file: %s", err.Error())
+		return err
+	}
+
+	if _, err := cli.PrepareTemplates(ctx, fs, cfg, nil, seen); err != nil {
+		logger.For(ctx).Errorf("Error while preparing scan templates: %s", err.Error())
+		return err
+	}
+
+	count, err := scan.CountRequests(ctx, fs, configFromArgs(cfg), entrypoint.Finders(), actives)
+	if err != nil {
+		logger.For(ctx).Errorf("Error while counting scan requests: %s", err.Error())
+		return err
+	}
+
+	logger.For(ctx).Infof("Scan requests counted: %d, templates: %d, entrypoints: %d", count.Total, count.Templates, count.Entrypoints)
+
+	pterm.Success.Printf("The scan would send %d request(s), for %d template(s) and %d entrypoint(s)\n", count.Total, count.Templates, count.Entrypoints)
+	printCountBreakdown(ctx, "Request(s) by host", "Host", count.ByHost)
+	printCountBreakdown(ctx, "Request(s) by profile", "Profile", count.ByProfile)
+
+	return nil
+}
+
+// printCountBreakdown prints the given amount of requests per key (e.g. host)
+// as a table, sorted by the amount of requests (in descending order).
+func printCountBreakdown(ctx context.Context, title, header string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	data := pterm.TableData{{header, "Requests"}}
+	for _, key := range keys {
+		data = append(data, []string{key, strconv.Itoa(counts[key])})
+	}
+
+	pterm.Println()
+	pterm.Info.Println(title)
+	if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+		logger.For(ctx).Errorf("Error while printing %s: %s", title, err.Error())
+	}
+}
@@ -0,0 +1,48 @@
+package bootstrap
+
+import (
+	"io"
+
+	"github.com/pterm/pterm"
+
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+	"github.com/bountysecurity/gbounty/kit/osext"
+)
+
+// mergeCommand is the name of the subcommand used to merge multiple scan
+// outputs into a single one (see [runMerge]), e.g. gbounty merge out1.json out2.json -o merged.json
+const mergeCommand = "merge"
+
+// runMerge merges the given scan outputs (JSON), e.g. from a sharded scan,
+// into a single one (see [writer.MergeFiles]), written to the output path.
+//
+// The given args are expected to start with the [mergeCommand].
+func runMerge(args []string) error {
+	cfg, err := cli.ParseMerge(args)
+	if err != nil || cfg.ShowHelp {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	merged, err := writer.MergeFiles(cfg.Inputs...)
+	if err != nil {
+		return err
+	}
+
+	// The output is written atomically, so it is never left half-written.
+	err = osext.WriteFileAtomically(cfg.OutPath, 0o644, func(w io.Writer) error { //nolint:mnd
+		_, err := w.Write(merged)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Printf("Scan outputs (%d) merged into: %s\n", len(cfg.Inputs), cfg.OutPath)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// rematchCommand is the name of the subcommand used to run profiles against
+// the responses stored by a previous scan (see [runRematch]), with no requests
+// sent, e.g. gbounty rematch --storage /path/to/storage -p profiles/
+const rematchCommand = "rematch"
+
+var errRematchScanNotFound = errors.New("scan not found")
+
+// runRematch loads the exchanges (i.e. requests and responses) stored by the scan
+// identified by [cli.Config.Continue], or from the given [cli.Config.Storage] (see
+// [cli.Config.StoreAllResponses]), runs the passive profiles against them (see
+// [scan.Rematch]), and prints the findings, tagged as such. So, detection logic
+// can be iterated over captured data, with no network traffic at all.
+//
+// Only passive profiles are rematched: active ones are ignored (with a warning), as
+// their matchers depend on the payloads injected, and the stored exchanges don't tell
+// which profile, step and payload (if any) each request was sent with.
+//
+// The given args are expected to start with the [rematchCommand].
+func runRematch(args []string) error {
+	cfg, err := cli.Parse(args)
+	if err != nil || cfg.ShowHelp {
+		return err
+	}
+
+	if len(cfg.ProfilesPath) == 0 {
+		if defaultPath := defaultProfilesLocation(); len(defaultPath) > 0 {
+			cfg.ProfilesPath = []string{defaultPath}
+		}
+	}
+
+	if err := cfg.ValidateRematch(); err != nil {
+		return err
+	}
+
+	ctx := initCtxWithLogger(cfg, nil)
+
+	basePath := scanStoragePath(cfg)
+	if _, err := os.Stat(filepath.Join(basePath, filesystem.FileExchanges)); err != nil {
+		logger.For(ctx).Errorf("Could not find stored responses for scan (path=%s): %s", basePath, err)
+		return fmt.Errorf("%w: %s (see --store-all-responses)", errRematchScanNotFound, basePath)
+	}
+
+	pterm.Info.Printf("Rematching stored responses from: %s\n", basePath)
+
+	fs, err := filesystem.New(afero.NewOsFs(), basePath)
+	if err != nil {
+		logger.For(ctx).Errorf("Could not initialize filesystem storage for scan metadata: %s", err)
+		return err
+	}
+
+	provider, err := profile.NewFileProvider(cfg.ProfilesPath...)
+	if err != nil {
+		logger.For(ctx).Errorf("Could not load profiles: %s", err)
+		return fmt.Errorf("could not load profiles: %w", err)
+	}
+
+	actives, passiveReqs, passiveRes := loadProfiles(ctx, cfg, provider)
+	if err := checkSeverityOverrides(cfg, provider, actives, passiveReqs, passiveRes); err != nil {
+		logger.For(ctx).Errorf("Invalid severity overrides: %s", err)
+		return err
+	}
+
+	if len(actives) > 0 {
+		logger.For(ctx).Warnf("Active profiles (%d) are ignored, only passive ones are rematched", len(actives))
+		pterm.Warning.Printf("Active profiles (%d) are ignored, only passive ones are rematched against stored responses\n", len(actives))
+	}
+
+	matches, err := scan.Rematch(ctx, fs, passiveReqs, passiveRes, cfg.CustomTokens)
+	if err != nil {
+		logger.For(ctx).Errorf("Error while rematching stored responses (id=%s): %s", cfg.Continue, err)
+		return err
+	}
+
+	// The redaction and severity overrides are already validated, see [cli.Config.ValidateRematch].
+	redaction, _ := cfg.Redaction()
+	severityOverrides, _ := cfg.SeverityOverrides()
+
+	w := writer.NewConsole(os.Stdout)
+	for _, m := range matches {
+		m.IssueSeverity = severityOverrides.Severity(m.ProfileName, m.IssueSeverity)
+		if err := w.WriteMatch(ctx, redaction.Match(m), cfg.ShowResponses); err != nil {
+			logger.For(ctx).Errorf("Error while writing rematch finding: %s", err)
+		}
+	}
+
+	pterm.Success.Printf("Stored responses rematched, findings: %d\n", len(matches))
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/platform/http/client"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/console/color"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/diff"
+)
+
+var (
+	errReplayScanNotFound = errors.New("scan not found")
+	errReplayNoRequest    = errors.New("finding has no stored request")
+)
+
+const replayDefaultTimeout = 20 * time.Second
+
+// runReplay loads the finding identified by [cli.Config.Replay] from the storage
+// of the scan identified by [cli.Config.Continue] (see [scanStoragePath]), re-sends the exact same request,
+// and prints a side-by-side diff between the stored and the fresh response.
+//
+// If the profile that caused the finding is available, the fresh response is
+// checked against it, to confirm whether the issue is still present.
+func runReplay(ctx context.Context, cfg cli.Config, provider profile.Provider) error {
+	basePath := scanStoragePath(cfg)
+	if _, err := os.Stat(basePath); err != nil {
+		logger.For(ctx).Errorf("Could not find storage for scan (path=%s): %s", basePath, err)
+		return fmt.Errorf("%w: %s", errReplayScanNotFound, basePath)
+	}
+
+	fs, err := filesystem.New(afero.NewOsFs(), basePath)
+	if err != nil {
+		logger.For(ctx).Errorf("Could not initialize filesystem storage for scan metadata: %s", err)
+		return err
+	}
+
+	m, err := fs.LoadMatch(ctx, cfg.Replay)
+	if err != nil {
+		logger.For(ctx).Errorf("Could not load finding (id=%s): %s", cfg.Replay, err)
+		return err
+	}
+
+	req, origRes := lastExchange(*m)
+	if req == nil {
+		return fmt.Errorf("%w: %s", errReplayNoRequest, cfg.Replay)
+	}
+
+	replayed := req.Clone()
+	if replayed.Timeout <= 0 {
+		replayed.Timeout = replayDefaultTimeout
+	}
+
+	pterm.Info.Printf("Replaying finding %s (%s) against: %s\n", m.ID, m.IssueName, m.URL)
+
+	freshRes, err := client.New(clientOptsFromConfig(ctx, cfg)...).Do(ctx, &replayed)
+	if err != nil {
+		logger.For(ctx).Errorf("Error while replaying finding (id=%s): %s", cfg.Replay, err)
+		return err
+	}
+
+	// The redaction is already validated, see [cli.Config.ValidateReplay].
+	redaction, _ := cfg.Redaction()
+
+	var origBytes string
+	if origRes != nil {
+		origBytes = string(redaction.Response(origRes).Bytes())
+	} else {
+		pterm.Warning.Println("The finding has no stored response, so it was not captured during the scan (see -sr/--show-responses)")
+	}
+
+	printSideBySide(diff.Lines(origBytes, string(redaction.Response(&freshRes).Bytes())))
+
+	present, checked := stillPresent(ctx, cfg, provider, *m, replayed, freshRes)
+
+	switch {
+	case !checked:
+		pterm.Warning.Printf("Profile (%s) not available, the fresh response could not be checked\n", m.ProfileName)
+	case present:
+		pterm.Success.Printf("The issue (%s) is still present\n", m.IssueName)
+	default:
+		pterm.Info.Printf("The issue (%s) is no longer present\n", m.IssueName)
+	}
+
+	return nil
+}
+
+// scanStoragePath returns the path to the storage of the scan referred by the given
+// config: either the one given (see [cli.Config.Storage]), or the default one of the
+// scan identified by [cli.Config.Continue]. The path to any of the storage files
+// (e.g. matches.json) is also accepted, so the storage is the directory it belongs to.
+func scanStoragePath(cfg cli.Config) string {
+	if len(cfg.Storage) == 0 {
+		return filepath.Join(os.TempDir(), cfg.Continue)
+	}
+
+	if info, err := os.Stat(cfg.Storage); err == nil && !info.IsDir() {
+		return filepath.Dir(cfg.Storage)
+	}
+
+	return cfg.Storage
+}
+
+func lastExchange(m scan.Match) (*request.Request, *response.Response) {
+	var (
+		req *request.Request
+		res *response.Response
+	)
+
+	for i := len(m.Requests) - 1; i >= 0; i-- {
+		if m.Requests[i] != nil {
+			req = m.Requests[i]
+			if i < len(m.Responses) {
+				res = m.Responses[i]
+			}
+			break
+		}
+	}
+
+	return req, res
+}
+
+func stillPresent(
+	ctx context.Context,
+	cfg cli.Config,
+	provider profile.Provider,
+	m scan.Match,
+	req request.Request,
+	res response.Response,
+) (present, checked bool) {
+	data := match.Data{
+		Original:     &req,
+		Request:      &req,
+		Response:     &res,
+		CustomTokens: cfg.CustomTokens,
+	}
+
+	if len(m.Requests) > 0 && m.Requests[0] != nil {
+		data.Original = m.Requests[0]
+	}
+
+	for _, prof := range provider.Actives() {
+		if prof.GetName() == m.ProfileName && len(prof.Steps) > 0 {
+			data.Profile = prof
+			data.Step = prof.Steps[len(prof.Steps)-1]
+			if m.Origin != nil && m.Origin.Step > 0 && m.Origin.Step <= len(prof.Steps) {
+				data.Step = prof.Steps[m.Origin.Step-1]
+			}
+
+			// The payload is decoded the same way it was when matched, from the step's payloads.
+			payload, payloadDecode := scan.MatchPayloads(data.Step, m.Payload, req)
+			data.Payload, data.PayloadDecode = &payload, &payloadDecode
+
+			ok, _ := match.Match(ctx, data)
+			return ok, true
+		}
+	}
+
+	// Passive profiles have no payloads, so the stored one is used as is.
+	data.Payload, data.PayloadDecode = &m.Payload, &m.Payload
+
+	for _, prof := range provider.PassiveReqs() {
+		if prof.GetName() == m.ProfileName {
+			data.Profile = prof
+			ok, _ := match.Match(ctx, data)
+			return ok, true
+		}
+	}
+
+	for _, prof := range provider.PassiveRes() {
+		if prof.GetName() == m.ProfileName {
+			data.Profile = prof
+			ok, _ := match.Match(ctx, data)
+			return ok, true
+		}
+	}
+
+	return false, false
+}
+
+func printSideBySide(lines []diff.Line) {
+	const (
+		separator = " │ "
+		minWidth  = 20
+	)
+
+	width := (pterm.GetTerminalWidth() - len(separator) - 2) / 2 //nolint:mnd
+	if width < minWidth {
+		width = minWidth
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("  %s%s%s\n",
+		color.BoldYellow().Sprint(pad("ORIGINAL RESPONSE", width)),
+		separator,
+		color.BoldYellow().Sprint("FRESH RESPONSE"),
+	))
+
+	for _, l := range lines {
+		left, right := pad(l.Left, width), pad(l.Right, width)
+
+		switch l.Op {
+		case diff.Equal:
+			builder.WriteString("  " + left + separator + right + "\n")
+		case diff.Changed:
+			builder.WriteString(color.Blue().Sprint("~ "+left) + separator + color.Blue().Sprint(right) + "\n")
+		case diff.Removed:
+			builder.WriteString(color.Red().Sprint("- "+left) + separator + "\n")
+		case diff.Added:
+			builder.WriteString("  " + pad("", width) + separator + color.Green().Sprint(right) + "\n")
+		}
+	}
+
+	if diff.AllEqual(lines) {
+		builder.WriteString(color.Cyan().Sprint("Both responses are identical") + "\n")
+	}
+
+	fmt.Fprint(os.Stdout, builder.String())
+}
+
+// pad truncates or right-pads the given string (by runes),
+// so it fits exactly the given width.
+func pad(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+
+	return s + strings.Repeat(" ", width-len(runes))
+}
@@ -0,0 +1,112 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/cli"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/kit/strings/capitalize"
+	"github.com/bountysecurity/gbounty/kit/ulid"
+)
+
+// validateCommand is the name of the subcommand used to validate
+// the scan inputs (see [runValidate]), e.g. gbounty validate -u ...
+const validateCommand = "validate"
+
+var errValidationFailed = errors.New("validation failed")
+
+// runValidate loads all the configured inputs (urls, requests and params files,
+// profiles, etc.), and reports all the errors found, with no requests sent.
+//
+// The given args are expected to start with the [validateCommand].
+func runValidate(args []string) error {
+	cfg, err := cli.Parse(args)
+	if err != nil || cfg.ShowHelp {
+		return err
+	}
+
+	if len(cfg.ProfilesPath) == 0 {
+		if defaultPath := defaultProfilesLocation(); len(defaultPath) > 0 {
+			cfg.ProfilesPath = []string{defaultPath}
+		}
+	}
+
+	ctx := initCtxWithLogger(cfg, nil)
+
+	var errs []error
+	collect := func(err error) {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			errs = append(errs, joined.Unwrap()...)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	collect(cfg.ValidateAll())
+
+	var numOfProfiles int
+	if len(cfg.ProfilesPath) > 0 && len(cfg.ProfilesPath[0]) > 0 {
+		numOfProfiles, err = profile.CheckFiles(cfg.ProfilesPath...)
+		collect(err)
+	}
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), filepath.Join(os.TempDir(), ulid.New()))
+	if err != nil {
+		return err
+	}
+
+	counter := &countingFS{FileSystem: fs}
+	collect(cli.ValidateTemplates(ctx, counter, cfg))
+
+	errs = uniqueErrors(errs)
+	if len(errs) == 0 {
+		pterm.Success.Printf("Inputs are valid: %d profile(s) and %d request template(s) found\n", numOfProfiles, counter.n)
+		return nil
+	}
+
+	for _, err := range errs {
+		pterm.Error.WithShowLineNumber(false).Printf("%s\n", capitalize.First(err.Error()))
+	}
+
+	return fmt.Errorf("%w: %d error(s) found", errValidationFailed, len(errs))
+}
+
+// uniqueErrors removes those errors with the same message, as the same
+// input (e.g. an invalid url) may be reported by multiple validations.
+func uniqueErrors(errs []error) []error {
+	seen := make(map[string]struct{}, len(errs))
+	unique := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		if _, ok := seen[err.Error()]; ok {
+			continue
+		}
+
+		seen[err.Error()] = struct{}{}
+		unique = append(unique, err)
+	}
+
+	return unique
+}
+
+// countingFS is a [scan.FileSystem] decorator that
+// counts the templates stored.
+type countingFS struct {
+	scan.FileSystem
+	n int
+}
+
+func (fs *countingFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	fs.n++
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
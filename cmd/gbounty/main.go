@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/pterm/pterm"
@@ -13,8 +14,15 @@ func main() {
 	bootstrap.PrintAppName()
 	bootstrap.CheckForUpdates()
 
-	if err := bootstrap.Run(); err != nil {
+	err := bootstrap.Run()
+	if err == nil {
+		return
+	}
+
+	// Once interrupted, the output has already been flushed, so there's nothing else to report.
+	if !errors.Is(err, bootstrap.ErrInterrupted) {
 		pterm.Error.WithShowLineNumber(false).Printf("%s\n", capitalize.First(err.Error()))
-		os.Exit(1)
 	}
+
+	os.Exit(bootstrap.ExitCode(err))
 }
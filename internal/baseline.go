@@ -0,0 +1,64 @@
+package scan
+
+// BaselineDiff is the delta between the findings ([Match]) of a [scan] and those
+// from a baseline (i.e. a previous scan), keyed by their stable identifier (see
+// [MatchID]). So, the same finding found on both scans is considered unchanged.
+//
+// New and Unchanged keep the order of the scan findings, while Resolved (i.e.
+// those only present on the baseline) keep the order of the baseline ones.
+type BaselineDiff struct {
+	New       []Match
+	Resolved  []Match
+	Unchanged []Match
+}
+
+// DiffBaseline compares the given findings against the given baseline ones,
+// and returns the [BaselineDiff]. The baseline findings must have an identifier, as these
+// are compared by it, while the scan findings with no identifier get one assigned (see
+// [MatchID]). Duplicates (i.e. same identifier) are only counted once.
+func DiffBaseline(baseline, current []Match) BaselineDiff {
+	inBaseline := make(map[string]struct{}, len(baseline))
+	for _, m := range baseline {
+		inBaseline[matchKey(m)] = struct{}{}
+	}
+
+	var (
+		diff      BaselineDiff
+		inCurrent = make(map[string]struct{}, len(current))
+	)
+
+	for _, m := range current {
+		key := matchKey(m)
+		if _, seen := inCurrent[key]; seen {
+			continue
+		}
+		inCurrent[key] = struct{}{}
+
+		if _, ok := inBaseline[key]; ok {
+			diff.Unchanged = append(diff.Unchanged, m)
+		} else {
+			diff.New = append(diff.New, m)
+		}
+	}
+
+	for _, m := range baseline {
+		key := matchKey(m)
+		if _, ok := inCurrent[key]; ok {
+			continue
+		}
+		// Marked as seen, so duplicates are skipped.
+		inCurrent[key] = struct{}{}
+
+		diff.Resolved = append(diff.Resolved, m)
+	}
+
+	return diff
+}
+
+func matchKey(m Match) string {
+	if len(m.ID) > 0 {
+		return m.ID
+	}
+
+	return MatchID(m)
+}
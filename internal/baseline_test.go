@@ -0,0 +1,59 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestDiffBaseline(t *testing.T) {
+	t.Parallel()
+
+	sqli := scan.Match{URL: "http://example.org/?id=1", IssueName: "SQL Injection", IssueParam: "id"}
+	xss := scan.Match{URL: "http://example.org/?q=1", IssueName: "Reflected XSS", IssueParam: "q"}
+	lfi := scan.Match{URL: "http://example.org/?file=a", IssueName: "Local File Inclusion", IssueParam: "file"}
+
+	// Baseline findings, as read from the output, already have an identifier.
+	baseline := []scan.Match{withID(sqli), withID(lfi), withID(lfi)}
+
+	diff := scan.DiffBaseline(baseline, []scan.Match{sqli, xss, xss})
+
+	assert.Equal(t, []scan.Match{xss}, diff.New)
+	assert.Equal(t, []scan.Match{withID(lfi)}, diff.Resolved)
+	assert.Equal(t, []scan.Match{sqli}, diff.Unchanged)
+}
+
+func TestMatchID(t *testing.T) {
+	t.Parallel()
+
+	m := scan.Match{URL: "http://example.org/?id=1", ProfileName: "SQLi", IssueName: "SQL Injection", IssueParam: "id", Payload: "'"}
+	id := scan.MatchID(m)
+	assert.Len(t, id, 12)
+
+	// The template the match was found while scanning doesn't change it,
+	// so those found on different scans (or rematched) get the same one.
+	m.Origin = &scan.MatchOrigin{TemplateIdx: 3, OriginIdx: 2}
+	assert.Equal(t, id, scan.MatchID(m))
+
+	// While those found with the same payload at different steps or entrypoints don't collide.
+	ids := map[string]struct{}{id: {}}
+	for _, origin := range []scan.MatchOrigin{
+		{Step: 1},
+		{Step: 2},
+		{InsertionPoint: "Param URL Value"},
+		{InsertionPoint: "Param Body Value"},
+		{Step: 2, InsertionPoint: "Param Body Value"},
+	} {
+		origin := origin
+		m.Origin = &origin
+		ids[scan.MatchID(m)] = struct{}{}
+	}
+	assert.Len(t, ids, 6)
+}
+
+func withID(m scan.Match) scan.Match {
+	m.ID = scan.MatchID(m)
+	return m
+}
@@ -0,0 +1,268 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+const (
+	// MetadataCachePoisoning is the [Match.Metadata] key that summarizes the unkeyed header
+	// whose (poisoned) value was served to a clean request while probing cache poisoning
+	// (see [CachePoisoningCfg]), e.g. X-Forwarded-Host: reflected in the clean response (cached).
+	MetadataCachePoisoning = "cache_poisoning"
+	// MetadataCacheHeaders is the [Match.Metadata] key that holds the cache-related headers
+	// of the clean response (e.g. Age or X-Cache), as evidence of the response being cached.
+	MetadataCacheHeaders = "cache_headers"
+)
+
+// DefaultCachePoisoningHeaders are the (commonly unkeyed) headers poisoned
+// while probing cache poisoning (see [CachePoisoningCfg]), unless others are given.
+var DefaultCachePoisoningHeaders = []string{
+	"X-Forwarded-Host",
+	"X-Host",
+	"X-Forwarded-Server",
+	"X-Original-Host",
+}
+
+// cacheBusterParam is the query parameter added to both the poisoned and the clean requests
+// (see [CachePoisoningProbe]), with a unique value, so both share the same cache key, but
+// one that no other client requests (i.e. the actual cached responses aren't poisoned).
+const cacheBusterParam = "gbcb"
+
+// cacheEvidenceHeaders are the response headers reported as evidence of the response
+// being cached (see [MetadataCacheHeaders]), by different caches and CDNs.
+var cacheEvidenceHeaders = []string{
+	"Age",
+	"X-Cache",
+	"X-Cache-Hits",
+	"X-Cache-Status",
+	"CF-Cache-Status",
+	"X-Varnish",
+	"X-Proxy-Cache",
+}
+
+// CachePoisoningCfg defines whether web cache poisoning is probed for every request, by sending
+// the request with a poisoned (unkeyed) header (e.g. X-Forwarded-Host), and then a clean one (with
+// no such header), to detect whether the poisoned value is reflected in the clean response, which
+// means the response to the poisoned request was cached and served to the clean one.
+type CachePoisoningCfg struct {
+	Enabled bool
+	Headers []string
+}
+
+// Clone returns a deep copy of the [CachePoisoningCfg] instance.
+func (c CachePoisoningCfg) Clone() CachePoisoningCfg {
+	return CachePoisoningCfg{Enabled: c.Enabled, Headers: slices.Clone(c.Headers)}
+}
+
+// Applies returns whether cache poisoning is probed for the given [Template].
+func (c CachePoisoningCfg) Applies(tpl Template) bool {
+	return c.Enabled && tpl.Response == nil
+}
+
+// CachePoisoningProbe is each pair of requests sent while probing cache poisoning: the poisoned
+// one, with the poisoned header, and the clean one, with no such header, both with the same
+// cache buster (see [cacheBusterParam]).
+type CachePoisoningProbe struct {
+	Header   string
+	Value    string
+	Poisoned request.Request
+	Clean    request.Request
+}
+
+// Probes returns the [CachePoisoningProbe] of the given request, one per header (see
+// [CachePoisoningCfg.Headers]), in order. The given nonce makes both the cache busters
+// and the poisoned values unique (see [newCachePoisoningNonce]).
+func (c CachePoisoningCfg) Probes(req request.Request, nonce string) []CachePoisoningProbe {
+	probes := make([]CachePoisoningProbe, 0, len(c.Headers))
+
+	for i, header := range c.Headers {
+		id := fmt.Sprintf("%s%d", nonce, i)
+		value := fmt.Sprintf("gbcp%s.example.com", id)
+
+		clean := request.WithoutHeader(header)(req)
+		clean.Path = appendQueryParam(clean.Path, cacheBusterParam+"="+id)
+
+		poisoned := clean.Clone()
+		poisoned.SetHeader(header, value)
+
+		probes = append(probes, CachePoisoningProbe{
+			Header:   header,
+			Value:    value,
+			Poisoned: poisoned,
+			Clean:    clean,
+		})
+	}
+
+	return probes
+}
+
+// newCachePoisoningNonce returns a random (hex-encoded) nonce, see [CachePoisoningCfg.Probes].
+func newCachePoisoningNonce() string {
+	nonce := make([]byte, 4) //nolint:mnd
+	_, _ = rand.Read(nonce)
+
+	return hex.EncodeToString(nonce)
+}
+
+// CachePoisoningResult is the behavior observed on the responses to a [CachePoisoningProbe].
+type CachePoisoningResult struct {
+	Header string
+	Value  string
+	// Reflected is true if the poisoned value is present in the response to the poisoned request.
+	Reflected bool
+	// Cached is true if the poisoned value is present in the response to the clean request,
+	// which means the response to the poisoned request was cached and served to the clean one.
+	Cached bool
+	// CacheHeaders are the cache-related headers of the clean response (see [cacheEvidenceHeaders]),
+	// with the form: name: value.
+	CacheHeaders []string
+}
+
+// EvaluateCachePoisoning returns the [CachePoisoningResult] of the given [CachePoisoningProbe],
+// by looking for the poisoned value in the responses to both the poisoned and the clean requests.
+func EvaluateCachePoisoning(p CachePoisoningProbe, poisoned, clean *response.Response) CachePoisoningResult {
+	return CachePoisoningResult{
+		Header:       p.Header,
+		Value:        p.Value,
+		Reflected:    reflectsValue(poisoned, p.Value),
+		Cached:       reflectsValue(clean, p.Value),
+		CacheHeaders: cacheHeaders(clean),
+	}
+}
+
+// reflectsValue returns whether the given value is present in either the headers
+// (e.g. Location) or the body of the given response.
+func reflectsValue(res *response.Response, value string) bool {
+	if bytes.Contains(res.Body, []byte(value)) {
+		return true
+	}
+
+	for _, values := range res.Headers {
+		for _, v := range values {
+			if strings.Contains(v, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cacheHeaders returns the cache-related headers (see [cacheEvidenceHeaders])
+// of the given response, in order, with the form: name: value.
+func cacheHeaders(res *response.Response) []string {
+	var headers []string
+	for _, name := range cacheEvidenceHeaders {
+		for key, values := range res.Headers {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				headers = append(headers, name+": "+strings.Join(values, ", "))
+			}
+		}
+	}
+
+	return headers
+}
+
+// Summary returns a single-line summary of the behavior observed,
+// e.g. X-Forwarded-Host: reflected in the clean response (cached).
+func (r CachePoisoningResult) Summary() string {
+	switch {
+	case r.Cached:
+		return r.Header + ": reflected in the clean response (cached)"
+	case r.Reflected:
+		return r.Header + ": reflected, but not cached"
+	default:
+		return r.Header + ": not reflected"
+	}
+}
+
+// cachePoisoningKey is the [context.Context] key for the [CachePoisoningResult] of a match.
+type cachePoisoningKey struct{}
+
+// withCachePoisoning returns a copy of the given [context.Context] with the given
+// [CachePoisoningResult], so it can be attached to the match reported (see [CachePoisoningOf]).
+func withCachePoisoning(ctx context.Context, r CachePoisoningResult) context.Context {
+	return context.WithValue(ctx, cachePoisoningKey{}, r)
+}
+
+// CachePoisoningOf returns the [CachePoisoningResult] of a match reported with the
+// given [context.Context], if any, or nil if it isn't a cache poisoning match.
+func CachePoisoningOf(ctx context.Context) *CachePoisoningResult {
+	r, ok := ctx.Value(cachePoisoningKey{}).(CachePoisoningResult)
+	if !ok {
+		return nil
+	}
+	return &r
+}
+
+// cachePoisoningMetadata returns the given metadata along with the [CachePoisoningResult]
+// from the given [context.Context] (see [MetadataCachePoisoning] and [MetadataCacheHeaders]), if any.
+func cachePoisoningMetadata(ctx context.Context, metadata map[string]string) map[string]string {
+	r := CachePoisoningOf(ctx)
+	if r == nil {
+		return metadata
+	}
+
+	metadata = withMetadata(metadata, MetadataCachePoisoning, []string{r.Summary()})
+	if len(r.CacheHeaders) > 0 {
+		metadata = withMetadata(metadata, MetadataCacheHeaders, r.CacheHeaders)
+	}
+
+	return metadata
+}
+
+// cachePoisoningProfile is the (built-in) profile the matches reported while probing cache
+// poisoning (see [CachePoisoningCfg]) belong to, when the poisoned value is served to a clean request.
+type cachePoisoningProfile struct {
+	result CachePoisoningResult
+}
+
+var (
+	_ profile.Profile          = cachePoisoningProfile{}
+	_ profile.IssueInformation = cachePoisoningProfile{}
+)
+
+func (cachePoisoningProfile) GetName() string       { return "Cache poisoning" }
+func (cachePoisoningProfile) GetType() profile.Type { return profile.TypeActive }
+func (cachePoisoningProfile) IsEnabled() bool       { return true }
+func (cachePoisoningProfile) GetTags() []string     { return []string{"cache-poisoning", "cache"} }
+
+func (cachePoisoningProfile) GetIssueName() string     { return "Web cache poisoning" }
+func (cachePoisoningProfile) GetIssueSeverity() string { return "High" }
+
+func (p cachePoisoningProfile) GetIssueConfidence() string {
+	if len(p.result.CacheHeaders) > 0 {
+		return "Firm"
+	}
+	return "Tentative"
+}
+
+func (p cachePoisoningProfile) GetIssueDetail() string {
+	detail := fmt.Sprintf("The value (%s) of the unkeyed header %s was reflected in the response to a subsequent request with no such header, "+
+		"so the response to the poisoned request was cached and served to other clients.", p.result.Value, p.result.Header)
+	if len(p.result.CacheHeaders) > 0 {
+		detail += fmt.Sprintf(" Cache headers: %s.", strings.Join(p.result.CacheHeaders, ", "))
+	}
+	return detail
+}
+
+func (cachePoisoningProfile) GetIssueBackground() string {
+	return "Caches that don't include every header that influences the response in the cache key (i.e. unkeyed headers) " +
+		"let attackers store malicious responses (e.g. with attacker-controlled hosts) that are then served to every other client."
+}
+
+func (cachePoisoningProfile) GetRemediationDetail() string {
+	return "Don't use unkeyed headers (e.g. X-Forwarded-Host) to build the responses, or add them to the cache key."
+}
+
+func (cachePoisoningProfile) GetRemediationBackground() string { return "" }
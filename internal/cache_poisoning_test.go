@@ -0,0 +1,179 @@
+package scan_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestCachePoisoningCfg_Probes(t *testing.T) {
+	t.Parallel()
+
+	cfg := scan.CachePoisoningCfg{Enabled: true, Headers: []string{"X-Forwarded-Host", "X-Host"}}
+
+	req, err := request.ParseRequest([]byte("GET /home?lang=en HTTP/1.1\r\nHost: example.org\r\nx-host: original\r\n\r\n"))
+	require.NoError(t, err)
+
+	probes := cfg.Probes(req, "abcd")
+	require.Len(t, probes, 2)
+
+	assert.Equal(t, "X-Forwarded-Host", probes[0].Header)
+	assert.Equal(t, "gbcpabcd0.example.com", probes[0].Value)
+	assert.Equal(t, "/home?lang=en&gbcb=abcd0", probes[0].Poisoned.Path)
+	assert.Equal(t, "/home?lang=en&gbcb=abcd0", probes[0].Clean.Path)
+	assert.Equal(t, "gbcpabcd0.example.com", probes[0].Poisoned.Header("X-Forwarded-Host"))
+	assert.Empty(t, probes[0].Clean.Header("X-Forwarded-Host"))
+
+	// The existing header is replaced (poisoned) and removed (clean), regardless of its case.
+	assert.Equal(t, "/home?lang=en&gbcb=abcd1", probes[1].Clean.Path)
+	assert.Equal(t, "gbcpabcd1.example.com", probes[1].Poisoned.Header("X-Host"))
+	assert.Empty(t, probes[1].Clean.Header("X-Host"))
+	assert.Empty(t, probes[1].Clean.Header("x-host"))
+
+	// The original request is left untouched.
+	assert.Equal(t, "/home?lang=en", req.Path)
+	assert.Equal(t, "original", req.Header("X-Host"))
+}
+
+func TestEvaluateCachePoisoning(t *testing.T) {
+	t.Parallel()
+
+	probe := scan.CachePoisoningProbe{Header: "X-Forwarded-Host", Value: "gbcp1.example.com"}
+
+	tcs := map[string]struct {
+		poisoned, clean response.Response
+		expReflected    bool
+		expCached       bool
+		expHeaders      []string
+	}{
+		"not reflected": {
+			poisoned: response.Response{Code: 200, Body: []byte("<a href=//example.org>")},
+			clean:    response.Response{Code: 200, Body: []byte("<a href=//example.org>")},
+		},
+		"reflected, not cached": {
+			poisoned:     response.Response{Code: 200, Body: []byte("<a href=//gbcp1.example.com>")},
+			clean:        response.Response{Code: 200, Body: []byte("<a href=//example.org>"), Headers: map[string][]string{"X-Cache": {"MISS"}}},
+			expReflected: true,
+			expHeaders:   []string{"X-Cache: MISS"},
+		},
+		"cached": {
+			poisoned:     response.Response{Code: 200, Body: []byte("<a href=//gbcp1.example.com>")},
+			clean:        response.Response{Code: 200, Body: []byte("<a href=//gbcp1.example.com>"), Headers: map[string][]string{"Age": {"3"}, "x-cache": {"HIT"}}},
+			expReflected: true,
+			expCached:    true,
+			expHeaders:   []string{"Age: 3", "X-Cache: HIT"},
+		},
+		"cached redirect": {
+			poisoned:     response.Response{Code: 302, Headers: map[string][]string{"Location": {"https://gbcp1.example.com/"}}},
+			clean:        response.Response{Code: 302, Headers: map[string][]string{"Location": {"https://gbcp1.example.com/"}}},
+			expReflected: true,
+			expCached:    true,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result := scan.EvaluateCachePoisoning(probe, &tc.poisoned, &tc.clean)
+			assert.Equal(t, tc.expReflected, result.Reflected)
+			assert.Equal(t, tc.expCached, result.Cached)
+			assert.Equal(t, tc.expHeaders, result.CacheHeaders)
+		})
+	}
+}
+
+func TestRunner_CachePoisoning(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for i, u := range []string{"http://example.com/cached", "http://example.com/dynamic"} {
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, i, request.WithOptions(u), nil)))
+	}
+
+	// Only the responses to /cached are cached, and there are
+	// no active profiles, so only the probes are sent.
+	requester := &cachingRequester{cache: make(map[string]response.Response)}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{
+			RPS:            100,
+			Concurrency:    1,
+			CachePoisoning: scan.CachePoisoningCfg{Enabled: true, Headers: []string{"X-Forwarded-Host"}},
+		}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{
+			{
+				Name:          "Admin panel",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,admin panel"},
+				IssueName:     "Admin panel",
+				IssueSeverity: "High",
+			},
+		}))
+	require.NoError(t, r.Start())
+
+	require.Equal(t, 4, requester.sent)
+
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "Web cache poisoning", matches[0].IssueName)
+	require.Equal(t, "http://example.com/cached", matches[0].URL)
+	require.Len(t, matches[0].Requests, 2)
+	require.Equal(t, "X-Forwarded-Host: reflected in the clean response (cached)", matches[0].Metadata[scan.MetadataCachePoisoning])
+	require.Equal(t, "Age: 1", matches[0].Metadata[scan.MetadataCacheHeaders])
+}
+
+// cachingRequester reflects the X-Forwarded-Host header into the responses,
+// and caches those to /cached paths (keyed by path, i.e. the header is unkeyed).
+type cachingRequester struct {
+	sync.Mutex
+	cache map[string]response.Response
+	sent  int
+}
+
+func (cr *cachingRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	cr.Lock()
+	defer cr.Unlock()
+	cr.sent++
+
+	if cached, ok := cr.cache[req.Path]; ok {
+		return cached, nil
+	}
+
+	host := req.Header("X-Forwarded-Host")
+	if len(host) == 0 {
+		host = "example.com"
+	}
+
+	res := response.Response{Code: 200, Body: []byte(`<script src="//` + host + `/app.js"></script>`)}
+	if strings.HasPrefix(req.Path, "/cached") {
+		cr.cache[req.Path] = response.Response{Code: res.Code, Body: res.Body, Headers: map[string][]string{"Age": {"1"}}}
+	}
+
+	return res, nil
+}
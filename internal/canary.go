@@ -0,0 +1,90 @@
+package scan
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// ErrInvalidCanaryPrefix is the error returned when the canary
+// prefix is not valid (see [ValidateCanaryPrefix]).
+var ErrInvalidCanaryPrefix = errors.New("invalid canary prefix")
+
+// MetadataCanary is the [Match.Metadata] key that identifies the canaries prepended to
+// the payloads injected (see [Config.CanaryPrefix]), only set when canaries are enabled.
+const MetadataCanary = "canary"
+
+const (
+	// maxCanaryPrefixLen is the maximum length of the canary prefix (see [ValidateCanaryPrefix]).
+	maxCanaryPrefixLen = 32
+	// canarySuffixLen is the amount of random bytes appended to the canary prefix,
+	// hex-encoded, so each canary is unique (see [newCanary]).
+	canarySuffixLen = 4
+)
+
+// ValidateCanaryPrefix validates the given canary prefix (see [Config.CanaryPrefix]), which must
+// only contain alphanumeric characters (up to 32), so the canaries prepended to the payloads
+// neither break them, nor change the context these are reflected in.
+func ValidateCanaryPrefix(prefix string) error {
+	if len(prefix) == 0 || len(prefix) > maxCanaryPrefixLen {
+		return fmt.Errorf("%w: it must be between 1 and %d characters long: %q", ErrInvalidCanaryPrefix, maxCanaryPrefixLen, prefix)
+	}
+
+	for _, c := range prefix {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("%w: it must only contain alphanumeric characters: %q", ErrInvalidCanaryPrefix, prefix)
+		}
+	}
+
+	return nil
+}
+
+// canaryPrefixKey is the [context.Context] key for the canary prefix (see [Config.CanaryPrefix]).
+type canaryPrefixKey struct{}
+
+// withCanaryPrefix returns a copy of the given [context.Context] with the given canary
+// prefix, if any, so every payload injected is prepended with a canary (see [newCanary]).
+func withCanaryPrefix(ctx context.Context, prefix string) context.Context {
+	if len(prefix) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, canaryPrefixKey{}, prefix)
+}
+
+// newCanary returns a new canary, made of the canary prefix from the given [context.Context]
+// (see [withCanaryPrefix]) and a random (hex-encoded) suffix, so each request injected gets a
+// unique one, and the payload reflections can be attributed to it. It returns an empty string
+// if there's no canary prefix.
+func newCanary(ctx context.Context) string {
+	prefix, _ := ctx.Value(canaryPrefixKey{}).(string)
+	if len(prefix) == 0 {
+		return ""
+	}
+
+	suffix := make([]byte, canarySuffixLen)
+	_, _ = rand.Read(suffix)
+
+	return prefix + hex.EncodeToString(suffix)
+}
+
+// Canaries returns the canaries prepended to the payloads injected into the given requests
+// (see [request.Request.Canary]), if any. So, these can be reported along with the match
+// (see [MetadataCanary]), and the reflections can be traced back to the requests.
+func Canaries(reqs []*request.Request) []string {
+	var canaries []string
+	for _, req := range reqs {
+		if req == nil || len(req.Canary) == 0 || slices.In(canaries, req.Canary) {
+			continue
+		}
+
+		canaries = append(canaries, req.Canary)
+	}
+
+	return canaries
+}
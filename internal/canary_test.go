@@ -0,0 +1,96 @@
+package scan_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/profile/profilefakes"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestValidateCanaryPrefix(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		prefix string
+		valid  bool
+	}{
+		"alphanumeric":     {prefix: "gb1", valid: true},
+		"empty":            {prefix: ""},
+		"too long":         {prefix: strings.Repeat("a", 33)},
+		"symbols":          {prefix: "gb-"},
+		"quotes":           {prefix: `gb"`},
+		"non-ascii letter": {prefix: "gbñ"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := scan.ValidateCanaryPrefix(tc.prefix)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, scan.ErrInvalidCanaryPrefix)
+		})
+	}
+}
+
+func TestCanaries(t *testing.T) {
+	t.Parallel()
+
+	reqs := []*request.Request{{Canary: "gb0a1b2c3d"}, nil, {}, {Canary: "gb4e5f6a7b"}, {Canary: "gb0a1b2c3d"}}
+	assert.Equal(t, []string{"gb0a1b2c3d", "gb4e5f6a7b"}, scan.Canaries(reqs))
+	assert.Empty(t, scan.Canaries([]*request.Request{{}}))
+}
+
+func TestRunner_CanaryPrefix(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	req := request.WithOptions("http://example.com/?id=1")
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, req, nil)))
+
+	requester := &replayRequester{}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, CanaryPrefix: "gb"}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithActiveProfiles([]*profile.Active{
+			profilefakes.SQLiTimeBased(),
+		}))
+	require.NoError(t, r.Start())
+	require.NotEmpty(t, requester.reqs)
+
+	// Every request injected must carry a unique canary, prepended to the payload.
+	seen := make(map[string]struct{}, len(requester.reqs))
+	for _, req := range requester.reqs {
+		require.True(t, strings.HasPrefix(req.Canary, "gb"), req.Canary)
+		require.Contains(t, string(req.Bytes()), req.Canary)
+
+		_, dup := seen[req.Canary]
+		require.False(t, dup, req.Canary)
+		seen[req.Canary] = struct{}{}
+	}
+}
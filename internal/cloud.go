@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+const (
+	// MetadataCloudProvider is the [Match.Metadata] key that identifies the cloud provider(s)
+	// (e.g. aws) whose metadata or buckets are exposed, only set when the [profile.Profile]
+	// looks for cloud exposures (see [profile.GrepTypeCloudExposure]), and any is found.
+	MetadataCloudProvider = "cloud_provider"
+
+	// MetadataCloudField is the [Match.Metadata] key that identifies the sensitive field(s)
+	// exposed (e.g. iam/security-credentials or ListBucketResult), along with the cloud
+	// provider (see [MetadataCloudProvider]).
+	MetadataCloudField = "cloud_field"
+)
+
+// CloudExposures returns the cloud metadata and buckets (see [match.CloudSignature]) exposed
+// by the given responses, according to the Cloud Exposure greps of the given [profile.Profile]
+// (see [match.CloudExposures]), if any, and the given payload (empty if none). So, the provider
+// and the field exposed can be reported along with the match (see [MetadataCloudProvider]).
+func CloudExposures(ctx context.Context, prof profile.Profile, res []*response.Response, payload string) []match.CloudSignature {
+	if prof == nil {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeCloudExposure)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var p *string
+	if len(payload) > 0 {
+		p = &payload
+	}
+
+	var (
+		names     []string
+		exposures []match.CloudSignature
+	)
+	for _, g := range greps {
+		for _, r := range res {
+			for _, s := range match.CloudExposures(ctx, g, r, p) {
+				if !slices.In(names, s.Name) {
+					names = append(names, s.Name)
+					exposures = append(exposures, s)
+				}
+			}
+		}
+	}
+
+	return exposures
+}
+
+// cloudMetadata returns the given metadata along with the cloud providers and the
+// sensitive fields exposed (see [MetadataCloudProvider] and [MetadataCloudField]), if any.
+func cloudMetadata(metadata map[string]string, exposures []match.CloudSignature) map[string]string {
+	var providers, fields []string
+	for _, e := range exposures {
+		if !slices.In(providers, e.Provider) {
+			providers = append(providers, e.Provider)
+		}
+		if !slices.In(fields, e.Field) {
+			fields = append(fields, e.Field)
+		}
+	}
+
+	metadata = withMetadata(metadata, MetadataCloudProvider, providers)
+	metadata = withMetadata(metadata, MetadataCloudField, fields)
+
+	return metadata
+}
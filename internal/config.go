@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"io"
 	"reflect"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/match"
 )
 
 // Never obfuscate the Config type.
@@ -13,16 +16,60 @@ var _ = reflect.TypeOf(Config{})
 // It includes options to control the scanner's behavior, such as the rate of
 // requests per second, the concurrency level, and the output format.
 type Config struct {
-	RPS             int `default:"100"`
-	Concurrency     int `default:"100"`
-	Version         string
-	SaveOnStop      bool
-	InMemory        bool
-	BlindHost       string
-	BlindHostKey    string
-	EmailAddress    bool
-	CustomTokens    map[string]string
-	PayloadStrategy PayloadStrategy
+	RPS                int `default:"100"`
+	Concurrency        int `default:"100"`
+	ConcurrencyPerHost int
+	TimeBudgetPerHost  time.Duration
+	Warmup             bool
+	Version            string
+	SaveOnStop         bool
+	InMemory           bool
+	KeepStorage        bool
+	StoreAllResponses  bool
+	NoEntrypoints      bool
+	Passive            bool
+	BlindHost          string
+	BlindHostKey       string
+	EmailAddress       bool
+	CustomTokens       map[string]string
+	PayloadStrategy    PayloadStrategy
+	Metadata           map[string]string
+	MimeFilter         MimeFilter
+	ResponseFilter     ResponseFilter
+	Shard              Shard
+	TemplateFilter     TemplateFilter
+	Redirects          RedirectPolicy
+	MatchTimeout       time.Duration
+	RequestIDHeader    string
+	Signatures         []match.Signature
+	FileSignatures     []match.FileSignature
+	CloudSignatures    []match.CloudSignature
+	DebugSignatures    []match.DebugSignature
+	TechSignatures     match.TechnologySignatures
+	SeverityOverrides  SeverityOverrides
+	ResponseDiff       ResponseDiffCfg
+	RateLimit          RateLimitCfg
+	MassAssignment     MassAssignmentCfg
+	// Prioritized determines whether the templates are dispatched by priority (see [Template.Priority]),
+	// which requires all of them to be read before the first one is dispatched. So, it's only enabled
+	// when there's any priority rule, otherwise templates are dispatched as these are read.
+	Prioritized bool
+	// ResolveAllTo is the address every host is resolved to (i.e. sinkhole
+	// mode), if any, so no traffic reaches the actual hosts.
+	ResolveAllTo string
+	// CanaryPrefix is the prefix of the unique canary prepended to every payload injected,
+	// if any, so the payload reflections can be attributed to the request (see [MetadataCanary]).
+	CanaryPrefix string
+	// GraphQLIntrospection determines whether an introspection query is sent to every
+	// template's url, so the GraphQL endpoints with introspection enabled are reported
+	// (see [GraphQLIntrospectionProfile]).
+	GraphQLIntrospection bool
+	// CachePoisoning determines whether every template's request is sent with poisoned
+	// (unkeyed) headers, followed by clean ones, to detect web cache poisoning.
+	CachePoisoning CachePoisoningCfg
+	// Profiles are the names of the profiles the scan is performed with (both active
+	// and passive ones), so those with no findings can also be reported (e.g. JUnit).
+	Profiles []string
 
 	Silent           bool
 	StreamErrors     bool
@@ -33,8 +80,12 @@ type Config struct {
 	ShowAllRequests  bool
 	ShowAllResponses bool
 
-	OutPath   string
-	OutFormat string
+	Outputs     []Output
+	OutTemplate string
+	OutAppend   bool
+	Baseline    string
+	Redaction   Redaction
+	Grouping    FindingsGrouping
 }
 
 // Clone returns a deep copy of the [Config] instance.
@@ -44,17 +95,55 @@ func (c Config) Clone() Config {
 		clonedTokens[key] = value
 	}
 
+	var clonedMetadata map[string]string
+	if c.Metadata != nil {
+		clonedMetadata = make(map[string]string, len(c.Metadata))
+		for key, value := range c.Metadata {
+			clonedMetadata[key] = value
+		}
+	}
+
 	return Config{
-		RPS:             c.RPS,
-		Concurrency:     c.Concurrency,
-		Version:         c.Version,
-		SaveOnStop:      c.SaveOnStop,
-		InMemory:        c.InMemory,
-		BlindHost:       c.BlindHost,
-		BlindHostKey:    c.BlindHostKey,
-		EmailAddress:    c.EmailAddress,
-		CustomTokens:    clonedTokens,
-		PayloadStrategy: c.PayloadStrategy,
+		RPS:                c.RPS,
+		Concurrency:        c.Concurrency,
+		ConcurrencyPerHost: c.ConcurrencyPerHost,
+		TimeBudgetPerHost:  c.TimeBudgetPerHost,
+		Warmup:             c.Warmup,
+		Version:            c.Version,
+		SaveOnStop:         c.SaveOnStop,
+		InMemory:           c.InMemory,
+		KeepStorage:        c.KeepStorage,
+		StoreAllResponses:  c.StoreAllResponses,
+		NoEntrypoints:      c.NoEntrypoints,
+		Passive:            c.Passive,
+		BlindHost:          c.BlindHost,
+		BlindHostKey:       c.BlindHostKey,
+		EmailAddress:       c.EmailAddress,
+		CustomTokens:       clonedTokens,
+		PayloadStrategy:    c.PayloadStrategy,
+		Metadata:           clonedMetadata,
+		MimeFilter:         c.MimeFilter.Clone(),
+		ResponseFilter:     c.ResponseFilter.Clone(),
+		Shard:              c.Shard,
+		TemplateFilter:     c.TemplateFilter,
+		Redirects:          c.Redirects,
+		MatchTimeout:       c.MatchTimeout,
+		RequestIDHeader:    c.RequestIDHeader,
+		Signatures:         cloneSignatures(c.Signatures),
+		FileSignatures:     cloneFileSignatures(c.FileSignatures),
+		CloudSignatures:    cloneCloudSignatures(c.CloudSignatures),
+		DebugSignatures:    cloneDebugSignatures(c.DebugSignatures),
+		TechSignatures:     cloneTechnologySignatures(c.TechSignatures),
+		SeverityOverrides:  c.SeverityOverrides.Clone(),
+		ResponseDiff:       c.ResponseDiff.Clone(),
+		RateLimit:          c.RateLimit,
+		MassAssignment:     c.MassAssignment.Clone(),
+		ResolveAllTo:       c.ResolveAllTo,
+		CanaryPrefix:       c.CanaryPrefix,
+		Profiles:           append([]string(nil), c.Profiles...),
+
+		GraphQLIntrospection: c.GraphQLIntrospection,
+		CachePoisoning:       c.CachePoisoning.Clone(),
 
 		Silent:           c.Silent,
 		StreamErrors:     c.StreamErrors,
@@ -65,11 +154,24 @@ func (c Config) Clone() Config {
 		ShowAllRequests:  c.ShowAllRequests,
 		ShowAllResponses: c.ShowAllResponses,
 
-		OutPath:   c.OutPath,
-		OutFormat: c.OutFormat,
+		Outputs:     cloneOutputs(c.Outputs),
+		OutTemplate: c.OutTemplate,
+		OutAppend:   c.OutAppend,
+		Baseline:    c.Baseline,
+		Redaction:   c.Redaction.Clone(),
+		Grouping:    c.Grouping,
 	}
 }
 
+// Output defines one of the files where the scan output is written to, once
+// the scan finishes, along with the format it is written in: plain, json,
+// ndjson, markdown or template. All the outputs are written from the same
+// scan results, so a single scan can produce multiple reports at once.
+type Output struct {
+	Path   string
+	Format string
+}
+
 // BlindHostConfigured returns whether the blind host and its key are configured.
 func (c Config) BlindHostConfigured() bool {
 	return len(c.BlindHost) > 0 && len(c.BlindHostKey) > 0
@@ -93,6 +195,22 @@ func WithConcurrency(concurrency int) CfgOption {
 	}
 }
 
+// WithConcurrencyPerHost sets the concurrency level per host.
+// Zero means no limit.
+func WithConcurrencyPerHost(concurrency int) CfgOption {
+	return func(cfg *Config) {
+		cfg.ConcurrencyPerHost = concurrency
+	}
+}
+
+// WithTimeBudgetPerHost sets the wall-clock time each host is scanned for,
+// before being paused so the remaining hosts get coverage. Zero means no budget.
+func WithTimeBudgetPerHost(budget time.Duration) CfgOption {
+	return func(cfg *Config) {
+		cfg.TimeBudgetPerHost = budget
+	}
+}
+
 // WithBlindHost sets the blind host.
 func WithBlindHost(blindHost string) CfgOption {
 	return func(cfg *Config) {
@@ -219,3 +337,54 @@ func (ps PayloadStrategy) String() string {
 }
 
 const unknown = "Unknown"
+
+func cloneOutputs(outputs []Output) []Output {
+	if outputs == nil {
+		return nil
+	}
+
+	return append([]Output{}, outputs...)
+}
+
+func cloneCloudSignatures(signatures []match.CloudSignature) []match.CloudSignature {
+	if signatures == nil {
+		return nil
+	}
+
+	return append([]match.CloudSignature{}, signatures...)
+}
+
+func cloneDebugSignatures(signatures []match.DebugSignature) []match.DebugSignature {
+	if signatures == nil {
+		return nil
+	}
+
+	return append([]match.DebugSignature{}, signatures...)
+}
+
+func cloneFileSignatures(signatures []match.FileSignature) []match.FileSignature {
+	if signatures == nil {
+		return nil
+	}
+
+	return append([]match.FileSignature{}, signatures...)
+}
+
+func cloneTechnologySignatures(signatures match.TechnologySignatures) match.TechnologySignatures {
+	if signatures.Signatures == nil {
+		return signatures
+	}
+
+	return match.TechnologySignatures{
+		Version:    signatures.Version,
+		Signatures: append([]match.TechnologySignature{}, signatures.Signatures...),
+	}
+}
+
+func cloneSignatures(signatures []match.Signature) []match.Signature {
+	if signatures == nil {
+		return nil
+	}
+
+	return append([]match.Signature{}, signatures...)
+}
@@ -0,0 +1,40 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// WithCORSOrigin decorates the given [RequesterBuilder], so every request sent through the
+// built [Requester] carries the Origin header, set to the given (attacker-controlled) origin
+// (e.g. https://evil.example), unless it already carries one, in any case (e.g. injected by a profile).
+// So, the CORS Misconfiguration greps (see [profile.GrepTypeCORSMisconfig]) with no origins
+// defined look for that origin reflected, along with credentials allowed.
+//
+// The header is set into the given request, so it is also present on the requests
+// attached to the scan results, e.g. [Match.Requests].
+func WithCORSOrigin(fn RequesterBuilder, origin string) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return corsOriginRequester{Requester: requester, origin: origin}, nil
+	}
+}
+
+type corsOriginRequester struct {
+	Requester
+	origin string
+}
+
+func (r corsOriginRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	if !hasHeader(req, "Origin") {
+		req.SetHeader("Origin", r.origin)
+	}
+
+	return r.Requester.Do(ctx, req)
+}
@@ -0,0 +1,56 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestWithCORSOrigin(t *testing.T) {
+	t.Parallel()
+
+	const origin = "https://evil.example"
+
+	builder := scan.WithCORSOrigin(func() (scan.Requester, error) {
+		return &recordingRequester{}, nil
+	}, origin)
+
+	r, err := builder()
+	require.NoError(t, err)
+
+	// The origin is set, unless the request already carries one (in any case).
+	req := request.Default("http://example.org/")
+	_, err = r.Do(context.Background(), &req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{origin}, req.Headers["Origin"])
+
+	injected := request.Default("http://example.org/")
+	injected.SetHeader("origin", "https://other.example")
+	_, err = r.Do(context.Background(), &injected)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://other.example"}, injected.Headers["origin"])
+	assert.NotContains(t, injected.Headers, "Origin")
+
+	// So, the CORS Misconfiguration greps with no origins defined look for it reflected.
+	res := response.Response{
+		Proto:  "HTTP/1.1",
+		Code:   200,
+		Status: "OK",
+		Headers: map[string][]string{
+			"Access-Control-Allow-Origin":      {origin},
+			"Access-Control-Allow-Credentials": {"true"},
+		},
+	}
+
+	cors := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,CORS Misconfiguration,,"}}
+	ok, _ := match.Match(context.Background(), match.Data{Profile: cors, Request: &req, Response: &res})
+	assert.True(t, ok)
+}
@@ -0,0 +1,134 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+// RequestCount is the planned request volume of a scan (see [CountRequests]),
+// broken down by host and by (active) profile.
+//
+// Note that the follow-up steps of multi-step profiles are only sent if the
+// previous step matches, so those requests aren't part of the count.
+type RequestCount struct {
+	Total       int
+	Templates   int
+	Entrypoints int
+	ByHost      map[string]int
+	ByProfile   map[string]int
+}
+
+func (rc *RequestCount) add(host, profileName string, n int) {
+	if n == 0 {
+		return
+	}
+
+	rc.Total += n
+	rc.ByHost[host] += n
+	if len(profileName) > 0 {
+		rc.ByProfile[profileName] += n
+	}
+}
+
+// CountRequests calculates the amount of requests the scan defined by the given [Config]
+// would send, for the templates stored in the given [FileSystem], with the given entrypoint
+// finders and active profiles, with no requests sent. It follows the same logic as the scan
+// itself (see [countTemplateRequests]).
+func CountRequests(
+	ctx context.Context,
+	fs FileSystem,
+	cfg Config,
+	finders []entrypoint.Finder,
+	actives []*profile.Active,
+) (RequestCount, error) {
+	count := RequestCount{ByHost: make(map[string]int), ByProfile: make(map[string]int)}
+	hostReqs := newHostRequests()
+
+	templates, err := fs.TemplatesIterator(ctx)
+	if err != nil {
+		return count, err
+	}
+
+	for tpl := range shard(ctx, templates, cfg.Shard) {
+		// Filtered templates are skipped during dispatch, so no requests are sent.
+		if !cfg.TemplateFilter.Allows(ctx, tpl) {
+			continue
+		}
+
+		count.Templates++
+
+		// Passive templates are analyzed only, with no requests sent.
+		if tpl.Response != nil {
+			continue
+		}
+
+		host := templateHost(tpl)
+		count.Entrypoints += countTemplateRequests(ctx, cfg, tpl, finders, actives, hostReqs, func(profileName string, n int, _ bool) {
+			count.add(host, profileName, n)
+		})
+	}
+
+	return count, ctx.Err()
+}
+
+// countTemplateRequests calculates the amount of requests sent to scan the given (active) [Template],
+// as defined by the given [Config], with the given entrypoint finders and active profiles, and returns
+// the amount of entrypoints found. It is shared by the scan itself (see [Runner.calculateTemplateTasks])
+// and by [CountRequests], so both count the same requests.
+//
+// The given function is called with the requests of each active profile, along with whether any was
+// skipped (e.g. due to a missing blind host), and with those sent regardless of the profiles (e.g. the
+// rate limit burst, or the request sent as is, with no entrypoints) with no profile name.
+func countTemplateRequests(
+	ctx context.Context,
+	cfg Config,
+	tpl Template,
+	finders []entrypoint.Finder,
+	actives []*profile.Active,
+	hostReqs *hostRequests,
+	fn func(profileName string, n int, skipped bool),
+) int {
+	var probes int
+
+	if cfg.ResponseDiff.Enabled { // Is diffed? (both variants sent)
+		probes += 2
+	}
+
+	if cfg.RateLimit.Applies(tpl, actives) { // Is probed? (burst sent)
+		probes += cfg.RateLimit.Burst
+	}
+
+	if cfg.MassAssignment.Applies(tpl) { // Is probed? (both baselines and every variant sent)
+		probes += 2 + len(cfg.MassAssignment.Variants(tpl.Request))
+	}
+
+	if cfg.CachePoisoning.Applies(tpl) { // Is probed? (both poisoned and clean requests sent, per header)
+		probes += 2 * len(cfg.CachePoisoning.Headers)
+	}
+
+	if cfg.GraphQLIntrospection { // Is probed? (introspection query sent)
+		probes++
+	}
+
+	if cfg.NoEntrypoints { // Is raw? (request sent as is)
+		probes++
+	}
+
+	fn("", probes, false)
+
+	if cfg.NoEntrypoints {
+		return 0
+	}
+
+	lineOfWork := &LineOfWork{Template: tpl, Matches: make(map[string]struct{})}
+	entrypoints := lineOfWork.findEntrypoints(ctx, finders)
+
+	for _, prof := range actives {
+		numTasksPrepared, skipped := lineOfWork.prepareTasks(ctx, prof, len(cfg.BlindHost) > 0, cfg.EmailAddress, hostReqs)
+		fn(prof.Name, numTasksPrepared, skipped)
+	}
+
+	return entrypoints
+}
@@ -0,0 +1,142 @@
+package scan_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestCountRequests(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, request.WithOptions("http://example.com/a?x=1"), nil)))
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 1, request.WithOptions("http://example.org/b"), nil)))
+	// Passive templates send no requests.
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 2, request.WithOptions("http://example.org/c"), &response.Response{Code: 200})))
+
+	actives := []*profile.Active{
+		{
+			Name:    "User-Agent",
+			Enabled: true,
+			Type:    profile.TypeActive,
+			Steps: []profile.Step{{
+				Payloads:        []string{"true,one", "true,two", "false,disabled"},
+				InsertionPoints: []profile.InsertionPointType{profile.HeaderUserAgent},
+			}},
+		},
+		{
+			Name:    "Query",
+			Enabled: true,
+			Type:    profile.TypeActive,
+			Steps: []profile.Step{{
+				Payloads:        []string{"true,one"},
+				InsertionPoints: []profile.InsertionPointType{profile.ParamURLValue},
+			}},
+		},
+	}
+
+	t.Run("with entrypoints", func(t *testing.T) {
+		t.Parallel()
+
+		count, err := scan.CountRequests(ctx, fs, scan.Config{}, entrypoint.Finders(), actives)
+		require.NoError(t, err)
+
+		require.Equal(t, 3, count.Templates)
+		require.Equal(t, 5, count.Total)
+		require.Equal(t, map[string]int{"example.com": 3, "example.org": 2}, count.ByHost)
+		require.Equal(t, map[string]int{"User-Agent": 4, "Query": 1}, count.ByProfile)
+	})
+
+	t.Run("with no entrypoints", func(t *testing.T) {
+		t.Parallel()
+
+		count, err := scan.CountRequests(ctx, fs, scan.Config{NoEntrypoints: true}, entrypoint.Finders(), actives)
+		require.NoError(t, err)
+
+		require.Equal(t, 3, count.Templates)
+		require.Equal(t, 2, count.Total)
+		require.Equal(t, 0, count.Entrypoints)
+		require.Equal(t, map[string]int{"example.com": 1, "example.org": 1}, count.ByHost)
+		require.Empty(t, count.ByProfile)
+	})
+}
+
+func TestCountRequests_Runner(t *testing.T) {
+	t.Parallel()
+
+	actives := []*profile.Active{{
+		Name:    "User-Agent",
+		Enabled: true,
+		Type:    profile.TypeActive,
+		Steps: []profile.Step{{
+			Payloads:        []string{"true,one", "true,two"},
+			InsertionPoints: []profile.InsertionPointType{profile.HeaderUserAgent},
+		}},
+	}}
+
+	tcs := map[string]scan.Config{
+		"none":            {},
+		"no entrypoints":  {NoEntrypoints: true},
+		"response diff":   {ResponseDiff: scan.ResponseDiffCfg{Enabled: true, B: scan.ResponseVariant{Headers: [][2]string{{"X-Role", "admin"}}}}},
+		"rate limit":      {RateLimit: scan.RateLimitCfg{Burst: 5, Match: regexp.MustCompile(`/0$`)}},
+		"mass assignment": {MassAssignment: scan.MassAssignmentCfg{Enabled: true, Params: scan.DefaultMassAssignmentParams}},
+		"cache poisoning": {CachePoisoning: scan.CachePoisoningCfg{Enabled: true, Headers: []string{"X-Forwarded-Host", "X-Host"}}},
+		"graphql":         {GraphQLIntrospection: true},
+	}
+
+	for name, cfg := range tcs {
+		name, cfg := name, cfg
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			aferoFs, basePath := initializeFsTest()
+			fs, err := filesystem.New(aferoFs, basePath)
+			require.NoError(t, err)
+
+			require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, request.WithOptions("http://example.com/a?x=1"), nil)))
+			require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 1, request.WithOptions("http://example.org/b"), nil)))
+
+			count, err := scan.CountRequests(ctx, fs, cfg, entrypoint.Finders(), actives)
+			require.NoError(t, err)
+
+			cfg.RPS, cfg.Concurrency = 100, 1
+			requester := &countingRequester{res: response.Response{Code: 200}}
+
+			var stats *scan.Stats
+
+			r := scan.NewRunner((&scan.RunnerOpts{}).
+				WithContext(ctx).
+				WithConfiguration(cfg).
+				WithRequesterBuilder(func() (scan.Requester, error) {
+					return requester, nil
+				}).
+				WithFileSystem(fs).
+				WithEntrypointFinders(entrypoint.Finders()).
+				WithActiveProfiles(actives).
+				WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+			require.NoError(t, r.Start())
+
+			// The count must match both the requests planned and those sent by the scan.
+			require.Positive(t, count.Total)
+			require.Equal(t, stats.NumOfTotalRequests, count.Total)
+			require.Equal(t, int(requester.count.Load()), count.Total)
+		})
+	}
+}
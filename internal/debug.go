@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+const (
+	// MetadataDebugSurface is the [Match.Metadata] key that identifies the debug surface(s)
+	// (e.g. go-pprof) exposed, only set when the [profile.Profile] looks for debug exposures
+	// (see [profile.GrepTypeDebugExposure]), and any is found.
+	MetadataDebugSurface = "debug_surface"
+
+	// MetadataDebugEndpoint is the [Match.Metadata] key that identifies the endpoint(s)
+	// (i.e. the request path) the debug surface (see [MetadataDebugSurface]) is exposed at.
+	MetadataDebugEndpoint = "debug_endpoint"
+)
+
+// DebugExposures returns the debug surfaces (see [match.DebugSignature]) exposed by the given
+// responses, according to the Debug Exposure greps of the given [profile.Profile] (see
+// [match.DebugExposures]), if any, along with the paths of the requests those were exposed at.
+// So, the surface and the endpoint exposed can be reported along with the match.
+func DebugExposures(
+	ctx context.Context,
+	prof profile.Profile,
+	reqs []*request.Request,
+	res []*response.Response,
+) ([]match.DebugSignature, []string) {
+	if prof == nil {
+		return nil, nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeDebugExposure)
+	if len(greps) == 0 {
+		return nil, nil
+	}
+
+	var (
+		names     []string
+		exposures []match.DebugSignature
+		endpoints []string
+	)
+	for _, g := range greps {
+		for i, r := range res {
+			var req *request.Request
+			if i < len(reqs) {
+				req = reqs[i]
+			}
+
+			found := match.DebugExposures(ctx, g, req, r)
+			if len(found) > 0 && req != nil && !slices.In(endpoints, req.Path) {
+				endpoints = append(endpoints, req.Path)
+			}
+
+			for _, s := range found {
+				if !slices.In(names, s.Name) {
+					names = append(names, s.Name)
+					exposures = append(exposures, s)
+				}
+			}
+		}
+	}
+
+	return exposures, endpoints
+}
+
+// debugMetadata returns the given metadata along with the debug surfaces and the endpoints
+// exposed (see [MetadataDebugSurface] and [MetadataDebugEndpoint]), if any (see [DebugExposures]).
+func debugMetadata(
+	ctx context.Context,
+	metadata map[string]string,
+	prof profile.Profile,
+	reqs []*request.Request,
+	res []*response.Response,
+) map[string]string {
+	exposures, endpoints := DebugExposures(ctx, prof, reqs, res)
+
+	names := make([]string, 0, len(exposures))
+	for _, e := range exposures {
+		names = append(names, e.Name)
+	}
+
+	metadata = withMetadata(metadata, MetadataDebugSurface, names)
+	metadata = withMetadata(metadata, MetadataDebugEndpoint, endpoints)
+
+	return metadata
+}
@@ -0,0 +1,44 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestMatchMetadata_DebugExposure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	reqs := []*request.Request{
+		{Method: "GET", Path: "/debug/pprof/", Proto: "HTTP/1.1"},
+		{Method: "GET", Path: "/debug/vars", Proto: "HTTP/1.1"},
+	}
+
+	res := []*response.Response{
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte(`<title>/debug/pprof/</title>`)},
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte(`{"cmdline": ["/app"], "memstats": {}}`)},
+	}
+
+	debug := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Debug Exposure,,"}}
+	metadata := scan.MatchMetadata(ctx, map[string]string{"team": "red"}, debug, reqs, res, "")
+	assert.Equal(t, map[string]string{"team": "red", scan.MetadataDebugSurface: "go-pprof, go-expvar", scan.MetadataDebugEndpoint: "/debug/pprof/, /debug/vars"}, metadata)
+
+	// The same content, at a path other than the signature's, isn't reported.
+	other := []*request.Request{{Method: "GET", Path: "/blog/pprof", Proto: "HTTP/1.1"}}
+	metadata = scan.MatchMetadata(ctx, nil, debug, other, res[:1], "")
+	assert.Empty(t, metadata)
+
+	// Only profiles looking for debug exposures report those.
+	simple := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Simple String,,pprof"}}
+	exposures, endpoints := scan.DebugExposures(ctx, simple, reqs, res)
+	assert.Empty(t, exposures)
+	assert.Empty(t, endpoints)
+}
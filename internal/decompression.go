@@ -0,0 +1,26 @@
+package scan
+
+import (
+	"strconv"
+
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// MetadataDecompressionTruncated is the [Match.Metadata] key that identifies the responses (by their
+// position, 1-based) whose body was truncated while being decompressed, as it exceeded the maximum
+// decompressed size (see [response.Response.DecompressionTruncated]), so the match is reported along
+// with the anomaly (e.g. a decompression bomb), as it may have been missed beyond the truncation.
+const MetadataDecompressionTruncated = "decompression_truncated"
+
+// DecompressionTruncated returns the positions (1-based) of the given responses whose body
+// was truncated while being decompressed, if any (see [MetadataDecompressionTruncated]).
+func DecompressionTruncated(res []*response.Response) []string {
+	var positions []string
+	for i, r := range res {
+		if r != nil && r.DecompressionTruncated {
+			positions = append(positions, strconv.Itoa(i+1))
+		}
+	}
+
+	return positions
+}
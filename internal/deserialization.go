@@ -0,0 +1,46 @@
+package scan
+
+import (
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// MetadataDeserialization is the [Match.Metadata] key that identifies the serialization format(s)
+// (e.g. java) detected, only set when the [profile.Profile] looks for insecure deserialization
+// (see [profile.GrepTypeDeserialization]), and any telltale sign is found.
+const MetadataDeserialization = "deserialization_format"
+
+// DeserializationFormats returns the serialization formats (e.g. java or php) detected within the
+// given responses, according to the Deserialization greps of the given [profile.Profile] (see
+// [match.DeserializationFormats]), if any, and the given payload (empty if none). So, these
+// can be reported along with the match (see [MetadataDeserialization]).
+func DeserializationFormats(prof profile.Profile, res []*response.Response, payload string) []string {
+	if prof == nil {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeDeserialization)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var p *string
+	if len(payload) > 0 {
+		p = &payload
+	}
+
+	var formats []string
+	for _, g := range greps {
+		for _, r := range res {
+			for _, f := range match.DeserializationFormats(g, r, p) {
+				if !slices.In(formats, f) {
+					formats = append(formats, f)
+				}
+			}
+		}
+	}
+
+	return formats
+}
@@ -0,0 +1,316 @@
+package scan
+
+import (
+	"container/heap"
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/panics"
+)
+
+// dispatcher sits between the templates iterator and the workers pool, and
+// makes sure that no more than perHost templates targeting the same host are
+// being scanned at the same time.
+//
+// Templates whose host is already at its cap are held back, so the next template
+// targeting any other host is dispatched first. That way, slow hosts (or those
+// with many templates) don't stall the rest, while the order of the templates
+// (e.g. their [Template.Priority]) is preserved as much as possible.
+//
+// Additionally, if a time budget per host is set, hosts that have been scanned for
+// longer than it are paused, so the remaining hosts get coverage first. Once every
+// host pending is paused, all of them get another budget (i.e. the leftover time
+// is shared in rounds), until every template has been dispatched.
+//
+// If a backlog is set, no more than backlog templates are held back at the same time,
+// so the templates iterator is only read when there's room (i.e. back-pressure), which
+// keeps the memory bounded, even when a single host holds back all of them (i.e. the
+// per-host queues are bounded by the backlog as a whole).
+type dispatcher struct {
+	out      chan Template
+	released chan string
+	exited   chan struct{}
+	clock    *hostClock
+}
+
+// dispatch takes a channel of [Template] and returns a [dispatcher] that yields
+// the same templates (see [dispatcher.templates]), but bounded by perHost, and
+// paced by the given time budget per host.
+//
+// Every template yielded must be reported back as finished with [dispatcher.done],
+// so the host's slot is released. If perHost is zero (or negative), no bound is applied.
+// Similarly, if budget is zero (or negative), hosts are never paused, and if backlog is
+// zero (or negative), templates are read from the given channel as soon as available.
+func dispatch(ctx context.Context, in chan Template, perHost int, budget time.Duration, backlog int) *dispatcher {
+	d := &dispatcher{
+		out:      make(chan Template),
+		released: make(chan string),
+		exited:   make(chan struct{}),
+		clock:    newHostClock(),
+	}
+
+	go func() {
+		defer panics.Log(ctx)
+		defer close(d.exited)
+		defer close(d.out)
+
+		var (
+			queues = make(map[string][]queuedTemplate)
+			active = make(map[string]int)
+			ready  = make(readyHosts, 0)
+			seq    int
+			rounds = 1
+			queued int
+		)
+
+		// A host is paused when it has been scanned for longer
+		// than the time budget given so far (i.e. one per round).
+		paused := func(host string) bool {
+			return budget > 0 && d.clock.elapsed(host) >= time.Duration(rounds)*budget
+		}
+
+		// A host is ready (i.e. present in the ready heap) when it has
+		// templates pending, and it isn't at its cap, nor paused.
+		available := func(host string) bool {
+			return len(queues[host]) > 0 && (perHost <= 0 || active[host] < perHost) && !paused(host)
+		}
+
+		for in != nil || len(queues) > 0 {
+			var (
+				out  chan Template
+				next Template
+				recv = in
+			)
+
+			// Once the backlog is full, no more templates are read until any is dispatched.
+			full := backlog > 0 && queued >= backlog
+			if full {
+				recv = nil
+			}
+
+			// Hosts may have been paused since those were pushed,
+			// as their time budget is consumed while being scanned.
+			for ready.Len() > 0 && paused(ready[0].host) {
+				host := heap.Pop(&ready).(readyHost).host //nolint:forcetypeassert
+				logger.For(ctx).Debugf("Host %s paused, its time budget (%s) has been consumed", host, time.Duration(rounds)*budget)
+			}
+
+			// Once all the templates have been received (or the backlog is full), and every host
+			// pending is paused, another round (i.e. another time budget per host) is started.
+			// Rounds with no host to resume (i.e. all still over budget) are skipped.
+			if (in == nil || full) && ready.Len() == 0 && len(queues) > 0 && allPaused(queues, paused) {
+				rounds = nextRound(queues, d.clock, budget)
+				logger.For(ctx).Debugf("All the hosts pending are paused, starting time budget round: %d", rounds)
+
+				for host := range queues {
+					if available(host) {
+						heap.Push(&ready, readyHost{host: host, seq: queues[host][0].seq})
+					}
+				}
+			}
+
+			if ready.Len() > 0 {
+				out, next = d.out, queues[ready[0].host][0].tpl
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case tpl, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+
+				host := templateHost(tpl)
+				queues[host] = append(queues[host], queuedTemplate{tpl: tpl, seq: seq})
+				seq++
+				queued++
+
+				if len(queues[host]) == 1 && available(host) {
+					heap.Push(&ready, readyHost{host: host, seq: queues[host][0].seq})
+				}
+			case out <- next:
+				host := heap.Pop(&ready).(readyHost).host //nolint:forcetypeassert
+				active[host]++
+				queued--
+				d.clock.start(host)
+
+				if queues[host] = queues[host][1:]; len(queues[host]) == 0 {
+					delete(queues, host)
+				} else if available(host) {
+					heap.Push(&ready, readyHost{host: host, seq: queues[host][0].seq})
+				}
+			case host := <-d.released:
+				if active[host]--; active[host] == 0 {
+					delete(active, host)
+				}
+
+				// It was at its cap, so it wasn't ready.
+				if perHost > 0 && active[host] == perHost-1 && available(host) {
+					heap.Push(&ready, readyHost{host: host, seq: queues[host][0].seq})
+				}
+			}
+		}
+
+		logger.For(ctx).Debug("All the templates have been dispatched")
+	}()
+
+	return d
+}
+
+// templates returns the channel the dispatched templates are yielded through.
+func (d *dispatcher) templates() chan Template {
+	return d.out
+}
+
+// done reports the given [Template] (previously yielded) as finished,
+// releasing its host's slot. It never blocks once the dispatcher has exited.
+func (d *dispatcher) done(tpl Template) {
+	host := templateHost(tpl)
+	d.clock.stop(host)
+
+	select {
+	case d.released <- host:
+	case <-d.exited:
+	}
+}
+
+// timeSpent returns the wall-clock time each host has been scanned for.
+func (d *dispatcher) timeSpent() map[string]time.Duration {
+	return d.clock.snapshot()
+}
+
+// nextRound returns the first round (i.e. amount of time budgets) in which any of
+// the hosts with templates queued is no longer paused, according to the given clock.
+func nextRound(queues map[string][]queuedTemplate, clock *hostClock, budget time.Duration) int {
+	next := -1
+	for host := range queues {
+		if round := int(clock.elapsed(host)/budget) + 1; next < 0 || round < next {
+			next = round
+		}
+	}
+	return next
+}
+
+// allPaused returns whether all the hosts with templates queued are paused.
+func allPaused(queues map[string][]queuedTemplate, paused func(string) bool) bool {
+	for host := range queues {
+		if !paused(host) {
+			return false
+		}
+	}
+	return true
+}
+
+// templateHost returns the (lowercase) host targeted by the given [Template].
+// If the host cannot be determined, it falls back to the template's URL.
+func templateHost(tpl Template) string {
+	u, err := url.Parse(tpl.URL)
+	if err != nil || len(u.Hostname()) == 0 {
+		return strings.ToLower(tpl.URL)
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+type readyHost struct {
+	host string
+	seq  int
+}
+
+// readyHosts implements [heap.Interface], as a priority queue of hosts,
+// sorted by the arrival order of their earliest pending template.
+type readyHosts []readyHost
+
+func (q readyHosts) Len() int { return len(q) }
+
+func (q readyHosts) Less(i, j int) bool { return q[i].seq < q[j].seq }
+
+func (q readyHosts) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *readyHosts) Push(x any) {
+	*q = append(*q, x.(readyHost)) //nolint:forcetypeassert
+}
+
+func (q *readyHosts) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// hostClock keeps track of the wall-clock time each host has been scanned for,
+// that is, while at least one template targeting it is being scanned. So,
+// templates scanned concurrently for the same host don't add up.
+type hostClock struct {
+	mu     sync.Mutex
+	active map[string]int
+	since  map[string]time.Time
+	spent  map[string]time.Duration
+}
+
+func newHostClock() *hostClock {
+	return &hostClock{
+		active: make(map[string]int),
+		since:  make(map[string]time.Time),
+		spent:  make(map[string]time.Duration),
+	}
+}
+
+func (c *hostClock) start(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.active[host]++; c.active[host] == 1 {
+		c.since[host] = time.Now()
+	}
+}
+
+func (c *hostClock) stop(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.active[host]--; c.active[host] == 0 {
+		c.spent[host] += time.Since(c.since[host])
+		delete(c.active, host)
+		delete(c.since, host)
+	}
+}
+
+// elapsed returns the time the given host has been scanned for,
+// including the ongoing period, if it is being scanned.
+func (c *hostClock) elapsed(host string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.elapsedLocked(host)
+}
+
+func (c *hostClock) elapsedLocked(host string) time.Duration {
+	elapsed := c.spent[host]
+	if since, ok := c.since[host]; ok {
+		elapsed += time.Since(since)
+	}
+	return elapsed
+}
+
+func (c *hostClock) snapshot() map[string]time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]time.Duration, len(c.spent)+len(c.since))
+	for host := range c.spent {
+		snapshot[host] = c.elapsedLocked(host)
+	}
+	for host := range c.since {
+		snapshot[host] = c.elapsedLocked(host)
+	}
+
+	return snapshot
+}
@@ -0,0 +1,65 @@
+//nolint:testpackage
+package scan
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestDispatch_Backlog(t *testing.T) {
+	t.Parallel()
+
+	const (
+		total   = 100
+		backlog = 5
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		in   = make(chan Template)
+		sent atomic.Int64
+	)
+
+	go func() {
+		defer close(in)
+
+		for idx := 0; idx < total; idx++ {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- NewTemplate(ctx, idx, request.WithOptions(fmt.Sprintf("http://example.com/%d", idx)), nil):
+				sent.Add(1)
+			}
+		}
+	}()
+
+	d := dispatch(ctx, in, 1, 0, backlog)
+
+	// The host is at its cap, so the rest of the templates are held back,
+	// but no more than the backlog, regardless of how many are pending.
+	first := <-d.templates()
+	time.Sleep(50 * time.Millisecond)
+	assert.LessOrEqual(t, sent.Load(), int64(1+backlog))
+
+	// Once released, all the remaining templates are dispatched, in order.
+	d.done(first)
+
+	idx := 1
+	for tpl := range d.templates() {
+		require.Equal(t, idx, tpl.Idx)
+		d.done(tpl)
+		idx++
+	}
+
+	assert.Equal(t, total, idx)
+}
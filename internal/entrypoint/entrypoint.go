@@ -28,11 +28,13 @@ type Finder interface {
 // within the `entrypoint` package.
 func Finders() []Finder {
 	return []Finder{
+		NewBase64Finder(),
 		NewBodyParamFinder(),
 		NewCookieFinder(),
 		NewEntireBodyFinder(),
 		NewHeaderFinder(),
 		NewJSONParamFinder(),
+		NewJWTFinder(),
 		NewMultipartFinder(),
 		NewPathFinder(),
 		NewQueryFinder(),
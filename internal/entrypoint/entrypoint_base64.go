@@ -0,0 +1,63 @@
+package entrypoint
+
+import (
+	"encoding/gob"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func init() {
+	gob.Register(Base64{})
+}
+
+// Base64 must implement the Entrypoint interface.
+var _ Entrypoint = Base64{}
+
+// Base64 represents an entrypoint within a base64-encoded param value (see [Base64Finder]).
+// It wraps both the Outer entrypoint (i.e. the param whose value is base64-encoded), and the
+// Inner one (i.e. the JSON or body param within the Decoded value), if any. Otherwise, the
+// Decoded value is used as a whole.
+//
+// Payloads are injected into the Decoded value, which is then re-encoded the same
+// way it was originally encoded, and finally set as the Outer param value.
+type Base64 struct {
+	Outer    Entrypoint
+	Inner    Entrypoint
+	Decoded  string
+	Encoding base64Encoding
+	baseEntrypoint
+}
+
+func newBase64(outer Entrypoint, enc base64Encoding, decoded string, inner Entrypoint) Base64 {
+	param, value := outer.Param(""), decoded
+	if inner != nil {
+		param, value = inner.Param(""), inner.Value()
+	}
+
+	return Base64{
+		Outer:          outer,
+		Inner:          inner,
+		Decoded:        decoded,
+		Encoding:       enc,
+		baseEntrypoint: baseEntrypoint{P: param, V: value, IPT: profile.ParamEncodedValue},
+	}
+}
+
+func (e Base64) Param(payload string) string {
+	if e.Inner == nil {
+		return e.Outer.Param(payload) + " (base64)"
+	}
+
+	return e.Inner.Param(payload) + " (base64: " + e.Outer.Param(payload) + ")"
+}
+
+func (e Base64) InjectPayload(req request.Request, pos profile.PayloadPosition, payload string) request.Request {
+	decoded := positioned(pos, e.Decoded, payload)
+	if e.Inner != nil {
+		injected := e.Inner.InjectPayload(request.Request{Body: []byte(e.Decoded)}, pos, payload)
+		decoded = string(injected.Body)
+	}
+
+	return e.Outer.InjectPayload(req, profile.Replace, e.Encoding.encode(decoded))
+}
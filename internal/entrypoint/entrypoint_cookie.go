@@ -54,9 +54,7 @@ func (e Cookie) Param(payload string) string {
 
 func (e Cookie) InjectPayload(req request.Request, pos profile.PayloadPosition, payload string) request.Request {
 	injReq := req.Clone()
-	injReq.Headers["Cookie"] = []string{
-		strings.Replace(e.Base, cookieReplace, e.inject(pos, payload), 1),
-	}
+	injReq.SetHeader("Cookie", strings.Replace(e.Base, cookieReplace, e.inject(pos, payload), 1))
 
 	return injReq
 }
@@ -34,7 +34,7 @@ func (e CustomHeader) Param(_ string) string {
 
 func (e CustomHeader) InjectPayload(req request.Request, _ profile.PayloadPosition, payload string) request.Request {
 	clone := req.Clone()
-	clone.Headers[e.HeaderKey] = append(clone.Headers[e.HeaderKey], payload)
+	clone.SetHeader(e.HeaderKey, append(clone.Headers[e.HeaderKey], payload)...)
 
 	return clone
 }
@@ -31,6 +31,7 @@ func TestCustomHeader_InjectPayload(t *testing.T) {
 					"Content-Type": {"application/json"},
 					"Origin":       {"localhost:8080"},
 				},
+				HeaderOrder: []string{"Origin"},
 			},
 		},
 		"existing header": {
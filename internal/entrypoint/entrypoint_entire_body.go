@@ -32,7 +32,7 @@ func newEntireBody(ipt profile.InsertionPointType, original []byte) EntireBody {
 func (e EntireBody) InjectPayload(req request.Request, pos profile.PayloadPosition, payload string) request.Request {
 	injReq := req.Clone()
 	injReq.SetBody([]byte(e.inject(pos, payload)))
-	injReq.Headers["Content-Type"] = []string{e.contentType(payload)}
+	injReq.SetHeader("Content-Type", e.contentType(payload))
 	return injReq
 }
 
@@ -0,0 +1,104 @@
+package entrypoint
+
+import (
+	"encoding/gob"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/jwt"
+)
+
+const (
+	jwtHeaderSegment  = "header"
+	jwtPayloadSegment = "payload"
+)
+
+func init() {
+	gob.Register(JWT{})
+}
+
+// JWT must implement the Entrypoint interface.
+var _ Entrypoint = JWT{}
+
+// JWT represents a JSON Web Token (JWT) claim entrypoint.
+// It is used to inject payloads into the claims (either from the
+// header or from the payload) of a JWT sent as a bearer token.
+//
+// Once injected, the token is re-encoded. If the "alg" header claim
+// is set to "none", the signature is dropped. Otherwise, the original
+// signature is kept (see the [modifier.JWTSigner] to re-sign it).
+type JWT struct {
+	HeaderKey string
+	Prefix    string
+	Token     string
+	Segment   string
+	baseEntrypoint
+}
+
+func newJWT(headerKey, prefix, token, segment, claim, value string) JWT {
+	return JWT{
+		HeaderKey:      headerKey,
+		Prefix:         prefix,
+		Token:          token,
+		Segment:        segment,
+		baseEntrypoint: baseEntrypoint{P: claim, V: value, IPT: profile.ParamJWTClaim},
+	}
+}
+
+func (e JWT) Param(_ string) string {
+	return e.P + " (jwt " + e.Segment + " claim)"
+}
+
+func (e JWT) InjectPayload(req request.Request, pos profile.PayloadPosition, payload string) request.Request {
+	tok, err := jwt.Parse(e.Token)
+	if err != nil {
+		return req.Clone()
+	}
+
+	tok = tok.Clone()
+	if e.Segment == jwtHeaderSegment {
+		tok.Header.Data[e.P] = e.inject(pos, payload)
+	} else {
+		tok.Payload.Data[e.P] = e.inject(pos, payload)
+	}
+
+	encoded, err := tok.Encode("")
+	if err != nil {
+		return req.Clone()
+	}
+
+	clone := req.Clone()
+	for i, val := range clone.Headers[e.HeaderKey] {
+		clone.Headers[e.HeaderKey][i] = strings.Replace(val, e.Prefix+e.Token, e.Prefix+encoded, 1)
+	}
+
+	return clone
+}
+
+func (e JWT) inject(pos profile.PayloadPosition, payload string) string {
+	switch pos {
+	case profile.Replace:
+		return e.replace(payload)
+	case profile.Append:
+		return e.append(payload)
+	case profile.Insert:
+		return e.insert(payload)
+	default:
+		return payload
+	}
+}
+
+func (e JWT) replace(payload string) string {
+	return payload
+}
+
+func (e JWT) append(payload string) string {
+	return e.V + payload
+}
+
+func (e JWT) insert(payload string) string {
+	mid := len(e.V) / half
+
+	return e.V[:mid] + payload + e.V[mid:]
+}
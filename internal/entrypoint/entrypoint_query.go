@@ -23,13 +23,11 @@ type Query struct {
 	baseEntrypoint
 }
 
-func newQueryKey(prefix, value, suffix string) Query {
-	// For query keys, we use the V as the P as well.
-	return newQuery(profile.ParamURLName, prefix, value, value, suffix)
-}
-
-func newQueryValue(prefix, param, value, suffix string) Query {
-	return newQuery(profile.ParamURLValue, prefix, param, value, suffix)
+// newQueryFrom builds a [Query] entrypoint from the given query [InsertionPoint],
+// either a key ([profile.ParamURLName]) or a value ([profile.ParamURLValue]).
+func newQueryFrom(ip InsertionPoint) Query {
+	// For query keys, the param is the key itself (i.e. the original value).
+	return newQuery(ip.Type, ip.Prefix(), ip.Name, ip.Original, ip.Suffix())
 }
 
 func newQuery(ipt profile.InsertionPointType, prefix, param, value, suffix string) Query {
@@ -59,27 +57,9 @@ func (e Query) InjectPayload(req request.Request, pos profile.PayloadPosition, p
 
 func (e Query) inject(pos profile.PayloadPosition, payload string) string {
 	switch pos {
-	case profile.Replace:
-		return e.replace(payload)
-	case profile.Append:
-		return e.append(payload)
-	case profile.Insert:
-		return e.insert(payload)
+	case profile.Replace, profile.Append, profile.Insert:
+		return e.Prefix + positioned(pos, e.V, payload) + e.Suffix
 	default:
 		return payload
 	}
 }
-
-func (e Query) replace(payload string) string {
-	return e.Prefix + payload + e.Suffix
-}
-
-func (e Query) append(payload string) string {
-	return e.Prefix + e.V + payload + e.Suffix
-}
-
-func (e Query) insert(payload string) string {
-	mid := len(e.V) / half
-
-	return e.Prefix + e.V[:mid] + payload + e.V[mid:] + e.Suffix
-}
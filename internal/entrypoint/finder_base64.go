@@ -0,0 +1,196 @@
+package entrypoint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// Base64Finder must implement the Finder interface.
+var _ Finder = Base64Finder{}
+
+// Base64Finder is used to find entrypoints within the base64-encoded (either standard or
+// URL-safe, with or without padding) values of the request's params (i.e. query, body and
+// cookies). For instance: data=eyJpZCI6MX0= (that is, data={"id":1}).
+//
+// Detection is conservative: only those values that are valid base64, and that decode
+// into printable text, are considered. Then, the decoded content is looked for either
+// JSON or body (form) params, or used as a whole, otherwise (see [Base64]).
+type Base64Finder struct{}
+
+// NewBase64Finder instantiates a new Base64Finder.
+func NewBase64Finder() Base64Finder {
+	return Base64Finder{}
+}
+
+// minBase64Length is the minimum length of the values considered
+// as base64-encoded, so short (and common) words are ignored.
+const minBase64Length = 8
+
+func (f Base64Finder) Find(req request.Request) []Entrypoint {
+	entrypoints := make([]Entrypoint, 0)
+
+	for _, outer := range f.params(req) {
+		enc, decoded, ok := decodeBase64(outer.Value())
+		if !ok {
+			continue
+		}
+
+		inner := f.inner(decoded)
+		if len(inner) == 0 {
+			entrypoints = append(entrypoints, newBase64(outer, enc, decoded, nil))
+			continue
+		}
+
+		for _, in := range inner {
+			entrypoints = append(entrypoints, newBase64(outer, enc, decoded, in))
+		}
+	}
+
+	return entrypoints
+}
+
+// params returns the entrypoints of the request's params values,
+// those whose value can be base64-encoded.
+func (f Base64Finder) params(req request.Request) []Entrypoint {
+	var found []Entrypoint
+	found = append(found, NewQueryFinder().Find(req)...)
+	found = append(found, NewBodyParamFinder().Find(req)...)
+	found = append(found, NewCookieFinder().Find(req)...)
+
+	params := make([]Entrypoint, 0, len(found))
+	for _, ep := range found {
+		//nolint:exhaustive
+		switch ep.InsertionPointType() {
+		case profile.ParamURLValue, profile.ParamBodyValue, profile.CookieValue:
+			params = append(params, ep)
+		}
+	}
+
+	return params
+}
+
+// inner returns the entrypoints of the given decoded content values,
+// either JSON or body (form) params, if any.
+func (f Base64Finder) inner(decoded string) []Entrypoint {
+	var (
+		found []Entrypoint
+		ipt   profile.InsertionPointType
+		req   = request.Request{Body: []byte(decoded)}
+	)
+
+	switch {
+	case json.Valid(req.Body):
+		found, ipt = NewJSONParamFinder().Find(req), profile.ParamJSONValue
+	case isForm(decoded):
+		found, ipt = NewBodyParamFinder().Find(req), profile.ParamBodyValue
+	default:
+		return nil
+	}
+
+	inner := make([]Entrypoint, 0, len(found))
+	for _, ep := range found {
+		if ep.InsertionPointType() == ipt {
+			inner = append(inner, ep)
+		}
+	}
+
+	return inner
+}
+
+// isForm returns whether the given content looks like
+// URL-encoded (form) params, like: id=1&name=john.
+func isForm(content string) bool {
+	if !strings.Contains(content, "=") || strings.ContainsAny(content, " \t\r\n") {
+		return false
+	}
+
+	for _, param := range strings.Split(content, "&") {
+		if key, _, _ := strings.Cut(param, "="); len(key) == 0 {
+			return false
+		}
+	}
+
+	_, err := url.ParseQuery(content)
+	return err == nil
+}
+
+// base64Encoding determines how a value is base64-encoded, so once
+// decoded and injected, it can be re-encoded the same way.
+type base64Encoding struct {
+	URLSafe bool
+	Padded  bool
+	Escaped bool // Whether the encoded value is also URL-encoded (e.g. %3D).
+}
+
+func (enc base64Encoding) encoding() *base64.Encoding {
+	switch {
+	case enc.URLSafe && enc.Padded:
+		return base64.URLEncoding
+	case enc.URLSafe:
+		return base64.RawURLEncoding
+	case enc.Padded:
+		return base64.StdEncoding
+	default:
+		return base64.RawStdEncoding
+	}
+}
+
+func (enc base64Encoding) encode(s string) string {
+	encoded := enc.encoding().EncodeToString([]byte(s))
+	if enc.Escaped {
+		return url.QueryEscape(encoded)
+	}
+	return encoded
+}
+
+// decodeBase64 decodes the given value, if it is base64-encoded (see [Base64Finder]),
+// and returns the decoded value along with the encoding used.
+func decodeBase64(value string) (base64Encoding, string, bool) {
+	var enc base64Encoding
+
+	if strings.Contains(value, "%") {
+		unescaped, err := url.QueryUnescape(value)
+		if err != nil {
+			return enc, "", false
+		}
+		value, enc.Escaped = unescaped, true
+	}
+
+	if len(value) < minBase64Length {
+		return enc, "", false
+	}
+
+	enc.Padded = strings.HasSuffix(value, "=")
+	enc.URLSafe = strings.ContainsAny(value, "-_")
+	if enc.URLSafe && strings.ContainsAny(value, "+/") {
+		return enc, "", false
+	}
+
+	decoded, err := enc.encoding().Strict().DecodeString(value)
+	if err != nil || !isPrintable(decoded) {
+		return enc, "", false
+	}
+
+	return enc, string(decoded), true
+}
+
+func isPrintable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
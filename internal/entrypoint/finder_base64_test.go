@@ -0,0 +1,127 @@
+package entrypoint_test
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestBase64Finder_Find(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		req    request.Request
+		params []string
+	}{
+		"no params": {
+			req: request.Request{Path: "/"},
+		},
+		"non-base64 values": {
+			req: request.Request{Path: "/?q=password&id=abcdefgh&page=12345678&short=YQ=="},
+		},
+		"json within query param": {
+			req: request.Request{Path: "/?data=eyJpZCI6MSwibmFtZSI6ImpvaG4ifQ%3D%3D&page=2"},
+			params: []string{
+				"id (json param) (base64: data (query param))",
+				"name (json param) (base64: data (query param))",
+			},
+		},
+		"form within body param (url-safe)": {
+			req: request.Request{Body: []byte("token=aWQ9MSZyb2xlPXVzZXI_Pz4")},
+			params: []string{
+				"id (body param) (base64: token (body param))",
+				"role (body param) (base64: token (body param))",
+			},
+		},
+		"plain text within cookie": {
+			req: request.Request{
+				Headers: map[string][]string{"Cookie": {"session=aGVsbG8gd29ybGQ="}},
+			},
+			params: []string{"session (cookie name) (base64)"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entrypoints := entrypoint.NewBase64Finder().Find(tc.req)
+
+			params := make([]string, 0, len(entrypoints))
+			for _, e := range entrypoints {
+				assert.Equal(t, profile.ParamEncodedValue, e.InsertionPointType())
+				params = append(params, e.Param(""))
+			}
+
+			if len(tc.params) == 0 {
+				assert.Empty(t, params)
+				return
+			}
+
+			assert.Equal(t, tc.params, params)
+		})
+	}
+}
+
+func TestBase64Finder_Find_Inject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json within query param", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Request{Path: "/?data=eyJpZCI6MSwibmFtZSI6ImpvaG4ifQ%3D%3D&page=2"}
+
+		entrypoints := entrypoint.NewBase64Finder().Find(req)
+		require.Len(t, entrypoints, 2)
+		assert.Equal(t, "john", entrypoints[1].Value())
+
+		injReq := entrypoints[1].InjectPayload(req, profile.Replace, "'--")
+
+		// Re-encoded the same way: standard, padded and URL-encoded.
+		encoded := base64.StdEncoding.EncodeToString([]byte(`{"id":1,"name":"'--"}`))
+		assert.Equal(t, "/?data="+url.QueryEscape(encoded)+"&page=2", injReq.Path)
+
+		// The original request must remain untouched.
+		assert.Equal(t, "/?data=eyJpZCI6MSwibmFtZSI6ImpvaG4ifQ%3D%3D&page=2", req.Path)
+	})
+
+	t.Run("form within body param (url-safe)", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Request{Body: []byte("token=aWQ9MSZyb2xlPXVzZXI_Pz4")}
+
+		entrypoints := entrypoint.NewBase64Finder().Find(req)
+		require.Len(t, entrypoints, 2)
+
+		injReq := entrypoints[0].InjectPayload(req, profile.Append, "'")
+
+		// Re-encoded the same way: URL-safe, with no padding.
+		encoded := base64.RawURLEncoding.EncodeToString([]byte("id=1'&role=user??>"))
+		assert.Equal(t, "token="+encoded, string(injReq.Body))
+	})
+
+	t.Run("plain text within cookie", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Request{
+			Headers: map[string][]string{"Cookie": {"session=aGVsbG8gd29ybGQ="}},
+		}
+
+		entrypoints := entrypoint.NewBase64Finder().Find(req)
+		require.Len(t, entrypoints, 1)
+
+		injReq := entrypoints[0].InjectPayload(req, profile.Insert, "<x>")
+
+		encoded := base64.StdEncoding.EncodeToString([]byte("hello<x> world"))
+		assert.Equal(t, "session="+encoded, injReq.Headers["Cookie"][0])
+	})
+}
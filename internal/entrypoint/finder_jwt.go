@@ -0,0 +1,70 @@
+package entrypoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/jsonmap"
+	"github.com/bountysecurity/gbounty/kit/jwt"
+)
+
+// JWTFinder must implement the Finder interface.
+var _ Finder = JWTFinder{}
+
+// JWTFinder is used to find entrypoints in the claims of the
+// JSON Web Tokens (JWTs) sent as bearer tokens (i.e. within the
+// `Authorization: Bearer <token>` request header).
+type JWTFinder struct{}
+
+// NewJWTFinder instantiates a new JWTFinder.
+func NewJWTFinder() JWTFinder {
+	return JWTFinder{}
+}
+
+func (f JWTFinder) Find(req request.Request) []Entrypoint {
+	headerKey := http.CanonicalHeaderKey("Authorization")
+
+	entrypoints := make([]Entrypoint, 0)
+
+	for _, val := range req.Headers[headerKey] {
+		prefix, token, ok := jwt.Bearer(val)
+		if !ok {
+			continue
+		}
+
+		tok, err := jwt.Parse(token)
+		if err != nil {
+			continue
+		}
+
+		for _, claim := range tok.Header.Order {
+			entrypoints = append(entrypoints, newJWT(headerKey, prefix, token, jwtHeaderSegment, claim, claimValue(tok.Header.Data[claim])))
+		}
+
+		for _, claim := range tok.Payload.Order {
+			entrypoints = append(entrypoints, newJWT(headerKey, prefix, token, jwtPayloadSegment, claim, claimValue(tok.Payload.Data[claim])))
+		}
+	}
+
+	return entrypoints
+}
+
+func claimValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case jsonmap.Ordered, []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,111 @@
+package entrypoint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/jwt"
+)
+
+const jwtToken = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"XbPfbIHMI6arZ3Y922BhjWgQzWXcXNrz0ogtVhfEd2o"
+
+func TestJWTFinder_Find(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		req    request.Request
+		params []string
+	}{
+		"no headers": {
+			req: request.Request{},
+		},
+		"non-bearer authorization": {
+			req: request.Request{
+				Headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			},
+		},
+		"opaque bearer token": {
+			req: request.Request{
+				Headers: map[string][]string{"Authorization": {"Bearer abcdef123456"}},
+			},
+		},
+		"jwt bearer token": {
+			req: request.Request{
+				Headers: map[string][]string{"Authorization": {"Bearer " + jwtToken}},
+			},
+			params: []string{
+				"alg (jwt header claim)",
+				"typ (jwt header claim)",
+				"sub (jwt payload claim)",
+				"name (jwt payload claim)",
+				"iat (jwt payload claim)",
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entrypoints := entrypoint.NewJWTFinder().Find(tc.req)
+
+			params := make([]string, 0, len(entrypoints))
+			for _, e := range entrypoints {
+				assert.Equal(t, profile.ParamJWTClaim, e.InsertionPointType())
+				params = append(params, e.Param(""))
+			}
+
+			if len(tc.params) == 0 {
+				assert.Empty(t, params)
+				return
+			}
+
+			assert.Equal(t, tc.params, params)
+		})
+	}
+}
+
+func TestJWTFinder_Find_Inject(t *testing.T) {
+	t.Parallel()
+
+	req := request.Request{
+		Headers: map[string][]string{"Authorization": {"Bearer " + jwtToken}},
+	}
+
+	entrypoints := entrypoint.NewJWTFinder().Find(req)
+	require.Len(t, entrypoints, 5)
+
+	t.Run("payload claim", func(t *testing.T) {
+		t.Parallel()
+
+		injReq := entrypoints[3].InjectPayload(req, profile.Append, "'--")
+
+		tok, err := jwt.Parse(injReq.Headers["Authorization"][0][len("Bearer "):])
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe'--", tok.Payload.Data["name"])
+		assert.Equal(t, "XbPfbIHMI6arZ3Y922BhjWgQzWXcXNrz0ogtVhfEd2o", tok.Signature)
+
+		// The original request must remain untouched.
+		assert.Equal(t, "Bearer "+jwtToken, req.Headers["Authorization"][0])
+	})
+
+	t.Run("alg none", func(t *testing.T) {
+		t.Parallel()
+
+		injReq := entrypoints[0].InjectPayload(req, profile.Replace, "none")
+
+		tok, err := jwt.Parse(injReq.Headers["Authorization"][0][len("Bearer "):])
+		require.NoError(t, err)
+		assert.Equal(t, "none", tok.Alg())
+		assert.True(t, tok.Unsigned())
+	})
+}
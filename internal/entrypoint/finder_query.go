@@ -3,6 +3,7 @@ package entrypoint
 import (
 	"strings"
 
+	"github.com/bountysecurity/gbounty/internal/profile"
 	"github.com/bountysecurity/gbounty/internal/request"
 )
 
@@ -17,50 +18,47 @@ func NewQueryFinder() QueryFinder {
 	return QueryFinder{}
 }
 
+// QueryFinder must implement the InsertionPointFinder interface.
+var _ InsertionPointFinder = QueryFinder{}
+
 func (f QueryFinder) Find(req request.Request) []Entrypoint {
-	entrypoints := make([]Entrypoint, 0)
-	base, raw := f.splitQuery(req.Path)
+	insertionPoints := f.InsertionPoints(req)
+
+	entrypoints := make([]Entrypoint, 0, len(insertionPoints))
+	for _, ip := range insertionPoints {
+		entrypoints = append(entrypoints, newQueryFrom(ip))
+	}
+
+	return entrypoints
+}
 
-	var (
-		idx           int
-		query         = raw
-		lastParamSeen string
-	)
-
-	for query != "" {
-		idx1 := strings.Index(query, "=")
-		idx2 := strings.Index(query, "&")
-
-		if idx2 == -1 {
-			if idx1 == -1 {
-				lastParamSeen = raw[idx:]
-				entrypoints = append(entrypoints, newQueryKey(base+raw[:idx], lastParamSeen, ""))
-			} else {
-				lastParamSeen = raw[idx : idx+idx1]
-				entrypoints = append(entrypoints, newQueryKey(base+raw[:idx], lastParamSeen, raw[idx+idx1:]))
-				entrypoints = append(entrypoints, newQueryValue(base+raw[:idx+idx1+1], lastParamSeen, raw[idx+idx1+1:], ""))
-			}
-
-			idx += len(query)
-			query = query[len(query):]
+// InsertionPoints returns the insertion points of the request's query,
+// both the keys and the values (if any) of each query parameter, in order.
+func (f QueryFinder) InsertionPoints(req request.Request) []InsertionPoint {
+	insertionPoints := make([]InsertionPoint, 0)
+	base, raw := f.splitQuery(req.Path)
 
+	offset := len(base)
+	for _, param := range strings.Split(raw, "&") {
+		if len(param) == 0 {
+			offset++
 			continue
 		}
 
-		if idx1 > idx2 {
-			lastParamSeen = raw[idx : idx+idx2]
-			entrypoints = append(entrypoints, newQueryKey(base+raw[:idx], lastParamSeen, raw[idx+idx2:]))
-		} else if idx1 < idx2 {
-			lastParamSeen = raw[idx : idx+idx1]
-			entrypoints = append(entrypoints, newQueryKey(base+raw[:idx], lastParamSeen, raw[idx+idx1:]))
-			entrypoints = append(entrypoints, newQueryValue(base+raw[:idx+idx1+1], lastParamSeen, raw[idx+idx1+1:idx+idx2], raw[idx+idx2:]))
+		key, _, hasValue := strings.Cut(param, "=")
+		insertionPoints = append(insertionPoints,
+			newInsertionPoint(req, profile.ParamURLName, LocationPath, key, offset, offset+len(key)))
+
+		if hasValue {
+			valueStart := offset + len(key) + len("=")
+			insertionPoints = append(insertionPoints,
+				newInsertionPoint(req, profile.ParamURLValue, LocationPath, key, valueStart, offset+len(param)))
 		}
 
-		idx += len(query[:idx2+1])
-		query = query[idx2+1:]
+		offset += len(param) + len("&")
 	}
 
-	return entrypoints
+	return insertionPoints
 }
 
 func (QueryFinder) splitQuery(path string) (string, string) {
@@ -0,0 +1,113 @@
+package entrypoint
+
+import (
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// Location represents the part of a request where an [InsertionPoint] is,
+// so the byte range of the [InsertionPoint] is relative to that part.
+type Location string
+
+const (
+	// LocationPath is the request's path (including the query).
+	LocationPath Location = "path"
+	// LocationBody is the request's body.
+	LocationBody Location = "body"
+)
+
+// InsertionPoint is a fuzzable position of a request, annotated by a finder,
+// as the byte range (from Start to End) of the given [Location] that holds
+// the Original value, so payloads can be injected generically (see [InsertionPoint.Inject]),
+// regardless of the kind of entrypoint (e.g. query, body or path).
+type InsertionPoint struct {
+	Type     profile.InsertionPointType
+	Location Location
+	Name     string
+	Original string
+	Start    int
+	End      int
+
+	req request.Request
+}
+
+// InsertionPointFinder defines the behavior of a finder capable of annotating
+// the fuzzable positions of a request as insertion points (see [InsertionPoint]).
+type InsertionPointFinder interface {
+	InsertionPoints(req request.Request) []InsertionPoint
+}
+
+func newInsertionPoint(
+	req request.Request,
+	ipt profile.InsertionPointType,
+	loc Location,
+	name string,
+	start, end int,
+) InsertionPoint {
+	return InsertionPoint{
+		Type:     ipt,
+		Location: loc,
+		Name:     name,
+		Original: string(locationBytes(req, loc)[start:end]),
+		Start:    start,
+		End:      end,
+		req:      req,
+	}
+}
+
+// Inject returns a copy of the request the [InsertionPoint] was annotated on,
+// with the Original value replaced by the given payload.
+func (ip InsertionPoint) Inject(payload string) *request.Request {
+	req := ip.req.Clone()
+
+	switch ip.Location {
+	case LocationPath:
+		req.Path = ip.Prefix() + payload + ip.Suffix()
+	case LocationBody:
+		req.SetBody([]byte(ip.Prefix() + payload + ip.Suffix()))
+	}
+
+	return &req
+}
+
+// InjectAt is like [InsertionPoint.Inject], but the payload is placed according
+// to the given [profile.PayloadPosition]: either replacing the Original value,
+// appended to it, or inserted in the middle of it.
+func (ip InsertionPoint) InjectAt(pos profile.PayloadPosition, payload string) *request.Request {
+	return ip.Inject(positioned(pos, ip.Original, payload))
+}
+
+// Prefix returns the content of the [Location] before the [InsertionPoint].
+func (ip InsertionPoint) Prefix() string {
+	return string(locationBytes(ip.req, ip.Location)[:ip.Start])
+}
+
+// Suffix returns the content of the [Location] after the [InsertionPoint].
+func (ip InsertionPoint) Suffix() string {
+	return string(locationBytes(ip.req, ip.Location)[ip.End:])
+}
+
+func locationBytes(req request.Request, loc Location) []byte {
+	switch loc {
+	case LocationPath:
+		return []byte(req.Path)
+	case LocationBody:
+		return req.Body
+	default:
+		return nil
+	}
+}
+
+// positioned returns the value that results from placing the payload
+// on the original value, according to the given [profile.PayloadPosition].
+func positioned(pos profile.PayloadPosition, original, payload string) string {
+	switch pos {
+	case profile.Append:
+		return original + payload
+	case profile.Insert:
+		mid := len(original) / half
+		return original[:mid] + payload + original[mid:]
+	default:
+		return payload
+	}
+}
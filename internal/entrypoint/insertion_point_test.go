@@ -0,0 +1,59 @@
+package entrypoint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestQueryFinder_InsertionPoints(t *testing.T) {
+	t.Parallel()
+
+	req := request.Request{Path: "/file.php?a=1&b&c=3"}
+
+	ips := entrypoint.NewQueryFinder().InsertionPoints(req)
+	require.Len(t, ips, 5)
+
+	expected := []struct {
+		ipt        profile.InsertionPointType
+		name, orig string
+		start, end int
+	}{
+		{ipt: profile.ParamURLName, name: "a", orig: "a", start: 10, end: 11},
+		{ipt: profile.ParamURLValue, name: "a", orig: "1", start: 12, end: 13},
+		{ipt: profile.ParamURLName, name: "b", orig: "b", start: 14, end: 15},
+		{ipt: profile.ParamURLName, name: "c", orig: "c", start: 16, end: 17},
+		{ipt: profile.ParamURLValue, name: "c", orig: "3", start: 18, end: 19},
+	}
+
+	for i, exp := range expected {
+		assert.Equal(t, exp.ipt, ips[i].Type)
+		assert.Equal(t, entrypoint.LocationPath, ips[i].Location)
+		assert.Equal(t, exp.name, ips[i].Name)
+		assert.Equal(t, exp.orig, ips[i].Original)
+		assert.Equal(t, exp.start, ips[i].Start)
+		assert.Equal(t, exp.end, ips[i].End)
+	}
+
+	assert.Equal(t, "/file.php?a=PAYLOAD&b&c=3", ips[1].Inject("PAYLOAD").Path)
+	assert.Equal(t, "/file.php?a=1&b&c=3PAYLOAD", ips[4].InjectAt(profile.Append, "PAYLOAD").Path)
+	assert.Equal(t, "/file.php?a=1&PAYLOADb&c=3", ips[2].InjectAt(profile.Insert, "PAYLOAD").Path)
+
+	// The original request is left untouched.
+	assert.Equal(t, "/file.php?a=1&b&c=3", req.Path)
+}
+
+func TestQueryFinder_InsertionPoints_KeysOnly(t *testing.T) {
+	t.Parallel()
+
+	ips := entrypoint.NewQueryFinder().InsertionPoints(request.Request{Path: "/?a&&b"})
+	require.Len(t, ips, 2)
+
+	assert.Equal(t, "/?PAYLOAD&&b", ips[0].Inject("PAYLOAD").Path)
+	assert.Equal(t, "/?a&&PAYLOAD", ips[1].Inject("PAYLOAD").Path)
+}
@@ -0,0 +1,309 @@
+package scan
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrTooManyErrors is the error returned when the `scan` has been aborted because too many
+	// requests failed (see [RunnerOpts.WithErrorThreshold]). It is always wrapped along with
+	// [context.Canceled], within a [TooManyErrorsError] that holds the diagnostic.
+	ErrTooManyErrors = errors.New("too many errors")
+	// ErrInvalidErrorThreshold is the error returned when the error threshold
+	// cannot be parsed (see [ParseErrorThreshold]).
+	ErrInvalidErrorThreshold = errors.New("invalid error threshold")
+)
+
+// DefaultErrorThresholdWindow is the amount of latest requests the error
+// rate is calculated over (see [ErrorThreshold.Rate]), unless set.
+const DefaultErrorThresholdWindow = 100
+
+// ErrorThreshold determines when the `scan` is aborted because too many requests failed,
+// either once Count requests failed, or once the rate of failed requests, over the latest
+// Window requests performed, reaches Rate (i.e. from 0 to 1). So, misconfigured scans
+// (e.g. a wrong proxy) fail fast, instead of wasting time producing nothing.
+//
+// Only transport-level failures (e.g. connection refused, timeouts or TLS errors)
+// count as errors, as responses with any status code (e.g. 403 or 500) are legit.
+type ErrorThreshold struct {
+	Count  int
+	Rate   float64
+	Window int
+}
+
+// ParseErrorThreshold parses the given string as an [ErrorThreshold], either as the amount of
+// failed requests (e.g. 50), or as the percentage of failed requests (e.g. 20%) over the given
+// window of latest requests ([DefaultErrorThresholdWindow] if zero). It returns the zero value
+// (i.e. disabled) if the given string is empty.
+func ParseErrorThreshold(s string, window int) (ErrorThreshold, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return ErrorThreshold{}, nil
+	}
+
+	if window < 0 {
+		return ErrorThreshold{}, fmt.Errorf("%w: the window must be positive: %d", ErrInvalidErrorThreshold, window)
+	}
+
+	if window == 0 {
+		window = DefaultErrorThresholdWindow
+	}
+
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || rate <= 0 || rate > 100 {
+			return ErrorThreshold{}, fmt.Errorf("%w: the percentage must be within (0, 100]: %s", ErrInvalidErrorThreshold, s)
+		}
+
+		return ErrorThreshold{Rate: rate / 100, Window: window}, nil //nolint:mnd
+	}
+
+	count, err := strconv.Atoi(s)
+	if err != nil || count <= 0 {
+		return ErrorThreshold{}, fmt.Errorf("%w: it must be either a positive number or a percentage: %s", ErrInvalidErrorThreshold, s)
+	}
+
+	return ErrorThreshold{Count: count}, nil
+}
+
+// Enabled returns whether the [ErrorThreshold] is set.
+func (t ErrorThreshold) Enabled() bool {
+	return t.Count > 0 || t.Rate > 0
+}
+
+// String returns the human-readable form of the [ErrorThreshold].
+func (t ErrorThreshold) String() string {
+	if t.Rate > 0 {
+		return fmt.Sprintf("%s%% of the latest %d requests", strconv.FormatFloat(t.Rate*100, 'f', -1, 64), t.Window) //nolint:mnd
+	}
+
+	return fmt.Sprintf("%d requests", t.Count)
+}
+
+// Error kinds, as classified by [ErrorKind].
+const (
+	ErrorKindDNS             = "dns resolution"
+	ErrorKindRefused         = "connection refused"
+	ErrorKindReset           = "connection reset"
+	ErrorKindUnreachable     = "network unreachable"
+	ErrorKindTLS             = "tls handshake"
+	ErrorKindTimeout         = "timeout"
+	ErrorKindInvalidResponse = "invalid response"
+	ErrorKindOther           = "other"
+)
+
+// errorKinds are the substrings (of the error messages) that identify each error
+// kind, in order, as the same message may contain more than one (e.g. a lookup timeout).
+var errorKinds = []struct {
+	kind       string
+	substrings []string
+}{
+	{kind: ErrorKindDNS, substrings: []string{"no such host", "server misbehaving", "lookup "}},
+	{kind: ErrorKindRefused, substrings: []string{"connection refused"}},
+	{kind: ErrorKindUnreachable, substrings: []string{"no route to host", "network is unreachable", "host is down"}},
+	{kind: ErrorKindTLS, substrings: []string{"tls:", "x509:", "handshake", "certificate"}},
+	{kind: ErrorKindTimeout, substrings: []string{"timeout", "timed out", "took more than", "deadline exceeded"}},
+	{kind: ErrorKindReset, substrings: []string{"connection reset", "broken pipe", "eof"}},
+	{kind: ErrorKindInvalidResponse, substrings: []string{"invalid status", "invalid protocol", "invalid header", "malformed"}},
+}
+
+// ErrorKind classifies the given (transport-level) error by its message, as errors are
+// flattened into strings once these go through the wire (e.g. see [Error]), like
+// [ErrorKindRefused] or [ErrorKindTimeout]. It returns [ErrorKindOther] if unknown.
+func ErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, k := range errorKinds {
+		for _, s := range k.substrings {
+			if strings.Contains(msg, s) {
+				return k.kind
+			}
+		}
+	}
+
+	return ErrorKindOther
+}
+
+// IsTransportError returns whether the given error is a transport-level failure, either a
+// network one (see [net.Error], e.g. connection refused, DNS resolution or timeouts), or
+// a TLS one (e.g. handshake or certificate errors). Any other error (e.g. a failed request
+// hook) isn't, so it is neither classified (see [ErrorKind]) nor counted as an error.
+func IsTransportError(err error) bool {
+	var (
+		netErr     net.Error
+		recordErr  tls.RecordHeaderError
+		verifyErr  *tls.CertificateVerificationError
+		invalidErr x509.CertificateInvalidError
+		hostErr    x509.HostnameError
+		authErr    x509.UnknownAuthorityError
+	)
+
+	return errors.As(err, &netErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &verifyErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &hostErr) ||
+		errors.As(err, &authErr)
+}
+
+// errorKindHints are the likely causes of each error kind, reported along with the diagnostic.
+var errorKindHints = map[string]string{
+	ErrorKindDNS:             "check the target host(s), or the DNS settings",
+	ErrorKindRefused:         "check the target port(s), and the proxy address, if any",
+	ErrorKindUnreachable:     "check the network connectivity, and the proxy address, if any",
+	ErrorKindTLS:             "check the target scheme (http vs https), and the certificates",
+	ErrorKindTimeout:         "check the network connectivity, or increase the timeout",
+	ErrorKindReset:           "the target (or a WAF) may be dropping the connections, reduce the rate",
+	ErrorKindInvalidResponse: "check the target scheme (http vs https), and the proxy, if any",
+}
+
+// TooManyErrorsError is the error returned when the `scan` has been aborted because too many
+// requests failed (see [RunnerOpts.WithErrorThreshold]), with the diagnostic: the amount of
+// failed requests, and the dominant error kind (see [ErrorKind]) along with a sample message.
+//
+// It matches both [ErrTooManyErrors] and [context.Canceled] (see [errors.Is]),
+// so it is handled as any other cancellation (e.g. the output is written).
+type TooManyErrorsError struct {
+	Threshold ErrorThreshold
+	Failed    int
+	Kind      string
+	OfKind    int
+	Sample    string
+}
+
+// Error returns the diagnostic.
+func (e *TooManyErrorsError) Error() string {
+	msg := fmt.Sprintf("%d request(s) failed (threshold: %s), mostly due to %s (%d): %s",
+		e.Failed, e.Threshold, e.Kind, e.OfKind, e.Sample)
+
+	if hint, ok := errorKindHints[e.Kind]; ok {
+		msg += " (hint: " + hint + ")"
+	}
+
+	return msg
+}
+
+// Unwrap returns both [ErrTooManyErrors] and [context.Canceled].
+func (e *TooManyErrorsError) Unwrap() []error {
+	return []error{ErrTooManyErrors, context.Canceled}
+}
+
+// errorBudget keeps track of the requests performed, and whether these failed, grouped by
+// kind (see [ErrorKind]), to determine whether the [ErrorThreshold] has been exceeded.
+type errorBudget struct {
+	mu        sync.Mutex
+	threshold ErrorThreshold
+
+	failed  int
+	kinds   map[string]int
+	samples map[string]string
+
+	// window is a ring buffer with the latest outcomes (true if failed),
+	// only used when the threshold is a rate (see [ErrorThreshold.Rate]).
+	window   []bool
+	next     int
+	filled   bool
+	inWindow int
+}
+
+func newErrorBudget(threshold ErrorThreshold) *errorBudget {
+	b := &errorBudget{
+		threshold: threshold,
+		kinds:     make(map[string]int),
+		samples:   make(map[string]string),
+	}
+
+	if threshold.Rate > 0 {
+		b.window = make([]bool, threshold.Window)
+	}
+
+	return b
+}
+
+// classify records the kind (see [ErrorKind]) of the given error, and its message as sample, if
+// it is the first one of its kind, so the dominant one can be reported along with the diagnostic.
+func (b *errorBudget) classify(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kind := ErrorKind(err)
+	b.kinds[kind]++
+	if _, ok := b.samples[kind]; !ok {
+		b.samples[kind] = err.Error()
+	}
+}
+
+// record records the outcome of a request, and returns the diagnostic
+// (see [TooManyErrorsError]) if the threshold has been exceeded.
+func (b *errorBudget) record(failed bool) *TooManyErrorsError {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if failed {
+		b.failed++
+	}
+
+	if b.window != nil {
+		if b.filled && b.window[b.next] {
+			b.inWindow--
+		}
+		if failed {
+			b.inWindow++
+		}
+
+		b.window[b.next] = failed
+		b.next = (b.next + 1) % len(b.window)
+		b.filled = b.filled || b.next == 0
+	}
+
+	if !failed || !b.exceeded() {
+		return nil
+	}
+
+	return b.diagnostic()
+}
+
+func (b *errorBudget) exceeded() bool {
+	if b.threshold.Count > 0 {
+		return b.failed >= b.threshold.Count
+	}
+
+	// The rate is only calculated once there are enough requests,
+	// so a few failures early on don't abort the scan.
+	return b.filled && float64(b.inWindow) >= math.Ceil(b.threshold.Rate*float64(len(b.window)))
+}
+
+func (b *errorBudget) diagnostic() *TooManyErrorsError {
+	kinds := make([]string, 0, len(b.kinds))
+	for kind := range b.kinds {
+		kinds = append(kinds, kind)
+	}
+
+	// The dominant kind is the one with more failures, or the first in alphabetical order.
+	sort.Slice(kinds, func(i, j int) bool {
+		if b.kinds[kinds[i]] != b.kinds[kinds[j]] {
+			return b.kinds[kinds[i]] > b.kinds[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+
+	diagnostic := &TooManyErrorsError{Threshold: b.threshold, Failed: b.failed, Kind: ErrorKindOther}
+	if len(kinds) > 0 {
+		diagnostic.Kind, diagnostic.OfKind, diagnostic.Sample = kinds[0], b.kinds[kinds[0]], b.samples[kinds[0]]
+	}
+
+	return diagnostic
+}
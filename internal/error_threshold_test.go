@@ -0,0 +1,108 @@
+package scan_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/http/client"
+)
+
+func TestParseErrorThreshold(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		input    string
+		window   int
+		expected scan.ErrorThreshold
+		str      string
+		err      error
+	}{
+		"empty":             {input: " "},
+		"count":             {input: "50", expected: scan.ErrorThreshold{Count: 50}, str: "50 requests"},
+		"rate":              {input: "20%", expected: scan.ErrorThreshold{Rate: 0.2, Window: scan.DefaultErrorThresholdWindow}, str: "20% of the latest 100 requests"},
+		"rate with window":  {input: "12.5 %", window: 40, expected: scan.ErrorThreshold{Rate: 0.125, Window: 40}, str: "12.5% of the latest 40 requests"},
+		"zero":              {input: "0", err: scan.ErrInvalidErrorThreshold},
+		"negative":          {input: "-1", err: scan.ErrInvalidErrorThreshold},
+		"not a number":      {input: "many", err: scan.ErrInvalidErrorThreshold},
+		"rate over 100":     {input: "101%", err: scan.ErrInvalidErrorThreshold},
+		"zero rate":         {input: "0%", err: scan.ErrInvalidErrorThreshold},
+		"negative window":   {input: "20%", window: -1, err: scan.ErrInvalidErrorThreshold},
+		"not a number rate": {input: "x%", err: scan.ErrInvalidErrorThreshold},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := scan.ParseErrorThreshold(tc.input, tc.window)
+			require.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, threshold)
+			assert.Equal(t, len(tc.str) > 0, threshold.Enabled())
+
+			if len(tc.str) > 0 {
+				assert.Equal(t, tc.str, threshold.String())
+			}
+		})
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]string{
+		"network error: dial tcp: lookup nope.example.com: no such host":                 scan.ErrorKindDNS,
+		"network error: dial tcp: lookup nope.example.com on 127.0.0.53:53: i/o timeout": scan.ErrorKindDNS,
+		"network error: dial tcp 127.0.0.1:8080: connect: connection refused":            scan.ErrorKindRefused,
+		"network error: read tcp 127.0.0.1:51234->127.0.0.1:443: read: connection reset": scan.ErrorKindReset,
+		"network error: EOF": scan.ErrorKindReset,
+		"network error: dial tcp 10.0.0.1:443: connect: no route to host":     scan.ErrorKindUnreachable,
+		"network error: tls: first record does not look like a TLS handshake": scan.ErrorKindTLS,
+		"http request took more than 20s (canceled)":                          scan.ErrorKindTimeout,
+		"network error: dial tcp 10.0.0.1:443: i/o timeout":                   scan.ErrorKindTimeout,
+		"network error: invalid status line: SSH-2.0-OpenSSH_8.9":             scan.ErrorKindInvalidResponse,
+		"something unexpected": scan.ErrorKindOther,
+	}
+
+	for msg, kind := range tcs {
+		assert.Equal(t, kind, scan.ErrorKind(errors.New(msg)), msg)
+	}
+
+	assert.Empty(t, scan.ErrorKind(nil))
+}
+
+func TestIsTransportError(t *testing.T) {
+	t.Parallel()
+
+	netErr := client.NetError("network error: EOF")
+
+	tcs := map[string]struct {
+		err       error
+		transport bool
+	}{
+		"connection refused": {err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, transport: true},
+		"dns resolution":     {err: &net.DNSError{Err: "no such host", Name: "nope.example.com"}, transport: true},
+		"deadline exceeded":  {err: fmt.Errorf("reading: %w", context.DeadlineExceeded), transport: true},
+		"tls record":         {err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, transport: true},
+		"tls certificate":    {err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, transport: true},
+		"client error":       {err: &netErr, transport: true},
+		"eof":                {err: errors.New("unexpected eof")},
+		"handshake":          {err: errors.New("request hook failed: handshake script exited with 1")},
+		"canceled":           {err: context.Canceled},
+		"nil":                {},
+	}
+
+	for name, tc := range tcs {
+		assert.Equal(t, tc.transport, scan.IsTransportError(tc.err), name)
+	}
+}
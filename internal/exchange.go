@@ -0,0 +1,166 @@
+package scan
+
+import (
+	"context"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// Exchange represents one of the requests sent during a [scan], along with the
+// response got, stored regardless of whether it matched or not (see [WithExchangeStore]),
+// so it can be analyzed offline later (see [Rematch]).
+type Exchange struct {
+	URL      string
+	Request  *request.Request
+	Response *response.Response
+	// Truncated determines whether the response body has been truncated
+	// before being stored, because it exceeded the maximum size.
+	Truncated bool
+	At        time.Time
+}
+
+// WithExchangeStore decorates the given [RequesterBuilder], so every request sent
+// through the built [Requester], along with the response got, is stored into the
+// given file system as an [Exchange]. Requests that failed aren't stored, as those
+// are already reported as errors.
+//
+// Response bodies larger than the given maximum size (in bytes) are truncated before
+// being stored, unless it is zero or negative. Each redirect followed is a different
+// request, so it is stored as a different exchange.
+func WithExchangeStore(fn RequesterBuilder, fs FileSystemExchanges, maxBodySize int) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return exchangeRequester{Requester: requester, fs: fs, maxBodySize: maxBodySize}, nil
+	}
+}
+
+type exchangeRequester struct {
+	Requester
+	fs          FileSystemExchanges
+	maxBodySize int
+}
+
+func (r exchangeRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	res, err := r.Requester.Do(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	storedReq, storedRes := req.Clone(), res
+	truncated := r.maxBodySize > 0 && len(res.Body) > r.maxBodySize
+	if truncated {
+		storedRes.Body = res.Body[:r.maxBodySize]
+	}
+
+	if err := r.fs.StoreExchange(ctx, Exchange{
+		URL:       req.URL,
+		Request:   &storedReq,
+		Response:  &storedRes,
+		Truncated: truncated,
+		At:        time.Now().UTC(),
+	}); err != nil {
+		logger.For(ctx).Errorf("Error while storing scan exchange: %s", err.Error())
+	}
+
+	return res, nil
+}
+
+const (
+	// MetadataSource is the [Match.Metadata] key that identifies where the match
+	// comes from, only set when it doesn't come from a regular scan.
+	MetadataSource = "source"
+	// SourceRematch identifies the [Match] instances found by [Rematch].
+	SourceRematch = "rematch"
+)
+
+// Rematch runs the given passive (request and response) profiles against the
+// [Exchange] instances stored into the given file system, with no requests sent,
+// and returns the [Match] instances found, in the same order as the exchanges.
+// These are tagged as such (see [SourceRematch]), but their identifiers are the
+// same as if found by a regular scan, so both can be compared (see [MatchID]).
+//
+// Active profiles aren't supported, as these depend on the payloads injected
+// into the requests. Bear in mind that truncated responses may lead to missing
+// matches (or to different ones), as only the stored part of the body is checked.
+func Rematch(
+	ctx context.Context,
+	fs FileSystemExchanges,
+	passiveReqProfiles []*profile.Request,
+	passiveResProfiles []*profile.Response,
+	customTokens CustomTokens,
+) ([]Match, error) {
+	exchanges, closeFn, err := fs.ExchangesIterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	var matches []Match
+
+	for exchange := range exchanges {
+		if exchange.Request == nil {
+			continue
+		}
+
+		req := exchange.Request
+		reqs := []*request.Request{req}
+
+		passiveRequestScan(ctx, passiveReqProfiles, req, func(prof *profile.Request, occ []occurrence.Occurrence) {
+			matches = append(matches, rematchOf(ctx, exchange.URL, reqs, nil, prof, prof, occ))
+		}, customTokens)
+
+		if exchange.Response == nil || exchange.Response.IsEmpty() {
+			continue
+		}
+
+		res := []*response.Response{exchange.Response}
+		passiveResponseScan(ctx, passiveResProfiles, req, exchange.Response, func(prof *profile.Response, occ []occurrence.Occurrence) {
+			matches = append(matches, rematchOf(ctx, exchange.URL, reqs, res, prof, prof, occ))
+		}, customTokens)
+	}
+
+	return matches, ctx.Err()
+}
+
+func rematchOf(
+	ctx context.Context,
+	url string,
+	reqs []*request.Request,
+	res []*response.Response,
+	prof profile.Profile,
+	issue profile.IssueInformation,
+	occ []occurrence.Occurrence,
+) Match {
+	occurrences := [][]occurrence.Occurrence{occ}
+
+	m := Match{
+		URL:                   url,
+		Requests:              reqs,
+		Responses:             RedactSensitiveData(prof, res, occurrences),
+		ProfileName:           prof.GetName(),
+		ProfileTags:           prof.GetTags(),
+		IssueName:             issue.GetIssueName(),
+		IssueSeverity:         issue.GetIssueSeverity(),
+		IssueConfidence:       issue.GetIssueConfidence(),
+		IssueDetail:           issue.GetIssueDetail(),
+		IssueBackground:       issue.GetIssueBackground(),
+		RemediationDetail:     issue.GetRemediationDetail(),
+		RemediationBackground: issue.GetRemediationBackground(),
+		ProfileType:           prof.GetType().String(),
+		Occurrences:           occurrences,
+		Metadata:              MatchMetadata(ctx, map[string]string{MetadataSource: SourceRematch}, prof, reqs, res, ""),
+		At:                    time.Now().UTC(),
+	}
+	m.ID = MatchID(m)
+
+	return m
+}
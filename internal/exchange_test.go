@@ -0,0 +1,94 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestWithExchangeStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	requester := &countingRequester{res: response.Response{
+		Code:   200,
+		Status: "OK",
+		Proto:  "HTTP/1.1",
+		Body:   []byte("Powered by gbounty, with a long enough body"),
+	}}
+
+	builder := scan.WithExchangeStore(func() (scan.Requester, error) {
+		return requester, nil
+	}, fs, 16)
+
+	for _, u := range []string{"http://example.org/a", "http://example.org/b"} {
+		req := request.Default(u)
+
+		r, err := builder()
+		require.NoError(t, err)
+
+		res, err := r.Do(ctx, &req)
+		require.NoError(t, err)
+
+		// The response returned must be the whole one, only the stored one is truncated.
+		assert.Equal(t, requester.res.Body, res.Body)
+	}
+
+	exchanges, closeFn, err := fs.ExchangesIterator(ctx)
+	require.NoError(t, err)
+
+	var urls []string
+	for exchange := range exchanges {
+		urls = append(urls, exchange.URL)
+
+		assert.True(t, exchange.Truncated)
+		assert.Equal(t, "Powered by gboun", string(exchange.Response.Body))
+	}
+	closeFn()
+
+	assert.Equal(t, []string{"http://example.org/a", "http://example.org/b"}, urls)
+
+	t.Run("rematch", func(t *testing.T) {
+		t.Parallel()
+
+		matches, err := scan.Rematch(ctx, fs, nil, []*profile.Response{
+			{
+				Name:      "Powered by",
+				Enabled:   true,
+				Type:      profile.TypePassiveRes,
+				Greps:     []string{"true,,Simple String,,Powered by"},
+				IssueName: "Technology disclosure",
+			},
+			{
+				// Only the stored part of the body is checked.
+				Name:    "Long enough",
+				Enabled: true,
+				Type:    profile.TypePassiveRes,
+				Greps:   []string{"true,,Simple String,,long enough"},
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		require.Len(t, matches, 2)
+		for i, u := range []string{"http://example.org/a", "http://example.org/b"} {
+			assert.Equal(t, u, matches[i].URL)
+			assert.Equal(t, "Powered by", matches[i].ProfileName)
+			assert.Equal(t, "Technology disclosure", matches[i].IssueName)
+			assert.Equal(t, scan.SourceRematch, matches[i].Metadata[scan.MetadataSource])
+			assert.NotEmpty(t, matches[i].ID)
+		}
+	})
+}
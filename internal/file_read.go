@@ -0,0 +1,48 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// MetadataFileRead is the [Match.Metadata] key that identifies the file(s) apparently
+// read (e.g. /etc/passwd), only set when the [profile.Profile] looks for file contents
+// (see [profile.GrepTypeFileRead]), and these are found within the responses.
+const MetadataFileRead = "file"
+
+// FilesRead returns the files apparently read (e.g. /etc/passwd) by the given responses,
+// according to the File Read greps of the given [profile.Profile] (see [match.FilesRead]),
+// if any, and the given payload (empty if none). So, these can be reported along with
+// the match (see [MetadataFileRead]).
+func FilesRead(ctx context.Context, prof profile.Profile, res []*response.Response, payload string) []string {
+	if prof == nil {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeFileRead)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var p *string
+	if len(payload) > 0 {
+		p = &payload
+	}
+
+	var files []string
+	for _, g := range greps {
+		for _, r := range res {
+			for _, f := range match.FilesRead(ctx, g, r, p) {
+				if !slices.In(files, f) {
+					files = append(files, f)
+				}
+			}
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,34 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestFilesRead(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	res := []*response.Response{
+		nil,
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte("root:x:0:0:root:/root:/bin/bash\n")},
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte("[boot loader]\r\ntimeout=30\r\n[operating systems]\r\n")},
+	}
+
+	fileRead := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,File Read,,"}}
+	assert.Equal(t, []string{"/etc/passwd", `C:\boot.ini`}, scan.FilesRead(ctx, fileRead, res, ""))
+
+	// Only the files targeted by the payload, if any, are reported.
+	assert.Equal(t, []string{"/etc/passwd"}, scan.FilesRead(ctx, fileRead, res, "../../../etc/passwd"))
+
+	// Only profiles looking for file contents report those.
+	other := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Simple String,,root"}}
+	assert.Empty(t, scan.FilesRead(ctx, other, res, ""))
+}
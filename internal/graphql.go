@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// MetadataGraphQLTypes is the [Match.Metadata] key that identifies the amount of types
+// exposed by a GraphQL introspection result, only set when the [profile.Profile] looks
+// for GraphQL introspection (see [profile.GrepTypeGraphQLIntrospect]), and any is found.
+const MetadataGraphQLTypes = "graphql_types"
+
+// GraphQLIntrospectionQuery is the GraphQL introspection query (i.e. __schema) sent to look for
+// those endpoints with introspection enabled (see [Config.GraphQLIntrospection]). It is kept
+// small, as the types exposed are just counted (see [match.GraphQLTypes]).
+const GraphQLIntrospectionQuery = `query IntrospectionQuery { __schema { queryType { name } mutationType { name } types { kind name } } }`
+
+// GraphQLIntrospectionRequest returns a copy of the given request turned into a GraphQL
+// introspection query (see [GraphQLIntrospectionQuery]): sent as POST, with the query as
+// JSON body, to the same url (and path).
+func GraphQLIntrospectionRequest(req request.Request) request.Request {
+	// It cannot fail, as it is a plain string.
+	body, _ := json.Marshal(map[string]string{"query": GraphQLIntrospectionQuery})
+
+	// Headers are removed first, as these could be present with a different casing.
+	introspection := request.WithoutHeader("Content-Type")(request.WithoutHeader("Content-Length")(req))
+	introspection.Method = http.MethodPost
+	introspection.SetBody(body)
+	introspection.SetHeader("Content-Type", "application/json")
+
+	return introspection
+}
+
+// GraphQLIntrospectionProfile returns the (built-in) [profile.Response] the matches reported while
+// probing GraphQL introspection (see [Config.GraphQLIntrospection]) belong to, built on top of the
+// GraphQL Introspection grep, so the types exposed are reported along with the match (see [GraphQLTypes]).
+func GraphQLIntrospectionProfile() *profile.Response {
+	return &profile.Response{
+		Name:    "GraphQL Introspection",
+		Enabled: true,
+		Type:    profile.TypePassiveRes,
+		Tags:    []string{"graphql", "exposure"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,", profile.GrepTypeGraphQLIntrospect),
+		},
+		IssueName:       "GraphQL introspection enabled",
+		IssueSeverity:   "Medium",
+		IssueConfidence: "Certain",
+		IssueDetail: "The GraphQL endpoint answers introspection queries (__schema), exposing its whole schema: " +
+			"the queries, mutations and types available. The amount of types exposed is reported along with the finding.",
+		RemediationDetail: "Disable introspection in production, or restrict it to authenticated (and authorized) clients.",
+	}
+}
+
+// GraphQLTypes returns the amount of types exposed by each of the given responses that is a valid
+// GraphQL introspection result (see [match.GraphQLTypes]), if the given [profile.Profile] looks for
+// GraphQL introspection. So, these can be reported along with the match (see [MetadataGraphQLTypes]).
+func GraphQLTypes(prof profile.Profile, res []*response.Response) []string {
+	if prof == nil || len(profile.GrepsOfType(prof, profile.GrepTypeGraphQLIntrospect)) == 0 {
+		return nil
+	}
+
+	var types []string
+	for _, r := range res {
+		if count, ok := match.GraphQLTypes(r); ok {
+			types = append(types, strconv.Itoa(count))
+		}
+	}
+
+	return types
+}
@@ -0,0 +1,129 @@
+package scan_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestMatchMetadata_GraphQLIntrospection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	reqs := []*request.Request{{Method: "POST", Path: "/graphql", Proto: "HTTP/1.1"}}
+	res := []*response.Response{{
+		Proto:  "HTTP/1.1",
+		Code:   200,
+		Status: "OK",
+		Body:   []byte(`{"data":{"__schema":{"queryType":{"name":"Query"},"types":[{"kind":"OBJECT","name":"Query"},{"kind":"SCALAR","name":"ID"}]}}}`),
+	}}
+
+	graphql := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,GraphQL Introspection,,"}}
+	metadata := scan.MatchMetadata(ctx, map[string]string{"team": "red"}, graphql, reqs, res, "")
+	assert.Equal(t, map[string]string{"team": "red", scan.MetadataGraphQLTypes: "2"}, metadata)
+
+	// Responses other than introspection results aren't reported.
+	disabled := []*response.Response{{Proto: "HTTP/1.1", Code: 400, Status: "Bad Request", Body: []byte(`{"errors":[{"message":"introspection disabled"}]}`)}}
+	metadata = scan.MatchMetadata(ctx, nil, graphql, reqs, disabled, "")
+	assert.Empty(t, metadata)
+
+	// Only profiles looking for GraphQL introspection report those.
+	simple := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Simple String,,__schema"}}
+	assert.Empty(t, scan.GraphQLTypes(simple, res))
+}
+
+func TestGraphQLIntrospectionRequest(t *testing.T) {
+	t.Parallel()
+
+	req := request.WithOptions("http://example.com/graphql?debug=1", request.WithHeader("content-type", "text/plain"))
+	introspection := scan.GraphQLIntrospectionRequest(req)
+
+	assert.Equal(t, "POST", introspection.Method)
+	assert.Equal(t, "/graphql?debug=1", introspection.Path)
+	assert.Equal(t, "application/json", introspection.Header("Content-Type"))
+	assert.NotContains(t, introspection.Headers, "content-type")
+	assert.Contains(t, string(introspection.Body), "__schema")
+
+	// The original request is left untouched.
+	assert.Equal(t, "GET", req.Method)
+	assert.Empty(t, req.Body)
+}
+
+func TestRunner_GraphQLIntrospection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for i, u := range []string{"http://example.com/graphql", "http://example.com/rest"} {
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, i, request.WithOptions(u), nil)))
+	}
+
+	// Only the GraphQL endpoint answers the introspection query,
+	// and there are no active profiles, so only those are sent.
+	requester := &graphQLRequester{}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, GraphQLIntrospection: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{
+			{
+				Name:          "Admin panel",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,admin panel"},
+				IssueName:     "Admin panel",
+				IssueSeverity: "High",
+			},
+		}))
+	require.NoError(t, r.Start())
+
+	require.Len(t, requester.reqs, 2)
+	for _, req := range requester.reqs {
+		require.Equal(t, "POST", req.Method)
+	}
+
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "GraphQL introspection enabled", matches[0].IssueName)
+	require.Equal(t, "http://example.com/graphql", matches[0].URL)
+	require.Equal(t, "3", matches[0].Metadata[scan.MetadataGraphQLTypes])
+}
+
+type graphQLRequester struct {
+	sync.Mutex
+	reqs []request.Request
+}
+
+func (gr *graphQLRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	gr.Lock()
+	defer gr.Unlock()
+	gr.reqs = append(gr.reqs, *req)
+
+	if strings.HasPrefix(req.Path, "/graphql") && strings.Contains(string(req.Body), "__schema") {
+		return response.Response{Code: 200, Body: []byte(`{"data":{"__schema":{"queryType":{"name":"Query"},"types":[{"name":"Query"},{"name":"User"},{"name":"String"}]}}}`)}, nil
+	}
+
+	return response.Response{Code: 404, Body: []byte("Not Found")}, nil
+}
@@ -0,0 +1,223 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// FindingsGrouping defines how the findings are grouped into a single one
+// when written to the scan outputs (see [WithFindingsGrouping]).
+type FindingsGrouping string
+
+const (
+	// FindingsGroupingNone means no grouping, so every finding is written on its own.
+	FindingsGroupingNone FindingsGrouping = ""
+	// FindingsGroupingByURLProfile means the findings sharing URL and profile are grouped
+	// into a single one, with the list of entrypoints affected (see [AffectedEntrypoint]).
+	FindingsGroupingByURLProfile FindingsGrouping = "by-url-profile"
+)
+
+// ErrInvalidFindingsGrouping is the error returned when the findings grouping is unknown.
+var ErrInvalidFindingsGrouping = errors.New("invalid findings grouping")
+
+// ParseFindingsGrouping returns the [FindingsGrouping] with the given name,
+// or an error if it is unknown. Empty means no grouping (see [FindingsGroupingNone]).
+func ParseFindingsGrouping(s string) (FindingsGrouping, error) {
+	switch g := FindingsGrouping(s); g {
+	case FindingsGroupingNone, FindingsGroupingByURLProfile:
+		return g, nil
+	default:
+		return FindingsGroupingNone, fmt.Errorf("%w: %s, expected: %s", ErrInvalidFindingsGrouping, s, FindingsGroupingByURLProfile)
+	}
+}
+
+// AffectedEntrypoint describes each of the findings grouped into a single one
+// (see [FindingsGrouping]), by the entrypoint affected (i.e. the insertion point,
+// the param and the payload), along with the evidence found in the responses.
+type AffectedEntrypoint struct {
+	ID             string
+	InsertionPoint string
+	Param          string
+	Payload        string
+	RequestIDs     []string
+	Evidence       []string
+}
+
+// maxEvidenceLen is the maximum length of each evidence (see [AffectedEntrypoint]),
+// as occurrences might span large portions of the responses (e.g. regular expressions).
+const maxEvidenceLen = 256
+
+// GroupMatches returns the given [Match] instances grouped as defined by the given
+// [FindingsGrouping], in the order the first finding of each group was given. Each
+// grouped finding is the first one of its group, with the [Match.AffectedEntrypoints]
+// describing all of them (including itself).
+//
+// With no grouping (see [FindingsGroupingNone]), the given matches are returned as is.
+func GroupMatches(matches []Match, grouping FindingsGrouping) []Match {
+	if grouping == FindingsGroupingNone {
+		return matches
+	}
+
+	type key struct{ url, profile string }
+
+	var (
+		grouped = make([]Match, 0, len(matches))
+		indexes = make(map[key]int)
+	)
+
+	for _, m := range matches {
+		k := key{url: m.URL, profile: m.ProfileName}
+
+		idx, ok := indexes[k]
+		if !ok {
+			idx = len(grouped)
+			indexes[k] = idx
+			grouped = append(grouped, m)
+			grouped[idx].AffectedEntrypoints = nil
+		}
+
+		grouped[idx].AffectedEntrypoints = append(grouped[idx].AffectedEntrypoints, affectedEntrypoint(m))
+	}
+
+	return grouped
+}
+
+// affectedEntrypoint returns the [AffectedEntrypoint] described by the given [Match].
+func affectedEntrypoint(m Match) AffectedEntrypoint {
+	ae := AffectedEntrypoint{
+		ID:         m.ID,
+		Param:      m.IssueParam,
+		Payload:    m.Payload,
+		RequestIDs: m.RequestIDs,
+		Evidence:   matchEvidence(m),
+	}
+
+	if m.Origin != nil {
+		ae.InsertionPoint = m.Origin.InsertionPoint
+	}
+
+	return ae
+}
+
+// matchEvidence returns the (unique) substrings of the [Match.Responses]
+// pointed by its [Match.Occurrences], truncated to maxEvidenceLen.
+func matchEvidence(m Match) []string {
+	var (
+		evidence []string
+		seen     = make(map[string]struct{})
+	)
+
+	for i, occurrences := range m.Occurrences {
+		if i >= len(m.Responses) || m.Responses[i] == nil {
+			continue
+		}
+
+		b := m.Responses[i].Bytes()
+		for _, occ := range occurrences {
+			if occ[0] < 0 || occ[0] >= occ[1] || occ[1] > len(b) {
+				continue
+			}
+
+			s := string(b[occ[0]:occ[1]])
+			if len(s) > maxEvidenceLen {
+				s = s[:maxEvidenceLen] + "..."
+			}
+
+			if _, ok := seen[s]; ok {
+				continue
+			}
+
+			seen[s] = struct{}{}
+			evidence = append(evidence, s)
+		}
+	}
+
+	return evidence
+}
+
+// WithFindingsGrouping decorates the given [FileSystem], so the [Match] instances loaded
+// from it are grouped as defined by the given [FindingsGrouping] (see [GroupMatches]),
+// while the stored ones are kept as is. So, it's meant to be used to write the scan
+// outputs. The [Stats] loaded from it report the amount of grouped findings too
+// (see [Stats.NumOfGroupedMatches]). With no grouping, the given [FileSystem] is
+// returned as is.
+func WithFindingsGrouping(fs FileSystem, grouping FindingsGrouping) FileSystem {
+	if grouping == FindingsGroupingNone {
+		return fs
+	}
+
+	return groupingFS{FileSystem: fs, grouping: grouping}
+}
+
+type groupingFS struct {
+	FileSystem
+	grouping FindingsGrouping
+}
+
+func (fs groupingFS) LoadStats(ctx context.Context) (*Stats, error) {
+	stats, err := fs.FileSystem.LoadStats(ctx)
+	if err != nil || stats == nil {
+		return stats, err
+	}
+
+	matches, err := fs.LoadMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.NumOfGroupedMatches = len(matches)
+
+	return stats, nil
+}
+
+func (fs groupingFS) LoadMatches(ctx context.Context) ([]Match, error) {
+	matches, err := fs.FileSystem.LoadMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupMatches(matches, fs.grouping), nil
+}
+
+// LoadMatch returns the grouped [Match] the one with the given id belongs to, if any.
+func (fs groupingFS) LoadMatch(ctx context.Context, id string) (*Match, error) {
+	matches, err := fs.LoadMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range matches {
+		for _, ae := range m.AffectedEntrypoints {
+			if ae.ID == id {
+				return &m, nil
+			}
+		}
+	}
+
+	return fs.FileSystem.LoadMatch(ctx, id)
+}
+
+func (fs groupingFS) MatchesIterator(ctx context.Context) (chan Match, CloseFunc, error) {
+	matches, err := fs.LoadMatches(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan Match)
+
+	go func() {
+		defer close(ch)
+
+		for _, m := range matches {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- m:
+			}
+		}
+	}()
+
+	return ch, CloseFunc(cancel), nil
+}
@@ -0,0 +1,117 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+func TestGroupMatches(t *testing.T) {
+	t.Parallel()
+
+	res := &response.Response{Code: 500, Body: []byte("You have an error in your SQL syntax")}
+	body := string(res.Bytes())
+
+	sqli := func(id, insertionPoint, param string) scan.Match {
+		return scan.Match{
+			ID:          id,
+			URL:         "http://example.org/search",
+			ProfileName: "SQLi",
+			IssueParam:  param,
+			Payload:     "'",
+			Responses:   []*response.Response{res},
+			Occurrences: [][]occurrence.Occurrence{occurrence.Find(body, "SQL syntax")},
+			Origin:      &scan.MatchOrigin{InsertionPoint: insertionPoint},
+		}
+	}
+
+	matches := []scan.Match{
+		sqli("1", "Param URL Value", "q"),
+		{ID: "2", URL: "http://example.org/search", ProfileName: "XSS"},
+		sqli("3", "Param Body Value", "page"),
+		{ID: "4", URL: "http://example.org/other", ProfileName: "SQLi"},
+	}
+
+	t.Run("none", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, matches, scan.GroupMatches(matches, scan.FindingsGroupingNone))
+	})
+
+	t.Run("by url and profile", func(t *testing.T) {
+		t.Parallel()
+
+		grouped := scan.GroupMatches(matches, scan.FindingsGroupingByURLProfile)
+		require.Len(t, grouped, 3)
+
+		// Groups are sorted by their first finding, which is the one grouped.
+		assert.Equal(t, []string{"1", "2", "4"}, []string{grouped[0].ID, grouped[1].ID, grouped[2].ID})
+		assert.Equal(t, []scan.AffectedEntrypoint{
+			{ID: "1", InsertionPoint: "Param URL Value", Param: "q", Payload: "'", Evidence: []string{"SQL syntax"}},
+			{ID: "3", InsertionPoint: "Param Body Value", Param: "page", Payload: "'", Evidence: []string{"SQL syntax"}},
+		}, grouped[0].AffectedEntrypoints)
+		assert.Equal(t, []scan.AffectedEntrypoint{{ID: "2"}}, grouped[1].AffectedEntrypoints)
+	})
+}
+
+func TestParseFindingsGrouping(t *testing.T) {
+	t.Parallel()
+
+	grouping, err := scan.ParseFindingsGrouping("by-url-profile")
+	require.NoError(t, err)
+	assert.Equal(t, scan.FindingsGroupingByURLProfile, grouping)
+
+	_, err = scan.ParseFindingsGrouping("by-host")
+	require.ErrorIs(t, err, scan.ErrInvalidFindingsGrouping)
+}
+
+func TestWithFindingsGrouping(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, fs.StoreMatch(ctx, scan.Match{ID: id, URL: "http://example.org/", ProfileName: "SQLi"}))
+	}
+	require.NoError(t, fs.StoreStats(ctx, &scan.Stats{NumOfMatches: 3}))
+
+	grouped := scan.WithFindingsGrouping(fs, scan.FindingsGroupingByURLProfile)
+
+	var iterated []scan.Match
+	ch, closeIt, err := grouped.MatchesIterator(ctx)
+	require.NoError(t, err)
+	for m := range ch {
+		iterated = append(iterated, m)
+	}
+	closeIt()
+
+	require.Len(t, iterated, 1)
+	assert.Len(t, iterated[0].AffectedEntrypoints, 3)
+
+	m, err := grouped.LoadMatch(ctx, "3")
+	require.NoError(t, err)
+	assert.Equal(t, "1", m.ID)
+
+	stats, err := grouped.LoadStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.NumOfMatches)
+	assert.Equal(t, 1, stats.NumOfGroupedMatches)
+
+	// The stored matches are kept as is.
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+
+	// With no grouping, the file system is returned as is.
+	assert.Same(t, fs, scan.WithFindingsGrouping(fs, scan.FindingsGroupingNone))
+}
@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// ErrInvalidHeaderPropagation is the error returned by [ParseHeaderPropagation]
+// when the given rule cannot be parsed.
+var ErrInvalidHeaderPropagation = errors.New("invalid header propagation rule")
+
+// headerPropagationPrefix is the prefix of the source of every [HeaderPropagation] rule.
+const headerPropagationPrefix = "response."
+
+// HeaderPropagation is a rule that sets the value captured from a response (see [LoginExtractor]),
+// either from a header or from a cookie (Key is the header or cookie name), as the given Header
+// of the following requests sent to the same host (e.g. to echo an anti-CSRF token).
+type HeaderPropagation struct {
+	Header string
+	From   string
+	Key    string
+}
+
+// ParseHeaderPropagation parses the given [HeaderPropagation] rule, defined as the header
+// set, followed by the source of its value, either a response header or a response cookie,
+// e.g. X-CSRF: response.header:X-CSRF-Token, or X-XSRF-Token: response.cookie:XSRF-TOKEN.
+func ParseHeaderPropagation(rule string) (HeaderPropagation, error) {
+	header, source, found := strings.Cut(rule, ":")
+	header, source = strings.TrimSpace(header), strings.TrimSpace(source)
+	if !found || len(header) == 0 || strings.ContainsAny(header, " \t") {
+		return HeaderPropagation{}, fmt.Errorf("%w: %s, expected: Header: response.header:Name (or response.cookie:Name)", ErrInvalidHeaderPropagation, rule)
+	}
+
+	from, key, found := strings.Cut(strings.TrimPrefix(source, headerPropagationPrefix), ":")
+	key = strings.TrimSpace(key)
+
+	switch {
+	case !strings.HasPrefix(source, headerPropagationPrefix) || !found:
+		return HeaderPropagation{}, fmt.Errorf("%w: %s, expected: Header: response.header:Name (or response.cookie:Name)", ErrInvalidHeaderPropagation, rule)
+	case from != ExtractFromHeader && from != ExtractFromCookie:
+		return HeaderPropagation{}, fmt.Errorf("%w: %s, the source must be either response.header or response.cookie", ErrInvalidHeaderPropagation, rule)
+	case len(key) == 0:
+		return HeaderPropagation{}, fmt.Errorf("%w: %s, with no %s name", ErrInvalidHeaderPropagation, rule, from)
+	}
+
+	return HeaderPropagation{Header: header, From: from, Key: key}, nil
+}
+
+// HeaderPropagator keeps the latest values captured from the responses, per host, according
+// to the [HeaderPropagation] rules, and sets them into the following requests sent to the same
+// host. Use [WithHeaderPropagation] to apply it to the requests sent.
+//
+// Until a value is captured, or if the responses don't carry it, the requests are sent as
+// is, with the previous value captured, if any. It is safe for concurrent use.
+type HeaderPropagator struct {
+	rules []HeaderPropagation
+
+	mu     sync.Mutex
+	values map[string]map[string]string
+}
+
+// NewHeaderPropagator is a constructor function that creates a new instance
+// of [HeaderPropagator] with the given [HeaderPropagation] rules.
+func NewHeaderPropagator(rules ...HeaderPropagation) *HeaderPropagator {
+	return &HeaderPropagator{
+		rules:  rules,
+		values: make(map[string]map[string]string),
+	}
+}
+
+// Value returns the latest value captured for the given header (case-insensitive),
+// from the responses of the given host, and whether there is any or not.
+func (p *HeaderPropagator) Value(host, header string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for h, value := range p.values[strings.ToLower(host)] {
+		if strings.EqualFold(h, header) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// apply sets the values captured from the given host into the given request, replacing
+// the existing ones (case-insensitive), except for the headers the payload is injected into.
+func (p *HeaderPropagator) apply(req *request.Request, host string, injected []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for header, value := range p.values[host] {
+		if isInjectedHeader(header, injected) {
+			continue
+		}
+
+		for key := range req.Headers {
+			if key != header && strings.EqualFold(key, header) {
+				req.DeleteHeader(key)
+			}
+		}
+
+		req.SetHeader(header, value)
+	}
+}
+
+// capture keeps the values carried by the given response, from the given host, if any.
+func (p *HeaderPropagator) capture(ctx context.Context, host string, res response.Response) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, rule := range p.rules {
+		value, ok := LoginExtractor{From: rule.From, Key: rule.Key}.extract(res)
+		if !ok {
+			continue
+		}
+
+		if _, ok := p.values[host]; !ok {
+			p.values[host] = make(map[string]string)
+		}
+
+		if p.values[host][rule.Header] != value {
+			logger.For(ctx).Debugf("Header propagation, value captured for host %s: %s (from %s %s)", host, rule.Header, rule.From, rule.Key)
+			p.values[host][rule.Header] = value
+		}
+	}
+}
+
+// WithHeaderPropagation decorates the given [RequesterBuilder], so every request sent through
+// the built [Requester] carries the values captured by the given [HeaderPropagator] from the
+// previous responses of the same host, and every response got is captured, in turn.
+//
+// The headers are set into the given request, so these are also present on the requests
+// attached to the scan results, e.g. [Match.Requests]. Redirects followed are requests
+// sent to the same (or to a different) host, so these are also covered.
+func WithHeaderPropagation(fn RequesterBuilder, p *HeaderPropagator) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return propagationRequester{Requester: requester, propagator: p}, nil
+	}
+}
+
+type propagationRequester struct {
+	Requester
+	propagator *HeaderPropagator
+}
+
+func (r propagationRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	host := throttleHost(req)
+	r.propagator.apply(req, host, injectedHeaders(ctx))
+
+	res, err := r.Requester.Do(ctx, req)
+	if err == nil {
+		r.propagator.capture(ctx, host, res)
+	}
+
+	return res, err
+}
@@ -0,0 +1,120 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestParseHeaderPropagation(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		rule     string
+		expected scan.HeaderPropagation
+		err      bool
+	}{
+		"response header": {
+			rule:     "X-CSRF: response.header:X-CSRF-Token",
+			expected: scan.HeaderPropagation{Header: "X-CSRF", From: scan.ExtractFromHeader, Key: "X-CSRF-Token"},
+		},
+		"response cookie": {
+			rule:     "x-xsrf-token:response.cookie:XSRF-TOKEN",
+			expected: scan.HeaderPropagation{Header: "x-xsrf-token", From: scan.ExtractFromCookie, Key: "XSRF-TOKEN"},
+		},
+		"no source":      {rule: "X-CSRF", err: true},
+		"unknown prefix": {rule: "X-CSRF: request.header:X-CSRF-Token", err: true},
+		"unknown source": {rule: "X-CSRF: response.body:token", err: true},
+		"no key":         {rule: "X-CSRF: response.header:", err: true},
+		"invalid header": {rule: "X CSRF: response.header:X-CSRF-Token", err: true},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := scan.ParseHeaderPropagation(tc.rule)
+			if tc.err {
+				require.ErrorIs(t, err, scan.ErrInvalidHeaderPropagation)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rule)
+		})
+	}
+}
+
+func TestWithHeaderPropagation(t *testing.T) {
+	t.Parallel()
+
+	header, err := scan.ParseHeaderPropagation("X-CSRF: response.header:X-CSRF-Token")
+	require.NoError(t, err)
+	cookie, err := scan.ParseHeaderPropagation("X-XSRF-Token: response.cookie:XSRF-TOKEN")
+	require.NoError(t, err)
+
+	requester := &propagationRequester{responses: []response.Response{
+		// No value yet, so nothing is captured.
+		{Code: 200},
+		{Code: 200, Headers: map[string][]string{"X-Csrf-Token": {"t1"}, "Set-Cookie": {"XSRF-TOKEN=c1; Path=/"}}},
+		// Absent, so the previous values are kept.
+		{Code: 200},
+		{Code: 200, Headers: map[string][]string{"X-Csrf-Token": {"t2"}}},
+		{Code: 200},
+	}}
+
+	propagator := scan.NewHeaderPropagator(header, cookie)
+	builder := scan.WithHeaderPropagation(func() (scan.Requester, error) {
+		return requester, nil
+	}, propagator)
+
+	send := func(rawURL string) request.Request {
+		req := request.Default(rawURL)
+
+		r, err := builder()
+		require.NoError(t, err)
+
+		_, err = r.Do(context.Background(), &req)
+		require.NoError(t, err)
+
+		return req
+	}
+
+	first := send("http://example.org/")
+	assert.Empty(t, first.Header("X-CSRF"))
+
+	send("http://example.org/")
+
+	third := send("http://example.org/")
+	assert.Equal(t, "t1", third.Header("X-CSRF"))
+	assert.Equal(t, "c1", third.Header("X-XSRF-Token"))
+
+	fourth := send("http://example.org/")
+	assert.Equal(t, "t1", fourth.Header("X-CSRF"))
+
+	// Values are kept per host.
+	other := send("http://other.example.org/")
+	assert.Empty(t, other.Header("X-CSRF"))
+
+	value, ok := propagator.Value("EXAMPLE.ORG", "x-csrf")
+	assert.True(t, ok)
+	assert.Equal(t, "t2", value)
+}
+
+type propagationRequester struct {
+	responses []response.Response
+}
+
+func (pr *propagationRequester) Do(_ context.Context, _ *request.Request) (response.Response, error) {
+	res := pr.responses[0]
+	pr.responses = pr.responses[1:]
+
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// hostRequests keeps track of the profiles whose first step is a host request (see
+// [profile.HostRequest]) already prepared for each host, so their requests are sent
+// once per host, instead of once per template. A nil *hostRequests keeps track of
+// nothing, so those are prepared for every template.
+type hostRequests struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newHostRequests() *hostRequests {
+	return &hostRequests{seen: make(map[string]struct{})}
+}
+
+// first returns whether it is the first time the given profile
+// is prepared for the host of the given [Template].
+func (hr *hostRequests) first(prof *profile.Active, tpl Template) bool {
+	if hr == nil {
+		return true
+	}
+
+	key := prof.Name + "\x00" + templateHost(tpl)
+
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	if _, ok := hr.seen[key]; ok {
+		return false
+	}
+
+	hr.seen[key] = struct{}{}
+
+	return true
+}
+
+// hostRequestFromStep returns the request defined by the given step (see [profile.StepRequest]),
+// built on top of the template's request, so it keeps its url (i.e. host) and headers (e.g. Host,
+// User-Agent or cookies), but with the step's method (GET by default), path, headers and body.
+func hostRequestFromStep(tpl Template, step profile.Step) request.Request {
+	req := tpl.Request.Clone()
+
+	req.Method = step.Request.Method
+	if len(req.Method) == 0 {
+		req.Method = http.MethodGet
+	}
+
+	req.Path = step.Request.Path
+
+	// The template's body (if any) doesn't belong to the step's request.
+	req = request.WithoutHeader("Content-Length")(request.WithoutHeader("Content-Type")(req))
+	req.Body = nil
+	req.SetBody([]byte(step.Request.Body))
+
+	for _, h := range step.Request.Headers {
+		name, value, _ := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+
+		req = request.WithoutHeader(name)(req)
+		req.SetHeader(name, strings.TrimSpace(value))
+	}
+
+	return req
+}
@@ -0,0 +1,96 @@
+package scan_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestRunner_HostRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for i, u := range []string{"http://example.com/", "http://example.com/about", "http://example.org/"} {
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, i, request.WithOptions(u), nil)))
+	}
+
+	// Only example.com exposes its .git directory.
+	requester := &gitRequester{exposed: "example.com"}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithActiveProfiles([]*profile.Active{
+			{
+				Name:    "Exposed .git",
+				Enabled: true,
+				Type:    profile.TypeActive,
+				Steps: []profile.Step{{
+					RequestType:   profile.HostRequest,
+					Request:       profile.StepRequest{Path: "/.git/HEAD", Headers: []string{"Accept: */*"}},
+					Greps:         []string{"true,,Simple String,,ref: refs/"},
+					ShowAlert:     profile.ShowAlertAlways,
+					IssueName:     "Exposed .git directory",
+					IssueSeverity: "Medium",
+				}},
+			},
+		}))
+	require.NoError(t, r.Start())
+
+	// The request is sent once per host, not once per template.
+	require.Len(t, requester.reqs, 2)
+	for _, req := range requester.reqs {
+		require.Equal(t, "GET", req.Method)
+		require.Equal(t, "/.git/HEAD", req.Path)
+		require.Equal(t, "*/*", req.Header("Accept"))
+	}
+
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "Exposed .git directory", matches[0].IssueName)
+	require.Equal(t, "http://example.com/", matches[0].URL)
+
+	stats, err := fs.LoadStats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.NumOfTotalRequests)
+}
+
+// gitRequester records the requests sent, and responds to /.git/HEAD with
+// a git reference, only for the exposed host.
+type gitRequester struct {
+	sync.Mutex
+	exposed string
+	reqs    []request.Request
+}
+
+func (gr *gitRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	gr.Lock()
+	defer gr.Unlock()
+	gr.reqs = append(gr.reqs, *req)
+
+	if req.Path == "/.git/HEAD" && req.Header("Host") == gr.exposed {
+		return response.Response{Code: 200, Body: []byte("ref: refs/heads/main\n")}, nil
+	}
+
+	return response.Response{Code: 404}, nil
+}
@@ -10,6 +10,7 @@ type FileSystem interface {
 	FileSystemMatches
 	FileSystemSummaries
 	FileSystemTemplates
+	FileSystemExchanges
 	Cleanup(ctx context.Context) error
 }
 
@@ -33,6 +34,7 @@ type FileSystemErrors interface {
 type FileSystemMatches interface {
 	StoreMatch(ctx context.Context, match Match) error
 	LoadMatches(ctx context.Context) ([]Match, error)
+	LoadMatch(ctx context.Context, id string) (*Match, error)
 	MatchesIterator(ctx context.Context) (chan Match, CloseFunc, error)
 }
 
@@ -58,6 +60,13 @@ type FileSystemTemplates interface {
 	TemplatesIterator(ctx context.Context) (chan Template, error)
 }
 
+// FileSystemExchanges defines the behavior expected from a [scan] file system
+// to store and retrieve [Exchange] instances.
+type FileSystemExchanges interface {
+	StoreExchange(ctx context.Context, exchange Exchange) error
+	ExchangesIterator(ctx context.Context) (chan Exchange, CloseFunc, error)
+}
+
 // CloseFunc is a function that can be used to close something that's open.
 // For instance, a channel, a socket or a file descriptor.
 //
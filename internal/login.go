@@ -0,0 +1,342 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	stdurl "net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/dotenv"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+var (
+	// ErrInvalidLoginSequence is the error returned by [ReadLoginSequence]
+	// when the [LoginSequence] cannot be parsed, or it isn't valid.
+	ErrInvalidLoginSequence = errors.New("invalid login sequence")
+
+	// ErrLoginFailed is the error returned by [LoginSequence.Run] when any of the
+	// steps fails, like when the request cannot be sent, the response status is not
+	// the expected one, or any of the values cannot be extracted.
+	ErrLoginFailed = errors.New("login sequence failed")
+)
+
+// Login extractor sources (see [LoginExtractor.From]).
+const (
+	ExtractFromBody   = "body"
+	ExtractFromHeader = "header"
+	ExtractFromCookie = "cookie"
+)
+
+var loginVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// LoginSequence is a sequence of requests (see [LoginStep]) performed before the scan,
+// to authenticate against the target, e.g. to submit a login form and to capture the
+// session cookie, or to request an access token.
+//
+// The cookies set by the responses are kept in a cookie jar, sent along with the following
+// steps, and the values captured by the extractors (see [LoginExtractor]) are expanded into
+// the ${NAME} references of the following steps. Both of them seed the scan (see [LoginSession]).
+//
+// For instance:
+//
+//	{
+//	  "steps": [
+//	    {
+//	      "method": "POST",
+//	      "url": "https://example.org/api/login",
+//	      "headers": ["Content-Type: application/json"],
+//	      "body": "{\"user\": \"${USER}\", \"pass\": \"${PASS}\"}",
+//	      "expectStatus": [200],
+//	      "extract": [{"name": "TOKEN", "from": "body", "regex": "\"token\":\\s*\"([^\"]+)\""}]
+//	    }
+//	  ]
+//	}
+type LoginSequence struct {
+	Steps []LoginStep `json:"steps"`
+}
+
+// LoginStep is each of the requests of a [LoginSequence], defined by its method (GET by
+// default), URL, headers (Key: Value) and body, all of them with ${NAME} references expanded.
+//
+// If ExpectStatus is defined, the step fails unless the response status code is any of those.
+// Redirects aren't followed, so the cookies set by the redirect itself are kept.
+type LoginStep struct {
+	Method       string           `json:"method"`
+	URL          string           `json:"url"`
+	Headers      []string         `json:"headers"`
+	Body         string           `json:"body"`
+	ExpectStatus []int            `json:"expectStatus"`
+	Extract      []LoginExtractor `json:"extract"`
+}
+
+// LoginExtractor captures a value from the response of a [LoginStep], named as Name, either
+// from the body (by default), from a header or from a cookie (Key is the header or cookie name).
+//
+// If Regex is defined, the value is the first capturing group (or the whole match, if none)
+// of its first match within the body, header or cookie value. It is mandatory for the body.
+//
+// If nothing is extracted, the step fails, so the scan isn't performed unauthenticated.
+type LoginExtractor struct {
+	Name  string `json:"name"`
+	From  string `json:"from"`
+	Key   string `json:"key"`
+	Regex string `json:"regex"`
+
+	regex *regexp.Regexp
+}
+
+// ReadLoginSequence reads the [LoginSequence] from the given [io.Reader], as JSON,
+// and validates it, including the extractors' regular expressions.
+func ReadLoginSequence(r io.Reader) (LoginSequence, error) {
+	var seq LoginSequence
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&seq); err != nil {
+		return LoginSequence{}, fmt.Errorf("%w: %s", ErrInvalidLoginSequence, err.Error())
+	}
+
+	if len(seq.Steps) == 0 {
+		return LoginSequence{}, fmt.Errorf("%w: no steps defined", ErrInvalidLoginSequence)
+	}
+
+	for i := range seq.Steps {
+		if err := seq.Steps[i].validate(); err != nil {
+			return LoginSequence{}, fmt.Errorf("%w: step %d: %s", ErrInvalidLoginSequence, i+1, err.Error())
+		}
+	}
+
+	return seq, nil
+}
+
+func (s *LoginStep) validate() error {
+	if len(strings.TrimSpace(s.URL)) == 0 {
+		return errors.New("no url defined") //nolint:err113
+	}
+
+	for _, header := range s.Headers {
+		if !strings.Contains(header, ":") {
+			return fmt.Errorf("invalid header, it must be Key: Value: %s", header) //nolint:err113
+		}
+	}
+
+	for i := range s.Extract {
+		e := &s.Extract[i]
+		if len(e.From) == 0 {
+			e.From = ExtractFromBody
+		}
+
+		switch {
+		case !loginVarNameRegex.MatchString(e.Name):
+			return fmt.Errorf("invalid extractor name: %q", e.Name) //nolint:err113
+		case !slices.In([]string{ExtractFromBody, ExtractFromHeader, ExtractFromCookie}, e.From):
+			return fmt.Errorf("invalid extractor (%s) source, it must be body, header or cookie: %s", e.Name, e.From) //nolint:err113
+		case e.From != ExtractFromBody && len(e.Key) == 0:
+			return fmt.Errorf("extractor (%s) with no %s name (key)", e.Name, e.From) //nolint:err113
+		case e.From == ExtractFromBody && len(e.Regex) == 0:
+			return fmt.Errorf("extractor (%s) with no regex", e.Name) //nolint:err113
+		}
+
+		if len(e.Regex) > 0 {
+			regex, err := regexp.Compile(e.Regex)
+			if err != nil {
+				return fmt.Errorf("invalid extractor (%s) regex: %s", e.Name, err.Error()) //nolint:err113
+			}
+			e.regex = regex
+		}
+	}
+
+	return nil
+}
+
+// LoginSession is the result of a [LoginSequence], used to seed the scan: the values
+// captured by the extractors (expanded into the ${NAME} references of the request
+// templates) and the cookies set (sent along with the request templates).
+type LoginSession struct {
+	Variables map[string]string
+	jar       http.CookieJar
+}
+
+// Run performs the [LoginSequence] steps, in order, through the [Requester] built with the given
+// [RequesterBuilder], with the given variables (e.g. credentials) expanded into the ${NAME} references,
+// along with those captured by the previous steps. It stops as soon as any step fails, and returns
+// [ErrLoginFailed], so the scan can be aborted instead of being performed unauthenticated.
+func (seq LoginSequence) Run(ctx context.Context, fn RequesterBuilder, vars map[string]string) (*LoginSession, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &LoginSession{Variables: make(map[string]string), jar: jar}
+
+	expansion := make(map[string]string, len(vars))
+	for k, v := range vars {
+		expansion[k] = v
+	}
+
+	for i, step := range seq.Steps {
+		req, reqURL, err := step.request(expansion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: step %d: %s", ErrLoginFailed, i+1, err.Error())
+		}
+
+		setCookies(&req, jar.Cookies(reqURL))
+
+		logger.For(ctx).Infof("Login sequence, step %d out of %d: %s %s", i+1, len(seq.Steps), req.Method, req.URL)
+
+		res, err := step.do(ctx, fn, &req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: step %d (%s %s): %s", ErrLoginFailed, i+1, req.Method, req.URL, err.Error())
+		}
+
+		jar.SetCookies(reqURL, (&http.Response{Header: http.Header{"Set-Cookie": res.Headers["Set-Cookie"]}}).Cookies())
+
+		for _, e := range step.Extract {
+			value, ok := e.extract(res)
+			if !ok {
+				return nil, fmt.Errorf("%w: step %d (%s %s): no value extracted for %s (from %s)", ErrLoginFailed, i+1, req.Method, req.URL, e.Name, e.From)
+			}
+
+			logger.For(ctx).Infof("Login sequence, value extracted for: %s", e.Name)
+			session.Variables[e.Name] = value
+			expansion[e.Name] = value
+		}
+	}
+
+	return session, nil
+}
+
+// request builds the [request.Request] for the [LoginStep], with the given variables expanded.
+func (s LoginStep) request(vars map[string]string) (request.Request, *stdurl.URL, error) {
+	rawURL := dotenv.Expand(strings.TrimSpace(s.URL), vars)
+
+	reqURL, err := stdurl.Parse(rawURL)
+	if err != nil || len(reqURL.Scheme) == 0 || len(reqURL.Host) == 0 {
+		return request.Request{}, nil, fmt.Errorf("invalid url: %s", rawURL) //nolint:err113
+	}
+
+	req := request.Default(rawURL)
+	if len(s.Method) > 0 {
+		req.Method = strings.ToUpper(s.Method)
+	}
+
+	for _, header := range s.Headers {
+		key, value, _ := strings.Cut(dotenv.Expand(header, vars), ":")
+		req.SetHeader(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	if len(s.Body) > 0 {
+		req.SetBody([]byte(dotenv.Expand(s.Body, vars)))
+	}
+
+	return req, reqURL, nil
+}
+
+// do sends the given [request.Request], and checks the response status, if expected.
+func (s LoginStep) do(ctx context.Context, fn RequesterBuilder, req *request.Request) (response.Response, error) {
+	requester, err := fn()
+	if err != nil {
+		return response.Response{}, err
+	}
+
+	res, err := requester.Do(ctx, req)
+	if err != nil {
+		return response.Response{}, err
+	}
+
+	if len(s.ExpectStatus) > 0 && !slices.In(s.ExpectStatus, res.Code) {
+		return response.Response{}, fmt.Errorf("unexpected response status: %d", res.Code) //nolint:err113
+	}
+
+	return res, nil
+}
+
+// extract returns the value captured by the [LoginExtractor] from the given
+// [response.Response], and whether there was any (non-empty) value or not.
+func (e LoginExtractor) extract(res response.Response) (string, bool) {
+	var value string
+
+	switch e.From {
+	case ExtractFromHeader:
+		value = http.Header(res.Headers).Get(e.Key)
+	case ExtractFromCookie:
+		for _, c := range (&http.Response{Header: http.Header{"Set-Cookie": res.Headers["Set-Cookie"]}}).Cookies() {
+			if c.Name == e.Key {
+				value = c.Value
+			}
+		}
+	default:
+		value = string(res.Body)
+	}
+
+	if e.regex != nil {
+		found := e.regex.FindStringSubmatch(value)
+		switch {
+		case len(found) == 0:
+			value = ""
+		case len(found) > 1:
+			value = found[1]
+		default:
+			value = found[0]
+		}
+	}
+
+	return value, len(value) > 0
+}
+
+// SetCookies sets the session cookies that apply to the given [request.Request] URL into its
+// Cookie header, replacing those with the same name. It does nothing if the session is nil.
+func (s *LoginSession) SetCookies(req *request.Request) {
+	if s == nil || s.jar == nil {
+		return
+	}
+
+	reqURL, err := stdurl.Parse(requestURL(req))
+	if err != nil {
+		return
+	}
+
+	setCookies(req, s.jar.Cookies(reqURL))
+}
+
+// setCookies sets the given cookies into the Cookie header of
+// the given [request.Request], replacing those with the same name.
+func setCookies(req *request.Request, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+
+	existing := req.Cookies()
+	for _, c := range cookies {
+		idx := -1
+		for i := range existing {
+			if existing[i].Name == c.Name {
+				idx = i
+			}
+		}
+
+		if idx < 0 {
+			existing = append(existing, &http.Cookie{Name: c.Name, Value: c.Value})
+		} else {
+			existing[idx].Value = c.Value
+		}
+	}
+
+	pairs := make([]string, 0, len(existing))
+	for _, c := range existing {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+
+	req.SetHeader("Cookie", strings.Join(pairs, "; "))
+}
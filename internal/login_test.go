@@ -0,0 +1,155 @@
+package scan_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+const loginSequence = `{
+  "steps": [
+    {
+      "url": "http://example.org/login",
+      "extract": [{"name": "CSRF", "from": "body", "regex": "name=\"csrf\" value=\"([^\"]+)\""}]
+    },
+    {
+      "method": "post",
+      "url": "http://example.org/login",
+      "headers": ["Content-Type: application/x-www-form-urlencoded"],
+      "body": "user=${USER}&csrf=${CSRF}",
+      "expectStatus": [302],
+      "extract": [
+        {"name": "SESSION", "from": "cookie", "key": "session"},
+        {"name": "TOKEN", "from": "header", "key": "X-Auth-Token"}
+      ]
+    }
+  ]
+}`
+
+func TestReadLoginSequence(t *testing.T) {
+	t.Parallel()
+
+	seq, err := scan.ReadLoginSequence(strings.NewReader(loginSequence))
+	require.NoError(t, err)
+	require.Len(t, seq.Steps, 2)
+	assert.Equal(t, scan.ExtractFromBody, seq.Steps[0].Extract[0].From)
+
+	tcs := map[string]string{
+		"malformed":      `{"steps": [`,
+		"unknown field":  `{"steps": [{"url": "http://example.org", "verb": "GET"}]}`,
+		"no steps":       `{"steps": []}`,
+		"no url":         `{"steps": [{"method": "GET"}]}`,
+		"invalid header": `{"steps": [{"url": "http://example.org", "headers": ["X-Api-Key"]}]}`,
+		"invalid name":   `{"steps": [{"url": "http://example.org", "extract": [{"name": "1TOKEN", "regex": "."}]}]}`,
+		"invalid source": `{"steps": [{"url": "http://example.org", "extract": [{"name": "TOKEN", "from": "status"}]}]}`,
+		"no key":         `{"steps": [{"url": "http://example.org", "extract": [{"name": "TOKEN", "from": "header"}]}]}`,
+		"no regex":       `{"steps": [{"url": "http://example.org", "extract": [{"name": "TOKEN"}]}]}`,
+		"invalid regex":  `{"steps": [{"url": "http://example.org", "extract": [{"name": "TOKEN", "regex": "("}]}]}`,
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := scan.ReadLoginSequence(strings.NewReader(tc))
+			require.ErrorIs(t, err, scan.ErrInvalidLoginSequence)
+		})
+	}
+}
+
+func TestLoginSequence_Run(t *testing.T) {
+	t.Parallel()
+
+	seq, err := scan.ReadLoginSequence(strings.NewReader(loginSequence))
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		requester := &loginRequester{}
+		session, err := seq.Run(context.Background(), requester.builder, map[string]string{"USER": "admin"})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"CSRF": "c5rf", "SESSION": "s3ss10n", "TOKEN": "t0k3n"}, session.Variables)
+
+		// Variables (including extracted values) are expanded, and cookies are sent.
+		require.Len(t, requester.reqs, 2)
+		assert.Equal(t, http.MethodPost, requester.reqs[1].Method)
+		assert.Equal(t, "user=admin&csrf=c5rf", string(requester.reqs[1].Body))
+		assert.Equal(t, "pre=1", requester.reqs[1].Header("Cookie"))
+
+		// The session cookies are set into the requests, replacing those with the same name.
+		req := request.Default("http://example.org/account")
+		req.SetHeader("Cookie", "session=old; theme=dark")
+		session.SetCookies(&req)
+		assert.Equal(t, "session=s3ss10n; theme=dark; pre=1", req.Header("Cookie"))
+
+		// Unless those don't apply to the request's host.
+		other := request.Default("http://other.org/")
+		session.SetCookies(&other)
+		assert.Empty(t, other.Header("Cookie"))
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		t.Parallel()
+
+		requester := &loginRequester{status: http.StatusUnauthorized}
+		_, err := seq.Run(context.Background(), requester.builder, nil)
+		require.ErrorIs(t, err, scan.ErrLoginFailed)
+		assert.Contains(t, err.Error(), "step 2")
+	})
+
+	t.Run("nothing extracted", func(t *testing.T) {
+		t.Parallel()
+
+		requester := &loginRequester{noToken: true}
+		_, err := seq.Run(context.Background(), requester.builder, nil)
+		require.ErrorIs(t, err, scan.ErrLoginFailed)
+		assert.Contains(t, err.Error(), "no value extracted for TOKEN")
+	})
+}
+
+// loginRequester is a [scan.Requester] that mimics a login form: a GET request
+// returns the form (with a CSRF token), and a POST request logs the user in.
+type loginRequester struct {
+	status  int
+	noToken bool
+	reqs    []request.Request
+}
+
+func (lr *loginRequester) builder() (scan.Requester, error) {
+	return lr, nil
+}
+
+func (lr *loginRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	lr.reqs = append(lr.reqs, req.Clone())
+
+	if req.Method == http.MethodGet {
+		return response.Response{
+			Code:    http.StatusOK,
+			Headers: map[string][]string{"Set-Cookie": {"pre=1; Path=/"}},
+			Body:    []byte(`<form><input type="hidden" name="csrf" value="c5rf"></form>`),
+		}, nil
+	}
+
+	status := http.StatusFound
+	if lr.status != 0 {
+		status = lr.status
+	}
+
+	headers := map[string][]string{"Set-Cookie": {"session=s3ss10n; Path=/; HttpOnly"}}
+	if !lr.noToken {
+		headers["X-Auth-Token"] = []string{"t0k3n"}
+	}
+
+	return response.Response{Code: status, Headers: headers}, nil
+}
@@ -0,0 +1,39 @@
+package scan
+
+import (
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// MetadataParseError is the [Match.Metadata] key that identifies the error(s) found while
+// parsing the responses' bodies (e.g. json: invalid character '}' ... (offset 42, line 3,
+// column 5)), only set when the [profile.Profile] checks the bodies' well-formedness
+// (see [profile.GrepTypeMalformedBody]), and these are malformed.
+const MetadataParseError = "parse_error"
+
+// ParseErrors returns the errors found while parsing the given responses' bodies, according
+// to the Malformed Body greps of the given [profile.Profile] (see [match.MalformedBody]),
+// if any. So, these can be reported along with the match (see [MetadataParseError]).
+func ParseErrors(prof profile.Profile, res []*response.Response) []string {
+	if prof == nil {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeMalformedBody)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var errs []string
+	for _, g := range greps {
+		for _, r := range res {
+			if parseErr := match.MalformedBody(g, r); parseErr != nil && !slices.In(errs, parseErr.Error()) {
+				errs = append(errs, parseErr.Error())
+			}
+		}
+	}
+
+	return errs
+}
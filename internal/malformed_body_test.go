@@ -0,0 +1,33 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	jsonHeaders := map[string][]string{"Content-Type": {"application/json"}}
+	res := []*response.Response{
+		nil,
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Headers: jsonHeaders, Body: []byte(`{"ok":true}`)},
+		{Proto: "HTTP/1.1", Code: 500, Status: "Internal Server Error", Headers: jsonHeaders, Body: []byte(`{"q":"a"b"}`)},
+	}
+
+	malformed := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Malformed Body,,"}}
+	assert.Equal(t, []string{"json: invalid character 'b' after object key:value pair (offset 8, line 1, column 9)"}, scan.ParseErrors(malformed, res))
+
+	// Only the formats looked for, if any, are reported.
+	onlyXML := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Malformed Body,,xml"}}
+	assert.Empty(t, scan.ParseErrors(onlyXML, res))
+
+	// Only profiles checking the bodies' well-formedness report those.
+	other := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Simple String,,ok"}}
+	assert.Empty(t, scan.ParseErrors(other, res))
+}
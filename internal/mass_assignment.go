@@ -0,0 +1,383 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// MetadataMassAssignment is the [Match.Metadata] key that summarizes why the parameter(s)
+// injected while probing mass assignment (see [MassAssignmentCfg]) seem to take effect,
+// e.g. isAdmin=true (body): reflected, status 200 -> 302.
+const MetadataMassAssignment = "mass_assignment"
+
+// DefaultMassAssignmentParams are the parameters injected while probing mass
+// assignment (see [MassAssignmentCfg]), unless others are given.
+var DefaultMassAssignmentParams = [][2]string{
+	{"isAdmin", "true"},
+	{"admin", "true"},
+	{"role", "admin"},
+	{"verified", "true"},
+}
+
+// pollutionCanary is the value of the parameters duplicated while probing
+// parameter pollution (see [MassAssignmentCfg.Variants]), so it can be
+// looked for (i.e. reflected) in the responses.
+const pollutionCanary = "gbhpp7391"
+
+const (
+	// MassAssignmentKindAddition is the kind of the [MassAssignmentVariant]
+	// built by adding an unexpected parameter (e.g. isAdmin=true).
+	MassAssignmentKindAddition = "mass assignment"
+	// MassAssignmentKindPollution is the kind of the [MassAssignmentVariant]
+	// built by duplicating the existing parameters (HTTP parameter pollution).
+	MassAssignmentKindPollution = "parameter pollution"
+)
+
+// MassAssignmentCfg defines whether extra parameters are injected into the query (or the body)
+// of every request, either unexpected ones (e.g. isAdmin=true) or duplicates of the existing ones,
+// to detect whether these are accepted (mass assignment) or cause divergent behavior (parameter
+// pollution), by comparing the responses with those to the request as is (i.e. the baseline).
+type MassAssignmentCfg struct {
+	Enabled bool
+	Params  [][2]string
+}
+
+// Clone returns a deep copy of the [MassAssignmentCfg] instance.
+func (c MassAssignmentCfg) Clone() MassAssignmentCfg {
+	return MassAssignmentCfg{Enabled: c.Enabled, Params: slices.Clone(c.Params)}
+}
+
+// Applies returns whether mass assignment is probed for the given [Template].
+func (c MassAssignmentCfg) Applies(tpl Template) bool {
+	return c.Enabled && tpl.Response == nil
+}
+
+// MassAssignmentVariant is each of the requests sent while probing mass assignment,
+// along with the parameter(s) injected, where, and what is looked for in the response.
+type MassAssignmentVariant struct {
+	// Kind is either [MassAssignmentKindAddition] or [MassAssignmentKindPollution].
+	Kind string
+	// Param is the parameter injected (e.g. isAdmin=true), or the
+	// parameters duplicated (e.g. id=gbhpp7391&page=gbhpp7391).
+	Param string
+	// Location is where the parameter(s) were injected into, either query or body.
+	Location string
+	// Reflection is what is looked for in the response, either the parameter
+	// name (e.g. isAdmin), or the value of the duplicated parameters.
+	Reflection string
+	Request    request.Request
+}
+
+// Variants returns the [MassAssignmentVariant] of the given request, in order: one per parameter
+// (see [MassAssignmentCfg.Params]), and another one with every existing parameter duplicated, if
+// any. Parameters are injected into the body, if it is a form or a JSON object, or into the query.
+// Duplicated JSON keys are not supported, so the JSON bodies aren't polluted.
+func (c MassAssignmentCfg) Variants(req request.Request) []MassAssignmentVariant {
+	variants := make([]MassAssignmentVariant, 0, len(c.Params)+1)
+
+	for _, p := range c.Params {
+		injected, location := addParam(req, p[0], p[1])
+		variants = append(variants, MassAssignmentVariant{
+			Kind:       MassAssignmentKindAddition,
+			Param:      p[0] + "=" + p[1],
+			Location:   location,
+			Reflection: p[0],
+			Request:    injected,
+		})
+	}
+
+	if polluted, params, location, ok := duplicateParams(req, pollutionCanary); ok {
+		variants = append(variants, MassAssignmentVariant{
+			Kind:       MassAssignmentKindPollution,
+			Param:      params,
+			Location:   location,
+			Reflection: pollutionCanary,
+			Request:    polluted,
+		})
+	}
+
+	return variants
+}
+
+// addParam returns a copy of the given request with the given parameter added,
+// either to the body (form or JSON object), or to the query, and where.
+func addParam(req request.Request, name, value string) (request.Request, string) {
+	req = req.Clone()
+
+	switch {
+	case isFormBody(req):
+		req.SetBody(appendParam(req.Body, name+"="+value))
+		return req, "body"
+	case req.HasJSONBody() && isJSONObject(req.Body):
+		req.SetBody(appendJSONField(req.Body, name, value))
+		return req, "body"
+	default:
+		req.Path = appendQueryParam(req.Path, name+"="+value)
+		return req, "query"
+	}
+}
+
+// duplicateParams returns a copy of the given request with every parameter of the body (form),
+// or of the query, duplicated with the given value, along with those, and where, if there's any.
+func duplicateParams(req request.Request, value string) (request.Request, string, string, bool) {
+	req = req.Clone()
+
+	if isFormBody(req) {
+		if keys := paramKeys(string(req.Body)); len(keys) > 0 {
+			params := duplicatedParams(keys, value)
+			req.SetBody(appendParam(req.Body, params))
+			return req, params, "body", true
+		}
+	}
+
+	if _, query, found := strings.Cut(req.Path, "?"); found {
+		if keys := paramKeys(query); len(keys) > 0 {
+			params := duplicatedParams(keys, value)
+			req.Path = appendQueryParam(req.Path, params)
+			return req, params, "query", true
+		}
+	}
+
+	return request.Request{}, "", "", false
+}
+
+func isFormBody(req request.Request) bool {
+	return strings.Contains(strings.ToLower(req.ContentType()), "application/x-www-form-urlencoded")
+}
+
+func isJSONObject(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return json.Valid(trimmed) && bytes.HasPrefix(trimmed, []byte("{"))
+}
+
+// appendParam appends the given parameter(s) to the given form (or query).
+func appendParam(form []byte, param string) []byte {
+	form = bytes.TrimRight(form, "\r\n")
+	if len(form) == 0 || bytes.HasSuffix(form, []byte("&")) {
+		return append(slices.Clone(form), param...)
+	}
+	return append(slices.Clone(form), "&"+param...)
+}
+
+// appendQueryParam appends the given parameter(s) to the query of the given path.
+func appendQueryParam(path, param string) string {
+	path, fragment, hasFragment := strings.Cut(path, "#")
+
+	switch {
+	case !strings.Contains(path, "?"):
+		path += "?" + param
+	case strings.HasSuffix(path, "?") || strings.HasSuffix(path, "&"):
+		path += param
+	default:
+		path += "&" + param
+	}
+
+	if hasFragment {
+		path += "#" + fragment
+	}
+
+	return path
+}
+
+// appendJSONField appends the given field to the given JSON object. The value is kept as is if it
+// is a valid JSON value (e.g. true, or 1), or encoded as a string otherwise (e.g. admin).
+func appendJSONField(body []byte, name, value string) []byte {
+	raw := []byte(value)
+	if !json.Valid(raw) {
+		raw, _ = json.Marshal(value)
+	}
+
+	key, _ := json.Marshal(name)
+	field := append(append(key, ':'), raw...)
+
+	trimmed := bytes.TrimSpace(body)
+	end := bytes.LastIndexByte(trimmed, '}')
+	inner := bytes.TrimSpace(trimmed[1:end])
+
+	injected := make([]byte, 0, len(trimmed)+len(field)+1)
+	injected = append(injected, trimmed[:end]...)
+	if len(inner) > 0 {
+		injected = append(injected, ',')
+	}
+	injected = append(injected, field...)
+
+	return append(injected, trimmed[end:]...)
+}
+
+// paramKeys returns the (distinct) keys of the given form (or query), in order.
+func paramKeys(form string) []string {
+	var keys []string
+	for _, pair := range strings.Split(strings.TrimRight(form, "\r\n"), "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if len(key) > 0 && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func duplicatedParams(keys []string, value string) string {
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		params = append(params, key+"="+value)
+	}
+	return strings.Join(params, "&")
+}
+
+// MassAssignmentResult is the behavior observed on the response to a [MassAssignmentVariant],
+// compared with those to the request as is (i.e. the baseline), which is sent twice, so the
+// status and body changes are only considered if these are stable across both baselines.
+type MassAssignmentResult struct {
+	Kind     string
+	Param    string
+	Location string
+	// Reflected is true if the [MassAssignmentVariant.Reflection] is present
+	// in the response body, while it wasn't in the baseline one.
+	Reflected bool
+	// StatusChanged is true if the status code differs from the baseline one.
+	StatusChanged bool
+	// BodyChanged is true if the body differs from the baseline one.
+	BodyChanged bool
+	// Diff is the difference between the baseline response and the response.
+	Diff ResponseDiff
+}
+
+// EvaluateMassAssignment returns the [MassAssignmentResult] of the given [MassAssignmentVariant],
+// by comparing its response (res) with the responses to both baselines (i.e. the request as is).
+func EvaluateMassAssignment(v MassAssignmentVariant, baseline, control, res *response.Response) MassAssignmentResult {
+	stable := baseline.Code == control.Code && bytes.Equal(baseline.Body, control.Body)
+
+	reflection := []byte(v.Reflection)
+
+	return MassAssignmentResult{
+		Kind:          v.Kind,
+		Param:         v.Param,
+		Location:      v.Location,
+		Reflected:     bytes.Contains(res.Body, reflection) && !bytes.Contains(baseline.Body, reflection),
+		StatusChanged: baseline.Code == control.Code && res.Code != baseline.Code,
+		BodyChanged:   stable && !bytes.Equal(res.Body, baseline.Body),
+		Diff:          DiffResponses(baseline, res),
+	}
+}
+
+// Accepted returns whether the injected parameter(s) seem to take effect.
+func (r MassAssignmentResult) Accepted() bool {
+	return r.Reflected || r.StatusChanged || r.BodyChanged
+}
+
+// Summary returns a single-line summary of the behavior observed,
+// e.g. isAdmin=true (body): reflected, status 200 -> 302.
+func (r MassAssignmentResult) Summary() string {
+	var evidences []string
+	if r.Reflected {
+		evidences = append(evidences, "reflected")
+	}
+	if r.StatusChanged {
+		evidences = append(evidences, fmt.Sprintf("status %d -> %d", r.Diff.StatusA, r.Diff.StatusB))
+	}
+	if r.BodyChanged {
+		evidences = append(evidences, "body: "+r.Diff.BodySummary)
+	}
+	if len(evidences) == 0 {
+		evidences = append(evidences, "no effect")
+	}
+
+	return fmt.Sprintf("%s (%s): %s", r.Param, r.Location, strings.Join(evidences, ", "))
+}
+
+// massAssignmentKey is the [context.Context] key for the [MassAssignmentResult] of a match.
+type massAssignmentKey struct{}
+
+// withMassAssignment returns a copy of the given [context.Context] with the given [MassAssignmentResult],
+// so it can be attached to the match reported (see [MassAssignmentOf]). If the status or the body changed,
+// the difference is also attached (see [ResponseDiffOf]).
+func withMassAssignment(ctx context.Context, r MassAssignmentResult) context.Context {
+	if r.StatusChanged || r.BodyChanged {
+		ctx = withResponseDiff(ctx, r.Diff)
+	}
+	return context.WithValue(ctx, massAssignmentKey{}, r)
+}
+
+// MassAssignmentOf returns the [MassAssignmentResult] of a match reported with the
+// given [context.Context], if any, or nil if it isn't a mass assignment match.
+func MassAssignmentOf(ctx context.Context) *MassAssignmentResult {
+	r, ok := ctx.Value(massAssignmentKey{}).(MassAssignmentResult)
+	if !ok {
+		return nil
+	}
+	return &r
+}
+
+// massAssignmentMetadata returns the given metadata along with the [MassAssignmentResult]
+// from the given [context.Context] (see [MetadataMassAssignment]), if any.
+func massAssignmentMetadata(ctx context.Context, metadata map[string]string) map[string]string {
+	r := MassAssignmentOf(ctx)
+	if r == nil {
+		return metadata
+	}
+
+	return withMetadata(metadata, MetadataMassAssignment, []string{r.Summary()})
+}
+
+// massAssignmentProfile is the (built-in) profile the matches reported while probing mass
+// assignment (see [MassAssignmentCfg]) belong to, when the injected parameter(s) take effect.
+type massAssignmentProfile struct {
+	result MassAssignmentResult
+}
+
+var (
+	_ profile.Profile          = massAssignmentProfile{}
+	_ profile.IssueInformation = massAssignmentProfile{}
+)
+
+func (massAssignmentProfile) GetName() string       { return "Mass assignment" }
+func (massAssignmentProfile) GetType() profile.Type { return profile.TypeActive }
+func (massAssignmentProfile) IsEnabled() bool       { return true }
+func (massAssignmentProfile) GetTags() []string     { return []string{"mass-assignment", "hpp"} }
+
+func (p massAssignmentProfile) GetIssueName() string {
+	if p.result.Kind == MassAssignmentKindPollution {
+		return "HTTP parameter pollution"
+	}
+	return "Mass assignment"
+}
+
+func (massAssignmentProfile) GetIssueSeverity() string { return "Medium" }
+
+func (p massAssignmentProfile) GetIssueConfidence() string {
+	if p.result.Reflected {
+		return "Firm"
+	}
+	return "Tentative"
+}
+
+func (p massAssignmentProfile) GetIssueDetail() string {
+	if p.result.Kind == MassAssignmentKindPollution {
+		return fmt.Sprintf("The request with duplicated parameters (%s) in the %s caused a divergent response: %s.", p.result.Param, p.result.Location, p.result.Summary())
+	}
+	return fmt.Sprintf("The unexpected parameter %s added to the %s seems to be accepted: %s.", p.result.Param, p.result.Location, p.result.Summary())
+}
+
+func (p massAssignmentProfile) GetIssueBackground() string {
+	if p.result.Kind == MassAssignmentKindPollution {
+		return "Endpoints that handle duplicated parameters inconsistently (e.g. first vs last occurrence across components) are exposed to filter bypasses and logic flaws."
+	}
+	return "Endpoints that bind every parameter received to internal objects (mass assignment) let attackers set properties they aren't supposed to, like roles or privileges."
+}
+
+func (p massAssignmentProfile) GetRemediationDetail() string {
+	if p.result.Kind == MassAssignmentKindPollution {
+		return "Reject requests with duplicated parameters, or handle these consistently across every component."
+	}
+	return "Bind only an explicit allowlist of parameters, and ignore (or reject) any other one."
+}
+
+func (massAssignmentProfile) GetRemediationBackground() string { return "" }
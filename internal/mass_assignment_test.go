@@ -0,0 +1,138 @@
+package scan_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestMassAssignmentCfg_Variants(t *testing.T) {
+	t.Parallel()
+
+	cfg := scan.MassAssignmentCfg{Enabled: true, Params: [][2]string{{"isAdmin", "true"}, {"role", "admin"}}}
+
+	tcs := map[string]struct {
+		raw      string
+		expPaths []string
+		expBody  []string
+		expHPP   string
+	}{
+		"query": {
+			raw:      "GET /users?id=1&page=2 HTTP/1.1\r\nHost: example.org\r\n\r\n",
+			expPaths: []string{"/users?id=1&page=2&isAdmin=true", "/users?id=1&page=2&role=admin", "/users?id=1&page=2&id=gbhpp7391&page=gbhpp7391"},
+			expBody:  []string{"", "", ""},
+			expHPP:   "id=gbhpp7391&page=gbhpp7391",
+		},
+		"no params": {
+			raw:      "GET /users HTTP/1.1\r\nHost: example.org\r\n\r\n",
+			expPaths: []string{"/users?isAdmin=true", "/users?role=admin"},
+			expBody:  []string{"", ""},
+		},
+		"form body": {
+			raw:      "POST /users HTTP/1.1\r\nHost: example.org\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 9\r\n\r\nname=john",
+			expPaths: []string{"/users", "/users", "/users"},
+			expBody:  []string{"name=john&isAdmin=true", "name=john&role=admin", "name=john&name=gbhpp7391"},
+			expHPP:   "name=gbhpp7391",
+		},
+		"json body": {
+			raw:      "POST /users HTTP/1.1\r\nHost: example.org\r\nContent-Type: application/json\r\nContent-Length: 16\r\n\r\n{\"name\": \"john\"}",
+			expPaths: []string{"/users", "/users"},
+			expBody:  []string{`{"name": "john","isAdmin":true}`, `{"name": "john","role":"admin"}`},
+		},
+		"empty json body": {
+			raw:      "POST /users HTTP/1.1\r\nHost: example.org\r\nContent-Type: application/json\r\nContent-Length: 2\r\n\r\n{}",
+			expPaths: []string{"/users", "/users"},
+			expBody:  []string{`{"isAdmin":true}`, `{"role":"admin"}`},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := request.ParseRequest([]byte(tc.raw))
+			require.NoError(t, err)
+
+			variants := cfg.Variants(req)
+			require.Len(t, variants, len(tc.expPaths))
+
+			for i, v := range variants {
+				assert.Equal(t, tc.expPaths[i], v.Request.Path)
+				assert.Equal(t, tc.expBody[i], string(v.Request.Body))
+				if len(v.Request.Body) > 0 {
+					assert.Equal(t, strconv.Itoa(len(tc.expBody[i])), v.Request.Header("Content-Length"))
+				}
+			}
+
+			assert.Equal(t, scan.MassAssignmentKindAddition, variants[0].Kind)
+			assert.Equal(t, "isAdmin=true", variants[0].Param)
+			assert.Equal(t, "isAdmin", variants[0].Reflection)
+
+			if len(tc.expHPP) > 0 {
+				last := variants[len(variants)-1]
+				assert.Equal(t, scan.MassAssignmentKindPollution, last.Kind)
+				assert.Equal(t, tc.expHPP, last.Param)
+			}
+
+			// The original request is left untouched.
+			original, err := request.ParseRequest([]byte(tc.raw))
+			require.NoError(t, err)
+			assert.Equal(t, original, req)
+		})
+	}
+}
+
+func TestEvaluateMassAssignment(t *testing.T) {
+	t.Parallel()
+
+	variant := scan.MassAssignmentVariant{Kind: scan.MassAssignmentKindAddition, Param: "isAdmin=true", Location: "body", Reflection: "isAdmin"}
+	baseline := response.Response{Code: 200, Body: []byte("{\"name\":\"john\"}")}
+
+	tcs := map[string]struct {
+		control     response.Response
+		res         response.Response
+		expAccepted bool
+		expSummary  string
+	}{
+		"no effect": {
+			control:    baseline,
+			res:        baseline,
+			expSummary: "isAdmin=true (body): no effect",
+		},
+		"reflected": {
+			control:     baseline,
+			res:         response.Response{Code: 200, Body: []byte("{\"name\":\"john\",\"isAdmin\":true}")},
+			expAccepted: true,
+			expSummary:  "isAdmin=true (body): reflected, body: 1 line(s) removed, 1 line(s) added",
+		},
+		"status changed": {
+			control:     baseline,
+			res:         response.Response{Code: 302, Body: baseline.Body},
+			expAccepted: true,
+			expSummary:  "isAdmin=true (body): status 200 -> 302",
+		},
+		"unstable baseline": {
+			control:    response.Response{Code: 200, Body: []byte("{\"name\":\"jane\"}")},
+			res:        response.Response{Code: 200, Body: []byte("{\"name\":\"doe\"}")},
+			expSummary: "isAdmin=true (body): no effect",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result := scan.EvaluateMassAssignment(variant, &baseline, &tc.control, &tc.res)
+			assert.Equal(t, tc.expAccepted, result.Accepted())
+			assert.Equal(t, tc.expSummary, result.Summary())
+		})
+	}
+}
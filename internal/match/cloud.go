@@ -0,0 +1,170 @@
+package match
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// CloudSignature is the content of a well-known cloud response, like the credentials served by
+// an instance metadata service, or the listing of an open storage bucket, looked for by the Cloud
+// Exposure grep (see [matchCloudExposure]) to detect SSRF and cloud misconfigurations.
+//
+// Provider (e.g. aws) and Field (e.g. iam/security-credentials) identify what is exposed. If
+// Hosts are defined (e.g. 169.254.169.254), the content is only served by those (i.e. metadata
+// services), so it is only looked for when the payload, if any, targets any of them (i.e. SSRF).
+type CloudSignature struct {
+	Name     string
+	Provider string
+	Field    string
+	Hosts    []string
+	Regex    *regexp.Regexp
+}
+
+func (s CloudSignature) signatureName() string { return s.Name }
+
+// metadataHosts are the addresses of the link-local instance metadata service
+// shared by most of the cloud providers, either as dotted, decimal or hex IP.
+var metadataHosts = []string{"169.254.169.254", "2852039166", "0xa9fea9fe"}
+
+var (
+	// awsMetadataHosts are the addresses of AWS' instance metadata service, including the IPv6 one.
+	awsMetadataHosts = append([]string{"fd00:ec2::254", "instance-data"}, metadataHosts...)
+	// gcpMetadataHosts are the addresses of GCP's instance metadata service, including its name.
+	gcpMetadataHosts = append([]string{"metadata.google.internal"}, metadataHosts...)
+)
+
+// defaultCloudSignatures are the built-in [CloudSignature] set (see [DefaultCloudSignatures]).
+var defaultCloudSignatures = []CloudSignature{
+	{Name: "aws-credentials", Provider: "aws", Field: "iam/security-credentials", Hosts: awsMetadataHosts, Regex: regexp.MustCompile(`"AccessKeyId"\s*:\s*"(?:ASIA|AKIA)[0-9A-Z]{16}"[\s\S]*?"SecretAccessKey"\s*:`)},
+	{Name: "aws-identity", Provider: "aws", Field: "instance-id", Hosts: awsMetadataHosts, Regex: regexp.MustCompile(`"instanceId"\s*:\s*"i-[0-9a-f]{8,17}"|(?m:^ami-id\r?$[\s\S]*?^instance-id\r?$)`)},
+	{Name: "gcp-token", Provider: "gcp", Field: "service-accounts/token", Hosts: gcpMetadataHosts, Regex: regexp.MustCompile(`"access_token"\s*:\s*"ya29\.[0-9A-Za-z_\-.]+"`)},
+	{Name: "gcp-identity", Provider: "gcp", Field: "instance/zone", Hosts: gcpMetadataHosts, Regex: regexp.MustCompile(`\bprojects/\d+/zones/[a-z]+-[a-z]+\d+-[a-z]\b`)},
+	{Name: "azure-token", Provider: "azure", Field: "identity/oauth2/token", Hosts: metadataHosts, Regex: regexp.MustCompile(`"access_token"\s*:\s*"eyJ[0-9A-Za-z_\-.]+"[\s\S]*?"resource"\s*:\s*"https://[^"]+"`)},
+	{Name: "azure-identity", Provider: "azure", Field: "compute/vmId", Hosts: metadataHosts, Regex: regexp.MustCompile(`"compute"\s*:\s*\{[\s\S]*?"vmId"\s*:\s*"[0-9a-fA-F-]{36}"`)},
+	{Name: "s3-bucket", Provider: "aws", Field: "ListBucketResult", Regex: regexp.MustCompile(`<ListBucketResult\s+xmlns=["']http://s3\.amazonaws\.com/doc/2006-03-01/?["']\s*>`)},
+	{Name: "gcs-bucket", Provider: "gcp", Field: "ListBucketResult", Regex: regexp.MustCompile(`<ListBucketResult\s+xmlns=["']http://doc\.s3\.amazonaws\.com/2006-03-01/?["']\s*>`)},
+	{Name: "gcs-objects", Provider: "gcp", Field: "storage#objects", Regex: regexp.MustCompile(`"kind"\s*:\s*"storage#objects"`)},
+	{Name: "azure-container", Provider: "azure", Field: "EnumerationResults", Regex: regexp.MustCompile(`<EnumerationResults\s[^>]*ContainerName=`)},
+}
+
+// DefaultCloudSignatures returns the built-in [CloudSignature] set: AWS, GCP and Azure
+// instance metadata (credentials, tokens and instance identity), and the listing of
+// open S3, GCS and Azure Blob Storage buckets.
+func DefaultCloudSignatures() []CloudSignature {
+	return cloneSignatures(defaultCloudSignatures)
+}
+
+// ReadCloudSignatures reads the [CloudSignature] set from the given [io.Reader], one per line,
+// with the form name=provider=field=hosts=regex, where hosts are comma-separated, and can be
+// empty (e.g. oci-identity=oracle=instance=169.254.169.254="canonicalRegionName"\s*:),
+// see [readSignatures].
+func ReadCloudSignatures(r io.Reader) ([]CloudSignature, error) {
+	return readSignatures(r, signatureFormat[CloudSignature]{
+		Form: "name=provider=field=hosts=regex",
+		Build: func(parts []string, regex *regexp.Regexp) (CloudSignature, bool) {
+			signature := CloudSignature{Name: parts[0], Provider: strings.ToLower(parts[1]), Field: parts[2], Regex: regex}
+			for _, host := range strings.Split(parts[3], ",") {
+				if host = strings.ToLower(strings.TrimSpace(host)); len(host) > 0 {
+					signature.Hosts = append(signature.Hosts, host)
+				}
+			}
+
+			return signature, len(signature.Provider) > 0 && len(signature.Field) > 0
+		},
+	})
+}
+
+// WithCloudSignatures returns a copy of the given [context.Context] with the given custom
+// [CloudSignature] set, looked for by the Cloud Exposure greps (see [mergeSignatures]).
+func WithCloudSignatures(ctx context.Context, signatures []CloudSignature) context.Context {
+	return withSignatures(ctx, signatures)
+}
+
+// matchCloudExposure checks whether the response's body contains cloud metadata (e.g. instance
+// credentials) or the listing of an open bucket, by looking for the signatures defined by the grep
+// value (see [profile.GrepValue.AsSignatures]), either built-in (see [DefaultCloudSignatures]) or
+// custom (see [WithCloudSignatures]).
+//
+// If there's a payload, those signatures only served by metadata services are only looked for
+// when the payload targets any of them (i.e. SSRF), so the same content found in other responses
+// isn't reported. The occurrences returned are the contents found.
+func matchCloudExposure(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	var occurrences []occurrence.Occurrence
+	for _, signature := range cloudSignatures(ctx, g, payload) {
+		for _, loc := range signature.Regex.FindAllStringIndex(body, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+
+			logger.For(ctx).Debugf("Cloud exposure found (%s: %s) at: %d", signature.Provider, signature.Field, loc[0])
+			occurrences = append(occurrences, occurrence.Occurrence{loc[0] + offset, loc[1] + offset})
+		}
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// CloudExposures returns the [CloudSignature] set whose content is found within the given
+// response's body, according to the given Cloud Exposure grep (see [profile.GrepTypeCloudExposure])
+// and payload, if any. So, the provider and the field exposed can be reported along with the match.
+func CloudExposures(ctx context.Context, g profile.Grep, res *response.Response, payload *string) []CloudSignature {
+	if res == nil || len(res.Body) == 0 {
+		return nil
+	}
+
+	var found []CloudSignature
+	for _, signature := range cloudSignatures(ctx, g, payload) {
+		if signature.Regex.Match(res.Body) {
+			found = append(found, signature)
+		}
+	}
+
+	return found
+}
+
+// cloudSignatures returns the [CloudSignature] set looked for by the given grep,
+// restricted to those applicable to the given payload, if any (see [matchCloudExposure]).
+func cloudSignatures(ctx context.Context, g profile.Grep, payload *string) []CloudSignature {
+	names := g.Value.AsSignatures()
+	targets := payloadTargets(payload)
+
+	var signatures []CloudSignature
+	for _, signature := range mergeSignatures(defaultCloudSignatures, customSignatures[CloudSignature](ctx)) {
+		if len(names) > 0 && !slices.In(names, signature.Name) {
+			continue
+		}
+
+		if len(targets) > 0 && len(signature.Hosts) > 0 && !containsAnyOf(targets, signature.Hosts) {
+			continue
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures
+}
+
+func containsAnyOf(ss []string, substrs []string) bool {
+	for _, substr := range substrs {
+		if containsAny(ss, substr) {
+			return true
+		}
+	}
+
+	return false
+}
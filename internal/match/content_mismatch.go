@@ -0,0 +1,202 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// mediaKind is the family of a media type (e.g. html for both text/html and
+// application/xhtml+xml), used to compare the declared and the sniffed types.
+type mediaKind string
+
+const (
+	kindNone       mediaKind = ""
+	kindText       mediaKind = "text"
+	kindBinary     mediaKind = "binary"
+	kindHTML       mediaKind = "html"
+	kindXML        mediaKind = "xml"
+	kindJSON       mediaKind = "json"
+	kindJavaScript mediaKind = "javascript"
+	kindCSS        mediaKind = "css"
+	kindPDF        mediaKind = "pdf"
+	kindArchive    mediaKind = "archive"
+	kindImage      mediaKind = "image"
+	kindAudio      mediaKind = "audio"
+	kindVideo      mediaKind = "video"
+	kindFont       mediaKind = "font"
+)
+
+// renderableTypes are the (non-HTML) media types that browsers
+// render as documents able to run scripts (e.g. SVG images).
+var renderableTypes = []string{"application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml"}
+
+// matchContentMismatch checks whether the response's declared media type (i.e. the Content-Type header)
+// doesn't match the one sniffed from the body (e.g. JSON declared, but HTML returned), which is a common
+// source of XSS and content sniffing issues. Sniffed types that aren't conclusive (i.e. plain text or
+// binary data) are no mismatch, while a missing Content-Type is, as the browser would sniff it.
+//
+// The grep value may restrict the mismatches to those where the payload is reflected within the body,
+// or those where the body would be rendered as HTML (see [profile.GrepValue.AsMismatchConditions]).
+// The occurrences returned are the declared type, the beginning of the body (i.e. where the type is
+// sniffed from) and the payload reflections, if any, so both types are reported.
+func matchContentMismatch(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || res.IsEmpty() || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	declared := strings.ToLower(strings.TrimSpace(res.ContentType()))
+	sniffed := sniffMediaType(res.Body)
+
+	declaredKind, sniffedKind := mediaKindOf(declared), mediaKindOf(sniffed)
+	if sniffedKind == kindNone || sniffedKind == kindText || sniffedKind == kindBinary ||
+		(len(declared) > 0 && compatibleKinds(declaredKind, sniffedKind)) {
+		return false, []occurrence.Occurrence{}
+	}
+
+	offset := res.BodyOffset()
+
+	reflections := payloadReflections(g, res.Body, payload)
+	renderable := declaredKind == kindHTML || slices.In(renderableTypes, declared) ||
+		(len(declared) == 0 && sniffedKind == kindHTML && !nosniff(res))
+
+	conditions := g.Value.AsMismatchConditions()
+	if slices.In(conditions, profile.MismatchReflected) && len(reflections) == 0 ||
+		slices.In(conditions, profile.MismatchRenderable) && !renderable {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf(
+		"Content type mismatch found, declared: %s, sniffed: %s (reflected: %t, renderable: %t)",
+		orNone(declared), sniffed, len(reflections) > 0, renderable,
+	)
+
+	occurrences := headerOccurrences(string(res.Bytes()), "Content-Type", res.Headers["Content-Type"])
+
+	start := len(res.Body) - len(bytes.TrimLeft(res.Body, leadingSpace))
+	snippet := snippetAt(string(res.Body), start)
+	occurrences = append(occurrences, occurrence.Occurrence{snippet[0] + offset, snippet[1] + offset})
+
+	for _, r := range reflections {
+		occurrences = append(occurrences, occurrence.Occurrence{r[0] + offset, r[1] + offset})
+	}
+
+	return true, occurrences
+}
+
+// leadingSpace are the characters (plus the UTF-8 BOM) skipped before sniffing the body.
+const leadingSpace = "\t\n\f\r \xef\xbb\xbf"
+
+// sniffMediaType returns the media type sniffed from the given body, the same way browsers
+// do (see [http.DetectContentType]), but also detecting JSON documents (which are sniffed
+// as plain text), as those are a common declared type.
+func sniffMediaType(body []byte) string {
+	trimmed := bytes.TrimLeft(body, leadingSpace)
+	if len(trimmed) == 0 {
+		return ""
+	}
+
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return "application/json"
+	}
+
+	return strings.Split(http.DetectContentType(trimmed), ";")[0]
+}
+
+// mediaKindOf returns the [mediaKind] of the given (lowercase) media type.
+func mediaKindOf(mediaType string) mediaKind {
+	switch {
+	case len(mediaType) == 0:
+		return kindNone
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return kindHTML
+	case mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json"):
+		return kindJSON
+	case mediaType == "text/xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return kindXML
+	case strings.Contains(mediaType, "javascript") || strings.Contains(mediaType, "ecmascript"):
+		return kindJavaScript
+	case mediaType == "text/css":
+		return kindCSS
+	case mediaType == "application/pdf" || mediaType == "application/postscript":
+		return kindPDF
+	case mediaType == "application/zip" || mediaType == "application/x-gzip" || mediaType == "application/gzip" ||
+		mediaType == "application/x-rar-compressed" || mediaType == "application/wasm":
+		return kindArchive
+	case strings.HasPrefix(mediaType, "image/"):
+		return kindImage
+	case strings.HasPrefix(mediaType, "audio/") || mediaType == "application/ogg":
+		return kindAudio
+	case strings.HasPrefix(mediaType, "video/"):
+		return kindVideo
+	case strings.HasPrefix(mediaType, "font/") || strings.Contains(mediaType, "font"):
+		return kindFont
+	case strings.HasPrefix(mediaType, "text/"):
+		return kindText
+	default:
+		return kindBinary
+	}
+}
+
+// compatibleKinds returns whether the body sniffed as the given kind
+// can be legitimately served with the given declared kind.
+func compatibleKinds(declared, sniffed mediaKind) bool {
+	switch {
+	case declared == sniffed:
+		return true
+	case declared == kindJavaScript && sniffed == kindJSON:
+		// JSONP responses, or JSON served as a script.
+		return true
+	case declared == kindImage && sniffed == kindXML:
+		// SVG images, which are XML documents.
+		return true
+	case declared == kindHTML && sniffed == kindXML:
+		// XHTML documents, sniffed as XML.
+		return true
+	default:
+		return false
+	}
+}
+
+// payloadReflections returns the occurrences of the
+// given payload, if any, within the given body.
+func payloadReflections(g profile.Grep, body []byte, payload *string) []occurrence.Occurrence {
+	if payload == nil || len(*payload) == 0 {
+		return nil
+	}
+
+	doc, canary := string(body), *payload
+	if !g.Option.CaseSensitive() {
+		doc, canary = strings.ToLower(doc), strings.ToLower(canary)
+	}
+
+	return occurrence.Find(doc, canary)
+}
+
+// nosniff returns whether the response disables the
+// content sniffing (i.e. X-Content-Type-Options: nosniff).
+func nosniff(res *response.Response) bool {
+	for _, value := range res.Headers["X-Content-Type-Options"] {
+		if strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func orNone(s string) string {
+	if len(s) == 0 {
+		return "none"
+	}
+
+	return s
+}
@@ -0,0 +1,73 @@
+package match
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// matchCookieFlags checks whether any of the cookies set by the response (i.e. each Set-Cookie
+// header, on its own) lacks any of the flags defined as the grep value (see [profile.GrepValue.AsCookieFlags]),
+// like Secure or HttpOnly. Flags are matched case-insensitively against the cookie attribute names,
+// so neither the cookie value nor other cookies (or headers) can satisfy them.
+//
+// The occurrences returned are the values of the Set-Cookie headers lacking any flag.
+func matchCookieFlags(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	var (
+		doc         = string(res.Bytes())
+		flags       = g.Value.AsCookieFlags()
+		occurrences []occurrence.Occurrence
+		found       bool
+	)
+
+	for _, cookie := range res.Headers["Set-Cookie"] {
+		missing := missingCookieFlags(cookie, flags)
+		if len(missing) == 0 {
+			continue
+		}
+
+		logger.For(ctx).Debugf("Cookie set without flag(s) %s: %s", strings.Join(missing, ", "), cookie)
+
+		found = true
+		occurrences = append(occurrences, headerOccurrences(doc, "Set-Cookie", []string{cookie})...)
+	}
+
+	if !found {
+		return false, []occurrence.Occurrence{}
+	}
+
+	return true, occurrences
+}
+
+// missingCookieFlags returns those of the given flags that aren't set as attributes
+// of the given Set-Cookie header value (e.g. id=abc; Path=/; Secure).
+func missingCookieFlags(cookie string, flags []string) []string {
+	// The first part is the cookie name and value, not an attribute.
+	attrs := strings.Split(cookie, ";")[1:]
+
+	var missing []string
+	for _, flag := range flags {
+		set := false
+		for _, attr := range attrs {
+			name, _, _ := strings.Cut(attr, "=")
+			if strings.EqualFold(strings.TrimSpace(name), flag) {
+				set = true
+				break
+			}
+		}
+
+		if !set {
+			missing = append(missing, flag)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,211 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// maxPayloadDecodes is the maximum amount of times the payload is (URL) decoded,
+// to find the CRLF sequences within it (e.g. %250d%250a is decoded twice).
+const maxPayloadDecodes = 3
+
+// injectedHeader is a header line carried by a payload, after a CRLF sequence.
+type injectedHeader struct {
+	name, value string
+}
+
+// crlfInjection is what a payload injects through CRLF sequences: the header lines
+// and, if there's an empty line (i.e. the response is split), the first line after it.
+type crlfInjection struct {
+	headers []injectedHeader
+	split   bool
+	body    string
+}
+
+// matchCRLFInjection checks whether the CRLF sequences (e.g. %0d%0aInjected: value) carried by
+// the payload became part of the response: either any of the header lines injected became a real
+// response header, or the response was split (i.e. the empty line injected ended the headers, so
+// the injected content is at the beginning of the body).
+//
+// The headers are inspected in their wire form (see [response.Response.RawHeaders]), if available,
+// so malformed lines are considered as well. If the grep value defines header names, only those are
+// looked for (with the value from the payload, if any). The occurrences returned are the injected
+// header lines found (or the beginning of the body, if split).
+func matchCRLFInjection(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || res.IsEmpty() {
+		return false, []occurrence.Occurrence{}
+	}
+
+	var injection crlfInjection
+	if payload != nil {
+		injection = crlfInjectionFrom(*payload)
+	}
+
+	expected := injection.headers
+	if names := g.Value.AsHeaderNames(); len(names) > 0 {
+		expected = expectedHeaders(names, injection.headers)
+	}
+
+	var (
+		found       bool
+		occurrences []occurrence.Occurrence
+	)
+
+	for _, h := range expected {
+		line, offset, ok := findRawHeader(res, h)
+		if !ok {
+			continue
+		}
+
+		found = true
+		logger.For(ctx).Debugf("CRLF injection found, injected header: %s (line: %d, offset: %d)", h.name, line, offset)
+		occurrences = append(occurrences, headerOccurrence(res, h.name)...)
+	}
+
+	if injection.split && len(injection.body) > 0 && bytes.HasPrefix(res.Body, []byte(injection.body)) {
+		offset := res.BodyOffset()
+
+		found = true
+		logger.For(ctx).Debugf("Response splitting found, injected content at the beginning of the body: %q", injection.body)
+		occurrences = append(occurrences, occurrence.Occurrence{offset, offset + len(injection.body)})
+	}
+
+	if !found {
+		return false, []occurrence.Occurrence{}
+	}
+
+	return true, occurrences
+}
+
+// crlfInjectionFrom returns what the given payload injects through CRLF sequences,
+// once decoded, if any. The content before the first line break is ignored, as
+// that's the value of the entrypoint (e.g. the header) where the payload is injected.
+func crlfInjectionFrom(payload string) crlfInjection {
+	decoded := decodePayload(payload)
+	decoded = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(decoded)
+
+	lines := strings.Split(decoded, "\n")
+	if len(lines) < 2 { //nolint:mnd
+		return crlfInjection{}
+	}
+
+	var injection crlfInjection
+	for i, line := range lines[1:] {
+		if len(strings.TrimSpace(line)) == 0 {
+			injection.split = true
+			for _, l := range lines[i+2:] {
+				if len(strings.TrimSpace(l)) > 0 {
+					injection.body = l
+					break
+				}
+			}
+
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || len(name) == 0 || strings.ContainsAny(name, " \t") {
+			continue
+		}
+
+		injection.headers = append(injection.headers, injectedHeader{
+			name:  textproto.CanonicalMIMEHeaderKey(name),
+			value: strings.TrimSpace(value),
+		})
+	}
+
+	return injection
+}
+
+// decodePayload returns the given payload (URL) decoded, as many times as needed
+// (up to [maxPayloadDecodes]), with the escaped line breaks (e.g. \r\n) unescaped.
+func decodePayload(payload string) string {
+	for i := 0; i < maxPayloadDecodes; i++ {
+		decoded, err := url.PathUnescape(payload)
+		if err != nil || decoded == payload {
+			break
+		}
+
+		payload = decoded
+	}
+
+	return strings.NewReplacer(`\r`, "\r", `\n`, "\n").Replace(payload)
+}
+
+// expectedHeaders returns the headers (by name) expected to be injected,
+// with the value from the ones injected by the payload, if any.
+func expectedHeaders(names []string, injected []injectedHeader) []injectedHeader {
+	expected := make([]injectedHeader, 0, len(names))
+	for _, name := range names {
+		h := injectedHeader{name: name}
+		for _, i := range injected {
+			if i.name == name {
+				h.value = i.value
+				break
+			}
+		}
+
+		expected = append(expected, h)
+	}
+
+	return expected
+}
+
+// findRawHeader looks for the given header within the response headers, in their wire form,
+// if available, or the parsed ones otherwise. If a value is expected, the header must contain it.
+// It returns the line (the status line is the first one) and its offset within the headers.
+func findRawHeader(res *response.Response, h injectedHeader) (int, int, bool) {
+	if len(res.RawHeaders) == 0 {
+		for _, value := range res.Headers[h.name] {
+			if strings.Contains(value, h.value) {
+				return 0, 0, true
+			}
+		}
+
+		return 0, 0, false
+	}
+
+	var offset int
+	for i, line := range strings.SplitAfter(string(res.RawHeaders), "\n") {
+		start := offset
+		offset += len(line)
+
+		// The first line is the status line.
+		if i == 0 {
+			continue
+		}
+
+		name, value, ok := strings.Cut(strings.TrimRight(line, "\r\n"), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), h.name) && strings.Contains(value, h.value) {
+			return i + 1, start, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// headerOccurrence returns the location of the given header line within the
+// response (see [response.Response.Bytes]), if the header was parsed (i.e.
+// malformed lines, only found within the wire form, have no occurrence).
+func headerOccurrence(res *response.Response, name string) []occurrence.Occurrence {
+	b := string(res.Bytes())
+
+	idx := strings.Index(b, "\r\n"+name+": ")
+	if idx < 0 {
+		return []occurrence.Occurrence{}
+	}
+
+	start := idx + len("\r\n")
+	end := start + strings.Index(b[start:], "\r\n")
+
+	return []occurrence.Occurrence{{start, end}}
+}
@@ -0,0 +1,184 @@
+package match
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// DebugSignature is the content of a well-known debug surface, like Spring Boot's actuator,
+// Go's pprof index or Django's debug page, looked for by the Debug Exposure grep (see
+// [matchDebugExposure]) to detect debug endpoints (or modes) exposed in production.
+//
+// If Paths are defined (e.g. /debug/pprof/), the content is only looked for when the
+// request's path ends with any of them, so the same content found elsewhere isn't
+// reported. Those are also the paths probed during content discovery (see [DebugPaths]).
+// Otherwise, the content is looked for on every response (e.g. error pages).
+type DebugSignature struct {
+	Name  string
+	Paths []string
+	Regex *regexp.Regexp
+}
+
+func (s DebugSignature) signatureName() string { return s.Name }
+
+// defaultDebugSignatures are the built-in [DebugSignature] set (see [DefaultDebugSignatures]).
+var defaultDebugSignatures = []DebugSignature{
+	{Name: "spring-actuator", Paths: []string{"/actuator"}, Regex: regexp.MustCompile(`"_links"\s*:\s*\{[\s\S]*?"href"\s*:\s*"[^"]*/actuator/?"`)},
+	{Name: "spring-env", Paths: []string{"/actuator/env", "/env"}, Regex: regexp.MustCompile(`"activeProfiles"\s*:\s*\[[\s\S]*?"propertySources"\s*:`)},
+	{Name: "go-pprof", Paths: []string{"/debug/pprof/"}, Regex: regexp.MustCompile(`<title>/debug/pprof/</title>|Types of profiles available:`)},
+	{Name: "go-expvar", Paths: []string{"/debug/vars"}, Regex: regexp.MustCompile(`"cmdline"\s*:\s*\[[\s\S]*?"memstats"\s*:`)},
+	{Name: "phpinfo", Paths: []string{"/phpinfo.php", "/info.php", "/phpinfo"}, Regex: regexp.MustCompile(`<title>phpinfo\(\)</title>|<h1 class="p">PHP Version [0-9.]+`)},
+	{Name: "symfony-profiler", Paths: []string{"/_profiler/"}, Regex: regexp.MustCompile(`<title>Symfony Profiler</title>`)},
+	{Name: "django-debug", Regex: regexp.MustCompile(`You're seeing this error because you have <code>DEBUG = True</code>`)},
+	{Name: "werkzeug-debugger", Regex: regexp.MustCompile(`<title>[^<]*// Werkzeug Debugger</title>|The debugger caught an exception in your WSGI application`)},
+	{Name: "rails-debug", Regex: regexp.MustCompile(`<title>Action Controller: Exception caught</title>`)},
+	{Name: "laravel-ignition", Regex: regexp.MustCompile(`window\.ignite\s*\(|<title>[^<]*Ignition</title>`)},
+}
+
+// DefaultDebugSignatures returns the built-in [DebugSignature] set: Spring Boot's actuator
+// (index and environment), Go's pprof and expvar, PHP's phpinfo, the Symfony profiler, and
+// the debug pages (or debuggers) of Django, Flask (Werkzeug), Rails and Laravel (Ignition).
+func DefaultDebugSignatures() []DebugSignature {
+	return cloneSignatures(defaultDebugSignatures)
+}
+
+// DebugPaths returns the paths of the given [DebugSignature] set (see [DebugSignature.Paths]),
+// with no duplicates, in order. So, those can be probed during content discovery.
+func DebugPaths(signatures []DebugSignature) []string {
+	var paths []string
+	for _, s := range signatures {
+		for _, p := range s.Paths {
+			if !slices.In(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	return paths
+}
+
+// ReadDebugSignatures reads the [DebugSignature] set from the given [io.Reader], one per line,
+// with the form name=paths=regex, where paths are comma-separated, and can be empty
+// (e.g. hangfire=/hangfire=<title>[^<]*Hangfire Dashboard</title>), see [readSignatures].
+func ReadDebugSignatures(r io.Reader) ([]DebugSignature, error) {
+	return readSignatures(r, signatureFormat[DebugSignature]{
+		Form: "name=paths=regex",
+		Build: func(parts []string, regex *regexp.Regexp) (DebugSignature, bool) {
+			signature := DebugSignature{Name: parts[0], Regex: regex}
+			for _, path := range strings.Split(parts[1], ",") {
+				if path = strings.TrimSpace(path); len(path) > 0 {
+					signature.Paths = append(signature.Paths, "/"+strings.TrimPrefix(path, "/"))
+				}
+			}
+
+			return signature, true
+		},
+	})
+}
+
+// WithDebugSignatures returns a copy of the given [context.Context] with the given custom
+// [DebugSignature] set, looked for by the Debug Exposure greps (see [MergeDebugSignatures]).
+func WithDebugSignatures(ctx context.Context, signatures []DebugSignature) context.Context {
+	return withSignatures(ctx, signatures)
+}
+
+// MergeDebugSignatures returns the [DefaultDebugSignatures] merged with the given custom ones
+// (see [mergeSignatures]), so their paths can also be probed (see [DebugPaths]).
+func MergeDebugSignatures(custom []DebugSignature) []DebugSignature {
+	return cloneSignatures(mergeSignatures(defaultDebugSignatures, custom))
+}
+
+// matchDebugExposure checks whether the response exposes a debug surface (e.g. Go's pprof index),
+// by correlating the request's path with the response's body, according to the signatures defined
+// by the grep value (see [profile.GrepValue.AsSignatures]), either built-in (see [DefaultDebugSignatures])
+// or custom (see [WithDebugSignatures]). Any status code is considered, as debug pages are usually
+// served along with errors. The occurrences returned are snippets of the exposed content.
+func matchDebugExposure(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	var occurrences []occurrence.Occurrence
+	for _, signature := range debugSignatures(ctx, g, req) {
+		loc := signature.Regex.FindStringIndex(body)
+		if loc == nil {
+			continue
+		}
+
+		logger.For(ctx).Debugf("Debug exposure found (%s) at: %d", signature.Name, loc[0])
+
+		snippet := snippetAt(body, loc[0])
+		occurrences = append(occurrences, occurrence.Occurrence{snippet[0] + offset, snippet[1] + offset})
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// DebugExposures returns the [DebugSignature] set whose content is found within the given
+// response's body, according to the given Debug Exposure grep (see [profile.GrepTypeDebugExposure])
+// and request. So, the debug surface exposed can be reported along with the match.
+func DebugExposures(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response) []DebugSignature {
+	if res == nil || len(res.Body) == 0 {
+		return nil
+	}
+
+	var found []DebugSignature
+	for _, signature := range debugSignatures(ctx, g, req) {
+		if signature.Regex.Match(res.Body) {
+			found = append(found, signature)
+		}
+	}
+
+	return found
+}
+
+// debugSignatures returns the [DebugSignature] set looked for by the given grep, restricted
+// to those applicable to the given request's path (see [DebugSignature.Paths]).
+func debugSignatures(ctx context.Context, g profile.Grep, req *request.Request) []DebugSignature {
+	names := g.Value.AsSignatures()
+
+	var path string
+	if req != nil {
+		path, _, _ = strings.Cut(req.Path, "?")
+		path = strings.TrimSuffix(strings.ToLower(path), "/")
+	}
+
+	var signatures []DebugSignature
+	for _, signature := range mergeSignatures(defaultDebugSignatures, customSignatures[DebugSignature](ctx)) {
+		if len(names) > 0 && !slices.In(names, signature.Name) {
+			continue
+		}
+
+		if len(signature.Paths) > 0 && !debugPathMatches(path, signature.Paths) {
+			continue
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures
+}
+
+// debugPathMatches returns whether the given (lowercase, with no trailing slash)
+// path ends with any of the given paths, regardless of their trailing slash.
+func debugPathMatches(path string, paths []string) bool {
+	for _, p := range paths {
+		if strings.HasSuffix(path, strings.TrimSuffix(strings.ToLower(p), "/")) {
+			return true
+		}
+	}
+
+	return false
+}
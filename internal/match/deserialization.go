@@ -0,0 +1,208 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// DeserializationSignature identifies a serialization format (e.g. java), by the shape of its
+// serialized objects (e.g. the rO0AB prefix of base64-encoded Java ones), either as text (Object)
+// or as raw bytes (Magic), and by the errors raised when deserializing malformed (or unexpected)
+// objects (e.g. java.io.StreamCorruptedException), looked for by the Deserialization grep
+// (see [matchDeserialization]) to detect insecure deserialization.
+type DeserializationSignature struct {
+	Name   string
+	Object *regexp.Regexp
+	Magic  []byte
+	Error  *regexp.Regexp
+}
+
+// defaultDeserializationSignatures are the built-in [DeserializationSignature] set
+// (see [DefaultDeserializationSignatures]).
+var defaultDeserializationSignatures = []DeserializationSignature{
+	{
+		Name:   "java",
+		Object: regexp.MustCompile(`rO0AB[A-Za-z0-9+/=]*|(?i:aced0005)[0-9A-Fa-f]*`),
+		Magic:  []byte{0xac, 0xed, 0x00, 0x05},
+		Error:  regexp.MustCompile(`java\.io\.(?:StreamCorruptedException|InvalidClassException|OptionalDataException|InvalidObjectException|NotSerializableException)|invalid stream header: [0-9A-Fa-f]{8}|ObjectInputStream\.readObject`),
+	},
+	{
+		Name:   "php",
+		Object: regexp.MustCompile(`\b[OC]:\d+:"[A-Za-z_\\][A-Za-z0-9_\\]*":\d+:\{|\ba:\d+:\{(?:[isbd]:|N;)`),
+		Error:  regexp.MustCompile(`unserialize\(\): Error at offset \d+|__PHP_Incomplete_Class`),
+	},
+	{
+		Name:   "dotnet",
+		Object: regexp.MustCompile(`/wE[A-Za-z0-9+/]{8,}={0,2}|AAEAAAD/////[A-Za-z0-9+/=]*`),
+		Error:  regexp.MustCompile(`Validation of viewstate MAC failed|The state information is invalid for this page|Invalid viewstate|System\.Runtime\.Serialization\.SerializationException|System\.Web\.UI\.(?:ObjectStateFormatter|LosFormatter)\.Deserialize`),
+	},
+	{
+		Name:   "python",
+		Object: regexp.MustCompile(`gASV[A-Za-z0-9+/=]+`),
+		Magic:  []byte{0x80, 0x04, 0x95},
+		Error:  regexp.MustCompile(`_?pickle\.UnpicklingError|UnpicklingError: (?:invalid load key|could not find MARK)`),
+	},
+}
+
+// DefaultDeserializationSignatures returns the built-in [DeserializationSignature] set:
+// java (ObjectInputStream), php (unserialize), dotnet (ViewState and BinaryFormatter)
+// and python (pickle).
+func DefaultDeserializationSignatures() []DeserializationSignature {
+	return cloneSignatures(defaultDeserializationSignatures)
+}
+
+// find returns the occurrences of the serialized objects and the errors within the given bytes.
+func (s DeserializationSignature) find(b []byte) []occurrence.Occurrence {
+	var occurrences []occurrence.Occurrence
+	for _, re := range []*regexp.Regexp{s.Object, s.Error} {
+		for _, loc := range re.FindAllIndex(b, -1) {
+			if loc[0] != loc[1] {
+				occurrences = append(occurrences, occurrence.Occurrence{loc[0], loc[1]})
+			}
+		}
+	}
+
+	if len(s.Magic) == 0 {
+		return occurrences
+	}
+
+	for offset := 0; ; {
+		idx := bytes.Index(b[offset:], s.Magic)
+		if idx < 0 {
+			break
+		}
+
+		occurrences = append(occurrences, occurrence.Occurrence{offset + idx, offset + idx + len(s.Magic)})
+		offset += idx + len(s.Magic)
+	}
+
+	return occurrences
+}
+
+// carriedBy returns whether the given payload carries a serialized object of the
+// format, either as is or (once, or twice) URL-decoded, as commonly sent in params.
+func (s DeserializationSignature) carriedBy(payload string) bool {
+	for i := 0; i < 3; i++ {
+		if s.Object.MatchString(payload) || (len(s.Magic) > 0 && bytes.Contains([]byte(payload), s.Magic)) {
+			return true
+		}
+
+		unescaped, err := url.QueryUnescape(payload)
+		if err != nil || unescaped == payload {
+			return false
+		}
+		payload = unescaped
+	}
+
+	return false
+}
+
+// matchDeserialization checks whether the response shows telltale signs of insecure deserialization,
+// by looking for the signatures defined by the grep value (see [profile.GrepValue.AsSignatures]),
+// or all the built-in ones (see [DefaultDeserializationSignatures]) if empty. That is, either any
+// deserialization error (e.g. java.io.InvalidClassException), or any serialized object (e.g. a PHP
+// object reflected, or a Java one set as a cookie). The occurrences returned are those found.
+//
+// If there's a payload, only those formats whose serialized objects are carried by it (e.g. rO0AB)
+// are looked for. Then, if there's no sign of them, the response is compared against the baseline
+// (i.e. the original request's response): a server error (5xx) the baseline doesn't produce is
+// considered a behavior change caused by the serialized object, and so a match too.
+func matchDeserialization(
+	ctx context.Context,
+	g profile.Grep,
+	origReq *request.Request,
+	res *response.Response,
+	payload *string,
+) (bool, []occurrence.Occurrence) {
+	if res == nil || res.IsEmpty() {
+		return false, []occurrence.Occurrence{}
+	}
+
+	found, carried := deserializationSignatures(g, res, payload)
+	if len(found) > 0 {
+		raw := res.Bytes()
+
+		var occurrences []occurrence.Occurrence
+		for _, s := range found {
+			logger.For(ctx).Debugf("Deserialization sign found (%s)", s.Name)
+			occurrences = append(occurrences, s.find(raw)...)
+		}
+
+		return true, occurrences
+	}
+
+	if len(carried) == 0 || origReq == nil || res.Code < http.StatusInternalServerError {
+		return false, []occurrence.Occurrence{}
+	}
+
+	origRes, err := originalResponse(ctx, origReq)
+	if err != nil {
+		logger.For(ctx).Errorf("Couldn't check deserialization behavior: couldn't get original response: %s", err.Error())
+		return false, []occurrence.Occurrence{}
+	}
+
+	return origRes.Code < http.StatusInternalServerError, []occurrence.Occurrence{}
+}
+
+// DeserializationFormats returns the serialization formats (e.g. java) whose telltale signs are
+// found within the given response, according to the given Deserialization grep (see
+// [profile.GrepTypeDeserialization]) and payload, if any. If none is found, those carried
+// by the payload are returned instead, as the match can only come from a behavior change
+// (see [matchDeserialization]). So, the format detected can be reported along with the match.
+func DeserializationFormats(g profile.Grep, res *response.Response, payload *string) []string {
+	if res == nil || res.IsEmpty() {
+		return nil
+	}
+
+	found, carried := deserializationSignatures(g, res, payload)
+	if len(found) == 0 {
+		found = carried
+	}
+
+	formats := make([]string, 0, len(found))
+	for _, s := range found {
+		if !slices.In(formats, s.Name) {
+			formats = append(formats, s.Name)
+		}
+	}
+
+	return formats
+}
+
+// deserializationSignatures returns the [DeserializationSignature] set looked for by the given grep
+// (restricted to those carried by the given payload, if any) that are found within the given
+// response, along with those carried by the payload (see [matchDeserialization]).
+func deserializationSignatures(g profile.Grep, res *response.Response, payload *string) ([]DeserializationSignature, []DeserializationSignature) {
+	names := g.Value.AsSignatures()
+	raw := res.Bytes()
+
+	var found, carried []DeserializationSignature
+	for _, s := range defaultDeserializationSignatures {
+		if len(names) > 0 && !slices.In(names, s.Name) {
+			continue
+		}
+
+		if payload != nil && len(*payload) > 0 {
+			if !s.carriedBy(*payload) {
+				continue
+			}
+			carried = append(carried, s)
+		}
+
+		if len(s.find(raw)) > 0 {
+			found = append(found, s)
+		}
+	}
+
+	return found, carried
+}
@@ -0,0 +1,53 @@
+package match
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+	"github.com/bountysecurity/gbounty/kit/strings/reflection"
+)
+
+// matchDOMSink looks for the payload (used as the canary) reflected within JavaScript
+// code of the response body (i.e. script tags and event handlers), and checks whether
+// any of those reflections flow into any of the expected DOM sinks (see [reflection.Sink]),
+// like innerHTML or document.write, within the same statement (see [reflection.AnalyzeSinks]).
+//
+// The occurrences returned are the code surrounding those reflections, from the sink
+// to the end of the statement, so the sink (e.g. innerHTML =) is also highlighted.
+func matchDOMSink(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || payload == nil || len(*payload) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	caseSensitive := g.Option.CaseSensitive()
+
+	// Reflections are only looked for within the response body.
+	g.Option = profile.GrepOptionNotInHeaders
+	offset, findIn := resBytesToFindIn(g, res)
+
+	doc, canary := string(findIn), *payload
+	if !caseSensitive {
+		doc, canary = strings.ToLower(doc), strings.ToLower(canary)
+	}
+
+	sinks := g.Value.AsDOMSinks()
+	occurrences := make([]occurrence.Occurrence, 0)
+
+	for _, r := range reflection.AnalyzeSinks(doc, canary) {
+		if !slices.In(sinks, r.Sink) {
+			continue
+		}
+
+		logger.For(ctx).Debugf("Payload reflected into DOM sink at offset %d (sink=%s, context=%s): %s",
+			r.Occurrence[0]+offset, r.Sink, r.Context, string(findIn[r.Code[0]:r.Code[1]]))
+
+		occurrences = append(occurrences, occurrence.Occurrence{r.Code[0] + offset, r.Code[1] + offset})
+	}
+
+	return len(occurrences) > 0, occurrences
+}
@@ -0,0 +1,165 @@
+package match
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// FileSignature is the canonical content of a well-known file (e.g. the root entry
+// of /etc/passwd), looked for by the File Read grep (see [matchFileRead]) to detect
+// path traversal and local file inclusion, with a higher confidence than reflection.
+type FileSignature struct {
+	Name  string
+	File  string
+	Regex *regexp.Regexp
+}
+
+// baseName returns the (lowercase) base name of the [FileSignature.File] (e.g. win.ini),
+// expected within the payloads that target the file, either Unix or Windows-like.
+func (s FileSignature) baseName() string {
+	return strings.ToLower(path.Base(strings.ReplaceAll(s.File, `\`, "/")))
+}
+
+func (s FileSignature) signatureName() string { return s.Name }
+
+// defaultFileSignatures are the built-in [FileSignature] set (see [DefaultFileSignatures]).
+var defaultFileSignatures = []FileSignature{
+	{Name: "etc-passwd", File: "/etc/passwd", Regex: regexp.MustCompile(`(?m)^root:[^:\r\n]*:0:0:`)},
+	{Name: "win-ini", File: `C:\Windows\win.ini`, Regex: regexp.MustCompile(`(?i); for 16-bit app support|\[fonts\]\s+\[extensions\]`)},
+	{Name: "boot-ini", File: `C:\boot.ini`, Regex: regexp.MustCompile(`(?i)\[boot loader\][\s\S]*?\[operating systems\]`)},
+	{Name: "proc-environ", File: "/proc/self/environ", Regex: regexp.MustCompile(`(?:^|\x00)(?:PATH|HOME|PWD)=[^\x00\r\n]*\x00`)},
+	{Name: "web-xml", File: "WEB-INF/web.xml", Regex: regexp.MustCompile(`<web-app[\s>][\s\S]*?</web-app>`)},
+}
+
+// DefaultFileSignatures returns the built-in [FileSignature] set: /etc/passwd,
+// win.ini, boot.ini, /proc/self/environ and WEB-INF/web.xml.
+func DefaultFileSignatures() []FileSignature {
+	return cloneSignatures(defaultFileSignatures)
+}
+
+// ReadFileSignatures reads the [FileSignature] set from the given [io.Reader], one per line, with
+// the form name=file=regex (e.g. etc-hosts=/etc/hosts=127\.0\.0\.1\s+localhost), see [readSignatures].
+func ReadFileSignatures(r io.Reader) ([]FileSignature, error) {
+	return readSignatures(r, signatureFormat[FileSignature]{
+		Form: "name=file=regex",
+		Build: func(parts []string, regex *regexp.Regexp) (FileSignature, bool) {
+			return FileSignature{Name: parts[0], File: parts[1], Regex: regex}, len(parts[1]) > 0
+		},
+	})
+}
+
+// WithFileSignatures returns a copy of the given [context.Context] with the given custom
+// [FileSignature] set, looked for by the File Read greps (see [mergeSignatures]).
+func WithFileSignatures(ctx context.Context, signatures []FileSignature) context.Context {
+	return withSignatures(ctx, signatures)
+}
+
+// matchFileRead checks whether the response's body contains the content of any well-known
+// file (e.g. /etc/passwd or win.ini), by looking for the signatures defined by the grep value
+// (see [profile.GrepValue.AsSignatures]), either built-in (see [DefaultFileSignatures]) or
+// custom (see [WithFileSignatures]).
+//
+// If there's a payload, only those signatures whose file is targeted by it (i.e. the payload
+// contains the file's base name, e.g. passwd) are looked for, so the same content found in
+// other responses isn't reported. The occurrences returned are the file contents found.
+func matchFileRead(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	var occurrences []occurrence.Occurrence
+	for _, signature := range fileSignatures(ctx, g, payload) {
+		for _, loc := range signature.Regex.FindAllStringIndex(body, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+
+			logger.For(ctx).Debugf("File content found (%s) at: %d", signature.File, loc[0])
+			occurrences = append(occurrences, occurrence.Occurrence{loc[0] + offset, loc[1] + offset})
+		}
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// FilesRead returns the files (e.g. /etc/passwd) whose content is found within the given
+// response's body, according to the given File Read grep (see [profile.GrepTypeFileRead])
+// and payload, if any. So, the file apparently read can be reported along with the match.
+func FilesRead(ctx context.Context, g profile.Grep, res *response.Response, payload *string) []string {
+	if res == nil || len(res.Body) == 0 {
+		return nil
+	}
+
+	var files []string
+	for _, signature := range fileSignatures(ctx, g, payload) {
+		if signature.Regex.Match(res.Body) && !slices.In(files, signature.File) {
+			files = append(files, signature.File)
+		}
+	}
+
+	return files
+}
+
+// fileSignatures returns the [FileSignature] set looked for by the given grep,
+// restricted to those targeted by the given payload, if any (see [matchFileRead]).
+func fileSignatures(ctx context.Context, g profile.Grep, payload *string) []FileSignature {
+	names := g.Value.AsSignatures()
+	targets := payloadTargets(payload)
+
+	var signatures []FileSignature
+	for _, signature := range mergeSignatures(defaultFileSignatures, customSignatures[FileSignature](ctx)) {
+		if len(names) > 0 && !slices.In(names, signature.Name) {
+			continue
+		}
+
+		if len(targets) > 0 && !containsAny(targets, signature.baseName()) {
+			continue
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures
+}
+
+// payloadTargets returns the given payload (lowercase), if any, along with its unescaped
+// forms, so these can be checked against what the payload is expected to target.
+func payloadTargets(payload *string) []string {
+	if payload == nil || len(*payload) == 0 {
+		return nil
+	}
+
+	targets := []string{strings.ToLower(*payload)}
+	if unescaped, err := url.PathUnescape(*payload); err == nil {
+		// Double-encoded payloads (e.g. %252f) are common for path traversal (and SSRF).
+		targets = append(targets, strings.ToLower(unescaped))
+		if twice, err := url.PathUnescape(unescaped); err == nil {
+			targets = append(targets, strings.ToLower(twice))
+		}
+	}
+
+	return targets
+}
+
+func containsAny(ss []string, substr string) bool {
+	for _, s := range ss {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package match
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// graphQLIntrospectionResult is the (partial) shape of a valid introspection result.
+type graphQLIntrospectionResult struct {
+	Data *struct {
+		Schema *struct {
+			QueryType *struct {
+				Name string `json:"name"`
+			} `json:"queryType"`
+			Types []struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"types"`
+		} `json:"__schema"`
+	} `json:"data"`
+}
+
+// GraphQLTypes returns the amount of types exposed by the given response, if its body is a valid
+// GraphQL introspection result (i.e. a JSON object with data.__schema, with a query type and named
+// types), and whether it is one. So, the types exposed can be reported along with the match.
+func GraphQLTypes(res *response.Response) (int, bool) {
+	if res == nil || len(res.Body) == 0 {
+		return 0, false
+	}
+
+	var result graphQLIntrospectionResult
+	if err := json.Unmarshal(res.Body, &result); err != nil {
+		return 0, false
+	}
+
+	if result.Data == nil || result.Data.Schema == nil || result.Data.Schema.QueryType == nil {
+		return 0, false
+	}
+
+	var count int
+	for _, t := range result.Data.Schema.Types {
+		if len(t.Name) > 0 {
+			count++
+		}
+	}
+
+	return count, count > 0
+}
+
+// matchGraphQLIntrospection looks for a valid GraphQL introspection result (see [GraphQLTypes]) within
+// the given response's body, exposing (at least) the minimum amount of types defined by the given grep
+// (see [profile.GrepValue.AsGraphQLMinTypes]). The occurrence is the (start of the) __schema object.
+func matchGraphQLIntrospection(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	count, ok := GraphQLTypes(res)
+	if !ok || count < g.Value.AsGraphQLMinTypes() {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("GraphQL introspection result found, with %d type(s)", count)
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	idx := strings.Index(body, `"__schema"`)
+	if idx < 0 {
+		return true, []occurrence.Occurrence{}
+	}
+
+	snippet := snippetAt(body, idx)
+
+	return true, []occurrence.Occurrence{{snippet[0] + offset, snippet[1] + offset}}
+}
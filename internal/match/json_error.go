@@ -0,0 +1,193 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// jsonScalar is a scalar (i.e. non-object and non-array) value from a JSON document,
+// with its (dot-separated) path and its location, where arrays are traversed implicitly,
+// so the elements share the path of the array (e.g. errors.code).
+type jsonScalar struct {
+	path       string
+	value      string
+	start, end int
+}
+
+// matchJSONError checks whether the response's body is a JSON document with an error
+// envelope (e.g. {"error":{"code":...,"message":...}}), looking for the error codes and
+// messages at the paths defined by the grep value (see [profile.GrepValue.AsJSONError]).
+//
+// If any codes (or messages) are defined, at least one of them must be found, compared
+// case-insensitively (messages are partial, and case-sensitive if the grep option says so).
+// Otherwise, any error found is a match. The occurrences returned are the codes and
+// messages found (only those expected, if any), so these are surfaced within the findings.
+func matchJSONError(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	offset := res.BodyOffset()
+
+	scalars, ok := jsonScalars(res.Body)
+	if !ok {
+		return false, []occurrence.Occurrence{}
+	}
+
+	jsonErr := g.Value.AsJSONError()
+
+	var codes, messages []occurrence.Occurrence
+	for _, s := range scalars {
+		switch {
+		case inJSONPaths(s.path, jsonErr.CodePaths) && matchesJSONErrorCode(s.value, jsonErr.Codes):
+			codes = append(codes, occurrence.Occurrence{s.start + offset, s.end + offset})
+		case inJSONPaths(s.path, jsonErr.MessagePaths) && matchesJSONErrorMessage(g, s.value, jsonErr.Messages):
+			messages = append(messages, occurrence.Occurrence{s.start + offset, s.end + offset})
+		}
+	}
+
+	var found bool
+	switch {
+	case len(jsonErr.Codes) > 0 && len(jsonErr.Messages) > 0:
+		found = len(codes) > 0 && len(messages) > 0
+	case len(jsonErr.Codes) > 0:
+		found = len(codes) > 0
+	case len(jsonErr.Messages) > 0:
+		found = len(messages) > 0
+	default:
+		found = len(codes) > 0 || len(messages) > 0
+	}
+
+	if !found {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("JSON error found, codes: %d, messages: %d", len(codes), len(messages))
+
+	return true, append(codes, messages...)
+}
+
+func matchesJSONErrorCode(value string, codes []string) bool {
+	if len(value) == 0 {
+		return false
+	}
+
+	if len(codes) == 0 {
+		return true
+	}
+
+	for _, code := range codes {
+		if strings.EqualFold(value, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesJSONErrorMessage(g profile.Grep, value string, messages []string) bool {
+	if len(value) == 0 {
+		return false
+	}
+
+	if len(messages) == 0 {
+		return true
+	}
+
+	for _, msg := range messages {
+		if g.Option.CaseSensitive() && strings.Contains(value, msg) ||
+			!g.Option.CaseSensitive() && strings.Contains(strings.ToLower(value), strings.ToLower(msg)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inJSONPaths returns whether the given path is any of the given
+// paths, compared case-insensitively (e.g. errorCode and errorcode).
+func inJSONPaths(path string, paths []string) bool {
+	for _, p := range paths {
+		if strings.EqualFold(path, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonScalars returns the scalar values of the given JSON document (see [jsonScalar]),
+// with their location within it, or false if it isn't a JSON object (nor array).
+func jsonScalars(data []byte) ([]jsonScalar, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid(trimmed) {
+		return nil, false
+	}
+
+	var scalars []jsonScalar
+	walkJSON(trimmed, bytes.Index(data, trimmed), "", &scalars)
+
+	return scalars, true
+}
+
+// walkJSON walks the given (valid) JSON value, located at the given offset,
+// appending its scalar values to the given slice.
+func walkJSON(data []byte, offset int, path string, scalars *[]jsonScalar) {
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil || value == nil {
+			return
+		}
+
+		// The numbers (and booleans) are kept as they are,
+		// while the strings are unquoted, and located without quotes.
+		start, end, str := offset, offset+len(data), string(data)
+		if s, ok := value.(string); ok {
+			start, end, str = start+1, end-1, s
+		}
+
+		*scalars = append(*scalars, jsonScalar{path: path, value: str, start: start, end: end})
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return
+	}
+
+	for dec.More() {
+		elemPath := path
+		if data[0] == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return
+			}
+
+			name, _ := key.(string)
+			elemPath = joinJSONPath(path, name)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return
+		}
+
+		end := int(dec.InputOffset())
+		walkJSON(raw, offset+end-len(raw), elemPath, scalars)
+	}
+}
+
+func joinJSONPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
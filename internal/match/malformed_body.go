@@ -0,0 +1,177 @@
+package match
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+var errXMLWithoutRoot = errors.New("no root element")
+
+// BodyParseError is the error found while parsing a response's body according to its declared
+// format (see [profile.BodyFormats]), with the position (within the body) where it was found.
+type BodyParseError struct {
+	Format string
+	Offset int
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error returns the error message, along with the format and position (e.g. json: invalid
+// character '}' looking for beginning of value (offset 42, line 3, column 5)).
+func (e *BodyParseError) Error() string {
+	return fmt.Sprintf("%s: %s (offset %d, line %d, column %d)", e.Format, e.Err.Error(), e.Offset, e.Line, e.Column)
+}
+
+// Unwrap returns the underlying parse error.
+func (e *BodyParseError) Unwrap() error {
+	return e.Err
+}
+
+// matchMalformedBody checks whether the response's body fails to parse according to the format
+// it claims to be (i.e. its Content-Type is JSON or XML), restricted to the formats defined by the
+// grep value (see [profile.GrepValue.AsBodyFormats]). Malformed documents (especially after an
+// injection) usually reveal the payload breaking the server-side serialization.
+//
+// The occurrences returned are the declared type and the body at the position where parsing
+// failed, so both are reported.
+func matchMalformedBody(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	parseErr := MalformedBody(g, res)
+	if parseErr == nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("Malformed body found, declared: %s, error: %s", res.ContentType(), parseErr)
+
+	offset := res.BodyOffset()
+
+	occurrences := headerOccurrences(string(res.Bytes()), "Content-Type", res.Headers["Content-Type"])
+
+	snippet := snippetAt(string(res.Body), min(parseErr.Offset, len(res.Body)))
+	if snippet[0] == snippet[1] {
+		// The document ended unexpectedly (or the position is at a line break),
+		// so the snippet is taken backwards, from the beginning of the line.
+		start := bytes.LastIndexAny(res.Body[:snippet[0]], "\r\n") + 1
+		snippet = occurrence.Occurrence{start, snippet[0]}
+	}
+
+	if snippet[0] < snippet[1] {
+		occurrences = append(occurrences, occurrence.Occurrence{snippet[0] + offset, snippet[1] + offset})
+	}
+
+	return true, occurrences
+}
+
+// MalformedBody returns the error found while parsing the given response's body, according to
+// the format its Content-Type claims (see [matchMalformedBody]), if it's among those defined by
+// the given Malformed Body grep (see [profile.GrepTypeMalformedBody]), or nil if well-formed.
+// So, the parse error (and its position) can be reported along with the match.
+func MalformedBody(g profile.Grep, res *response.Response) *BodyParseError {
+	if res == nil || len(bytes.TrimSpace(res.Body)) == 0 {
+		return nil
+	}
+
+	var format string
+	switch mediaKindOf(strings.ToLower(strings.TrimSpace(res.ContentType()))) { //nolint:exhaustive
+	case kindJSON:
+		format = profile.BodyFormatJSON
+	case kindXML:
+		format = profile.BodyFormatXML
+	default:
+		return nil
+	}
+
+	if !slices.In(g.Value.AsBodyFormats(), format) {
+		return nil
+	}
+
+	var (
+		off int
+		err error
+	)
+	if format == profile.BodyFormatJSON {
+		off, err = parseJSONBody(res.Body)
+	} else {
+		off, err = parseXMLBody(res.Body)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	line, col := lineColumn(res.Body, off)
+
+	return &BodyParseError{Format: format, Offset: off, Line: line, Column: col, Err: err}
+}
+
+// parseJSONBody parses the given body as a JSON document, and returns
+// the error found, if any, along with its offset within the body.
+func parseJSONBody(body []byte) (int, error) {
+	var v any
+	err := json.Unmarshal(body, &v)
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &syntaxErr) && int(syntaxErr.Offset) >= len(body):
+		// The document ended unexpectedly.
+		return len(body), syntaxErr
+	case errors.As(err, &syntaxErr):
+		// The offset is right after the offending byte.
+		return max(int(syntaxErr.Offset)-1, 0), syntaxErr
+	default:
+		return 0, err
+	}
+}
+
+// parseXMLBody parses the given body as an XML document (strictly, so unknown entities and
+// unbalanced elements are errors), and returns the error found, if any, along with its offset
+// within the body. Documents with no root element (e.g. plain text) are malformed too.
+func parseXMLBody(body []byte) (int, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	var hasRoot bool
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			// The offset is right after the last token read successfully.
+			return int(min(dec.InputOffset(), int64(len(body)))), err
+		}
+
+		if _, ok := tok.(xml.StartElement); ok {
+			hasRoot = true
+		}
+	}
+
+	if !hasRoot {
+		return 0, errXMLWithoutRoot
+	}
+
+	return 0, nil
+}
+
+// lineColumn returns the (1-based) line and column of the given offset within the given body.
+func lineColumn(body []byte, offset int) (int, int) {
+	offset = min(offset, len(body))
+	line := bytes.Count(body[:offset], []byte("\n")) + 1
+	col := offset - (bytes.LastIndexByte(body[:offset], '\n') + 1) + 1
+
+	return line, col
+}
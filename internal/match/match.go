@@ -2,7 +2,11 @@ package match
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,8 +14,12 @@ import (
 	"github.com/bountysecurity/gbounty/internal/profile"
 	"github.com/bountysecurity/gbounty/internal/request"
 	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/arith"
+	"github.com/bountysecurity/gbounty/kit/jwt"
 	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
 	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+	"github.com/bountysecurity/gbounty/kit/strings/reflection"
 )
 
 // Data is a data transfer object (DTO) used as the
@@ -26,6 +34,28 @@ type Data struct {
 	Request       *request.Request
 	Response      *response.Response
 	CustomTokens  map[string]string
+	// Canary is the token prepended to the payload, if any, so the payload reflections
+	// are only those of the payload along with it (i.e. attributable to the request).
+	Canary string
+}
+
+// timeoutKey is the [context.Context] key for the matching timeout (see [WithTimeout]).
+type timeoutKey struct{}
+
+type timeout struct {
+	duration  time.Duration
+	onTimeout func()
+}
+
+// WithTimeout returns a copy of the given [context.Context] that bounds each [Match]
+// evaluation to the given duration, so a slow profile (e.g. with many regexes from a
+// user-supplied profile against a huge response) cannot stall the scan. The deadline
+// is checked between greps, as each grep evaluation cannot be interrupted. Once exceeded,
+// [Match] reports no match, logs a warning and calls onTimeout, if not nil.
+//
+// A non-positive duration means no timeout.
+func WithTimeout(ctx context.Context, d time.Duration, onTimeout func()) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout{duration: d, onTimeout: onTimeout})
 }
 
 // Match checks whether there's a match for the given Data,
@@ -33,6 +63,31 @@ type Data struct {
 //
 // Note: not all matches are accompanied by occurrences.
 func Match(ctx context.Context, d Data) (bool, []occurrence.Occurrence) {
+	t, ok := ctx.Value(timeoutKey{}).(timeout)
+	if !ok || t.duration <= 0 || d.Profile == nil {
+		return match(ctx, d)
+	}
+
+	// The remaining greps are skipped once timed out (see [match]).
+	matchCtx, cancel := context.WithTimeout(ctx, t.duration)
+	defer cancel()
+
+	ok, occ := match(matchCtx, d)
+
+	// Only timeouts are reported, not cancellations.
+	if matchCtx.Err() == nil || ctx.Err() != nil {
+		return ok, occ
+	}
+
+	logger.For(ctx).Warnf("Matcher timed out (after %s) for profile (name='%s'): no match reported", t.duration, d.Profile.GetName())
+	if t.onTimeout != nil {
+		t.onTimeout()
+	}
+
+	return false, []occurrence.Occurrence{}
+}
+
+func match(ctx context.Context, d Data) (bool, []occurrence.Occurrence) {
 	if d.Profile == nil {
 		return false, []occurrence.Occurrence{}
 	}
@@ -81,6 +136,11 @@ func Match(ctx context.Context, d Data) (bool, []occurrence.Occurrence) {
 	occurrences := make([]occurrence.Occurrence, 0)
 
 	for idx := 0; idx < ngreps; idx++ {
+		// The evaluation has timed out (see [WithTimeout]), or the scan has been cancelled.
+		if ctx.Err() != nil {
+			return false, []occurrence.Occurrence{}
+		}
+
 		// It must never fail here.
 		// Any error must be caught by the profile validation.
 		g, err := x.GrepAt(idx, d.CustomTokens)
@@ -120,9 +180,51 @@ func Match(ctx context.Context, d Data) (bool, []occurrence.Occurrence) {
 		case profile.GrepTypeURLExtension:
 			ok, occ = matchURLExtension(g, d.Request)
 		case profile.GrepTypePayload:
-			ok, occ = matchPayload(g, d.Request, d.Response, d.Payload)
+			ok, occ = matchPayload(g, d.Request, d.Response, withCanary(d.Canary, d.Payload))
 		case profile.GrepTypePreEncodedPayload:
-			ok, occ = matchPayload(g, d.Request, d.Response, d.PayloadDecode)
+			ok, occ = matchPayload(g, d.Request, d.Response, withCanary(d.Canary, d.PayloadDecode))
+		case profile.GrepTypeJWTWeakness:
+			ok, occ = matchJWTWeakness(ctx, g, d.Request, d.Response)
+		case profile.GrepTypeReflectionContext:
+			ok, occ = matchReflectionContext(ctx, g, d.Response, withCanary(d.Canary, d.Payload))
+		case profile.GrepTypeOpenRedirect:
+			ok, occ = matchOpenRedirect(ctx, g, d.Request, d.Response, d.Payload)
+		case profile.GrepTypeComputedPayload:
+			ok, occ = matchComputedPayload(ctx, g, d.Request, d.Response, d.PayloadDecode, d.Payload)
+		case profile.GrepTypeCORSMisconfig:
+			ok, occ = matchCORSMisconfig(ctx, g, d.Request, d.Response)
+		case profile.GrepTypeExposedContent:
+			ok, occ = matchExposedContent(ctx, g, d.Request, d.Response)
+		case profile.GrepTypeSensitiveData:
+			ok, occ = matchSensitiveData(ctx, g, d.Response)
+		case profile.GrepTypeJSONError:
+			ok, occ = matchJSONError(ctx, g, d.Response)
+		case profile.GrepTypeCRLFInjection:
+			ok, occ = matchCRLFInjection(ctx, g, d.Response, d.Payload)
+		case profile.GrepTypeContentMismatch:
+			ok, occ = matchContentMismatch(ctx, g, d.Response, d.Payload)
+		case profile.GrepTypeFileRead:
+			ok, occ = matchFileRead(ctx, g, d.Response, d.Payload)
+		case profile.GrepTypeMalformedBody:
+			ok, occ = matchMalformedBody(ctx, g, d.Response)
+		case profile.GrepTypeTechnology:
+			ok, occ = matchTechnology(ctx, g, d.Response)
+		case profile.GrepTypeWebSocketUpgrade:
+			ok, occ = matchWebSocketUpgrade(ctx, g, d.Response)
+		case profile.GrepTypeDOMSink:
+			ok, occ = matchDOMSink(ctx, g, d.Response, d.Payload)
+		case profile.GrepTypeCloudExposure:
+			ok, occ = matchCloudExposure(ctx, g, d.Response, d.Payload)
+		case profile.GrepTypeDebugExposure:
+			ok, occ = matchDebugExposure(ctx, g, d.Request, d.Response)
+		case profile.GrepTypeGraphQLIntrospect:
+			ok, occ = matchGraphQLIntrospection(ctx, g, d.Response)
+		case profile.GrepTypeDeserialization:
+			ok, occ = matchDeserialization(ctx, g, d.Original, d.Response, d.Payload)
+		case profile.GrepTypeCookieFlags:
+			ok, occ = matchCookieFlags(ctx, g, d.Response)
+		case profile.GrepTypeURLScheme:
+			ok, occ = matchURLScheme(g, d.Request)
 		}
 
 		// We append the occurrences to the global list,
@@ -293,6 +395,38 @@ func matchURLExtension(g profile.Grep, req *request.Request) (bool, []occurrence
 	return false, []occurrence.Occurrence{}
 }
 
+// matchURLScheme checks whether the request URL scheme (e.g. https)
+// is any of those defined as the grep value (see [profile.GrepValue.AsURLSchemes]).
+func matchURLScheme(g profile.Grep, req *request.Request) (bool, []occurrence.Occurrence) {
+	if req == nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	for _, scheme := range g.Value.AsURLSchemes() {
+		if strings.EqualFold(scheme, u.Scheme) {
+			return true, []occurrence.Occurrence{}
+		}
+	}
+
+	return false, []occurrence.Occurrence{}
+}
+
+// withCanary returns the given payload with the given canary prepended (see [Data.Canary]),
+// if any, so the payload reflections looked for are only those attributable to the request.
+func withCanary(canary string, payload *string) *string {
+	if len(canary) == 0 || payload == nil || len(*payload) == 0 {
+		return payload
+	}
+
+	canaried := canary + *payload
+	return &canaried
+}
+
 func matchPayload(g profile.Grep, req *request.Request, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
 	_, findIn := bytesToFindIn(g, req, res)
 
@@ -302,3 +436,490 @@ func matchPayload(g profile.Grep, req *request.Request, res *response.Response,
 
 	return strings.Contains(strings.ToLower(string(findIn)), strings.ToLower(*payload)), []occurrence.Occurrence{}
 }
+
+// matchJWTWeakness checks whether the request carries a weak JWT as bearer token (see
+// [profile.GrepValue.AsJWTWeaknesses]), either unsigned (i.e. alg: none) or signed with
+// a weak secret (see [jwt.WeakSecrets]), and the response accepts it: the token is only
+// reported if the response is successful (2xx), so rejected (e.g. 401) tokens aren't.
+func matchJWTWeakness(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response) (bool, []occurrence.Occurrence) {
+	if req == nil || res == nil || res.Code < 200 || res.Code > 299 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	for _, val := range req.Headers["Authorization"] {
+		_, token, ok := jwt.Bearer(val)
+		if !ok {
+			continue
+		}
+
+		tok, err := jwt.Parse(token)
+		if err != nil {
+			continue
+		}
+
+		for _, weakness := range g.Value.AsJWTWeaknesses() {
+			switch weakness {
+			case profile.JWTWeaknessNone:
+				if tok.Unsigned() {
+					logger.For(ctx).Debugf("Unsigned JWT accepted (status=%d): %s", res.Code, token)
+					return true, []occurrence.Occurrence{}
+				}
+			case profile.JWTWeaknessWeakSecret:
+				if secret, weak := tok.WeakSecret(); weak {
+					logger.For(ctx).Debugf("JWT signed with a weak secret (%q) accepted (status=%d)", secret, res.Code)
+					return true, []occurrence.Occurrence{}
+				}
+			}
+		}
+	}
+
+	return false, []occurrence.Occurrence{}
+}
+
+// matchReflectionContext looks for the payload (used as the canary) reflected within
+// the response body, and checks whether any of those reflections land in any of the
+// expected contexts (see [reflection.Context]). The occurrences returned are those
+// reflections in any of the expected contexts.
+func matchReflectionContext(ctx context.Context, g profile.Grep, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	occurrences := make([]occurrence.Occurrence, 0)
+
+	for _, r := range reflectionsInContext(ctx, g, res, payload) {
+		occurrences = append(occurrences, r.Occurrence)
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// ReflectionContexts returns the contexts (see [reflection.Context]) the payload is reflected
+// in within the given response's body, among those expected by the given Reflection Context grep
+// (see [profile.GrepTypeReflectionContext]). So, these can be reported along with the match.
+func ReflectionContexts(ctx context.Context, g profile.Grep, res *response.Response, payload *string) []reflection.Context {
+	var contexts []reflection.Context
+	for _, r := range reflectionsInContext(ctx, g, res, payload) {
+		if !slices.In(contexts, r.Context) {
+			contexts = append(contexts, r.Context)
+		}
+	}
+
+	return contexts
+}
+
+// reflectionsInContext returns the reflections of the payload within the given response's body
+// that land in any of the contexts expected by the given grep, with their occurrences relative
+// to the whole response (see [matchReflectionContext]).
+func reflectionsInContext(ctx context.Context, g profile.Grep, res *response.Response, payload *string) []reflection.Reflection {
+	if res == nil || payload == nil || len(*payload) == 0 {
+		return nil
+	}
+
+	caseSensitive := g.Option.CaseSensitive()
+
+	// Reflections are only looked for within the response body.
+	g.Option = profile.GrepOptionNotInHeaders
+	offset, findIn := resBytesToFindIn(g, res)
+
+	// The case is folded without changing the byte length, so the offsets remain valid.
+	doc, canary := string(findIn), *payload
+	if !caseSensitive {
+		doc, canary = occurrence.ToLower(doc), occurrence.ToLower(canary)
+	}
+
+	contexts := g.Value.AsReflectionContexts()
+
+	var reflections []reflection.Reflection
+	for _, r := range reflection.Analyze(doc, canary) {
+		logger.For(ctx).Debugf("Payload reflected at offset %d (context=%s)", r.Occurrence[0]+offset, r.Context)
+
+		if !slices.In(contexts, r.Context) {
+			continue
+		}
+
+		r.Occurrence = occurrence.Occurrence{r.Occurrence[0] + offset, r.Occurrence[1] + offset}
+		reflections = append(reflections, r)
+	}
+
+	return reflections
+}
+
+// matchComputedPayload looks for the value that the arithmetic expression within the
+// payload (e.g. 7*7 within {{7*7}}) evaluates to, formatted with the grep value as
+// template (see [profile.GrepValue.AsComputedTemplate]). So, it only matches when the
+// target has evaluated the injected expression, like in template injections.
+//
+// Occurrences that are part of the payload reflected as is (either decoded or encoded)
+// or, when the template starts (or ends) with the result, those preceded (or followed)
+// by other digits, are ignored. The occurrences returned are the remaining ones.
+func matchComputedPayload(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response, payload, encoded *string) (bool, []occurrence.Occurrence) {
+	if payload == nil || len(*payload) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	expr, found := arith.Find(*payload)
+	if !found {
+		logger.For(ctx).Debugf("No arithmetic expression found within the payload: %s", *payload)
+		return false, []occurrence.Occurrence{}
+	}
+
+	// It must never fail here, as [arith.Find] only returns valid expressions.
+	result, err := arith.Eval(expr)
+	if err != nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	tmpl := g.Value.AsComputedTemplate()
+	expected := strings.Replace(tmpl, profile.ComputedResultLabel, strconv.FormatInt(result, 10), 1)
+
+	offset, findIn := bytesToFindIn(g, req, res)
+
+	doc := string(findIn)
+	canaries := []string{*payload}
+	if encoded != nil && len(*encoded) > 0 {
+		canaries = append(canaries, *encoded)
+	}
+
+	if !g.Option.CaseSensitive() {
+		doc, expected = strings.ToLower(doc), strings.ToLower(expected)
+		for i := range canaries {
+			canaries[i] = strings.ToLower(canaries[i])
+		}
+	}
+
+	var reflections []occurrence.Occurrence
+	for _, canary := range canaries {
+		reflections = append(reflections, occurrence.Find(doc, canary)...)
+	}
+
+	var (
+		checkBefore = strings.HasPrefix(tmpl, profile.ComputedResultLabel)
+		checkAfter  = strings.HasSuffix(tmpl, profile.ComputedResultLabel)
+		occurrences = make([]occurrence.Occurrence, 0)
+	)
+
+	for _, o := range occurrence.Find(doc, expected) {
+		if checkBefore && o[0] > 0 && isDigit(doc[o[0]-1]) ||
+			checkAfter && o[1] < len(doc) && isDigit(doc[o[1]]) ||
+			overlapsAny(o, reflections) {
+			continue
+		}
+
+		occurrences = append(occurrences, occurrence.Occurrence{o[0] + offset, o[1] + offset})
+	}
+
+	logger.For(ctx).Debugf("Computed payload (%s = %d) found %d time(s)", expr, result, len(occurrences))
+
+	return len(occurrences) > 0, occurrences
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func overlapsAny(o occurrence.Occurrence, others []occurrence.Occurrence) bool {
+	for _, other := range others {
+		if o[0] < other[1] && other[0] < o[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchCORSMisconfig checks whether the response allows credentialed cross-origin
+// requests (i.e. Access-Control-Allow-Credentials: true) from any origin, either by
+// reflecting an attacker-controlled origin, or with a wildcard (*) or null origin.
+//
+// The attacker-controlled origins are those defined by the grep value (see
+// [profile.GrepValue.AsCORSOrigins]) or, if empty, the request's Origin header,
+// which can be injected with an active profile (e.g. as a new header), or set on
+// every request sent (i.e. --cors-origin).
+//
+// The occurrences returned are those of both headers, so the reflected origin
+// and the credentials flag are reported.
+func matchCORSMisconfig(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil {
+		return false, []occurrence.Occurrence{}
+	}
+
+	allowOrigin := strings.TrimSpace(strings.Join(res.Headers["Access-Control-Allow-Origin"], ", "))
+	allowCredentials := strings.TrimSpace(strings.Join(res.Headers["Access-Control-Allow-Credentials"], ", "))
+
+	if len(allowOrigin) == 0 || !strings.EqualFold(allowCredentials, "true") {
+		return false, []occurrence.Occurrence{}
+	}
+
+	origins := g.Value.AsCORSOrigins()
+	if len(origins) == 0 && req != nil {
+		for _, origin := range req.Headers["Origin"] {
+			origins = append(origins, strings.ToLower(strings.TrimSpace(origin)))
+		}
+	}
+
+	origin := strings.ToLower(allowOrigin)
+	if origin != "*" && origin != "null" && !slices.In(origins, origin) {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("CORS misconfiguration found, allowed origin: %s (with credentials)", allowOrigin)
+
+	doc := string(res.Bytes())
+	occurrences := headerOccurrences(doc, "Access-Control-Allow-Origin", res.Headers["Access-Control-Allow-Origin"])
+	occurrences = append(occurrences, headerOccurrences(doc, "Access-Control-Allow-Credentials", res.Headers["Access-Control-Allow-Credentials"])...)
+
+	return true, occurrences
+}
+
+var (
+	// listingRegex matches the directory listings from Apache and nginx (autoindex),
+	// lighttpd, Python's http.server and IIS.
+	listingRegex = regexp.MustCompile(`(?i)<(?:title|h1)>\s*(?:Index of|Directory listing for) /[^<]*|\[To Parent Directory\]`)
+	// gitConfigRegex matches the core section of a git config file.
+	gitConfigRegex = regexp.MustCompile(`(?m)^\[core\]\s*$`)
+	// gitHeadRegex matches the contents of a git HEAD file.
+	gitHeadRegex = regexp.MustCompile(`^ref: refs/heads/\S+`)
+	// envRegex matches the variables defined on a dotenv file (i.e. KEY=VALUE).
+	envRegex = regexp.MustCompile(`(?m)^(?:export\s+)?[A-Z][A-Z0-9_]*=.*$`)
+	// sourceRegex matches server-side source code markers (e.g. PHP, ASP or JSP tags, or shebang lines).
+	sourceRegex = regexp.MustCompile(`<\?php|<%[@=]?|(?m)^#!/.*$`)
+	// htmlRegex matches the beginning of an HTML document (e.g. a soft-404 page).
+	htmlRegex = regexp.MustCompile(`(?i)<!doctype html|<html`)
+	// sqlDumpRegex matches the statements (or headers) of an SQL dump (e.g. from mysqldump or pg_dump).
+	sqlDumpRegex = regexp.MustCompile(`(?im)^(?:CREATE TABLE|INSERT INTO|-- MySQL dump|-- PostgreSQL database dump)`)
+)
+
+// archiveMagics are the magic numbers of the archives backups are commonly made with (i.e. ZIP and gzip).
+var archiveMagics = []string{"PK\x03\x04", "\x1f\x8b"}
+
+// backupExtensions are the file extensions (or suffixes) of those files commonly
+// left behind as backups, whose content is usually served as is (i.e. not executed).
+var backupExtensions = []string{".bak", ".old", ".orig", ".backup", ".save", ".sav", ".tmp", ".copy", "~"}
+
+// swapExtensions are the file extensions of Vim's swap files.
+var swapExtensions = []string{".swp", ".swo", ".swn"}
+
+// maxSnippetLength is the maximum length of the snippet reported (as occurrence)
+// by [matchExposedContent], which is cut at the end of the line, if shorter.
+const maxSnippetLength = 120
+
+// matchExposedContent checks whether the response exposes content that shouldn't be
+// publicly available, by correlating the request's path with the response's body.
+// The exposures looked for are those defined by the grep value (see [profile.GrepValue.AsExposures]):
+//   - listing: a directory listing (any path), e.g. Apache's "Index of /".
+//   - git: a git config or HEAD file (.git/config or .git/HEAD).
+//   - env: a dotenv file (.env, .env.local...) with KEY=VALUE variables.
+//   - backup: a backup file (e.g. index.php.bak) with evidence of its content (see [backupContentIndex]).
+//   - swap: a Vim swap file (e.g. .index.php.swp).
+//
+// Only successful (200) responses are considered, and the occurrences returned
+// are snippets of the exposed content (see [maxSnippetLength]).
+func matchExposedContent(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response) (bool, []occurrence.Occurrence) {
+	if req == nil || res == nil || res.Code != http.StatusOK || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	path, _, _ := strings.Cut(req.Path, "?")
+	path = strings.ToLower(path)
+	name := filepath.Base(path)
+
+	var occurrences []occurrence.Occurrence
+	for _, exposure := range g.Value.AsExposures() {
+		var loc []int
+
+		switch exposure {
+		case profile.ExposureListing:
+			loc = listingRegex.FindStringIndex(body)
+		case profile.ExposureGit:
+			if strings.HasSuffix(path, "/.git/config") {
+				loc = gitConfigRegex.FindStringIndex(body)
+			} else if strings.HasSuffix(path, "/.git/head") {
+				loc = gitHeadRegex.FindStringIndex(body)
+			}
+		case profile.ExposureEnv:
+			if strings.HasPrefix(name, ".env") && !htmlRegex.MatchString(body) {
+				loc = envRegex.FindStringIndex(body)
+			}
+		case profile.ExposureBackup:
+			if hasAnySuffix(name, backupExtensions) {
+				loc = backupContentIndex(body)
+			}
+		case profile.ExposureSwap:
+			if hasAnySuffix(name, swapExtensions) && strings.HasPrefix(body, "b0VIM ") {
+				loc = []int{0, 0}
+			}
+		}
+
+		if loc == nil {
+			continue
+		}
+
+		logger.For(ctx).Debugf("Exposed content found (%s), path: %s", exposure, req.Path)
+
+		snippet := snippetAt(body, loc[0])
+		occurrences = append(occurrences, occurrence.Occurrence{snippet[0] + offset, snippet[1] + offset})
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// backupContentIndex returns the location of the evidence of the given body being a backup:
+// an archive, source code, an SQL dump or dotenv-like variables, or nil if there's none.
+// So, any other content served for backup paths (e.g. soft-404 pages) isn't reported.
+func backupContentIndex(body string) []int {
+	for _, magic := range archiveMagics {
+		if strings.HasPrefix(body, magic) {
+			return []int{0, 0}
+		}
+	}
+
+	for _, regex := range []*regexp.Regexp{sourceRegex, sqlDumpRegex, envRegex} {
+		if loc := regex.FindStringIndex(body); loc != nil {
+			return loc
+		}
+	}
+
+	return nil
+}
+
+// snippetAt returns the occurrence of the snippet that starts at the given
+// position, up to the end of the line, but no longer than [maxSnippetLength].
+func snippetAt(s string, start int) occurrence.Occurrence {
+	end := min(len(s), start+maxSnippetLength)
+	if idx := strings.IndexAny(s[start:end], "\r\n"); idx >= 0 {
+		end = start + idx
+	}
+
+	return occurrence.Occurrence{start, end}
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headerOccurrences returns the occurrences of the given
+// header value(s) within the given (response) document.
+func headerOccurrences(doc, key string, values []string) []occurrence.Occurrence {
+	prefix := key + ": "
+
+	occurrences := occurrence.Find(doc, prefix+strings.Join(values, ", "))
+	for i := range occurrences {
+		occurrences[i][0] += len(prefix)
+	}
+
+	return occurrences
+}
+
+// dangerousRedirectSchemes are those schemes that, used as redirect targets,
+// lead to script execution (or content injection) within the browser.
+var dangerousRedirectSchemes = []string{"javascript", "vbscript", "data"}
+
+// matchOpenRedirect checks whether the response is a redirection (3xx) whose Location
+// header, once resolved against the request URL, points to an attacker-controlled
+// destination: either any of the hosts defined as the grep value or, if none, the host
+// derived from the payload (e.g. https://evil.com, //evil.com). Redirections to dangerous
+// schemes (e.g. javascript:) carried by the payload are also considered a match.
+// The occurrence returned is the Location header value.
+func matchOpenRedirect(ctx context.Context, g profile.Grep, req *request.Request, res *response.Response, payload *string) (bool, []occurrence.Occurrence) {
+	if res == nil || res.Code < 300 || res.Code > 399 || len(res.Headers["Location"]) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	target, err := resolveRedirect(req, res.Headers["Location"][0])
+	if err != nil {
+		logger.For(ctx).Debugf("Couldn't resolve redirect location: %s", err.Error())
+		return false, []occurrence.Occurrence{}
+	}
+
+	var injected string
+	if payload != nil {
+		injected = *payload
+	}
+
+	hosts := g.Value.AsRedirectHosts()
+	if len(hosts) == 0 {
+		if host := redirectHost(injected); len(host) > 0 {
+			hosts = []string{host}
+		}
+	}
+
+	switch {
+	case slices.In(dangerousRedirectSchemes, target.Scheme):
+		if !strings.Contains(cleanRedirect(strings.ToLower(injected)), target.Scheme+":") {
+			return false, []occurrence.Occurrence{}
+		}
+	case len(target.Host) == 0 || !slices.In(hosts, strings.ToLower(target.Hostname())):
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("Open redirect found, redirecting to: %s", target.String())
+
+	return true, locationOccurrences(res)
+}
+
+// resolveRedirect resolves the given location against the request URL,
+// the same way browsers do, so relative references (e.g. //evil.com) are
+// resolved to absolute ones.
+func resolveRedirect(req *request.Request, location string) (*url.URL, error) {
+	loc, err := url.Parse(cleanRedirect(location))
+	if err != nil {
+		return nil, err
+	}
+
+	base := &url.URL{}
+	if req != nil {
+		if u, err := url.Parse(req.URL); err == nil {
+			base = u
+		}
+	}
+
+	return base.ResolveReference(loc), nil
+}
+
+// redirectHost returns the (lowercase) host the given payload would redirect to,
+// if used as a redirect target, or an empty string if there's no such host.
+func redirectHost(payload string) string {
+	payload = cleanRedirect(payload)
+	if len(payload) == 0 {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(payload, "//"):
+		payload = "http:" + payload
+	case !strings.Contains(payload, "://"):
+		payload = "http://" + payload
+	}
+
+	u, err := url.Parse(payload)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// cleanRedirect removes the whitespaces and control characters, and replaces
+// the backslashes with slashes, as browsers do with redirect locations.
+func cleanRedirect(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.ReplaceAll(s, "\\", "/")
+}
+
+// locationOccurrences returns the occurrences of the Location header
+// value(s) within the response.
+func locationOccurrences(res *response.Response) []occurrence.Occurrence {
+	return headerOccurrences(string(res.Bytes()), "Location", res.Headers["Location"])
+}
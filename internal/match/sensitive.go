@@ -0,0 +1,138 @@
+package match
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// Signature is a named pattern of sensitive data (e.g. an API key or a private key)
+// looked for by the Sensitive Data grep (see [matchSensitiveData]). If defined,
+// Check is used to discard the false positives (e.g. the Luhn check for credit cards).
+type Signature struct {
+	Name  string
+	Regex *regexp.Regexp
+	Check func(string) bool
+}
+
+func (s Signature) signatureName() string { return s.Name }
+
+// defaultSignatures are the built-in [Signature] set (see [DefaultSignatures]).
+var defaultSignatures = []Signature{
+	{Name: "aws-access-key", Regex: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "google-api-key", Regex: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`)},
+	{Name: "slack-token", Regex: regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z\-]{10,72}`)},
+	{Name: "private-key", Regex: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?(?:-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----|$)`)},
+	{Name: "email", Regex: regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}\b`)},
+	{Name: "credit-card", Regex: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`), Check: luhn},
+}
+
+// DefaultSignatures returns the built-in [Signature] set: AWS access keys,
+// Google API keys, Slack tokens, private keys, emails and credit cards.
+func DefaultSignatures() []Signature {
+	return cloneSignatures(defaultSignatures)
+}
+
+// ReadSignatures reads the [Signature] set from the given [io.Reader], one
+// per line, with the form name=regex (e.g. github-token=ghp_[0-9A-Za-z]{36}),
+// see [readSignatures].
+func ReadSignatures(r io.Reader) ([]Signature, error) {
+	return readSignatures(r, signatureFormat[Signature]{
+		Form: "name=regex",
+		Build: func(parts []string, regex *regexp.Regexp) (Signature, bool) {
+			return Signature{Name: parts[0], Regex: regex}, true
+		},
+	})
+}
+
+// WithSignatures returns a copy of the given [context.Context] with the given custom
+// [Signature] set, looked for by the Sensitive Data greps (see [mergeSignatures]).
+func WithSignatures(ctx context.Context, signatures []Signature) context.Context {
+	return withSignatures(ctx, signatures)
+}
+
+// matchSensitiveData checks whether the response's body contains sensitive data,
+// like API keys, private keys, emails or credit cards, by looking for the signatures
+// defined by the grep value (see [profile.GrepValue.AsSignatures]), either built-in
+// (see [DefaultSignatures]) or custom (see [WithSignatures]).
+//
+// The occurrences returned are the values found, which are expected to be
+// redacted before being reported (see [Redact]).
+func matchSensitiveData(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil || len(res.Body) == 0 {
+		return false, []occurrence.Occurrence{}
+	}
+
+	body := string(res.Body)
+	offset := res.BodyOffset()
+
+	names := g.Value.AsSignatures()
+
+	var occurrences []occurrence.Occurrence
+	for _, signature := range mergeSignatures(defaultSignatures, customSignatures[Signature](ctx)) {
+		if len(names) > 0 && !slices.In(names, signature.Name) {
+			continue
+		}
+
+		for _, loc := range signature.Regex.FindAllStringIndex(body, -1) {
+			if loc[0] == loc[1] || (signature.Check != nil && !signature.Check(body[loc[0]:loc[1]])) {
+				continue
+			}
+
+			logger.For(ctx).Debugf("Sensitive data found (%s) at: %d", signature.Name, loc[0])
+			occurrences = append(occurrences, occurrence.Occurrence{loc[0] + offset, loc[1] + offset})
+		}
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// luhn returns whether the digits of the given string (spaces
+// and dashes are ignored) pass the Luhn (mod 10) checksum.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		if !isDigit(c) {
+			return false
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		n++
+	}
+
+	return n > 0 && sum%10 == 0
+}
+
+// Redact returns the given (sensitive) value partially masked with asterisks,
+// keeping the first and last (up to) four characters, so it can still be
+// identified. The length is kept, so occurrences remain valid once redacted.
+func Redact(s string) string {
+	const visible = 4
+
+	keep := visible
+	if len(s) < 3*visible {
+		keep = len(s) / 4
+	}
+
+	return s[:keep] + strings.Repeat("*", len(s)-2*keep) + s[len(s)-keep:]
+}
@@ -0,0 +1,137 @@
+package match
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+// ErrInvalidSignature is the error returned when reading custom signatures (e.g. [ReadSignatures])
+// if any of them is malformed, or its regular expression is invalid.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// signatureFormat describes the line format of a set of custom signatures (e.g. [ReadFileSignatures]):
+// Form is the line form reported on errors (e.g. name=file=regex), whose parts (separated by =) are
+// the ones each line is split into, the first one being the signature name, and the last one its
+// regular expression, so it can contain = too. Build makes the signature out of the (trimmed) parts
+// and the compiled regular expression, or returns false if any part is invalid.
+//
+// Directive, if defined, is called first with every line, and returns true if the line isn't
+// a signature, but a directive (e.g. version=2024.1), so it is skipped.
+type signatureFormat[S any] struct {
+	Form      string
+	Build     func(parts []string, regex *regexp.Regexp) (S, bool)
+	Directive func(line string) bool
+}
+
+// readSignatures reads the signatures from the given [io.Reader], one per line, with the given
+// [signatureFormat]. Blank lines and those starting with # (comments) are skipped.
+//
+// Signature names must be lowercase alphanumeric (plus - and _), so these can be used as the
+// value of the greps (see [profile.IsSignatureName]).
+func readSignatures[S any](r io.Reader, format signatureFormat[S]) ([]S, error) {
+	var (
+		signatures []S
+		lineNum    int
+	)
+
+	numOfParts := strings.Count(format.Form, "=") + 1
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if format.Directive != nil && format.Directive(line) {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", numOfParts)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		if len(parts) < numOfParts || !profile.IsSignatureName(parts[0]) || len(parts[numOfParts-1]) == 0 {
+			return nil, fmt.Errorf("%w (line %d), it must be %s: %s", ErrInvalidSignature, lineNum, format.Form, line)
+		}
+
+		regex, err := regexp.Compile(parts[numOfParts-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w (line %d): %s", ErrInvalidSignature, lineNum, err.Error())
+		}
+
+		signature, ok := format.Build(parts, regex)
+		if !ok {
+			return nil, fmt.Errorf("%w (line %d), it must be %s: %s", ErrInvalidSignature, lineNum, format.Form, line)
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures, scanner.Err()
+}
+
+// signaturesKey is the [context.Context] key for the custom signatures of type S (e.g. [FileSignature]).
+type signaturesKey[S any] struct{}
+
+// withSignatures returns a copy of the given [context.Context] with the given custom signatures,
+// if any, so these are looked for by the corresponding greps (see [customSignatures]).
+func withSignatures[S any](ctx context.Context, signatures []S) context.Context {
+	if len(signatures) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, signaturesKey[S]{}, signatures)
+}
+
+// customSignatures returns the custom signatures of type S set into the given
+// [context.Context] (see [withSignatures]), if any.
+func customSignatures[S any](ctx context.Context) []S {
+	custom, _ := ctx.Value(signaturesKey[S]{}).([]S)
+	return custom
+}
+
+// namedSignature is implemented by the signatures that can be overridden by name
+// (see [mergeSignatures]).
+type namedSignature interface {
+	signatureName() string
+}
+
+// mergeSignatures returns the given default signatures along with the given custom ones,
+// which take precedence over those with the same name (i.e. these are overridden).
+// The defaults are returned as is if there are no custom ones.
+func mergeSignatures[S namedSignature](defaults, custom []S) []S {
+	if len(custom) == 0 {
+		return defaults
+	}
+
+	overridden := make(map[string]struct{}, len(custom))
+	for _, s := range custom {
+		overridden[s.signatureName()] = struct{}{}
+	}
+
+	signatures := make([]S, 0, len(defaults)+len(custom))
+	for _, s := range defaults {
+		if _, ok := overridden[s.signatureName()]; !ok {
+			signatures = append(signatures, s)
+		}
+	}
+
+	return append(signatures, custom...)
+}
+
+// cloneSignatures returns a copy of the given (default) signatures,
+// so these cannot be modified by callers (e.g. by appending).
+func cloneSignatures[S any](signatures []S) []S {
+	return append(make([]S, 0, len(signatures)), signatures...)
+}
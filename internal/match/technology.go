@@ -0,0 +1,294 @@
+package match
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// DefaultTechnologySignaturesVersion is the version of the built-in [TechnologySignature] set
+// (see [DefaultTechnologySignatures]), bumped whenever it changes, so the findings can be traced
+// back to the signatures that produced them (see [TechnologySignaturesVersion]).
+const DefaultTechnologySignaturesVersion = "1"
+
+// Technology signature sources (see [TechnologySignature.Source]).
+const (
+	TechnologySourceHeader = "header"
+	TechnologySourceCookie = "cookie"
+	TechnologySourceBody   = "body"
+)
+
+// TechnologySignature is a pattern that identifies a technology (e.g. nginx or PHP), looked for
+// by the Technology grep (see [matchTechnology]), either within the value of a response header
+// (e.g. Server), the names of the cookies set (e.g. PHPSESSID) or the response body.
+//
+// If the regular expression has a capturing group, the first one is the technology version.
+// The same technology can be identified by multiple signatures (e.g. from headers and cookies).
+type TechnologySignature struct {
+	Name   string
+	Source string
+	Header string
+	Regex  *regexp.Regexp
+}
+
+// TechnologySignatures is a versioned set of [TechnologySignature].
+type TechnologySignatures struct {
+	Version    string
+	Signatures []TechnologySignature
+}
+
+// defaultTechnologySignatures are the built-in [TechnologySignature] set (see [DefaultTechnologySignatures]).
+var defaultTechnologySignatures = func() []TechnologySignature {
+	header := func(name, key, expr string) TechnologySignature {
+		return TechnologySignature{Name: name, Source: TechnologySourceHeader, Header: key, Regex: regexp.MustCompile(expr)}
+	}
+	cookie := func(name, expr string) TechnologySignature {
+		return TechnologySignature{Name: name, Source: TechnologySourceCookie, Regex: regexp.MustCompile(expr)}
+	}
+	body := func(name, expr string) TechnologySignature {
+		return TechnologySignature{Name: name, Source: TechnologySourceBody, Regex: regexp.MustCompile(expr)}
+	}
+
+	return []TechnologySignature{
+		header("nginx", "Server", `(?i)\bnginx(?:/([\d.]+))?`),
+		header("apache", "Server", `(?i)\bApache(?:/([\d.]+))?(?:$|[\s(])`),
+		header("iis", "Server", `(?i)\bMicrosoft-IIS(?:/([\d.]+))?`),
+		header("litespeed", "Server", `(?i)\bLiteSpeed\b`),
+		header("caddy", "Server", `(?i)\bCaddy\b`),
+		header("cloudflare", "Server", `(?i)^cloudflare$`),
+		header("php", "X-Powered-By", `(?i)\bPHP(?:/([\d.]+))?`),
+		header("aspnet", "X-Powered-By", `(?i)\bASP\.NET\b`),
+		header("aspnet", "X-AspNet-Version", `^([\d.]+)$`),
+		header("express", "X-Powered-By", `(?i)\bExpress\b`),
+		header("nextjs", "X-Powered-By", `(?i)\bNext\.js(?: ([\d.]+))?`),
+		header("drupal", "X-Generator", `(?i)\bDrupal(?: ([\d.]+))?`),
+		cookie("php", `^PHPSESSID$`),
+		cookie("java", `^JSESSIONID$`),
+		cookie("aspnet", `^ASP\.NET_SessionId$`),
+		cookie("laravel", `^laravel_session$`),
+		cookie("django", `^(?:csrftoken|sessionid)$`),
+		body("wordpress", `<meta name="generator" content="WordPress ?([\d.]+)?"`),
+		body("wordpress", `/wp-(?:content|includes)/`),
+		body("joomla", `<meta name="generator" content="Joomla!`),
+	}
+}()
+
+// DefaultTechnologySignatures returns the built-in [TechnologySignature] set (see
+// [DefaultTechnologySignaturesVersion]): web servers (e.g. nginx, Apache or IIS),
+// languages and frameworks (e.g. PHP, ASP.NET, Java or Express) and CMSs (e.g. WordPress).
+func DefaultTechnologySignatures() []TechnologySignature {
+	return cloneSignatures(defaultTechnologySignatures)
+}
+
+// ReadTechnologySignatures reads the [TechnologySignatures] from the given [io.Reader], one per line,
+// with the form name=source=regex, where source is either header:Name (e.g. header:Server), cookie
+// or body (e.g. varnish=header:Via=(?i)varnish(?: \(Varnish/([\d.]+)\))?), see [readSignatures].
+// The set can be versioned with a version=v line (e.g. version=2024.1).
+func ReadTechnologySignatures(r io.Reader) (TechnologySignatures, error) {
+	var set TechnologySignatures
+
+	signatures, err := readSignatures(r, signatureFormat[TechnologySignature]{
+		Form: "name=source=regex",
+		Directive: func(line string) bool {
+			name, version, found := strings.Cut(line, "=")
+			if strings.TrimSpace(name) != "version" || !found || len(strings.TrimSpace(version)) == 0 {
+				return false
+			}
+
+			set.Version = strings.TrimSpace(version)
+			return true
+		},
+		Build: func(parts []string, regex *regexp.Regexp) (TechnologySignature, bool) {
+			source, header, _ := strings.Cut(parts[1], ":")
+			header = strings.TrimSpace(header)
+
+			valid := (source == TechnologySourceHeader && len(header) > 0) ||
+				(slices.In([]string{TechnologySourceCookie, TechnologySourceBody}, source) && len(header) == 0)
+
+			return TechnologySignature{Name: parts[0], Source: source, Header: header, Regex: regex}, valid
+		},
+	})
+	if err != nil {
+		return TechnologySignatures{}, err
+	}
+
+	set.Signatures = signatures
+	return set, nil
+}
+
+// technologyVersionKey is the [context.Context] key for the version of the custom
+// technology signatures, if any (see [WithTechnologySignatures]).
+type technologyVersionKey struct{}
+
+// WithTechnologySignatures returns a copy of the given [context.Context] with the given
+// [TechnologySignatures], looked for by the Technology greps along with the defaults ones.
+// Unlike other signatures, those with the same name as any of the default ones don't
+// override them, as the same technology can be identified by multiple signatures.
+func WithTechnologySignatures(ctx context.Context, signatures TechnologySignatures) context.Context {
+	if len(signatures.Signatures) == 0 {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, technologyVersionKey{}, signatures.Version)
+	return withSignatures(ctx, signatures.Signatures)
+}
+
+// TechnologySignaturesVersion returns the version of the [TechnologySignature] set looked for by
+// the Technology greps: the built-in one (e.g. builtin-1) and the custom one, if any (e.g.
+// builtin-1, custom-2024.1). So, it can be reported along with the technologies found.
+func TechnologySignaturesVersion(ctx context.Context) string {
+	version := "builtin-" + DefaultTechnologySignaturesVersion
+
+	custom, ok := ctx.Value(technologyVersionKey{}).(string)
+	switch {
+	case !ok:
+		return version
+	case len(custom) > 0:
+		return version + ", custom-" + custom
+	default:
+		return version + ", custom"
+	}
+}
+
+// matchTechnology checks whether the response identifies any technology (e.g. nginx or PHP),
+// either by its headers (e.g. Server or X-Powered-By), the names of the cookies set (e.g.
+// PHPSESSID) or by any marker within its body, looking for the signatures defined by the grep
+// value (see [profile.GrepValue.AsSignatures]), either built-in (see [DefaultTechnologySignatures])
+// or custom (see [WithTechnologySignatures]).
+//
+// The occurrences returned are the markers found. The technologies found (see [Technologies])
+// are expected to be reported along with the match, as the matcher only reports where.
+func matchTechnology(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	if res == nil || res.IsEmpty() {
+		return false, []occurrence.Occurrence{}
+	}
+
+	doc := string(res.Bytes())
+
+	var occurrences []occurrence.Occurrence
+	for _, signature := range technologySignatures(ctx, g) {
+		for _, loc := range signature.find(doc, res) {
+			logger.For(ctx).Debugf("Technology found (%s) at: %d", signature.Name, loc[0])
+			occurrences = append(occurrences, loc)
+		}
+	}
+
+	return len(occurrences) > 0, occurrences
+}
+
+// Technologies returns the technologies (e.g. nginx 1.18.0 or php) identified by the given
+// response, according to the given Technology grep (see [profile.GrepTypeTechnology]), along
+// with their version, if found. So, the technologies can be reported along with the match.
+func Technologies(ctx context.Context, g profile.Grep, res *response.Response) []string {
+	if res == nil || res.IsEmpty() {
+		return nil
+	}
+
+	doc := string(res.Bytes())
+
+	var (
+		names    []string
+		versions = make(map[string]string)
+	)
+	for _, signature := range technologySignatures(ctx, g) {
+		for _, loc := range signature.find(doc, res) {
+			if !slices.In(names, signature.Name) {
+				names = append(names, signature.Name)
+			}
+
+			if found := signature.Regex.FindStringSubmatch(doc[loc[0]:loc[1]]); len(found) > 1 && len(found[1]) > 0 && len(versions[signature.Name]) == 0 {
+				versions[signature.Name] = found[1]
+			}
+		}
+	}
+
+	var technologies []string
+	for _, name := range names {
+		if version := versions[name]; len(version) > 0 {
+			name += " " + version
+		}
+		technologies = append(technologies, name)
+	}
+
+	return technologies
+}
+
+// technologySignatures returns the [TechnologySignature] set looked for by the given grep.
+func technologySignatures(ctx context.Context, g profile.Grep) []TechnologySignature {
+	names := g.Value.AsSignatures()
+
+	var signatures []TechnologySignature
+	for _, set := range [][]TechnologySignature{defaultTechnologySignatures, customSignatures[TechnologySignature](ctx)} {
+		for _, signature := range set {
+			if len(names) == 0 || slices.In(names, signature.Name) {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	return signatures
+}
+
+// find returns the occurrences of the [TechnologySignature] within the given
+// document, which is expected to be the given [response.Response] bytes.
+func (s TechnologySignature) find(doc string, res *response.Response) []occurrence.Occurrence {
+	switch s.Source {
+	case TechnologySourceHeader:
+		key := textproto.CanonicalMIMEHeaderKey(s.Header)
+		return s.findWithin(doc, headerOccurrences(doc, key, res.Headers[key]))
+	case TechnologySourceCookie:
+		return s.findCookies(doc, res)
+	default:
+		body := occurrence.Occurrence{res.BodyOffset(), len(doc)}
+		return s.findWithin(doc, []occurrence.Occurrence{body})
+	}
+}
+
+// findWithin returns the matches of the [TechnologySignature]'s regular expression
+// within the given ranges (e.g. a header value) of the given document.
+func (s TechnologySignature) findWithin(doc string, ranges []occurrence.Occurrence) []occurrence.Occurrence {
+	var occurrences []occurrence.Occurrence
+	for _, r := range ranges {
+		for _, loc := range s.Regex.FindAllStringIndex(doc[r[0]:r[1]], -1) {
+			if loc[0] < loc[1] {
+				occurrences = append(occurrences, occurrence.Occurrence{r[0] + loc[0], r[0] + loc[1]})
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// findCookies returns the occurrences of the names of the cookies set by the given
+// [response.Response] (i.e. Set-Cookie) that match the [TechnologySignature].
+func (s TechnologySignature) findCookies(doc string, res *response.Response) []occurrence.Occurrence {
+	setCookies := res.Headers["Set-Cookie"]
+	ranges := headerOccurrences(doc, "Set-Cookie", setCookies)
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	var occurrences []occurrence.Occurrence
+	for _, c := range (&http.Response{Header: http.Header{"Set-Cookie": setCookies}}).Cookies() {
+		if !s.Regex.MatchString(c.Name) {
+			continue
+		}
+
+		value := doc[ranges[0][0]:ranges[0][1]]
+		if idx := strings.Index(value, c.Name+"="); idx >= 0 {
+			start := ranges[0][0] + idx
+			occurrences = append(occurrences, occurrence.Occurrence{start, start + len(c.Name)})
+		}
+	}
+
+	return occurrences
+}
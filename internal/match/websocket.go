@@ -0,0 +1,67 @@
+package match
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// matchWebSocketUpgrade checks whether the response completes a WebSocket opening handshake:
+// a 101 Switching Protocols with the Upgrade: websocket header. If the grep value defines
+// any subprotocol (see [profile.GrepValue.AsWebSocketProtocols]), the one negotiated
+// (i.e. the Sec-WebSocket-Protocol header) must be among them.
+//
+// The occurrences returned are the Upgrade and Sec-WebSocket-Protocol header values.
+func matchWebSocketUpgrade(ctx context.Context, g profile.Grep, res *response.Response) (bool, []occurrence.Occurrence) {
+	if !WebSocketUpgraded(res) {
+		return false, []occurrence.Occurrence{}
+	}
+
+	protocol := WebSocketProtocol(res)
+	if protocols := g.Value.AsWebSocketProtocols(); len(protocols) > 0 && !slices.In(protocols, protocol) {
+		return false, []occurrence.Occurrence{}
+	}
+
+	logger.For(ctx).Debugf("WebSocket upgrade found, negotiated subprotocol: %q", protocol)
+
+	doc := string(res.Bytes())
+	occurrences := headerOccurrences(doc, "Upgrade", res.Headers["Upgrade"])
+	if len(protocol) > 0 {
+		occurrences = append(occurrences, headerOccurrences(doc, "Sec-Websocket-Protocol", res.Headers["Sec-Websocket-Protocol"])...)
+	}
+
+	return true, occurrences
+}
+
+// WebSocketUpgraded returns whether the given response completes a WebSocket opening
+// handshake (see RFC 6455, section 4.2.2), that is: a 101 Switching Protocols
+// with the Upgrade: websocket header.
+func WebSocketUpgraded(res *response.Response) bool {
+	if res == nil || res.Code != http.StatusSwitchingProtocols {
+		return false
+	}
+
+	for _, upgrade := range res.Headers["Upgrade"] {
+		if strings.EqualFold(strings.TrimSpace(upgrade), "websocket") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebSocketProtocol returns the WebSocket subprotocol negotiated by the given
+// response (i.e. the Sec-WebSocket-Protocol header), if any.
+func WebSocketProtocol(res *response.Response) string {
+	if res == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.Join(res.Headers["Sec-Websocket-Protocol"], ", "))
+}
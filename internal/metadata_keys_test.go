@@ -0,0 +1,53 @@
+package scan_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestMetadataKeys(t *testing.T) {
+	t.Parallel()
+
+	// Every Metadata* constant (i.e. a key set by the scanner) must be listed.
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+
+	var keys []string
+	for _, f := range pkgs["scan"].Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.ValueSpec)
+			if !ok {
+				return true
+			}
+
+			for i, name := range spec.Names {
+				if !strings.HasPrefix(name.Name, "Metadata") || i >= len(spec.Values) {
+					continue
+				}
+
+				if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					key, err := strconv.Unquote(lit.Value)
+					require.NoError(t, err)
+					keys = append(keys, key)
+				}
+			}
+
+			return true
+		})
+	}
+
+	require.NotEmpty(t, keys)
+	assert.ElementsMatch(t, keys, scan.MetadataKeys())
+}
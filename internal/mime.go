@@ -0,0 +1,87 @@
+package scan
+
+import (
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// MimeFilter defines which responses are analyzed (or not) by the body matchers,
+// based on their media type (see [response.Response.MediaType]).
+//
+// Both, Allow and Deny, are lists of media types (e.g. text/html), where the
+// subtype can be a wildcard (e.g. image/*). If Allow is non-empty, only those
+// responses with an allowed media type are analyzed. Those with a denied media
+// type are never analyzed, even if allowed.
+type MimeFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+// IsEmpty returns whether the [MimeFilter] has neither allowed nor denied types.
+func (f MimeFilter) IsEmpty() bool {
+	return len(f.Allow) == 0 && len(f.Deny) == 0
+}
+
+// Skips returns whether the body of the given [response.Response]
+// must be skipped (i.e. not analyzed) according to the [MimeFilter].
+//
+// Responses with no body are never skipped, as there's nothing to skip.
+func (f MimeFilter) Skips(res *response.Response) bool {
+	if f.IsEmpty() || res == nil || len(res.Body) == 0 {
+		return false
+	}
+
+	mediaType := res.MediaType()
+
+	for _, pattern := range f.Deny {
+		if matchesMediaType(pattern, mediaType) {
+			return true
+		}
+	}
+
+	if len(f.Allow) == 0 {
+		return false
+	}
+
+	for _, pattern := range f.Allow {
+		if matchesMediaType(pattern, mediaType) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of the [MimeFilter] instance.
+func (f MimeFilter) Clone() MimeFilter {
+	return MimeFilter{
+		Allow: cloneStrings(f.Allow),
+		Deny:  cloneStrings(f.Deny),
+	}
+}
+
+func matchesMediaType(pattern, mediaType string) bool {
+	if prefix, wildcard := strings.CutSuffix(pattern, "/*"); wildcard {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+
+	return pattern == mediaType
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append([]string{}, s...)
+}
+
+// withoutBody returns a copy of the given [response.Response], with no body,
+// so only the status line and headers are analyzed by the matchers.
+func withoutBody(res *response.Response) *response.Response {
+	stripped := *res
+	stripped.Body = []byte{}
+
+	return &stripped
+}
@@ -0,0 +1,81 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestMimeFilter_Skips(t *testing.T) {
+	t.Parallel()
+
+	resWithType := func(contentType string) *response.Response {
+		return &response.Response{
+			Headers: map[string][]string{"Content-Type": {contentType}},
+			Body:    []byte("..."),
+		}
+	}
+
+	tcs := map[string]struct {
+		filter scan.MimeFilter
+		res    *response.Response
+		exp    bool
+	}{
+		"empty filter": {
+			filter: scan.MimeFilter{},
+			res:    resWithType("image/png"),
+			exp:    false,
+		},
+		"denied": {
+			filter: scan.MimeFilter{Deny: []string{"image/png"}},
+			res:    resWithType("image/png"),
+			exp:    true,
+		},
+		"denied with wildcard": {
+			filter: scan.MimeFilter{Deny: []string{"font/*", "image/*"}},
+			res:    resWithType("image/svg+xml"),
+			exp:    true,
+		},
+		"not denied": {
+			filter: scan.MimeFilter{Deny: []string{"image/*"}},
+			res:    resWithType("text/html; charset=utf-8"),
+			exp:    false,
+		},
+		"allowed": {
+			filter: scan.MimeFilter{Allow: []string{"text/*", "application/json"}},
+			res:    resWithType("application/json"),
+			exp:    false,
+		},
+		"not allowed": {
+			filter: scan.MimeFilter{Allow: []string{"text/*", "application/json"}},
+			res:    resWithType("application/octet-stream"),
+			exp:    true,
+		},
+		"allowed but denied": {
+			filter: scan.MimeFilter{Allow: []string{"text/*"}, Deny: []string{"text/css"}},
+			res:    resWithType("text/css"),
+			exp:    true,
+		},
+		"sniffed": {
+			filter: scan.MimeFilter{Deny: []string{"image/*"}},
+			res:    &response.Response{Body: []byte("GIF89a...")},
+			exp:    true,
+		},
+		"no body": {
+			filter: scan.MimeFilter{Deny: []string{"image/*"}},
+			res:    &response.Response{Headers: map[string][]string{"Content-Type": {"image/png"}}},
+			exp:    false,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.exp, tc.filter.Skips(tc.res))
+		})
+	}
+}
@@ -17,7 +17,7 @@ var _ scan.Modifier = InteractionHost{}
 
 // InteractionHost is a [scan.Modifier] implementation that replaces the interaction host
 // placeholders (e.g. {IH}, {BH} and {BC}) of a [request.Request] with unique
-// request urls.
+// request urls, and the {{callback}} placeholder with the same, but as a domain.
 type InteractionHost struct {
 	scheme string
 	base   string
@@ -51,7 +51,8 @@ func NewInteractionHost(base string, hid blindhost.HostIdentifier) InteractionHo
 func (ih InteractionHost) Modify(_ *profile.Step, _ scan.Template, req request.Request) request.Request {
 	req.UID = uuid.New().String()[:8]
 	bh := ih.hid.HostReqURL(ih.scheme, ih.base, req.UID)
-	return replace(req, map[string]string{bhLabel: bh, ihLabel: bh, legacyLabel: bh})
+	callback := strings.TrimPrefix(bh, ih.scheme+"://")
+	return replace(req, map[string]string{bhLabel: bh, ihLabel: bh, legacyLabel: bh, callbackPlaceholder: callback})
 }
 
 func urlScheme(addr string) string {
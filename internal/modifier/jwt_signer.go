@@ -0,0 +1,74 @@
+package modifier
+
+import (
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/jwt"
+)
+
+// JWTSigner must implement the [scan.Modifier] interface.
+var _ scan.Modifier = JWTSigner{}
+
+// JWTSigner is a [scan.Modifier] implementation that modifies the request
+// by re-signing the JWT bearer token (i.e. `Authorization: Bearer <token>`)
+// with the given key, so the server accepts the tampered claims.
+//
+// Only tokens that differ from the ones present in the original [scan.Template]
+// and that use an HMAC-based algorithm (HS256, HS384, HS512) are re-signed.
+// Tokens with the "none" algorithm are left unsigned.
+type JWTSigner struct {
+	key string
+}
+
+// NewJWTSigner is a constructor function that creates a new instance of
+// the [JWTSigner] modifier with the given signing key.
+func NewJWTSigner(key string) JWTSigner {
+	return JWTSigner{
+		key: key,
+	}
+}
+
+// Modify modifies the request by re-signing the JWT bearer token, if any.
+func (s JWTSigner) Modify(_ *profile.Step, tpl scan.Template, req request.Request) request.Request {
+	const header = "Authorization"
+
+	values, ok := req.Headers[header]
+	if !ok {
+		return req
+	}
+
+	original := make(map[string]struct{})
+	for _, val := range tpl.Request.Headers[header] {
+		original[strings.TrimSpace(val)] = struct{}{}
+	}
+
+	clone := req.Clone()
+	for i, val := range values {
+		trimmed := strings.TrimSpace(val)
+		if _, unchanged := original[trimmed]; unchanged {
+			continue
+		}
+
+		_, token, ok := jwt.Bearer(trimmed)
+		if !ok {
+			continue
+		}
+
+		tok, err := jwt.Parse(token)
+		if err != nil {
+			continue
+		}
+
+		signed, err := tok.Encode(s.key)
+		if err != nil {
+			continue
+		}
+
+		clone.Headers[header][i] = strings.Replace(val, tok.String(), signed, 1)
+	}
+
+	return clone
+}
@@ -8,9 +8,11 @@ func Modifiers() []scan.Modifier {
 	return []scan.Modifier{
 		NewHTTPMethod(),
 		NewMatchAndReplace(),
+		NewPlaceholders(),
 		NewRandom(),
 		NewTemplate(),
 		NewTimeout(),
+		NewRemoveHeaders(),
 		// NewInteractionHost(), - intentionally commented, as it is created on demand.
 	}
 }
@@ -0,0 +1,127 @@
+package modifier
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+const (
+	// {{target_host}} is replaced with the host (without port) of the scanned template's URL.
+	targetHostPlaceholder = "{{target_host}}"
+
+	// {{timestamp}} is replaced with the Unix time (in seconds) the request is built at.
+	timestampPlaceholder = "{{timestamp}}"
+
+	// {{nonce}} is replaced with a random, hex-encoded, 16-char value, unique per request.
+	noncePlaceholder = "{{nonce}}"
+
+	// {{callback}} is replaced with a unique interaction host domain, unique per request,
+	// so interactions can be correlated. Only available with a blind host, see [InteractionHost].
+	callbackPlaceholder = "{{callback}}"
+)
+
+// placeholderRegex matches any {{name}} placeholder, whether known or not.
+// So, expressions like {{7*7}}, commonly used as payloads, are never matched.
+var placeholderRegex = regexp.MustCompile(`\{\{[A-Za-z_][A-Za-z0-9_]*\}\}`)
+
+// Placeholders must implement the [scan.Modifier] interface.
+var _ scan.Modifier = Placeholders{}
+
+// Placeholders is a [scan.Modifier] implementation that modifies the request by
+// replacing the built-in placeholders (i.e. {{target_host}}, {{timestamp}} and
+// {{nonce}}) with values resolved per request. Unknown placeholders are left as is
+// (see [UnknownPlaceholders]), as well as {{callback}}, which is replaced by the
+// [InteractionHost] modifier, if any.
+//
+// Unlike the variables (i.e. ${VAR}), expanded once when the templates are built,
+// placeholders are resolved on every request, so payloads can use them as well.
+type Placeholders struct{}
+
+// NewPlaceholders is a constructor function that creates a new instance of
+// the [Placeholders] modifier.
+func NewPlaceholders() Placeholders {
+	return Placeholders{}
+}
+
+// Modify modifies the request by replacing the built-in placeholders.
+func (Placeholders) Modify(_ *profile.Step, tpl scan.Template, req request.Request) request.Request {
+	if !hasPlaceholders(req) {
+		return req
+	}
+
+	var host string
+	if u, err := url.Parse(tpl.URL); err == nil {
+		host = u.Hostname()
+	}
+
+	return replace(req, map[string]string{
+		targetHostPlaceholder: host,
+		timestampPlaceholder:  strconv.FormatInt(time.Now().Unix(), 10),
+		noncePlaceholder:      nonce(),
+	})
+}
+
+// UnknownPlaceholders returns the (unique) placeholders within the given string that
+// aren't resolved by any modifier, in order of appearance. The {{callback}} placeholder
+// is only resolved with a blind host (see [InteractionHost]), so it's reported as
+// unknown unless the given withCallback is true.
+func UnknownPlaceholders(s string, withCallback bool) []string {
+	var unknown []string
+	for _, p := range placeholderRegex.FindAllString(s, -1) {
+		switch {
+		case p == targetHostPlaceholder, p == timestampPlaceholder, p == noncePlaceholder:
+			continue
+		case p == callbackPlaceholder && withCallback:
+			continue
+		}
+
+		if !slices.Contains(unknown, p) {
+			unknown = append(unknown, p)
+		}
+	}
+
+	return unknown
+}
+
+// hasPlaceholders returns whether any of the built-in placeholders
+// resolved by [Placeholders] is present on the given request.
+func hasPlaceholders(req request.Request) bool {
+	contains := func(s string) bool {
+		return strings.Contains(s, targetHostPlaceholder) ||
+			strings.Contains(s, timestampPlaceholder) ||
+			strings.Contains(s, noncePlaceholder)
+	}
+
+	if contains(req.Path) || contains(string(req.Body)) {
+		return true
+	}
+
+	for _, values := range req.Headers {
+		for _, v := range values {
+			if contains(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func nonce() string {
+	const size = 8
+
+	b := make([]byte, size)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package modifier_test
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/modifier"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/blindhost"
+)
+
+func TestPlaceholders_Modify(t *testing.T) {
+	t.Parallel()
+
+	m := modifier.NewPlaceholders()
+	tpl := scan.Template{Request: request.Request{URL: "https://example.org:8443/login"}}
+
+	t.Run("path, headers and body", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := request.ParseRequest([]byte("POST /?host={{target_host}}&n={{nonce}} HTTP/1.1\r\n" +
+			"Host: example.org\r\n" +
+			"X-Timestamp: {{timestamp}}\r\n" +
+			"Content-Length: 21\r\n\r\n" +
+			"nonce={{nonce}}&ssti={{7*7}}"))
+		require.NoError(t, err)
+
+		before := time.Now().Unix()
+		modified := m.Modify(nil, tpl, req)
+
+		match := regexp.MustCompile(`^/\?host=example\.org&n=([0-9a-f]{16})$`).FindStringSubmatch(modified.Path)
+		require.Len(t, match, 2)
+
+		// The same nonce is used along the whole request.
+		assert.Equal(t, "nonce="+match[1]+"&ssti={{7*7}}", string(modified.Body))
+
+		ts, err := strconv.ParseInt(modified.Header("X-Timestamp"), 10, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, ts, before)
+
+		assert.Equal(t, match[1], modified.Modifications["{{nonce}}"])
+	})
+
+	t.Run("unique nonce per request", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Request{Path: "/{{nonce}}"}
+		assert.NotEqual(t, m.Modify(nil, tpl, req).Path, m.Modify(nil, tpl, req).Path)
+	})
+
+	t.Run("unknown placeholders left as is", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Request{Path: "/{{unknown}}/{{callback}}"}
+		modified := m.Modify(nil, tpl, req)
+		assert.Equal(t, req.Path, modified.Path)
+		assert.Empty(t, modified.Modifications)
+	})
+}
+
+func TestInteractionHost_Modify_Callback(t *testing.T) {
+	t.Parallel()
+
+	hid := blindhost.RandomHostIdentifier()
+	m := modifier.NewInteractionHost("bh.example.com", hid)
+
+	modified := m.Modify(nil, scan.Template{}, request.Request{Path: "/?url=http://{{callback}}/"})
+
+	callback := modified.UID + "." + hid.ID() + ".bh.example.com"
+	assert.Equal(t, "/?url=http://"+callback+"/", modified.Path)
+	assert.False(t, strings.Contains(modified.Path, "{{"))
+}
+
+func TestUnknownPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	s := "{{target_host}} {{timestamp}} {{nonce}} {{callback}} {{foo}} {{7*7}} {{ bar }} {{foo}} {{Baz_1}}"
+
+	assert.Equal(t, []string{"{{callback}}", "{{foo}}", "{{Baz_1}}"}, modifier.UnknownPlaceholders(s, false))
+	assert.Equal(t, []string{"{{foo}}", "{{Baz_1}}"}, modifier.UnknownPlaceholders(s, true))
+	assert.Empty(t, modifier.UnknownPlaceholders("{{7*7}} ${VAR} {RANDOM}", false))
+}
@@ -0,0 +1,36 @@
+package modifier
+
+import (
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// RemoveHeaders must implement the [scan.Modifier] interface.
+var _ scan.Modifier = RemoveHeaders{}
+
+// RemoveHeaders is a [scan.Modifier] implementation that modifies the request
+// by removing the headers defined by the step (see [profile.Step.RemoveHeaders]).
+type RemoveHeaders struct{}
+
+// NewRemoveHeaders is a constructor function that creates a new instance of
+// the [RemoveHeaders] modifier.
+func NewRemoveHeaders() RemoveHeaders {
+	return RemoveHeaders{}
+}
+
+// Modify modifies the request by removing the step's headers (case-insensitive),
+// no matter whether these come from the template or were inherited (e.g. from config).
+// Headers not present are ignored.
+func (RemoveHeaders) Modify(step *profile.Step, _ scan.Template, req request.Request) request.Request {
+	cloned := req.Clone()
+	if step == nil {
+		return cloned
+	}
+
+	for _, key := range step.RemoveHeaders {
+		cloned = request.WithoutHeader(key)(cloned)
+	}
+
+	return cloned
+}
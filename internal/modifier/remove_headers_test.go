@@ -0,0 +1,51 @@
+package modifier_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/modifier"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestRemoveHeaders_Modify(t *testing.T) {
+	t.Parallel()
+
+	req := request.WithOptions("http://example.org", request.WithHeader("Authorization", "Bearer token"))
+
+	tcs := map[string]struct {
+		step       *profile.Step
+		expRemoved []string
+	}{
+		"nil step does nothing": {},
+		"no headers to remove does nothing": {
+			step: &profile.Step{},
+		},
+		"headers are removed case-insensitive": {
+			step:       &profile.Step{RemoveHeaders: []string{"authorization", "Accept-Encoding"}},
+			expRemoved: []string{"Authorization", "Accept-Encoding"},
+		},
+		"non-present headers are ignored": {
+			step:       &profile.Step{RemoveHeaders: []string{"Cookie", "Authorization"}},
+			expRemoved: []string{"Authorization"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			modified := modifier.NewRemoveHeaders().Modify(tc.step, scan.Template{}, req)
+			assert.Len(t, modified.Headers, len(req.Headers)-len(tc.expRemoved))
+			assert.Len(t, modified.HeaderOrder, len(req.HeaderOrder)-len(tc.expRemoved))
+			for _, key := range tc.expRemoved {
+				assert.NotContains(t, modified.Headers, key)
+				assert.Contains(t, req.Headers, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// ErrUnknownRequestMutator is the error returned by [RequestMutatorFrom]
+// when the given name doesn't correspond to any of the available mutators.
+var ErrUnknownRequestMutator = errors.New("unknown request mutator")
+
+// RequestMutator is a function that mutates the given request in place (e.g. the header
+// names' casing), commonly used to bypass web application firewalls (WAFs), and returns
+// the details of the mutation applied (e.g. casing: hOsT uSeR-aGeNt), or an empty string
+// if none. So, it can be recorded along with the request (see [request.Request.Mutations]).
+//
+// The headers the payload is injected into (see [entrypoint.Entrypoint]) are given, so
+// those are left untouched. As requests are sent concurrently, it must be safe for concurrent use.
+type RequestMutator func(req *request.Request, injected []string) string
+
+// RequestMutatorNames are the names of the available [RequestMutator], see [RequestMutatorFrom].
+var RequestMutatorNames = []string{"casing", "junk-headers", "charset", "whitespace"}
+
+// RequestMutatorFrom returns the [RequestMutator] with the given name, either "casing"
+// (see [CasingMutator]), "junk-headers" (see [JunkHeadersMutator]), "charset" (see
+// [CharsetMutator]) or "whitespace" (see [WhitespaceMutator]).
+func RequestMutatorFrom(name string) (RequestMutator, error) {
+	switch name {
+	case "casing":
+		return CasingMutator, nil
+	case "junk-headers":
+		return JunkHeadersMutator, nil
+	case "charset":
+		return CharsetMutator, nil
+	case "whitespace":
+		return WhitespaceMutator, nil
+	default:
+		return nil, fmt.Errorf("%w: %s (valid ones are: %s)", ErrUnknownRequestMutator, name, strings.Join(RequestMutatorNames, ", "))
+	}
+}
+
+// CasingMutator is a [RequestMutator] that randomizes the casing of the header names
+// (e.g. hOsT), except for those the payload is injected into, and the framing headers
+// (see [request.IsFramingHeader]), which are left untouched.
+func CasingMutator(req *request.Request, injected []string) string {
+	var mutated []string
+	for _, key := range req.HeaderKeys() {
+		if request.IsFramingHeader(key) || isInjectedHeader(key, injected) {
+			continue
+		}
+
+		cased := randomCase(key)
+		if cased == key {
+			continue
+		}
+
+		if _, exists := req.Headers[cased]; exists {
+			continue
+		}
+
+		values := req.Headers[key]
+		req.Headers[cased] = values
+		delete(req.Headers, key)
+
+		for i := range req.HeaderOrder {
+			if req.HeaderOrder[i] == key {
+				req.HeaderOrder[i] = cased
+			}
+		}
+
+		mutated = append(mutated, cased)
+	}
+
+	if len(mutated) == 0 {
+		return ""
+	}
+
+	return "casing: " + strings.Join(mutated, " ")
+}
+
+// junkHeaders is the amount of headers added by the [JunkHeadersMutator].
+const junkHeaders = 3
+
+// JunkHeadersMutator is a [RequestMutator] that adds a few headers with random
+// names and values (e.g. X-Qwxkfhzt: 9b1d0f7c2a6e3h4k) to the request.
+func JunkHeadersMutator(req *request.Request, _ []string) string {
+	added := make([]string, 0, junkHeaders)
+	for i := 0; i < junkHeaders; i++ {
+		key := "X-" + strings.ToUpper(randomString(1, lowercase)) + randomString(7, lowercase)
+		req.SetHeader(key, randomString(16, lowercase+digits))
+		added = append(added, key+"="+req.Headers[key][0])
+	}
+
+	return "junk-headers: " + strings.Join(added, " ")
+}
+
+// charsetVariants are the (equivalent) ways the UTF-8 charset is
+// declared by the [CharsetMutator], on top of the media type.
+var charsetVariants = []string{
+	"; charset=UTF-8",
+	`;charset="utf-8"`,
+	"; Charset=Utf-8",
+	"; charset=utf-8; charset=utf-8",
+	";  charset=utf-8 ",
+}
+
+// CharsetMutator is a [RequestMutator] that rewrites the charset parameter of the
+// Content-Type header, if any, into any of its equivalent (but unusual) forms, like
+// quoted, with unusual casing, duplicated or surrounded by whitespaces. It does nothing
+// if the payload is injected into the Content-Type header.
+func CharsetMutator(req *request.Request, injected []string) string {
+	// The header name might have been mutated already (see [CasingMutator]).
+	var key string
+	for k := range req.Headers {
+		if strings.EqualFold(k, "Content-Type") {
+			key = k
+		}
+	}
+
+	contentType := strings.Join(req.Headers[key], " ")
+	if len(contentType) == 0 || isInjectedHeader(key, injected) {
+		return ""
+	}
+
+	params := strings.Split(contentType, ";")
+
+	kept := params[:1]
+	for _, p := range params[1:] {
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(p)), "charset=") {
+			kept = append(kept, p)
+		}
+	}
+
+	mutated := strings.Join(kept, ";") + charsetVariants[rand.Intn(len(charsetVariants))] //nolint:gosec
+	req.SetHeader(key, mutated)
+
+	return fmt.Sprintf("charset: %q", mutated)
+}
+
+// whitespaceVariants are the whitespaces appended to the method by the
+// [WhitespaceMutator], so the request line is sent with unusual separators.
+var whitespaceVariants = []string{" ", "\t", "  ", " \t"}
+
+// WhitespaceMutator is a [RequestMutator] that varies the whitespaces of the request line
+// (e.g. GET\t /path HTTP/1.1), by appending unusual whitespaces to the method, as the request
+// line is sent as is. Thus, it requires requests to be sent over HTTP/1.x.
+func WhitespaceMutator(req *request.Request, _ []string) string {
+	req.Method += whitespaceVariants[rand.Intn(len(whitespaceVariants))] //nolint:gosec
+
+	return fmt.Sprintf("whitespace: %q", req.Method+" "+req.Path)
+}
+
+// jitterOptionalHeaders are the benign headers optionally added by the [JitterHeadersMutator],
+// along with the plausible values each of them is sent with, as browsers commonly do.
+var jitterOptionalHeaders = []struct {
+	key    string
+	values []string
+}{
+	{key: "Accept-Language", values: []string{"en-US,en;q=0.9", "en-US,en;q=0.5", "en-GB,en;q=0.9", "en-GB,en-US;q=0.9,en;q=0.8"}},
+	{key: "DNT", values: []string{"1"}},
+	{key: "Upgrade-Insecure-Requests", values: []string{"1"}},
+	{key: "Sec-GPC", values: []string{"1"}},
+}
+
+// jitterReorderedHeaders are the (non-essential) headers reordered by the [JitterHeadersMutator].
+// Any other header (e.g. Host, Cookie, Authorization or Content-Type) is left in place.
+var jitterReorderedHeaders = []string{
+	"User-Agent",
+	"Accept",
+	"Accept-Language",
+	"Accept-Encoding",
+	"Connection",
+	"DNT",
+	"Upgrade-Insecure-Requests",
+	"Sec-GPC",
+}
+
+// JitterHeadersMutator is a [RequestMutator] that varies the benign headers of the request, so
+// the scan cannot be trivially fingerprinted by a fixed header set. It randomly adds any of the
+// optional headers (see [jitterOptionalHeaders]) not present yet, and shuffles the order of the
+// non-essential headers (see [jitterReorderedHeaders]) among their positions. Headers the payload
+// is injected into, as well as any other header (e.g. Host or Authorization), are left untouched.
+//
+// The mutation recorded is the exact order the headers are sent in, along with those added
+// (e.g. jitter-headers: Host Accept DNT User-Agent (added: DNT=1)), so it can be reproduced.
+func JitterHeadersMutator(req *request.Request, injected []string) string {
+	var added []string
+	for _, h := range jitterOptionalHeaders {
+		if hasHeader(req, h.key) || isInjectedHeader(h.key, injected) || rand.Intn(2) == 0 { //nolint:gosec
+			continue
+		}
+
+		value := h.values[rand.Intn(len(h.values))] //nolint:gosec
+		req.SetHeader(h.key, value)
+		added = append(added, h.key+"="+value)
+	}
+
+	keys := req.HeaderKeys()
+
+	var (
+		positions []int
+		reordered []string
+	)
+	for i, key := range keys {
+		if isJitterReorderedHeader(key) && !isInjectedHeader(key, injected) {
+			positions = append(positions, i)
+			reordered = append(reordered, key)
+		}
+	}
+
+	rand.Shuffle(len(reordered), func(i, j int) { //nolint:gosec
+		reordered[i], reordered[j] = reordered[j], reordered[i]
+	})
+
+	for i, pos := range positions {
+		keys[pos] = reordered[i]
+	}
+
+	req.HeaderOrder = keys
+
+	mutation := "jitter-headers: " + strings.Join(keys, " ")
+	if len(added) > 0 {
+		mutation += " (added: " + strings.Join(added, " ") + ")"
+	}
+
+	return mutation
+}
+
+func isJitterReorderedHeader(key string) bool {
+	for _, h := range jitterReorderedHeaders {
+		if strings.EqualFold(key, h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasHeader returns whether the given request has the given header (case-insensitive).
+func hasHeader(req *request.Request, key string) bool {
+	for k := range req.Headers {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithRequestMutators decorates the given [RequesterBuilder], so every request sent through
+// the built [Requester] is mutated by the given [RequestMutator] set, in order, except for the
+// headers the payload is injected into, if any (see [withEntrypoint]).
+//
+// The mutations are applied into the given request, and recorded (see [request.Request.Mutations]),
+// so these are also present on the requests attached to the scan results, e.g. [Match.Requests],
+// and a successful bypass can be reproduced. Redirects followed are sent with the same mutations.
+func WithRequestMutators(fn RequesterBuilder, mutators []RequestMutator) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return mutatorRequester{Requester: requester, mutators: mutators}, nil
+	}
+}
+
+type mutatorRequester struct {
+	Requester
+	mutators []RequestMutator
+}
+
+func (r mutatorRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	// Requests already mutated (e.g. redirects followed) are sent as is.
+	if len(req.Mutations) == 0 {
+		injected := injectedHeaders(ctx)
+		for _, mutate := range r.mutators {
+			if mutation := mutate(req, injected); len(mutation) > 0 {
+				req.Mutations = append(req.Mutations, mutation)
+			}
+		}
+	}
+
+	return r.Requester.Do(ctx, req)
+}
+
+// MetadataMutations is the [Match.Metadata] key that identifies the mutations applied to the
+// requests (see [WithRequestMutators]), only set when the request mutators are enabled.
+const MetadataMutations = "mutations"
+
+// Mutations returns the mutations applied to the given requests (see [request.Request.Mutations]),
+// if any. So, these can be reported along with the match (see [MetadataMutations]).
+func Mutations(reqs []*request.Request) []string {
+	var mutations []string
+	for _, req := range reqs {
+		if req == nil {
+			continue
+		}
+
+		for _, m := range req.Mutations {
+			if !slices.In(mutations, m) {
+				mutations = append(mutations, m)
+			}
+		}
+	}
+
+	return mutations
+}
+
+// entrypointKey is the [context.Context] key for the [entrypoint.Entrypoint] the payload
+// of the request being sent is injected into, if any.
+type entrypointKey struct{}
+
+// withEntrypoint returns a copy of the given [context.Context] with the given [entrypoint.Entrypoint],
+// so the headers the payload is injected into are left untouched by the [RequestMutator] set.
+func withEntrypoint(ctx context.Context, ep entrypoint.Entrypoint) context.Context {
+	if ep == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, entrypointKey{}, ep)
+}
+
+// injectedHeaders returns the names of the headers the payload is injected
+// into, according to the [entrypoint.Entrypoint] from the given [context.Context].
+func injectedHeaders(ctx context.Context) []string {
+	ep, _ := ctx.Value(entrypointKey{}).(entrypoint.Entrypoint)
+	return entrypointHeaders(ep)
+}
+
+func entrypointHeaders(ep entrypoint.Entrypoint) []string {
+	switch ep := ep.(type) {
+	case entrypoint.Header:
+		return []string{ep.HeaderKey}
+	case entrypoint.CustomHeader:
+		return []string{ep.HeaderKey}
+	case entrypoint.JWT:
+		return []string{ep.HeaderKey}
+	case entrypoint.Cookie:
+		return []string{"Cookie"}
+	case entrypoint.Base64:
+		return entrypointHeaders(ep.Outer)
+	default:
+		return nil
+	}
+}
+
+func isInjectedHeader(key string, injected []string) bool {
+	for _, h := range injected {
+		if strings.EqualFold(key, h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	digits    = "0123456789"
+)
+
+// randomCase returns the given string with the casing of each letter randomized.
+func randomCase(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if rand.Intn(2) == 0 { //nolint:gosec
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	return string(runes)
+}
+
+// randomString returns a random string of the given length, from the given alphabet.
+func randomString(n int, alphabet string) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rand.Intn(len(alphabet))] //nolint:gosec
+	}
+
+	return string(b)
+}
@@ -0,0 +1,137 @@
+package scan_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestRequestMutatorFrom(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range scan.RequestMutatorNames {
+		mutator, err := scan.RequestMutatorFrom(name)
+		require.NoError(t, err)
+		assert.NotNil(t, mutator)
+	}
+
+	_, err := scan.RequestMutatorFrom("gzip")
+	require.ErrorIs(t, err, scan.ErrUnknownRequestMutator)
+}
+
+func TestCasingMutator(t *testing.T) {
+	t.Parallel()
+
+	req := request.Default("http://example.org/")
+	req.SetBody([]byte("a=1"))
+
+	// The casing is random, so it's retried until any header is mutated.
+	var mutation string
+	for len(mutation) == 0 {
+		mutation = scan.CasingMutator(&req, []string{"User-Agent"})
+	}
+	assert.True(t, strings.HasPrefix(mutation, "casing: "))
+
+	// Values are kept, and the injected (and framing) headers are left untouched.
+	assert.Len(t, req.Headers, 7)
+	assert.Contains(t, req.Headers, "User-Agent")
+	assert.Contains(t, req.Headers, "Content-Length")
+	assert.Len(t, req.HeaderKeys(), 7)
+
+	for key, values := range req.Headers {
+		if strings.EqualFold(key, "Host") {
+			assert.Equal(t, []string{"example.org"}, values)
+		}
+	}
+}
+
+func TestCharsetMutator(t *testing.T) {
+	t.Parallel()
+
+	req := request.Default("http://example.org/")
+	assert.Empty(t, scan.CharsetMutator(&req, nil))
+
+	req.SetHeader("Content-Type", "application/json; charset=iso-8859-1; boundary=x")
+	mutation := scan.CharsetMutator(&req, nil)
+	require.NotEmpty(t, mutation)
+
+	contentType := req.ContentType()
+	assert.True(t, strings.HasPrefix(contentType, "application/json; boundary=x;"), contentType)
+	assert.NotContains(t, contentType, "iso-8859-1")
+	assert.Equal(t, "charset: \""+strings.ReplaceAll(contentType, `"`, `\"`)+"\"", mutation)
+
+	// Unless the payload is injected into the Content-Type header.
+	injected := request.Default("http://example.org/")
+	injected.SetHeader("Content-Type", "text/plain")
+	assert.Empty(t, scan.CharsetMutator(&injected, []string{"content-type"}))
+	assert.Equal(t, "text/plain", injected.ContentType())
+}
+
+func TestJitterHeadersMutator(t *testing.T) {
+	t.Parallel()
+
+	// The jitter is random, so it's retried until any header is added.
+	var (
+		req      request.Request
+		mutation string
+	)
+	for !strings.Contains(mutation, "(added: ") {
+		req = request.Default("http://example.org/")
+		req.SetHeader("Authorization", "Bearer token")
+		req.SetBody([]byte("a=1"))
+		mutation = scan.JitterHeadersMutator(&req, []string{"Accept", "DNT"})
+	}
+
+	// The mutation records the exact order the headers are sent in.
+	keys := req.HeaderKeys()
+	assert.True(t, strings.HasPrefix(mutation, "jitter-headers: "+strings.Join(keys, " ")+" (added: "), mutation)
+
+	// The essential (and injected) headers are left in place, and the injected ones aren't added.
+	assert.Equal(t, "Host", keys[0])
+	assert.Equal(t, "Accept", keys[2])
+	assert.Equal(t, []string{"Authorization", "Content-Length"}, keys[6:8])
+	assert.Equal(t, []string{"Bearer token"}, req.Headers["Authorization"])
+	assert.NotContains(t, req.Headers, "DNT")
+	assert.Contains(t, req.Headers, "User-Agent")
+}
+
+func TestWithRequestMutators(t *testing.T) {
+	t.Parallel()
+
+	requester := &recordingRequester{}
+	builder := scan.WithRequestMutators(func() (scan.Requester, error) {
+		return requester, nil
+	}, []scan.RequestMutator{scan.JunkHeadersMutator, scan.WhitespaceMutator})
+
+	r, err := builder()
+	require.NoError(t, err)
+
+	req := request.Default("http://example.org/path")
+	_, err = r.Do(context.Background(), &req)
+	require.NoError(t, err)
+
+	// The mutations are applied into the request, and recorded.
+	require.Len(t, req.Mutations, 2)
+	assert.True(t, strings.HasPrefix(req.Mutations[0], "junk-headers: X-"))
+	assert.Len(t, req.Headers, 9)
+	assert.NotEqual(t, "GET", req.Method)
+	assert.Equal(t, "GET", strings.TrimSpace(req.Method))
+	assert.True(t, strings.HasPrefix(req.Mutations[1], `whitespace: "GET`))
+
+	// Requests already mutated (e.g. redirects followed) are sent as is.
+	_, err = r.Do(context.Background(), &req)
+	require.NoError(t, err)
+	assert.Len(t, req.Mutations, 2)
+	assert.Len(t, req.Headers, 9)
+
+	// Those are reported along with the match.
+	other := request.Default("http://example.org/")
+	metadata := scan.MatchMetadata(context.Background(), nil, nil, []*request.Request{&req, nil, &other}, nil, "")
+	assert.Equal(t, strings.Join(req.Mutations, ", "), metadata[scan.MetadataMutations])
+}
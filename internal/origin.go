@@ -0,0 +1,58 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+)
+
+// MatchOrigin identifies where a [Match] comes from: the [Template] whose scan found it, the
+// one that template was generated from (see [ParamsCfg.AlterEach]), the profile step and the
+// insertion point the payload was injected into, if any. Along with the [Match.IssueParam] and [Match.Payload],
+// a finding can be traced back to the input request it came from, e.g. template 7, insertion
+// point Param URL Value, param id, payload ' OR 1=1.
+type MatchOrigin struct {
+	TemplateIdx    int
+	OriginIdx      int
+	InsertionPoint string
+	// Step is the (1-based) number of the active profile step the match was
+	// found at, or zero if none (e.g. those found by passive profiles).
+	Step int
+}
+
+// templateOriginKey is the [context.Context] key for the [MatchOrigin] of the template being scanned.
+type templateOriginKey struct{}
+
+// withTemplateOrigin returns a copy of the given [context.Context] with the
+// [MatchOrigin] of the given [Template], so it can be attached to the matches
+// found while scanning it (see [MatchOriginOf]).
+func withTemplateOrigin(ctx context.Context, tpl Template) context.Context {
+	return context.WithValue(ctx, templateOriginKey{}, MatchOrigin{TemplateIdx: tpl.Idx, OriginIdx: tpl.OriginIdx})
+}
+
+// stepOriginKey is the [context.Context] key for the [MatchOrigin.Step] of the task being run.
+type stepOriginKey struct{}
+
+// withStepOrigin returns a copy of the given [context.Context] with the given (zero-based)
+// step index, so it can be attached to the matches found at it (see [MatchOriginOf]).
+func withStepOrigin(ctx context.Context, stepIdx int) context.Context {
+	return context.WithValue(ctx, stepOriginKey{}, stepIdx+1)
+}
+
+// MatchOriginOf returns the [MatchOrigin] of a match found with the given [entrypoint.Entrypoint],
+// if any, while scanning the template the given [context.Context] belongs to, or nil if it doesn't
+// belong to any (e.g. on rematch).
+func MatchOriginOf(ctx context.Context, ep entrypoint.Entrypoint) *MatchOrigin {
+	origin, ok := ctx.Value(templateOriginKey{}).(MatchOrigin)
+	if !ok {
+		return nil
+	}
+
+	origin.Step, _ = ctx.Value(stepOriginKey{}).(int)
+
+	if ep != nil {
+		origin.InsertionPoint = ep.InsertionPointType().String()
+	}
+
+	return &origin
+}
@@ -0,0 +1,59 @@
+//nolint:testpackage
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestMatchOriginOf(t *testing.T) {
+	t.Parallel()
+
+	req := request.Request{URL: "http://localhost/search.php?id=1", Method: "GET", Path: "/search.php?id=1"}
+	tpl := NewTemplate(context.Background(), 8, req, nil)
+	tpl.OriginIdx = 7
+
+	t.Run("no template", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, MatchOriginOf(context.Background(), nil))
+	})
+
+	t.Run("no entrypoint", func(t *testing.T) {
+		t.Parallel()
+
+		origin := MatchOriginOf(withTemplateOrigin(context.Background(), tpl), nil)
+		assert.Equal(t, &MatchOrigin{TemplateIdx: 8, OriginIdx: 7}, origin)
+	})
+
+	t.Run("entrypoint", func(t *testing.T) {
+		t.Parallel()
+
+		var ep entrypoint.Entrypoint
+		for _, found := range entrypoint.NewQueryFinder().Find(req) {
+			if found.InsertionPointType() == profile.ParamURLValue {
+				ep = found
+			}
+		}
+		require.NotNil(t, ep)
+
+		origin := MatchOriginOf(withTemplateOrigin(context.Background(), tpl), ep)
+		assert.Equal(t, &MatchOrigin{TemplateIdx: 8, OriginIdx: 7, InsertionPoint: "Param URL Value"}, origin)
+	})
+	t.Run("step", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := withStepOrigin(withTemplateOrigin(context.Background(), tpl), 0)
+		assert.Equal(t, &MatchOrigin{TemplateIdx: 8, OriginIdx: 7, Step: 1}, MatchOriginOf(ctx, nil))
+
+		// Those not found by a step (e.g. passive profiles) have none.
+		assert.Zero(t, MatchOriginOf(withTemplateOrigin(context.Background(), tpl), nil).Step)
+	})
+}
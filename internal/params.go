@@ -1,69 +1,298 @@
 package scan
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/kit/logger"
 )
 
 // ParamsCfg defines the configuration for request parameters and is responsible
 // for splitting them into chunked groups.
+//
+// MaxVariants limits the amount of variants (i.e. templates) a single [Template]
+// produces (see [ParamsCfg.Alter]), so zero (or negative) means no limit.
+//
+// Sets are params only injected as certain insertion point types (see [ParamsSet]),
+// split into groups apart from Params, which are injected as any insertion point type.
 type ParamsCfg struct {
-	Params   []string
-	Size     int
-	Method   string
-	Encoding string
+	Params      []string
+	Sets        []ParamsSet
+	Size        int
+	Method      string
+	Encoding    string
+	MaxVariants int
 }
 
 // Alter takes a [Template] as an input, and using the given [ParamsCfg] it constructs
-// a new set of [Template].
+// a new set of [Template]. See [ParamsCfg.AlterEach] for a lazy alternative.
 func (pCfg ParamsCfg) Alter(tpl Template) []Template {
-	groups := pCfg.grouped()
-	if len(groups) == 0 {
-		return []Template{tpl}
+	templates := make([]Template, 0, pCfg.numVariants())
+
+	_ = pCfg.AlterEach(context.Background(), tpl, func(t Template) error {
+		templates = append(templates, t)
+		return nil
+	})
+
+	return templates
+}
+
+// AlterEach is like [ParamsCfg.Alter], but instead of building the whole set of
+// [Template] at once, it builds them one by one, and calls fn with each of them,
+// so there's no need to keep all of them in memory, regardless of the amount of
+// params. The variants are yielded in order, with consecutive indexes starting
+// from the given template's one, which is kept as their origin index (see
+// [Template.OriginIdx]). It stops as soon as fn returns an error, and
+// returns it.
+//
+// If the amount of variants exceeds [ParamsCfg.MaxVariants], the remaining ones
+// are not even built, and a warning is logged.
+func (pCfg ParamsCfg) AlterEach(ctx context.Context, tpl Template, fn func(Template) error) error {
+	ng := pCfg.numGroups()
+	if ng == 0 {
+		return fn(tpl)
 	}
 
-	templates := make([]Template, 0, len(groups))
+	if pCfg.MaxVariants > 0 && ng > pCfg.MaxVariants {
+		logger.For(ctx).Warnf("Template (idx=%d) variants truncated: %d out of %d (--max-template-variants)", tpl.Idx, pCfg.MaxVariants, ng)
+		ng = pCfg.MaxVariants
+	}
 
 	tplIdx := tpl.Idx
-	for _, params := range groups {
-		switch pCfg.Method {
-		case http.MethodGet:
-			templates = append(templates, paramsToURL(tpl, tplIdx, params))
-			tplIdx++
-		case http.MethodPost:
-			switch pCfg.Encoding {
-			case "url", "json":
-				templates = append(templates, paramsToBody(tpl, tplIdx, params, pCfg.Encoding))
-				tplIdx++
+	for i := 0; i < ng; i++ {
+		params := pCfg.group(i)
+
+		var variant Template
+		switch {
+		case pCfg.Method == http.MethodGet:
+			variant = paramsToURL(tpl, tplIdx, params)
+		case pCfg.Method == http.MethodPost && (pCfg.Encoding == "url" || pCfg.Encoding == "json"):
+			variant = paramsToBody(tpl, tplIdx, params, pCfg.Encoding)
+		default:
+			continue
+		}
+
+		tplIdx++
+		if err := fn(variant); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParamsSet is a set of params only injected (see [ParamsCfg.Alter]) as the insertion
+// point types it applies to (e.g. [profile.ParamURLValue]), or as any if none.
+type ParamsSet struct {
+	Params    []string
+	AppliesTo []profile.InsertionPointType
+}
+
+// appliesTo returns whether the [ParamsSet] applies to any of the given insertion point types.
+func (set ParamsSet) appliesTo(ipts []profile.InsertionPointType) bool {
+	if len(set.AppliesTo) == 0 {
+		return true
+	}
+
+	for _, ipt := range ipts {
+		if slices.Contains(set.AppliesTo, ipt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paramsAppliesTo is the directive that, within a params file (see [ParseParams]), scopes
+// the params that follow it to the given (comma-separated) insertion point types.
+const paramsAppliesTo = "#applies-to:"
+
+// ParseParams parses the given params file contents, one param per line, into the params
+// injected as any insertion point type, and the sets of params scoped by the
+// "#applies-to:" directive (see [ParamsSet]), which applies to every line that
+// follows it, until the next one. For instance:
+//
+//	query
+//	#applies-to: ParamURLValue, ParamJSONValue
+//	order
+//	#applies-to: *
+//	limit
+//
+// Insertion point types are parsed with [profile.ParseInsertionPointType], and
+// either an asterisk (*) or "all" scopes the params that follow to any of them.
+func ParseParams(data []byte) ([]string, []ParamsSet, error) {
+	var (
+		params []string
+		sets   []ParamsSet
+		set    *ParamsSet
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) < len(paramsAppliesTo) || !strings.EqualFold(line[:len(paramsAppliesTo)], paramsAppliesTo) {
+			if set != nil {
+				set.Params = append(set.Params, line)
+			} else {
+				params = append(params, line)
 			}
+			continue
+		}
+
+		ipts, err := parseAppliesTo(line[len(paramsAppliesTo):])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if set != nil && len(set.Params) > 0 {
+			sets = append(sets, *set)
+		}
+
+		set = nil
+		if len(ipts) > 0 {
+			set = &ParamsSet{AppliesTo: ipts}
 		}
 	}
 
-	return templates
+	if set != nil && len(set.Params) > 0 {
+		sets = append(sets, *set)
+	}
+
+	return params, sets, scanner.Err()
 }
 
-func (pCfg ParamsCfg) grouped() [][]string {
-	if len(pCfg.Params) == 0 || pCfg.Size == 0 {
+func parseAppliesTo(s string) ([]profile.InsertionPointType, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 || s == "*" || strings.EqualFold(s, "all") {
+		return nil, nil
+	}
+
+	var ipts []profile.InsertionPointType
+	for _, name := range strings.Split(s, ",") {
+		ipt, err := profile.ParseInsertionPointType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		ipts = append(ipts, ipt)
+	}
+
+	return ipts, nil
+}
+
+// insertionPoints returns the insertion point types the params are injected
+// as, given the [ParamsCfg.Method] and the [ParamsCfg.Encoding].
+func (pCfg ParamsCfg) insertionPoints() []profile.InsertionPointType {
+	switch {
+	case pCfg.Method == http.MethodGet:
+		return []profile.InsertionPointType{profile.ParamURLValue, profile.ParamURLName}
+	case pCfg.Method == http.MethodPost && pCfg.Encoding == "url":
+		return []profile.InsertionPointType{profile.ParamBodyValue, profile.ParamBodyName}
+	case pCfg.Method == http.MethodPost && pCfg.Encoding == "json":
+		return []profile.InsertionPointType{profile.ParamJSONValue, profile.ParamJSONName}
+	default:
 		return nil
 	}
+}
+
+// applicable returns the lists of params that are injected, so the [ParamsCfg.Params]
+// along with the [ParamsCfg.Sets] that apply to the insertion point types these are
+// injected as (see [ParamsCfg.insertionPoints]), each split into groups on its own.
+func (pCfg ParamsCfg) applicable() [][]string {
+	lists := make([][]string, 0, 1+len(pCfg.Sets))
+	if len(pCfg.Params) > 0 {
+		lists = append(lists, pCfg.Params)
+	}
+
+	if len(pCfg.Sets) == 0 {
+		return lists
+	}
+
+	ipts := pCfg.insertionPoints()
+	for _, set := range pCfg.Sets {
+		if len(set.Params) > 0 && set.appliesTo(ipts) {
+			lists = append(lists, set.Params)
+		}
+	}
+
+	return lists
+}
+
+// numVariants returns the amount of variants (i.e. templates) a single [Template]
+// produces, bounded by [ParamsCfg.MaxVariants], if any.
+func (pCfg ParamsCfg) numVariants() int {
+	ng := pCfg.numGroups()
+	switch {
+	case ng == 0:
+		return 1
+	case pCfg.MaxVariants > 0 && ng > pCfg.MaxVariants:
+		return pCfg.MaxVariants
+	default:
+		return ng
+	}
+}
+
+// numGroups returns the amount of groups the params are split into.
+func (pCfg ParamsCfg) numGroups() int {
+	if pCfg.Size <= 0 {
+		return 0
+	}
+
+	var ng int
+	for _, params := range pCfg.applicable() {
+		ng += numGroups(len(params), pCfg.Size)
+	}
+
+	return ng
+}
 
-	ng := len(pCfg.Params) / pCfg.Size
-	if len(pCfg.Params)%pCfg.Size > 0 {
+func numGroups(n, size int) int {
+	ng := n / size
+	if n%size > 0 {
 		ng++
 	}
 
-	groups := make([][]string, ng)
+	return ng
+}
+
+// group returns the i-th group of params, with i in the range [0, numGroups).
+func (pCfg ParamsCfg) group(i int) []string {
+	for _, params := range pCfg.applicable() {
+		ng := numGroups(len(params), pCfg.Size)
+		if i >= ng {
+			i -= ng
+			continue
+		}
 
-	for i := 0; i < ng; i++ {
 		start := i * pCfg.Size
 		end := start + pCfg.Size
-		if end > len(pCfg.Params) {
-			end = len(pCfg.Params)
+		if end > len(params) {
+			end = len(params)
 		}
 
-		groups[i] = append(groups[i], pCfg.Params[start:end]...)
+		return append([]string(nil), params[start:end]...)
+	}
+
+	return nil
+}
+
+func (pCfg ParamsCfg) grouped() [][]string {
+	ng := pCfg.numGroups()
+	if ng == 0 {
+		return nil
+	}
+
+	groups := make([][]string, ng)
+	for i := 0; i < ng; i++ {
+		groups[i] = pCfg.group(i)
 	}
 
 	return groups
@@ -104,10 +333,10 @@ func paramsToBody(tpl Template, idx int, params []string, encoding string) Templ
 	switch encoding {
 	case "url":
 		body = paramsAsURL(params)
-		newTpl.Request.Headers["Content-Type"] = []string{"application/x-www-form-urlencoded"}
+		newTpl.Request.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	case "json":
 		body = paramsAsJSON(params)
-		newTpl.Request.Headers["Content-Type"] = []string{"application/json"}
+		newTpl.Request.SetHeader("Content-Type", "application/json")
 	}
 
 	// We explicitly override the existing body
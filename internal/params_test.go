@@ -2,11 +2,15 @@
 package scan
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/bountysecurity/gbounty/internal/profile"
 	"github.com/bountysecurity/gbounty/internal/request"
 )
 
@@ -176,6 +180,9 @@ func Test_ParamsCfg_Expand_POST_url(t *testing.T) {
 						"Content-Type":   {"application/x-www-form-urlencoded"},
 						"Content-Length": {"23"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php",
 					Method: http.MethodPost,
 					Body:   []byte("order=order&query=query"),
@@ -185,6 +192,9 @@ func Test_ParamsCfg_Expand_POST_url(t *testing.T) {
 						"Content-Type":   {"application/x-www-form-urlencoded"},
 						"Content-Length": {"9"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php",
 					Method: http.MethodPost,
 					Body:   []byte("limit=100"),
@@ -206,6 +216,9 @@ func Test_ParamsCfg_Expand_POST_url(t *testing.T) {
 						"Content-Type":   {"application/x-www-form-urlencoded"},
 						"Content-Length": {"23"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php?test=query",
 					Method: http.MethodPost,
 					Body:   []byte("order=order&query=query"),
@@ -215,6 +228,9 @@ func Test_ParamsCfg_Expand_POST_url(t *testing.T) {
 						"Content-Type":   {"application/x-www-form-urlencoded"},
 						"Content-Length": {"9"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php?test=query",
 					Method: http.MethodPost,
 					Body:   []byte("limit=100"),
@@ -330,6 +346,9 @@ func Test_ParamsCfg_Expand_POST_json(t *testing.T) {
 						"Content-Type":   {"application/json"},
 						"Content-Length": {"33"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php",
 					Method: http.MethodPost,
 					Body:   []byte(`{"order":"order","query":"query"}`),
@@ -339,6 +358,9 @@ func Test_ParamsCfg_Expand_POST_json(t *testing.T) {
 						"Content-Type":   {"application/json"},
 						"Content-Length": {"15"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php",
 					Method: http.MethodPost,
 					Body:   []byte(`{"limit":"100"}`),
@@ -360,6 +382,9 @@ func Test_ParamsCfg_Expand_POST_json(t *testing.T) {
 						"Content-Type":   {"application/json"},
 						"Content-Length": {"33"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php?test=query",
 					Method: http.MethodPost,
 					Body:   []byte(`{"order":"order","query":"query"}`),
@@ -369,6 +394,9 @@ func Test_ParamsCfg_Expand_POST_json(t *testing.T) {
 						"Content-Type":   {"application/json"},
 						"Content-Length": {"15"},
 					},
+					HeaderOrder: []string{
+						"Content-Type", "Content-Length",
+					},
 					Path:   "/search.php?test=query",
 					Method: http.MethodPost,
 					Body:   []byte(`{"limit":"100"}`),
@@ -496,3 +524,190 @@ func Test_ParamsCfg_grouped(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParamsCfg_MaxVariants(t *testing.T) {
+	t.Parallel()
+
+	tpl := Template{
+		Idx:         3,
+		OriginalURL: "http://testphp.vulnweb.com/search.php",
+		Request: request.Request{
+			URL:    "http://testphp.vulnweb.com/search.php",
+			Method: http.MethodGet,
+			Path:   "/search.php",
+		},
+	}
+
+	tcs := map[string]struct {
+		maxVariants int
+		out         int
+	}{
+		"no limit":       {maxVariants: 0, out: 5},
+		"below the cap":  {maxVariants: 10, out: 5},
+		"equals the cap": {maxVariants: 5, out: 5},
+		"truncated":      {maxVariants: 2, out: 2},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pCfg := ParamsCfg{
+				Params:      []string{"query", "order", "limit", "offset", "page"},
+				Size:        1,
+				Method:      http.MethodGet,
+				MaxVariants: tc.maxVariants,
+			}
+
+			templates := pCfg.Alter(tpl)
+			assert.Len(t, templates, tc.out)
+			assert.Equal(t, tpl.Idx+tc.out-1, templates[len(templates)-1].Idx)
+		})
+	}
+}
+
+func Test_ParamsCfg_AlterEach(t *testing.T) {
+	t.Parallel()
+
+	pCfg := ParamsCfg{
+		Params: []string{"query", "order", "limit"},
+		Size:   1,
+		Method: http.MethodGet,
+	}
+
+	tpl := Template{
+		OriginalURL: "http://testphp.vulnweb.com/search.php",
+		Request:     request.Request{URL: "http://testphp.vulnweb.com/search.php", Path: "/search.php"},
+	}
+
+	errStop := errors.New("stop")
+
+	var paths []string
+	err := pCfg.AlterEach(context.Background(), tpl, func(t Template) error {
+		paths = append(paths, t.Path)
+		if len(paths) == 2 {
+			return errStop
+		}
+		return nil
+	})
+
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"/search.php?query=query", "/search.php?order=order"}, paths)
+}
+
+func Test_ParamsCfg_Alter_OriginIdx(t *testing.T) {
+	t.Parallel()
+
+	pCfg := ParamsCfg{
+		Params: []string{"query", "order", "limit"},
+		Size:   1,
+		Method: http.MethodGet,
+	}
+
+	tpl := NewTemplate(context.Background(), 7, request.Request{
+		URL:    "http://testphp.vulnweb.com/search.php",
+		Method: http.MethodGet,
+		Path:   "/search.php",
+	}, nil)
+
+	templates := pCfg.Alter(tpl)
+	require.Len(t, templates, 3)
+
+	for i, variant := range templates {
+		assert.Equal(t, 7+i, variant.Idx)
+		assert.Equal(t, 7, variant.OriginIdx)
+	}
+
+	// Those not altered are their own origin.
+	notAltered := ParamsCfg{}.Alter(tpl)
+	require.Len(t, notAltered, 1)
+	assert.Equal(t, 7, notAltered[0].OriginIdx)
+}
+
+func Test_ParseParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no directives", func(t *testing.T) {
+		t.Parallel()
+
+		params, sets, err := ParseParams([]byte("query\norder\nlimit,100\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"query", "order", "limit,100"}, params)
+		assert.Empty(t, sets)
+	})
+
+	t.Run("applies-to", func(t *testing.T) {
+		t.Parallel()
+
+		params, sets, err := ParseParams([]byte(`query
+#applies-to: ParamURLValue, param_json
+order
+#Applies-To: Param Body Value
+limit
+offset
+#applies-to: *
+page
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"query", "page"}, params)
+		assert.Equal(t, []ParamsSet{
+			{Params: []string{"order"}, AppliesTo: []profile.InsertionPointType{profile.ParamURLValue, profile.ParamJSONValue}},
+			{Params: []string{"limit", "offset"}, AppliesTo: []profile.InsertionPointType{profile.ParamBodyValue}},
+		}, sets)
+	})
+
+	t.Run("unknown insertion point type", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := ParseParams([]byte("#applies-to: ParamURLValue, Unknown\nquery\n"))
+		require.ErrorIs(t, err, profile.ErrUnknownInsertionPointType)
+	})
+}
+
+func Test_ParamsCfg_Alter_Sets(t *testing.T) {
+	t.Parallel()
+
+	tpl := Template{
+		OriginalURL: "http://testphp.vulnweb.com/search.php",
+		Request:     request.Request{URL: "http://testphp.vulnweb.com/search.php", Path: "/search.php"},
+	}
+
+	sets := []ParamsSet{
+		{Params: []string{"order"}, AppliesTo: []profile.InsertionPointType{profile.ParamURLValue}},
+		{Params: []string{"limit"}, AppliesTo: []profile.InsertionPointType{profile.ParamJSONValue}},
+	}
+
+	tcs := map[string]struct {
+		method   string
+		encoding string
+		out      []string
+	}{
+		"url":  {method: http.MethodGet, out: []string{"/search.php?query=query", "/search.php?order=order"}},
+		"json": {method: http.MethodPost, encoding: "json", out: []string{`{"query":"query"}`, `{"limit":"limit"}`}},
+		"body": {method: http.MethodPost, encoding: "url", out: []string{"query=query"}},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pCfg := ParamsCfg{Params: []string{"query"}, Sets: sets, Size: 1, Method: tc.method, Encoding: tc.encoding}
+
+			var out []string
+			for _, variant := range pCfg.Alter(tpl) {
+				if tc.method == http.MethodGet {
+					out = append(out, variant.Path)
+				} else {
+					out = append(out, string(variant.Request.Body))
+				}
+			}
+
+			assert.Equal(t, tc.out, out)
+			assert.Equal(t, len(tc.out), pCfg.numVariants())
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+// ErrUnknownPayloadMutation is the error returned by [PayloadMutationFrom]
+// when the given name doesn't correspond to any of the available mutations.
+var ErrUnknownPayloadMutation = errors.New("unknown payload mutation")
+
+// PayloadMutation is a function that returns the variations of the given payload (e.g.
+// with its casing flipped), or none if it doesn't apply. The given [rand.Rand] is the only
+// source of randomness, so the variations are deterministic (see [PayloadMutationsCfg.Seed]).
+type PayloadMutation func(payload string, rnd *rand.Rand) []string
+
+// PayloadMutationNames are the names of the available [PayloadMutation], see [PayloadMutationFrom].
+var PayloadMutationNames = []string{"case", "sql", "encoding", "null-byte"}
+
+// PayloadMutationFrom returns the [PayloadMutation] with the given name, either "case"
+// (see [CaseMutation]), "sql" (see [SQLCommentMutation]), "encoding" (see [EncodingMutation])
+// or "null-byte" (see [NullByteMutation]), along with the insertion point types it applies
+// to by default, or nil if it applies to any of them.
+func PayloadMutationFrom(name string) (PayloadMutation, []profile.InsertionPointType, error) {
+	switch name {
+	case "case":
+		return CaseMutation, nil, nil
+	case "sql":
+		return SQLCommentMutation, nil, nil
+	case "encoding":
+		return EncodingMutation, nil, nil
+	case "null-byte":
+		return NullByteMutation, nullByteInsertionPoints, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s (valid ones are: %s)", ErrUnknownPayloadMutation, name, strings.Join(PayloadMutationNames, ", "))
+	}
+}
+
+// nullByteInsertionPoints are the insertion point types the [NullByteMutation] applies
+// to by default, those commonly used as (or to build) file names and paths.
+var nullByteInsertionPoints = []profile.InsertionPointType{
+	profile.URLPathFile,
+	profile.URLPathFolder,
+	profile.ParamURLValue,
+	profile.ParamBodyValue,
+	profile.ParamJSONValue,
+	profile.ParamMultiAttrValue,
+}
+
+// CaseMutation is a [PayloadMutation] that flips the casing of the payload's letters,
+// both randomly (e.g. SeLeCt) and entirely (e.g. SELECT into select), commonly used
+// to bypass case-sensitive filters.
+func CaseMutation(payload string, rnd *rand.Rand) []string {
+	random := []rune(payload)
+	for i, r := range random {
+		if rnd.Intn(2) == 0 {
+			random[i] = unicode.ToUpper(r)
+		} else {
+			random[i] = unicode.ToLower(r)
+		}
+	}
+
+	swapped := []rune(payload)
+	for i, r := range swapped {
+		if unicode.IsUpper(r) {
+			swapped[i] = unicode.ToLower(r)
+		} else {
+			swapped[i] = unicode.ToUpper(r)
+		}
+	}
+
+	return []string{string(random), string(swapped)}
+}
+
+// sqlKeywords are the SQL keywords wrapped into (MySQL) versioned comments by the [SQLCommentMutation].
+var sqlKeywords = regexp.MustCompile(`(?i)\b(select|union|from|where|and|or|sleep|benchmark|order|by|having)\b`)
+
+// SQLCommentMutation is a [PayloadMutation] that inserts SQL comments into the payload,
+// either as whitespaces (e.g. UNION/**/SELECT), or wrapping its keywords into (MySQL)
+// versioned comments (e.g. /*!50000UNION*/ /*!50000SELECT*/), commonly used to bypass
+// keyword-based filters. Whitespaces can be either spaces or plus signs (+).
+func SQLCommentMutation(payload string, _ *rand.Rand) []string {
+	spaced := strings.NewReplacer(" ", "/**/", "+", "/**/").Replace(payload)
+	versioned := sqlKeywords.ReplaceAllString(payload, "/*!50000$1*/")
+
+	return []string{spaced, versioned}
+}
+
+// EncodingMutation is a [PayloadMutation] that encodes the payload's special characters
+// (i.e. those not alphanumeric), either URL-encoded (e.g. %27), double URL-encoded
+// (e.g. %2527) or as HTML entities (e.g. &#x27;), commonly used to bypass filters
+// that only look for them decoded.
+func EncodingMutation(payload string, _ *rand.Rand) []string {
+	var urlEncoded, htmlEncoded strings.Builder
+	for _, r := range payload {
+		if r < unicode.MaxASCII && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			urlEncoded.WriteString(fmt.Sprintf("%%%02X", r))
+			htmlEncoded.WriteString(fmt.Sprintf("&#x%x;", r))
+			continue
+		}
+
+		urlEncoded.WriteString(url.PathEscape(string(r)))
+		htmlEncoded.WriteRune(r)
+	}
+
+	return []string{
+		urlEncoded.String(),
+		strings.ReplaceAll(urlEncoded.String(), "%", "%25"),
+		htmlEncoded.String(),
+	}
+}
+
+// NullByteMutation is a [PayloadMutation] that injects a (URL-encoded) null byte into the
+// payload, either at its end (e.g. ../../etc/passwd%00) or followed by an allowed extension
+// (e.g. ../../etc/passwd%00.jpg), commonly used to truncate file names on vulnerable parsers.
+func NullByteMutation(payload string, _ *rand.Rand) []string {
+	return []string{payload + "%00", payload + "%00.jpg"}
+}
+
+// PayloadMutationRule is a [PayloadMutation], by name (e.g. sql), along
+// with the insertion point types it applies to, or nil if any of them.
+type PayloadMutationRule struct {
+	Name      string
+	Mutate    PayloadMutation
+	AppliesTo []profile.InsertionPointType
+}
+
+// DefaultMaxPayloadMutations is the default amount of variations generated per payload, see
+// [PayloadMutationsCfg.MaxVariants].
+const DefaultMaxPayloadMutations = 10
+
+// PayloadMutationsCfg defines the mutations (see [PayloadMutationRule]) applied to the payloads
+// of the active profiles, to generate variations of them (e.g. with SQL comments, or encoded),
+// so coverage is expanded without huge static payload lists (see [PayloadMutationsCfg.Apply]).
+//
+// The mutations are applied recursively, up to the given depth (i.e. one means only to the
+// original payloads), and at most the given amount of variations are generated per payload.
+// These are deterministic: the same seed generates the same variations.
+//
+// MaxVariants is a limit of its own, apart from [ParamsCfg.MaxVariants], as mutated payloads
+// are part of the (derived) profiles, not of the templates: each template built with the params
+// is scanned with each of the mutated payloads, so the requests sent multiply both limits.
+type PayloadMutationsCfg struct {
+	Rules       []PayloadMutationRule
+	Depth       int
+	Seed        int64
+	MaxVariants int
+}
+
+// Enabled returns whether any [PayloadMutationRule] is defined.
+func (c PayloadMutationsCfg) Enabled() bool {
+	return len(c.Rules) > 0
+}
+
+// payloadLabels matches the profile labels (e.g. {BH}) and the placeholders (e.g. {{nonce}})
+// within payloads, so those payloads aren't mutated, as the labels would be mutated as well.
+var payloadLabels = regexp.MustCompile(`\{[A-Z]+\}|\{\{[a-z_]+\}\}`)
+
+// Mutate returns the variations of the given payload, generated by the given [PayloadMutationRule]
+// set, in order, up to the [PayloadMutationsCfg.Depth] and the [PayloadMutationsCfg.MaxVariants],
+// with no duplicates, nor the payload itself. Payloads with labels (e.g. {BH}) aren't mutated.
+func (c PayloadMutationsCfg) Mutate(payload string, rules []PayloadMutationRule) []string {
+	if payloadLabels.MatchString(payload) {
+		return nil
+	}
+
+	// Each payload has its own source of randomness, derived from the seed,
+	// so its variations don't depend on the order payloads are mutated in.
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(payload))
+	rnd := rand.New(rand.NewSource(c.Seed ^ int64(h.Sum64()))) //nolint:gosec
+
+	var (
+		variants []string
+		seen     = map[string]struct{}{payload: {}}
+		level    = []string{payload}
+	)
+
+	for depth := 0; depth < c.Depth; depth++ {
+		var next []string
+		for _, p := range level {
+			for _, rule := range rules {
+				for _, v := range rule.Mutate(p, rnd) {
+					if _, ok := seen[v]; ok {
+						continue
+					}
+
+					seen[v] = struct{}{}
+					variants = append(variants, v)
+					next = append(next, v)
+
+					if c.MaxVariants > 0 && len(variants) >= c.MaxVariants {
+						return variants
+					}
+				}
+			}
+		}
+		level = next
+	}
+
+	return variants
+}
+
+// Apply returns the given active profiles, plus the ones derived from them with the payloads
+// of their first step mutated (see [PayloadMutationsCfg.Mutate]), one per profile and set of
+// rules with the same insertion point types (if any), named after the original profile and the
+// rules (e.g. SQLi (mutations: sql, encoding)). Their first step only has the mutated payloads,
+// and only the insertion points the rules apply to, so the findings carry the exact mutated payload.
+//
+// Profiles whose first step has no payloads (e.g. raw requests), or no variations, aren't derived.
+func (c PayloadMutationsCfg) Apply(actives []*profile.Active) []*profile.Active {
+	if !c.Enabled() {
+		return actives
+	}
+
+	groups := c.groups()
+
+	derived := make([]*profile.Active, 0, len(actives)*len(groups))
+	for _, prof := range actives {
+		for _, rules := range groups {
+			if d := c.derive(prof, rules); d != nil {
+				derived = append(derived, d)
+			}
+		}
+	}
+
+	return append(slices.Clip(actives), derived...)
+}
+
+// groups returns the [PayloadMutationRule] set grouped by the insertion point types these
+// apply to, in order of appearance, so each group derives a single profile.
+func (c PayloadMutationsCfg) groups() [][]PayloadMutationRule {
+	var (
+		groups [][]PayloadMutationRule
+		keys   []string
+	)
+
+	for _, rule := range c.Rules {
+		ipts := make([]string, 0, len(rule.AppliesTo))
+		for _, ipt := range rule.AppliesTo {
+			ipts = append(ipts, string(ipt))
+		}
+		slices.Sort(ipts)
+		key := strings.Join(ipts, ",")
+
+		if idx := slices.Index(keys, key); idx >= 0 {
+			groups[idx] = append(groups[idx], rule)
+			continue
+		}
+
+		keys = append(keys, key)
+		groups = append(groups, []PayloadMutationRule{rule})
+	}
+
+	return groups
+}
+
+func (c PayloadMutationsCfg) derive(prof *profile.Active, rules []PayloadMutationRule) *profile.Active {
+	if len(prof.Steps) == 0 || prof.Steps[0].RequestType.RawRequest() || prof.Steps[0].RequestType.HostRequest() {
+		return nil
+	}
+
+	first := prof.Steps[0]
+
+	// The insertion points are restricted to those the rules apply to, if any.
+	if appliesTo := rules[0].AppliesTo; len(appliesTo) > 0 {
+		first.InsertionPoints = slices.DeleteFunc(slices.Clone(first.InsertionPoints), func(ipt profile.InsertionPointType) bool {
+			return !slices.Contains(appliesTo, ipt)
+		})
+		if len(first.InsertionPoints) == 0 {
+			return nil
+		}
+	}
+
+	var payloads []string
+	for idx := range first.Payloads {
+		enabled, payload, err := first.PayloadAt(idx)
+		if err != nil || !enabled {
+			continue
+		}
+
+		for _, v := range c.Mutate(payload, rules) {
+			payloads = append(payloads, "true,"+v)
+		}
+	}
+
+	if len(payloads) == 0 {
+		return nil
+	}
+	first.Payloads = payloads
+
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+
+	steps := slices.Clone(prof.Steps)
+	steps[0] = first
+
+	return &profile.Active{
+		Name:    fmt.Sprintf("%s (mutations: %s)", prof.Name, strings.Join(names, ", ")),
+		Enabled: prof.Enabled,
+		Type:    prof.Type,
+		Author:  prof.Author,
+		Tags:    slices.Clone(prof.Tags),
+		Steps:   steps,
+	}
+}
@@ -0,0 +1,137 @@
+package scan_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+func TestPayloadMutationFrom(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range scan.PayloadMutationNames {
+		mutate, _, err := scan.PayloadMutationFrom(name)
+		require.NoError(t, err)
+		assert.NotNil(t, mutate)
+	}
+
+	_, _, err := scan.PayloadMutationFrom("gzip")
+	require.ErrorIs(t, err, scan.ErrUnknownPayloadMutation)
+}
+
+func TestPayloadMutations(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	assert.Contains(t, scan.CaseMutation("Select", rnd), "sELECT")
+	assert.Equal(t, []string{"'/**/UNION/**/SELECT/**/1--", "' /*!50000UNION*/ /*!50000SELECT*/ 1--"}, scan.SQLCommentMutation("' UNION SELECT 1--", rnd))
+	assert.Equal(t, []string{"%27%3Ca%3E", "%2527%253Ca%253E", "&#x27;&#x3c;a&#x3e;"}, scan.EncodingMutation("'<a>", rnd))
+	assert.Equal(t, []string{"../etc/passwd%00", "../etc/passwd%00.jpg"}, scan.NullByteMutation("../etc/passwd", rnd))
+}
+
+func TestPayloadMutationsCfg_Mutate(t *testing.T) {
+	t.Parallel()
+
+	sql, _, _ := scan.PayloadMutationFrom("sql")
+	encoding, _, _ := scan.PayloadMutationFrom("encoding")
+	casing, _, _ := scan.PayloadMutationFrom("case")
+
+	rules := []scan.PayloadMutationRule{{Name: "sql", Mutate: sql}, {Name: "encoding", Mutate: encoding}}
+
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := scan.PayloadMutationsCfg{Rules: rules, Depth: 1}
+		assert.Len(t, cfg.Mutate("' OR 1", rules), 5)
+
+		// The second level mutates the variants of the first one (e.g. encoded SQL comments).
+		cfg.Depth = 2
+		variants := cfg.Mutate("' OR 1", rules)
+		assert.Greater(t, len(variants), 5)
+		assert.Contains(t, variants, "%27%2F%2A%2A%2FOR%2F%2A%2A%2F1")
+		assert.NotContains(t, variants, "' OR 1")
+	})
+
+	t.Run("max variants", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := scan.PayloadMutationsCfg{Rules: rules, Depth: 3, MaxVariants: 4}
+		assert.Len(t, cfg.Mutate("' OR 1", rules), 4)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		rules := []scan.PayloadMutationRule{{Name: "case", Mutate: casing}}
+
+		cfg := scan.PayloadMutationsCfg{Rules: rules, Depth: 2, Seed: 42}
+		assert.Equal(t, cfg.Mutate("union select", rules), cfg.Mutate("union select", rules))
+
+		cfg.Seed = 43
+		other := cfg.Mutate("union select", rules)
+		cfg.Seed = 42
+		assert.NotEqual(t, cfg.Mutate("union select", rules), other)
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := scan.PayloadMutationsCfg{Rules: rules, Depth: 1}
+		assert.Empty(t, cfg.Mutate("http://{BH}/", rules))
+		assert.Empty(t, cfg.Mutate("' OR '{{nonce}}'", rules))
+	})
+}
+
+func TestPayloadMutationsCfg_Apply(t *testing.T) {
+	t.Parallel()
+
+	sql, _, _ := scan.PayloadMutationFrom("sql")
+	nullByte, nullByteIPTs, _ := scan.PayloadMutationFrom("null-byte")
+
+	cfg := scan.PayloadMutationsCfg{
+		Rules: []scan.PayloadMutationRule{
+			{Name: "sql", Mutate: sql},
+			{Name: "null-byte", Mutate: nullByte, AppliesTo: nullByteIPTs},
+		},
+		Depth:       1,
+		MaxVariants: scan.DefaultMaxPayloadMutations,
+	}
+
+	sqli := &profile.Active{
+		Name:    "SQLi",
+		Enabled: true,
+		Type:    profile.TypeActive,
+		Steps: []profile.Step{{
+			Payloads:        []string{"true,' OR 1=1--", "false,' OR 2=2--"},
+			InsertionPoints: []profile.InsertionPointType{profile.ParamURLValue, profile.HeaderUserAgent},
+			Greps:           []string{"true,,Simple String,,SQL syntax"},
+		}},
+	}
+	raw := &profile.Active{
+		Name:  "Raw",
+		Steps: []profile.Step{{RequestType: profile.RawRequest, RawRequest: "GET / HTTP/1.1\r\n\r\n"}},
+	}
+
+	actives := cfg.Apply([]*profile.Active{sqli, raw})
+	require.Len(t, actives, 4)
+	assert.Same(t, sqli, actives[0])
+	assert.Same(t, raw, actives[1])
+
+	// Disabled payloads aren't mutated, and the original profile is left untouched.
+	assert.Equal(t, "SQLi (mutations: sql)", actives[2].Name)
+	assert.Equal(t, []string{"true,'/**/OR/**/1=1--", "true,' /*!50000OR*/ 1=1--"}, actives[2].Steps[0].Payloads)
+	assert.Equal(t, sqli.Steps[0].InsertionPoints, actives[2].Steps[0].InsertionPoints)
+	assert.Len(t, sqli.Steps[0].Payloads, 2)
+
+	// The insertion points are restricted to those the mutations apply to.
+	assert.Equal(t, "SQLi (mutations: null-byte)", actives[3].Name)
+	assert.Equal(t, []string{"true,' OR 1=1--%00", "true,' OR 1=1--%00.jpg"}, actives[3].Steps[0].Payloads)
+	assert.Equal(t, []profile.InsertionPointType{profile.ParamURLValue}, actives[3].Steps[0].InsertionPoints)
+	assert.Equal(t, []profile.InsertionPointType{profile.ParamURLValue, profile.HeaderUserAgent}, sqli.Steps[0].InsertionPoints)
+}
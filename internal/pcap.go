@@ -0,0 +1,319 @@
+package scan
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrInvalidPCAP is the error returned when the capture file (see [EachTemplateFromPCAP])
+// is neither a pcap nor a pcapng file, or its link type isn't supported.
+var ErrInvalidPCAP = errors.New("invalid pcap file")
+
+// Capture file magic numbers, both for pcap (with microsecond or
+// nanosecond timestamps) and for pcapng (the section header block type).
+const (
+	pcapMagicMicros = 0xa1b2c3d4
+	pcapMagicNanos  = 0xa1b23c4d
+	pcapngMagic     = 0x0a0d0d0a
+	pcapngByteOrder = 0x1a2b3c4d
+)
+
+// pcapng block types.
+const (
+	pcapngInterfaceBlock      = 0x00000001
+	pcapngSimplePacketBlock   = 0x00000003
+	pcapngEnhancedPacketBlock = 0x00000006
+)
+
+// Supported link types, see https://www.tcpdump.org/linktypes.html.
+const (
+	linkTypeNull      = 0
+	linkTypeEthernet  = 1
+	linkTypeRawAlt    = 12
+	linkTypeRaw       = 101
+	linkTypeLinuxSLL  = 113
+	linkTypeIPv4      = 228
+	linkTypeIPv6      = 229
+	linkTypeLinuxSLL2 = 276
+)
+
+// tcpSegment is a TCP segment decoded from a captured packet.
+type tcpSegment struct {
+	src, dst string // ip:port
+	seq      uint32
+	syn      bool
+	payload  []byte
+}
+
+// readPCAP decodes the given pcap or pcapng capture, and calls fn with each of its TCP
+// segments, in capture order. Packets that cannot be decoded (e.g. truncated ones,
+// non-TCP ones or IP fragments) are ignored.
+//
+// Captures are decoded by hand, instead of with gopacket (i.e. pcapgo and layers), as only
+// the few headers needed to reassemble the TCP streams are read (no checksums, options nor
+// any other protocol), which doesn't justify such a dependency. So, every length read from
+// the capture is checked against the data left, see FuzzEachTemplateFromPCAP.
+func readPCAP(data []byte, fn func(tcpSegment)) error {
+	if len(data) < 4 {
+		return fmt.Errorf("%w: too short", ErrInvalidPCAP)
+	}
+
+	switch {
+	case binary.LittleEndian.Uint32(data) == pcapngMagic:
+		return readPCAPNG(data, fn)
+	case binary.LittleEndian.Uint32(data) == pcapMagicMicros, binary.LittleEndian.Uint32(data) == pcapMagicNanos:
+		return readClassicPCAP(data, binary.LittleEndian, fn)
+	case binary.BigEndian.Uint32(data) == pcapMagicMicros, binary.BigEndian.Uint32(data) == pcapMagicNanos:
+		return readClassicPCAP(data, binary.BigEndian, fn)
+	default:
+		return fmt.Errorf("%w: unknown magic number: %#x", ErrInvalidPCAP, data[:4])
+	}
+}
+
+func readClassicPCAP(data []byte, order binary.ByteOrder, fn func(tcpSegment)) error {
+	const (
+		fileHeaderLen   = 24
+		recordHeaderLen = 16
+	)
+
+	if len(data) < fileHeaderLen {
+		return fmt.Errorf("%w: truncated file header", ErrInvalidPCAP)
+	}
+
+	linkType := order.Uint32(data[20:24]) & 0x0fffffff
+	if !supportedLinkType(linkType) {
+		return fmt.Errorf("%w: unsupported link type: %d", ErrInvalidPCAP, linkType)
+	}
+
+	for off := fileHeaderLen; off+recordHeaderLen <= len(data); {
+		capLen := int(order.Uint32(data[off+8 : off+12]))
+		off += recordHeaderLen
+
+		// A truncated last record is (most likely) a capture that was
+		// interrupted, so the rest of the packets are kept anyway.
+		if capLen > len(data)-off {
+			return nil
+		}
+
+		decodeLinkLayer(linkType, data[off:off+capLen], fn)
+		off += capLen
+	}
+
+	return nil
+}
+
+func readPCAPNG(data []byte, fn func(tcpSegment)) error {
+	var (
+		order     binary.ByteOrder
+		linkTypes []uint32
+	)
+
+	for off := 0; off+12 <= len(data); {
+		if binary.LittleEndian.Uint32(data[off:]) == pcapngMagic {
+			// Each section (header block) defines its own byte order and interfaces.
+			switch uint32(pcapngByteOrder) {
+			case binary.LittleEndian.Uint32(data[off+8:]):
+				order = binary.LittleEndian
+			case binary.BigEndian.Uint32(data[off+8:]):
+				order = binary.BigEndian
+			default:
+				return fmt.Errorf("%w: invalid byte-order magic", ErrInvalidPCAP)
+			}
+			linkTypes = nil
+		}
+
+		if order == nil {
+			return fmt.Errorf("%w: missing section header block", ErrInvalidPCAP)
+		}
+
+		blockType := order.Uint32(data[off:])
+		blockLen := int(order.Uint32(data[off+4:]))
+		if blockLen < 12 || blockLen > len(data)-off {
+			// Same as with pcap, a truncated block is ignored.
+			return nil
+		}
+
+		body := data[off+8 : off+blockLen-4]
+		off += blockLen
+
+		switch blockType {
+		case pcapngInterfaceBlock:
+			if len(body) < 2 {
+				return fmt.Errorf("%w: truncated interface description block", ErrInvalidPCAP)
+			}
+			linkType := uint32(order.Uint16(body))
+			if !supportedLinkType(linkType) {
+				return fmt.Errorf("%w: unsupported link type: %d", ErrInvalidPCAP, linkType)
+			}
+			linkTypes = append(linkTypes, linkType)
+
+		case pcapngEnhancedPacketBlock:
+			if len(body) < 20 {
+				continue
+			}
+			iface, capLen := int(order.Uint32(body)), int(order.Uint32(body[12:]))
+			if iface >= len(linkTypes) || capLen > len(body)-20 {
+				continue
+			}
+			decodeLinkLayer(linkTypes[iface], body[20:20+capLen], fn)
+
+		case pcapngSimplePacketBlock:
+			if len(body) < 4 || len(linkTypes) == 0 {
+				continue
+			}
+			capLen := min(int(order.Uint32(body)), len(body)-4)
+			decodeLinkLayer(linkTypes[0], body[4:4+capLen], fn)
+		}
+	}
+
+	return nil
+}
+
+func supportedLinkType(linkType uint32) bool {
+	switch linkType {
+	case linkTypeNull, linkTypeEthernet, linkTypeRawAlt, linkTypeRaw,
+		linkTypeLinuxSLL, linkTypeIPv4, linkTypeIPv6, linkTypeLinuxSLL2:
+		return true
+	default:
+		return false
+	}
+}
+
+// Header lengths and protocol numbers used to decode the captured packets.
+const (
+	etherTypeIPv4   = 0x0800
+	etherTypeIPv6   = 0x86dd
+	etherTypeVLAN   = 0x8100
+	etherTypeQinQ   = 0x88a8
+	etherHeaderLen  = 14
+	vlanTagLen      = 4
+	sllHeaderLen    = 16
+	sll2HeaderLen   = 20
+	nullHeaderLen   = 4
+	ipProtocolTCP   = 6
+	ipv4MinHeadLen  = 20
+	ipv6HeaderLen   = 40
+	tcpMinHeaderLen = 20
+)
+
+// decodeLinkLayer decodes the given packet, with the given link type, down
+// to the TCP layer, and calls fn with the TCP segment, if any.
+func decodeLinkLayer(linkType uint32, b []byte, fn func(tcpSegment)) {
+	switch linkType {
+	case linkTypeEthernet:
+		if len(b) < etherHeaderLen {
+			return
+		}
+		etherType, off := binary.BigEndian.Uint16(b[12:]), etherHeaderLen
+		for (etherType == etherTypeVLAN || etherType == etherTypeQinQ) && len(b) >= off+vlanTagLen {
+			etherType = binary.BigEndian.Uint16(b[off+2:])
+			off += vlanTagLen
+		}
+		if etherType != etherTypeIPv4 && etherType != etherTypeIPv6 {
+			return
+		}
+		b = b[off:]
+	case linkTypeLinuxSLL:
+		if len(b) < sllHeaderLen {
+			return
+		}
+		b = b[sllHeaderLen:]
+	case linkTypeLinuxSLL2:
+		if len(b) < sll2HeaderLen {
+			return
+		}
+		b = b[sll2HeaderLen:]
+	case linkTypeNull:
+		// The address family differs between platforms (e.g. AF_INET6),
+		// so the IP version is taken from the IP header instead.
+		if len(b) < nullHeaderLen {
+			return
+		}
+		b = b[nullHeaderLen:]
+	}
+
+	decodeIP(b, fn)
+}
+
+func decodeIP(b []byte, fn func(tcpSegment)) {
+	if len(b) == 0 {
+		return
+	}
+
+	var (
+		src, dst string
+		payload  []byte
+	)
+
+	switch b[0] >> 4 {
+	case 4:
+		if len(b) < ipv4MinHeadLen {
+			return
+		}
+		headerLen, totalLen := int(b[0]&0x0f)*4, int(binary.BigEndian.Uint16(b[2:]))
+		if headerLen < ipv4MinHeadLen || totalLen < headerLen || totalLen > len(b) || b[9] != ipProtocolTCP {
+			return
+		}
+		// Fragments (either more fragments or a fragment offset) are ignored.
+		if flagsAndOffset := binary.BigEndian.Uint16(b[6:]); flagsAndOffset&0x3fff != 0 {
+			return
+		}
+		src, dst = net.IP(b[12:16]).String(), net.IP(b[16:20]).String()
+		payload = b[headerLen:totalLen]
+
+	case 6:
+		if len(b) < ipv6HeaderLen {
+			return
+		}
+		payloadLen := int(binary.BigEndian.Uint16(b[4:]))
+		if ipv6HeaderLen+payloadLen > len(b) {
+			return
+		}
+		src, dst = "["+net.IP(b[8:24]).String()+"]", "["+net.IP(b[24:40]).String()+"]"
+		next, rest := b[6], b[ipv6HeaderLen:ipv6HeaderLen+payloadLen]
+
+		// Hop-by-hop, routing and destination options extension headers are skipped.
+		for next == 0 || next == 43 || next == 60 {
+			if len(rest) < 8 {
+				return
+			}
+			extLen := (int(rest[1]) + 1) * 8
+			if extLen > len(rest) {
+				return
+			}
+			next, rest = rest[0], rest[extLen:]
+		}
+		if next != ipProtocolTCP {
+			return
+		}
+		payload = rest
+
+	default:
+		return
+	}
+
+	decodeTCP(src, dst, payload, fn)
+}
+
+func decodeTCP(src, dst string, b []byte, fn func(tcpSegment)) {
+	if len(b) < tcpMinHeaderLen {
+		return
+	}
+
+	headerLen := int(b[12]>>4) * 4
+	if headerLen < tcpMinHeaderLen || headerLen > len(b) {
+		return
+	}
+
+	const flagSYN = 0x02
+
+	fn(tcpSegment{
+		src:     fmt.Sprintf("%s:%d", src, binary.BigEndian.Uint16(b[0:])),
+		dst:     fmt.Sprintf("%s:%d", dst, binary.BigEndian.Uint16(b[2:])),
+		seq:     binary.BigEndian.Uint32(b[4:]),
+		syn:     b[13]&flagSYN != 0,
+		payload: b[headerLen:],
+	})
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/ntlm"
+)
+
+const authSchemeNTLM = "ntlm"
+
+var (
+	errUnsupportedAuthScheme     = errors.New("only ntlm is supported (e.g. ntlm:domain\\user:pass)")
+	errNTLMWithSimpleHTTPVersion = errors.New("ntlm cannot be used in combination with HTTP/0.9-style requests (--http-version 0.9), as those have no headers")
+)
+
+// NTLMCredentials returns the NTLM credentials defined by [Config.Auth], in the form of
+// ntlm:domain\user:pass (the domain is optional), or an error if the scheme isn't
+// supported, or the credentials are invalid.
+//
+// If no [Config.Auth] is defined, it returns nil.
+func (cfg Config) NTLMCredentials() (*ntlm.Credentials, error) {
+	if len(cfg.Auth) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	scheme, creds, _ := strings.Cut(cfg.Auth, ":")
+	if !strings.EqualFold(scheme, authSchemeNTLM) {
+		return nil, fmt.Errorf(`%w: "%s"`, errUnsupportedAuthScheme, scheme)
+	}
+
+	parsed, err := ntlm.ParseCredentials(creds)
+	if err != nil {
+		return nil, fmt.Errorf(`%w, expected domain\user:pass`, err)
+	}
+
+	if proto, _ := cfg.RequestLineProto(); proto == "HTTP/0.9" {
+		return nil, errNTLMWithSimpleHTTPVersion
+	}
+
+	return &parsed, nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var (
+	errInvalidCachePoisoningHeader      = errors.New("invalid cache poisoning header")
+	errCachePoisoningHeaderWithoutProbe = errors.New("the cache poisoning headers (--cache-poisoning-header) can only be used in combination with --cache-poisoning")
+)
+
+// CachePoisoningProbe returns the [scan.CachePoisoningCfg] defined by [Config.CachePoisoning] and
+// [Config.CachePoisoningHeaders], or an error if any of the headers is invalid. If no headers
+// are defined, the default ones are poisoned (see [scan.DefaultCachePoisoningHeaders]).
+func (cfg Config) CachePoisoningProbe() (scan.CachePoisoningCfg, error) {
+	switch {
+	case !cfg.CachePoisoning && len(cfg.CachePoisoningHeaders) > 0:
+		return scan.CachePoisoningCfg{}, errCachePoisoningHeaderWithoutProbe
+	case !cfg.CachePoisoning:
+		return scan.CachePoisoningCfg{}, nil
+	}
+
+	if len(cfg.CachePoisoningHeaders) == 0 {
+		return scan.CachePoisoningCfg{Enabled: true, Headers: scan.DefaultCachePoisoningHeaders}, nil
+	}
+
+	headers := make([]string, 0, len(cfg.CachePoisoningHeaders))
+	for _, h := range cfg.CachePoisoningHeaders {
+		header := strings.TrimSpace(h)
+		if len(header) == 0 || strings.ContainsAny(header, " \t:") {
+			return scan.CachePoisoningCfg{}, fmt.Errorf("%w: %q, expected a header name, e.g. X-Forwarded-Host", errInvalidCachePoisoningHeader, h)
+		}
+		headers = append(headers, header)
+	}
+
+	return scan.CachePoisoningCfg{Enabled: true, Headers: headers}, nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/url"
+)
+
+const (
+	defaultCIDRPorts = "80,443"
+	// defaultMaxCIDRURLs is the maximum amount of URLs expanded from the CIDR ranges
+	// (see [Config.MaxCIDRURLs]), unless set, e.g. a /16 on a single port.
+	defaultMaxCIDRURLs = 1 << 16
+)
+
+var errCIDRTooLarge = errors.New("the cidr range(s) (--cidr) expand into too many urls, use --max-cidr-urls to raise the limit")
+
+// CIDRURLs returns the URLs expanded from the CIDR ranges defined by [Config.CIDRs],
+// one per host and port (see [Config.Ports]), e.g. http://10.0.0.1:8080/.
+// Those on port 443 or 8443 use https. For IPv4 ranges, the network and broadcast
+// addresses are skipped, unless the range is /31 or /32.
+//
+// It returns an error if any of the CIDR ranges or ports is invalid, or if the
+// expansion exceeds the maximum amount of URLs allowed (see [Config.MaxCIDRURLs]).
+func (cfg Config) CIDRURLs() ([]string, error) {
+	prefixes, ports, total, err := cfg.cidrRanges()
+	if err != nil || len(prefixes) == 0 {
+		return nil, err
+	}
+
+	urls := make([]string, 0, total)
+	for _, prefix := range prefixes {
+		first, last := prefix.Addr(), cidrLast(prefix)
+		if prefix.Addr().Is4() && prefix.Bits() < 31 {
+			first, last = first.Next(), last.Prev()
+		}
+
+		for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+			for _, port := range ports {
+				scheme := "http"
+				if port == 443 || port == 8443 {
+					scheme = "https"
+				}
+
+				u := scheme + "://" + net.JoinHostPort(addr.String(), strconv.Itoa(port)) + "/"
+				if err := url.Validate(&u); err != nil {
+					return nil, err
+				}
+
+				urls = append(urls, u)
+			}
+		}
+	}
+
+	return urls, nil
+}
+
+// cidrRanges parses the CIDR ranges and ports, and checks the amount of URLs
+// these expand into (returned as total), without expanding them.
+func (cfg Config) cidrRanges() ([]netip.Prefix, []int, uint64, error) {
+	if len(cfg.CIDRs) == 0 {
+		return nil, nil, 0, nil
+	}
+
+	ports, err := cfg.cidrPorts()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(cfg.CIDRs))
+	total := uint64(0)
+	for _, cidr := range cfg.CIDRs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf(`invalid cidr: "%s"`, cidr) //nolint:err113
+		}
+
+		prefix = prefix.Masked()
+		prefixes = append(prefixes, prefix)
+
+		hostBits := prefix.Addr().BitLen() - prefix.Bits()
+		if hostBits >= 32 {
+			return nil, nil, 0, fmt.Errorf(`cidr range too large, it must have less than 32 host bits: "%s"`, cidr) //nolint:err113
+		}
+
+		total += cidrHosts(prefix) * uint64(len(ports))
+	}
+
+	if cfg.MaxCIDRURLs > 0 && total > uint64(cfg.MaxCIDRURLs) {
+		return nil, nil, 0, fmt.Errorf("%w: %d urls (maximum: %d)", errCIDRTooLarge, total, cfg.MaxCIDRURLs)
+	}
+
+	return prefixes, ports, total, nil
+}
+
+func (cfg Config) cidrPorts() ([]int, error) {
+	raw := cfg.Ports
+	if len(strings.TrimSpace(raw)) == 0 {
+		raw = defaultCIDRPorts
+	}
+
+	var ports []int
+	seen := make(map[int]struct{})
+	for _, s := range strings.Split(raw, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf(`invalid port: "%s"`, s) //nolint:err113
+		}
+
+		if _, ok := seen[port]; ok {
+			continue
+		}
+		seen[port] = struct{}{}
+
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+// cidrHosts returns the amount of hosts of the given prefix, which must have less
+// than 32 host bits. Like [Config.CIDRURLs], it skips the IPv4 network and broadcast.
+func cidrHosts(prefix netip.Prefix) uint64 {
+	hosts := uint64(1) << (prefix.Addr().BitLen() - prefix.Bits())
+	if prefix.Addr().Is4() && prefix.Bits() < 31 {
+		hosts -= 2
+	}
+
+	return hosts
+}
+
+// cidrLast returns the last address of the given (masked) prefix.
+func cidrLast(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+
+	addr, _ := netip.AddrFromSlice(b)
+
+	return addr
+}
@@ -0,0 +1,167 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+)
+
+func TestConfig_CIDRURLs(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		cidrs    []string
+		ports    string
+		expected []string
+	}{
+		"network and broadcast skipped": {
+			cidrs:    []string{"10.0.0.0/30"},
+			ports:    "80",
+			expected: []string{"http://10.0.0.1:80/", "http://10.0.0.2:80/"},
+		},
+		"unmasked range": {
+			cidrs:    []string{"10.0.0.6/30"},
+			ports:    "80",
+			expected: []string{"http://10.0.0.5:80/", "http://10.0.0.6:80/"},
+		},
+		"/31": {
+			cidrs:    []string{"10.0.0.0/31"},
+			ports:    "80",
+			expected: []string{"http://10.0.0.0:80/", "http://10.0.0.1:80/"},
+		},
+		"/32": {
+			cidrs:    []string{"10.0.0.7/32"},
+			ports:    "8080",
+			expected: []string{"http://10.0.0.7:8080/"},
+		},
+		"default ports": {
+			cidrs:    []string{"10.0.0.7/32"},
+			expected: []string{"http://10.0.0.7:80/", "https://10.0.0.7:443/"},
+		},
+		"https ports, deduplicated": {
+			cidrs:    []string{"10.0.0.7/32"},
+			ports:    "8443, 443,8443",
+			expected: []string{"https://10.0.0.7:8443/", "https://10.0.0.7:443/"},
+		},
+		"ipv6": {
+			cidrs:    []string{"2001:db8::/127"},
+			ports:    "80",
+			expected: []string{"http://[2001:db8::]:80/", "http://[2001:db8::1]:80/"},
+		},
+		"multiple ranges": {
+			cidrs:    []string{"10.0.0.7/32", "192.168.1.1/32"},
+			ports:    "80",
+			expected: []string{"http://10.0.0.7:80/", "http://192.168.1.1:80/"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{CIDRs: tc.cidrs, Ports: tc.ports, MaxCIDRURLs: defaultMaxCIDRURLs}
+
+			urls, err := cfg.CIDRURLs()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, urls)
+		})
+	}
+}
+
+func TestConfig_CIDRURLs_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		cidrs  []string
+		ports  string
+		maxURL int
+		err    string
+	}{
+		"invalid cidr":        {cidrs: []string{"10.0.0.0"}, err: `invalid cidr: "10.0.0.0"`},
+		"invalid port":        {cidrs: []string{"10.0.0.0/30"}, ports: "80,http", err: `invalid port: "http"`},
+		"port zero":           {cidrs: []string{"10.0.0.0/30"}, ports: "0", err: `invalid port: "0"`},
+		"port out of range":   {cidrs: []string{"10.0.0.0/30"}, ports: "65536", err: `invalid port: "65536"`},
+		"too many host bits":  {cidrs: []string{"2001:db8::/64"}, err: "cidr range too large"},
+		"over the maximum":    {cidrs: []string{"10.0.0.0/16"}, maxURL: defaultMaxCIDRURLs, err: errCIDRTooLarge.Error()},
+		"over a lower limit":  {cidrs: []string{"10.0.0.0/24"}, ports: "80", maxURL: 100, err: "254 urls (maximum: 100)"},
+		"over, summed ranges": {cidrs: []string{"10.0.0.0/25", "10.0.1.0/25"}, ports: "80", maxURL: 250, err: "252 urls (maximum: 250)"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{CIDRs: tc.cidrs, Ports: tc.ports, MaxCIDRURLs: tc.maxURL}
+
+			_, err := cfg.CIDRURLs()
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestConfig_cidrRanges_Limit(t *testing.T) {
+	t.Parallel()
+
+	// A /16 on a single port (65534 urls) fits within the default maximum,
+	// but it doesn't on the two default ports, unless the limit is raised.
+	tcs := map[string]struct {
+		ports  string
+		maxURL int
+		total  uint64
+		err    error
+	}{
+		"single port":     {ports: "80", maxURL: defaultMaxCIDRURLs, total: 65534},
+		"default ports":   {maxURL: defaultMaxCIDRURLs, err: errCIDRTooLarge},
+		"raised limit":    {maxURL: 1 << 17, total: 131068},
+		"no limit (zero)": {total: 131068},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{CIDRs: []string{"10.0.0.0/16"}, Ports: tc.ports, MaxCIDRURLs: tc.maxURL}
+
+			_, _, total, err := cfg.cidrRanges()
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.total, total)
+		})
+	}
+}
+
+func TestConfig_checkValidCIDRs(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorIs(t, Config{Ports: "80"}.checkValidCIDRs(), errPortsWithoutCIDR)
+	require.ErrorIs(t, Config{CIDRs: []string{"10.0.0.0/30"}, MaxCIDRURLs: -1}.checkValidCIDRs(), errNegativeMaxCIDRURLs)
+	require.ErrorIs(t, Config{CIDRs: []string{"10.0.0.0/8"}, MaxCIDRURLs: defaultMaxCIDRURLs}.checkValidCIDRs(), errCIDRTooLarge)
+	require.ErrorContains(t, Config{CIDRs: []string{"10.0.0.0/33"}}.checkValidCIDRs(), "the provided cidr range(s) are invalid")
+	require.NoError(t, Config{CIDRs: []string{"10.0.0.0/30"}, MaxCIDRURLs: defaultMaxCIDRURLs}.checkValidCIDRs())
+}
+
+func TestPrepareTemplates_CIDRTooLarge(t *testing.T) {
+	t.Parallel()
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	// Too large ranges are refused, so no templates are prepared (i.e. the scan doesn't go on).
+	cfg := Config{CIDRs: []string{"10.0.0.0/8"}, MaxCIDRURLs: defaultMaxCIDRURLs}
+	_, err = PrepareTemplates(context.Background(), fs, cfg, nil, nil)
+	require.ErrorIs(t, err, errCIDRTooLarge)
+	assert.Empty(t, storedTemplates(t, fs))
+}
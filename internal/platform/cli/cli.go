@@ -4,7 +4,9 @@ import (
 	"flag"
 	"io"
 	"os"
+	"strings"
 
+	scan "github.com/bountysecurity/gbounty/internal"
 	"github.com/bountysecurity/gbounty/kit/getopt"
 )
 
@@ -12,6 +14,7 @@ const (
 	target     = "target"
 	targetOpts = "target-opts"
 	profile    = "profile"
+	discovery  = "discovery"
 	runtime    = "runtime"
 	output     = "output"
 	debug      = "debug"
@@ -41,18 +44,37 @@ func Parse(args []string) (Config, error) {
 	fs.Alias("u", "url")
 	fs.StringVar(target, &config.UrlsFile, "urls-file", "", "If specified, each line present on the file will be used as the target urls")
 	fs.Alias("uf", "urls-file")
+	fs.BoolVar(target, &config.Stream, "stream", false, "If specified, each line read from the standard input will be used as the target url, scanned as these arrive, until EOF\n\tLines can either be plain urls or JSON objects (ND-JSON), with the url or host field: {\"url\": \"https://example.org\"}\n\tThe standard input is only read as fast as the scan goes, so it can be piped from other tools: subfinder | httpx -json | gbounty --stream\n\tTemplates are scanned in arrival order, and the total amount of requests grows as these arrive")
+	fs.Var(target, &config.CIDRs, "cidr", "If specified, each host within the given CIDR range will be used as a target url, on each of the ports (--ports)\n\tCan be used more than once: --cidr 10.0.0.0/24 --cidr 10.0.1.0/24\n\tLarge ranges are refused, see --max-cidr-urls")
+	fs.StringVar(target, &config.Ports, "ports", "", "Determines the ports (comma-separated) each host within the CIDR range(s) (--cidr) is scanned on (default: "+defaultCIDRPorts+")\n\tPorts 443 and 8443 are scanned over https, the rest over http")
+	fs.IntVar(target, &config.MaxCIDRURLs, "max-cidr-urls", defaultMaxCIDRURLs, "Determines the maximum amount of urls the CIDR range(s) (--cidr) can expand into, all ports included (default: 65536)\n\tLarger ranges are refused, so these aren't scanned by accident. Use zero (0) for no limit")
 	fs.StringVar(target, &config.RequestsFile, "requests-file", "", "If specified, each file present on the requests file will be used as the target url and request template\n\tOnly zipped (.zip) requests files are supported")
 	fs.Alias("rf", "requests-file")
-	fs.Var(target, &config.RawRequests, "raw-request", "If specified, contents on given path will be used as the target url and request template\n\tCan be used more than once: --raw-request path/requests/req1.txt --raw-request path/requests/req2.txt")
+	fs.Var(target, &config.RawRequests, "raw-request", "If specified, contents on given path will be used as the target url and request template\n\tCan be used more than once: --raw-request path/requests/req1.txt --raw-request path/requests/req2.txt\n\tIf the path is a directory, every .req file within it (recursively) is used\n\tEach file can have a sidecar (JSON) .opts file next to it, with the method, headers and paramsFile used for it\n\tFor instance, login.opts: {\"method\": \"PUT\", \"headers\": [\"X-Api-Key: abc\"], \"paramsFile\": \"params.txt\"}")
 	fs.Alias("rr", "raw-request")
-	fs.StringVar(target, &config.ParamsFile, "params-file", "", "If specified, each line present on the file will be used as a request parameter\n\tUsed in combination with --params-split")
+	fs.StringVar(target, &config.CSVFile, "csv", "", "If specified, each row present on the CSV file will be used as the target url and request template\n\tColumns: method, url, body, content-type and headers (as a JSON object or array), in that order\n\tUnless the first row is a header row, which can also define header columns: method,url,X-Api-Key\n\tMalformed rows are skipped, unless --csv-strict is specified")
+	fs.BoolVar(target, &config.CSVStrict, "csv-strict", false, "If specified, the scan fails if any of the rows present on the CSV file (--csv) is malformed")
+	fs.StringVar(target, &config.PCAPFile, "pcap", "", "If specified, each HTTP request present on the capture (pcap or pcapng) file will be used as the target url and request template\n\tTCP streams are reassembled, and requests are sent over http, to the host defined by the Host header\n\tEncrypted (e.g. HTTPS), partial and malformed streams are skipped")
+	fs.StringVar(target, &config.ParamsFile, "params-file", "", "If specified, each line present on the file will be used as a request parameter\n\tUsed in combination with --params-split\n\tParams can be scoped to the insertion point types these are injected as (see --params-method and --params-encoding)\n\tFor instance, with a line like: #applies-to: ParamURLValue,ParamJSONValue (or * to reset), for the lines that follow")
 	fs.Alias("pf", "params-file")
 	fs.IntVar(target, &config.ParamsSplit, "params-split", defaultParamsSplit, "Determines the amount of parameters (-pf/--params-file) included into each group (default: 10)\n\tUse one (1) to scan every param individually")
 	fs.Alias("ps", "params-split")
+	fs.IntVar(target, &config.MaxTemplateVariants, "max-template-variants", 0, "If specified, limits the amount of variants built from each request template with the params (-pf/--params-file)\n\tThe remaining params groups are ignored, and a warning is logged (default: no limit)")
 	fs.StringVar(target, &config.ParamsMethod, "params-method", defaultParamsMethod, "Determines the HTTP method the params (-pf/--params-file) will be included into (default: \"GET\")\n\tSupported methods are: \"GET\" (url) and \"POST\" (www/url-encoded, body)")
 	fs.Alias("pm", "params-method")
 	fs.StringVar(target, &config.ParamsEncoding, "params-encoding", defaultParamsEncode, "Determines the encoding the params (-pf/--params-file) will be included into (default: \"url\")\n\tSupported encodings are: \"url\" (application/x-www-form-urlencoded) and \"json\" (application/json)\n\tOnly used when --params-method/-pm is set to \"POST\"")
 	fs.Alias("pe", "params-encoding")
+	fs.StringVar(target, &config.EnvFile, "env-file", "", "If specified, variables defined on the given dotenv file (KEY=VALUE) are expanded into ${VAR} references\n\tExpansion applies to request templates' URL, headers and body\n\tProcess environment variables take precedence, unless --env-file-priority is specified")
+	fs.Alias("env", "env-file")
+	fs.BoolVar(target, &config.EnvFilePriority, "env-file-priority", false, "If specified, variables defined on the dotenv file (--env-file) take precedence over process environment variables")
+	fs.StringVar(target, &config.LoginSequenceFile, "login-sequence", "", "If specified, the sequence of requests defined on the given (JSON) file is performed before the scan, to authenticate\n\tThe cookies set and the values extracted (e.g. tokens) are sent along with request templates, as cookies and ${NAME}\n\tThe scan is aborted if any step fails (e.g. no token extracted): --login-sequence login.json")
+	fs.StringVar(target, &config.URLMatch, "url-match", "", "If specified, only those request templates whose full URL matches the given regular expression are scanned\n\tApplies to all the inputs, including the URL reconstructed from requests files: --url-match /api/")
+	fs.Alias("um", "url-match")
+	fs.StringVar(target, &config.URLReject, "url-reject", "", "If specified, those request templates whose full URL matches the given regular expression are not scanned\n\tTakes precedence over --url-match: --url-reject \"\\.(css|js|png)$\"")
+	fs.Alias("ur", "url-reject")
+	fs.StringVar(target, &config.ExcludeExtensions, "exclude-extensions", "", "If specified, those request templates whose URL path ends with any of the given extensions (comma-separated) are not scanned\n\tCase-insensitive, applies to all the inputs: --exclude-extensions css,png,woff. Use none to disable the defaults\n\tBy default, static assets (css, images, fonts and media) are excluded during content discovery (--discover)")
+	fs.StringVar(target, &config.SeenHostsFile, "seen-hosts-file", "", "If specified, the hosts scanned are recorded into the given (state) file, one per line, once the scan finishes\n\tThe file is updated atomically, and locked while so, so it can be shared by concurrent runs: --seen-hosts-file seen.txt")
+	fs.BoolVar(target, &config.OnlyNewHosts, "only-new-hosts", false, "If specified, the hosts already recorded into the seen hosts file (--seen-hosts-file) are skipped (i.e. not scanned)\n\tApplies to all the inputs, once filtered, so recurring scans only target the hosts not seen in previous runs")
 
 	// targetOpts
 	fs.InitGroup(targetOpts, "Options for --url (-u) and --urls-file:")
@@ -79,40 +101,155 @@ func Parse(args []string) (Config, error) {
 	fs.Alias("psres", "only-passive-res")
 	fs.BoolVar(profile, &config.PrintTags, "print-tags", false, "Print available profile tags")
 	fs.Alias("tags", "print-tags")
+	fs.DurationVar(profile, &config.ProfileTimeout, "profile-timeout", 0, "If specified, determines the maximum duration of each profile's matchers evaluation against a response\n\tIt is checked between matchers, and once exceeded, no match is reported for that profile: --profile-timeout 10s")
+	fs.StringVar(profile, &config.SensitiveData, "sensitive-data", "", "If specified, responses are analyzed looking for sensitive data, with the given signatures (comma-separated), or all\n\tBuilt-in ones are: aws-access-key, google-api-key, slack-token, private-key, email and credit-card (Luhn-validated)\n\tThe values found are partially redacted within the results: --sensitive-data all")
+	fs.StringVar(profile, &config.SensitiveDataFile, "sensitive-data-file", "", "If specified, custom signatures are read from the given file, one per line with the form name=regex\n\tThose are looked for with --sensitive-data all, or by name, and take precedence over built-in ones with the same name")
+	fs.StringVar(profile, &config.FileSignaturesFile, "file-signatures", "", "If specified, custom file signatures are read from the given file, one per line with the form name=file=regex\n\tThose are looked for by the File Read greps (along with etc-passwd, win-ini, boot-ini, proc-environ and web-xml)\n\tand take precedence over built-in ones with the same name: etc-hosts=/etc/hosts=127\\.0\\.0\\.1\\s+localhost")
+	fs.StringVar(profile, &config.CloudSignaturesFile, "cloud-signatures", "", "If specified, custom cloud signatures are read from the given file, one per line with the form name=provider=field=hosts=regex\n\tThose are looked for by the Cloud Exposure greps (along with aws-credentials, aws-identity, gcp-token, gcp-identity, azure-token,\n\tazure-identity, s3-bucket, gcs-bucket, gcs-objects and azure-container), and take precedence over built-in ones with the same name\n\tIf hosts (comma-separated) are given, the signature is only looked for when the payload, if any, targets any of them (i.e. SSRF)\n\tFor instance: oci-identity=oracle=instance=169.254.169.254=\"canonicalRegionName\"\\s*:")
+	fs.StringVar(profile, &config.DebugSignaturesFile, "debug-signatures", "", "If specified, custom debug signatures are read from the given file, one per line with the form name=paths=regex\n\tThose are looked for by the Debug Exposure greps (e.g. --debug-exposures), and take precedence over built-in ones with the same name\n\tIf paths (comma-separated) are given, the signature is only looked for when the request path ends with any of them\n\tand those are probed with --debug-paths. For instance: hangfire=/hangfire=<title>[^<]*Hangfire Dashboard</title>")
+	fs.BoolVar(profile, &config.Fingerprint, "fingerprint", false, "If specified, responses are analyzed looking for the technology stack (e.g. web server, language or framework)\n\tFrom the Server and X-Powered-By headers, the cookie names (e.g. PHPSESSID) and body markers, reported as informational findings")
+	fs.StringVar(profile, &config.TechnologySignaturesFile, "technology-signatures", "", "If specified, custom technology signatures are read from the given file, one per line with the form name=source=regex\n\tSource is header:<name>, cookie or body, and the first capturing group (if any) is the version: varnish=header:Via=(?i)varnish\n\tThose are looked for by the Technology greps (e.g. --fingerprint) along with built-in ones. Use version=<v> to version the set")
+	fs.BoolVar(profile, &config.WebSocket, "websocket", false, "If specified, requests are sent as WebSocket opening handshakes (GET, with Upgrade: websocket and a random Sec-WebSocket-Key)\n\tSuccessful upgrades (101 Switching Protocols) are reported as informational findings, along with the negotiated subprotocol, if any\n\tCannot be used in combination with --http2, --http2-prior-knowledge or --http-version")
+	fs.StringVar(profile, &config.WebSocketProtocols, "websocket-protocols", "", "If specified, the given subprotocols (comma-separated) are offered on the WebSocket opening handshakes (Sec-WebSocket-Protocol)\n\tThe one negotiated by the server, if any, is reported along with the finding: --websocket-protocols graphql-ws,chat")
+	fs.BoolVar(profile, &config.GraphQLIntrospection, "graphql-introspection", false, "If specified, a GraphQL introspection query (__schema) is sent (POST, as JSON) to every request template's url\n\tEndpoints with introspection enabled are reported, along with the amount of types exposed: -u https://example.org/graphql --graphql-introspection")
+	fs.Var(profile, &config.SeverityOverride, "severity-override", "If specified, the issues found by the given profile are reported with the given severity: High, Medium, Low or Information\n\tCan be used more than once: --severity-override \"Email disclosure=Low\" --severity-override \"Open Redirect=High\"")
+	fs.StringVar(profile, &config.SeverityOverrideFile, "severity-override-file", "", "If specified, severity overrides are read from the given file, one per line with the form profile=severity\n\tThose given with --severity-override take precedence. Unknown profiles (or severities) make the scan fail at startup")
+	fs.BoolVar(profile, &config.StrictPlaceholders, "strict-placeholders", false, "If specified, unknown placeholders (e.g. {{foo}}) within the active profiles (payloads, raw requests and headers) make the scan fail at startup\n\tBy default, those are sent as is. Built-in ones, resolved per request, are {{target_host}}, {{timestamp}}, {{nonce}}\n\tand {{callback}}, a unique interaction host domain (requires --blind-host)")
+	fs.StringVar(profile, &config.Mutations, "mutations", "", "If specified, the payloads of the active profiles are mutated with the given mutations (comma-separated), to generate variations of them\n\tAvailable ones are: case (flipping), sql (comments), encoding (url, double url and html) and null-byte (only on paths and param values, by default)\n\tEach one can be scoped to some insertion point types: --mutations sql,encoding:ParamURLValue|ParamJSONValue\n\tThe variations are sent as additional profiles, named after the mutations, and the mutated payload is recorded within the findings")
+	fs.IntVar(profile, &config.MutationsDepth, "mutations-depth", defaultMutationsDepth, "Determines how many times the mutations (--mutations) are applied recursively, e.g. two to encode the payloads with SQL comments (default: 1)")
+	fs.Int64Var(profile, &config.MutationsSeed, "mutations-seed", defaultMutationsSeed, "Determines the seed of the mutations (--mutations), so the same seed generates the same variations (default: 1)")
+	fs.IntVar(profile, &config.MutationsMaxVariants, "mutations-max-variants", scan.DefaultMaxPayloadMutations, "Determines the maximum amount of variations generated from each payload by the mutations (--mutations) (default: 10)\n\tIt's applied apart from --max-template-variants, which limits the templates (requests) built with the params,\n\twhile this one limits the payloads injected into each of them, so the requests sent multiply both")
+
+	// discovery
+	fs.InitGroup(discovery, "CONTENT DISCOVERY OPTIONS:")
+	fs.BoolVar(discovery, &config.Discover, "discover", false, "If specified, the target url(s) are used as base urls, and each word from -w/--wordlist is requested on them\n\tOnly those responses matching the --match-status and --filter-size, or exposing content (see --exposures), are reported, no profiles are used")
+	fs.StringVar(discovery, &config.Wordlist, "wordlist", "", "Determines the path where the words (one per line) used for content discovery will be read from")
+	fs.Alias("w", "wordlist")
+	fs.StringVar(discovery, &config.Extensions, "extensions", "", "If specified, each word is also requested with each of the given extensions (comma-separated)\n\tFor instance: --extensions php,bak")
+	fs.Alias("ext", "extensions")
+	fs.StringVar(discovery, &config.MatchStatus, "match-status", "", "Determines the status codes (comma-separated) of those responses reported (default: "+defaultDiscoveryStatus+")")
+	fs.Alias("ms", "match-status")
+	fs.StringVar(discovery, &config.FilterSize, "filter-size", "", "If specified, those responses with the given sizes (comma-separated) are not reported, e.g. soft-404 pages\n\tSizes are matched with a margin of 20%, like the Content Length grep")
+	fs.Alias("fsz", "filter-size")
+	fs.StringVar(discovery, &config.Exposures, "exposures", "", "If specified, the given exposures (comma-separated) are reported during content discovery and passive scans (--passive-scan)\n\tEither listing, git, env, backup or swap, or all of them: --exposures all\n\tThose are reported by correlating the request path and the response body (e.g. a backup file's content)")
+	fs.StringVar(discovery, &config.DebugExposures, "debug-exposures", "", "Determines the debug surfaces (comma-separated) reported during content discovery and passive scans (--passive-scan)\n\tEither spring-actuator, spring-env, go-pprof, go-expvar, phpinfo, symfony-profiler, django-debug, werkzeug-debugger,\n\trails-debug or laravel-ignition (default: all), by correlating the request path and the response body, as high-severity issues\n\tUse none to disable them: --debug-exposures none")
+	fs.BoolVar(discovery, &config.ProbeDebugPaths, "debug-paths", false, "If specified, the known debug paths (e.g. actuator or debug/pprof/, plus those from --debug-signatures) are requested\n\ton each base url, before the words from -w/--wordlist, if any (i.e. the wordlist becomes optional)")
 
 	// runtime
 	fs.InitGroup(runtime, "RUNTIME OPTIONS:")
 	const defaultConcurrency = 10
 	fs.IntVar(runtime, &config.Concurrency, "concurrency", defaultConcurrency, "Determines how many target URL(s) will be scanned concurrently (default: 10)")
 	fs.Alias("c", "concurrency")
+	fs.IntVar(runtime, &config.ConcurrencyPerHost, "concurrency-per-host", 0, "Determines how many target URL(s) with the same host will be scanned concurrently (default: no limit)\n\tCombined with -r/--rps, it also bounds the requests per second sent to each host")
+	fs.Alias("cph", "concurrency-per-host")
+	fs.DurationVar(runtime, &config.TimeBudgetPerHost, "time-budget-per-host", 0, "If specified, each host is scanned for up to the given (wall-clock) duration, e.g. 5m, then paused so the remaining hosts get coverage\n\tOnce every host pending is paused, the leftover time is shared among them in rounds (of the same duration)\n\tUseful in combination with --scan-timeout, so hosts with many templates don't starve the rest")
+	fs.BoolVar(runtime, &config.Warmup, "warmup", false, "If specified, a throwaway request (HEAD /) is sent to each host before scanning it, so its DNS resolution is primed\n\tAddresses resolved are cached (see --dns-cache-ttl), for 10m if not specified, as connections aren't reused\n\tIts response isn't analyzed (i.e. no findings), and its timing is excluded, so timing-based detections are more accurate\n\tWarmup requests are reported apart from the scan requests, in the summary")
 	const defaultRps = 10
 	fs.IntVar(runtime, &config.Rps, "rps", defaultRps, "Determines the limit of requests per second (per URL) (default: 10)")
 	fs.Alias("r", "rps")
+	fs.BoolVar(runtime, &config.AdaptiveThrottle, "adaptive-throttle", false, "If specified, the requests per second sent to each host are adjusted dynamically, within the -r/--rps limit\n\tIt backs off on errors and 429 (or 503) responses, and when the latency exceeds the target, and ramps up when healthy")
+	fs.DurationVar(runtime, &config.AdaptiveTargetLatency, "adaptive-target-latency", defaultAdaptiveTargetLatency, "Determines the response latency the adaptive throttle (--adaptive-throttle) aims for (default: 1s)")
+	fs.IntVar(runtime, &config.AdaptiveMinRps, "adaptive-min-rps", defaultAdaptiveMinRps, "Determines the minimum requests per second per host of the adaptive throttle (--adaptive-throttle) (default: 1)")
+	fs.IntVar(runtime, &config.AdaptiveMaxRps, "adaptive-max-rps", 0, "Determines the maximum requests per second per host of the adaptive throttle (--adaptive-throttle) (default: -r/--rps)")
+	fs.StringVar(runtime, &config.RetryOnStatus, "retry-on-status", "", "If specified, the requests whose responses have any of the given (comma-separated) status codes are retried, e.g. 429,503\n\tEach retry waits for the Retry-After header, if present, or backs off exponentially (from 1s) otherwise\n\tThe final response (after retries) is the one analyzed")
+	fs.IntVar(runtime, &config.MaxRetries, "max-retries", defaultMaxRetries, "Determines the maximum amount of retries per request, when retrying on status (--retry-on-status) (default: 2)")
 	fs.BoolVar(runtime, &config.Silent, "silent", false, "If specified, no results will be printed to stdout")
 	fs.Alias("s", "silent")
 	fs.BoolVar(runtime, &config.SaveOnStop, "save-on-stop", false, "Saves the scan's status when stopped")
 	fs.Alias("sos", "save-on-stop")
 	fs.StringVar(runtime, &config.Continue, "from", "", "Scan's identifier to be used to continue")
 	fs.Alias("f", "from")
+	fs.StringVar(runtime, &config.Storage, "storage", "", "Determines the path to the storage of a previous scan, instead of its identifier (-f/--from)\n\tUsed to replay findings (--replay) or to rematch stored responses: gbounty rematch --storage <path>")
 	fs.BoolVar(runtime, &config.InMemory, "in-memory", false, "Use memory (only) as scan storage")
 	fs.Alias("m", "in-memory")
+	fs.BoolVar(runtime, &config.KeepStorage, "keep-storage", false, "If specified, the scan's storage is kept once finished, so it can be used later (e.g. with --replay)")
+	fs.Alias("ks", "keep-storage")
+	fs.BoolVar(runtime, &config.StoreAllResponses, "store-all-responses", false, "If specified, every request sent, along with the response got, is stored into the scan's storage, regardless of matches\n\tThe storage is kept once finished, so profiles can be run against it later, with no requests sent: gbounty rematch -f <scan-id>")
+	fs.IntVar(runtime, &config.StoreMaxBodySize, "store-max-body", defaultStoreMaxBodySize, "Determines the maximum size (in bytes) of the response bodies stored (--store-all-responses), larger ones are truncated (default: 1048576)\n\tUse 0 for no limit")
+	fs.Var(runtime, &config.PriorityHosts, "priority-host", "If specified, templates targeting the given host are scanned first, with the given weight (default: 1)\n\tSubdomains can be matched with a wildcard: *.example.org\n\tCan be used more than once: --priority-host api.example.org=10 --priority-host *.example.org=5")
+	fs.Var(runtime, &config.PriorityPathRegexes, "priority-path-regex", "If specified, templates whose path matches the given regular expression are scanned first, with the given weight (default: 1)\n\tCan be used more than once: --priority-path-regex ^/admin=10 --priority-path-regex /api/=5")
+	fs.DurationVar(runtime, &config.ScanTimeout, "scan-timeout", 0, "If specified, the scan is stopped once the given duration is reached (e.g. 30m, 2h)\n\tUsed in combination with priorities, to make sure the most important targets are scanned first")
+	fs.StringVar(runtime, &config.MaxErrors, "max-errors", "", "If specified, the scan is aborted (exit code 5) once the given amount of requests failed, reporting the dominant error\n\tOr the given percentage of the latest --max-errors-window requests: --max-errors 20%. Only transport-level failures\n\t(e.g. connection refused, timeouts or TLS errors) are errors, not responses with any status code (e.g. 403 or 500)\n\tUsed to fail fast on misconfigurations (e.g. a wrong proxy). Cannot be used in combination with -sos/--save-on-stop")
+	fs.IntVar(runtime, &config.MaxErrorsWindow, "max-errors-window", 0, "Determines the amount of latest requests the percentage of failed requests (--max-errors) is calculated over (default: 100)")
+	fs.StringVar(runtime, &config.Shard, "shard", "", "If specified, only the given portion (i/n) of the templates is scanned, with i in the range [0, n)\n\tUsed to split the same scan across multiple runners: --shard 0/3, --shard 1/3 and --shard 2/3\n\tThe same shard must be specified to continue (-f/--from) a scan")
+	fs.BoolVar(runtime, &config.NoEntrypoints, "no-entrypoints", false, "If specified, request templates are sent as is, with no entrypoints nor payload injection\n\tOnly passive profiles are analyzed, and params (-pf/--params-file) are ignored")
+	fs.Alias("noep", "no-entrypoints")
+	fs.BoolVar(runtime, &config.Passive, "passive-scan", false, "If specified, the scan is passive: request templates are sent once, as is, with no entrypoints nor params expansion\n\tResponses are analyzed with a curated set of passive profiles (security headers, cookies, error messages...)\n\tplus the enabled passive profiles loaded (-p/--profiles), if any")
+	fs.Var(runtime, &config.FilterMime, "filter-mime", "If specified, the body of those responses with the given media types is not analyzed, only their headers\n\tPrefix with allow: to only analyze the body of those responses with the given media types\n\tCan be used more than once: --filter-mime image/*,font/* --filter-mime allow:text/*,application/json")
+	fs.StringVar(runtime, &config.OnlyStatus, "only-status", "", "If specified, only the responses with the given status codes or classes (comma-separated) are evaluated by the matchers\n\tThe rest are neither analyzed nor recorded, and counted as filtered: --only-status 4xx,5xx,302")
+	fs.BoolVar(runtime, &config.OnlyErrors, "only-errors", false, "If specified, only the error responses (4xx and 5xx) are evaluated by the matchers, like --only-status 4xx,5xx")
+	fs.BoolVar(runtime, &config.SuppressSuccess, "suppress-success", false, "If specified, the successful responses (2xx) are neither evaluated by the matchers nor recorded")
+	fs.BoolVar(runtime, &config.OnlyDiff, "only-diff", false, "If specified, only the responses that differ from the per-URL baseline (the first response received for the URL) are evaluated\n\tResponses differ when the status code is different, or the length differs by more than 10%")
+	fs.BoolVar(runtime, &config.DiffResponses, "diff-responses", false, "If specified, two variants (--diff-a and --diff-b) of each request template are sent, as is, and their responses compared\n\tThose whose responses differ are reported, along with the diff: status, headers removed and added, and body (line-based, or summarized if binary)")
+	fs.Var(runtime, &config.DiffA, "diff-a", "Determines the first variant of the requests sent to diff the responses (--diff-responses), by headers and up to one payload\n\tThe payload replaces the {{variant}} placeholder of the request templates, e.g. -u 'https://example.org/?id=1{{variant}}'\n\tCan be used more than once: --diff-a 'header:X-Role: admin' --diff-a \"payload:' AND '1'='1\"")
+	fs.Var(runtime, &config.DiffB, "diff-b", "Determines the second variant of the requests sent to diff the responses (--diff-responses), like --diff-a\n\tEither of the variants can be omitted, so the requests are sent as is (with no payload)")
+	fs.IntVar(runtime, &config.RateLimitBurst, "rate-limit-burst", 0, "If specified, a burst of the given amount of requests is sent to the request templates that ask for it (see --rate-limit-match), as fast as possible, to detect rate limiting\n\tA finding reports whether responses degrade to 429 (or 403) and after how many requests (threshold), or stay unthrottled\n\tRequests are sent as is, regardless of -r/--rps, so use it with caution, e.g. on authentication endpoints: --rate-limit-burst 50")
+	fs.StringVar(runtime, &config.RateLimitMatch, "rate-limit-match", "", "If specified, the burst (--rate-limit-burst) is only sent to those request templates whose URL matches the given regular expression\n\tBy default, it is only sent (to all of them) if any active profile is tagged rate-limit: --rate-limit-match '/(login|signin)'")
+	fs.BoolVar(runtime, &config.MassAssignment, "mass-assignment", false, "If specified, extra parameters are injected into the body (form or JSON object) or the query of each request template, to detect mass assignment\n\tEvery existing parameter is also duplicated, to detect HTTP parameter pollution (HPP)\n\tThose accepted (reflected, status change, or body diff vs the baseline, the request as is) are reported, along with the evidence")
+	fs.Var(runtime, &config.MassAssignmentParams, "mass-assignment-param", "Determines the parameters (name=value) injected to detect mass assignment (--mass-assignment)\n\tBy default: isAdmin=true, admin=true, role=admin and verified=true. Can be used more than once: --mass-assignment-param isAdmin=true --mass-assignment-param role=owner")
+	fs.BoolVar(runtime, &config.CachePoisoning, "cache-poisoning", false, "If specified, each request template is sent with a poisoned (unkeyed) header, followed by a clean request, to detect web cache poisoning\n\tBoth share a unique cache buster (query param), so the actual cached responses aren't poisoned\n\tThose poisoned values reflected in the clean responses (i.e. cached) are reported, along with the cache headers (e.g. Age or X-Cache)")
+	fs.Var(runtime, &config.CachePoisoningHeaders, "cache-poisoning-header", "Determines the headers poisoned to detect web cache poisoning (--cache-poisoning)\n\tBy default: X-Forwarded-Host, X-Host, X-Forwarded-Server and X-Original-Host. Can be used more than once: --cache-poisoning-header X-Forwarded-Host --cache-poisoning-header X-Forwarded-Scheme")
+	fs.StringVar(runtime, &config.Replay, "replay", "", "Finding's identifier to be re-sent and compared against the stored response\n\tMust be used in combination with -f/--from <scan-id>")
+	fs.BoolVar(runtime, &config.Count, "count", false, "If specified, the amount of requests the scan would send is printed (by host and profile), with no requests sent\n\tIt accounts for params (-pf/--params-file) expansion and the entrypoints (per method) enabled by each profile")
+	fs.StringVar(runtime, &config.SkipIf, "skip-if", "", "If specified, those templates the given expression holds for are skipped (i.e. not scanned)\n\tVariables (--env-file), values extracted (--login-sequence) and the template's request.method,\n\trequest.url, request.host and request.path can be referenced: --skip-if '{{logged_in}} == false && {{request.path}} =~ ^/admin'\n\tOperators: ==, !=, <, <=, >, >=, =~ (regex), !~, &&, ||, ! and parentheses")
+	fs.BoolVar(runtime, &config.SkipIfUndefined, "skip-if-undefined", false, "If specified, templates are skipped when the skip predicate (--skip-if) references undefined variables\n\tOtherwise, those templates are scanned")
+	fs.StringVar(runtime, &config.TemplateFilter, "template-filter", "", "If specified, only those templates the given expression holds for are scanned, the rest are skipped during dispatch\n\tThe template's request.method, request.url, request.host, request.path, request.proto, request.headers,\n\trequest.header.<name> (lowercase), request.body, template.priority and template.passive can be referenced:\n\t--template-filter \"{{request.method}} == POST && {{request.host}} matches '.*api.*'\"\n\tTemplates that lack any of the headers referenced are skipped, and all the skipped ones are counted as filtered")
+	fs.StringVar(runtime, &config.SaveTemplateBundle, "save-template-bundle", "", "If specified, the prepared scan templates are saved into the given zipped (.zip) file, before the scan starts\n\tThe bundle can be used later as a requests file: -rf/--requests-file out.zip (with params already expanded)")
+	fs.BoolVar(runtime, &config.PrepareOnly, "prepare-only", false, "If specified, the scan templates are only prepared and saved (--save-template-bundle), with no requests sent")
 	fs.StringVar(runtime, &config.BlindHost, "interaction-host", "", "(Deprecated) If specified, the interaction host is injected into {IH}, {BH} and {BC} labels")
 	fs.Alias("ih", "interaction-host")
 	fs.StringVar(runtime, &config.BlindHost, "blind-host", "", "If specified, the interaction host is injected into {IH}, {BH} and {BC} labels")
 	fs.Alias("bh", "blind-host")
 	fs.StringVar(runtime, &config.EmailAddress, "email-address", "", "If specified, the email address is injected into {EMAIL} labels")
 	fs.Alias("email", "email-address")
+	fs.StringVar(runtime, &config.JWTSignKey, "jwt-sign-key", "", "If specified, JWT bearer tokens (with HMAC-based algorithms) are re-signed with the given key after injection")
 	fs.StringVar(runtime, &config.ProxyAddress, "proxy-address", "", "If specified, requests are proxied to the given address\n\tTo specify host and port use host:port")
 	fs.StringVar(runtime, &config.ProxyAuth, "proxy-auth", "", "If specified, proxied requests will include authentication details")
+	fs.StringVar(runtime, &config.ReplayProxy, "replay-proxy", "", "If specified, the requests that produced findings are replayed through the given proxy, e.g. http://127.0.0.1:8080\n\tReplayed requests are tagged with the X-Gbounty-Match header (finding id and issue name), the scan traffic isn't proxied")
+	fs.StringVar(runtime, &config.UnixSocket, "unix-socket", "", "If specified, requests are sent through the given Unix domain socket, instead of connecting to the target host\n\tThe target URL is still used for the Host header, the path and the TLS server name (https)\n\tCannot be used in combination with --proxy-address")
+	fs.DurationVar(runtime, &config.DNSCacheTTL, "dns-cache-ttl", 0, "If specified, the addresses resolved for each host are cached (in-process) for the given duration, e.g. 5m\n\tBy default (or zero), hosts are resolved on every connection. Cannot be used in combination with --proxy-address or --unix-socket")
+	fs.BoolVar(runtime, &config.DNSPin, "dns-pin", false, "If specified, the first address resolved for each host is used for the whole scan, regardless of --dns-cache-ttl\n\tUseful to avoid flapping between hosts behind round-robin DNS")
+	fs.StringVar(runtime, &config.ResolveAllTo, "resolve-all-to", "", "If specified, every host is resolved to the given address (host:port), e.g. a local mock, so no traffic reaches the actual hosts (sinkhole mode)\n\tRequests are still sent with the original Host header (and TLS server name), so virtual-host routing works on the mock: --resolve-all-to 127.0.0.1:8080\n\tCannot be used in combination with --proxy-address, --unix-socket or --dns-cache-ttl/--dns-pin")
+	fs.Var(runtime, &config.CABundle, "ca-bundle", "If specified, the targets' TLS certificates are verified against the certificate authorities from the given PEM bundle, along with the system ones\n\tBy default, certificates aren't verified. Can be used more than once: --ca-bundle corp-ca.pem --ca-bundle partner-ca.pem")
+	fs.StringVar(runtime, &config.CABundleDir, "ca-bundle-dir", "", "If specified, the PEM bundles (.pem, .crt and .cer files) within the given directory are loaded like those from --ca-bundle\n\tBoth can be used in combination, and all the bundles are merged")
+	fs.StringVar(runtime, &config.Auth, "auth", "", "If specified, requests are authenticated against those hosts that require it: ntlm:domain\\user:pass\n\tNTLM authenticates connections, so requests are sent with Connection: keep-alive, and the authenticated connections are reused\n\tKeep-alive must stay enabled for NTLM: the Connection header is overridden, and HTTP/0.9-style requests (--http-version 0.9) are not allowed")
+	fs.StringVar(runtime, &config.AwsSigV4, "aws-sigv4", "", "If specified, every request is signed with AWS Signature Version 4 for the given region and service: us-east-1:execute-api\n\tRequests are signed right before being sent (i.e. once the payload is injected), so the signature covers the final request\n\tCredentials are read from --aws-credentials or, otherwise, from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN")
+	fs.StringVar(runtime, &config.AwsCredentials, "aws-credentials", "", "If specified, the AWS credentials requests are signed with (--aws-sigv4): access_key_id:secret_access_key[:session_token]\n\tOtherwise, those are read from the environment")
+	fs.BoolVar(runtime, &config.AllowRawHeaders, "allow-raw-headers", false, "If specified, ambiguous Content-Length and Transfer-Encoding headers from raw requests are sent verbatim\n\tUseful to test HTTP request smuggling, use with caution")
+	fs.BoolVar(runtime, &config.PreserveLineEndings, "preserve-line-endings", false, "If specified, raw requests are sent with their original line endings (e.g. bare LFs), for exact-byte replays\n\tBy default, the request line and headers are sent with CRLF, while bodies are always sent as is")
+	fs.StringVar(runtime, &config.HTTPVersion, "http-version", "", "If specified, requests are sent with the given protocol version in the request line: 1.0 or 1.1\n\tHTTP/0.9-style requests (0.9) and custom (or malformed) versions require --allow-raw-headers\n\tResponses to those are parsed leniently, useful for server fingerprinting")
+	fs.BoolVar(runtime, &config.HTTP2, "http2", false, "If specified, requests are sent over HTTP/2, if negotiated (https), falling back to HTTP/1.1 otherwise\n\tCleartext (http) requests are sent over HTTP/1.1, unless --http2-prior-knowledge is specified")
+	fs.BoolVar(runtime, &config.HTTP2PriorKnowledge, "http2-prior-knowledge", false, "If specified, requests are sent over HTTP/2 with no fallback, including cleartext (h2c) ones\n\tCannot be used in combination with --http-version, --allow-raw-headers or --auth")
+	fs.IntVar(runtime, &config.MaxDecompressedSize, "max-decompressed-size", defaultMaxDecompressedSize, "Determines the maximum size (in bytes) of the response bodies once decompressed (e.g. gzip), larger ones are truncated (default: 10485760)\n\tProtects the scan from decompression bombs sent by untrusted targets. The truncation is noted within the findings\n\tUse 0 for no limit")
+	fs.StringVar(runtime, &config.HeaderOrder, "header-order", "", "If specified, request headers are sent in the given order (comma-separated), case-insensitive\n\tHeaders not listed are sent afterward, in the order those were added: --header-order Host,User-Agent,Accept")
+	fs.Var(runtime, &config.RemoveHeaders, "remove-header", "If specified, the given headers (comma-separated) are removed from request templates, case-insensitive\n\tApplied once inherited (e.g. from --header, raw requests or --login-sequence), so these can be stripped: --remove-header Authorization\n\tCan be used more than once. Profiles can also remove headers per step (remove_headers)")
+	fs.StringVar(runtime, &config.RequestIDHeader, "request-id-header", "", "If specified, every request sent carries the given header, with a unique value per request (e.g. X-Req-Id)\n\tThe value is attached to the finding(s), so requests can be correlated with the server logs")
+	fs.StringVar(runtime, &config.RequestIDGenerator, "request-id-generator", "sequence", "Determines how the values of the --request-id-header are generated: sequence, uuid or timestamp (default: sequence)")
+	fs.StringVar(runtime, &config.CORSOrigin, "cors-origin", "", "If specified, every request sent carries the Origin header, set to the given (attacker-controlled) origin, unless already present: --cors-origin https://evil.example\n\tSo, the CORS Misconfiguration greps with no origins defined report it reflected, along with credentials allowed")
+	fs.StringVar(runtime, &config.CanaryPrefix, "canary-prefix", "", "If specified, every payload injected is prepended with a unique canary, made of the given (alphanumeric) prefix and a random suffix: --canary-prefix gb\n\tOnly the payload reflections along with the canary are matched, so each reflection maps back to the request (and entrypoint) it was injected into\n\tThe canary is attached to the finding(s). Payloads relying on their exact bytes (e.g. path traversal) may not work with it")
+	fs.StringVar(runtime, &config.RequestMutators, "request-mutators", "", "If specified, every request sent is mutated with the given mutators (comma-separated), in order, e.g. to bypass WAFs\n\tAvailable ones are: casing, junk-headers, charset and whitespace (in the request line). Headers targeted by the payload are left untouched\n\tThe mutations applied are recorded within the findings, so these can be reproduced: --request-mutators casing,junk-headers,charset")
+	fs.BoolVar(runtime, &config.JitterHeaders, "jitter-headers", false, "If specified, the benign headers of every request sent are varied, so the scan cannot be trivially fingerprinted by a fixed header set\n\tThat is, headers like Accept-Language, DNT or Sec-GPC are randomly added, and non-essential ones (e.g. User-Agent or Accept) are reordered\n\tHost, auth (e.g. Cookie), body-related and payload-targeted headers are left untouched. The headers sent are recorded within the findings\n\tCannot be used in combination with --header-order")
+	fs.StringVar(runtime, &config.RequestHook, "request-hook", "", "If specified, every request is piped through the given command right before being sent, for custom per-request logic (e.g. re-signing it)\n\tThe raw request, preceded by its URL on the first line, is written into its stdin, and the request written into its stdout is the one sent\n\tThe command (and its arguments, split on whitespace) is executed directly, not through a shell, once per request (login requests and redirects included)\n\tUse it only with trusted commands, as these get the full requests (credentials included): --request-hook './sign.sh --key secret.pem'")
+	fs.DurationVar(runtime, &config.RequestHookTimeout, "request-hook-timeout", scan.DefaultRequestHookTimeout, "Determines the maximum duration of each request hook (--request-hook) execution, killed once exceeded (default: 10s)")
+	fs.StringVar(runtime, &config.HookFailureMode, "hook-failure-mode", defaultHookFailureMode, "Determines what happens to a request when the request hook (--request-hook) fails (e.g. non-zero exit, timeout or invalid output)\n\tEither skip (reported as skipped) or send (unmodified). Failures are logged (default: skip)")
+	fs.Var(runtime, &config.HeaderFromResponse, "header-from-response", "If specified, the value of a response header (or cookie) is set as the given header of the following requests to the same host\n\tUseful for double-submit CSRF tokens. Until captured, or if absent from the responses, the requests are sent with the latest value, if any\n\tCan be used more than once: --header-from-response 'X-CSRF: response.header:X-CSRF-Token' --header-from-response 'X-XSRF-Token: response.cookie:XSRF-TOKEN'")
+	fs.BoolVar(runtime, &config.SendReferer, "send-referer", false, "If specified, the Referer header is set to the previous URL when following redirects")
+	fs.BoolVar(runtime, &config.KeepAuthOnRedirect, "keep-auth-on-redirect", false, "If specified, the Authorization and Cookie headers are kept when following redirects to a different host\n\tBy default, those are dropped, and only the cookies set for the new host are sent")
 
 	// output
 	fs.InitGroup(output, "OUTPUT OPTIONS:")
-	fs.StringVar(output, &config.OutPath, "output", "", "Determines the path where the output file will be stored to\n\tBy default, the output file is formatted as plain text")
+	fs.Var(output, &config.OutPaths, "output", "Determines the path where the output file will be stored to\n\tCan be used more than once, to write the output in multiple formats at once: -o results.ndjson -o report.txt\n\tBy default, the format is inferred from the extension (.json, .ndjson, .md, .xml, .html), plain text otherwise")
 	fs.Alias("o", "output")
-	json := fs.Bool(output, "json", false, "If specified, the output file will be JSON-formatted\n\tBy default, the output file is formatted as plain text")
+	json := fs.Bool(output, "json", false, "If specified, the output file(s) will be JSON-formatted\n\tBy default, the format is inferred from the output file extension (see -o/--output)")
 	fs.Alias("j", "json")
-	markdown := fs.Bool(output, "markdown", false, "If specified, the output file will be Markdown-formatted\n\tBy default, the output file is formatted as plain text")
+	markdown := fs.Bool(output, "markdown", false, "If specified, the output file(s) will be Markdown-formatted\n\tBy default, the format is inferred from the output file extension (see -o/--output)")
 	fs.Alias("md", "markdown")
+	fs.Var(output, &config.OutFormats, "output-format", "If specified, the output file will be formatted with the given format: plain, json, ndjson, markdown, junit, html or template\n\tCan be used once per output (-o/--output), in the same order, or once for all of them\n\tJUnit (XML) reports have a test suite per profile, and a test case per target, failed if there are findings, useful for CI dashboards\n\tHTML reports are self-contained, with the findings grouped by severity and profile, useful to share with non-technical stakeholders\n\tBy default, the format is inferred from the output file extension (see -o/--output)")
+	fs.Alias("of", "output-format")
+	fs.StringVar(output, &config.OutTemplate, "output-template", "", "If specified, the output file will be formatted with the given Go template file (text/template)\n\tThe file content is executed once, with .Config, .Stats, .Duration and .Matches\n\tIf defined, the \"finding\" and \"error\" templates are executed once per finding and per failed request")
+	fs.Alias("ot", "output-template")
+	fs.BoolVar(output, &config.OutAppend, "output-append", false, "If specified, the output is appended to the existing output file, if any, instead of overwriting it\n\tJSON outputs are merged: findings (deduplicated by identifier) and errors are accumulated, the rest is the latest")
+	fs.BoolVar(output, &config.Force, "force", false, "If specified, the existing output file, if any, is overwritten\n\tBy default, the execution fails if the output file already exists, so it is never overwritten by accident")
 	fs.BoolVar(output, &config.ShowAll, "all", false, "If specified, results will include all requests and responses\n\tBy default, only those requests that caused a match are included in results\n\tAs it causes a noisy output, must be used in combination with -o/--output flag")
 	fs.Alias("a", "all")
 	fs.BoolVar(output, &config.ShowAllRequests, "all-requests", false, "If specified, results will include all requests\n\tBy default, only those requests that caused a match are included in results\n\tAs it causes a noisy output, must be used in combination with -o/--output flag")
@@ -127,6 +264,14 @@ func Parse(args []string) (Config, error) {
 	fs.Alias("ste", "stream-errors")
 	fs.BoolVar(output, &config.StreamMatches, "stream-matches", true, "If specified, those requests that caused a match are printed to stdout during the scan (live)\n\tEnabled by default, can be disabled with --stream-matches=false or -stm=false")
 	fs.Alias("stm", "stream-matches")
+	fs.Var(output, &config.Metadata, "metadata", "If specified, the given key=value pair is attached to every finding and to the summary (e.g. for CI correlation)\n\tCan be used more than once: --metadata commit=4f2a1c9 --metadata pipeline=1234\n\tKeys of the findings' fields (e.g. severity), or set by the scanner itself (e.g. canary), are reserved")
+	fs.Alias("meta", "metadata")
+	fs.Var(output, &config.RedactHeaders, "redact-headers", "If specified, the values of the given headers (comma-separated) are replaced with ***REDACTED***\n\twithin the requests and responses written to the outputs (but not while matching)\n\tCan be used more than once: --redact-headers Authorization,Cookie --redact-headers Set-Cookie")
+	fs.Var(output, &config.RedactPatterns, "redact-pattern", "If specified, the matches of the given regular expression are replaced with ***REDACTED***\n\twithin the requests and responses written to the outputs (but not while matching)\n\tCan be used more than once: --redact-pattern 'token=[^&]+' --redact-pattern 'eyJ[\\w.-]+'")
+	fs.StringVar(output, &config.GroupFindings, "group-findings", "", "If specified, the findings written to the outputs are grouped: by-url-profile\n\tThose sharing URL and profile are written as a single one, with the affected entrypoints (and the evidence found on each)\n\tThe summary reports the grouped findings, while those printed during the scan (live) aren't grouped")
+	fs.StringVar(output, &config.Baseline, "baseline", "", "If specified, the findings are compared against those from the given output (JSON) of a previous scan\n\tThe new, resolved and unchanged findings are printed (and written to the JSON output) once finished")
+	fs.BoolVar(output, &config.FailOnNew, "fail-on-new", false, "If specified, the execution fails (exit code 3) when there are new findings compared to the baseline (--baseline)\n\tUseful to detect drifts in CI pipelines")
+	fs.Var(output, &config.StopOnFirstFinding, "stop-on-first-finding", "If specified, the scan is stopped as soon as the first finding is found, and the execution fails (exit code 4) once the output is written\n\tOptionally, only those with the given severity or higher are considered: --stop-on-first-finding=medium\n\tUseful for smoke tests and CI gating. Cannot be used in combination with -sos/--save-on-stop")
 
 	// debug
 	fs.InitGroup(debug, "DEBUG OPTIONS:")
@@ -142,6 +287,9 @@ func Parse(args []string) (Config, error) {
 	fs.SetUsage(`
 Usage:
   gbounty [flags]
+  gbounty validate [flags]	Validates the inputs (urls, requests, profiles...) with no requests sent
+  gbounty merge [flags] <output.json>...	Merges multiple scan outputs (JSON), e.g. from a sharded scan (--shard)
+  gbounty rematch [-f <scan-id> | --storage <path>] [flags]	Runs the passive profiles (active ones are ignored) against the responses stored by a scan (--store-all-responses), with no requests sent
 
 Flags:`)
 
@@ -149,9 +297,10 @@ Flags:`)
 gbounty -u https://example.org -X POST -d "param1=value1&param2=value2" -t XSS -r 20 -a -o /tmp/results.json --json
 gbounty --urls-file domains.txt -c 200 -r 10 -p /tmp/gbounty-profiles --silent --markdown -o /tmp/results.md
 gbounty --requests-file requests.zip -r 150 --proxy-address=127.0.0.1:8080 -o /tmp/results.txt --all
-gbounty --raw-request 1.txt --raw-request 2.txt --blind-host burpcollaborator.net`)
+gbounty --raw-request 1.txt --raw-request 2.txt --blind-host burpcollaborator.net
+gbounty -u https://example.org --discover -w words.txt --extensions php,bak --filter-size 1234`)
 
-	if err := fs.Parse(os.Args[1:]); err != nil {
+	if err := fs.Parse(args[1:]); err != nil {
 		return Config{}, err
 	}
 
@@ -160,13 +309,17 @@ gbounty --raw-request 1.txt --raw-request 2.txt --blind-host burpcollaborator.ne
 		fs.PrintDefaults()
 	}
 
+	for i, format := range config.OutFormats {
+		config.OutFormats[i] = strings.ToLower(format)
+	}
+
 	switch {
+	case len(config.OutTemplate) > 0:
+		config.OutFormat = "template"
 	case *json:
 		config.OutFormat = "json"
 	case *markdown:
 		config.OutFormat = "markdown"
-	default:
-		config.OutFormat = "plain"
 	}
 
 	return config, nil
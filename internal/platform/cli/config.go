@@ -3,11 +3,21 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	stdurl "net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
 	"github.com/bountysecurity/gbounty/kit/blindhost"
+	"github.com/bountysecurity/gbounty/kit/dotenv"
 	"github.com/bountysecurity/gbounty/kit/logger"
 	"github.com/bountysecurity/gbounty/kit/url"
 )
@@ -48,19 +58,47 @@ type Config struct {
 	SaveOnStop bool
 	// Continue contains the scan's identifier to be used to continue.
 	Continue string
+	// Storage contains the path to the storage of a previous scan, used instead
+	// of its identifier (see [Config.Continue]) to replay findings or to rematch
+	// stored responses, e.g. when the storage has been moved elsewhere.
+	Storage string
 	// URLS specifies the list of URLs used to define the scan.
 	URLS MultiValue
 	// UrlsFile specifies the path to the URLs file to define the scan.
 	UrlsFile string
+	// Stream determines whether the URLs used to define the scan are read from the standard
+	// input, one per line (either plain or ND-JSON), and scanned as these arrive, until EOF.
+	Stream bool
+	// CIDRs specifies the list of CIDR ranges used to define the scan, expanded
+	// into one URL per host and port (see [Config.Ports] and [Config.CIDRURLs]).
+	CIDRs MultiValue
+	// Ports specifies the ports (comma-separated) the CIDR ranges are expanded into.
+	Ports string
+	// MaxCIDRURLs specifies the maximum amount of URLs the CIDR ranges can be
+	// expanded into (see [Config.CIDRURLs]). Zero means no limit.
+	MaxCIDRURLs int
 	// RequestsFile specifies the path to the request(s) file to define the scan.
 	RequestsFile string
 	// RawRequests specifies the path(s) to the raw request file(s) to define the scan.
+	// Directories are walked (recursively) to collect the .req files (see [rawRequestOpts]).
 	RawRequests MultiValue
+	// CSVFile specifies the path to the CSV file to define the scan, with one
+	// request (method, url, body, content type and headers) per row (see [scan.EachTemplateFromCSV]).
+	CSVFile string
+	// CSVStrict determines whether the scan fails when any of the rows from the
+	// CSV file (see [Config.CSVFile]) is malformed, instead of skipping it.
+	CSVStrict bool
+	// PCAPFile specifies the path to the capture (pcap or pcapng) file to define the scan,
+	// with one request per HTTP request captured (see [scan.EachTemplateFromPCAP]).
+	PCAPFile string
 	// ParamsFile specifies the path to the paths file to define the scan.
 	ParamsFile string
 	// ParamsSplit determines the size of the params groups the params from file will be
 	// grouped into.
 	ParamsSplit int
+	// MaxTemplateVariants limits the amount of variants (i.e. templates) built from
+	// each request template with the params from file (see [scan.ParamsCfg]).
+	MaxTemplateVariants int
 	// ParamsMethod determines the HTTP method that will be used to inject the params
 	// into the request.
 	ParamsMethod string
@@ -73,12 +111,66 @@ type Config struct {
 	Headers MultiValue
 	// Data specifies the body's data used to define the scan's requests.
 	Data MultiValue
+	// EnvFile specifies the path to the dotenv file used to populate the variables
+	// that are expanded (${VAR}) into the scan's requests.
+	EnvFile string
+	// EnvFilePriority determines whether the variables defined on the EnvFile take
+	// precedence over the process environment variables.
+	EnvFilePriority bool
+	// LoginSequenceFile specifies the path to the (JSON) file with the sequence of requests
+	// performed before the scan, to authenticate against the target (see [scan.LoginSequence]).
+	LoginSequenceFile string
+	// URLMatch specifies the regular expression the (full) URL of the request templates must
+	// match to be scanned, applied when the templates are created (see [InputsDropped]).
+	URLMatch string
+	// URLReject specifies the regular expression the (full) URL of the request templates must
+	// not match to be scanned. It takes precedence over URLMatch.
+	URLReject string
+	// ExcludeExtensions specifies the extensions (comma-separated) of the request templates' URL
+	// path not to be scanned, so those requests aren't even sent (see [Config.ExcludedExtensions]).
+	ExcludeExtensions string
+	// SeenHostsFile specifies the path to the (state) file with the hosts scanned in previous
+	// runs (one per line), where the hosts scanned are recorded (see [Config.SeenHosts]).
+	SeenHostsFile string
+	// OnlyNewHosts determines whether the hosts already recorded into the SeenHostsFile
+	// are skipped (i.e. not scanned), so only those not seen in previous runs are.
+	OnlyNewHosts bool
 	// ProfilesPath specifies the paths to the directories/files containing profiles.
 	ProfilesPath MultiValue
 	// Concurrency determines the amount of URLs scanned at the same time (concurrently).
 	Concurrency int
+	// ConcurrencyPerHost determines the amount of URLs targeting the same host
+	// scanned at the same time (concurrently). Zero means no limit.
+	ConcurrencyPerHost int
+	// TimeBudgetPerHost determines the wall-clock time each host is scanned for, before
+	// being paused so the remaining hosts get coverage. Once every host pending is paused,
+	// each of them gets another budget, in rounds. Zero means no budget.
+	TimeBudgetPerHost time.Duration
+	// Warmup determines whether a throwaway request is sent to each host before its first
+	// template is scanned, so its DNS resolution is primed (see [scan.Config.Warmup]).
+	Warmup bool
+	// Shard specifies the portion (i/n) of the templates scanned, so the same scan can be
+	// split across multiple runners, each one with a different i (see [scan.Shard]).
+	Shard string
 	// Rps determines the maximum amount of requests per second per each URL.
 	Rps int
+	// AdaptiveThrottle determines whether the requests per second sent to each host are
+	// adjusted dynamically, according to the host's latency and errors (see [scan.AdaptiveThrottle]).
+	AdaptiveThrottle bool
+	// AdaptiveTargetLatency determines the response latency the adaptive throttle aims for.
+	AdaptiveTargetLatency time.Duration
+	// AdaptiveMinRps determines the minimum amount of requests per second per host,
+	// when the adaptive throttle is enabled.
+	AdaptiveMinRps int
+	// AdaptiveMaxRps determines the maximum amount of requests per second per host,
+	// when the adaptive throttle is enabled. Zero means [Config.Rps].
+	AdaptiveMaxRps int
+	// RetryOnStatus determines the comma-separated list of response status codes (e.g. 429,503)
+	// that are considered transient, so the requests are retried (see [Config.StatusRetry]).
+	RetryOnStatus string
+	// MaxRetries determines the maximum amount of retries per request,
+	// for those responses with any of the [Config.RetryOnStatus] codes.
+	MaxRetries int
 	// OnlyActive determines whether the scan will only use active profiles.
 	OnlyActive bool
 	// OnlyPassive determines whether the scan will only use passive profiles.
@@ -87,10 +179,45 @@ type Config struct {
 	OnlyPassiveReq bool
 	// OnlyPassiveRes determines whether the scan will only use passive response profiles.
 	OnlyPassiveRes bool
-	// OutPath specifies the path where the scan output will be written to.
-	OutPath string
-	// OutFormat specifies the format the scan output will be written.
+	// OutPaths specifies the paths where the scan output will be written to,
+	// each one in its own format (see [Config.Outputs]).
+	OutPaths MultiValue
+	// OutFormats specifies the formats the scan output will be written, either
+	// a single one for all the [Config.OutPaths], or one per path, in order.
+	OutFormats MultiValue
+	// OutFormat specifies the format the scan output will be written, when no
+	// [Config.OutFormats] are specified (i.e. set by -j, -md or -ot flags).
 	OutFormat string
+	// OutTemplate specifies the path to the Go template file used to write the
+	// scan output(s), whose format is template.
+	OutTemplate string
+	// OutAppend determines whether the scan output is appended to the existing output file,
+	// if any, instead of overwriting it. JSON outputs are merged (see [writer.MergeJSON]).
+	OutAppend bool
+	// Force determines whether the existing output file, if any, can be overwritten.
+	Force bool
+	// Metadata specifies the key=value pairs attached to every finding
+	// and to the scan summary (e.g. commit SHA, pipeline ID).
+	Metadata MultiValue
+	// RedactHeaders specifies the headers (comma-separated) whose values are masked
+	// from the requests and responses written to the outputs (see [Config.Redaction]).
+	RedactHeaders MultiValue
+	// RedactPatterns specifies the regular expressions whose matches are masked
+	// from the requests and responses written to the outputs (see [Config.Redaction]).
+	RedactPatterns MultiValue
+	// GroupFindings specifies how the findings written to the outputs are grouped
+	// (see [Config.FindingsGrouping]). Empty means no grouping.
+	GroupFindings string
+	// Baseline specifies the path to the output (JSON) of a previous scan, used as the
+	// baseline the findings are compared against (see [scan.DiffBaseline]).
+	Baseline string
+	// FailOnNew determines whether the execution fails (i.e. non-zero exit code)
+	// when there are new findings compared to the Baseline.
+	FailOnNew bool
+	// StopOnFirstFinding determines whether the scan is stopped, and the execution fails
+	// (i.e. non-zero exit code), as soon as the first finding is found, optionally only those
+	// with the given severity or higher (see [Config.StopOnFirstFindingSeverity]).
+	StopOnFirstFinding OptionalValue
 	// Silent determines whether the scan summary will be printed.
 	Silent bool
 	// ShowAll determines whether all the scan tasks will be printed.
@@ -113,12 +240,189 @@ type Config struct {
 	PrintTags bool
 	// InMemory determines whether the scan uses memory as storage.
 	InMemory bool
+	// KeepStorage determines whether the scan storage is kept once the scan is finished.
+	KeepStorage bool
+	// StoreAllResponses determines whether every request sent, along with the response got,
+	// is stored into the scan storage, regardless of matches, so it can be analyzed offline
+	// later (e.g. with the rematch subcommand). It implies keeping the storage (see [Config.KeepStorage]).
+	StoreAllResponses bool
+	// StoreMaxBodySize specifies the maximum size (in bytes) of the response bodies stored
+	// (see [Config.StoreAllResponses]), so larger ones are truncated. Zero means no limit.
+	StoreMaxBodySize int
+	// Replay contains the identifier of the finding to be replayed.
+	Replay string
+	// Count determines whether the amount of requests the scan would send is reported
+	// (by host and profile) instead of running the scan, so no requests are sent.
+	Count bool
+	// SkipIf specifies the expression (see [Config.SkipPredicate]) evaluated against each
+	// template before the scan starts, over the variables defined and extracted, so those
+	// templates it holds for are skipped, e.g. {{logged_in}} == false.
+	SkipIf string
+	// SkipIfUndefined determines whether the templates are skipped when the skip
+	// predicate (see [Config.SkipIf]) references undefined variables, instead of scanned.
+	SkipIfUndefined bool
+	// TemplateFilter specifies the expression (see [scan.TemplateFilter]) evaluated against
+	// each template during dispatch, over its method, host, path, headers and body, so those
+	// templates it doesn't hold for are skipped, e.g. {{request.method}} == POST.
+	TemplateFilter string
+	// SaveTemplateBundle specifies the path to the zipped (.zip) file the prepared scan templates
+	// are saved into (see [scan.WriteTemplateBundle]), so these can be re-used (-rf/--requests-file).
+	SaveTemplateBundle string
+	// PrepareOnly determines whether the scan templates are only prepared and saved (see
+	// [Config.SaveTemplateBundle]) instead of running the scan, so no requests are sent.
+	PrepareOnly bool
+	// PriorityHosts specifies the hosts (host[=weight]) whose templates are scanned first.
+	PriorityHosts MultiValue
+	// PriorityPathRegexes specifies the path regular expressions (regex[=weight]) whose
+	// matching templates are scanned first.
+	PriorityPathRegexes MultiValue
+	// ScanTimeout determines the maximum duration of the scan, stopped once reached.
+	ScanTimeout time.Duration
+	// MaxErrors determines when the scan is aborted because too many requests failed (e.g. connection refused),
+	// either as an amount (e.g. 50) or as a percentage (e.g. 20%) of the latest [Config.MaxErrorsWindow]
+	// requests. Responses with any status code aren't failures (see [Config.ErrorThreshold]).
+	MaxErrors string
+	// MaxErrorsWindow determines the amount of latest requests the percentage of failed
+	// requests (see [Config.MaxErrors]) is calculated over. Zero means the default (100).
+	MaxErrorsWindow int
+	// ProfileTimeout determines the maximum duration of each profile's matchers evaluation
+	// against a request/response, so slow matchers (e.g. regexes) cannot stall the scan.
+	// Zero (default) means no timeout.
+	ProfileTimeout time.Duration
+	// SensitiveData determines the signatures (comma-separated, or all) of the sensitive data
+	// (e.g. API keys) looked for within the responses (see [Config.SensitiveDataProfile]).
+	SensitiveData string
+	// SensitiveDataFile specifies the path to the file with custom signatures (see [Config.Signatures]).
+	SensitiveDataFile string
+	// FileSignaturesFile specifies the path to the file with custom file signatures,
+	// looked for by the File Read greps (see [Config.FileSignatures]).
+	FileSignaturesFile string
+	// CloudSignaturesFile specifies the path to the file with custom cloud signatures,
+	// looked for by the Cloud Exposure greps (see [Config.CloudSignatures]).
+	CloudSignaturesFile string
+	// DebugExposures specifies the debug surfaces (comma-separated signature names) looked for
+	// during content discovery and passive scans, like Go's pprof or Django's debug page (see
+	// [Config.DebugExposureProfile]). All of them by default, or none if "none".
+	DebugExposures string
+	// DebugSignaturesFile specifies the path to the file with custom debug signatures,
+	// looked for by the Debug Exposure greps (see [Config.DebugSignatures]).
+	DebugSignaturesFile string
+	// ProbeDebugPaths determines whether the paths of the debug signatures (e.g. /debug/pprof/)
+	// are probed during content discovery, in addition to the words from the [Config.Wordlist].
+	ProbeDebugPaths bool
+	// Fingerprint determines whether the responses are analyzed looking for the technology
+	// stack (e.g. nginx or PHP), reported as informational findings (see [Config.FingerprintProfile]).
+	Fingerprint bool
+	// TechnologySignaturesFile specifies the path to the file with custom technology signatures,
+	// looked for by the Technology greps (see [Config.TechnologySignatures]).
+	TechnologySignaturesFile string
+	// WebSocket determines whether requests are sent as WebSocket opening handshakes, so
+	// the successful upgrades are reported as informational findings (see [Config.WebSocketProfile]).
+	WebSocket bool
+	// WebSocketProtocols specifies the WebSocket subprotocols (comma-separated) offered
+	// on the opening handshakes (see [Config.WebSocketSubprotocols]).
+	WebSocketProtocols string
+	// GraphQLIntrospection determines whether an introspection query (i.e. __schema) is sent to
+	// every template's url, so the GraphQL endpoints with introspection enabled are reported,
+	// along with the amount of types exposed (see [scan.GraphQLIntrospectionProfile]).
+	GraphQLIntrospection bool
+	// SeverityOverride specifies the profile=severity pairs that override the severity
+	// of the issues found by the given profiles (see [Config.SeverityOverrides]).
+	SeverityOverride MultiValue
+	// SeverityOverrideFile specifies the path to the file with profile=severity pairs,
+	// one per line, overridden by [Config.SeverityOverride] (see [Config.SeverityOverrides]).
+	SeverityOverrideFile string
+	// StrictPlaceholders determines whether the scan fails at startup if the active profiles
+	// contain unknown placeholders (e.g. {{foo}}), instead of sending those as is. Built-in
+	// ones are {{target_host}}, {{timestamp}}, {{nonce}} and {{callback}} (with --blind-host).
+	StrictPlaceholders bool
+	// Mutations specifies the mutations (comma-separated) applied to the payloads of the active profiles,
+	// to generate variations of them, each optionally scoped to some insertion point types, e.g.
+	// sql,null-byte:URLPathFile|ParamURLValue (see [Config.PayloadMutations]).
+	Mutations string
+	// MutationsDepth determines how many times the [Config.Mutations] are applied recursively.
+	MutationsDepth int
+	// MutationsSeed determines the seed of the [Config.Mutations], so the variations are reproducible.
+	MutationsSeed int64
+	// MutationsMaxVariants limits the amount of variations generated from each payload. It's not
+	// bounded by [Config.MaxTemplateVariants], as that one limits the templates, while the mutated
+	// payloads are injected into each of those, so the amount of requests is the product of both.
+	MutationsMaxVariants int
+	// NoEntrypoints determines whether the scan's requests are sent as is, with no
+	// entrypoints nor injections, so only passive (response-based) profiles are used.
+	NoEntrypoints bool
+	// Passive determines whether the scan is passive: requests are sent once, as is (like
+	// with [Config.NoEntrypoints]), and analyzed with the curated passive profiles (see
+	// [PassiveProfiles]), in addition to the enabled passive ones loaded, if any.
+	Passive bool
 	// FilterTags determines whether enabled profiles will be filtered by provided tags.
 	FilterTags MultiValue
+	// Discover determines whether the scan is a content discovery, where the target urls are
+	// used as base urls, and the words from the [Config.Wordlist] are appended to them.
+	Discover bool
+	// Wordlist specifies the path to the wordlist file used for content discovery.
+	Wordlist string
+	// Extensions specifies the extensions (comma-separated) appended to each word
+	// from the [Config.Wordlist], in addition to the word itself.
+	Extensions string
+	// MatchStatus specifies the status codes (comma-separated) of those
+	// responses reported during content discovery.
+	MatchStatus string
+	// FilterSize specifies the sizes (comma-separated) of those responses
+	// not reported during content discovery, like soft-404 pages.
+	FilterSize string
+	// Exposures specifies the exposures (comma-separated) looked for during content
+	// discovery and passive scans, like directory listings or backup files (see
+	// [Config.ExposureProfile]). None by default (i.e. opt-in), or all of them if "all".
+	Exposures string
+	// FilterMime specifies the media types (e.g. image/*) of those responses whose body
+	// is (allow:) or is not (deny:) analyzed by the matchers. Headers are always analyzed.
+	FilterMime MultiValue
+	// OnlyStatus specifies the status codes (e.g. 302) or classes (e.g. 4xx), comma-separated,
+	// of those responses evaluated by the matchers (and recorded). The rest are filtered out.
+	OnlyStatus string
+	// OnlyErrors determines whether only the 4xx and 5xx responses are evaluated by the matchers.
+	OnlyErrors bool
+	// SuppressSuccess determines whether the 2xx responses are filtered out, so never evaluated.
+	SuppressSuccess bool
+	// OnlyDiff determines whether only those responses that differ from the per-URL
+	// baseline (i.e. the first response for the same URL) are evaluated by the matchers.
+	OnlyDiff bool
+	// DiffResponses determines whether two variants (see [Config.DiffA] and [Config.DiffB]) of
+	// every request are sent, and their responses compared, so those requests whose responses
+	// differ are reported along with the response diff (see [Config.ResponseDiff]).
+	DiffResponses bool
+	// DiffA and DiffB define the two variants of every request sent to diff the responses,
+	// each one made of headers (e.g. header:X-Role: admin) and up to one payload (e.g. payload:1).
+	DiffA MultiValue
+	DiffB MultiValue
+	// RateLimitBurst determines how many requests are sent, in a burst, to the request templates
+	// that ask for it (see [Config.RateLimitMatch]), to detect whether the endpoint is rate-limited.
+	RateLimitBurst int
+	// RateLimitMatch specifies the regular expression that determines which request templates
+	// (by URL) the burst is sent to (see [Config.RateLimitBurst]). By default, all of them, but
+	// only if any active profile is tagged rate-limit (see [scan.RateLimitTag]), none otherwise.
+	RateLimitMatch string
+	// MassAssignment determines whether extra parameters are injected into every request
+	// template (see [Config.MassAssignmentParams]), along with duplicates of the existing
+	// ones, to detect whether these are accepted (mass assignment, parameter pollution).
+	MassAssignment bool
+	// MassAssignmentParams specifies the parameters (name=value) injected to probe mass
+	// assignment (see [Config.MassAssignment]). By default, a few common ones (e.g. isAdmin=true).
+	MassAssignmentParams MultiValue
+	// CachePoisoning determines whether every request template is sent with poisoned (unkeyed)
+	// headers (see [Config.CachePoisoningHeaders]), followed by clean requests, to detect
+	// whether the poisoned values are cached (i.e. web cache poisoning).
+	CachePoisoning bool
+	// CachePoisoningHeaders specifies the headers poisoned to probe cache poisoning
+	// (see [Config.CachePoisoning]). By default, a few common ones (e.g. X-Forwarded-Host).
+	CachePoisoningHeaders MultiValue
 	// BlindHost determines the host that will be used for interactions.
 	BlindHost string
 	// EmailAddress determines the email address that will be used during the scan.
 	EmailAddress string
+	// JWTSignKey determines the key used to re-sign JWT bearer tokens after injection.
+	JWTSignKey string
 	// CustomTokens can be used to replace certain tokens or labels (like {MY_TOKEN}) with
 	// user-configured values.
 	CustomTokens map[string]string
@@ -126,6 +430,107 @@ type Config struct {
 	ProxyAddress string
 	// ProxyAuth determines the proxy auth that will be used during the scan.
 	ProxyAuth string
+	// ReplayProxy determines the proxy (e.g. http://127.0.0.1:8080) the requests that
+	// produced findings are replayed through (see [Config.ReplayProxyAddress]), once these
+	// are found, so only those reach an intercepting proxy (e.g. Burp) for follow-up.
+	ReplayProxy string
+	// UnixSocket specifies the path to the Unix domain socket the requests are sent
+	// through, instead of dialing the target host (i.e. the host is only used for
+	// the Host header and the TLS server name).
+	UnixSocket string
+	// CABundle specifies the path(s) to the PEM bundle(s) of the certificate authorities
+	// the targets' certificates are verified against (see [Config.RootCAs]), along with
+	// the system ones. By default, certificates aren't verified.
+	CABundle MultiValue
+	// CABundleDir specifies the path to a directory whose PEM bundles (.pem, .crt and .cer
+	// files) are loaded like those from [Config.CABundle], which they're merged with.
+	CABundleDir string
+	// DNSCacheTTL determines for how long the addresses resolved for each host
+	// are cached, and reused by the following connections. Zero means no cache.
+	DNSCacheTTL time.Duration
+	// DNSPin determines whether the first address resolved for each host is
+	// used for the whole scan (i.e. cached regardless of the [Config.DNSCacheTTL]).
+	DNSPin bool
+	// ResolveAllTo specifies the address (host:port) every host is resolved to (i.e. sinkhole
+	// mode), like a local mock, so no traffic reaches the actual hosts. The requests are still
+	// sent with the original Host header, so virtual-host routing works on the mock.
+	ResolveAllTo string
+	// Auth specifies the authentication performed against those hosts that require it,
+	// in the form of scheme:credentials. Only NTLM is supported (ntlm:domain\user:pass),
+	// which authenticates connections, so requests are sent over keep-alive connections.
+	Auth string
+	// AwsSigV4 specifies the region and service (region:service) every request is signed
+	// for, with AWS Signature Version 4 (see [Config.SigV4Signer]), e.g. us-east-1:execute-api.
+	AwsSigV4 string
+	// AwsCredentials specifies the AWS credentials the requests are signed with (see [Config.AwsSigV4]),
+	// as access_key_id:secret_access_key[:session_token], instead of those from the environment.
+	AwsCredentials string
+	// AllowRawHeaders determines whether ambiguous framing headers (e.g. duplicated
+	// Content-Length or Transfer-Encoding) from raw requests are sent verbatim.
+	AllowRawHeaders bool
+	// PreserveLineEndings determines whether raw requests are sent with their original
+	// line endings (e.g. bare LFs), instead of being normalized to CRLF, for exact-byte
+	// replays. Bodies are always sent as is, with no normalization at all.
+	PreserveLineEndings bool
+	// HTTPVersion specifies the protocol version sent in the request line (e.g. 1.0), instead
+	// of the one from the request templates. HTTP/0.9-style requests (0.9) and custom (or
+	// malformed) versions are only allowed along with [Config.AllowRawHeaders].
+	HTTPVersion string
+	// HTTP2 determines whether requests are sent over HTTP/2, if negotiated (ALPN),
+	// falling back to HTTP/1.1 otherwise. Cleartext requests are sent over HTTP/1.1.
+	HTTP2 bool
+	// HTTP2PriorKnowledge determines whether requests are sent over HTTP/2 with no fallback,
+	// including cleartext ones (h2c), with no upgrade. It implies [Config.HTTP2].
+	HTTP2PriorKnowledge bool
+	// MaxDecompressedSize specifies the maximum size (in bytes) of the response bodies once
+	// decompressed (e.g. gzip-encoded ones), so larger ones (e.g. decompression bombs) are
+	// truncated, and reported as such within the findings. Zero means no limit.
+	MaxDecompressedSize int
+	// HeaderOrder specifies the order (comma-separated) the request headers are sent in.
+	// Those headers not listed are sent afterward, in the order those were added.
+	HeaderOrder string
+	// RemoveHeaders specifies the headers (comma-separated) removed from the request
+	// templates, once inherited, no matter where these come from (e.g. [Config.Headers]).
+	RemoveHeaders MultiValue
+	// SendReferer determines whether the Referer header is set to the previous URL
+	// when following redirects (see [scan.RedirectPolicy]).
+	SendReferer bool
+	// KeepAuthOnRedirect determines whether the Authorization and Cookie headers
+	// are kept on cross-host redirects, instead of being dropped.
+	KeepAuthOnRedirect bool
+	// RequestIDHeader specifies the header set on every request sent, with a unique
+	// value per request (see [Config.RequestIDGenerator]), so it can be correlated.
+	RequestIDHeader string
+	// RequestIDGenerator specifies how the values of the [Config.RequestIDHeader] are
+	// generated, either "sequence", "uuid" or "timestamp" (see [scan.RequestIDGeneratorFrom]).
+	RequestIDGenerator string
+	// CORSOrigin specifies the (attacker-controlled) origin set as the Origin header of every request
+	// sent, unless already present, looked for by the CORS Misconfiguration greps (see [scan.WithCORSOrigin]).
+	CORSOrigin string
+	// CanaryPrefix specifies the prefix of the unique canary prepended to every payload injected,
+	// so the payload reflections can be attributed to the request (see [scan.Config.CanaryPrefix]).
+	CanaryPrefix string
+	// RequestMutators specifies the mutations (comma-separated) applied to every request sent,
+	// like randomizing the header names' casing, commonly used to bypass web application
+	// firewalls (WAFs), in the given order (see [Config.Mutators]).
+	RequestMutators string
+	// JitterHeaders determines whether the benign headers of every request sent (e.g. Accept-Language
+	// or DNT) are randomly added and reordered, so the scan cannot be trivially fingerprinted by a fixed
+	// header set (see [scan.JitterHeadersMutator]). It's applied before any [Config.RequestMutators].
+	JitterHeaders bool
+	// RequestHook specifies the external command (with its arguments, if any) every request is
+	// piped through right before being sent, e.g. to re-sign it (see [Config.Hook]).
+	// It is executed directly (i.e. not through a shell, so arguments are split on whitespace), once per request.
+	RequestHook string
+	// RequestHookTimeout determines the maximum duration of each [Config.RequestHook] execution.
+	RequestHookTimeout time.Duration
+	// HookFailureMode determines whether the requests are skipped ("skip") or sent
+	// unmodified ("send") when the [Config.RequestHook] fails (see [scan.RequestHookFailureMode]).
+	HookFailureMode string
+	// HeaderFromResponse defines the rules that set the values captured from the responses
+	// (either headers or cookies) as headers of the following requests sent to the same host,
+	// e.g. X-CSRF: response.header:X-CSRF-Token (see [Config.HeaderPropagations]).
+	HeaderFromResponse MultiValue
 	// Verbosity determines the level of verbosity for the internal logger.
 	Verbosity Verbosity
 	// Update determines whether both app and profiles will be updated.
@@ -166,20 +571,150 @@ func (cfg Config) ProfUpdate() bool {
 
 // Validate validates the [Config] and returns an [error] if it isn't valid.
 func (cfg Config) Validate() error {
-	validations := []func() error{
+	for _, validation := range cfg.validations() {
+		if err := validation(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateAll is like [Config.Validate], but instead of returning the
+// first error found, it returns all of them (joined), if any.
+func (cfg Config) ValidateAll() error {
+	var errs []error
+	for _, validation := range cfg.validations() {
+		if err := validation(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg Config) validations() []func() error {
+	return []func() error{
 		cfg.checkProfilesPathFound,
 		cfg.checkInMemoryIncompatibility,
+		cfg.checkCountIncompatibility,
+		cfg.checkTemplateBundleIncompatibility,
+		cfg.checkValidSkipIf,
+		cfg.checkValidTemplateFilter,
+		cfg.checkValidSeenHosts,
+		cfg.checkKeepStorageIncompatibility,
+		cfg.checkStoreAllResponsesIncompatibility,
+		cfg.checkStorageIncompatibility,
+		cfg.checkValidStoreMaxBodySize,
+		cfg.checkValidMaxDecompressedSize,
+		cfg.checkNoEntrypointsIncompatibility,
+		cfg.checkPassiveIncompatibility,
 		cfg.checkOnlyOneExecutionEntry,
+		cfg.checkStreamIncompatibility,
 		cfg.checkOnlyOneAllOption,
 		cfg.checkExecutionEntryAcceptParams,
+		cfg.checkCSVStrictIncompatibility,
+		cfg.checkValidEnvFile,
+		cfg.checkValidLoginSequence,
+		cfg.checkValidPriorities,
+		cfg.checkValidScanTimeout,
+		cfg.checkValidMaxErrors,
+		cfg.checkValidProfileTimeout,
+		cfg.checkValidSensitiveData,
+		cfg.checkValidFileSignatures,
+		cfg.checkValidCloudSignatures,
+		cfg.checkValidDebugSignatures,
+		cfg.checkValidTechnologySignatures,
+		cfg.checkValidSeverityOverrides,
+		cfg.checkValidPayloadMutations,
+		cfg.checkValidStopOnFirstFinding,
+		cfg.checkValidMetadata,
+		cfg.checkValidMimeFilter,
+		cfg.checkValidRedaction,
+		cfg.checkValidFindingsGrouping,
+		cfg.checkValidResponseFilter,
+		cfg.checkValidURLFilter,
+		cfg.checkValidHeaderOrder,
+		cfg.checkValidRemoveHeaders,
+		cfg.checkValidRequestMutators,
+		cfg.checkValidRequestHook,
+		cfg.checkValidRequestID,
+		cfg.checkValidCORSOrigin,
+		cfg.checkValidCanaryPrefix,
+		cfg.checkValidHeaderPropagations,
+		cfg.checkValidHTTPVersion,
+		cfg.checkValidPreserveLineEndings,
+		cfg.checkHTTP2Incompatibility,
+		cfg.checkValidWebSocket,
+		cfg.checkGraphQLIntrospectionIncompatibility,
+		cfg.checkValidAuth,
+		cfg.checkValidSigV4,
+		cfg.checkDiscoveryIncompatibility,
+		cfg.checkValidDiscovery,
+		cfg.checkValidExposures,
 		cfg.checkValidUrls,
+		cfg.checkValidCIDRs,
 		cfg.checkValidConcurrency,
+		cfg.checkValidConcurrencyPerHost,
+		cfg.checkValidTimeBudgetPerHost,
+		cfg.checkValidShard,
+		cfg.checkValidUnixSocket,
+		cfg.checkValidReplayProxy,
+		cfg.checkValidResponseDiff,
+		cfg.checkValidRateLimit,
+		cfg.checkValidMassAssignment,
+		cfg.checkValidCachePoisoning,
+		cfg.checkValidDNSCache,
+		cfg.checkValidResolveAllTo,
+		cfg.checkValidCABundle,
 		cfg.checkValidRPS,
+		cfg.checkValidAdaptiveThrottle,
+		cfg.checkValidStatusRetry,
 		cfg.checkOutputForAnyAllFlag,
+		cfg.checkValidOutputFormats,
 		cfg.checkValidOutput,
+		cfg.checkValidOutputTemplate,
+		cfg.checkValidBaseline,
 		cfg.checkValidParamsFlag,
 		cfg.checkInteractionHostIsValid,
 	}
+}
+
+// ValidateReplay validates the [Config] for a replay execution (see [Config.Replay])
+// and returns an [error] if it isn't valid.
+func (cfg Config) ValidateReplay() error {
+	validations := []func() error{
+		cfg.checkProfilesPathFound,
+		cfg.checkReplayFromDefined,
+		cfg.checkOnlyFromOrStorage,
+		cfg.checkValidRedaction,
+	}
+
+	for _, validation := range validations {
+		if err := validation(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errReplayWithoutFrom = errors.New("you must specify the scan's identifier (-f/--from) or storage (--storage) the finding (--replay) belongs to")
+
+func (cfg Config) checkReplayFromDefined() error {
+	if len(cfg.Continue) == 0 && len(cfg.Storage) == 0 {
+		return errReplayWithoutFrom
+	}
+	return nil
+}
+
+// ValidateRematch validates the [Config] for a rematch execution (i.e. the rematch
+// subcommand) and returns an [error] if it isn't valid.
+func (cfg Config) ValidateRematch() error {
+	validations := []func() error{
+		cfg.checkProfilesPathFound,
+		cfg.checkRematchFromDefined,
+		cfg.checkOnlyFromOrStorage,
+		cfg.checkValidRedaction,
+		cfg.checkValidSeverityOverrides,
+	}
 
 	for _, validation := range validations {
 		if err := validation(); err != nil {
@@ -189,9 +724,113 @@ func (cfg Config) Validate() error {
 	return nil
 }
 
+var (
+	errRematchWithoutFrom    = errors.New("you must specify the scan's identifier (-f/--from) or storage (--storage) whose responses are rematched")
+	errStorageWithoutRematch = errors.New("you can only use --storage to replay findings (--replay) or to rematch stored responses (rematch)")
+)
+
+func (cfg Config) checkRematchFromDefined() error {
+	if len(cfg.Continue) == 0 && len(cfg.Storage) == 0 {
+		return errRematchWithoutFrom
+	}
+	return nil
+}
+
+var errFromAndStorage = errors.New("you cannot use both, the scan's identifier (-f/--from) and storage (--storage)")
+
+func (cfg Config) checkOnlyFromOrStorage() error {
+	if len(cfg.Continue) > 0 && len(cfg.Storage) > 0 {
+		return errFromAndStorage
+	}
+	return nil
+}
+
+func (cfg Config) checkStorageIncompatibility() error {
+	if len(cfg.Storage) > 0 {
+		return errStorageWithoutRematch
+	}
+	return nil
+}
+
+var errStoreAllResponsesInMemory = errors.New("you cannot use --store-all-responses on memory-only (-m/--inmem) executions")
+
+func (cfg Config) checkStoreAllResponsesIncompatibility() error {
+	if cfg.StoreAllResponses && cfg.InMemory {
+		return errStoreAllResponsesInMemory
+	}
+	return nil
+}
+
+const defaultStoreMaxBodySize = 1 << 20
+
+var errInvalidStoreMaxBodySize = errors.New("the maximum size of the stored response bodies (--store-max-body) cannot be negative")
+
+func (cfg Config) checkValidStoreMaxBodySize() error {
+	if cfg.StoreMaxBodySize < 0 {
+		return errInvalidStoreMaxBodySize
+	}
+	return nil
+}
+
+const defaultMaxDecompressedSize = 10 << 20
+
+var errInvalidMaxDecompressedSize = errors.New("the maximum size of the decompressed response bodies (--max-decompressed-size) cannot be negative")
+
+func (cfg Config) checkValidMaxDecompressedSize() error {
+	if cfg.MaxDecompressedSize < 0 {
+		return errInvalidMaxDecompressedSize
+	}
+	return nil
+}
+
+var errKeepStorageInMemory = errors.New("you cannot use -ks/--keep-storage on memory-only (-m/--inmem) executions")
+
+func (cfg Config) checkKeepStorageIncompatibility() error {
+	if cfg.KeepStorage && cfg.InMemory {
+		return errKeepStorageInMemory
+	}
+	return nil
+}
+
 var errNoProfilesPathFound = errors.New("no profiles path (-p/--profiles) specified nor default one found")
 
+var errNoEntrypointsOnlyActive = errors.New("you cannot use -noep/--no-entrypoints with only active profiles (-active/--only-active), as those require entrypoints")
+
+func (cfg Config) checkNoEntrypointsIncompatibility() error {
+	if cfg.NoEntrypoints && cfg.OnlyActive {
+		return errNoEntrypointsOnlyActive
+	}
+	return nil
+}
+
+var (
+	errPassiveOnlyActive = errors.New("you cannot use --passive-scan with only active profiles (-active/--only-active), as those require injection")
+	errPassiveDiscover   = errors.New("you cannot use --passive-scan with content discovery (--discover)")
+)
+
+func (cfg Config) checkPassiveIncompatibility() error {
+	if !cfg.Passive {
+		return nil
+	}
+
+	if cfg.OnlyActive {
+		return errPassiveOnlyActive
+	}
+
+	if cfg.Discover {
+		return errPassiveDiscover
+	}
+
+	return nil
+}
+
 func (cfg Config) checkProfilesPathFound() error {
+	// Content discovery uses its own profile (see [Config.DiscoveryProfile]),
+	// and passive scans can rely on the curated ones only (see [PassiveProfiles]).
+	if cfg.Discover || cfg.Passive {
+		return nil
+	}
+
 	if len(cfg.ProfilesPath) == 0 || (len(cfg.ProfilesPath) == 1 && len(cfg.ProfilesPath[0]) == 0) {
 		return errNoProfilesPathFound
 	}
@@ -207,7 +846,71 @@ func (cfg Config) checkInMemoryIncompatibility() error {
 	return nil
 }
 
-var errMultipleExecutionEntries = errors.New("you must specify either URL(s) (-u/--url), a URLs file (-uf/--urls-file), a request(s) file (-rf/--requests-file) or some raw request file(s) (-rr/--raw-request)")
+var errCountIncompatibility = errors.New("you cannot use --count to continue (-f/--from) a scan")
+
+var errStopOnFirstFindingIncompatibility = errors.New("you cannot use --stop-on-first-finding in combination with -sos/--save-on-stop, as stopped scans aren't meant to be continued")
+
+func (cfg Config) checkValidStopOnFirstFinding() error {
+	if !cfg.StopOnFirstFinding.Enabled {
+		return nil
+	}
+
+	if cfg.SaveOnStop {
+		return errStopOnFirstFindingIncompatibility
+	}
+
+	if _, err := cfg.StopOnFirstFindingSeverity(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (cfg Config) checkCountIncompatibility() error {
+	if cfg.Count && len(cfg.Continue) > 0 {
+		return errCountIncompatibility
+	}
+	return nil
+}
+
+var (
+	errPrepareOnlyWithoutBundle      = errors.New("you must specify the template bundle (--save-template-bundle) to make use of --prepare-only")
+	errTemplateBundleIncompatibility = errors.New("you cannot use --save-template-bundle to continue (-f/--from) a scan, nor in combination with --count")
+)
+
+func (cfg Config) checkTemplateBundleIncompatibility() error {
+	if cfg.PrepareOnly && len(cfg.SaveTemplateBundle) == 0 {
+		return errPrepareOnlyWithoutBundle
+	}
+	if len(cfg.SaveTemplateBundle) > 0 && (len(cfg.Continue) > 0 || cfg.Count) {
+		return errTemplateBundleIncompatibility
+	}
+	if info, err := os.Stat(cfg.SaveTemplateBundle); err == nil && info.IsDir() {
+		return fmt.Errorf(`invalid template bundle path: "%s" - is a directory`, cfg.SaveTemplateBundle) //nolint:err113
+	}
+	return nil
+}
+
+var errSkipIfUndefinedWithoutSkipIf = errors.New("you must specify the skip predicate (--skip-if) to make use of --skip-if-undefined")
+
+func (cfg Config) checkValidSkipIf() error {
+	if cfg.SkipIfUndefined && len(cfg.SkipIf) == 0 {
+		return errSkipIfUndefinedWithoutSkipIf
+	}
+	if _, err := cfg.SkipPredicate(); err != nil {
+		return fmt.Errorf(`the provided skip predicate (--skip-if) is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidTemplateFilter() error {
+	if _, err := cfg.ScanTemplateFilter(); err != nil {
+		return fmt.Errorf(`the provided template filter (--template-filter) is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+var errMultipleExecutionEntries = errors.New("you must specify either URL(s) (-u/--url) and/or CIDR range(s) (--cidr), a URLs file (-uf/--urls-file), a stream of URLs (--stream), a request(s) file (-rf/--requests-file), some raw request file(s) (-rr/--raw-request), a CSV file (--csv) or a capture file (--pcap)")
 
 func (cfg Config) checkOnlyOneExecutionEntry() error {
 	if cfg.rawURLSAndFileDefined() || cfg.multipleFilesDefined() || cfg.noEntriesDefined() {
@@ -226,15 +929,37 @@ func (cfg Config) checkOnlyOneAllOption() error {
 	return nil
 }
 
-var errExecutionEntryAcceptParams = errors.New("you must specify either URL(s) (with -u/--url, or with -uf/--urls-file) with some options (-X, -H, -d) or a request(s) file (-rf/--requests-file) or some raw request file(s) (-rr/--raw-request)")
+var errExecutionEntryAcceptParams = errors.New("you must specify either URL(s) (with -u/--url, -uf/--urls-file or --stream) with some options (-X, -H, -d) or a request(s) file (-rf/--requests-file), some raw request file(s) (-rr/--raw-request), a CSV file (--csv) or a capture file (--pcap)")
 
 func (cfg Config) checkExecutionEntryAcceptParams() error {
-	if cfg.requestsFileDefined() && cfg.requestOptsDefined() {
+	if (cfg.requestsFileDefined() || cfg.csvFileDefined() || cfg.pcapFileDefined()) && cfg.requestOptsDefined() {
 		return errExecutionEntryAcceptParams
 	}
 	return nil
 }
 
+var errStreamIncompatibility = errors.New("you cannot use --stream to continue (-f/--from) a scan, nor in combination with -sos/--save-on-stop, --count, --save-template-bundle, --discover or priority rules (--priority-host, --priority-path-regex), as streamed templates are scanned as these arrive")
+
+func (cfg Config) checkStreamIncompatibility() error {
+	if !cfg.Stream {
+		return nil
+	}
+	if len(cfg.Continue) > 0 || cfg.SaveOnStop || cfg.Count || len(cfg.SaveTemplateBundle) > 0 || cfg.Discover ||
+		len(cfg.PriorityHosts) > 0 || len(cfg.PriorityPathRegexes) > 0 {
+		return errStreamIncompatibility
+	}
+	return nil
+}
+
+var errCSVStrictWithoutCSV = errors.New("the strict mode (--csv-strict) can only be used in combination with a CSV file (--csv)")
+
+func (cfg Config) checkCSVStrictIncompatibility() error {
+	if cfg.CSVStrict && !cfg.csvFileDefined() {
+		return errCSVStrictWithoutCSV
+	}
+	return nil
+}
+
 var errInvalidConcurrency = errors.New("you must specify a concurrency (-c/--concurrency) higher than zero")
 
 func (cfg Config) checkValidConcurrency() error {
@@ -245,141 +970,894 @@ func (cfg Config) checkValidConcurrency() error {
 	return nil
 }
 
-var errInvalidRPS = errors.New("you must specify an amount of req/s (-r/--rps) higher than zero")
+var errInvalidConcurrencyPerHost = errors.New("the concurrency per host (-cph/--concurrency-per-host) cannot be negative")
 
-func (cfg Config) checkValidRPS() error {
-	if !(cfg.Rps > 0) {
-		return errInvalidRPS
+func (cfg Config) checkValidConcurrencyPerHost() error {
+	if cfg.ConcurrencyPerHost < 0 {
+		return errInvalidConcurrencyPerHost
 	}
 
 	return nil
 }
 
-func (cfg Config) checkValidUrls() error {
-	if len(cfg.URLS) == 0 {
-		return nil
-	}
+var errInvalidTimeBudgetPerHost = errors.New("the time budget per host (--time-budget-per-host) cannot be negative")
 
-	for idx := range cfg.URLS {
-		err := url.Validate(&cfg.URLS[idx])
-		if err != nil {
-			return err
-		}
+func (cfg Config) checkValidTimeBudgetPerHost() error {
+	if cfg.TimeBudgetPerHost < 0 {
+		return errInvalidTimeBudgetPerHost
 	}
 
 	return nil
 }
 
-var errMissingOutputForAllFlags = errors.New("to include all requests and/or all responses within results, you must specify an output file path (-o/--output <path>)")
+var errInvalidRPS = errors.New("you must specify an amount of req/s (-r/--rps) higher than zero")
 
-func (cfg Config) checkOutputForAnyAllFlag() error {
-	if (cfg.ShowAll || cfg.ShowAllRequests || cfg.ShowAllResponses) && len(cfg.OutPath) == 0 {
-		return errMissingOutputForAllFlags
+func (cfg Config) checkValidShard() error {
+	if _, err := cfg.ScanShard(); err != nil {
+		return fmt.Errorf(`the provided shard is invalid: %s`, err.Error()) //nolint:err113
 	}
 	return nil
 }
 
-func (cfg Config) checkValidOutput() error {
-	if len(cfg.OutPath) == 0 {
+const (
+	defaultAdaptiveTargetLatency = time.Second
+	defaultAdaptiveMinRps        = 1
+)
+
+var (
+	errAdaptiveThrottleDisabled = errors.New("the adaptive throttle options (--adaptive-*) require the adaptive throttle (--adaptive-throttle) to be enabled")
+	errInvalidAdaptiveLatency   = errors.New("the adaptive target latency (--adaptive-target-latency) must be higher than zero")
+	errInvalidAdaptiveMinRPS    = errors.New("the adaptive minimum req/s (--adaptive-min-rps) must be higher than zero")
+	errInvalidAdaptiveMaxRPS    = errors.New("the adaptive maximum req/s (--adaptive-max-rps) cannot be lower than the minimum (--adaptive-min-rps)")
+)
+
+func (cfg Config) checkValidAdaptiveThrottle() error {
+	if !cfg.AdaptiveThrottle {
+		if cfg.AdaptiveTargetLatency != defaultAdaptiveTargetLatency ||
+			cfg.AdaptiveMinRps != defaultAdaptiveMinRps || cfg.AdaptiveMaxRps != 0 {
+			return errAdaptiveThrottleDisabled
+		}
+
+		return nil
+	}
+
+	if cfg.AdaptiveTargetLatency <= 0 {
+		return errInvalidAdaptiveLatency
+	}
+
+	if cfg.AdaptiveMinRps < 1 {
+		return errInvalidAdaptiveMinRPS
+	}
+
+	if cfg.AdaptiveMaxRps != 0 && cfg.AdaptiveMaxRps < cfg.AdaptiveMinRps {
+		return errInvalidAdaptiveMaxRPS
+	}
+
+	return nil
+}
+
+// AdaptiveMaxRPS returns the maximum amount of requests per second per host, when
+// the adaptive throttle is enabled, which defaults to [Config.Rps] if not specified.
+func (cfg Config) AdaptiveMaxRPS() int {
+	if cfg.AdaptiveMaxRps > 0 {
+		return cfg.AdaptiveMaxRps
+	}
+
+	return max(cfg.Rps, cfg.AdaptiveMinRps)
+}
+
+var errUnixSocketIncompatibility = errors.New("the unix socket (--unix-socket) cannot be used in combination with a proxy (--proxy-address/--proxy-auth)")
+
+func (cfg Config) checkValidUnixSocket() error {
+	if len(cfg.UnixSocket) == 0 {
 		return nil
 	}
 
-	f, err := os.Create(cfg.OutPath)
+	if len(cfg.ProxyAddress) > 0 || len(cfg.ProxyAuth) > 0 {
+		return errUnixSocketIncompatibility
+	}
+
+	info, err := os.Stat(cfg.UnixSocket)
 	if err != nil {
-		var pathErr *os.PathError
-		if errors.As(err, &pathErr) {
-			return fmt.Errorf(`invalid output path: "%s" - %s`, cfg.OutPath, pathErr.Err) //nolint:err113,errorlint
-		}
-		return fmt.Errorf(`invalid output path: "%s" - %s`, cfg.OutPath, err) //nolint:err113,errorlint
+		return fmt.Errorf(`the provided unix socket is invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf(`the provided unix socket is invalid: %s is not a socket`, cfg.UnixSocket) //nolint:err113
 	}
 
-	f.Close()
 	return nil
 }
 
 var (
-	errMissingParamsFileForParamsSplit    = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters split (-ps/--params-split)")
-	errMissingParamsFileForParamsMethod   = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters method (-pm/--params-method)")
-	errMissingParamsFileForParamsEncoding = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters method (-pe/--params-encoding)")
+	errInvalidDNSCacheTTL      = errors.New("the dns cache ttl (--dns-cache-ttl) cannot be negative")
+	errDNSCacheIncompatibility = errors.New("the dns cache (--dns-cache-ttl/--dns-pin) cannot be used in combination with a proxy (--proxy-address) or a unix socket (--unix-socket)")
 )
 
-func (cfg Config) checkValidParamsFlag() error {
-	// Case insensitiveness
-	cfg.ParamsMethod = strings.ToUpper(cfg.ParamsMethod)
-	cfg.ParamsEncoding = strings.ToLower(cfg.ParamsEncoding)
+func (cfg Config) checkValidDNSCache() error {
+	if cfg.DNSCacheTTL < 0 {
+		return errInvalidDNSCacheTTL
+	}
 
-	// No params file defined
-	if len(cfg.ParamsFile) == 0 {
-		// No default (10), therefore explicitly defined
-		if cfg.ParamsSplit != defaultParamsSplit {
-			return errMissingParamsFileForParamsSplit
-		}
-		// No default (GET), therefore explicitly defined
-		if cfg.ParamsMethod != defaultParamsMethod {
-			return errMissingParamsFileForParamsMethod
-		}
-		// No default (url), therefore explicitly defined
-		if cfg.ParamsEncoding != defaultParamsEncode {
-			return errMissingParamsFileForParamsEncoding
-		}
-		// All defaults, nothing to check
-		return nil
+	if (cfg.DNSCacheTTL > 0 || cfg.DNSPin) && (len(cfg.ProxyAddress) > 0 || len(cfg.UnixSocket) > 0) {
+		return errDNSCacheIncompatibility
 	}
 
-	// Not any of the supported values
-	if cfg.ParamsMethod != http.MethodGet && cfg.ParamsMethod != http.MethodPost {
-		return fmt.Errorf(`the provided parameters method (-pm/--params-method) is invalid: "%s" - only "GET and "POST" are supported"`, cfg.ParamsMethod) //nolint:err113
+	return nil
+}
+
+// warmupDNSCacheTTL is the ttl of the addresses cached when warmup is
+// enabled (see [Config.Warmup]), but the dns cache isn't (see [Config.DNSCache]).
+const warmupDNSCacheTTL = 10 * time.Minute
+
+// DNSCache returns the ttl of the addresses resolved for each host, and whether these are
+// pinned (see [Config.DNSCacheTTL] and [Config.DNSPin]). If warmup is enabled, but the dns
+// cache isn't, addresses are cached for [warmupDNSCacheTTL], as the DNS resolution is what
+// the warmup primes. Unless hosts aren't resolved by gbounty (e.g. through a proxy).
+func (cfg Config) DNSCache() (time.Duration, bool) {
+	if cfg.DNSCacheTTL > 0 || cfg.DNSPin || !cfg.Warmup {
+		return cfg.DNSCacheTTL, cfg.DNSPin
 	}
 
-	// Not any of the supported values
-	if cfg.ParamsEncoding != "url" && cfg.ParamsEncoding != "json" {
-		return fmt.Errorf(`the provided parameters encoding (-pe/--params-encoding) is invalid: "%s" - only "url and "json" are supported"`, cfg.ParamsMethod) //nolint:err113
+	if len(cfg.ProxyAddress) > 0 || len(cfg.UnixSocket) > 0 || len(cfg.ResolveAllTo) > 0 {
+		return 0, false
 	}
 
-	info, err := os.Stat(cfg.ParamsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf(`the provided parameters file does not exist: "%s"`, cfg.ParamsFile) //nolint:err113
-		}
+	return warmupDNSCacheTTL, false
+}
 
-		return fmt.Errorf(`the provided parameters file is invalid: "%s" - %s`, cfg.OutPath, err.Error()) //nolint:err113
+var errResolveAllToIncompatibility = errors.New("the sinkhole address (--resolve-all-to) cannot be used in combination with a proxy (--proxy-address), a unix socket (--unix-socket) or the dns cache (--dns-cache-ttl/--dns-pin)")
+
+func (cfg Config) checkValidResolveAllTo() error {
+	if len(cfg.ResolveAllTo) == 0 {
+		return nil
 	}
 
-	if info.IsDir() {
-		return fmt.Errorf(`the provided parameters file is a directory: "%s"`, cfg.ParamsFile) //nolint:err113
+	if len(cfg.ProxyAddress) > 0 || len(cfg.UnixSocket) > 0 || cfg.DNSCacheTTL > 0 || cfg.DNSPin {
+		return errResolveAllToIncompatibility
+	}
+
+	host, port, err := net.SplitHostPort(cfg.ResolveAllTo)
+	if err != nil {
+		return fmt.Errorf(`the provided sinkhole address (--resolve-all-to) is invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || len(host) == 0 {
+		return fmt.Errorf(`the provided sinkhole address (--resolve-all-to) is invalid: "%s", expected: host:port`, cfg.ResolveAllTo) //nolint:err113
 	}
 
 	return nil
 }
 
-func (cfg Config) checkInteractionHostIsValid() error {
-	if len(cfg.BlindHost) > 0 {
-		_, err := blindhost.NewClient(cfg.BlindHost)
-		if err != nil {
-			return err
-		}
+func (cfg Config) checkValidReplayProxy() error {
+	if _, err := cfg.ReplayProxyAddress(); err != nil {
+		return fmt.Errorf(`the provided replay proxy (--replay-proxy) is invalid: %s`, err.Error()) //nolint:err113
 	}
 	return nil
 }
 
-func (cfg Config) rawURLSAndFileDefined() bool {
-	return cfg.rawURLSDefined() && cfg.eitherFileDefined()
+func (cfg Config) checkValidResponseDiff() error {
+	if _, err := cfg.ResponseDiff(); err != nil {
+		return fmt.Errorf(`the provided response diff variants are invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
 }
 
-func (cfg Config) noEntriesDefined() bool {
-	return !cfg.eitherFileDefined() && !cfg.rawURLSDefined()
+func (cfg Config) checkValidRateLimit() error {
+	if _, err := cfg.RateLimit(); err != nil {
+		return fmt.Errorf(`the provided rate limit probe is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
 }
 
-func (cfg Config) eitherFileDefined() bool {
-	return cfg.urlsFileDefined() || cfg.requestsFileDefined() || cfg.rawRequestsFilesDefined()
+func (cfg Config) checkValidCachePoisoning() error {
+	if _, err := cfg.CachePoisoningProbe(); err != nil {
+		return fmt.Errorf(`the provided cache poisoning probe is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
 }
 
-func (cfg Config) multipleFilesDefined() bool {
-	return (cfg.urlsFileDefined() && cfg.requestsFileDefined() && cfg.rawRequestsFilesDefined()) ||
-		(cfg.urlsFileDefined() && cfg.requestsFileDefined()) ||
-		(cfg.urlsFileDefined() && cfg.rawRequestsFilesDefined()) ||
-		(cfg.requestsFileDefined() && cfg.rawRequestsFilesDefined())
-}
+func (cfg Config) checkValidMassAssignment() error {
+	if _, err := cfg.MassAssignmentProbe(); err != nil {
+		return fmt.Errorf(`the provided mass assignment probe is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidCABundle() error {
+	if _, err := cfg.RootCAs(); err != nil {
+		return fmt.Errorf(`the provided ca bundle is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidRPS() error {
+	if !(cfg.Rps > 0) {
+		return errInvalidRPS
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidUrls() error {
+	if len(cfg.URLS) == 0 {
+		return nil
+	}
+
+	// Variables (if any) are expanded before validating,
+	// as those are expanded before building the templates.
+	vars, _ := cfg.Variables()
+
+	for idx := range cfg.URLS {
+		expanded := dotenv.Expand(cfg.URLS[idx], vars)
+		err := url.Validate(&expanded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	errPortsWithoutCIDR    = errors.New("the ports (--ports) can only be used in combination with CIDR range(s) (--cidr)")
+	errNegativeMaxCIDRURLs = errors.New("the maximum amount of cidr urls (--max-cidr-urls) cannot be negative")
+)
+
+func (cfg Config) checkValidCIDRs() error {
+	if !cfg.cidrsDefined() {
+		if len(cfg.Ports) > 0 {
+			return errPortsWithoutCIDR
+		}
+		return nil
+	}
+
+	if cfg.MaxCIDRURLs < 0 {
+		return errNegativeMaxCIDRURLs
+	}
+
+	if _, _, _, err := cfg.cidrRanges(); err != nil {
+		if errors.Is(err, errCIDRTooLarge) {
+			return err
+		}
+		return fmt.Errorf(`the provided cidr range(s) are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errMissingOutputForAllFlags = errors.New("to include all requests and/or all responses within results, you must specify an output file path (-o/--output <path>)")
+
+func (cfg Config) checkOutputForAnyAllFlag() error {
+	if (cfg.ShowAll || cfg.ShowAllRequests || cfg.ShowAllResponses) && len(cfg.OutPaths) == 0 {
+		return errMissingOutputForAllFlags
+	}
+	return nil
+}
+
+var (
+	errMissingOutputForAppend = errors.New("to append (--output-append) or overwrite (--force) the output, you must specify an output file path (-o/--output <path>)")
+	errOutputAlreadyExists    = errors.New("the output file already exists, use --output-append to append to it, or --force to overwrite it")
+	errDuplicatedOutput       = errors.New("the output file path is specified more than once")
+)
+
+func (cfg Config) checkValidOutput() error {
+	if len(cfg.OutPaths) == 0 {
+		if cfg.OutAppend || cfg.Force {
+			return errMissingOutputForAppend
+		}
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(cfg.OutPaths))
+	for _, path := range cfg.OutPaths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return invalidOutputPath(path, err)
+		}
+
+		if _, ok := seen[abs]; ok {
+			return fmt.Errorf(`%w: "%s"`, errDuplicatedOutput, path)
+		}
+		seen[abs] = struct{}{}
+
+		if err := cfg.checkValidOutputPath(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidOutputPath(path string) error {
+	// The existing output file, if any, must be kept untouched
+	// (i.e. not truncated), as it might be appended to.
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf(`invalid output path: "%s" - is a directory`, path) //nolint:err113
+	case err == nil && !cfg.OutAppend && !cfg.Force:
+		return fmt.Errorf(`%w: "%s"`, errOutputAlreadyExists, path)
+	case err == nil:
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return invalidOutputPath(path, err)
+		}
+		f.Close()
+	default:
+		f, err := os.Create(path)
+		if err != nil {
+			return invalidOutputPath(path, err)
+		}
+		f.Close()
+
+		// The output file is created once the scan finishes,
+		// so it is never left empty if the scan fails.
+		_ = os.Remove(path)
+	}
+
+	return nil
+}
+
+func invalidOutputPath(path string, err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return fmt.Errorf(`invalid output path: "%s" - %s`, path, pathErr.Err) //nolint:err113,errorlint
+	}
+	return fmt.Errorf(`invalid output path: "%s" - %s`, path, err) //nolint:err113,errorlint
+}
+
+var errOutputFormatsMismatch = errors.New("the output formats (--output-format) must be either one for all the outputs, or one per output (-o/--output), in the same order")
+
+func (cfg Config) checkValidOutputFormats() error {
+	for _, format := range cfg.OutFormats {
+		if !isOutputFormat(format) {
+			return fmt.Errorf(`invalid output format: "%s", must be one of: %s`, format, strings.Join(outputFormats, ", ")) //nolint:err113
+		}
+	}
+
+	if len(cfg.OutFormats) > 1 && len(cfg.OutFormats) != len(cfg.OutPaths) {
+		return errOutputFormatsMismatch
+	}
+
+	return nil
+}
+
+var (
+	errMissingOutputTemplate       = errors.New("to use the template output format, you must specify a template file (--output-template <path>)")
+	errOutputTemplateWithoutFormat = errors.New("the output template (--output-template) can only be used with the template output format (--output-format template)")
+	errMissingOutputForTemplate    = errors.New("to use an output template, you must specify an output file path (-o/--output <path>)")
+)
+
+func (cfg Config) checkValidOutputTemplate() error {
+	if len(cfg.OutPaths) == 0 {
+		if cfg.OutFormat == "template" || slices.Contains(cfg.OutFormats, "template") {
+			return errMissingOutputForTemplate
+		}
+		return nil
+	}
+
+	var withTemplate bool
+	for _, out := range cfg.Outputs() {
+		withTemplate = withTemplate || out.Format == "template"
+	}
+
+	switch {
+	case !withTemplate && len(cfg.OutTemplate) > 0:
+		return errOutputTemplateWithoutFormat
+	case !withTemplate:
+		return nil
+	case len(cfg.OutTemplate) == 0:
+		return errMissingOutputTemplate
+	}
+
+	if _, err := writer.ParseTemplate(cfg.OutTemplate); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var errMissingBaselineForFailOnNew = errors.New("you must specify a baseline (--baseline <path>) to make use of the fail on new findings (--fail-on-new)")
+
+func (cfg Config) checkValidBaseline() error {
+	if len(cfg.Baseline) == 0 {
+		if cfg.FailOnNew {
+			return errMissingBaselineForFailOnNew
+		}
+		return nil
+	}
+
+	if _, err := writer.ReadBaseline(cfg.Baseline); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var (
+	errMissingParamsFileForParamsSplit    = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters split (-ps/--params-split)")
+	errMissingParamsFileForParamsMethod   = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters method (-pm/--params-method)")
+	errMissingParamsFileForParamsEncoding = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the parameters method (-pe/--params-encoding)")
+	errMissingParamsFileForMaxVariants    = errors.New("you must specify a parameters file (with -pf/--params-file) to make use of the max template variants (--max-template-variants)")
+	errInvalidMaxTemplateVariants         = errors.New("the max template variants (--max-template-variants) cannot be negative")
+)
+
+func (cfg Config) checkValidParamsFlag() error {
+	// Case insensitiveness
+	cfg.ParamsMethod = strings.ToUpper(cfg.ParamsMethod)
+	cfg.ParamsEncoding = strings.ToLower(cfg.ParamsEncoding)
+
+	// No params file defined
+	if len(cfg.ParamsFile) == 0 {
+		// No default (10), therefore explicitly defined
+		if cfg.ParamsSplit != defaultParamsSplit {
+			return errMissingParamsFileForParamsSplit
+		}
+		// No default (GET), therefore explicitly defined
+		if cfg.ParamsMethod != defaultParamsMethod {
+			return errMissingParamsFileForParamsMethod
+		}
+		// No default (url), therefore explicitly defined
+		if cfg.ParamsEncoding != defaultParamsEncode {
+			return errMissingParamsFileForParamsEncoding
+		}
+		// No default (0), therefore explicitly defined
+		if cfg.MaxTemplateVariants != 0 {
+			return errMissingParamsFileForMaxVariants
+		}
+		// All defaults, nothing to check
+		return nil
+	}
+
+	if cfg.MaxTemplateVariants < 0 {
+		return errInvalidMaxTemplateVariants
+	}
+
+	// Not any of the supported values
+	if cfg.ParamsMethod != http.MethodGet && cfg.ParamsMethod != http.MethodPost {
+		return fmt.Errorf(`the provided parameters method (-pm/--params-method) is invalid: "%s" - only "GET and "POST" are supported"`, cfg.ParamsMethod) //nolint:err113
+	}
+
+	// Not any of the supported values
+	if cfg.ParamsEncoding != "url" && cfg.ParamsEncoding != "json" {
+		return fmt.Errorf(`the provided parameters encoding (-pe/--params-encoding) is invalid: "%s" - only "url and "json" are supported"`, cfg.ParamsMethod) //nolint:err113
+	}
+
+	info, err := os.Stat(cfg.ParamsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf(`the provided parameters file does not exist: "%s"`, cfg.ParamsFile) //nolint:err113
+		}
+
+		return fmt.Errorf(`the provided parameters file is invalid: "%s" - %s`, cfg.ParamsFile, err.Error()) //nolint:err113
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf(`the provided parameters file is a directory: "%s"`, cfg.ParamsFile) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidEnvFile() error {
+	if len(cfg.EnvFile) == 0 {
+		if cfg.EnvFilePriority {
+			return errMissingEnvFileForPriority
+		}
+		return nil
+	}
+
+	if _, err := dotenv.ParseFile(cfg.EnvFile); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf(`the provided env file does not exist: "%s"`, cfg.EnvFile) //nolint:err113
+		}
+
+		return fmt.Errorf(`the provided env file is invalid: "%s" - %s`, cfg.EnvFile, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidPriorities() error {
+	if _, err := cfg.priorityRules(); err != nil {
+		return fmt.Errorf(`the provided priority rule is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+var errInvalidProfileTimeout = errors.New("the profile timeout (--profile-timeout) cannot be negative")
+
+func (cfg Config) checkValidProfileTimeout() error {
+	if cfg.ProfileTimeout < 0 {
+		return errInvalidProfileTimeout
+	}
+
+	return nil
+}
+
+var errInvalidScanTimeout = errors.New("the scan timeout (--scan-timeout) cannot be negative")
+
+func (cfg Config) checkValidScanTimeout() error {
+	if cfg.ScanTimeout < 0 {
+		return errInvalidScanTimeout
+	}
+	return nil
+}
+
+var errMaxErrorsIncompatibility = errors.New("you cannot use --max-errors in combination with -sos/--save-on-stop, as aborted scans aren't meant to be continued")
+
+func (cfg Config) checkValidMaxErrors() error {
+	threshold, err := cfg.ErrorThreshold()
+	if err != nil {
+		return fmt.Errorf(`the provided max errors are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if threshold.Enabled() && cfg.SaveOnStop {
+		return errMaxErrorsIncompatibility
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidMetadata() error {
+	if _, err := cfg.MetadataPairs(); err != nil {
+		return fmt.Errorf(`the provided metadata is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidMimeFilter() error {
+	if _, err := cfg.MimeFilter(); err != nil {
+		return fmt.Errorf(`the provided mime filter is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidFindingsGrouping() error {
+	if _, err := cfg.FindingsGrouping(); err != nil {
+		return fmt.Errorf(`the provided findings grouping (--group-findings) is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidRedaction() error {
+	if _, err := cfg.Redaction(); err != nil {
+		return fmt.Errorf(`the provided redaction is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidResponseFilter() error {
+	if _, err := cfg.ResponseFilter(); err != nil {
+		return fmt.Errorf(`the provided response filter is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidURLFilter() error {
+	if _, err := cfg.urlFilter(); err != nil {
+		return fmt.Errorf(`the provided url filter is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+var errHeaderOrderWithJitterHeaders = errors.New("the header order (--header-order) cannot be fixed when the headers are jittered (--jitter-headers)")
+
+func (cfg Config) checkValidHeaderOrder() error {
+	if _, err := cfg.HeaderOrderKeys(); err != nil {
+		return fmt.Errorf(`the provided header order is invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if len(strings.TrimSpace(cfg.HeaderOrder)) > 0 && cfg.JitterHeaders {
+		return errHeaderOrderWithJitterHeaders
+	}
+	return nil
+}
+
+func (cfg Config) checkValidRemoveHeaders() error {
+	if _, err := cfg.RemovedHeaders(); err != nil {
+		return fmt.Errorf(`the provided headers to remove are invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidRequestMutators() error {
+	if _, err := cfg.Mutators(); err != nil {
+		return fmt.Errorf(`the provided request mutators are invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidHeaderPropagations() error {
+	if _, err := cfg.HeaderPropagations(); err != nil {
+		return fmt.Errorf(`the provided header propagation rules are invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidCORSOrigin() error {
+	if len(cfg.CORSOrigin) == 0 {
+		return nil
+	}
+
+	u, err := stdurl.Parse(cfg.CORSOrigin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 ||
+		len(strings.Trim(u.Path, "/")) > 0 || len(u.RawQuery) > 0 || len(u.Fragment) > 0 || u.User != nil {
+		return fmt.Errorf(`the provided cors origin is invalid, it must be like https://evil.example: "%s"`, cfg.CORSOrigin) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidRequestID() error {
+	if len(cfg.RequestIDHeader) > 0 &&
+		(strings.IndexFunc(cfg.RequestIDHeader, unicode.IsSpace) >= 0 || strings.Contains(cfg.RequestIDHeader, ":")) {
+		return fmt.Errorf(`the provided request id header is invalid: "%s"`, cfg.RequestIDHeader) //nolint:err113
+	}
+
+	if _, err := scan.RequestIDGeneratorFrom(cfg.RequestIDGenerator); err != nil {
+		return fmt.Errorf(`the provided request id generator is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidCanaryPrefix() error {
+	if len(cfg.CanaryPrefix) == 0 {
+		return nil
+	}
+
+	if err := scan.ValidateCanaryPrefix(cfg.CanaryPrefix); err != nil {
+		return fmt.Errorf(`the provided canary prefix (--canary-prefix) is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidHTTPVersion() error {
+	if _, err := cfg.RequestLineProto(); err != nil {
+		return fmt.Errorf(`the provided http version is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+var errPreserveLineEndingsWithoutRawRequests = errors.New("the original line endings (--preserve-line-endings) can only be kept for raw request files (--raw-request)")
+
+func (cfg Config) checkValidPreserveLineEndings() error {
+	if cfg.PreserveLineEndings && len(cfg.RawRequests) == 0 {
+		return errPreserveLineEndingsWithoutRawRequests
+	}
+	return nil
+}
+
+var errHTTP2Incompatibility = errors.New("--http2 (and --http2-prior-knowledge) cannot be used in combination with --http-version, --allow-raw-headers, --preserve-line-endings, --auth, --request-mutators or --jitter-headers")
+
+func (cfg Config) checkHTTP2Incompatibility() error {
+	if (cfg.HTTP2 || cfg.HTTP2PriorKnowledge) && (len(cfg.HTTPVersion) > 0 || cfg.AllowRawHeaders || cfg.PreserveLineEndings || len(cfg.Auth) > 0 || len(cfg.RequestMutators) > 0 || cfg.JitterHeaders) {
+		return errHTTP2Incompatibility
+	}
+	return nil
+}
+
+var errWebSocketIncompatibility = errors.New("--websocket cannot be used in combination with --http2, --http2-prior-knowledge or --http-version")
+
+func (cfg Config) checkValidWebSocket() error {
+	if !cfg.WebSocket {
+		if len(cfg.WebSocketProtocols) > 0 {
+			return errWebSocketProtocolsWithoutWebSocket
+		}
+		return nil
+	}
+
+	if cfg.HTTP2 || cfg.HTTP2PriorKnowledge || len(cfg.HTTPVersion) > 0 {
+		return errWebSocketIncompatibility
+	}
+
+	if _, err := cfg.WebSocketSubprotocols(); err != nil {
+		return fmt.Errorf(`the provided websocket protocols are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errGraphQLIntrospectionIncompatibility = errors.New("--graphql-introspection cannot be used in combination with --websocket")
+
+func (cfg Config) checkGraphQLIntrospectionIncompatibility() error {
+	if cfg.GraphQLIntrospection && cfg.WebSocket {
+		return errGraphQLIntrospectionIncompatibility
+	}
+	return nil
+}
+
+func (cfg Config) checkValidAuth() error {
+	if _, err := cfg.NTLMCredentials(); err != nil {
+		return fmt.Errorf(`the provided auth is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+func (cfg Config) checkValidSigV4() error {
+	if _, err := cfg.SigV4Signer(); err != nil {
+		return fmt.Errorf(`the provided aws sigv4 configuration is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+var (
+	errDiscoveryOptionsWithoutDiscover = errors.New("you must enable content discovery (--discover) to make use of --wordlist, --extensions, --match-status or --filter-size")
+	errMissingWordlist                 = errors.New("you must specify a wordlist (-w/--wordlist), or probe the debug paths (--debug-paths), to make use of content discovery (--discover)")
+	errDebugPathsWithoutDiscover       = errors.New("you must enable content discovery (--discover) to make use of --debug-paths")
+	errDiscoveryRequiresURLs           = errors.New("you must specify URL(s) (with -u/--url, or with -uf/--urls-file) to make use of content discovery (--discover)")
+)
+
+func (cfg Config) checkDiscoveryIncompatibility() error {
+	if !cfg.Discover {
+		if len(cfg.Wordlist) > 0 || len(cfg.Extensions) > 0 || len(cfg.MatchStatus) > 0 || len(cfg.FilterSize) > 0 {
+			return errDiscoveryOptionsWithoutDiscover
+		}
+		if cfg.ProbeDebugPaths {
+			return errDebugPathsWithoutDiscover
+		}
+		return nil
+	}
+
+	if len(cfg.Wordlist) == 0 && !cfg.ProbeDebugPaths {
+		return errMissingWordlist
+	}
+
+	if cfg.requestsFileDefined() || cfg.rawRequestsFilesDefined() || cfg.csvFileDefined() || cfg.pcapFileDefined() {
+		return errDiscoveryRequiresURLs
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidDiscovery() error {
+	if !cfg.Discover {
+		return nil
+	}
+
+	if len(cfg.Wordlist) > 0 {
+		if _, err := os.Stat(cfg.Wordlist); err != nil {
+			return fmt.Errorf(`the provided wordlist does not exist: "%s"`, cfg.Wordlist) //nolint:err113
+		}
+	}
+
+	if _, err := cfg.DiscoveryExtensions(); err != nil {
+		return fmt.Errorf(`the provided extensions are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if _, err := cfg.DiscoveryProfile(); err != nil {
+		return fmt.Errorf(`the provided discovery matchers are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errExposuresWithoutDiscoverOrPassive = errors.New("you must enable either content discovery (--discover) or passive scan (--passive-scan) to make use of --exposures")
+
+func (cfg Config) checkValidExposures() error {
+	if len(cfg.Exposures) == 0 {
+		return nil
+	}
+
+	if !cfg.Discover && !cfg.Passive {
+		return errExposuresWithoutDiscoverOrPassive
+	}
+
+	if _, err := cfg.ExposureProfile(); err != nil {
+		return fmt.Errorf(`the provided exposures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errSensitiveDataFileWithoutSensitiveData = errors.New("the sensitive data file (--sensitive-data-file) can only be used in combination with --sensitive-data")
+
+func (cfg Config) checkValidSensitiveData() error {
+	if len(cfg.SensitiveData) == 0 {
+		if len(cfg.SensitiveDataFile) > 0 {
+			return errSensitiveDataFileWithoutSensitiveData
+		}
+		return nil
+	}
+
+	if _, err := cfg.SensitiveDataProfile(); err != nil {
+		return fmt.Errorf(`the provided sensitive data signatures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidFileSignatures() error {
+	if _, err := cfg.FileSignatures(); err != nil {
+		return fmt.Errorf(`the provided file signatures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidCloudSignatures() error {
+	if _, err := cfg.CloudSignatures(); err != nil {
+		return fmt.Errorf(`the provided cloud signatures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errDebugExposuresWithoutDiscoverOrPassive = errors.New("you must enable either content discovery (--discover) or passive scan (--passive-scan) to make use of --debug-exposures")
+
+func (cfg Config) checkValidDebugSignatures() error {
+	if _, err := cfg.DebugSignatures(); err != nil {
+		return fmt.Errorf(`the provided debug signatures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	if len(cfg.DebugExposures) > 0 && !cfg.Discover && !cfg.Passive {
+		return errDebugExposuresWithoutDiscoverOrPassive
+	}
+
+	if _, err := cfg.DebugExposureProfile(); err != nil {
+		return fmt.Errorf(`the provided debug exposures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidTechnologySignatures() error {
+	if _, err := cfg.TechnologySignatures(); err != nil {
+		return fmt.Errorf(`the provided technology signatures are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidSeverityOverrides() error {
+	if _, err := cfg.SeverityOverrides(); err != nil {
+		return fmt.Errorf(`the provided severity overrides are invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+func (cfg Config) checkValidLoginSequence() error {
+	if _, err := cfg.LoginSequence(); err != nil {
+		return fmt.Errorf(`the provided login sequence is invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
+
+var errMissingEnvFileForPriority = errors.New("you must specify an env file (with --env-file) to make use of the env file priority (--env-file-priority)")
+
+func (cfg Config) checkInteractionHostIsValid() error {
+	if len(cfg.BlindHost) > 0 {
+		_, err := blindhost.NewClient(cfg.BlindHost)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg Config) rawURLSAndFileDefined() bool {
+	return cfg.rawURLSDefined() && cfg.eitherFileDefined()
+}
+
+func (cfg Config) noEntriesDefined() bool {
+	return !cfg.eitherFileDefined() && !cfg.rawURLSDefined()
+}
+
+func (cfg Config) eitherFileDefined() bool {
+	return cfg.urlsFileDefined() || cfg.Stream || cfg.requestsFileDefined() || cfg.rawRequestsFilesDefined() || cfg.csvFileDefined() || cfg.pcapFileDefined()
+}
+
+func (cfg Config) multipleFilesDefined() bool {
+	var defined int
+	for _, d := range []bool{cfg.urlsFileDefined(), cfg.Stream, cfg.requestsFileDefined(), cfg.rawRequestsFilesDefined(), cfg.csvFileDefined(), cfg.pcapFileDefined()} {
+		if d {
+			defined++
+		}
+	}
+	return defined > 1
+}
 
 func (cfg Config) urlsFileDefined() bool {
 	return len(cfg.UrlsFile) > 0
@@ -393,8 +1871,20 @@ func (cfg Config) rawRequestsFilesDefined() bool {
 	return len(cfg.RawRequests) > 0
 }
 
+func (cfg Config) csvFileDefined() bool {
+	return len(cfg.CSVFile) > 0
+}
+
+func (cfg Config) pcapFileDefined() bool {
+	return len(cfg.PCAPFile) > 0
+}
+
 func (cfg Config) rawURLSDefined() bool {
-	return len(cfg.URLS) > 0
+	return len(cfg.URLS) > 0 || cfg.cidrsDefined()
+}
+
+func (cfg Config) cidrsDefined() bool {
+	return len(cfg.CIDRs) > 0
 }
 
 func (cfg Config) requestOptsDefined() bool {
@@ -0,0 +1,107 @@
+//nolint:testpackage
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_checkValidOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "existing.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("previous output"), 0o600))
+
+	missing := filepath.Join(dir, "missing.txt")
+
+	// Once all checked, neither the existing output is truncated, nor the missing one is left created.
+	t.Cleanup(func() {
+		b, err := os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "previous output", string(b))
+
+		_, err = os.Stat(missing)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	tcs := map[string]struct {
+		cfg Config
+		err error
+	}{
+		"no output":                   {cfg: Config{}},
+		"append without output":       {cfg: Config{OutAppend: true}, err: errMissingOutputForAppend},
+		"force without output":        {cfg: Config{Force: true}, err: errMissingOutputForAppend},
+		"missing output":              {cfg: Config{OutPaths: []string{missing}}},
+		"existing output":             {cfg: Config{OutPaths: []string{existing}}, err: errOutputAlreadyExists},
+		"existing output, forced":     {cfg: Config{OutPaths: []string{existing}, Force: true}},
+		"existing output, appended":   {cfg: Config{OutPaths: []string{existing}, OutAppend: true}},
+		"any existing output":         {cfg: Config{OutPaths: []string{missing, existing}}, err: errOutputAlreadyExists},
+		"duplicated output":           {cfg: Config{OutPaths: []string{missing, missing}}, err: errDuplicatedOutput},
+		"duplicated output, relative": {cfg: Config{OutPaths: []string{existing, filepath.Join(dir, ".", "existing.txt")}, Force: true}, err: errDuplicatedOutput},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.cfg.checkValidOutput()
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfig_checkValidOutput_Directory(t *testing.T) {
+	t.Parallel()
+
+	err := Config{OutPaths: []string{t.TempDir()}, Force: true}.checkValidOutput()
+	require.ErrorContains(t, err, "is a directory")
+}
+
+func TestConfig_checkValidCORSOrigin(t *testing.T) {
+	t.Parallel()
+
+	for _, valid := range []string{"", "https://evil.example", "http://evil.example:8080", "https://evil.example/"} {
+		require.NoError(t, Config{CORSOrigin: valid}.checkValidCORSOrigin(), valid)
+	}
+
+	for _, invalid := range []string{"evil.example", "ftp://evil.example", "https://", "https://evil.example/path", "https://evil.example?q=1", "https://user@evil.example"} {
+		require.Error(t, Config{CORSOrigin: invalid}.checkValidCORSOrigin(), invalid)
+	}
+}
+
+func TestConfig_DNSCache(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		cfg Config
+		ttl time.Duration
+		pin bool
+	}{
+		"none":                 {cfg: Config{}},
+		"ttl":                  {cfg: Config{DNSCacheTTL: time.Minute}, ttl: time.Minute},
+		"pinned":               {cfg: Config{DNSPin: true}, pin: true},
+		"warmup":               {cfg: Config{Warmup: true}, ttl: warmupDNSCacheTTL},
+		"warmup with ttl":      {cfg: Config{Warmup: true, DNSCacheTTL: time.Minute}, ttl: time.Minute},
+		"warmup through proxy": {cfg: Config{Warmup: true, ProxyAddress: "127.0.0.1:8080"}},
+		"warmup with sinkhole": {cfg: Config{Warmup: true, ResolveAllTo: "127.0.0.1:8080"}},
+	}
+
+	for name, tc := range tcs {
+		ttl, pin := tc.cfg.DNSCache()
+		assert.Equal(t, tc.ttl, ttl, name)
+		assert.Equal(t, tc.pin, pin, name)
+	}
+}
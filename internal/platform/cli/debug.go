@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+)
+
+// DebugExposureProfile returns the [gbprofile.Response] used to report exposed debug surfaces
+// (e.g. Spring Boot's actuator or Go's pprof) during content discovery (see [Config.Discover])
+// and passive scans (see [Config.Passive]), built on top of the Debug Exposure grep, looking
+// for those signatures defined by [Config.DebugExposures] (all, by default).
+//
+// It returns nil if [Config.DebugExposures] is "none", or an error if any of them is invalid.
+func (cfg Config) DebugExposureProfile() (*gbprofile.Response, error) {
+	exposures := strings.TrimSpace(cfg.DebugExposures)
+	if strings.EqualFold(exposures, noExposures) {
+		return nil, nil //nolint:nilnil
+	}
+
+	var names []string
+	if len(exposures) > 0 {
+		for _, name := range strings.Split(exposures, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+
+	prof := &gbprofile.Response{
+		Name:    "Debug Exposure",
+		Enabled: true,
+		Type:    gbprofile.TypePassiveRes,
+		Tags:    []string{"exposure", "debug"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,%s", gbprofile.GrepTypeDebugExposure, strings.Join(names, ";")),
+		},
+		IssueName:       "Debug Exposure",
+		IssueSeverity:   "High",
+		IssueConfidence: "Firm",
+		IssueDetail: "The requested path exposes a debug surface, like Spring Boot's actuator, Go's pprof, " +
+			"phpinfo() or a framework's debug page, which usually leaks configuration, secrets or internals.",
+		RemediationDetail: "Disable debug modes in production, and remove (or deny access to) " +
+			"debug endpoints, like actuators, profilers and diagnostic pages.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+// DebugPaths returns the paths probed during content discovery when [Config.ProbeDebugPaths]
+// is enabled, those of the debug signatures (see [match.DebugPaths]), including the custom
+// ones (see [Config.DebugSignatures]), with no leading slash, so these can be used as words.
+func (cfg Config) DebugPaths() ([]string, error) {
+	if !cfg.ProbeDebugPaths {
+		return nil, nil
+	}
+
+	custom, err := cfg.DebugSignatures()
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, path := range match.DebugPaths(match.MergeDebugSignatures(custom)) {
+		words = append(words, strings.TrimPrefix(path, "/"))
+	}
+
+	return words, nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var (
+	errDiffVariantsWithoutDiff = errors.New("the variants (--diff-a and --diff-b) can only be used in combination with --diff-responses")
+	errDiffWithoutVariants     = errors.New("you must specify at least one variant (--diff-a or --diff-b) to make use of --diff-responses")
+	errDiffVariantsEqual       = errors.New("both variants (--diff-a and --diff-b) are the same")
+	errInvalidDiffVariant      = errors.New("invalid variant, expected: header:Key: Value or payload:value")
+)
+
+// Prefixes of each of the modifications a response diff variant (see [Config.DiffA]) is made of.
+const (
+	diffVariantHeader  = "header:"
+	diffVariantPayload = "payload:"
+)
+
+// ResponseDiff returns the [scan.ResponseDiffCfg] defined by [Config.DiffResponses], with the
+// variants defined by [Config.DiffA] and [Config.DiffB], or an error if any of them is invalid.
+//
+// Each variant is made of headers (e.g. header:X-Role: admin), and up to one payload (e.g.
+// payload:' OR 1=1), which replaces the [scan.VariantPlaceholder] of every request.
+func (cfg Config) ResponseDiff() (scan.ResponseDiffCfg, error) {
+	if !cfg.DiffResponses {
+		if len(cfg.DiffA) > 0 || len(cfg.DiffB) > 0 {
+			return scan.ResponseDiffCfg{}, errDiffVariantsWithoutDiff
+		}
+		return scan.ResponseDiffCfg{}, nil
+	}
+
+	if len(cfg.DiffA) == 0 && len(cfg.DiffB) == 0 {
+		return scan.ResponseDiffCfg{}, errDiffWithoutVariants
+	}
+
+	a, err := parseDiffVariant(cfg.DiffA)
+	if err != nil {
+		return scan.ResponseDiffCfg{}, fmt.Errorf("--diff-a: %w", err)
+	}
+
+	b, err := parseDiffVariant(cfg.DiffB)
+	if err != nil {
+		return scan.ResponseDiffCfg{}, fmt.Errorf("--diff-b: %w", err)
+	}
+
+	if a.Payload == b.Payload && slices.Equal(a.Headers, b.Headers) {
+		return scan.ResponseDiffCfg{}, errDiffVariantsEqual
+	}
+
+	return scan.ResponseDiffCfg{Enabled: true, A: a, B: b}, nil
+}
+
+func parseDiffVariant(values []string) (scan.ResponseVariant, error) {
+	var (
+		variant    scan.ResponseVariant
+		hasPayload bool
+	)
+
+	for _, v := range values {
+		switch {
+		case strings.HasPrefix(v, diffVariantHeader):
+			key, value, found := strings.Cut(strings.TrimPrefix(v, diffVariantHeader), ":")
+			key = strings.TrimSpace(key)
+			if !found || len(key) == 0 || strings.ContainsAny(key, " \t") {
+				return scan.ResponseVariant{}, fmt.Errorf("%w: %s", errInvalidDiffVariant, v)
+			}
+			variant.Headers = append(variant.Headers, [2]string{key, strings.TrimSpace(value)})
+
+		case strings.HasPrefix(v, diffVariantPayload):
+			if hasPayload {
+				return scan.ResponseVariant{}, fmt.Errorf("only one payload allowed per variant: %s", v) //nolint:err113
+			}
+			hasPayload = true
+			variant.Payload = strings.TrimPrefix(v, diffVariantPayload)
+
+		default:
+			return scan.ResponseVariant{}, fmt.Errorf("%w: %s", errInvalidDiffVariant, v)
+		}
+	}
+
+	return variant, nil
+}
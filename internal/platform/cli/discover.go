@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	stdurl "net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/url"
+)
+
+// defaultDiscoveryStatus is the list of status codes considered
+// interesting during content discovery (see [Config.Discover]),
+// when no [Config.MatchStatus] is specified.
+const defaultDiscoveryStatus = "200,204,301,302,307,308,401,403,405,500"
+
+// ErrProcessWordlist is the error returned when [Config] points to a wordlist
+// file (see [Config.Wordlist]), and it could not be processed successfully.
+var ErrProcessWordlist = errors.New("could not process wordlist file")
+
+// DiscoveryProfile returns the [gbprofile.Response] used to report the interesting
+// responses during content discovery (see [Config.Discover]), built on top of the
+// Status Code and Content Length greps, or an error if any of the values
+// defined by [Config.MatchStatus] or [Config.FilterSize] is invalid.
+func (cfg Config) DiscoveryProfile() (*gbprofile.Response, error) {
+	status := cfg.MatchStatus
+	if len(strings.TrimSpace(status)) == 0 {
+		status = defaultDiscoveryStatus
+	}
+
+	codes := strings.Split(status, ",")
+	for i := range codes {
+		codes[i] = strings.TrimSpace(codes[i])
+	}
+
+	greps := []string{fmt.Sprintf("true,,%s,,%s", gbprofile.GrepTypeStatusCode, strings.Join(codes, ";"))}
+
+	if len(strings.TrimSpace(cfg.FilterSize)) > 0 {
+		for _, size := range strings.Split(cfg.FilterSize, ",") {
+			size = strings.TrimSpace(size)
+			if n, err := strconv.Atoi(size); err != nil || n < 0 {
+				return nil, fmt.Errorf(`invalid size: "%s"`, size) //nolint:err113
+			}
+
+			greps = append(greps, fmt.Sprintf("true,%s,%s,,%s", gbprofile.GrepOperatorAndNot, gbprofile.GrepTypeContentLength, size))
+		}
+	}
+
+	prof := &gbprofile.Response{
+		Name:            "Content Discovery",
+		Enabled:         true,
+		Type:            gbprofile.TypePassiveRes,
+		Tags:            []string{"discovery"},
+		Greps:           greps,
+		IssueName:       "Content Discovered",
+		IssueSeverity:   "Information",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The requested resource, built from the wordlist, responded with an interesting status code.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+// DiscoveryExtensions returns the list of extensions defined by [Config.Extensions],
+// with no leading dot, or an error if any of them is invalid.
+//
+// If no [Config.Extensions] is defined, it returns nil.
+func (cfg Config) DiscoveryExtensions() ([]string, error) {
+	if len(strings.TrimSpace(cfg.Extensions)) == 0 {
+		return nil, nil
+	}
+
+	var exts []string
+	for _, ext := range strings.Split(cfg.Extensions, ",") {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if len(ext) == 0 || strings.ContainsAny(ext, "/?# \t") {
+			return nil, fmt.Errorf(`invalid extension: "%s"`, ext) //nolint:err113
+		}
+
+		exts = append(exts, ext)
+	}
+
+	return exts, nil
+}
+
+// createFromWordlist creates (and stores) a template for each combination of the
+// base urls (see [Config.URLS]) and the words read from the [Config.Wordlist], with
+// and without each of the [Config.DiscoveryExtensions]. The wordlist is streamed,
+// line by line, so it is never fully loaded into memory.
+//
+// If [Config.ProbeDebugPaths] is enabled, the debug paths (see [Config.DebugPaths])
+// are requested first, as is (i.e. with no extensions), and the wordlist is optional.
+func createFromWordlist(ctx context.Context, fs scan.FileSystem, cfg Config, options []request.Option, iss *issues) error {
+	// Already validated (see [Config.Validate]).
+	exts, _ := cfg.DiscoveryExtensions()
+	debugPaths, _ := cfg.DebugPaths()
+
+	var bases []string
+	for _, cfgURL := range cfg.URLS {
+		if err := url.Validate(&cfgURL); err != nil { //nolint:gosec,scopelint
+			logger.For(ctx).Errorf("Error while validating url (%s): %s", cfgURL, err.Error())
+
+			if err := iss.report(err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		bases = append(bases, discoveryBase(cfgURL))
+	}
+
+	tplStore := newTemplateStore(ctx, fs)
+	store := func(source string, words []string) error {
+		for _, base := range bases {
+			for _, w := range words {
+				target := base + w
+				if err := url.Validate(&target); err != nil {
+					logger.For(ctx).Warnf("Skipping %s word (%s) - not a valid url: %s", source, w, err.Error())
+					continue
+				}
+
+				tpl := scan.NewTemplate(ctx, tplStore.idx, request.WithOptions(target, options...), nil)
+				if err := tplStore.store(tpl); err != nil {
+					logger.For(ctx).Errorf("Error while building scan template: %s", err.Error())
+
+					return fmt.Errorf("%w(%s): %s", ErrProcessWordlist, target, err.Error())
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if len(debugPaths) > 0 {
+		logger.For(ctx).Infof("Scan templates from debug paths: %d (base urls: %d)", len(debugPaths), len(bases))
+
+		if err := store("debug paths", debugPaths); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Wordlist) == 0 {
+		return nil
+	}
+
+	file, err := os.Open(cfg.Wordlist)
+	if err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessWordlist, cfg.Wordlist, err.Error()))
+	}
+	defer file.Close()
+
+	logger.For(ctx).Infof("Scan templates from wordlist: %s (base urls: %d, extensions: %d)", cfg.Wordlist, len(bases), len(exts))
+
+	source := fmt.Sprintf("wordlist (%s)", cfg.Wordlist)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "/")
+		// Blank lines and comments are skipped.
+		if len(word) == 0 || strings.HasPrefix(word, "#") {
+			continue
+		}
+
+		words := []string{word}
+		for _, ext := range exts {
+			words = append(words, word+"."+ext)
+		}
+
+		if err := store(source, words); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessWordlist, cfg.Wordlist, err.Error()))
+	}
+
+	return nil
+}
+
+// discoveryBase returns the given url with no query nor fragment,
+// and with a trailing slash, so words can be appended to it.
+func discoveryBase(rawURL string) string {
+	u, err := stdurl.Parse(rawURL)
+	if err != nil {
+		return strings.TrimSuffix(rawURL, "/") + "/"
+	}
+
+	u.RawQuery, u.Fragment = "", ""
+
+	return strings.TrimSuffix(u.String(), "/") + "/"
+}
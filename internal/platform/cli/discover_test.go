@@ -0,0 +1,171 @@
+//nolint:testpackage
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func Test_createFromWordlist(t *testing.T) {
+	t.Parallel()
+
+	wordlist := filepath.Join(t.TempDir(), "wordlist.txt")
+	require.NoError(t, os.WriteFile(wordlist, []byte("admin\n\n# comment\n /backup \nlogin\n"), 0o600))
+
+	tcs := map[string]struct {
+		cfg      Config
+		expected []string
+	}{
+		"words": {
+			cfg: Config{URLS: []string{"https://example.org/app/?q=1#top"}, Wordlist: wordlist},
+			expected: []string{
+				"https://example.org/app/admin",
+				"https://example.org/app/backup",
+				"https://example.org/app/login",
+			},
+		},
+		"words with extensions": {
+			cfg: Config{URLS: []string{"https://example.org"}, Wordlist: wordlist, Extensions: "php, .bak"},
+			expected: []string{
+				"https://example.org/admin", "https://example.org/admin.php", "https://example.org/admin.bak",
+				"https://example.org/backup", "https://example.org/backup.php", "https://example.org/backup.bak",
+				"https://example.org/login", "https://example.org/login.php", "https://example.org/login.bak",
+			},
+		},
+		"multiple base urls": {
+			cfg: Config{URLS: []string{"https://example.org/", "http://example.com/api"}, Wordlist: wordlist},
+			expected: []string{
+				"https://example.org/admin", "https://example.org/backup", "https://example.org/login",
+				"http://example.com/api/admin", "http://example.com/api/backup", "http://example.com/api/login",
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+			require.NoError(t, err)
+
+			require.NoError(t, createFromWordlist(ctx, fs, tc.cfg, nil, nil))
+
+			assert.ElementsMatch(t, tc.expected, storedURLs(t, fs))
+		})
+	}
+}
+
+func Test_createFromWordlist_Issues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	cfg := Config{URLS: []string{"https://example.org"}, Wordlist: missing}
+
+	// It fails fast, unless the issues are collected (e.g. while validating).
+	require.ErrorIs(t, createFromWordlist(ctx, fs, cfg, nil, nil), ErrProcessWordlist)
+
+	iss := new(issues)
+	require.NoError(t, createFromWordlist(ctx, fs, cfg, nil, iss))
+	require.Len(t, iss.errs, 1)
+	require.ErrorIs(t, iss.errs[0], ErrProcessWordlist)
+}
+
+func TestConfig_DiscoveryProfile(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		cfg  Config
+		code int
+		body []byte
+		ok   bool
+	}{
+		"default status, interesting": {cfg: Config{}, code: 403, ok: true},
+		"default status, not found":   {cfg: Config{}, code: 404},
+		"custom status":               {cfg: Config{MatchStatus: "200, 404"}, code: 404, ok: true},
+		"custom status, not listed":   {cfg: Config{MatchStatus: "200"}, code: 403},
+		"filtered size":               {cfg: Config{FilterSize: "1000"}, code: 200, body: bytes.Repeat([]byte("a"), 1000)},
+		"any filtered size":           {cfg: Config{FilterSize: "10, 1000"}, code: 200, body: bytes.Repeat([]byte("a"), 1000)},
+		"not filtered size":           {cfg: Config{FilterSize: "1000"}, code: 200, body: bytes.Repeat([]byte("a"), 5000), ok: true},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			prof, err := tc.cfg.DiscoveryProfile()
+			require.NoError(t, err)
+
+			req := request.Default("https://example.org/admin")
+			res := response.Response{Proto: "HTTP/1.1", Code: tc.code, Body: tc.body}
+
+			ok, _ := match.Match(context.Background(), match.Data{Profile: prof, Request: &req, Response: &res})
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestConfig_DiscoveryProfile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, cfg := range map[string]Config{
+		"invalid size":   {FilterSize: "1k"},
+		"negative size":  {FilterSize: "-1"},
+		"invalid status": {MatchStatus: "ok"},
+	} {
+		_, err := cfg.DiscoveryProfile()
+		require.Error(t, err, name)
+	}
+}
+
+func TestConfig_DiscoveryExtensions(t *testing.T) {
+	t.Parallel()
+
+	exts, err := Config{Extensions: " php,.bak ,tar.gz"}.DiscoveryExtensions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"php", "bak", "tar.gz"}, exts)
+
+	exts, err = Config{}.DiscoveryExtensions()
+	require.NoError(t, err)
+	assert.Nil(t, exts)
+
+	for _, invalid := range []string{"php,", "p/hp", ".php?x"} {
+		_, err = Config{Extensions: invalid}.DiscoveryExtensions()
+		require.Error(t, err, invalid)
+	}
+}
+
+func storedURLs(t *testing.T, fs scan.FileSystem) []string {
+	t.Helper()
+
+	templates, err := fs.LoadTemplates(context.Background())
+	require.NoError(t, err)
+
+	urls := make([]string, 0, len(templates))
+	for _, tpl := range templates {
+		urls = append(urls, tpl.OriginalURL)
+	}
+
+	return urls
+}
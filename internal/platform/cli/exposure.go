@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+)
+
+const (
+	// allExposures is the value of [Config.Exposures] that enables the [Config.ExposureProfile]
+	// looking for all the exposures.
+	allExposures = "all"
+	// noExposures is the value of [Config.Exposures] that disables the [Config.ExposureProfile],
+	// same as leaving it empty.
+	noExposures = "none"
+)
+
+// ExposureProfile returns the [gbprofile.Response] used to report exposed content
+// (e.g. directory listings or backup files) during content discovery (see [Config.Discover])
+// and passive scans (see [Config.Passive]), built on top of the Exposed Content grep,
+// looking for those exposures defined by [Config.Exposures], or all of them if "all".
+//
+// It returns nil if [Config.Exposures] is empty (i.e. it's opt-in) or "none",
+// or an error if any of them is invalid.
+func (cfg Config) ExposureProfile() (*gbprofile.Response, error) {
+	exposures := strings.TrimSpace(cfg.Exposures)
+	if len(exposures) == 0 || strings.EqualFold(exposures, noExposures) {
+		return nil, nil //nolint:nilnil
+	}
+
+	var kinds []string
+	if !strings.EqualFold(exposures, allExposures) {
+		for _, kind := range strings.Split(exposures, ",") {
+			kinds = append(kinds, strings.TrimSpace(kind))
+		}
+	}
+
+	prof := &gbprofile.Response{
+		Name:    "Exposed Content",
+		Enabled: true,
+		Type:    gbprofile.TypePassiveRes,
+		Tags:    []string{"exposure"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,%s", gbprofile.GrepTypeExposedContent, strings.Join(kinds, ";")),
+		},
+		IssueName:       "Exposed Content",
+		IssueSeverity:   "Medium",
+		IssueConfidence: "Firm",
+		IssueDetail: "The requested path exposes content that shouldn't be publicly available, " +
+			"like a directory listing, a git repository, a dotenv file, or a backup (or swap) file.",
+		RemediationDetail: "Disable directory listings, and remove (or deny access to) " +
+			"version control directories, dotenv files, and backup files from the web root.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
@@ -0,0 +1,13 @@
+package cli
+
+import (
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// FindingsGrouping returns the [scan.FindingsGrouping] defined by [Config.GroupFindings]
+// (case-insensitive), or an error if it is unknown (see [scan.ParseFindingsGrouping]).
+func (cfg Config) FindingsGrouping() (scan.FindingsGrouping, error) {
+	return scan.ParseFindingsGrouping(strings.ToLower(strings.TrimSpace(cfg.GroupFindings)))
+}
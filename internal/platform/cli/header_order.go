@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// HeaderOrderKeys returns the list of header keys defined by [Config.HeaderOrder],
+// or an error if any of them is invalid, like those empty, with spaces or colons,
+// or duplicated (case-insensitive).
+//
+// If no [Config.HeaderOrder] is defined, it returns nil.
+func (cfg Config) HeaderOrderKeys() ([]string, error) {
+	if len(strings.TrimSpace(cfg.HeaderOrder)) == 0 {
+		return nil, nil
+	}
+
+	var (
+		keys = make([]string, 0, strings.Count(cfg.HeaderOrder, ",")+1)
+		seen = make(map[string]struct{})
+	)
+
+	for _, key := range strings.Split(cfg.HeaderOrder, ",") {
+		key = strings.TrimSpace(key)
+		if len(key) == 0 {
+			return nil, fmt.Errorf(`empty header key: "%s"`, cfg.HeaderOrder) //nolint:err113
+		}
+
+		if strings.IndexFunc(key, unicode.IsSpace) >= 0 || strings.Contains(key, ":") {
+			return nil, fmt.Errorf(`invalid header key: "%s"`, key) //nolint:err113
+		}
+
+		if _, duplicated := seen[strings.ToLower(key)]; duplicated {
+			return nil, fmt.Errorf(`duplicated header key: "%s"`, key) //nolint:err113
+		}
+
+		seen[strings.ToLower(key)] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
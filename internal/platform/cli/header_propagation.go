@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// HeaderPropagations returns the [scan.HeaderPropagation] rules defined by [Config.HeaderFromResponse],
+// or an error if any of them is invalid, or if there are two (or more) rules for the same header
+// (case-insensitive).
+//
+// If no [Config.HeaderFromResponse] is defined, it returns nil.
+func (cfg Config) HeaderPropagations() ([]scan.HeaderPropagation, error) {
+	if len(cfg.HeaderFromResponse) == 0 {
+		return nil, nil
+	}
+
+	var (
+		rules = make([]scan.HeaderPropagation, 0, len(cfg.HeaderFromResponse))
+		seen  = make(map[string]struct{})
+	)
+
+	for _, raw := range cfg.HeaderFromResponse {
+		rule, err := scan.ParseHeaderPropagation(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, duplicated := seen[strings.ToLower(rule.Header)]; duplicated {
+			return nil, fmt.Errorf(`duplicated header: "%s"`, rule.Header) //nolint:err113
+		}
+
+		seen[strings.ToLower(rule.Header)] = struct{}{}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// RemovedHeaders returns the list of header keys defined by [Config.RemoveHeaders]
+// (each comma-separated), or an error if any of them is invalid, like those empty,
+// or with spaces or colons.
+//
+// If no [Config.RemoveHeaders] is defined, it returns nil.
+func (cfg Config) RemovedHeaders() ([]string, error) {
+	var keys []string
+
+	for _, v := range cfg.RemoveHeaders {
+		for _, key := range strings.Split(v, ",") {
+			key = strings.TrimSpace(key)
+			if len(key) == 0 {
+				return nil, fmt.Errorf(`empty header key: "%s"`, v) //nolint:err113
+			}
+
+			if strings.IndexFunc(key, unicode.IsSpace) >= 0 || strings.Contains(key, ":") {
+				return nil, fmt.Errorf(`invalid header key: "%s"`, key) //nolint:err113
+			}
+
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// headerRemovingFS is a [scan.FileSystem] decorator that removes the given headers
+// from the templates right before these are stored, so after all the headers have
+// been inherited (e.g. from --header, raw requests or the login session).
+type headerRemovingFS struct {
+	scan.FileSystem
+	keys []string
+}
+
+func (fs headerRemovingFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	for _, key := range fs.keys {
+		tpl.Request = request.WithoutHeader(key)(tpl.Request)
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
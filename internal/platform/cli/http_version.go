@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var errCustomHTTPVersionWithoutRawHeaders = errors.New("only 1.0 and 1.1 are allowed, unless --allow-raw-headers is specified")
+
+// httpVersionRegex matches the numeric versions (e.g. 1.0, 2 or 0.9),
+// which are sent prefixed with HTTP/ in the request line.
+var httpVersionRegex = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// RequestLineProto returns the protocol version sent in the request line, as defined
+// by [Config.HTTPVersion], either numeric (e.g. 1.0, sent as HTTP/1.0) or verbatim
+// (e.g. HTTP/1.1.1), or an error if it isn't allowed or contains line breaks.
+//
+// Apart from 1.0 and 1.1, versions are only allowed along with [Config.AllowRawHeaders],
+// including 0.9, which sends HTTP/0.9-style requests (i.e. the request line only).
+//
+// If no [Config.HTTPVersion] is defined, it returns an empty string.
+func (cfg Config) RequestLineProto() (string, error) {
+	version := strings.TrimSpace(cfg.HTTPVersion)
+	if len(version) == 0 {
+		return "", nil
+	}
+
+	if strings.ContainsAny(version, "\r\n") {
+		return "", fmt.Errorf(`line breaks are not allowed: %q`, version) //nolint:err113
+	}
+
+	proto := version
+	if httpVersionRegex.MatchString(version) {
+		proto = "HTTP/" + version
+	}
+
+	if proto != "HTTP/1.0" && proto != "HTTP/1.1" && !cfg.AllowRawHeaders {
+		return "", fmt.Errorf(`%w: "%s"`, errCustomHTTPVersionWithoutRawHeaders, version)
+	}
+
+	return proto, nil
+}
+
+// protoFS is a [scan.FileSystem] decorator that sets the protocol
+// version sent in the request line of the templates stored.
+type protoFS struct {
+	scan.FileSystem
+	proto string
+}
+
+func (fs protoFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	tpl.Proto = fs.proto
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
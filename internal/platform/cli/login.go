@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// LoginSequence returns the [scan.LoginSequence] read from [Config.LoginSequenceFile] (see
+// [scan.ReadLoginSequence]), if any, performed before the scan to authenticate against the target.
+func (cfg Config) LoginSequence() (*scan.LoginSequence, error) {
+	if len(cfg.LoginSequenceFile) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	f, err := os.Open(cfg.LoginSequenceFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seq, err := scan.ReadLoginSequence(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seq, nil
+}
+
+// withSessionVariables returns the given variables along with those from the given
+// [scan.LoginSession], which take precedence, as those are the result of the login.
+func withSessionVariables(vars map[string]string, session *scan.LoginSession) map[string]string {
+	merged := make(map[string]string, len(vars)+len(session.Variables))
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	for k, v := range session.Variables {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// sessionFS is a [scan.FileSystem] decorator that sets the cookies
+// from the [scan.LoginSession] into the templates before storing them.
+type sessionFS struct {
+	scan.FileSystem
+	session *scan.LoginSession
+}
+
+func (fs sessionFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	tpl.Request = tpl.Request.Clone()
+	fs.session.SetCookies(&tpl.Request)
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var (
+	errInvalidMassAssignmentParam      = errors.New("invalid mass assignment param")
+	errMassAssignmentParamWithoutProbe = errors.New("the mass assignment params (--mass-assignment-param) can only be used in combination with --mass-assignment")
+)
+
+// MassAssignmentProbe returns the [scan.MassAssignmentCfg] defined by [Config.MassAssignment] and
+// [Config.MassAssignmentParams], or an error if any of the params is invalid. If no params
+// are defined, the default ones are injected (see [scan.DefaultMassAssignmentParams]).
+func (cfg Config) MassAssignmentProbe() (scan.MassAssignmentCfg, error) {
+	switch {
+	case !cfg.MassAssignment && len(cfg.MassAssignmentParams) > 0:
+		return scan.MassAssignmentCfg{}, errMassAssignmentParamWithoutProbe
+	case !cfg.MassAssignment:
+		return scan.MassAssignmentCfg{}, nil
+	}
+
+	if len(cfg.MassAssignmentParams) == 0 {
+		return scan.MassAssignmentCfg{Enabled: true, Params: scan.DefaultMassAssignmentParams}, nil
+	}
+
+	params := make([][2]string, 0, len(cfg.MassAssignmentParams))
+	for _, p := range cfg.MassAssignmentParams {
+		name, value, found := strings.Cut(strings.TrimSpace(p), "=")
+		if !found || len(name) == 0 || strings.ContainsAny(name, " \t&") || strings.ContainsAny(value, "&") {
+			return scan.MassAssignmentCfg{}, fmt.Errorf("%w: %s, expected: name=value, e.g. isAdmin=true", errInvalidMassAssignmentParam, p)
+		}
+		params = append(params, [2]string{name, value})
+	}
+
+	return scan.MassAssignmentCfg{Enabled: true, Params: params}, nil
+}
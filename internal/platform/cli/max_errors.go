@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"errors"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var errMaxErrorsWindowWithoutRate = errors.New("the window (--max-errors-window) can only be used in combination with a percentage of errors: --max-errors 20%")
+
+// ErrorThreshold returns the [scan.ErrorThreshold] defined by [Config.MaxErrors], either as the
+// amount of failed requests, or as the percentage of failed requests over the latest
+// [Config.MaxErrorsWindow] requests, or an error if it is invalid.
+//
+// If no [Config.MaxErrors] is defined, it returns an empty [scan.ErrorThreshold] (i.e. never aborted).
+func (cfg Config) ErrorThreshold() (scan.ErrorThreshold, error) {
+	threshold, err := scan.ParseErrorThreshold(cfg.MaxErrors, cfg.MaxErrorsWindow)
+	if err != nil {
+		return scan.ErrorThreshold{}, err
+	}
+
+	if cfg.MaxErrorsWindow != 0 && threshold.Rate == 0 {
+		return scan.ErrorThreshold{}, errMaxErrorsWindowWithoutRate
+	}
+
+	return threshold, nil
+}
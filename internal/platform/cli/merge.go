@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bountysecurity/gbounty/kit/getopt"
+)
+
+// MergeConfig is the configuration of the merge subcommand, used to merge multiple
+// scan outputs (e.g. from a sharded scan) into a single one (see [writer.MergeFiles]).
+type MergeConfig struct {
+	// ShowHelp determines whether the help message should be printed.
+	ShowHelp bool
+	// Inputs specifies the paths to the scan outputs (JSON) to be merged.
+	Inputs []string
+	// OutPath specifies the path to the file where the merged output is written.
+	OutPath string
+	// Force determines whether the existing output file, if any, can be overwritten.
+	Force bool
+}
+
+var (
+	errMergeNotEnoughInputs = errors.New("you must specify, at least, two scan outputs (JSON) to be merged")
+	errMergeMissingOutput   = errors.New("you must specify an output file path (-o/--output <path>) for the merged output")
+)
+
+// ParseMerge parses a slice of strings as a list of arguments of the merge
+// subcommand (e.g. merge out1.json out2.json -o merged.json), where flags and
+// inputs can be interleaved, and constructs a [MergeConfig] based on those.
+func ParseMerge(args []string) (MergeConfig, error) {
+	config := MergeConfig{}
+
+	fs := getopt.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	fs.BoolVar("", &config.ShowHelp, "help", false, "Show help")
+	fs.Alias("h", "help")
+	fs.StringVar("", &config.OutPath, "output", "", "Determines the path where the merged output will be written to")
+	fs.Alias("o", "output")
+	fs.BoolVar("", &config.Force, "force", false, "If specified, the existing output file, if any, is overwritten")
+
+	fs.SetUsage(`
+Usage:
+  gbounty merge [flags] <output.json> <output.json>...	Merges multiple scan outputs (JSON) into a single one
+  Findings are deduplicated by their identifier, the summary is re-calculated and the results are summed up
+
+Flags:`)
+
+	fs.SetExamples(`EXAMPLES:
+gbounty merge shard-0.json shard-1.json shard-2.json -o merged.json`)
+
+	// Flags are parsed up to the first input, so the remaining ones
+	// are parsed again after each input, so these can be interleaved.
+	rest := args[1:]
+	for {
+		if err := fs.Parse(rest); err != nil {
+			return MergeConfig{}, err
+		}
+
+		rest = fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+
+		config.Inputs = append(config.Inputs, rest[0])
+		rest = rest[1:]
+	}
+
+	if config.ShowHelp {
+		fs.SetOutput(os.Stdout)
+		fs.PrintDefaults()
+	}
+
+	return config, nil
+}
+
+// Validate validates the [MergeConfig] and returns an [error] if it isn't valid.
+func (cfg MergeConfig) Validate() error {
+	if len(cfg.Inputs) < 2 { //nolint:mnd
+		return errMergeNotEnoughInputs
+	}
+
+	for _, input := range cfg.Inputs {
+		if _, err := os.Stat(input); err != nil {
+			return fmt.Errorf(`the provided scan output is invalid: %s`, err.Error()) //nolint:err113
+		}
+	}
+
+	if len(cfg.OutPath) == 0 {
+		return errMergeMissingOutput
+	}
+
+	info, err := os.Stat(cfg.OutPath)
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf(`invalid output path: "%s" - is a directory`, cfg.OutPath) //nolint:err113
+	case err == nil && !cfg.Force:
+		return fmt.Errorf(`the output file already exists, use --force to overwrite it: "%s"`, cfg.OutPath) //nolint:err113
+	}
+
+	return nil
+}
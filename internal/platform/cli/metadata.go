@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// reservedMetadataKeys are those keys that cannot be used as metadata keys, because
+// they either identify a finding's field in the output, or are set by the scanner itself
+// (see [scan.MetadataKeys]), so these would be overwritten (or spoofed) otherwise.
+var reservedMetadataKeys = func() map[string]struct{} {
+	keys := map[string]struct{}{
+		"id": {}, "url": {}, "issue": {}, "name": {}, "severity": {}, "confidence": {},
+		"param": {}, "type": {}, "payload": {}, "profile": {}, "requests": {},
+		"responses": {}, "count": {}, "urls": {}, "metadata": {},
+	}
+
+	for _, key := range scan.MetadataKeys() {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}()
+
+// MetadataPairs returns the set of key=value pairs defined by
+// [Config.Metadata], or an error if any of them is invalid, like
+// those with empty, reserved or duplicated keys.
+//
+// If no [Config.Metadata] is defined, it returns nil.
+func (cfg Config) MetadataPairs() (map[string]string, error) {
+	if len(cfg.Metadata) == 0 {
+		return nil, nil
+	}
+
+	pairs := make(map[string]string, len(cfg.Metadata))
+	for _, v := range cfg.Metadata {
+		key, value, found := strings.Cut(v, "=")
+		if !found {
+			return nil, fmt.Errorf(`missing value (key=value): "%s"`, v) //nolint:err113
+		}
+
+		key = strings.TrimSpace(key)
+		if len(key) == 0 {
+			return nil, fmt.Errorf(`empty key: "%s"`, v) //nolint:err113
+		}
+
+		if strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf(`key cannot contain spaces: "%s"`, key) //nolint:err113
+		}
+
+		if _, reserved := reservedMetadataKeys[strings.ToLower(key)]; reserved {
+			return nil, fmt.Errorf(`reserved key: "%s"`, key) //nolint:err113
+		}
+
+		if _, duplicated := pairs[key]; duplicated {
+			return nil, fmt.Errorf(`duplicated key: "%s"`, key) //nolint:err113
+		}
+
+		pairs[key] = value
+	}
+
+	return pairs, nil
+}
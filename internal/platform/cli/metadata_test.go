@@ -0,0 +1,59 @@
+//nolint:testpackage
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestConfig_MetadataPairs(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		metadata MultiValue
+		expected map[string]string
+		err      string
+	}{
+		"none": {},
+		"key=value pairs": {
+			metadata: MultiValue{"commit=4f2a1c9", " pipeline =1234", "empty=", "query=a=b"},
+			expected: map[string]string{"commit": "4f2a1c9", "pipeline": "1234", "empty": "", "query": "a=b"},
+		},
+		"missing value":        {metadata: MultiValue{"commit"}, err: "missing value"},
+		"empty key":            {metadata: MultiValue{" =1234"}, err: "empty key"},
+		"key with spaces":      {metadata: MultiValue{"build id=1"}, err: "key cannot contain spaces"},
+		"duplicated key":       {metadata: MultiValue{"commit=1", "commit=2"}, err: "duplicated key"},
+		"reserved field key":   {metadata: MultiValue{"Severity=High"}, err: "reserved key"},
+		"reserved scanner key": {metadata: MultiValue{scan.MetadataCanary + "=gb"}, err: "reserved key"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pairs, err := Config{Metadata: tc.metadata}.MetadataPairs()
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pairs)
+		})
+	}
+}
+
+func TestConfig_MetadataPairs_ScannerKeys(t *testing.T) {
+	t.Parallel()
+
+	// None of the keys set by the scanner itself can be overwritten (or spoofed).
+	for _, key := range scan.MetadataKeys() {
+		_, err := Config{Metadata: MultiValue{key + "=value"}}.MetadataPairs()
+		require.ErrorContains(t, err, "reserved key", key)
+	}
+}
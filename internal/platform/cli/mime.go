@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+const (
+	mimeAllowPrefix = "allow:"
+	mimeDenyPrefix  = "deny:"
+)
+
+// MimeFilter returns the [scan.MimeFilter] defined by [Config.FilterMime],
+// or an error if any of the media types is invalid.
+//
+// Each value is a comma-separated list of media types (e.g. image/*,font/*),
+// optionally prefixed with either allow: or deny:. If no prefix is present,
+// the media types are denied.
+func (cfg Config) MimeFilter() (scan.MimeFilter, error) {
+	var filter scan.MimeFilter
+
+	for _, v := range cfg.FilterMime {
+		list, allow := strings.CutPrefix(strings.ToLower(v), mimeAllowPrefix)
+		if !allow {
+			list = strings.TrimPrefix(list, mimeDenyPrefix)
+		}
+
+		for _, mediaType := range strings.Split(list, ",") {
+			mediaType = strings.TrimSpace(mediaType)
+			if err := validateMediaType(mediaType); err != nil {
+				return scan.MimeFilter{}, err
+			}
+
+			if allow {
+				filter.Allow = append(filter.Allow, mediaType)
+			} else {
+				filter.Deny = append(filter.Deny, mediaType)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// validateMediaType checks the media type has the form type/subtype,
+// where subtype can be a wildcard (e.g. image/*), but type cannot.
+func validateMediaType(mediaType string) error {
+	typ, subtype, found := strings.Cut(mediaType, "/")
+	if !found || len(typ) == 0 || len(subtype) == 0 {
+		return fmt.Errorf(`media type must be type/subtype: "%s"`, mediaType) //nolint:err113
+	}
+
+	if strings.IndexFunc(mediaType, unicode.IsSpace) >= 0 || strings.ContainsAny(subtype, "/;") {
+		return fmt.Errorf(`invalid media type: "%s"`, mediaType) //nolint:err113
+	}
+
+	if strings.Contains(typ, "*") || (strings.Contains(subtype, "*") && subtype != "*") {
+		return fmt.Errorf(`only the whole subtype can be a wildcard: "%s"`, mediaType) //nolint:err113
+	}
+
+	return nil
+}
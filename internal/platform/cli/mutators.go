@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// Mutators returns the list of [scan.RequestMutator] defined by [Config.RequestMutators]
+// (comma-separated), in the given order, or an error if any of them is unknown, or duplicated.
+// If [Config.JitterHeaders] is set, the [scan.JitterHeadersMutator] goes first.
+//
+// If no [Config.RequestMutators] is defined, nor [Config.JitterHeaders], it returns nil.
+func (cfg Config) Mutators() ([]scan.RequestMutator, error) {
+	var jitter []scan.RequestMutator
+	if cfg.JitterHeaders {
+		jitter = []scan.RequestMutator{scan.JitterHeadersMutator}
+	}
+
+	if len(strings.TrimSpace(cfg.RequestMutators)) == 0 {
+		return jitter, nil
+	}
+
+	var (
+		mutators = append(make([]scan.RequestMutator, 0, len(jitter)+strings.Count(cfg.RequestMutators, ",")+1), jitter...)
+		seen     = make(map[string]struct{})
+	)
+
+	for _, name := range strings.Split(cfg.RequestMutators, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		mutator, err := scan.RequestMutatorFrom(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, duplicated := seen[name]; duplicated {
+			return nil, fmt.Errorf(`duplicated request mutator: "%s"`, name) //nolint:err113
+		}
+
+		seen[name] = struct{}{}
+		mutators = append(mutators, mutator)
+	}
+
+	return mutators, nil
+}
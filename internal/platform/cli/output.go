@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// outputFormats are the formats supported for the scan outputs.
+var outputFormats = []string{"plain", "json", "ndjson", "markdown", "junit", "html", "template"}
+
+func isOutputFormat(format string) bool {
+	return slices.Contains(outputFormats, format)
+}
+
+// Outputs returns the [scan.Output] instances defined by [Config.OutPaths].
+//
+// The format of each output is the one given by [Config.OutFormats] (either a
+// single one for all of them, or one per path), or by [Config.OutFormat] (i.e.
+// the -j, -md or -ot flags), or otherwise inferred from the path extension:
+// .json, .ndjson (or .jsonl), .md (or .markdown), .xml (JUnit) and .html (or .htm), plain text by default.
+func (cfg Config) Outputs() []scan.Output {
+	outputs := make([]scan.Output, 0, len(cfg.OutPaths))
+
+	for i, path := range cfg.OutPaths {
+		var format string
+
+		switch {
+		case len(cfg.OutFormats) == 1:
+			format = cfg.OutFormats[0]
+		case i < len(cfg.OutFormats):
+			format = cfg.OutFormats[i]
+		case len(cfg.OutFormat) > 0:
+			format = cfg.OutFormat
+		default:
+			format = outputFormatFromExt(path)
+		}
+
+		outputs = append(outputs, scan.Output{Path: path, Format: format})
+	}
+
+	return outputs
+}
+
+func outputFormatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".md", ".markdown":
+		return "markdown"
+	case ".xml":
+		return "junit"
+	case ".html", ".htm":
+		return "html"
+	default:
+		return "plain"
+	}
+}
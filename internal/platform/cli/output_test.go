@@ -0,0 +1,75 @@
+//nolint:testpackage
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestConfig_Outputs(t *testing.T) {
+	t.Parallel()
+
+	paths := MultiValue{"out.JSON", "out.jsonl", "out.md", "out.xml", "out.htm", "out.txt", "out"}
+
+	tcs := map[string]struct {
+		cfg      Config
+		expected []scan.Output
+	}{
+		"none": {
+			cfg:      Config{},
+			expected: []scan.Output{},
+		},
+		"inferred from extension": {
+			cfg: Config{OutPaths: paths},
+			expected: []scan.Output{
+				{Path: "out.JSON", Format: "json"},
+				{Path: "out.jsonl", Format: "ndjson"},
+				{Path: "out.md", Format: "markdown"},
+				{Path: "out.xml", Format: "junit"},
+				{Path: "out.htm", Format: "html"},
+				{Path: "out.txt", Format: "plain"},
+				{Path: "out", Format: "plain"},
+			},
+		},
+		"single format for all": {
+			cfg: Config{OutPaths: MultiValue{"out.json", "out.md"}, OutFormats: MultiValue{"ndjson"}},
+			expected: []scan.Output{
+				{Path: "out.json", Format: "ndjson"},
+				{Path: "out.md", Format: "ndjson"},
+			},
+		},
+		"one format per path": {
+			cfg: Config{OutPaths: MultiValue{"out.json", "out.md"}, OutFormats: MultiValue{"plain", "html"}},
+			expected: []scan.Output{
+				{Path: "out.json", Format: "plain"},
+				{Path: "out.md", Format: "html"},
+			},
+		},
+		"fewer formats than paths": {
+			cfg: Config{OutPaths: MultiValue{"a.txt", "b.txt", "c.md"}, OutFormats: MultiValue{"json", "html"}},
+			expected: []scan.Output{
+				{Path: "a.txt", Format: "json"},
+				{Path: "b.txt", Format: "html"},
+				{Path: "c.md", Format: "markdown"},
+			},
+		},
+		"format flag over extension": {
+			cfg: Config{OutPaths: MultiValue{"a.txt", "b.md"}, OutFormat: "json"},
+			expected: []scan.Output{
+				{Path: "a.txt", Format: "json"},
+				{Path: "b.md", Format: "json"},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, tc.cfg.Outputs())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+)
+
+// passiveProfiles is the curated set of passive response profiles used during
+// passive scans (see [Config.Passive]), in addition to the enabled passive ones
+// loaded from the profiles path (see [Config.ProfilesPath]), if any.
+var passiveProfiles = []gbprofile.Response{
+	{
+		Name: "Missing Strict-Transport-Security Header",
+		Greps: []string{
+			"true,,URL Scheme,,https",
+			"true,AND,Status Code,,200;201;202;204;301;302;307;308",
+			"true,AND NOT,Regex,Only in Headers,(?m)^Strict-Transport-Security:",
+		},
+		IssueName:       "Strict Transport Security Not Enforced",
+		IssueSeverity:   "Low",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The HTTPS response does not include the Strict-Transport-Security header, so browsers may connect over plain HTTP.",
+	},
+	{
+		Name: "Missing X-Content-Type-Options Header",
+		Greps: []string{
+			"true,,Status Code,,200",
+			"true,AND NOT,Regex,Only in Headers,(?m)^X-Content-Type-Options:\\s*nosniff",
+		},
+		IssueName:       "Content Sniffing Not Disabled",
+		IssueSeverity:   "Information",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The response does not include the X-Content-Type-Options: nosniff header.",
+	},
+	{
+		Name: "Missing Anti-Clickjacking Header",
+		Greps: []string{
+			"true,,Content Type,,text/html",
+			"true,AND NOT,Regex,Only in Headers,(?m)^X-Frame-Options:",
+			"true,AND NOT,Regex,Only in Headers,(?m)^Content-Security-Policy:.*frame-ancestors",
+		},
+		IssueName:       "Frameable Response (Potential Clickjacking)",
+		IssueSeverity:   "Low",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The HTML response neither includes the X-Frame-Options header nor a Content-Security-Policy with frame-ancestors.",
+	},
+	{
+		Name: "Cookie Without Secure Flag",
+		Greps: []string{
+			"true,,Cookie Flags,,Secure",
+		},
+		IssueName:       "Cookie Without Secure Flag Set",
+		IssueSeverity:   "Low",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The response sets a cookie without the Secure flag, so it may be sent over plain HTTP.",
+	},
+	{
+		Name: "Cookie Without HttpOnly Flag",
+		Greps: []string{
+			"true,,Cookie Flags,,HttpOnly",
+		},
+		IssueName:       "Cookie Without HttpOnly Flag Set",
+		IssueSeverity:   "Low",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The response sets a cookie without the HttpOnly flag, so it is accessible from scripts.",
+	},
+	{
+		Name: "Error Signatures",
+		Greps: []string{
+			"true,,Regex,Not in Headers,Traceback \\(most recent call last\\)|(?:Fatal error|Warning)</b>:.+ on line <b>\\d+|" +
+				"java\\.lang\\.\\w+Exception|System\\.\\w+Exception|ORA-\\d{5}|SQLSTATE\\[|You have an error in your SQL syntax",
+		},
+		IssueName:       "Error Message Disclosure",
+		IssueSeverity:   "Low",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The response contains an error message or stack trace, which may disclose internal details.",
+	},
+	{
+		Name: "Software Version Disclosure",
+		Greps: []string{
+			"true,,Regex,Only in Headers,(?m)^(?:Server|X-Powered-By|X-AspNet-Version|X-AspNetMvc-Version):.*\\d+\\.\\d+",
+		},
+		IssueName:       "Software Version Disclosure",
+		IssueSeverity:   "Information",
+		IssueConfidence: "Firm",
+		IssueDetail:     "The response headers disclose the version of the software running on the server.",
+	},
+}
+
+// PassiveProfiles returns the curated set of passive response profiles used
+// during passive scans (see [Config.Passive]), or an error if any is invalid.
+func PassiveProfiles() ([]*gbprofile.Response, error) {
+	profiles := make([]*gbprofile.Response, 0, len(passiveProfiles))
+
+	for _, p := range passiveProfiles {
+		prof := p
+		prof.Enabled = true
+		prof.Type = gbprofile.TypePassiveRes
+		prof.Tags = []string{"passive"}
+		prof.Greps = append([]string(nil), p.Greps...)
+
+		if err := prof.Validate(); err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, &prof)
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,87 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestPassiveProfiles(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := PassiveProfiles()
+	require.NoError(t, err)
+
+	byName := make(map[string]match.Data, len(profiles))
+	for _, prof := range profiles {
+		byName[prof.Name] = match.Data{Profile: prof}
+	}
+
+	tcs := map[string]struct {
+		profile string
+		url     string
+		headers map[string][]string
+		ok      bool
+	}{
+		"hsts missing over https": {
+			profile: "Missing Strict-Transport-Security Header",
+			url:     "https://example.org/",
+			ok:      true,
+		},
+		"hsts missing over http": {
+			profile: "Missing Strict-Transport-Security Header",
+			url:     "http://example.org/",
+		},
+		"hsts set over https": {
+			profile: "Missing Strict-Transport-Security Header",
+			url:     "https://example.org/",
+			headers: map[string][]string{"Strict-Transport-Security": {"max-age=31536000"}},
+		},
+		"secure cookie": {
+			profile: "Cookie Without Secure Flag",
+			url:     "https://example.org/",
+			headers: map[string][]string{"Set-Cookie": {"id=1; Path=/; Secure"}},
+		},
+		"insecure cookie along with a secure one": {
+			profile: "Cookie Without Secure Flag",
+			url:     "https://example.org/",
+			headers: map[string][]string{"Set-Cookie": {"id=1; Path=/", "theme=dark; Secure"}},
+			ok:      true,
+		},
+		"httponly cookie": {
+			profile: "Cookie Without HttpOnly Flag",
+			url:     "https://example.org/",
+			headers: map[string][]string{"Set-Cookie": {"id=1; HttpOnly; Secure"}},
+		},
+		"httponly only within the value": {
+			profile: "Cookie Without HttpOnly Flag",
+			url:     "https://example.org/",
+			headers: map[string][]string{"Set-Cookie": {"id=HttpOnly; Secure"}},
+			ok:      true,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d, found := byName[tc.profile]
+			require.True(t, found)
+
+			req := request.Default(tc.url)
+			d.Request = &req
+			d.Response = &response.Response{Proto: "HTTP/1.1", Code: 200, Status: "OK", Headers: tc.headers}
+
+			ok, _ := match.Match(context.Background(), d)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
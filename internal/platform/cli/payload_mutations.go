@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+)
+
+const (
+	defaultMutationsDepth = 1
+	defaultMutationsSeed  = 1
+)
+
+var (
+	errMutationsOptionsWithoutMutations = errors.New("the mutations options (--mutations-depth, --mutations-seed and --mutations-max-variants) can only be used in combination with the mutations (--mutations)")
+	errInvalidMutationsDepth            = errors.New("the mutations depth (--mutations-depth) must be greater than zero")
+	errInvalidMutationsMaxVariants      = errors.New("the maximum amount of variations (--mutations-max-variants) must be greater than zero")
+)
+
+// PayloadMutations returns the [scan.PayloadMutationsCfg] defined by [Config.Mutations] (comma-separated),
+// in the given order, along with [Config.MutationsDepth], [Config.MutationsSeed] and [Config.MutationsMaxVariants],
+// or an error if any of the mutations is unknown, or duplicated, or any of its insertion point types is unknown.
+//
+// Each mutation can be scoped to some insertion point types (pipe-separated), e.g. encoding:ParamURLValue|ParamJSONValue,
+// instead of those it applies to by default (see [scan.PayloadMutationFrom]).
+//
+// If no [Config.Mutations] is defined, it returns an empty [scan.PayloadMutationsCfg] (i.e. disabled).
+func (cfg Config) PayloadMutations() (scan.PayloadMutationsCfg, error) {
+	if len(strings.TrimSpace(cfg.Mutations)) == 0 {
+		if cfg.MutationsDepth != defaultMutationsDepth || cfg.MutationsSeed != defaultMutationsSeed || cfg.MutationsMaxVariants != scan.DefaultMaxPayloadMutations {
+			return scan.PayloadMutationsCfg{}, errMutationsOptionsWithoutMutations
+		}
+		return scan.PayloadMutationsCfg{}, nil
+	}
+
+	if cfg.MutationsDepth < 1 {
+		return scan.PayloadMutationsCfg{}, errInvalidMutationsDepth
+	}
+
+	if cfg.MutationsMaxVariants < 1 {
+		return scan.PayloadMutationsCfg{}, errInvalidMutationsMaxVariants
+	}
+
+	var (
+		rules = make([]scan.PayloadMutationRule, 0, strings.Count(cfg.Mutations, ",")+1)
+		seen  = make(map[string]struct{})
+	)
+
+	for _, rule := range strings.Split(cfg.Mutations, ",") {
+		name, scope, scoped := strings.Cut(rule, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		mutate, appliesTo, err := scan.PayloadMutationFrom(name)
+		if err != nil {
+			return scan.PayloadMutationsCfg{}, err
+		}
+
+		if _, duplicated := seen[name]; duplicated {
+			return scan.PayloadMutationsCfg{}, fmt.Errorf(`duplicated mutation: "%s"`, name) //nolint:err113
+		}
+		seen[name] = struct{}{}
+
+		if scoped {
+			appliesTo = nil
+			for _, s := range strings.Split(scope, "|") {
+				ipt, err := gbprofile.ParseInsertionPointType(s)
+				if err != nil {
+					return scan.PayloadMutationsCfg{}, err
+				}
+				appliesTo = append(appliesTo, ipt)
+			}
+		}
+
+		rules = append(rules, scan.PayloadMutationRule{Name: name, Mutate: mutate, AppliesTo: appliesTo})
+	}
+
+	return scan.PayloadMutationsCfg{
+		Rules:       rules,
+		Depth:       cfg.MutationsDepth,
+		Seed:        cfg.MutationsSeed,
+		MaxVariants: cfg.MutationsMaxVariants,
+	}, nil
+}
+
+func (cfg Config) checkValidPayloadMutations() error {
+	if _, err := cfg.PayloadMutations(); err != nil {
+		return fmt.Errorf(`the provided payload mutations are invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+const defaultPriorityWeight = 1
+
+// priorityRule is a rule used to determine the [scan.Template.Priority],
+// either based on the template's host or path.
+type priorityRule struct {
+	host   string
+	path   *regexp.Regexp
+	weight int
+}
+
+func (r priorityRule) matches(tpl scan.Template) bool {
+	if r.path != nil {
+		return r.path.MatchString(tpl.Path)
+	}
+
+	u, err := url.Parse(tpl.URL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if suffix, wildcard := strings.CutPrefix(r.host, "*."); wildcard {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+
+	return host == r.host
+}
+
+// Prioritized returns whether there's any priority rule defined, so the templates
+// are dispatched by priority (see [scan.Config.Prioritized]).
+func (cfg Config) Prioritized() bool {
+	return len(cfg.PriorityHosts)+len(cfg.PriorityPathRegexes) > 0
+}
+
+// priorityRules returns the list of [priorityRule] defined by
+// the [Config.PriorityHosts] and [Config.PriorityPathRegexes].
+func (cfg Config) priorityRules() ([]priorityRule, error) {
+	rules := make([]priorityRule, 0, len(cfg.PriorityHosts)+len(cfg.PriorityPathRegexes))
+
+	for _, v := range cfg.PriorityHosts {
+		host, weight := splitWeight(v)
+		if len(host) == 0 {
+			return nil, fmt.Errorf(`empty host: "%s"`, v) //nolint:err113
+		}
+
+		rules = append(rules, priorityRule{host: strings.ToLower(host), weight: weight})
+	}
+
+	for _, v := range cfg.PriorityPathRegexes {
+		expr, weight := splitWeight(v)
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid path regex: "%s" - %s`, expr, err.Error()) //nolint:err113
+		}
+
+		rules = append(rules, priorityRule{path: re, weight: weight})
+	}
+
+	return rules, nil
+}
+
+// splitWeight splits a value with the form value[=weight].
+// If no (valid) weight is present, [defaultPriorityWeight] is used.
+func splitWeight(v string) (string, int) {
+	idx := strings.LastIndex(v, "=")
+	if idx < 0 {
+		return v, defaultPriorityWeight
+	}
+
+	weight, err := strconv.Atoi(v[idx+1:])
+	if err != nil {
+		return v, defaultPriorityWeight
+	}
+
+	return v[:idx], weight
+}
+
+// prioritizingFS is a [scan.FileSystem] decorator that sets the
+// priority to the templates, based on rules, before storing them.
+type prioritizingFS struct {
+	scan.FileSystem
+	rules []priorityRule
+}
+
+func (fs prioritizingFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	for _, rule := range fs.rules {
+		if rule.weight > tpl.Priority && rule.matches(tpl) {
+			tpl.Priority = rule.weight
+		}
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
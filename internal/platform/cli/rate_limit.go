@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// maxRateLimitBurst is the maximum amount of requests sent in a burst (see [Config.RateLimitBurst]).
+const maxRateLimitBurst = 1_000
+
+var (
+	errInvalidRateLimitBurst      = errors.New("the burst (--rate-limit-burst) must be between 1 and 1000 requests")
+	errRateLimitMatchWithoutBurst = errors.New("the rate limit match (--rate-limit-match) can only be used in combination with --rate-limit-burst")
+)
+
+// RateLimit returns the [scan.RateLimitCfg] defined by [Config.RateLimitBurst] and [Config.RateLimitMatch],
+// or an error if any of them is invalid. If no [Config.RateLimitBurst] is defined, it is disabled.
+func (cfg Config) RateLimit() (scan.RateLimitCfg, error) {
+	match := strings.TrimSpace(cfg.RateLimitMatch)
+
+	switch {
+	case cfg.RateLimitBurst == 0 && len(match) > 0:
+		return scan.RateLimitCfg{}, errRateLimitMatchWithoutBurst
+	case cfg.RateLimitBurst == 0:
+		return scan.RateLimitCfg{}, nil
+	case cfg.RateLimitBurst < 0 || cfg.RateLimitBurst > maxRateLimitBurst:
+		return scan.RateLimitCfg{}, errInvalidRateLimitBurst
+	}
+
+	rateLimit := scan.RateLimitCfg{Burst: cfg.RateLimitBurst}
+	if len(match) > 0 {
+		regex, err := regexp.Compile(match)
+		if err != nil {
+			return scan.RateLimitCfg{}, err
+		}
+		rateLimit.Match = regex
+	}
+
+	return rateLimit, nil
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+const (
+	// rawRequestExt is the extension of the raw request files
+	// read from directories (see [Config.RawRequests]).
+	rawRequestExt = ".req"
+	// rawOptsExt is the extension of the sidecar files (see [rawRequestOpts]).
+	rawOptsExt = ".opts"
+)
+
+// ErrInvalidRawRequestOpts is the error returned when the sidecar file
+// (see [rawRequestOpts]) of a raw request file cannot be parsed.
+var ErrInvalidRawRequestOpts = errors.New("invalid raw request options")
+
+// rawRequestOpts defines how the template of a raw request file is built, read
+// from a (JSON) sidecar file next to it, with the same name but the .opts extension
+// (e.g. login.opts for login.req). Those without a sidecar file use the global config.
+//
+// The method overrides the one from the raw request, the headers (e.g. "X-Api-Key: abc")
+// are set on top of those from the raw request, and the params file (relative to the
+// sidecar file) replaces the global one (-pf/--params-file), if any.
+type rawRequestOpts struct {
+	Method     string   `json:"method"`
+	Headers    []string `json:"headers"`
+	ParamsFile string   `json:"paramsFile"`
+}
+
+// rawRequestPaths returns the paths of the raw request files, walking those given
+// that are directories (recursively), to collect the files with the .req extension.
+func rawRequestPaths(paths MultiValue) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			// Non-existing files are reported once read.
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(p), rawRequestExt) {
+				files = append(files, p)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+		}
+	}
+
+	return files, nil
+}
+
+// readRawRequestOpts reads the sidecar file (see [rawRequestOpts]) of the given raw
+// request file, if any. Otherwise, it returns nil (with no error).
+func readRawRequestOpts(path string) (*rawRequestOpts, error) {
+	optsPath := strings.TrimSuffix(path, filepath.Ext(path)) + rawOptsExt
+
+	contents, err := os.ReadFile(optsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil //nolint:nilnil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w(%s): %s", ErrInvalidRawRequestOpts, optsPath, err.Error())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(contents))
+	dec.DisallowUnknownFields()
+
+	var opts rawRequestOpts
+	if err := dec.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("%w(%s): %s", ErrInvalidRawRequestOpts, optsPath, err.Error())
+	}
+
+	for _, header := range opts.Headers {
+		if key, _, found := strings.Cut(header, ":"); !found || len(strings.TrimSpace(key)) == 0 {
+			return nil, fmt.Errorf("%w(%s): %w: %s", ErrInvalidRawRequestOpts, optsPath, ErrInvalidHeader, header)
+		}
+	}
+
+	if len(opts.ParamsFile) > 0 && !filepath.IsAbs(opts.ParamsFile) {
+		opts.ParamsFile = filepath.Join(filepath.Dir(optsPath), opts.ParamsFile)
+	}
+
+	return &opts, nil
+}
+
+// requestOptions returns the [request.Option] defined by the [rawRequestOpts].
+func (o *rawRequestOpts) requestOptions() []request.Option {
+	var options []request.Option
+
+	if len(o.Method) > 0 {
+		options = append(options, request.WithMethod(strings.ToUpper(o.Method)))
+	}
+
+	for _, header := range o.Headers {
+		key, value, _ := strings.Cut(header, ":")
+		options = append(options, request.WithHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+
+	return options
+}
+
+// paramsCfg returns the [scan.ParamsCfg] defined by the [rawRequestOpts], which is
+// the given one unless there's a params file, used with the global params settings.
+func (o *rawRequestOpts) paramsCfg(ctx context.Context, cfg Config, pCfg scan.ParamsCfg) (scan.ParamsCfg, error) {
+	if len(o.ParamsFile) == 0 {
+		return pCfg, nil
+	}
+
+	if cfg.NoEntrypoints || cfg.Passive {
+		logger.For(ctx).Warnf("Params file (%s) ignored: entrypoints are disabled", o.ParamsFile)
+		return pCfg, nil
+	}
+
+	params, sets, err := readParamsFile(ctx, o.ParamsFile)
+	if err != nil {
+		return pCfg, fmt.Errorf("could not read params file(%s): %w", o.ParamsFile, err)
+	}
+
+	return scan.ParamsCfg{
+		Params:      params,
+		Sets:        sets,
+		Size:        cfg.ParamsSplit,
+		Method:      strings.ToUpper(cfg.ParamsMethod),
+		Encoding:    strings.ToLower(cfg.ParamsEncoding),
+		MaxVariants: cfg.MaxTemplateVariants,
+	}, nil
+}
@@ -0,0 +1,148 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+)
+
+const testRawRequest = "GET /api HTTP/1.1\r\nHost: example.org\r\nX-Api-Key: old\r\n\r\n"
+
+func Test_readRawRequestOpts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tcs := map[string]struct {
+		opts     string
+		expected *rawRequestOpts
+		err      bool
+	}{
+		"no sidecar file": {},
+		"all options": {
+			opts: `{"method":"post","headers":["X-Api-Key: abc"],"paramsFile":"params.txt"}`,
+			expected: &rawRequestOpts{
+				Method:     "post",
+				Headers:    []string{"X-Api-Key: abc"},
+				ParamsFile: filepath.Join(dir, "params.txt"),
+			},
+		},
+		"absolute params file": {
+			opts:     `{"paramsFile":"/etc/params.txt"}`,
+			expected: &rawRequestOpts{ParamsFile: "/etc/params.txt"},
+		},
+		"invalid json":     {opts: `{"method":`, err: true},
+		"unknown field":    {opts: `{"verb":"POST"}`, err: true},
+		"invalid header":   {opts: `{"headers":["X-Api-Key"]}`, err: true},
+		"header w/out key": {opts: `{"headers":[": abc"]}`, err: true},
+	}
+
+	var idx int
+	for name, tc := range tcs {
+		idx++
+		path := filepath.Join(dir, strconv.Itoa(idx)+rawRequestExt)
+		if len(tc.opts) > 0 {
+			optsPath := path[:len(path)-len(rawRequestExt)] + rawOptsExt
+			require.NoError(t, os.WriteFile(optsPath, []byte(tc.opts), 0o600))
+		}
+
+		opts, err := readRawRequestOpts(path)
+		if tc.err {
+			require.ErrorIs(t, err, ErrInvalidRawRequestOpts, name)
+			continue
+		}
+
+		require.NoError(t, err, name)
+		assert.Equal(t, tc.expected, opts, name)
+	}
+}
+
+func Test_createFromRawRequestFiles_Opts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	writeFile := func(name, contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+	}
+
+	// With options, without them (global config), and with a params file.
+	writeFile("opts.req", testRawRequest)
+	writeFile("opts.opts", `{"method":"put","headers":["X-Api-Key: abc","X-Debug: 1"]}`)
+	writeFile("global.req", testRawRequest)
+	writeFile("params.req", testRawRequest)
+	writeFile("params.opts", `{"paramsFile":"params.txt"}`)
+	writeFile("params.txt", "debug\n")
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	cfg := Config{RawRequests: MultiValue{dir}, ParamsSplit: 10, ParamsMethod: "GET", ParamsEncoding: "url"}
+	require.NoError(t, createFromRawRequestFiles(ctx, fs, cfg, scan.ParamsCfg{}, nil))
+
+	templates := storedTemplates(t, fs)
+	require.Len(t, templates, 3)
+
+	// Sorted by path: global, opts, params.
+	assert.Equal(t, "GET", templates[0].Method)
+	assert.Equal(t, "old", templates[0].Header("X-Api-Key"))
+
+	assert.Equal(t, "PUT", templates[1].Method)
+	assert.Equal(t, "abc", templates[1].Header("X-Api-Key"))
+	assert.Equal(t, "1", templates[1].Header("X-Debug"))
+
+	assert.Equal(t, "GET", templates[2].Method)
+	assert.Contains(t, templates[2].Path, "debug=")
+}
+
+func Test_createFromRawRequestFiles_InvalidOpts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "valid.req"), []byte(testRawRequest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.req"), []byte(testRawRequest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.opts"), []byte(`{"verb":"POST"}`), 0o600))
+
+	cfg := Config{RawRequests: MultiValue{dir}}
+
+	// The file is skipped (with a warning), while the rest are still stored.
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	require.NoError(t, createFromRawRequestFiles(ctx, fs, cfg, scan.ParamsCfg{}, nil))
+	assert.Len(t, storedTemplates(t, fs), 1)
+
+	// Unless when validating, where it's reported as an issue.
+	fs, err = filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	iss := new(issues)
+	require.NoError(t, createFromRawRequestFiles(ctx, fs, cfg, scan.ParamsCfg{}, iss))
+	require.Len(t, iss.errs, 1)
+	require.ErrorIs(t, iss.errs[0], ErrInvalidRawRequestOpts)
+}
+
+func storedTemplates(t *testing.T, fs scan.FileSystem) []scan.Template {
+	t.Helper()
+
+	templates, err := fs.LoadTemplates(context.Background())
+	require.NoError(t, err)
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Idx < templates[j].Idx })
+
+	return templates
+}
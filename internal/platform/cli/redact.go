@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+var errEmptyRedactPattern = errors.New("empty pattern")
+
+// Redaction returns the [scan.Redaction] defined by [Config.RedactHeaders] and
+// [Config.RedactPatterns], or an error if any of the patterns is invalid.
+//
+// Each header value is a comma-separated list of header names (e.g. Authorization,Cookie),
+// while each pattern value is a single regular expression, as these may contain commas.
+func (cfg Config) Redaction() (scan.Redaction, error) {
+	var redaction scan.Redaction
+
+	for _, v := range cfg.RedactHeaders {
+		for _, header := range strings.Split(v, ",") {
+			header = strings.TrimSpace(header)
+			if len(header) == 0 || strings.ContainsAny(header, ": \t") {
+				return scan.Redaction{}, fmt.Errorf(`invalid header name: "%s"`, header) //nolint:err113
+			}
+
+			redaction.Headers = append(redaction.Headers, header)
+		}
+	}
+
+	for _, v := range cfg.RedactPatterns {
+		if len(v) == 0 {
+			return scan.Redaction{}, errEmptyRedactPattern
+		}
+
+		pattern, err := regexp.Compile(v)
+		if err != nil {
+			return scan.Redaction{}, fmt.Errorf(`invalid pattern "%s": %s`, v, err.Error()) //nolint:err113
+		}
+
+		redaction.Patterns = append(redaction.Patterns, pattern)
+	}
+
+	return redaction, nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var errInvalidReplayProxyScheme = errors.New("unsupported scheme")
+
+// ReplayProxyAddress returns the address (host:port) of the proxy defined by [Config.ReplayProxy],
+// either as a URL (e.g. http://127.0.0.1:8080) or as is (e.g. 127.0.0.1:8080), or an error if
+// it is invalid. If no replay proxy is defined, it returns an empty string.
+func (cfg Config) ReplayProxyAddress() (string, error) {
+	raw := strings.TrimSpace(cfg.ReplayProxy)
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	addr := raw
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", err
+		}
+
+		if !strings.EqualFold(u.Scheme, "http") {
+			return "", fmt.Errorf("%w: %s, expected: http", errInvalidReplayProxyScheme, u.Scheme)
+		}
+
+		addr = u.Host
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return "", err
+	}
+
+	return addr, nil
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 
 	scan "github.com/bountysecurity/gbounty/internal"
 	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/dotenv"
 	"github.com/bountysecurity/gbounty/kit/logger"
 	"github.com/bountysecurity/gbounty/kit/url"
 )
@@ -32,114 +32,413 @@ var (
 // PrepareTemplates takes a [Config] and a [scan.FileSystem], and uses the first one to
 // initialize the [Template] instances that compound the scan defined by that configuration,
 // and stores them into the given file system, so it is ready for the scan to start.
-func PrepareTemplates(ctx context.Context, fs scan.FileSystem, cfg Config) error {
+//
+// If given, the [scan.LoginSession] (see [Config.LoginSequence]) seeds the templates: the values
+// extracted are expanded as variables, and the cookies set are sent along with them.
+//
+// If given, the [SeenHosts] (see [Config.SeenHosts]) collects the hosts targeted by the templates,
+// and skips those already seen in previous runs, if so (see [Config.OnlyNewHosts]).
+//
+// It also returns the amount of templates dropped by the url filters, and those skipped by
+// the skip predicate (see [InputsDropped]).
+func PrepareTemplates(ctx context.Context, fs scan.FileSystem, cfg Config, session *scan.LoginSession, seen *SeenHosts) (InputsDropped, error) {
+	dropped := new(InputsDropped)
+	err := prepareTemplates(ctx, fs, cfg, session, seen, dropped, nil)
+
+	return *dropped, err
+}
+
+// ValidateTemplates is like [PrepareTemplates], but instead of failing on the first
+// error, it keeps going and returns all the errors found (joined), like invalid URLs,
+// malformed headers or request files that cannot be parsed.
+//
+// It is meant to validate the scan inputs, with no requests sent, so the given
+// [scan.FileSystem] is expected to be a disposable one (e.g. in-memory). Errors
+// on the [Config] itself (e.g. an invalid env file) are not reported, as those
+// are already reported by [Config.ValidateAll].
+func ValidateTemplates(ctx context.Context, fs scan.FileSystem, cfg Config) error {
+	iss := new(issues)
+	if err := prepareTemplates(ctx, fs, cfg, nil, nil, new(InputsDropped), iss); err != nil {
+		iss.errs = append(iss.errs, err)
+	}
+
+	return errors.Join(iss.errs...)
+}
+
+// issues collects the errors found while preparing templates (see [ValidateTemplates]).
+// A nil *issues means fail-fast, so errors are returned as soon as they are found.
+type issues struct {
+	errs []error
+}
+
+// report returns the given error if fail-fast, or collects it and returns nil otherwise.
+func (iss *issues) report(err error) error {
+	if iss == nil {
+		return err
+	}
+
+	iss.errs = append(iss.errs, err)
+
+	return nil
+}
+
+// skip collects the given error only when validating, as it's otherwise skipped (with a warning),
+// like invalid lines on the urls file, so it's never returned.
+func (iss *issues) skip(err error) {
+	if iss != nil {
+		iss.errs = append(iss.errs, err)
+	}
+}
+
+// config logs the given error, found while reading the given [Config] setting (if any), and returns
+// it if fail-fast. When validating, it isn't collected, as it is already reported by [Config.ValidateAll].
+func (iss *issues) config(ctx context.Context, setting string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	logger.For(ctx).Errorf("Error while reading %s: %s", setting, err.Error())
+	if iss != nil {
+		return nil
+	}
+
+	return err
+}
+
+func prepareTemplates(ctx context.Context, fs scan.FileSystem, cfg Config, session *scan.LoginSession, seen *SeenHosts, dropped *InputsDropped, iss *issues) error {
 	pCfg := scan.ParamsCfg{}
-	if len(cfg.ParamsFile) > 0 {
-		params, err := readParamsFile(ctx, cfg.ParamsFile)
+	noEntrypoints := cfg.NoEntrypoints || cfg.Passive
+	if len(cfg.ParamsFile) > 0 && noEntrypoints {
+		logger.For(ctx).Warnf("Params file (%s) ignored: entrypoints are disabled", cfg.ParamsFile)
+	}
+
+	if len(cfg.ParamsFile) > 0 && !noEntrypoints {
+		params, sets, err := readParamsFile(ctx, cfg.ParamsFile)
 		switch err {
 		case nil:
 			pCfg.Params = params
+			pCfg.Sets = sets
 			pCfg.Size = cfg.ParamsSplit
 			pCfg.Method = strings.ToUpper(cfg.ParamsMethod)
 			pCfg.Encoding = strings.ToLower(cfg.ParamsEncoding)
+			pCfg.MaxVariants = cfg.MaxTemplateVariants
 		default:
 			logger.For(ctx).Errorf("Error while reading params file: %s", err.Error())
+			iss.skip(fmt.Errorf("could not read params file(%s): %w", cfg.ParamsFile, err))
 		}
 	}
-	return createTemplates(ctx, fs, cfg, pCfg)
+
+	vars, err := cfg.Variables()
+	if err := iss.config(ctx, "env file", err); err != nil {
+		return err
+	}
+
+	// It must be the first decorator (i.e. the last one applied),
+	// so headers are removed once all of them have been inherited.
+	removed, err := cfg.RemovedHeaders()
+	if err := iss.config(ctx, "headers to remove", err); err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		logger.For(ctx).Infof("HTTP headers (%s) will be removed from scan templates", strings.Join(removed, ", "))
+		fs = headerRemovingFS{FileSystem: fs, keys: removed}
+	}
+
+	if session != nil {
+		logger.For(ctx).Infof("Login session values (%d) and cookies will be set into scan templates", len(session.Variables))
+		vars = withSessionVariables(vars, session)
+		fs = sessionFS{FileSystem: fs, session: session}
+	}
+
+	rules, err := cfg.priorityRules()
+	if err := iss.config(ctx, "priority rules", err); err != nil {
+		return err
+	}
+
+	if len(rules) > 0 {
+		logger.For(ctx).Infof("Priority rules (%d) will be applied to scan templates", len(rules))
+		fs = prioritizingFS{FileSystem: fs, rules: rules}
+	}
+
+	filter, err := cfg.urlFilter()
+	if err := iss.config(ctx, "url filters", err); err != nil {
+		return err
+	}
+
+	predicate, err := cfg.SkipPredicate()
+	if err := iss.config(ctx, "skip predicate", err); err != nil {
+		return err
+	}
+
+	// The hosts are only collected (and skipped) once the templates pass
+	// the url filters and the skip predicate (i.e. these'd be scanned).
+	if seen != nil {
+		fs = seenHostsFS{FileSystem: fs, seen: seen}
+		defer func() {
+			logger.For(ctx).Infof("Hosts skipped as already seen (--only-new-hosts): %d", seen.Skipped())
+		}()
+	}
+
+	if predicate != nil {
+		if undefined := undefinedSkipVariables(predicate, vars); len(undefined) > 0 {
+			logger.For(ctx).Warnf("Skip predicate (--skip-if) references undefined variable(s): %s (skipped: %t)", strings.Join(undefined, ", "), cfg.SkipIfUndefined)
+		}
+
+		fs = skippingFS{FileSystem: fs, predicate: predicate, vars: vars, skipUndefined: cfg.SkipIfUndefined, dropped: dropped}
+		defer func() {
+			logger.For(ctx).Infof("Scan templates skipped by predicate (--skip-if): %d", dropped.BySkipIf)
+		}()
+	}
+
+	if filter.isEmpty() {
+		return createTemplates(ctx, fs, cfg, pCfg, vars, iss)
+	}
+
+	fs = filteringFS{FileSystem: fs, filter: filter, dropped: dropped}
+	err = createTemplates(ctx, fs, cfg, pCfg, vars, iss)
+
+	logger.For(ctx).Infof("Scan templates dropped by url filters: %d (--url-match), %d (--url-reject), %d (--exclude-extensions)", dropped.ByURLMatch, dropped.ByURLReject, dropped.ByExtension)
+
+	return err
 }
 
-func readParamsFile(ctx context.Context, pathToFile string) ([]string, error) {
+// readParamsFile reads the params file from the given path, with the params scoped by
+// insertion point type (i.e. with the "#applies-to:" directive) as sets (see [scan.ParseParams]).
+func readParamsFile(ctx context.Context, pathToFile string) ([]string, []scan.ParamsSet, error) {
 	logger.For(ctx).Infof("Reading params file from: %s", pathToFile)
 
 	contents, err := os.ReadFile(pathToFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var params []string
-	scanner := bufio.NewScanner(bytes.NewReader(contents))
-	for scanner.Scan() {
-		params = append(params, strings.TrimSpace(scanner.Text()))
+	params, sets, err := scan.ParseParams(contents)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return params, nil
+	if len(sets) > 0 {
+		logger.For(ctx).Infof("Params sets (%d) scoped by insertion point type (#applies-to) read from: %s", len(sets), pathToFile)
+	}
+
+	return params, sets, nil
 }
 
-func createTemplates(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg) error {
+func createTemplates(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg, vars map[string]string, iss *issues) error {
 	logger.For(ctx).Info("Preparing templates for scan")
 
+	// Templates are sent with the given protocol version, if any, no matter where these come from.
+	if proto, _ := cfg.RequestLineProto(); len(proto) > 0 {
+		logger.For(ctx).Infof("Request line protocol version is set to: %s", proto)
+		fs = protoFS{FileSystem: fs, proto: proto}
+	}
+
+	// Templates are sent as WebSocket opening handshakes, no matter where these come from.
+	if cfg.WebSocket {
+		// The subprotocols are already validated, see [Config.Validate].
+		protocols, _ := cfg.WebSocketSubprotocols()
+		logger.For(ctx).Infof("Scan templates are sent as WebSocket upgrades, with subprotocols: %v", protocols)
+		fs = webSocketFS{FileSystem: fs, protocols: protocols}
+	}
+
+	// Templates read from files are expanded once stored, while
+	// those built from config are built from already expanded values.
+	filesFS := fs
+	if len(vars) > 0 {
+		logger.For(ctx).Infof("Variables (%d) will be expanded into scan templates", len(vars))
+		cfg = cfg.expand(vars)
+		filesFS = expandingFS{FileSystem: fs, vars: vars}
+	}
+
 	if len(cfg.RequestsFile) > 0 {
 		logger.For(ctx).Infof("Scan templates from requests file: %s", cfg.RequestsFile)
-		return createFromRequestsFile(ctx, fs, cfg.RequestsFile, pCfg)
+		return createFromRequestsFile(ctx, filesFS, cfg.RequestsFile, pCfg, iss)
 	}
 
 	if len(cfg.RawRequests) > 0 {
 		logger.For(ctx).Infof("Scan templates from raw requests: %s", cfg.RawRequests)
-		return createFromRawRequestFiles(ctx, fs, cfg.RawRequests, pCfg)
+		return createFromRawRequestFiles(ctx, filesFS, cfg, pCfg, iss)
+	}
+
+	if len(cfg.CSVFile) > 0 {
+		logger.For(ctx).Infof("Scan templates from csv file: %s", cfg.CSVFile)
+		return createFromCSVFile(ctx, filesFS, cfg, pCfg, iss)
+	}
+
+	if len(cfg.PCAPFile) > 0 {
+		logger.For(ctx).Infof("Scan templates from pcap file: %s", cfg.PCAPFile)
+		return createFromPCAPFile(ctx, filesFS, cfg.PCAPFile, pCfg, iss)
 	}
 
 	if len(cfg.UrlsFile) > 0 {
 		logger.For(ctx).Info("Updating config with urls file")
 
-		err := updateConfigWithURLS(ctx, &cfg)
+		err := updateConfigWithURLS(ctx, &cfg, vars, iss)
 		if err != nil {
 			logger.For(ctx).Errorf("Error while updating config with urls file: %s", err.Error())
+			if err := iss.report(err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.cidrsDefined() {
+		logger.For(ctx).Info("Updating config with cidr range(s)")
+
+		// Ranges that are invalid, or too large (see [Config.MaxCIDRURLs]), are a hard
+		// error, so the scan never goes on without them (i.e. with no targets).
+		urls, err := cfg.CIDRURLs()
+		if err := iss.config(ctx, "cidr range(s)", err); err != nil {
 			return err
 		}
+
+		logger.For(ctx).Infof("CIDR range(s) expanded into %d url(s)", len(urls))
+		cfg.URLS = append(cfg.URLS, urls...)
+	}
+
+	options, err := requestOptions(ctx, cfg, iss)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Stream {
+		logger.For(ctx).Infof("Scan templates from stream (stdin)")
+		return createFromStream(ctx, fs, pCfg, vars, options, iss)
+	}
+
+	if cfg.Discover {
+		logger.For(ctx).Infof("Scan templates from wordlist")
+		return createFromWordlist(ctx, fs, cfg, options, iss)
 	}
 
 	logger.For(ctx).Infof("Scan templates from config")
-	return createFromConfig(ctx, fs, cfg, pCfg)
+	return createFromConfig(ctx, fs, cfg, pCfg, options, iss)
 }
 
-func createFromRequestsFile(ctx context.Context, fs scan.FileSystem, path string, pCfg scan.ParamsCfg) error {
+func createFromRequestsFile(ctx context.Context, fs scan.FileSystem, path string, pCfg scan.ParamsCfg, iss *issues) error {
 	file, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
 	}
 
-	templates, err := scan.TemplatesFromZipBytes(ctx, pCfg, file)
+	err = scan.EachTemplateFromZipBytes(ctx, pCfg, file, newTemplateStore(ctx, fs).store)
 	if err != nil {
-		return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
 	}
 
-	for _, template := range templates {
-		err := fs.StoreTemplate(ctx, template)
-		if err != nil {
-			return fmt.Errorf("%w(%s) %s", ErrProcessRequestFile, path, err.Error())
+	return nil
+}
+
+// createFromCSVFile creates the templates from the CSV file (see [Config.CSVFile]), one per row.
+// Malformed rows are skipped (with a warning), unless when validating or in strict mode (see [Config.CSVStrict]).
+func createFromCSVFile(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg, iss *issues) error {
+	path := cfg.CSVFile
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
+	}
+
+	skipped, err := scan.EachTemplateFromCSV(ctx, pCfg, file, newTemplateStore(ctx, fs).store)
+	if err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
+	}
+
+	if len(skipped) == 0 || (!cfg.CSVStrict && iss == nil) {
+		return nil
+	}
+
+	for _, rowErr := range skipped {
+		if err := iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, rowErr.Error())); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func createFromRawRequestFiles(ctx context.Context, fs scan.FileSystem, paths MultiValue, pCfg scan.ParamsCfg) error {
-	var tplIdx int
+// createFromPCAPFile creates the templates from the capture file (see [Config.PCAPFile]), one per
+// HTTP request captured. Encrypted, partial and malformed streams are skipped (with a warning).
+func createFromPCAPFile(ctx context.Context, fs scan.FileSystem, path string, pCfg scan.ParamsCfg, iss *issues) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
+	}
+
+	skipped, err := scan.EachTemplateFromPCAP(ctx, pCfg, file, newTemplateStore(ctx, fs).store)
+	if err != nil {
+		return iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error()))
+	}
+
+	if skipped.Total() > 0 {
+		logger.For(ctx).Warnf("Skipped %d tcp stream(s) from pcap file (%d encrypted, %d malformed): %s",
+			skipped.Total(), skipped.Encrypted, skipped.Malformed, path)
+	}
+
+	return nil
+}
+
+// createFromRawRequestFiles creates the templates from the raw request files (see [Config.RawRequests]),
+// each one built as defined by its sidecar file (see [rawRequestOpts]), if any. Those files whose
+// sidecar file cannot be parsed are skipped (with a warning), unless when validating.
+func createFromRawRequestFiles(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg, iss *issues) error {
+	paths, err := rawRequestPaths(cfg.RawRequests)
+	if err != nil {
+		return iss.report(err)
+	}
+
+	tplStore := newTemplateStore(ctx, fs)
 	for _, path := range paths {
 		bytes, err := os.ReadFile(path)
 		if err != nil {
-			return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+			if err := iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var (
+			filePCfg = pCfg
+			options  []request.Option
+		)
+
+		opts, err := readRawRequestOpts(path)
+		if err == nil && opts != nil {
+			logger.For(ctx).Infof("Raw request (%s) options read from sidecar file", path)
+			options = opts.requestOptions()
+			filePCfg, err = opts.paramsCfg(ctx, cfg, pCfg)
 		}
 
-		templates, err := scan.TemplateFromRawBytes(ctx, tplIdx, pCfg, bytes)
 		if err != nil {
-			return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+			logger.For(ctx).Warnf("Skipping raw request file (%s): %s", path, err.Error())
+			iss.skip(err)
+			continue
+		}
+
+		if cfg.PreserveLineEndings {
+			options = append(options, request.WithLineEndings(bytes))
+		}
+
+		err = scan.EachTemplateFromRawBytes(ctx, tplStore.idx, filePCfg, bytes, tplStore.store, options...)
+		if tplStore.err != nil {
+			return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, tplStore.err.Error())
 		}
 
-		for _, tpl := range templates {
-			tplIdx++
-			err = fs.StoreTemplate(ctx, tpl)
-			if err != nil {
-				return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())
+		if err != nil {
+			if err := iss.report(fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, path, err.Error())); err != nil {
+				return err
 			}
+			continue
 		}
 	}
 
 	return nil
 }
 
-func createFromConfig(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg) error {
+// requestOptions returns the [request.Option] defined by the [Config],
+// used to build the request templates from urls (e.g. the HTTP method).
+func requestOptions(ctx context.Context, cfg Config, iss *issues) ([]request.Option, error) {
 	var options []request.Option
 
 	if len(cfg.Method) > 0 {
@@ -160,7 +459,10 @@ func createFromConfig(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg
 		for _, header := range cfg.Headers {
 			key, value, found := strings.Cut(header, ":")
 			if !found {
-				return fmt.Errorf("%w: %s", ErrInvalidHeader, header)
+				if err := iss.report(fmt.Errorf("%w: %s", ErrInvalidHeader, header)); err != nil {
+					return nil, err
+				}
+				continue
 			}
 
 			key = strings.TrimSpace(key)
@@ -169,33 +471,70 @@ func createFromConfig(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg
 		}
 	}
 
-	var tplIdx int
+	return options, nil
+}
+
+func createFromConfig(ctx context.Context, fs scan.FileSystem, cfg Config, pCfg scan.ParamsCfg, options []request.Option, iss *issues) error {
+	tplStore := newTemplateStore(ctx, fs)
 	for _, cfgURL := range cfg.URLS {
 		err := url.Validate(&cfgURL) //nolint:gosec,scopelint
 		if err != nil {
 			logger.For(ctx).Errorf("Error while validating url (%s): %s", cfgURL, err.Error())
 
+			if err := iss.report(err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := createFromURL(ctx, tplStore, cfgURL, pCfg, options); err != nil {
 			return err
 		}
+	}
 
-		reqWithOpts := request.WithOptions(cfgURL, options...)
-		templates := pCfg.Alter(scan.NewTemplate(ctx, tplIdx, reqWithOpts, nil))
+	return nil
+}
 
-		for _, tpl := range templates {
-			tplIdx++
-			err = fs.StoreTemplate(ctx, tpl)
-			if err != nil {
-				logger.For(ctx).Errorf("Error while building scan template: %s", err.Error())
+// createFromURL creates the templates from the given (already validated) url,
+// with the given options, and stores them into the given [templateStore].
+func createFromURL(ctx context.Context, tplStore *templateStore, u string, pCfg scan.ParamsCfg, options []request.Option) error {
+	reqWithOpts := request.WithOptions(u, options...)
 
-				return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, cfgURL, err.Error())
-			}
-		}
+	err := pCfg.AlterEach(ctx, scan.NewTemplate(ctx, tplStore.idx, reqWithOpts, nil), tplStore.store)
+	if err != nil {
+		logger.For(ctx).Errorf("Error while building scan template: %s", err.Error())
+
+		return fmt.Errorf("%w(%s): %s", ErrProcessRequestFile, u, err.Error())
 	}
 
 	return nil
 }
 
-func updateConfigWithURLS(ctx context.Context, cfg *Config) error {
+// templateStore stores the templates into a [scan.FileSystem], one by one, as these are built (see
+// [scan.ParamsCfg.AlterEach]), so neither the variants nor the templates read from a file (e.g. a CSV
+// file) are kept in memory. Thus, [templateStore.store] is the callback given to every importer.
+//
+// It also keeps track of the index of the next template, and of the error returned by the file
+// system, if any, so it can be told apart from those returned while building the templates.
+type templateStore struct {
+	ctx context.Context
+	fs  scan.FileSystem
+	idx int
+	err error
+}
+
+func newTemplateStore(ctx context.Context, fs scan.FileSystem) *templateStore {
+	return &templateStore{ctx: ctx, fs: fs}
+}
+
+// store stores the given [scan.Template] into the file system.
+func (s *templateStore) store(tpl scan.Template) error {
+	s.idx++
+	s.err = s.fs.StoreTemplate(s.ctx, tpl)
+	return s.err
+}
+
+func updateConfigWithURLS(ctx context.Context, cfg *Config, vars map[string]string, iss *issues) error {
 	file, err := os.Open(cfg.UrlsFile)
 	if err != nil {
 		var pathErr *os.PathError
@@ -204,17 +543,24 @@ func updateConfigWithURLS(ctx context.Context, cfg *Config) error {
 			return fmt.Errorf("%w(%s): %s", ErrProcessUrlsFile, cfg.UrlsFile, pathErr.Err) //nolint:errorlint
 		}
 
-		return fmt.Errorf("%w(%s): %s", ErrProcessUrlsFile, cfg.OutPath, err) //nolint:errorlint
+		return fmt.Errorf("%w(%s): %s", ErrProcessUrlsFile, cfg.UrlsFile, err) //nolint:errorlint
 	}
 	defer file.Close()
 
+	var lineNum int
+
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := scanner.Text()
+		lineNum++
+		line := dotenv.Expand(scanner.Text(), vars)
 
 		err := url.Validate(&line)
 		if err != nil {
 			logger.For(ctx).Warnf("Skipping url(s) file (%s) line (%s) - not a valid url: %s", cfg.UrlsFile, line, err.Error())
+			// Blank lines are skipped silently.
+			if len(strings.TrimSpace(scanner.Text())) > 0 {
+				iss.skip(fmt.Errorf("%w(%s:%d): %s", ErrProcessUrlsFile, cfg.UrlsFile, lineNum, err.Error()))
+			}
 			continue
 		}
 
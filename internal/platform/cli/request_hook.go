@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+const defaultHookFailureMode = string(scan.RequestHookSkip)
+
+var (
+	errInvalidRequestHookTimeout  = errors.New("the request hook timeout (--request-hook-timeout) must be positive")
+	errRequestHookOptsWithoutHook = errors.New("the request hook timeout (--request-hook-timeout) and failure mode (--hook-failure-mode) can only be used in combination with the request hook (--request-hook)")
+)
+
+// Hook returns the [scan.RequestHook] defined by [Config.RequestHook], [Config.RequestHookTimeout]
+// and [Config.HookFailureMode], or an error if any of those is invalid (e.g. the command cannot be found).
+//
+// If no [Config.RequestHook] is defined, it returns nil (i.e. requests are sent as is).
+func (cfg Config) Hook() (*scan.RequestHook, error) {
+	fields := strings.Fields(cfg.RequestHook)
+	if len(fields) == 0 {
+		if cfg.RequestHookTimeout != scan.DefaultRequestHookTimeout || cfg.HookFailureMode != defaultHookFailureMode {
+			return nil, errRequestHookOptsWithoutHook
+		}
+		return nil, nil //nolint:nilnil
+	}
+
+	command, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RequestHookTimeout <= 0 {
+		return nil, errInvalidRequestHookTimeout
+	}
+
+	mode, err := scan.RequestHookFailureModeFrom(cfg.HookFailureMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &scan.RequestHook{
+		Command:     command,
+		Args:        fields[1:],
+		Timeout:     cfg.RequestHookTimeout,
+		FailureMode: mode,
+	}, nil
+}
+
+func (cfg Config) checkValidRequestHook() error {
+	if _, err := cfg.Hook(); err != nil {
+		return fmt.Errorf(`the provided request hook is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
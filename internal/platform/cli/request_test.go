@@ -0,0 +1,67 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func Test_issues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errTest := errors.New("test")
+
+	t.Run("fail-fast", func(t *testing.T) {
+		t.Parallel()
+
+		var iss *issues
+
+		require.ErrorIs(t, iss.report(errTest), errTest)
+		require.ErrorIs(t, iss.config(ctx, "test", errTest), errTest)
+		require.NoError(t, iss.config(ctx, "test", nil))
+		iss.skip(errTest)
+	})
+
+	t.Run("validating", func(t *testing.T) {
+		t.Parallel()
+
+		iss := new(issues)
+
+		// Config errors aren't collected, as these are already reported.
+		require.NoError(t, iss.report(errTest))
+		require.NoError(t, iss.config(ctx, "test", errTest))
+		iss.skip(errTest)
+
+		assert.Equal(t, []error{errTest, errTest}, iss.errs)
+	})
+}
+
+func Test_templateStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	// Templates are stored as these are built, with the index of the next one kept.
+	tplStore := newTemplateStore(ctx, fs)
+	pCfg := scan.ParamsCfg{Params: []string{"a", "b"}, Size: 1, Method: "GET", Encoding: "url"}
+	tpl := scan.NewTemplate(ctx, tplStore.idx, request.Default("https://example.org"), nil)
+	require.NoError(t, pCfg.AlterEach(ctx, tpl, tplStore.store))
+
+	templates := storedTemplates(t, fs)
+	assert.Len(t, templates, tplStore.idx)
+	assert.Greater(t, tplStore.idx, 1)
+	assert.NoError(t, tplStore.err)
+}
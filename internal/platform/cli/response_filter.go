@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// ResponseFilter returns the [scan.ResponseFilter] defined by [Config.OnlyStatus],
+// [Config.OnlyErrors], [Config.SuppressSuccess] and [Config.OnlyDiff], or an error
+// if any of the status codes (or classes) is invalid.
+//
+// [Config.OnlyErrors] is equivalent to 4xx,5xx within [Config.OnlyStatus],
+// while [Config.SuppressSuccess] denies the 2xx class.
+func (cfg Config) ResponseFilter() (scan.ResponseFilter, error) {
+	filter := scan.ResponseFilter{OnlyDiff: cfg.OnlyDiff}
+
+	if len(strings.TrimSpace(cfg.OnlyStatus)) > 0 {
+		for _, status := range strings.Split(cfg.OnlyStatus, ",") {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if !scan.IsStatusFilter(status) {
+				return scan.ResponseFilter{}, fmt.Errorf(`status must be either a code (e.g. 302) or a class (e.g. 4xx): "%s"`, status) //nolint:err113
+			}
+
+			filter.Allow = append(filter.Allow, status)
+		}
+	}
+
+	if cfg.OnlyErrors {
+		filter.Allow = append(filter.Allow, "4xx", "5xx")
+	}
+
+	if cfg.SuppressSuccess {
+		filter.Deny = append(filter.Deny, "2xx")
+	}
+
+	return filter, nil
+}
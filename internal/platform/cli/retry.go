@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+const defaultMaxRetries = 2
+
+var (
+	errInvalidMaxRetries      = errors.New("the maximum amount of retries (--max-retries) cannot be negative")
+	errMaxRetriesWithoutRetry = errors.New("the maximum amount of retries (--max-retries) can only be used in combination with the status codes to retry (--retry-on-status)")
+)
+
+// StatusRetry returns the [scan.StatusRetryCfg] defined by [Config.RetryOnStatus]
+// and [Config.MaxRetries], or an error if any of those is invalid.
+//
+// If no [Config.RetryOnStatus] is defined, it returns an empty [scan.StatusRetryCfg] (i.e. never retried).
+func (cfg Config) StatusRetry() (scan.StatusRetryCfg, error) {
+	statuses, err := scan.ParseRetryStatuses(cfg.RetryOnStatus)
+	if err != nil {
+		return scan.StatusRetryCfg{}, err
+	}
+
+	if cfg.MaxRetries < 0 {
+		return scan.StatusRetryCfg{}, errInvalidMaxRetries
+	}
+
+	if len(statuses) == 0 && cfg.MaxRetries != defaultMaxRetries {
+		return scan.StatusRetryCfg{}, errMaxRetriesWithoutRetry
+	}
+
+	return scan.StatusRetryCfg{
+		Statuses:   statuses,
+		MaxRetries: cfg.MaxRetries,
+		Backoff:    scan.DefaultRetryBackoff,
+	}, nil
+}
+
+func (cfg Config) checkValidStatusRetry() error {
+	if _, err := cfg.StatusRetry(); err != nil {
+		return fmt.Errorf(`the provided status retry is invalid: %s`, err.Error()) //nolint:err113
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/osext"
+)
+
+var (
+	errOnlyNewHostsWithoutSeenHostsFile = errors.New("you must specify the seen hosts file (--seen-hosts-file) to make use of --only-new-hosts")
+	errSeenHostsFileLocked              = errors.New("the seen hosts file is locked by another run")
+)
+
+const (
+	// seenHostsLockRetry is the time waited between attempts to acquire the seen hosts file's lock.
+	seenHostsLockRetry = 100 * time.Millisecond
+	// seenHostsLockTimeout is the time waited to acquire the seen hosts file's lock, before giving up.
+	seenHostsLockTimeout = 30 * time.Second
+	// seenHostsStaleLock is the age a seen hosts file's lock is considered stale (e.g. left
+	// behind by a run that crashed) after, and thus removed.
+	seenHostsStaleLock = 2 * time.Minute
+)
+
+// SeenHosts is the state of the hosts scanned across runs, read from (and recorded into)
+// [Config.SeenHostsFile], one (lowercase) host per line. Empty lines and comments (#) are ignored.
+//
+// While preparing the templates (see [PrepareTemplates]), the hosts targeted are collected,
+// and those already seen are skipped, if [Config.OnlyNewHosts]. Once the scan finishes, the
+// newly-seen ones are appended into the file (see [SeenHosts.Record]).
+type SeenHosts struct {
+	path string
+	only bool
+
+	mu      sync.Mutex
+	seen    map[string]struct{}
+	skipped map[string]struct{}
+	scanned map[string]struct{}
+}
+
+// SeenHosts returns the [SeenHosts] read from [Config.SeenHostsFile], if any, or nil otherwise.
+// A file that doesn't exist (yet) means no host has been seen, so it is created once recorded.
+func (cfg Config) SeenHosts() (*SeenHosts, error) {
+	if len(cfg.SeenHostsFile) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	seen, err := readSeenHosts(cfg.SeenHostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SeenHosts{
+		path:    cfg.SeenHostsFile,
+		only:    cfg.OnlyNewHosts,
+		seen:    seen,
+		skipped: make(map[string]struct{}),
+		scanned: make(map[string]struct{}),
+	}, nil
+}
+
+func (cfg Config) checkValidSeenHosts() error {
+	if cfg.OnlyNewHosts && len(cfg.SeenHostsFile) == 0 {
+		return errOnlyNewHostsWithoutSeenHostsFile
+	}
+	if _, err := cfg.SeenHosts(); err != nil {
+		return fmt.Errorf(`the provided seen hosts file (--seen-hosts-file) is invalid: %s`, err.Error()) //nolint:err113
+	}
+	return nil
+}
+
+// Skipped returns the amount of hosts skipped as already seen (see [Config.OnlyNewHosts]).
+// It is nil-safe, so it returns zero if there's no [SeenHosts].
+func (s *SeenHosts) Skipped() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.skipped)
+}
+
+// skip returns whether the given host must be skipped, as already seen,
+// or collects it as scanned otherwise, so it is recorded afterward.
+func (s *SeenHosts) skip(host string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.seen[host]; ok && s.only {
+		s.skipped[host] = struct{}{}
+		return true
+	}
+
+	s.scanned[host] = struct{}{}
+	return false
+}
+
+// Record appends the hosts scanned (i.e. not skipped), not seen yet, into the seen hosts file,
+// and returns how many of them. The file is re-read and replaced atomically (i.e. written into
+// a temporary file, then renamed) while locked (see [lockSeenHosts]), so the hosts recorded by
+// concurrent runs in the meantime are kept. It is nil-safe, so it does nothing if there's no [SeenHosts].
+func (s *SeenHosts) Record(ctx context.Context) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockSeenHosts(ctx, s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	contents, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	seen := parseSeenHosts(contents)
+
+	var hosts []string
+	for host := range s.scanned {
+		if _, ok := seen[host]; !ok {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return 0, nil
+	}
+
+	sort.Strings(hosts)
+
+	if len(contents) > 0 && !bytes.HasSuffix(contents, []byte("\n")) {
+		contents = append(contents, '\n')
+	}
+	contents = append(contents, []byte(strings.Join(hosts, "\n")+"\n")...)
+
+	err = osext.WriteFileAtomically(s.path, 0o600, func(w io.Writer) error {
+		_, err := w.Write(contents)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, host := range hosts {
+		s.seen[host] = struct{}{}
+	}
+
+	return len(hosts), nil
+}
+
+func readSeenHosts(path string) (map[string]struct{}, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]struct{}), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSeenHosts(contents), nil
+}
+
+func parseSeenHosts(contents []byte) map[string]struct{} {
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		host := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if len(host) == 0 || strings.HasPrefix(host, "#") {
+			continue
+		}
+
+		seen[host] = struct{}{}
+	}
+
+	return seen
+}
+
+// lockSeenHosts acquires the lock of the seen hosts file at the given path, as a lock file
+// next to it (i.e. <path>.lock) created exclusively, so it works on any platform, and returns
+// the function that releases it. Stale locks (see [seenHostsStaleLock]) are removed.
+func lockSeenHosts(ctx context.Context, path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(seenHostsLockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > seenHostsStaleLock {
+			logger.For(ctx).Warnf("Removing stale lock of seen hosts file: %s", lockPath)
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", errSeenHostsFileLocked, lockPath)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(seenHostsLockRetry):
+		}
+	}
+}
+
+// seenHostsFS is a [scan.FileSystem] decorator that skips the templates targeting
+// the hosts already seen (see [Config.OnlyNewHosts]), instead of storing them, and
+// collects the hosts of those stored, so these are recorded once the scan finishes.
+type seenHostsFS struct {
+	scan.FileSystem
+	seen *SeenHosts
+}
+
+func (fs seenHostsFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	if host := seenHost(tpl); fs.seen.skip(host) {
+		logger.For(ctx).Debugf("Scan template (idx=%d) skipped, host already seen (--only-new-hosts): %s", tpl.Idx, host)
+		return nil
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
+
+// seenHost returns the (lowercase) host targeted by the given template,
+// or its URL if the host cannot be determined, like the scan does per host.
+func seenHost(tpl scan.Template) string {
+	u, err := url.Parse(tpl.URL)
+	if err != nil || len(u.Hostname()) == 0 {
+		return strings.ToLower(tpl.URL)
+	}
+
+	return strings.ToLower(u.Hostname())
+}
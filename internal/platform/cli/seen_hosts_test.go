@@ -0,0 +1,124 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeenHosts_Record(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "seen.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# seen hosts\nA.example.org"), 0o600))
+
+	seen, err := Config{SeenHostsFile: path, OnlyNewHosts: true}.SeenHosts()
+	require.NoError(t, err)
+
+	assert.True(t, seen.skip("a.example.org"))
+	assert.False(t, seen.skip("b.example.org"))
+	assert.False(t, seen.skip("c.example.org"))
+	assert.Equal(t, 1, seen.Skipped())
+
+	n, err := seen.Record(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// Once recorded, these aren't recorded again.
+	n, err = seen.Record(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# seen hosts\nA.example.org\nb.example.org\nc.example.org\n", string(b))
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestSeenHosts_Record_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const runs = 10
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "seen.txt")
+
+	// Each run scans its own host, plus one shared by all of them.
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		seen, err := Config{SeenHostsFile: path}.SeenHosts()
+		require.NoError(t, err)
+
+		seen.skip(fmt.Sprintf("host%d.example.org", i))
+		seen.skip("shared.example.org")
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := seen.Record(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, runs+1)
+	assert.Len(t, parseSeenHosts(b), runs+1)
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestSeenHosts_Record_StaleLock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "seen.txt")
+
+	lockPath := path + ".lock"
+	require.NoError(t, os.WriteFile(lockPath, nil, 0o600))
+
+	stale := time.Now().Add(-2 * seenHostsStaleLock)
+	require.NoError(t, os.Chtimes(lockPath, stale, stale))
+
+	seen, err := Config{SeenHostsFile: path}.SeenHosts()
+	require.NoError(t, err)
+	seen.skip("a.example.org")
+
+	n, err := seen.Record(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestSeenHosts_Record_Locked(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "seen.txt")
+
+	// A recent lock is held by another run, so it waits until the context is done.
+	lockPath := path + ".lock"
+	require.NoError(t, os.WriteFile(lockPath, nil, 0o600))
+
+	seen, err := Config{SeenHostsFile: path}.SeenHosts()
+	require.NoError(t, err)
+	seen.skip("a.example.org")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*seenHostsLockRetry)
+	defer cancel()
+
+	_, err = seen.Record(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.FileExists(t, lockPath)
+	assert.NoFileExists(t, path)
+}
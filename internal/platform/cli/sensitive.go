@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// allSignatures is the value of [Config.SensitiveData] that looks for all the signatures.
+const allSignatures = "all"
+
+// Signatures returns the custom [match.Signature] set read from [Config.SensitiveDataFile]
+// (see [match.ReadSignatures]), if any, looked for along with the default ones.
+func (cfg Config) Signatures() ([]match.Signature, error) {
+	if len(cfg.SensitiveDataFile) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(cfg.SensitiveDataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return match.ReadSignatures(f)
+}
+
+// FileSignatures returns the custom [match.FileSignature] set read from [Config.FileSignaturesFile]
+// (see [match.ReadFileSignatures]), if any, looked for by the File Read greps along with the default ones.
+func (cfg Config) FileSignatures() ([]match.FileSignature, error) {
+	if len(cfg.FileSignaturesFile) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(cfg.FileSignaturesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return match.ReadFileSignatures(f)
+}
+
+// CloudSignatures returns the custom [match.CloudSignature] set read from [Config.CloudSignaturesFile]
+// (see [match.ReadCloudSignatures]), if any, looked for by the Cloud Exposure greps along with the default ones.
+func (cfg Config) CloudSignatures() ([]match.CloudSignature, error) {
+	if len(cfg.CloudSignaturesFile) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(cfg.CloudSignaturesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return match.ReadCloudSignatures(f)
+}
+
+// DebugSignatures returns the custom [match.DebugSignature] set read from [Config.DebugSignaturesFile]
+// (see [match.ReadDebugSignatures]), if any, looked for by the Debug Exposure greps along with the default ones.
+func (cfg Config) DebugSignatures() ([]match.DebugSignature, error) {
+	if len(cfg.DebugSignaturesFile) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.Open(cfg.DebugSignaturesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return match.ReadDebugSignatures(f)
+}
+
+// TechnologySignatures returns the custom [match.TechnologySignatures] set read from
+// [Config.TechnologySignaturesFile] (see [match.ReadTechnologySignatures]), if any,
+// looked for by the Technology greps along with the default ones.
+func (cfg Config) TechnologySignatures() (match.TechnologySignatures, error) {
+	if len(cfg.TechnologySignaturesFile) == 0 {
+		return match.TechnologySignatures{}, nil
+	}
+
+	f, err := os.Open(cfg.TechnologySignaturesFile)
+	if err != nil {
+		return match.TechnologySignatures{}, err
+	}
+	defer f.Close()
+
+	return match.ReadTechnologySignatures(f)
+}
+
+// SensitiveDataProfile returns the [gbprofile.Response] used to report sensitive data
+// (e.g. API keys, private keys or credit cards) exposed by the responses, built on top
+// of the Sensitive Data grep, looking for the signatures defined by [Config.SensitiveData]
+// (either all or some of them, by name), from the default and custom ones (see [Config.Signatures]).
+//
+// It returns nil if [Config.SensitiveData] isn't defined, or an error if any of the signatures
+// is unknown, or if the custom signatures cannot be read.
+func (cfg Config) SensitiveDataProfile() (*gbprofile.Response, error) {
+	value := strings.TrimSpace(cfg.SensitiveData)
+	if len(value) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	custom, err := cfg.Signatures()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make([]string, 0)
+	for _, s := range append(match.DefaultSignatures(), custom...) {
+		known = append(known, s.Name)
+	}
+
+	var names []string
+	if !strings.EqualFold(value, allSignatures) {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !slices.In(known, name) {
+				return nil, fmt.Errorf("unknown signature: %s (valid ones are: %s)", name, strings.Join(known, ", ")) //nolint:err113
+			}
+			names = append(names, name)
+		}
+	}
+
+	prof := &gbprofile.Response{
+		Name:    "Sensitive Data Exposure",
+		Enabled: true,
+		Type:    gbprofile.TypePassiveRes,
+		Tags:    []string{"sensitive-data"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,%s", gbprofile.GrepTypeSensitiveData, strings.Join(names, ";")),
+		},
+		IssueName:       "Sensitive Data Exposure",
+		IssueSeverity:   "Medium",
+		IssueConfidence: "Tentative",
+		IssueDetail: "The response exposes sensitive data, like API keys, tokens, private keys, " +
+			"emails or credit card numbers. The values found are partially redacted.",
+		RemediationDetail: "Remove the sensitive data from the response, and revoke (or rotate) " +
+			"any secret that might have been exposed.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+// FingerprintProfile returns the [gbprofile.Response] used to report the technology stack
+// (e.g. nginx 1.18.0 or PHP) identified by the responses, built on top of the Technology grep,
+// looking for all the signatures, both the default and custom ones (see [Config.TechnologySignatures]).
+//
+// It returns nil if [Config.Fingerprint] isn't enabled.
+func (cfg Config) FingerprintProfile() (*gbprofile.Response, error) {
+	if !cfg.Fingerprint {
+		return nil, nil //nolint:nilnil
+	}
+
+	prof := &gbprofile.Response{
+		Name:    "Technology Fingerprint",
+		Enabled: true,
+		Type:    gbprofile.TypePassiveRes,
+		Tags:    []string{"fingerprint"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,", gbprofile.GrepTypeTechnology),
+		},
+		IssueName:       "Technology Fingerprint",
+		IssueSeverity:   "Information",
+		IssueConfidence: "Firm",
+		IssueDetail: "The response discloses the technology stack (e.g. web server, language or framework), " +
+			"and its version, if any, from its headers, cookies or body.",
+		RemediationDetail: "Remove (or genericize) the headers and markers that disclose the technology stack, " +
+			"like Server or X-Powered-By, so it cannot be used to target known vulnerabilities.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
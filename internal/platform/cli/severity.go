@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// StopOnFirstFindingSeverity returns the minimum severity (see [scan.Severities]) of the findings
+// the scan is stopped on (see [Config.StopOnFirstFinding]), if any, or an error if it is invalid.
+// An empty severity means the scan is stopped on any finding.
+func (cfg Config) StopOnFirstFindingSeverity() (string, error) {
+	if len(strings.TrimSpace(cfg.StopOnFirstFinding.Value)) == 0 {
+		return "", nil
+	}
+
+	severity, ok := scan.ParseSeverity(cfg.StopOnFirstFinding.Value)
+	if !ok {
+		return "", fmt.Errorf(`invalid severity "%s" for --stop-on-first-finding, must be one of: %s`, //nolint:err113
+			strings.TrimSpace(severity), strings.Join(scan.Severities(), ", "))
+	}
+
+	return severity, nil
+}
+
+// SeverityOverrides returns the [scan.SeverityOverrides] defined by [Config.SeverityOverrideFile],
+// if any, and [Config.SeverityOverride], which take precedence, or an error if any of these is
+// malformed, or its severity is invalid (see [scan.Severities]).
+//
+// Each value has the form profile=severity (e.g. Email disclosure=Low), and so does each line of
+// the file, where blank lines and those starting with # (comments) are skipped. Profile names are
+// only known once loaded, so those are checked later (see [scan.SeverityOverrides.Check]).
+func (cfg Config) SeverityOverrides() (scan.SeverityOverrides, error) {
+	var overrides scan.SeverityOverrides
+
+	set := func(value string) error {
+		idx := strings.LastIndex(value, "=")
+		if idx < 0 || len(strings.TrimSpace(value[:idx])) == 0 {
+			return fmt.Errorf(`invalid severity override, it must be profile=severity: "%s"`, value) //nolint:err113
+		}
+
+		severity, ok := scan.ParseSeverity(value[idx+1:])
+		if !ok {
+			return fmt.Errorf(`invalid severity "%s" for profile "%s", must be one of: %s`, //nolint:err113
+				strings.TrimSpace(value[idx+1:]), strings.TrimSpace(value[:idx]), strings.Join(scan.Severities(), ", "))
+		}
+
+		if overrides == nil {
+			overrides = make(scan.SeverityOverrides)
+		}
+		overrides[strings.TrimSpace(value[:idx])] = severity
+
+		return nil
+	}
+
+	if len(cfg.SeverityOverrideFile) > 0 {
+		f, err := os.Open(cfg.SeverityOverrideFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if err := set(line); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range cfg.SeverityOverride {
+		if err := set(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return overrides, nil
+}
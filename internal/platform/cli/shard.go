@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// ScanShard returns the [scan.Shard] defined by [Config.Shard], with the form i/n,
+// or an error if it is invalid, like when i is not in the range [0, n).
+//
+// If no [Config.Shard] is defined, it returns an empty [scan.Shard] (i.e. no sharding).
+func (cfg Config) ScanShard() (scan.Shard, error) {
+	if len(strings.TrimSpace(cfg.Shard)) == 0 {
+		return scan.Shard{}, nil
+	}
+
+	idx, count, found := strings.Cut(strings.TrimSpace(cfg.Shard), "/")
+	if !found {
+		return scan.Shard{}, fmt.Errorf(`shard must be i/n: "%s"`, cfg.Shard) //nolint:err113
+	}
+
+	i, errIdx := strconv.Atoi(idx)
+	n, errCount := strconv.Atoi(count)
+	if errIdx != nil || errCount != nil || n < 1 || i < 0 || i >= n {
+		return scan.Shard{}, fmt.Errorf(`invalid shard, i must be in the range [0, n): "%s"`, cfg.Shard) //nolint:err113
+	}
+
+	return scan.Shard{Index: i, Count: n}, nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bountysecurity/gbounty/kit/sigv4"
+)
+
+// Environment variables the AWS credentials are read from, when not
+// specified (see [Config.AwsCredentials]), as the AWS CLI and SDKs do.
+const (
+	awsAccessKeyIDEnvVar     = "AWS_ACCESS_KEY_ID"
+	awsSecretAccessKeyEnvVar = "AWS_SECRET_ACCESS_KEY"
+	awsSessionTokenEnvVar    = "AWS_SESSION_TOKEN"
+)
+
+var (
+	errAwsCredentialsWithoutSigV4 = errors.New("you must specify the region and service (--aws-sigv4) to make use of --aws-credentials")
+	errMissingAwsCredentials      = errors.New("no aws credentials found, specify them with --aws-credentials, or with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	errSigV4WithAuth              = errors.New("aws sigv4 cannot be used in combination with --auth, as both rely on the Authorization header")
+	errSigV4WithSimpleHTTPVersion = errors.New("aws sigv4 cannot be used in combination with HTTP/0.9-style requests (--http-version 0.9), as those have no headers")
+)
+
+// SigV4Signer returns the [sigv4.Signer] defined by [Config.AwsSigV4], in the form of
+// region:service, with the credentials defined by [Config.AwsCredentials] or, otherwise,
+// read from the environment (e.g. AWS_ACCESS_KEY_ID), or an error if any is invalid.
+//
+// If no [Config.AwsSigV4] is defined, it returns nil.
+func (cfg Config) SigV4Signer() (*sigv4.Signer, error) {
+	if len(cfg.AwsSigV4) == 0 {
+		if len(cfg.AwsCredentials) > 0 {
+			return nil, errAwsCredentialsWithoutSigV4
+		}
+		return nil, nil //nolint:nilnil
+	}
+
+	signer, err := sigv4.ParseScope(cfg.AwsSigV4)
+	if err != nil {
+		return nil, fmt.Errorf(`%w, expected region:service (e.g. us-east-1:execute-api): "%s"`, err, cfg.AwsSigV4)
+	}
+
+	if len(cfg.AwsCredentials) > 0 {
+		signer.Credentials, err = sigv4.ParseCredentials(cfg.AwsCredentials)
+		if err != nil {
+			return nil, fmt.Errorf(`%w, expected access_key_id:secret_access_key[:session_token]`, err)
+		}
+	} else {
+		signer.Credentials = sigv4.Credentials{
+			AccessKeyID:     os.Getenv(awsAccessKeyIDEnvVar),
+			SecretAccessKey: os.Getenv(awsSecretAccessKeyEnvVar),
+			SessionToken:    os.Getenv(awsSessionTokenEnvVar),
+		}
+	}
+
+	if signer.Credentials.IsEmpty() {
+		return nil, errMissingAwsCredentials
+	}
+
+	if len(cfg.Auth) > 0 {
+		return nil, errSigV4WithAuth
+	}
+
+	if proto, _ := cfg.RequestLineProto(); proto == "HTTP/0.9" {
+		return nil, errSigV4WithSimpleHTTPVersion
+	}
+
+	return &signer, nil
+}
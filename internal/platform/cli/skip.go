@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/kit/expr"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// Template variables, available to the skip predicate (see [Config.SkipPredicate])
+// along with the variables defined (see [Config.Variables]) and extracted (see [Config.LoginSequence]).
+const (
+	skipVarMethod = "request.method"
+	skipVarURL    = "request.url"
+	skipVarHost   = "request.host"
+	skipVarPath   = "request.path"
+)
+
+// SkipPredicate returns the expression defined by [Config.SkipIf] (see [expr.Parse]), if any,
+// evaluated against each template before the scan starts, so those it holds for are skipped.
+func (cfg Config) SkipPredicate() (*expr.Expr, error) {
+	if len(cfg.SkipIf) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return expr.Parse(cfg.SkipIf)
+}
+
+// ScanTemplateFilter returns the [scan.TemplateFilter] defined by [Config.TemplateFilter], if any,
+// evaluated against each template during dispatch, so those it doesn't hold for are skipped.
+func (cfg Config) ScanTemplateFilter() (scan.TemplateFilter, error) {
+	if len(strings.TrimSpace(cfg.TemplateFilter)) == 0 {
+		return scan.TemplateFilter{}, nil
+	}
+
+	return scan.ParseTemplateFilter(cfg.TemplateFilter)
+}
+
+// skippingFS is a [scan.FileSystem] decorator that skips the templates the
+// skip predicate (see [Config.SkipPredicate]) holds for, instead of storing them.
+// When the predicate references undefined variables, the templates are either
+// skipped or not, depending on [Config.SkipIfUndefined].
+type skippingFS struct {
+	scan.FileSystem
+	predicate     *expr.Expr
+	vars          map[string]string
+	skipUndefined bool
+	dropped       *InputsDropped
+}
+
+func (fs skippingFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	skip, err := fs.predicate.Eval(templateVariables(fs.vars, tpl))
+	switch {
+	case errors.Is(err, expr.ErrUndefined):
+		logger.For(ctx).Debugf("Skip predicate (--skip-if) for template (idx=%d): %s", tpl.Idx, err.Error())
+		skip = fs.skipUndefined
+	case err != nil:
+		return fmt.Errorf("could not evaluate skip predicate (--skip-if): %w", err)
+	}
+
+	if skip {
+		logger.For(ctx).Debugf("Scan template (idx=%d) skipped by predicate (--skip-if): %s", tpl.Idx, fs.predicate)
+		fs.dropped.BySkipIf++
+		return nil
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
+
+// templateVariables returns the given variables along with those
+// describing the given template (e.g. request.path), which take precedence.
+func templateVariables(vars map[string]string, tpl scan.Template) map[string]string {
+	merged := make(map[string]string, len(vars)+4) //nolint:mnd
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	merged[skipVarMethod] = tpl.Method
+	merged[skipVarURL] = tpl.OriginalURL
+	merged[skipVarPath] = tpl.Path
+	if u, err := url.Parse(tpl.URL); err == nil {
+		merged[skipVarHost] = strings.ToLower(u.Hostname())
+	}
+
+	return merged
+}
+
+// undefinedSkipVariables returns the variables referenced by the skip predicate
+// that are neither within the given variables nor template variables.
+func undefinedSkipVariables(predicate *expr.Expr, vars map[string]string) []string {
+	var undefined []string
+	for _, name := range predicate.Vars() {
+		switch name {
+		case skipVarMethod, skipVarURL, skipVarHost, skipVarPath:
+			continue
+		}
+
+		if _, ok := vars[name]; !ok {
+			undefined = append(undefined, name)
+		}
+	}
+
+	return undefined
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/dotenv"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/panics"
+	"github.com/bountysecurity/gbounty/kit/url"
+)
+
+// ErrProcessStream is the error returned when [Config.Stream] is enabled,
+// and the standard input could not be processed successfully.
+var ErrProcessStream = errors.New("could not process stream")
+
+// StreamTemplates is like [PrepareTemplates], but for streamed scans (see [Config.Stream]), so
+// instead of waiting for all the templates to be stored, it returns the channel the templates are
+// sent through as soon as these are built (and stored), which is closed once the standard input
+// is exhausted (i.e. EOF), meant to be used with [scan.RunnerOpts.WithTemplatesStream].
+//
+// Each template is only built once the previous one has been received, so the standard
+// input is only read as fast as templates are scanned (i.e. back-pressure).
+func StreamTemplates(ctx context.Context, fs scan.FileSystem, cfg Config, session *scan.LoginSession, seen *SeenHosts) chan scan.Template {
+	ch := make(chan scan.Template)
+
+	go func() {
+		defer panics.Log(ctx)
+		defer close(ch)
+
+		dropped := new(InputsDropped)
+		if err := prepareTemplates(ctx, streamFS{FileSystem: fs, ch: ch}, cfg, session, seen, dropped, nil); err != nil {
+			logger.For(ctx).Errorf("Error while streaming scan templates: %s", err.Error())
+		}
+	}()
+
+	return ch
+}
+
+// streamFS is a [scan.FileSystem] decorator that sends the templates through the given
+// channel once stored, so these are scanned as soon as built (see [StreamTemplates]).
+type streamFS struct {
+	scan.FileSystem
+	ch chan scan.Template
+}
+
+func (fs streamFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	if err := fs.FileSystem.StoreTemplate(ctx, tpl); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case fs.ch <- tpl:
+		return nil
+	}
+}
+
+// createFromStream creates the templates from the urls read from the standard input (see [Config.Stream]),
+// one per line, as these arrive, until EOF. Lines can either be plain urls, or JSON objects (i.e. ND-JSON)
+// with the url in either the "url" or the "host" field (e.g. the output of httpx or subfinder).
+// Invalid lines are skipped (with a warning).
+//
+// When validating, the standard input isn't read, as it is only available once.
+func createFromStream(ctx context.Context, fs scan.FileSystem, pCfg scan.ParamsCfg, vars map[string]string, options []request.Option, iss *issues) error {
+	if iss != nil {
+		logger.For(ctx).Warn("Stream (--stream) not validated: urls are validated as these arrive")
+		return nil
+	}
+
+	var (
+		lineNum  int
+		tplStore = newTemplateStore(ctx, fs)
+	)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		lineNum++
+
+		raw := strings.TrimSpace(scanner.Text())
+		if len(raw) == 0 {
+			continue
+		}
+
+		line, err := streamURL(raw)
+		if err == nil {
+			line = dotenv.Expand(line, vars)
+			err = url.Validate(&line)
+		}
+
+		if err != nil {
+			logger.For(ctx).Warnf("Skipping stream line (%d) - not a valid url (%s): %s", lineNum, raw, err.Error())
+			continue
+		}
+
+		logger.For(ctx).Debugf("Scan templates from stream url: %s", line)
+
+		if err := createFromURL(ctx, tplStore, line, pCfg, options); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s", ErrProcessStream, err.Error()) //nolint:errorlint
+	}
+
+	logger.For(ctx).Infof("Stream exhausted (EOF) after %d line(s)", lineNum)
+
+	return nil
+}
+
+// streamURL returns the url from the given stream line, either as is, or from the
+// "url" field (or the "host" field, if the former is empty) if it is a JSON object.
+func streamURL(line string) (string, error) {
+	if !strings.HasPrefix(line, "{") {
+		return line, nil
+	}
+
+	var obj struct {
+		URL  string `json:"url"`
+		Host string `json:"host"`
+	}
+
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return "", err
+	}
+
+	if len(obj.URL) > 0 {
+		return obj.URL, nil
+	}
+
+	if len(obj.Host) > 0 {
+		return obj.Host, nil
+	}
+
+	return "", errors.New("no url nor host field") //nolint:err113
+}
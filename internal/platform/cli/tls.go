@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+var errNoCertificates = errors.New("no PEM certificates found")
+
+// caBundleExtensions are the extensions of the files loaded from the [Config.CABundleDir].
+var caBundleExtensions = []string{".pem", ".crt", ".cer"}
+
+// RootCAs returns the pool of root certificate authorities the targets' certificates
+// are verified against, made of the system ones plus those from the [Config.CABundle]
+// files and the [Config.CABundleDir] directory (i.e. its .pem, .crt and .cer files),
+// or an error if any of them cannot be read or has no certificates.
+//
+// If no [Config.CABundle] nor [Config.CABundleDir] is defined, it returns nil.
+func (cfg Config) RootCAs() (*x509.CertPool, error) {
+	if len(cfg.CABundle) == 0 && len(cfg.CABundleDir) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	paths := append([]string{}, cfg.CABundle...)
+	if len(cfg.CABundleDir) > 0 {
+		found, err := caBundleFiles(cfg.CABundleDir)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, path := range paths {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w in %s", errNoCertificates, path)
+		}
+	}
+
+	return pool, nil
+}
+
+// caBundleFiles returns the paths of the CA bundles within the given directory,
+// sorted by name (see [os.ReadDir]), or an error if it cannot be read or has no bundles.
+func caBundleFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !slices.In(caBundleExtensions, ext) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no CA bundles (%s) found in %s", strings.Join(caBundleExtensions, ", "), dir) //nolint:err113
+	}
+
+	return paths, nil
+}
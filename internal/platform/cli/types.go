@@ -14,3 +14,34 @@ func (m *MultiValue) Set(value string) error {
 	*m = append(*m, value)
 	return nil
 }
+
+// OptionalValue defines a command-line argument that can be used either as a boolean
+// flag (e.g. --flag) or with a value, but only in its --flag=value form, as the flag
+// is parsed as a boolean one (see [OptionalValue.IsBoolFlag]).
+type OptionalValue struct {
+	Enabled bool
+	Value   string
+}
+
+func (o *OptionalValue) String() string {
+	if o == nil || !o.Enabled {
+		return ""
+	}
+	return o.Value
+}
+
+func (o *OptionalValue) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "true":
+		o.Enabled, o.Value = true, ""
+	case "false":
+		o.Enabled, o.Value = false, ""
+	default:
+		o.Enabled, o.Value = true, value
+	}
+	return nil
+}
+
+func (o *OptionalValue) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// InputsDropped holds the amount of request templates (i.e. inputs) dropped
+// by each of the url filters ([Config.URLMatch], [Config.URLReject] and
+// [Config.ExcludeExtensions]), and those skipped by the skip predicate
+// ([Config.SkipIf]), while preparing the scan (see [PrepareTemplates]).
+type InputsDropped struct {
+	ByURLMatch  int
+	ByURLReject int
+	ByExtension int
+	BySkipIf    int
+}
+
+// defaultExcludedExtensions are the extensions of the static assets excluded by default
+// during content discovery (see [Config.ExcludedExtensions]), as requesting those wastes
+// budget. Scripts aren't, as these may disclose endpoints or secrets.
+var defaultExcludedExtensions = []string{
+	"css", "png", "jpg", "jpeg", "gif", "bmp", "ico", "svg", "webp",
+	"woff", "woff2", "ttf", "otf", "eot", "mp3", "mp4", "avi", "webm",
+}
+
+// noExcludedExtensions is the [Config.ExcludeExtensions] value that disables the
+// default excluded extensions during content discovery.
+const noExcludedExtensions = "none"
+
+// extensionRegex is the format of the excluded extensions (e.g. woff2 or tar.gz).
+var extensionRegex = regexp.MustCompile(`^[a-z0-9_-]+(\.[a-z0-9_-]+)*$`)
+
+// ExcludedExtensions returns the (lowercase) extensions defined by [Config.ExcludeExtensions]
+// (comma-separated, with or without the leading dot), or an error if any of them is invalid.
+//
+// If none is defined, it returns the [defaultExcludedExtensions] during content discovery (see
+// [Config.Discover]), unless disabled (i.e. none), or nil otherwise. So, static assets aren't
+// requested when expanding the wordlist, while other scans are left as is.
+func (cfg Config) ExcludedExtensions() ([]string, error) {
+	value := strings.TrimSpace(cfg.ExcludeExtensions)
+
+	switch {
+	case strings.EqualFold(value, noExcludedExtensions):
+		return nil, nil
+	case len(value) == 0 && cfg.Discover:
+		return defaultExcludedExtensions, nil
+	case len(value) == 0:
+		return nil, nil
+	}
+
+	var exts []string
+	for _, ext := range strings.Split(value, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if !extensionRegex.MatchString(ext) {
+			return nil, fmt.Errorf(`invalid extension: "%s"`, ext) //nolint:err113
+		}
+
+		exts = append(exts, ext)
+	}
+
+	return exts, nil
+}
+
+// urlFilter is the filter applied to the (full) URL of the request templates, defined by
+// [Config.URLMatch], [Config.URLReject] and [Config.ExcludedExtensions]. A nil regex
+// (or no extensions) means no filter.
+type urlFilter struct {
+	match       *regexp.Regexp
+	reject      *regexp.Regexp
+	excludeExts []string
+}
+
+func (f urlFilter) isEmpty() bool {
+	return f.match == nil && f.reject == nil && len(f.excludeExts) == 0
+}
+
+// excludes returns whether the path of the given URL ends with any of the
+// excluded extensions (case-insensitive). The query and the fragment are ignored.
+func (f urlFilter) excludes(rawURL string) bool {
+	if len(f.excludeExts) == 0 {
+		return false
+	}
+
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+
+	p = strings.ToLower(path.Base(p))
+	for _, ext := range f.excludeExts {
+		if strings.HasSuffix(p, "."+ext) && len(p) > len(ext)+1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// urlFilter returns the [urlFilter] defined by the [Config.URLMatch], [Config.URLReject]
+// and [Config.ExcludedExtensions], or an error if any of these is invalid.
+func (cfg Config) urlFilter() (urlFilter, error) {
+	var (
+		filter urlFilter
+		err    error
+	)
+
+	filter.excludeExts, err = cfg.ExcludedExtensions()
+	if err != nil {
+		return urlFilter{}, fmt.Errorf(`invalid excluded extensions: "%s" - %s`, cfg.ExcludeExtensions, err.Error()) //nolint:err113
+	}
+
+	if len(cfg.URLMatch) > 0 {
+		filter.match, err = regexp.Compile(cfg.URLMatch)
+		if err != nil {
+			return urlFilter{}, fmt.Errorf(`invalid url match regex: "%s" - %s`, cfg.URLMatch, err.Error()) //nolint:err113
+		}
+	}
+
+	if len(cfg.URLReject) > 0 {
+		filter.reject, err = regexp.Compile(cfg.URLReject)
+		if err != nil {
+			return urlFilter{}, fmt.Errorf(`invalid url reject regex: "%s" - %s`, cfg.URLReject, err.Error()) //nolint:err113
+		}
+	}
+
+	return filter, nil
+}
+
+// filteringFS is a [scan.FileSystem] decorator that drops the templates
+// whose (full) URL doesn't pass the [urlFilter], instead of storing them.
+// The templates built from raw requests are filtered by their reconstructed
+// URL (i.e. the target url plus the request's path).
+type filteringFS struct {
+	scan.FileSystem
+	filter  urlFilter
+	dropped *InputsDropped
+}
+
+func (fs filteringFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	// The reject filters win, so these are checked first.
+	if fs.filter.excludes(tpl.OriginalURL) {
+		fs.dropped.ByExtension++
+		return nil
+	}
+
+	if fs.filter.reject != nil && fs.filter.reject.MatchString(tpl.OriginalURL) {
+		fs.dropped.ByURLReject++
+		return nil
+	}
+
+	if fs.filter.match != nil && !fs.filter.match.MatchString(tpl.OriginalURL) {
+		fs.dropped.ByURLMatch++
+		return nil
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
@@ -0,0 +1,149 @@
+//nolint:testpackage
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestConfig_ExcludedExtensions(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		cfg      Config
+		expected []string
+		err      bool
+	}{
+		"none defined":                 {cfg: Config{}},
+		"defaults while discovering":   {cfg: Config{Discover: true}, expected: defaultExcludedExtensions},
+		"defaults disabled":            {cfg: Config{Discover: true, ExcludeExtensions: "none"}},
+		"defaults disabled, uppercase": {cfg: Config{Discover: true, ExcludeExtensions: " NONE "}},
+		"defined":                      {cfg: Config{ExcludeExtensions: "css,png"}, expected: []string{"css", "png"}},
+		"defined, replace defaults":    {cfg: Config{Discover: true, ExcludeExtensions: "css"}, expected: []string{"css"}},
+		"leading dots and spaces":      {cfg: Config{ExcludeExtensions: " .css, .PNG "}, expected: []string{"css", "png"}},
+		"compound extension":           {cfg: Config{ExcludeExtensions: "tar.gz"}, expected: []string{"tar.gz"}},
+		"invalid extension":            {cfg: Config{ExcludeExtensions: "css,p/ng"}, err: true},
+		"empty extension":              {cfg: Config{ExcludeExtensions: "css,,png"}, err: true},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exts, err := tc.cfg.ExcludedExtensions()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, exts)
+		})
+	}
+}
+
+func TestFilteringFS_StoreTemplate(t *testing.T) {
+	t.Parallel()
+
+	urls := []string{
+		"https://example.org/admin/login",
+		"https://example.org/admin/logo.PNG?v=2",
+		"https://example.org/admin/logout",
+		"https://example.org/api/users",
+		"https://example.org/static/app.css",
+		"https://example.org/static/app.js",
+		"https://example.org/.css",
+	}
+
+	tcs := map[string]struct {
+		cfg      Config
+		stored   []string
+		expected InputsDropped
+	}{
+		"no filters": {
+			cfg:    Config{},
+			stored: urls,
+		},
+		"match": {
+			cfg:      Config{URLMatch: "/admin/"},
+			stored:   []string{urls[0], urls[1], urls[2]},
+			expected: InputsDropped{ByURLMatch: 4},
+		},
+		"reject": {
+			cfg:      Config{URLReject: "logout|/static/"},
+			stored:   []string{urls[0], urls[1], urls[3], urls[6]},
+			expected: InputsDropped{ByURLReject: 3},
+		},
+		"reject wins over match": {
+			cfg:      Config{URLMatch: "/admin/", URLReject: "logout"},
+			stored:   []string{urls[0], urls[1]},
+			expected: InputsDropped{ByURLMatch: 4, ByURLReject: 1},
+		},
+		"extensions, case-insensitive and without query": {
+			cfg:      Config{ExcludeExtensions: "css,png"},
+			stored:   []string{urls[0], urls[2], urls[3], urls[5], urls[6]},
+			expected: InputsDropped{ByExtension: 2},
+		},
+		"extensions win over the rest": {
+			cfg:      Config{URLMatch: "/admin/", URLReject: "logo", ExcludeExtensions: "png"},
+			stored:   []string{urls[0]},
+			expected: InputsDropped{ByExtension: 1, ByURLReject: 1, ByURLMatch: 4},
+		},
+		"default extensions while discovering": {
+			cfg:      Config{Discover: true},
+			stored:   []string{urls[0], urls[2], urls[3], urls[5], urls[6]},
+			expected: InputsDropped{ByExtension: 2},
+		},
+		"default extensions disabled": {
+			cfg:    Config{Discover: true, ExcludeExtensions: "none"},
+			stored: urls,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			filter, err := tc.cfg.urlFilter()
+			require.NoError(t, err)
+
+			base, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+			require.NoError(t, err)
+
+			dropped := new(InputsDropped)
+			fs := filteringFS{FileSystem: base, filter: filter, dropped: dropped}
+
+			for idx, u := range urls {
+				require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, request.WithOptions(u), nil)))
+			}
+
+			assert.ElementsMatch(t, tc.stored, storedURLs(t, base))
+			assert.Equal(t, tc.expected, *dropped)
+		})
+	}
+}
+
+func TestConfig_urlFilter_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, cfg := range map[string]Config{
+		"match":      {URLMatch: "("},
+		"reject":     {URLReject: "["},
+		"extensions": {ExcludeExtensions: "c s s"},
+	} {
+		_, err := cfg.urlFilter()
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/kit/dotenv"
+)
+
+// Variables returns the set of variables that are expanded (${VAR}) into the
+// scan's requests, built from the [Config.EnvFile] and the process environment.
+//
+// By default, process environment variables take precedence over those defined
+// on the env file, unless [Config.EnvFilePriority] is set.
+//
+// If no [Config.EnvFile] is defined, it returns no variables, so no expansion
+// is performed at all.
+func (cfg Config) Variables() (map[string]string, error) {
+	if len(cfg.EnvFile) == 0 {
+		return nil, nil
+	}
+
+	fileVars, err := dotenv.ParseFile(cfg.EnvFile)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, found := strings.Cut(kv, "="); found {
+			vars[key] = value
+		}
+	}
+
+	for key, value := range fileVars {
+		if _, inEnv := vars[key]; inEnv && !cfg.EnvFilePriority {
+			continue
+		}
+		vars[key] = value
+	}
+
+	return vars, nil
+}
+
+// expand returns a copy of the [Config] with the given variables
+// expanded into the values used to build request templates.
+func (cfg Config) expand(vars map[string]string) Config {
+	expandAll := func(values MultiValue) MultiValue {
+		expanded := make(MultiValue, 0, len(values))
+		for _, v := range values {
+			expanded = append(expanded, dotenv.Expand(v, vars))
+		}
+		return expanded
+	}
+
+	cfg.URLS = expandAll(cfg.URLS)
+	cfg.Headers = expandAll(cfg.Headers)
+	cfg.Data = expandAll(cfg.Data)
+
+	return cfg
+}
+
+// expandingFS is a [scan.FileSystem] decorator that expands
+// variables into the templates before storing them.
+type expandingFS struct {
+	scan.FileSystem
+	vars map[string]string
+}
+
+func (fs expandingFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	tpl.URL = dotenv.Expand(tpl.URL, fs.vars)
+	tpl.OriginalURL = dotenv.Expand(tpl.OriginalURL, fs.vars)
+	tpl.Path = dotenv.Expand(tpl.Path, fs.vars)
+
+	headers := make(map[string][]string, len(tpl.Headers))
+	for key, values := range tpl.Headers {
+		expanded := make([]string, 0, len(values))
+		for _, v := range values {
+			expanded = append(expanded, dotenv.Expand(v, fs.vars))
+		}
+		headers[key] = expanded
+	}
+	tpl.Headers = headers
+
+	if body := dotenv.Expand(string(tpl.Body), fs.vars); body != string(tpl.Body) {
+		tpl.SetBody([]byte(body))
+	}
+
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	gbprofile "github.com/bountysecurity/gbounty/internal/profile"
+)
+
+var errWebSocketProtocolsWithoutWebSocket = errors.New("you must enable WebSocket upgrades (--websocket) to make use of --websocket-protocols")
+
+// WebSocketSubprotocols returns the WebSocket subprotocols defined by [Config.WebSocketProtocols]
+// (comma-separated), offered on the opening handshakes (i.e. Sec-WebSocket-Protocol), if any.
+func (cfg Config) WebSocketSubprotocols() ([]string, error) {
+	if len(strings.TrimSpace(cfg.WebSocketProtocols)) == 0 {
+		return nil, nil
+	}
+
+	var protocols []string
+	for _, protocol := range strings.Split(cfg.WebSocketProtocols, ",") {
+		protocol = strings.TrimSpace(protocol)
+		// Subprotocols are tokens (see RFC 6455, section 4.1).
+		if len(protocol) == 0 || strings.ContainsAny(protocol, " \t\r\n()<>@,;:\\\"/[]?={}") {
+			return nil, fmt.Errorf("invalid subprotocol: %q", protocol) //nolint:err113
+		}
+		protocols = append(protocols, protocol)
+	}
+
+	return protocols, nil
+}
+
+// WebSocketProfile returns the [gbprofile.Response] used to report the successful WebSocket
+// upgrades (i.e. 101 Switching Protocols), built on top of the WebSocket Upgrade grep,
+// along with the negotiated subprotocol, if any (see [scan.MetadataWebSocketProtocol]).
+//
+// It returns nil if [Config.WebSocket] isn't enabled.
+func (cfg Config) WebSocketProfile() (*gbprofile.Response, error) {
+	if !cfg.WebSocket {
+		return nil, nil //nolint:nilnil
+	}
+
+	prof := &gbprofile.Response{
+		Name:    "WebSocket Upgrade",
+		Enabled: true,
+		Type:    gbprofile.TypePassiveRes,
+		Tags:    []string{"websocket"},
+		Greps: []string{
+			fmt.Sprintf("true,,%s,,", gbprofile.GrepTypeWebSocketUpgrade),
+		},
+		IssueName:       "WebSocket Upgrade",
+		IssueSeverity:   "Information",
+		IssueConfidence: "Certain",
+		IssueDetail: "The endpoint accepts WebSocket connections: the opening handshake succeeded (101 Switching Protocols). " +
+			"The subprotocol negotiated (Sec-WebSocket-Protocol), if any, is reported along with the finding.",
+		RemediationDetail: "Make sure the WebSocket endpoint is meant to be exposed, and that it authenticates the connections " +
+			"and validates the Origin header, to prevent cross-site WebSocket hijacking.",
+	}
+
+	if err := prof.Validate(); err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+// webSocketFS is a [scan.FileSystem] decorator that turns the templates
+// stored into WebSocket opening handshakes (see [request.Request.SetWebSocketUpgrade]).
+type webSocketFS struct {
+	scan.FileSystem
+	protocols []string
+}
+
+func (fs webSocketFS) StoreTemplate(ctx context.Context, tpl scan.Template) error {
+	tpl.Request = tpl.Request.Clone()
+	tpl.SetWebSocketUpgrade(fs.protocols...)
+	return fs.FileSystem.StoreTemplate(ctx, tpl)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -30,10 +31,17 @@ const (
 
 	// FileTemplates is the name of the file where the scan templates are saved to.
 	FileTemplates = "templates.json"
+
+	// FileExchanges is the name of the file where the scan exchanges
+	// (i.e. requests and responses) are saved to, if enabled.
+	FileExchanges = "exchanges.json"
 )
 
 const maxCapacity = 50e6
 
+// ErrMatchNotFound is returned when the requested [scan.Match] cannot be found.
+var ErrMatchNotFound = errors.New("match not found")
+
 // Afero must implement the [scan.FileSystem] interface.
 var _ scan.FileSystem = &Afero{}
 
@@ -52,12 +60,16 @@ type Afero struct {
 
 	matchesMtx  sync.Mutex
 	matchesFile afero.File
+	matchesIdx  map[string]int64
 
 	tasksMtx  sync.Mutex
 	tasksFile afero.File
 
 	templatesMtx  sync.RWMutex
 	templatesFile afero.File
+
+	exchangesMtx  sync.Mutex
+	exchangesFile afero.File
 }
 
 // New creates a new [Afero] instance, using the given [afero.Fs] and the base path.
@@ -92,6 +104,11 @@ func New(fs afero.Fs, basePath string) (*Afero, error) {
 		return nil, err
 	}
 
+	exchangesFile, err := fs.OpenFile(exchangesFilePath(basePath), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Afero{
 		fs:       fs,
 		basePath: basePath,
@@ -101,6 +118,7 @@ func New(fs afero.Fs, basePath string) (*Afero, error) {
 		matchesFile:   matchesFile,
 		tasksFile:     tasksFile,
 		templatesFile: templatesFile,
+		exchangesFile: exchangesFile,
 	}, nil
 }
 
@@ -281,9 +299,115 @@ func (a *Afero) StoreMatch(ctx context.Context, scanMatch scan.Match) error {
 	a.matchesMtx.Lock()
 	defer a.matchesMtx.Unlock()
 
+	offset, err := a.matchesFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
 	_, err = a.matchesFile.WriteString(string(bytes) + "\n")
+	if err != nil {
+		return err
+	}
 
-	return err
+	if a.matchesIdx != nil && len(scanMatch.ID) > 0 {
+		if _, exists := a.matchesIdx[scanMatch.ID]; !exists {
+			a.matchesIdx[scanMatch.ID] = offset
+		}
+	}
+
+	return nil
+}
+
+// LoadMatch loads the [scan.Match] identified by the given id from the file system.
+// It returns [ErrMatchNotFound] if there is no match with such identifier.
+//
+// The first call builds an index (id => offset) of the stored matches, so
+// subsequent look-ups don't need to read the whole file.
+func (a *Afero) LoadMatch(ctx context.Context, id string) (*scan.Match, error) {
+	logger.For(ctx).Debugf("Loading match (id=%s) from the file system...", id)
+
+	a.matchesMtx.Lock()
+	defer a.matchesMtx.Unlock()
+
+	if a.matchesIdx == nil {
+		if err := a.indexMatches(); err != nil {
+			return nil, err
+		}
+	}
+
+	offset, ok := a.matchesIdx[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMatchNotFound, id)
+	}
+
+	// Get the current seek offset and defer reset
+	currSeekOffset, err := a.matchesFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = a.matchesFile.Seek(currSeekOffset, io.SeekStart) }()
+
+	_, err = a.matchesFile.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(a.matchesFile)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var scanMatch scan.Match
+	if err := json.Unmarshal(line, &scanMatch); err != nil {
+		return nil, err
+	}
+
+	return &scanMatch, nil
+}
+
+// indexMatches builds the matches index, by reading the whole matches file.
+// It must be called with the matches mutex held.
+func (a *Afero) indexMatches() error {
+	currSeekOffset, err := a.matchesFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = a.matchesFile.Seek(currSeekOffset, io.SeekStart) }()
+
+	_, err = a.matchesFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	idx := make(map[string]int64)
+
+	var offset int64
+
+	scanner := bufio.NewScanner(a.matchesFile)
+	buf := make([]byte, maxCapacity)
+	scanner.Buffer(buf, maxCapacity)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var scanMatch struct{ ID string }
+		if err := json.Unmarshal(line, &scanMatch); err == nil && len(scanMatch.ID) > 0 {
+			if _, exists := idx[scanMatch.ID]; !exists {
+				idx[scanMatch.ID] = offset
+			}
+		}
+
+		offset += int64(len(line)) + 1
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	a.matchesIdx = idx
+
+	return nil
 }
 
 // LoadMatches loads the [scan.Match] instances from the file system.
@@ -606,10 +730,76 @@ func (a *Afero) TemplatesIterator(ctx context.Context) (chan scan.Template, erro
 	return ch, nil
 }
 
+// StoreExchange stores the given [scan.Exchange] into the file system.
+func (a *Afero) StoreExchange(ctx context.Context, scanExchange scan.Exchange) error {
+	logger.For(ctx).Debug("Storing exchange into the file system...")
+
+	bytes, err := json.Marshal(&scanExchange)
+	if err != nil {
+		return err
+	}
+
+	a.exchangesMtx.Lock()
+	defer a.exchangesMtx.Unlock()
+
+	_, err = a.exchangesFile.WriteString(string(bytes) + "\n")
+
+	return err
+}
+
+// ExchangesIterator returns a channel that iterates over the [scan.Exchange] instances.
+//
+// It also returns a function that can be used to close the iterator (see [scan.CloseFunc]).
+// The channel is closed when the iterator is done (no more elements), when the [scan.CloseFunc]
+// is called, or when the context is canceled. Thus, the context cancellation can also be used
+// to stop the iteration.
+func (a *Afero) ExchangesIterator(ctx context.Context) (chan scan.Exchange, scan.CloseFunc, error) {
+	logger.For(ctx).Info("Reading exchanges from the file system...")
+
+	a.exchangesMtx.Lock()
+	defer a.exchangesMtx.Unlock()
+
+	exchangesFile, err := a.fs.OpenFile(exchangesFilePath(a.basePath), os.O_RDONLY, 0o755)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan scan.Exchange)
+
+	go func() {
+		defer close(ch)
+
+		scanner := bufio.NewScanner(exchangesFile)
+		buf := make([]byte, maxCapacity)
+		scanner.Buffer(buf, maxCapacity)
+
+		for scanner.Scan() {
+			var scanExchange scan.Exchange
+
+			err := json.Unmarshal(scanner.Bytes(), &scanExchange)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case ch <- scanExchange:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if scanner.Err() != nil {
+			logger.For(ctx).Errorf("Error while reading exchanges: %s", scanner.Err())
+		}
+	}()
+
+	return ch, func() { _ = exchangesFile.Close() }, nil
+}
+
 // Cleanup removes all the files from the file system.
 func (a *Afero) Cleanup(ctx context.Context) error {
 	logger.For(ctx).Info("Removing files from the file system...")
-	toClose := []afero.File{a.statsFile, a.errorsFile, a.matchesFile, a.tasksFile, a.templatesFile}
+	toClose := []afero.File{a.statsFile, a.errorsFile, a.matchesFile, a.tasksFile, a.templatesFile, a.exchangesFile}
 	for _, f := range toClose {
 		if err := f.Close(); err != nil {
 			logger.For(ctx).Errorf("Error while closing file '%s': %v", f.Name(), err)
@@ -637,3 +827,7 @@ func tasksFilePath(basePath string) string {
 func templatesFilePath(basePath string) string {
 	return fmt.Sprintf("%s/%s", basePath, FileTemplates)
 }
+
+func exchangesFilePath(basePath string) string {
+	return fmt.Sprintf("%s/%s", basePath, FileExchanges)
+}
@@ -158,6 +158,39 @@ func TestAfero_MatchesIterator(t *testing.T) {
 	assert.Equal(t, 5, numMatches)
 }
 
+func TestAfero_LoadMatch(t *testing.T) {
+	t.Parallel()
+
+	fs, basePath := initializeFsTest()
+
+	aferoFS, err := filesystem.New(fs, basePath)
+	require.NoError(t, err)
+
+	storeSomeMatches(t, aferoFS)
+
+	other := dummyMatch()
+	other.IssueName = "Another issue for tests"
+	other.ID = scan.MatchID(other)
+	require.NoError(t, aferoFS.StoreMatch(context.Background(), other))
+
+	scanMatch, err := aferoFS.LoadMatch(context.Background(), other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other, *scanMatch)
+
+	// Once indexed, newly stored matches must be found too.
+	last := dummyMatch()
+	last.IssueName = "Last issue for tests"
+	last.ID = scan.MatchID(last)
+	require.NoError(t, aferoFS.StoreMatch(context.Background(), last))
+
+	scanMatch, err = aferoFS.LoadMatch(context.Background(), last.ID)
+	require.NoError(t, err)
+	assert.Equal(t, last, *scanMatch)
+
+	_, err = aferoFS.LoadMatch(context.Background(), "unknown")
+	assert.ErrorIs(t, err, filesystem.ErrMatchNotFound)
+}
+
 func TestAfero_LoadTasksSummaries(t *testing.T) {
 	t.Parallel()
 
@@ -254,6 +287,32 @@ func TestAfero_TemplatesIterator(t *testing.T) {
 	assert.Equal(t, 5, numTemplates)
 }
 
+func TestAfero_ExchangesIterator(t *testing.T) {
+	t.Parallel()
+
+	fs, basePath := initializeFsTest()
+
+	aferoFS, err := filesystem.New(fs, basePath)
+	require.NoError(t, err)
+
+	storeSomeExchanges(t, aferoFS)
+
+	var numExchanges int
+
+	scanExchangesIterator, closeIt, err := aferoFS.ExchangesIterator(context.Background())
+	require.NoError(t, err)
+
+	for scanExchange := range scanExchangesIterator {
+		numExchanges++
+
+		assert.Equal(t, dummyExchange(), scanExchange)
+	}
+
+	closeIt()
+
+	assert.Equal(t, 5, numExchanges)
+}
+
 func TestConcurrentOps(t *testing.T) {
 	t.Parallel()
 
@@ -394,10 +453,18 @@ func storeSomeTemplates(t *testing.T, aferoFS *filesystem.Afero) {
 	}
 }
 
+func storeSomeExchanges(t *testing.T, aferoFS *filesystem.Afero) {
+	t.Helper()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, aferoFS.StoreExchange(context.Background(), dummyExchange()))
+	}
+}
+
 func assertEmptyFiles(t *testing.T, fs afero.Fs, basePath string) {
 	t.Helper()
 
-	toBeCreated := []string{filesystem.FileErrors, filesystem.FileMatches, filesystem.FileTasks}
+	toBeCreated := []string{filesystem.FileErrors, filesystem.FileMatches, filesystem.FileTasks, filesystem.FileExchanges}
 
 	for _, f := range toBeCreated {
 		stat, err := fs.Stat(fmt.Sprintf("%s/%s", basePath, f))
@@ -453,6 +520,14 @@ func dummyTemplate() scan.Template {
 	}
 }
 
+func dummyExchange() scan.Exchange {
+	return scan.Exchange{
+		URL:      "localhost:8080",
+		Request:  dummyRequest(),
+		Response: dummyResponse(),
+	}
+}
+
 func dummyRequest() *request.Request {
 	return &request.Request{
 		URL:    "http://localhost:8080",
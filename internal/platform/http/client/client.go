@@ -5,12 +5,14 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	stdurl "net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/proxy"
@@ -18,18 +20,39 @@ import (
 	"github.com/bountysecurity/gbounty/internal/platform/metrics"
 	"github.com/bountysecurity/gbounty/internal/request"
 	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/ntlm"
 	"github.com/bountysecurity/gbounty/kit/panics"
+	"github.com/bountysecurity/gbounty/kit/sigv4"
 )
 
 const (
 	httpProtocol = "http"
+	defaultProto = "HTTP/1.1"
+	simpleProto  = "HTTP/0.9"
 )
 
 // Client is a custom implementation of an HTTP client that
 // can be used to perform HTTP requests.
 type Client struct {
-	proxyAddr string
-	proxyAuth string
+	proxyAddr   string
+	proxyAuth   string
+	unixSocket  string
+	sinkhole    string
+	rawHeaders  bool
+	headerOrder []string
+	dnsCache    *DNSCache
+	rootCAs     *x509.CertPool
+
+	http2               bool
+	http2PriorKnowledge bool
+
+	maxDecompressedSize int64
+
+	ntlm      *ntlm.Credentials
+	ntlmMu    sync.Mutex
+	ntlmConns map[string]net.Conn
+
+	sigv4 *sigv4.Signer
 }
 
 // New is a constructor function that creates a new instance of
@@ -48,8 +71,8 @@ func (c *Client) Do(ctx context.Context, req *request.Request) (response.Respons
 	metrics.OngoingRequests.Inc()
 	defer metrics.OngoingRequests.Dec()
 
-	errReqTimeout := fmt.Errorf("http request took more than %s (canceled)", req.Timeout.String()) //nolint:goerr113
-	ctxWithTimeout, cancel := context.WithTimeoutCause(ctx, req.Timeout, errReqTimeout)
+	errReqTimeout := NetError(fmt.Sprintf("http request took more than %s (canceled)", req.Timeout.String()))
+	ctxWithTimeout, cancel := context.WithTimeoutCause(ctx, req.Timeout, &errReqTimeout)
 	defer cancel()
 
 	type result struct {
@@ -59,13 +82,22 @@ func (c *Client) Do(ctx context.Context, req *request.Request) (response.Respons
 
 	ch := make(chan result, 1)
 
+	if c.sigv4 != nil {
+		c.signSigV4(req)
+	}
+
+	var rawHeaders []string
+	if c.rawHeaders {
+		rawHeaders = req.RawHeaders
+	}
+
 	go func() {
 		defer panics.Log(ctx)
 
 		resp, err := c.do(
 			ctxWithTimeout,
 			req.URL, req.Method, req.Path, req.Proto,
-			req.Headers, bytes.NewReader(req.Body),
+			req.Headers, req.HeaderKeys(c.headerOrder...), rawHeaders, req.LineEndings, bytes.NewReader(req.Body),
 			req.Timeout,
 		)
 
@@ -88,7 +120,7 @@ func (c *Client) Do(ctx context.Context, req *request.Request) (response.Respons
 func (c *Client) do(
 	ctx context.Context,
 	url, method, uripath, proto string,
-	headers http.Header, body io.Reader,
+	headers http.Header, headerKeys, rawHeaders, lineEndings []string, body io.Reader,
 	timeout time.Duration,
 ) (res response.Response, err error) {
 	var conn net.Conn
@@ -149,6 +181,11 @@ func (c *Client) do(
 		res.Time = time.Since(startTime)
 	}()
 
+	if c.ntlm != nil {
+		conn, res, err = c.doNTLM(ctx, protocol, host, method, path, proto, headers, headerKeys, rawHeaders, lineEndings, body, timeout)
+		return
+	}
+
 	conn, err = c.connect(ctx, protocol, host, proto, timeout)
 	if err != nil {
 		return
@@ -161,28 +198,80 @@ func (c *Client) do(
 		}
 	}
 
-	if err = c.writeRequest(conn, method, path, proto, headers, body); err != nil {
+	var useHTTP2 bool
+	if useHTTP2, err = c.negotiateHTTP2(ctx, conn); err != nil {
+		return
+	}
+
+	if useHTTP2 {
+		res, err = c.roundTripHTTP2(conn, protocol, u.Host, method, path, headers, headerKeys, body)
+		return
+	}
+
+	res, err = c.roundTrip(conn, method, path, proto, headers, headerKeys, rawHeaders, lineEndings, body)
+
+	return
+}
+
+// roundTrip writes the request into the given connection,
+// and reads the response (including the body) from it.
+func (c *Client) roundTrip(
+	conn net.Conn,
+	method, path, proto string,
+	headers http.Header, headerKeys, rawHeaders, lineEndings []string, body io.Reader,
+) (res response.Response, err error) {
+	// HTTP/0.9-style requests are only sent when raw requests are allowed,
+	// as those are made of the request line only, without headers nor body.
+	if c.rawHeaders && proto == simpleProto {
+		err = (&writer{Writer: conn}).writeSimpleRequest(method, path)
+	} else {
+		err = c.writeRequest(conn, method, path, proto, headers, headerKeys, rawHeaders, lineEndings, body)
+	}
+	if err != nil {
 		return
 	}
 
-	var respBody io.Reader
+	var (
+		respBody io.Reader
+		head     bytes.Buffer
+	)
 
-	res.Proto, res.Code, res.Status, res.Headers, respBody, err = c.readResponse(conn)
+	// Responses to requests with a custom protocol version (e.g. HTTP/1.0 or a malformed one)
+	// are parsed leniently, as those usually come from old (or unusual) servers.
+	if proto != defaultProto {
+		res.Proto, res.Code, res.Status, res.Headers, respBody, err = c.readLenientResponse(conn, &head)
+	} else {
+		res.Proto, res.Code, res.Status, res.Headers, respBody, err = c.readResponse(conn, &head)
+	}
 	if err != nil {
 		return
 	}
 
-	res.Body, err = io.ReadAll(respBody)
+	res.RawHeaders = head.Bytes()
+
+	res.Body, res.DecompressionTruncated, err = readDecompressed(respBody)
 
 	return
 }
 
 func (c *Client) connect(ctx context.Context, protocol, host, proto string, timeout time.Duration) (net.Conn, error) {
+	if len(c.unixSocket) > 0 {
+		return c.connectUnix(ctx, protocol, host, timeout)
+	}
+
+	if len(c.sinkhole) > 0 {
+		return c.connectSinkhole(ctx, protocol, host, timeout)
+	}
+
+	if len(c.proxyAddr) == 0 && c.dnsCache != nil {
+		return c.connectCached(ctx, protocol, host, timeout)
+	}
+
 	if len(c.proxyAddr) == 0 {
 		var d proxy.ContextDialer = &net.Dialer{Timeout: timeout}
 		if protocol != httpProtocol {
-			//nolint:gosec,forcetypeassert
-			d = &tls.Dialer{NetDialer: d.(*net.Dialer), Config: &tls.Config{InsecureSkipVerify: true}}
+			//nolint:forcetypeassert
+			d = &tls.Dialer{NetDialer: d.(*net.Dialer), Config: c.tlsConfig("")}
 		}
 		return d.DialContext(ctx, "tcp", host)
 	}
@@ -206,17 +295,19 @@ func (c *Client) connect(ctx context.Context, protocol, host, proto string, time
 	}
 
 	headers := map[string][]string{}
+	headerKeys := make([]string, 0, 1)
 	if len(c.proxyAuth) > 0 {
 		headers["Proxy-Authorization"] = []string{"Basic " + c.proxyAuth}
+		headerKeys = append(headerKeys, "Proxy-Authorization")
 	}
 
-	err = c.writeRequest(conn, http.MethodConnect, host, proto, headers, nil)
+	err = c.writeRequest(conn, http.MethodConnect, host, proto, headers, headerKeys, nil, nil, nil)
 	if err != nil {
 		conn.Close()
 		return nil, err
 	}
 
-	_, _, _, _, _, err = c.readResponse(conn) //nolint:dogsled
+	_, _, _, _, _, err = c.readResponse(conn, nil) //nolint:dogsled
 	if err != nil {
 		conn.Close()
 		return nil, err
@@ -226,16 +317,101 @@ func (c *Client) connect(ctx context.Context, protocol, host, proto string, time
 		return conn, nil
 	}
 
-	return tls.Client(conn, &tls.Config{InsecureSkipVerify: true}), nil //nolint:gosec
+	return c.tlsHandshake(ctx, conn, host)
 }
 
-func (c *Client) writeRequest(conn io.Writer, method, path, proto string, headers map[string][]string, body io.Reader) error {
-	return (&writer{Writer: conn}).writeRequest(method, path, proto, headers, body)
+// connectUnix dials the Unix domain socket, instead of the given host, which is
+// only used as the TLS server name, if the protocol is HTTPS. So, the requests
+// are still sent with the request's Host header and path.
+func (c *Client) connectUnix(ctx context.Context, protocol, host string, timeout time.Duration) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "unix", c.unixSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol == httpProtocol {
+		return conn, nil
+	}
+
+	return c.tlsHandshake(ctx, conn, host)
+}
+
+// connectSinkhole dials the sinkhole address (see [WithResolveAllTo]), instead of the given
+// host, which is only used as the TLS server name, if the protocol is HTTPS. So, the requests
+// are still sent with the request's Host header and path, but never reach the actual host.
+func (c *Client) connectSinkhole(ctx context.Context, protocol, host string, timeout time.Duration) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", c.sinkhole)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol == httpProtocol {
+		return conn, nil
+	}
+
+	return c.tlsHandshake(ctx, conn, host)
+}
+
+// connectCached dials the given host with its address resolved through the client's
+// [DNSCache] (see [WithDNSCache]), instead of resolving it on every connection. So,
+// the TLS server name is set from the given host, as the address dialed is an IP.
+func (c *Client) connectCached(ctx context.Context, protocol, host string, timeout time.Duration) (net.Conn, error) {
+	conn, err := c.dnsCache.dial(ctx, &net.Dialer{Timeout: timeout}, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol == httpProtocol {
+		return conn, nil
+	}
+
+	return c.tlsHandshake(ctx, conn, host)
+}
+
+// tlsHandshake performs the TLS handshake over the given connection, with
+// the server name (i.e. SNI) taken from the given host (i.e. host:port).
+func (c *Client) tlsHandshake(ctx context.Context, conn net.Conn, host string) (net.Conn, error) {
+	serverName, _, err := net.SplitHostPort(host)
+	if err != nil {
+		serverName = host
+	}
+
+	tlsConn := tls.Client(conn, c.tlsConfig(serverName))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// tlsConfig returns the TLS configuration used to connect to the targets, with the
+// given server name (i.e. SNI), if any, and the application protocols offered (ALPN).
+// Certificates aren't verified, as targets are often self-signed (or misconfigured),
+// unless the client has root certificate authorities (see [WithRootCAs]).
+func (c *Client) tlsConfig(serverName string) *tls.Config {
+	cfg := &tls.Config{ServerName: serverName, NextProtos: c.nextProtos()} //nolint:gosec
+	if c.rootCAs != nil {
+		cfg.RootCAs = c.rootCAs
+	} else {
+		cfg.InsecureSkipVerify = true
+	}
+
+	return cfg
+}
+
+func (c *Client) writeRequest(conn io.Writer, method, path, proto string, headers map[string][]string, headerKeys, rawHeaders, lineEndings []string, body io.Reader) error {
+	return (&writer{Writer: conn}).writeRequest(method, path, proto, headers, headerKeys, rawHeaders, lineEndings, body)
+}
+
+func (c *Client) readResponse(conn io.Reader, head *bytes.Buffer) (string, int, string, map[string][]string, io.Reader, error) {
+	const readerSize = 4096
+	return (&reader{Reader: bufio.NewReaderSize(conn, readerSize), head: head, maxDecompressedSize: c.maxDecompressedSize}).readResponse()
 }
 
-func (c *Client) readResponse(conn io.Reader) (string, int, string, map[string][]string, io.Reader, error) {
+func (c *Client) readLenientResponse(conn io.Reader, head *bytes.Buffer) (string, int, string, map[string][]string, io.Reader, error) {
 	const readerSize = 4096
-	return (&reader{bufio.NewReaderSize(conn, readerSize)}).readResponse()
+	return (&reader{Reader: bufio.NewReaderSize(conn, readerSize), lenient: true, head: head, maxDecompressedSize: c.maxDecompressedSize}).readResponse()
 }
 
 func (c *Client) closeConn(conn net.Conn) error {
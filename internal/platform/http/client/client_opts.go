@@ -1,5 +1,12 @@
 package client
 
+import (
+	"crypto/x509"
+
+	"github.com/bountysecurity/gbounty/kit/ntlm"
+	"github.com/bountysecurity/gbounty/kit/sigv4"
+)
+
 // Opt is a functional option for the Client.
 type Opt func(*Client)
 
@@ -16,3 +23,101 @@ func WithProxyAuth(auth string) Opt {
 		c.proxyAuth = auth
 	}
 }
+
+// WithNTLM is an option that makes the client authenticate through NTLM,
+// with the given credentials, to those hosts that require it. As NTLM is
+// connection-bound, requests are sent over keep-alive connections, and the
+// authenticated ones are reused for the following requests to the same host.
+func WithNTLM(creds ntlm.Credentials) Opt {
+	return func(c *Client) {
+		c.ntlm = &creds
+	}
+}
+
+// WithSigV4 is an option that makes the client sign every request with the given
+// [sigv4.Signer] (i.e. AWS Signature Version 4), right before being sent, so the
+// signature covers the final request (e.g. once the payload has been injected).
+// It cannot be combined with NTLM (see [WithNTLM]), as both rely on the Authorization header.
+func WithSigV4(signer sigv4.Signer) Opt {
+	return func(c *Client) {
+		c.sigv4 = &signer
+	}
+}
+
+// WithUnixSocket is an option that makes the client dial the Unix domain
+// socket at the given path, instead of the request's host. The request is
+// still sent with its Host header and path. It cannot be combined with a proxy.
+func WithUnixSocket(path string) Opt {
+	return func(c *Client) {
+		c.unixSocket = path
+	}
+}
+
+// WithResolveAllTo is an option that makes the client dial the given address (i.e. host:port)
+// for every request, regardless of the request's host, like if every host was resolved to it
+// (i.e. sinkhole mode), so no traffic reaches the actual hosts. The request is still sent with
+// its Host header and path, and the TLS server name. It cannot be combined with a proxy.
+func WithResolveAllTo(addr string) Opt {
+	return func(c *Client) {
+		c.sinkhole = addr
+	}
+}
+
+// WithRootCAs is an option that makes the client verify the targets' certificates
+// (and host names) against the given pool of root certificate authorities, so TLS
+// connections to those not trusted fail. By default, certificates aren't verified.
+func WithRootCAs(pool *x509.CertPool) Opt {
+	return func(c *Client) {
+		c.rootCAs = pool
+	}
+}
+
+// WithRawHeaders is an option that makes the client send the request's
+// raw (verbatim) framing headers, if any (see [request.Request.RawHeaders]),
+// instead of the normalized ones. Useful to test HTTP request smuggling.
+func WithRawHeaders() Opt {
+	return func(c *Client) {
+		c.rawHeaders = true
+	}
+}
+
+// WithHeaderOrder is an option that sets the order the request headers are
+// sent in (case-insensitive). Headers not listed are sent afterward, in the
+// order those were added to the request (see [request.Request.HeaderKeys]).
+func WithHeaderOrder(keys []string) Opt {
+	return func(c *Client) {
+		c.headerOrder = keys
+	}
+}
+
+// WithDNSCache is an option that makes the client resolve the hosts through the
+// given [DNSCache], shared with other clients (e.g. those of a [NewPool]), so the
+// addresses resolved are reused. It has no effect when a proxy (the proxy resolves
+// the hosts) or a Unix domain socket is used.
+func WithDNSCache(cache *DNSCache) Opt {
+	return func(c *Client) {
+		c.dnsCache = cache
+	}
+}
+
+// WithMaxDecompressedSize is an option that sets the maximum size (in bytes) of the response
+// bodies once decompressed (e.g. gzip-encoded ones), so those larger (e.g. decompression bombs)
+// are truncated to it, instead of exhausting the memory (see [response.Response.DecompressionTruncated]).
+// Zero (or less) means no limit.
+func WithMaxDecompressedSize(size int64) Opt {
+	return func(c *Client) {
+		c.maxDecompressedSize = size
+	}
+}
+
+// WithHTTP2 is an option that makes the client send the requests over HTTP/2, if negotiated
+// during the TLS handshake (ALPN), falling back to HTTP/1.1 otherwise. With prior knowledge,
+// HTTP/2 is required (i.e. no fallback), and cleartext requests are sent over h2c, with no
+// upgrade. Otherwise, cleartext requests are sent over HTTP/1.1. It cannot be combined with
+// NTLM (see [WithNTLM]), nor raw headers (see [WithRawHeaders]), as those rely on HTTP/1.x.
+func WithHTTP2(priorKnowledge bool) Opt {
+	return func(c *Client) {
+		c.http2 = true
+		c.http2PriorKnowledge = priorKnowledge
+	}
+}
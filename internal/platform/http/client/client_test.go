@@ -0,0 +1,984 @@
+package client_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/bountysecurity/gbounty/internal/platform/http/client"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/ntlm"
+	"github.com/bountysecurity/gbounty/kit/sigv4"
+)
+
+func TestClient_RawHeaders(t *testing.T) {
+	t.Parallel()
+
+	raw := "POST / HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Content-Length: 6\r\n" +
+		"transfer-encoding:\tchunked\r\n" +
+		"\r\n" +
+		"0\r\n\r\nG"
+
+	tcs := map[string]struct {
+		opts []client.Opt
+		exp  []string
+	}{
+		"normalized": {
+			opts: nil,
+			exp:  []string{"Content-Length: 6", "Transfer-Encoding: chunked"},
+		},
+		"verbatim": {
+			opts: []client.Opt{client.WithRawHeaders()},
+			exp:  []string{"Content-Length: 6", "transfer-encoding:\tchunked"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addr, received := listen(t)
+
+			req, err := request.ParseRequest([]byte(raw), "http://"+addr)
+			require.NoError(t, err)
+			req.Timeout = 5 * time.Second
+
+			_, err = client.New(tc.opts...).Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			lines := <-received
+			assert.Equal(t, "POST / HTTP/1.1", lines[0])
+			assert.Subset(t, lines, tc.exp)
+		})
+	}
+}
+
+func TestClient_HeaderOrder(t *testing.T) {
+	t.Parallel()
+
+	raw := "GET / HTTP/1.1\r\n" +
+		"User-Agent: gbounty\r\n" +
+		"Host: localhost\r\n" +
+		"X-Custom: value\r\n" +
+		"Accept: */*\r\n" +
+		"\r\n"
+
+	tcs := map[string]struct {
+		opts []client.Opt
+		exp  []string
+	}{
+		"as captured": {
+			opts: nil,
+			exp:  []string{"User-Agent: gbounty", "Host: localhost", "X-Custom: value", "Accept: */*"},
+		},
+		"explicit order": {
+			opts: []client.Opt{client.WithHeaderOrder([]string{"host", "Accept"})},
+			exp:  []string{"Host: localhost", "Accept: */*", "User-Agent: gbounty", "X-Custom: value"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addr, received := listen(t)
+
+			req, err := request.ParseRequest([]byte(raw), "http://"+addr)
+			require.NoError(t, err)
+			req.Timeout = 5 * time.Second
+
+			_, err = client.New(tc.opts...).Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			lines := <-received
+			assert.Equal(t, append([]string{"GET / HTTP/1.1"}, tc.exp...), lines)
+		})
+	}
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	t.Parallel()
+
+	sock := filepath.Join(t.TempDir(), "gbounty.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	received := serve(t, ln)
+
+	raw := "GET /containers/json?all=1 HTTP/1.1\r\n" +
+		"Host: docker\r\n" +
+		"\r\n"
+
+	req, err := request.ParseRequest([]byte(raw), "http://docker")
+	require.NoError(t, err)
+	req.Timeout = 5 * time.Second
+
+	res, err := client.New(client.WithUnixSocket(sock)).Do(context.Background(), &req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.Code)
+
+	lines := <-received
+	assert.Equal(t, "GET /containers/json?all=1 HTTP/1.1", lines[0])
+	assert.Contains(t, lines, "Host: docker")
+}
+
+func TestClient_ResolveAllTo(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	received := serve(t, ln)
+
+	raw := "GET /admin HTTP/1.1\r\n" +
+		"Host: www.example.invalid\r\n" +
+		"\r\n"
+
+	req, err := request.ParseRequest([]byte(raw), "http://www.example.invalid")
+	require.NoError(t, err)
+	req.Timeout = 5 * time.Second
+
+	res, err := client.New(client.WithResolveAllTo(ln.Addr().String())).Do(context.Background(), &req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.Code)
+
+	lines := <-received
+	assert.Equal(t, "GET /admin HTTP/1.1", lines[0])
+	assert.Contains(t, lines, "Host: www.example.invalid")
+}
+
+func TestClient_ProtoVersion(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		proto    string
+		opts     []client.Opt
+		reply    string
+		expLine  string
+		expProto string
+		expCode  int
+		expBody  string
+		expRaw   string
+	}{
+		"http/1.1": {
+			proto:    "HTTP/1.1",
+			reply:    "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhello",
+			expLine:  "GET / HTTP/1.1",
+			expProto: "HTTP/1.1",
+			expCode:  200,
+			expBody:  "hello",
+			expRaw:   "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\n",
+		},
+		"http/1.0": {
+			proto:    "HTTP/1.0",
+			reply:    "HTTP/1.0 200 OK\r\n\r\nhello",
+			expLine:  "GET / HTTP/1.0",
+			expProto: "HTTP/1.0",
+			expCode:  200,
+			expBody:  "hello",
+			expRaw:   "HTTP/1.0 200 OK\r\n\r\n",
+		},
+		"malformed version, loose status line": {
+			proto:    "HTTP/1.1.1",
+			reply:    "HTTP/2 400\r\nbroken header\r\nServer: old\r\n\r\n",
+			expLine:  "GET / HTTP/1.1.1",
+			expProto: "HTTP/2",
+			expCode:  400,
+			expRaw:   "HTTP/2 400\r\nbroken header\r\nServer: old\r\n\r\n",
+		},
+		"http/0.9 without raw headers": {
+			proto:    "HTTP/0.9",
+			reply:    "hello",
+			expLine:  "GET / HTTP/0.9",
+			expProto: "HTTP/0.9",
+			expBody:  "hello",
+		},
+		"http/0.9 with raw headers": {
+			proto:    "HTTP/0.9",
+			opts:     []client.Opt{client.WithRawHeaders()},
+			reply:    "<html>hello</html>",
+			expLine:  "GET /",
+			expProto: "HTTP/0.9",
+			expBody:  "<html>hello</html>",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = ln.Close() })
+
+			received := make(chan string, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				line, _ := textproto.NewReader(bufio.NewReader(conn)).ReadLine()
+				received <- line
+
+				_, _ = conn.Write([]byte(tc.reply))
+			}()
+
+			req, err := request.ParseRequest([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"), "http://"+ln.Addr().String())
+			require.NoError(t, err)
+			req.Proto = tc.proto
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New(tc.opts...).Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expLine, <-received)
+			assert.Equal(t, tc.expProto, res.Proto)
+			assert.Equal(t, tc.expCode, res.Code)
+			assert.Equal(t, tc.expBody, string(res.Body))
+			assert.Equal(t, tc.expRaw, string(res.RawHeaders))
+		})
+	}
+}
+
+func TestClient_WebSocketUpgrade(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+
+	received := make(chan textproto.MIMEHeader, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewReader(bufio.NewReader(conn))
+		_, _ = tp.ReadLine()
+		headers, _ := tp.ReadMIMEHeader()
+		received <- headers
+
+		// The connection is kept open once switched, and a
+		// WebSocket frame is sent, which must not be read.
+		_, _ = conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Protocol: chat\r\n\r\n" +
+			"\x81\x05hello"))
+		<-done
+	}()
+
+	req, err := request.ParseRequest([]byte("POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 2\r\n\r\n{}"), "http://"+ln.Addr().String())
+	require.NoError(t, err)
+	req.SetWebSocketUpgrade("chat", "superchat")
+	req.Timeout = 5 * time.Second
+
+	res, err := client.New().Do(context.Background(), &req)
+	require.NoError(t, err)
+
+	headers := <-received
+	assert.Equal(t, "websocket", headers.Get("Upgrade"))
+	assert.Equal(t, "chat, superchat", headers.Get("Sec-WebSocket-Protocol"))
+	assert.Empty(t, headers.Get("Content-Length"))
+
+	assert.Equal(t, http.StatusSwitchingProtocols, res.Code)
+	assert.Equal(t, []string{"chat"}, res.Headers["Sec-Websocket-Protocol"])
+	assert.Empty(t, res.Body)
+	assert.Less(t, res.Time, req.Timeout)
+}
+
+func TestClient_ChunkedTrailers(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		reply      string
+		expBody    string
+		expHeaders map[string][]string
+	}{
+		"multiple chunks, extensions and trailer": {
+			reply: "HTTP/1.1 200 OK\r\n" +
+				"Transfer-Encoding: chunked\r\n" +
+				"Trailer: X-Checksum\r\n\r\n" +
+				"5;ext=1\r\nhello\r\n" +
+				"6;name=\"quoted value\"\r\n world\r\n" +
+				"0\r\n" +
+				"X-Checksum: abc123\r\n" +
+				"Content-Length: 999\r\n\r\n",
+			expBody: "hello world",
+			expHeaders: map[string][]string{
+				"Transfer-Encoding": {"chunked"},
+				"Trailer":           {"X-Checksum"},
+				"X-Checksum":        {"abc123"},
+			},
+		},
+		"chunked precedes content length": {
+			reply: "HTTP/1.1 200 OK\r\n" +
+				"Content-Length: 3\r\n" +
+				"Transfer-Encoding: gzip, Chunked\r\n\r\n" +
+				"a\r\n0123456789\r\n" +
+				"0\r\n\r\n",
+			expBody: "0123456789",
+			expHeaders: map[string][]string{
+				"Content-Length":    {"3"},
+				"Transfer-Encoding": {"gzip, Chunked"},
+			},
+		},
+		"connection closed after last chunk": {
+			reply: "HTTP/1.1 200 OK\r\n" +
+				"Transfer-Encoding: chunked\r\n\r\n" +
+				"5\r\nhello\r\n" +
+				"0\r\n",
+			expBody: "hello",
+			expHeaders: map[string][]string{
+				"Transfer-Encoding": {"chunked"},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = ln.Close() })
+
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				tp := textproto.NewReader(bufio.NewReader(conn))
+				_, _ = tp.ReadLine()
+				_, _ = tp.ReadMIMEHeader()
+
+				_, _ = conn.Write([]byte(tc.reply))
+			}()
+
+			req, err := request.ParseRequest([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"), "http://"+ln.Addr().String())
+			require.NoError(t, err)
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New().Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, tc.expBody, string(res.Body))
+			assert.Equal(t, tc.expHeaders, res.Headers)
+		})
+	}
+}
+
+func TestClient_NTLM(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu            sync.Mutex
+		handshakes    int
+		authenticated = make(map[string]string)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// NTLM authenticates the connection (i.e. the remote address), not the request.
+		if user, ok := authenticated[r.RemoteAddr]; ok {
+			_, _ = fmt.Fprintf(w, "hello %s", user)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "NTLM ")
+		msg, _ := base64.StdEncoding.DecodeString(token)
+
+		switch {
+		case !found || len(msg) < 12:
+			w.Header().Set("WWW-Authenticate", "NTLM")
+		case binary.LittleEndian.Uint32(msg[8:]) == 1:
+			handshakes++
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(ntlmChallenge()))
+		case binary.LittleEndian.Uint32(msg[8:]) == 3:
+			l, offset := binary.LittleEndian.Uint16(msg[36:]), binary.LittleEndian.Uint32(msg[40:])
+			user := strings.ReplaceAll(string(msg[offset:offset+uint32(l)]), "\x00", "")
+			authenticated[r.RemoteAddr] = user
+			_, _ = fmt.Fprintf(w, "hello %s", user)
+			return
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	send := func(c *client.Client) response.Response {
+		req, err := request.ParseRequest([]byte("GET / HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"), srv.URL)
+		require.NoError(t, err)
+		req.Timeout = 5 * time.Second
+
+		res, err := c.Do(context.Background(), &req)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("without credentials", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, http.StatusUnauthorized, send(client.New()).Code)
+	})
+
+	t.Run("with credentials", func(t *testing.T) {
+		t.Parallel()
+
+		c := client.New(client.WithNTLM(ntlm.Credentials{Domain: "CORP", User: "john", Password: "secret"}))
+
+		for i := 0; i < 3; i++ {
+			res := send(c)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, "hello john", string(res.Body))
+		}
+
+		// The authenticated connection is reused, so there's only one handshake.
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 1, handshakes)
+	})
+}
+
+func TestClient_SigV4(t *testing.T) {
+	t.Parallel()
+
+	signer := sigv4.Signer{
+		Region:      "us-east-1",
+		Service:     "execute-api",
+		Credentials: sigv4.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+	}
+
+	// The server signs the request as received, so it only
+	// matches the one sent when it covers the final bytes.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signedAt, err := time.Parse("20060102T150405Z", r.Header.Get(sigv4.HeaderDate))
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		headers := r.Header.Clone()
+		headers.Set("Host", r.Host)
+
+		expected := signer.Sign(r.Method, "", r.RequestURI, headers, body, signedAt)
+		if r.Header.Get(sigv4.HeaderAuthorization) != expected[len(expected)-1].Value {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, _ = fmt.Fprint(w, "signed")
+	}))
+	t.Cleanup(srv.Close)
+
+	send := func(c *client.Client, raw string) (*request.Request, response.Response) {
+		req, err := request.ParseRequest([]byte(raw), srv.URL)
+		require.NoError(t, err)
+		req.Timeout = 5 * time.Second
+
+		res, err := c.Do(context.Background(), &req)
+		require.NoError(t, err)
+
+		return &req, res
+	}
+
+	const raw = "POST /v1/users?id=1%27%20OR%201=1&b=2 HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n" +
+		"authorization: Bearer stale\r\nConnection: close\r\nContent-Length: 15\r\n\r\n{\"name\":\"<h1>\"}"
+
+	t.Run("without signer", func(t *testing.T) {
+		t.Parallel()
+
+		_, res := send(client.New(), raw)
+		assert.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("with signer", func(t *testing.T) {
+		t.Parallel()
+
+		req, res := send(client.New(client.WithSigV4(signer)), raw)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "signed", string(res.Body))
+
+		// The signature headers are set into the request, replacing the stale ones.
+		assert.NotContains(t, req.Headers, "authorization")
+		assert.Contains(t, req.Header(sigv4.HeaderAuthorization), "Credential=AKIDEXAMPLE/")
+		assert.NotEmpty(t, req.Header(sigv4.HeaderDate))
+	})
+}
+
+// ntlmChallenge returns a challenge (type 2) message, with no target info.
+func ntlmChallenge() []byte {
+	msg := make([]byte, 32)
+	copy(msg, "NTLMSSP\x00")
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], 0x00000001|0x00000200)
+	copy(msg[24:], "8bytes!!")
+
+	return msg
+}
+
+// listen starts a TCP server that replies every connection with
+// an empty response, and sends the received header lines through
+// the returned channel.
+func TestClient_DNSCache(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	send := func(t *testing.T, c *client.Client) {
+		t.Helper()
+
+		req := request.Default("http://gbounty.test:" + port + "/")
+		req.Timeout = 5 * time.Second
+
+		res, err := c.Do(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, res.Code)
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		resolver, lookups := fakeDNS(t, "gbounty.test.", "127.0.0.1")
+		c := client.New(client.WithDNSCache(client.NewDNSCache(time.Minute, false, resolver)))
+
+		for i := 0; i < 3; i++ {
+			send(t, c)
+		}
+
+		assert.EqualValues(t, 1, lookups.Load())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		resolver, lookups := fakeDNS(t, "gbounty.test.", "127.0.0.1")
+		c := client.New(client.WithDNSCache(client.NewDNSCache(time.Nanosecond, false, resolver)))
+
+		send(t, c)
+		time.Sleep(time.Millisecond)
+		send(t, c)
+
+		assert.EqualValues(t, 2, lookups.Load())
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		t.Parallel()
+
+		resolver, lookups := fakeDNS(t, "gbounty.test.", "127.0.0.1", "127.0.0.2")
+		cache := client.NewDNSCache(0, true, resolver)
+
+		for i := 0; i < 3; i++ {
+			addrs, err := cache.LookupHost(context.Background(), "gbounty.test")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"127.0.0.1"}, addrs)
+		}
+
+		assert.EqualValues(t, 1, lookups.Load())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, client.NewDNSCache(0, false, nil))
+	})
+}
+
+func TestClient_HTTP2(t *testing.T) {
+	t.Parallel()
+
+	// The handler echoes the protocol, the request body's length
+	// and the order the headers were received in.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Proto", r.Proto)
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.RequestURI()))
+	})
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	t.Cleanup(h2.Close)
+
+	h1 := httptest.NewTLSServer(handler)
+	t.Cleanup(h1.Close)
+
+	h2c := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	t.Cleanup(h2c.Close)
+
+	// Larger than the default flow-control window, so it takes window updates.
+	payload := strings.Repeat("A", 100_000)
+
+	tcs := map[string]struct {
+		url            string
+		priorKnowledge bool
+		expProto       string
+		expErr         error
+	}{
+		"negotiated":                  {url: h2.URL, expProto: "HTTP/2"},
+		"fallback":                    {url: h1.URL, expProto: "HTTP/1.1"},
+		"cleartext":                   {url: h2c.URL, expProto: "HTTP/1.1"},
+		"prior knowledge (cleartext)": {url: h2c.URL, priorKnowledge: true, expProto: "HTTP/2"},
+		"prior knowledge (tls)":       {url: h2.URL, priorKnowledge: true, expProto: "HTTP/2"},
+		"prior knowledge (no h2)":     {url: h1.URL, priorKnowledge: true, expErr: client.ErrHTTP2NotNegotiated},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := request.Default(tc.url + "/search?q=gbounty")
+			req.Method = http.MethodPost
+			req.SetHeader("Host", "gbounty.test")
+			req.SetBody([]byte(payload))
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New(client.WithHTTP2(tc.priorKnowledge)).Do(context.Background(), &req)
+			if tc.expErr != nil {
+				assert.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, res.Code)
+			assert.Equal(t, tc.expProto, res.Proto)
+			assert.Equal(t, []string{strings.Replace(tc.expProto, "HTTP/2", "HTTP/2.0", 1)}, res.Headers["X-Proto"])
+			assert.Equal(t, []string{"gbounty.test"}, res.Headers["X-Host"])
+			assert.Equal(t, []string{strconv.Itoa(len(payload))}, res.Headers["X-Length"])
+			assert.Equal(t, "POST /search?q=gbounty", string(res.Body))
+			assert.True(t, strings.HasPrefix(string(res.RawHeaders), tc.expProto+" 201 Created\r\n"))
+		})
+	}
+}
+
+func TestClient_MaxDecompressedSize(t *testing.T) {
+	t.Parallel()
+
+	const (
+		bombSize = 8 << 20
+		maxSize  = 1 << 20
+	)
+
+	// A high-ratio gzip stream (i.e. a decompression bomb): 8 MiB of zeros compressed into a few KiB.
+	var bomb bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&bomb, gzip.BestCompression)
+	require.NoError(t, err)
+	_, err = gz.Write(make([]byte, bombSize))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.Less(t, bomb.Len(), bombSize/500)
+
+	var small bytes.Buffer
+	gz = gzip.NewWriter(&small)
+	_, err = gz.Write([]byte("gbounty"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		if r.URL.Path == "/small" {
+			_, _ = w.Write(small.Bytes())
+			return
+		}
+		_, _ = w.Write(bomb.Bytes())
+	})
+
+	h1 := httptest.NewServer(handler)
+	t.Cleanup(h1.Close)
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	t.Cleanup(h2.Close)
+
+	tcs := map[string]struct {
+		url          string
+		opts         []client.Opt
+		expBody      int
+		expTruncated bool
+	}{
+		"capped":          {url: h1.URL + "/bomb", opts: []client.Opt{client.WithMaxDecompressedSize(maxSize)}, expBody: maxSize, expTruncated: true},
+		"capped (http/2)": {url: h2.URL + "/bomb", opts: []client.Opt{client.WithMaxDecompressedSize(maxSize), client.WithHTTP2(false)}, expBody: maxSize, expTruncated: true},
+		"below the cap":   {url: h1.URL + "/small", opts: []client.Opt{client.WithMaxDecompressedSize(maxSize)}, expBody: len("gbounty")},
+		"no limit":        {url: h1.URL + "/bomb", expBody: bombSize},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := request.Default(tc.url)
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New(tc.opts...).Do(context.Background(), &req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Len(t, res.Body, tc.expBody)
+			assert.Equal(t, tc.expTruncated, res.DecompressionTruncated)
+		})
+	}
+}
+
+func TestClient_RootCAs(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(srv.Certificate())
+
+	// No authorities at all, so the server's certificate isn't trusted.
+	untrusted := x509.NewCertPool()
+
+	tcs := map[string]struct {
+		opts   []client.Opt
+		expErr bool
+	}{
+		"not verified": {},
+		"trusted":      {opts: []client.Opt{client.WithRootCAs(trusted)}},
+		"untrusted":    {opts: []client.Opt{client.WithRootCAs(untrusted)}, expErr: true},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := request.Default(srv.URL + "/")
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New(tc.opts...).Do(context.Background(), &req)
+			if tc.expErr {
+				assert.ErrorContains(t, err, "certificate signed by unknown authority")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, res.Code)
+		})
+	}
+}
+
+func listen(t *testing.T) (string, chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	return ln.Addr().String(), serve(t, ln)
+}
+
+// serve is like listen, but with the given [net.Listener].
+func serve(t *testing.T, ln net.Listener) chan []string {
+	t.Helper()
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		tp := textproto.NewReader(bufio.NewReader(conn))
+		for {
+			line, err := tp.ReadLine()
+			if err != nil || len(line) == 0 {
+				break
+			}
+			lines = append(lines, line)
+		}
+		received <- lines
+
+		_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"))
+	}()
+
+	return received
+}
+
+// fakeDNS starts a DNS server (UDP) that resolves the given name to the given (IPv4)
+// addresses, and returns a [net.Resolver] that queries it, along with the number of
+// lookups (i.e. A queries for the given name) received.
+func fakeDNS(t *testing.T, name string, ips ...string) (*net.Resolver, *atomic.Int32) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	var lookups atomic.Int32
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+
+			rcode := dnsmessage.RCodeSuccess
+			if q.Name.String() != name {
+				rcode = dnsmessage.RCodeNameError
+			}
+
+			b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true, RCode: rcode})
+			_ = b.StartQuestions()
+			_ = b.Question(q)
+			_ = b.StartAnswers()
+
+			if rcode == dnsmessage.RCodeSuccess && q.Type == dnsmessage.TypeA {
+				lookups.Add(1)
+				for _, ip := range ips {
+					var a [4]byte
+					copy(a[:], net.ParseIP(ip).To4())
+					_ = b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.AResource{A: a})
+				}
+			}
+
+			msg, err := b.Finish()
+			if err != nil {
+				continue
+			}
+
+			_, _ = pc.WriteTo(msg, addr)
+		}
+	}()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "udp", pc.LocalAddr().String())
+		},
+	}, &lookups
+}
+
+func TestClient_LineEndings(t *testing.T) {
+	t.Parallel()
+
+	const raw = "POST /login HTTP/1.1\nHost: localhost\r\nContent-Length: 8\n\na\nb\r\nc\nd"
+
+	tcs := map[string]struct {
+		opts []request.Option
+		exp  string
+	}{
+		"normalized": {
+			exp: "POST /login HTTP/1.1\r\nHost: localhost\r\nContent-Length: 8\r\n\r\na\nb\r\nc\nd",
+		},
+		"preserved": {
+			opts: []request.Option{request.WithLineEndings([]byte(raw))},
+			exp:  raw,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = ln.Close() })
+
+			received := make(chan string, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				buf := make([]byte, len(tc.exp))
+				n, _ := io.ReadFull(conn, buf)
+				received <- string(buf[:n])
+
+				_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"))
+			}()
+
+			req, err := request.ParseRequest([]byte(raw), "http://"+ln.Addr().String())
+			require.NoError(t, err)
+			for _, opt := range tc.opts {
+				req = opt(req)
+			}
+			req.Timeout = 5 * time.Second
+
+			res, err := client.New().Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.exp, <-received)
+			assert.Equal(t, http.StatusOK, res.Code)
+		})
+	}
+}
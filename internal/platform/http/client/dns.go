@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// DNSCache is an in-process cache of the addresses resolved for each host, safe for
+// concurrent use, so the clients sharing it (see [WithDNSCache]) don't resolve the same
+// hosts over and over again, which adds latency and load under high concurrency.
+//
+// Resolved addresses are reused for the given TTL, while errors aren't cached. If pinned,
+// only the first address resolved for each host is used, for as long as the cache lives
+// (i.e. the whole scan), so requests don't flap between hosts behind round-robin DNS.
+type DNSCache struct {
+	ttl      time.Duration
+	pin      bool
+	resolver *net.Resolver
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	ready   chan struct{}
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// NewDNSCache creates a new instance of [DNSCache], that resolves hosts through the given
+// [net.Resolver] (or [net.DefaultResolver], if nil), and keeps the addresses for the given
+// TTL, or for as long as it lives, if pinned. A zero (or negative) TTL with no pinning means
+// no caching at all, so it returns nil, which is a valid (no-op) [DNSCache].
+func NewDNSCache(ttl time.Duration, pin bool, resolver *net.Resolver) *DNSCache {
+	if ttl <= 0 && !pin {
+		return nil
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return &DNSCache{
+		ttl:      ttl,
+		pin:      pin,
+		resolver: resolver,
+		entries:  make(map[string]*dnsEntry),
+	}
+}
+
+// LookupHost returns the addresses of the given host, either cached or resolved.
+// Concurrent lookups of the same host are resolved once. IP addresses are returned as is.
+func (c *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	if c == nil {
+		return net.DefaultResolver.LookupHost(ctx, host)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	if !ok || c.expired(e) {
+		e = &dnsEntry{ready: make(chan struct{})}
+		c.entries[host] = e
+		c.mu.Unlock()
+
+		c.resolve(ctx, host, e)
+	} else {
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-e.ready:
+		return e.addrs, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *DNSCache) resolve(ctx context.Context, host string, e *dnsEntry) {
+	defer close(e.ready)
+
+	e.addrs, e.err = c.resolver.LookupHost(ctx, host)
+	if e.err == nil && len(e.addrs) == 0 {
+		e.err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	if e.err != nil {
+		// Errors aren't cached, so the following lookups try again.
+		c.mu.Lock()
+		if c.entries[host] == e {
+			delete(c.entries, host)
+		}
+		c.mu.Unlock()
+
+		return
+	}
+
+	if c.pin {
+		e.addrs = e.addrs[:1]
+	}
+
+	e.expires = time.Now().Add(c.ttl)
+}
+
+// expired returns whether the given entry must be resolved again. Those
+// still being resolved are never expired, nor the pinned ones.
+func (c *DNSCache) expired(e *dnsEntry) bool {
+	select {
+	case <-e.ready:
+		return !c.pin && e.err == nil && time.Now().After(e.expires)
+	default:
+		return false
+	}
+}
+
+// dial connects to the given address (i.e. host:port) through the given dialer,
+// with the host resolved through the [DNSCache]. Each of the addresses resolved
+// is tried, in order, until any of them succeeds.
+func (c *DNSCache) dial(ctx context.Context, d *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := c.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	for _, addr := range addrs {
+		conn, err = d.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
@@ -1,6 +1,9 @@
 package client
 
-import "encoding/gob"
+import (
+	"encoding/gob"
+	"strings"
+)
 
 func init() {
 	netError := NetError("")
@@ -8,6 +11,9 @@ func init() {
 }
 
 // NetError represents a network error.
+//
+// It is the marshalable form of any error found while performing the request (e.g. dialing,
+// the TLS handshake or reading the response), so it implements the [net.Error] interface.
 type NetError string
 
 // Error returns the error message.
@@ -16,6 +22,19 @@ func (err *NetError) Error() string {
 	return string(*err)
 }
 
+// Timeout returns whether the error is a timeout.
+// Implements the [net.Error] interface.
+func (err *NetError) Timeout() bool {
+	msg := string(*err)
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "took more than")
+}
+
+// Temporary returns false, as it is deprecated.
+// Implements the [net.Error] interface.
+func (err *NetError) Temporary() bool {
+	return false
+}
+
 // GobEncode encodes the error into a byte slice.
 // Implements the [gob.GobEncoder] interface.
 func (err *NetError) GobEncode() ([]byte, error) {
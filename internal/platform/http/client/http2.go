@@ -0,0 +1,438 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+var (
+	// ErrHTTP2NotNegotiated is returned when HTTP/2 is required (i.e. with prior knowledge,
+	// see [WithHTTP2]) but the server doesn't negotiate it during the TLS handshake (ALPN).
+	ErrHTTP2NotNegotiated = errors.New("http/2 not negotiated")
+	// ErrHTTP2StreamReset is returned when the server resets the HTTP/2 stream (RST_STREAM).
+	ErrHTTP2StreamReset = errors.New("http/2 stream reset")
+	// ErrHTTP2GoAway is returned when the server closes the HTTP/2 connection (GOAWAY)
+	// before processing the request.
+	ErrHTTP2GoAway = errors.New("http/2 connection closed")
+	// ErrHTTP2InvalidStatus is returned when the :status pseudo-header is missing or invalid.
+	ErrHTTP2InvalidStatus = errors.New("http/2 invalid status")
+)
+
+const (
+	http2Proto = "HTTP/2"
+	http2ALPN  = "h2"
+
+	// http2StreamID is the identifier of the (single) stream opened on each connection,
+	// as every request is sent over its own connection (see [Client.Do]).
+	http2StreamID = 1
+	// http2Window is the flow-control window advertised, large enough for the
+	// whole response to be received without sending any window update.
+	http2Window = 1<<31 - 1
+	// http2DefaultWindow and http2DefaultMaxFrameSize are the initial values
+	// defined by the spec, until the server's settings are received.
+	http2DefaultWindow       = 65_535
+	http2DefaultMaxFrameSize = 16_384
+	// http2HeaderTableSize is the size of the HPACK dynamic table used to decode the headers.
+	http2HeaderTableSize = 4_096
+)
+
+// http2ConnectionHeaders are the connection-specific headers, not allowed in HTTP/2.
+// So, those are never sent, apart from TE: trailers, the only TE value allowed.
+var http2ConnectionHeaders = []string{"connection", "keep-alive", "proxy-connection", "transfer-encoding", "upgrade"}
+
+// nextProtos returns the application protocols offered during the TLS handshake (ALPN).
+// HTTP/1.1 is offered too, even if HTTP/2 is required (see [WithHTTP2]), as otherwise some
+// servers abort the handshake, so [ErrHTTP2NotNegotiated] is returned instead.
+func (c *Client) nextProtos() []string {
+	if !c.http2 {
+		return nil
+	}
+
+	return []string{http2ALPN, "http/1.1"}
+}
+
+// negotiateHTTP2 returns whether the request must be sent over HTTP/2 through the given
+// connection: if negotiated during the TLS handshake (ALPN), or in cleartext (h2c) with
+// prior knowledge. It returns an error if HTTP/2 is required but not negotiated.
+func (c *Client) negotiateHTTP2(ctx context.Context, conn net.Conn) (bool, error) {
+	if !c.http2 {
+		return false, nil
+	}
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return c.http2PriorKnowledge, nil
+	}
+
+	// The handshake is usually done already, but not over proxies.
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return false, err
+	}
+
+	if tlsConn.ConnectionState().NegotiatedProtocol == http2ALPN {
+		return true, nil
+	}
+
+	if c.http2PriorKnowledge {
+		return false, ErrHTTP2NotNegotiated
+	}
+
+	return false, nil
+}
+
+// roundTripHTTP2 writes the request into the given connection as an HTTP/2 stream,
+// with the pseudo-headers built from the request (see [http2HeaderFields]), and
+// reads the response from it, so it flows through the same pipeline as HTTP/1.x ones.
+func (c *Client) roundTripHTTP2(
+	conn net.Conn,
+	scheme, authority, method, path string,
+	headers http.Header, headerKeys []string, body io.Reader,
+) (response.Response, error) {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(body); err != nil {
+			return response.Response{}, err
+		}
+	}
+
+	bw := bufio.NewWriter(conn)
+	rt := &http2RoundTrip{
+		bw:            bw,
+		framer:        http2.NewFramer(bw, conn),
+		maxFrameSize:  http2DefaultMaxFrameSize,
+		initialWindow: http2DefaultWindow,
+		connWindow:    http2DefaultWindow,
+		streamWindow:  http2DefaultWindow,
+	}
+	rt.framer.ReadMetaHeaders = hpack.NewDecoder(http2HeaderTableSize, nil)
+
+	if err := rt.writePreface(); err != nil {
+		return response.Response{}, err
+	}
+
+	if err := rt.writeHeaders(http2HeaderFields(scheme, authority, method, path, headers, headerKeys), len(reqBody) == 0); err != nil {
+		return response.Response{}, err
+	}
+
+	if err := rt.writeBody(reqBody); err != nil {
+		return response.Response{}, err
+	}
+
+	for !rt.done {
+		if err := rt.readFrame(); err != nil {
+			return response.Response{}, err
+		}
+	}
+
+	return rt.response(c.maxDecompressedSize)
+}
+
+// http2HeaderFields returns the header fields of the request: the pseudo-headers first (:method,
+// :scheme, :authority and :path), and then the request headers, in the order given by headerKeys
+// (see [request.Request.HeaderKeys]), lowercase, with the connection-specific ones left out.
+//
+// The Host header, if any, is sent as the :authority, instead of the given one (from the URL).
+func http2HeaderFields(scheme, authority, method, path string, headers http.Header, headerKeys []string) []hpack.HeaderField {
+	for _, k := range headerKeys {
+		if strings.EqualFold(k, "Host") && len(headers[k]) > 0 {
+			authority = headers[k][0]
+		}
+	}
+
+	fields := []hpack.HeaderField{{Name: ":method", Value: method}}
+	if method != http.MethodConnect {
+		fields = append(fields, hpack.HeaderField{Name: ":scheme", Value: scheme})
+	}
+	fields = append(fields, hpack.HeaderField{Name: ":authority", Value: authority})
+	if method != http.MethodConnect {
+		fields = append(fields, hpack.HeaderField{Name: ":path", Value: path})
+	}
+
+	for _, k := range headerKeys {
+		name := strings.ToLower(k)
+		if name == "host" || slices.In(http2ConnectionHeaders, name) {
+			continue
+		}
+
+		for _, v := range headers[k] {
+			if name == "te" && !strings.EqualFold(v, "trailers") {
+				continue
+			}
+
+			fields = append(fields, hpack.HeaderField{Name: name, Value: v})
+		}
+	}
+
+	return fields
+}
+
+// http2RoundTrip holds the state of a single HTTP/2 request-response exchange:
+// the flow-control windows and settings received from the server, and the
+// response being read.
+type http2RoundTrip struct {
+	bw     *bufio.Writer
+	framer *http2.Framer
+
+	maxFrameSize  int
+	initialWindow int
+	connWindow    int
+	streamWindow  int
+
+	proto   string
+	code    int
+	headers map[string][]string
+	head    bytes.Buffer
+	body    bytes.Buffer
+	done    bool
+}
+
+// writePreface writes the connection preface, followed by the client settings:
+// no server push, and flow-control windows large enough for the whole response.
+func (rt *http2RoundTrip) writePreface() error {
+	if _, err := rt.bw.WriteString(http2.ClientPreface); err != nil {
+		return err
+	}
+
+	err := rt.framer.WriteSettings(
+		http2.Setting{ID: http2.SettingEnablePush, Val: 0},
+		http2.Setting{ID: http2.SettingInitialWindowSize, Val: http2Window},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := rt.framer.WriteWindowUpdate(0, http2Window-http2DefaultWindow); err != nil {
+		return err
+	}
+
+	return rt.bw.Flush()
+}
+
+// writeHeaders writes the HPACK-encoded header fields, split into HEADERS
+// and CONTINUATION frames, if larger than the maximum frame size.
+func (rt *http2RoundTrip) writeHeaders(fields []hpack.HeaderField, endStream bool) error {
+	var block bytes.Buffer
+	enc := hpack.NewEncoder(&block)
+	for _, f := range fields {
+		if err := enc.WriteField(f); err != nil {
+			return err
+		}
+	}
+
+	// The server's settings may not be received yet, so the default size is used.
+	fragment := block.Bytes()
+	for first := true; first || len(fragment) > 0; first = false {
+		chunk := fragment[:min(len(fragment), http2DefaultMaxFrameSize)]
+		fragment = fragment[len(chunk):]
+
+		var err error
+		if first {
+			err = rt.framer.WriteHeaders(http2.HeadersFrameParam{
+				StreamID:      http2StreamID,
+				BlockFragment: chunk,
+				EndStream:     endStream,
+				EndHeaders:    len(fragment) == 0,
+			})
+		} else {
+			err = rt.framer.WriteContinuation(http2StreamID, len(fragment) == 0, chunk)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return rt.bw.Flush()
+}
+
+// writeBody writes the request body as DATA frames, within the flow-control windows,
+// reading the server frames (e.g. WINDOW_UPDATE) whenever those are exhausted. It stops
+// early if the response is complete before the whole body is written (e.g. 413).
+func (rt *http2RoundTrip) writeBody(body []byte) error {
+	for len(body) > 0 && !rt.done {
+		n := min(len(body), rt.maxFrameSize, rt.connWindow, rt.streamWindow)
+		if n <= 0 {
+			if err := rt.readFrame(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := rt.framer.WriteData(http2StreamID, n == len(body), body[:n]); err != nil {
+			return err
+		}
+
+		if err := rt.bw.Flush(); err != nil {
+			return err
+		}
+
+		body = body[n:]
+		rt.connWindow -= n
+		rt.streamWindow -= n
+	}
+
+	return nil
+}
+
+// readFrame reads the next frame from the server and processes it: settings and pings are
+// acknowledged, window updates are applied, and those from the request's stream (i.e. the
+// response headers, data, trailers or reset) are recorded. Other streams are ignored.
+func (rt *http2RoundTrip) readFrame() error {
+	frame, err := rt.framer.ReadFrame()
+	if err != nil {
+		return err
+	}
+
+	switch f := frame.(type) {
+	case *http2.SettingsFrame:
+		if f.IsAck() {
+			return nil
+		}
+
+		if err := f.ForeachSetting(rt.applySetting); err != nil {
+			return err
+		}
+
+		return rt.flushAfter(rt.framer.WriteSettingsAck())
+	case *http2.WindowUpdateFrame:
+		if f.StreamID == 0 {
+			rt.connWindow += int(f.Increment)
+		} else if f.StreamID == http2StreamID {
+			rt.streamWindow += int(f.Increment)
+		}
+	case *http2.PingFrame:
+		if !f.IsAck() {
+			return rt.flushAfter(rt.framer.WritePing(true, f.Data))
+		}
+	case *http2.MetaHeadersFrame:
+		if f.StreamID == http2StreamID {
+			return rt.readHeaders(f)
+		}
+	case *http2.DataFrame:
+		if f.StreamID == http2StreamID {
+			rt.body.Write(f.Data())
+			rt.done = f.StreamEnded()
+		}
+	case *http2.RSTStreamFrame:
+		if f.StreamID == http2StreamID {
+			return fmt.Errorf("%w: %s", ErrHTTP2StreamReset, f.ErrCode)
+		}
+	case *http2.GoAwayFrame:
+		if f.LastStreamID < http2StreamID {
+			return fmt.Errorf("%w: %s", ErrHTTP2GoAway, f.ErrCode)
+		}
+	}
+
+	return nil
+}
+
+func (rt *http2RoundTrip) applySetting(s http2.Setting) error {
+	switch s.ID { //nolint:exhaustive
+	case http2.SettingMaxFrameSize:
+		rt.maxFrameSize = int(s.Val)
+	case http2.SettingInitialWindowSize:
+		// The delta applies to the windows of the streams already open.
+		rt.streamWindow += int(s.Val) - rt.initialWindow
+		rt.initialWindow = int(s.Val)
+	}
+
+	return nil
+}
+
+func (rt *http2RoundTrip) flushAfter(err error) error {
+	if err != nil {
+		return err
+	}
+
+	return rt.bw.Flush()
+}
+
+// readHeaders records the response headers, skipping the informational (1xx) ones,
+// or the trailers, if the headers were already received. The status line and the
+// headers are also recorded as text, like HTTP/1.x ones (see [response.Response.RawHeaders]),
+// as there are no raw headers in HTTP/2 (those are HPACK-encoded).
+func (rt *http2RoundTrip) readHeaders(f *http2.MetaHeadersFrame) error {
+	rt.done = f.StreamEnded()
+
+	// Trailers are appended to the headers.
+	if rt.code != 0 {
+		for _, hf := range f.RegularFields() {
+			key := textproto.CanonicalMIMEHeaderKey(hf.Name)
+			rt.headers[key] = append(rt.headers[key], hf.Value)
+		}
+
+		return nil
+	}
+
+	status := f.PseudoValue("status")
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 100 || code > 999 {
+		return fmt.Errorf("%w: %q", ErrHTTP2InvalidStatus, status)
+	}
+
+	if code < 200 && !rt.done {
+		return nil
+	}
+
+	rt.proto, rt.code, rt.headers = http2Proto, code, make(map[string][]string)
+	fmt.Fprintf(&rt.head, "%s %d %s\r\n", rt.proto, rt.code, http.StatusText(rt.code))
+
+	for _, hf := range f.RegularFields() {
+		key := textproto.CanonicalMIMEHeaderKey(hf.Name)
+		rt.headers[key] = append(rt.headers[key], hf.Value)
+		fmt.Fprintf(&rt.head, "%s: %s\r\n", hf.Name, hf.Value)
+	}
+
+	rt.head.WriteString("\r\n")
+
+	return nil
+}
+
+// response returns the [response.Response] read, with the body decompressed,
+// if gzip-encoded, like HTTP/1.x ones, up to the given size, if any.
+func (rt *http2RoundTrip) response(maxDecompressedSize int64) (response.Response, error) {
+	res := response.Response{
+		Proto:      rt.proto,
+		Code:       rt.code,
+		Status:     http.StatusText(rt.code),
+		Headers:    rt.headers,
+		Body:       rt.body.Bytes(),
+		RawHeaders: rt.head.Bytes(),
+	}
+
+	if strings.Contains(strings.Join(res.Headers["Content-Encoding"], " "), "gzip") {
+		gz, err := gzip.NewReader(&rt.body)
+		if err != nil {
+			return response.Response{}, ErrInvalidGZIP
+		}
+
+		var body io.Reader = gz
+		if maxDecompressedSize > 0 {
+			body = &decompressedReader{reader: gz, remaining: maxDecompressedSize}
+		}
+
+		if res.Body, res.DecompressionTruncated, err = readDecompressed(body); err != nil {
+			return response.Response{}, ErrInvalidGZIP
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/ntlm"
+)
+
+const (
+	ntlmScheme       = "NTLM"
+	connectionHeader = "Connection"
+)
+
+// doNTLM performs the request through an NTLM-authenticated connection (see [WithNTLM]).
+//
+// As NTLM authenticates connections (not requests), the connection previously authenticated
+// for the same host, if any, is reused. Otherwise, the handshake is performed over a new one:
+// the request is sent with the negotiate (type 1) message and, if the server responds with
+// a challenge (type 2), it is sent again (over the same connection) with the authenticate
+// (type 3) message. If the server doesn't require NTLM, the first response is returned as is.
+//
+// So, requests are sent with the Connection: keep-alive header, and the connection is kept
+// for the next request to the same host, unless the server closes it. The returned connection,
+// if any, is the one that must be closed (i.e. it hasn't been kept).
+func (c *Client) doNTLM(
+	ctx context.Context,
+	protocol, host, method, path, proto string,
+	headers http.Header, headerKeys, rawHeaders, lineEndings []string, body io.Reader,
+	timeout time.Duration,
+) (net.Conn, response.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, response.Response{}, err
+		}
+	}
+
+	headers, headerKeys = withHeader(headers, headerKeys, connectionHeader, "keep-alive")
+
+	// send sends the request over the given connection,
+	// with the given Authorization header, if any.
+	send := func(conn net.Conn, authorization string) (response.Response, error) {
+		headers, headerKeys := headers, headerKeys
+		if len(authorization) > 0 {
+			headers, headerKeys = withHeader(headers, headerKeys, "Authorization", authorization)
+		}
+
+		if timeout > 0 {
+			if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+				return response.Response{}, err
+			}
+		}
+
+		return c.roundTrip(conn, method, path, proto, headers, headerKeys, rawHeaders, lineEndings, bytes.NewReader(payload))
+	}
+
+	if conn := c.takeNTLMConn(host); conn != nil {
+		res, err := send(conn, "")
+		if _, required := ntlmChallenge(res); err == nil && !required {
+			return c.keepNTLMConn(host, conn, res), res, nil
+		}
+
+		// The connection was closed by the server (or it isn't authenticated
+		// anymore), so the handshake is performed over a new one.
+		_ = c.closeConn(conn)
+	}
+
+	conn, err := c.connect(ctx, protocol, host, proto, timeout)
+	if err != nil {
+		return nil, response.Response{}, err
+	}
+
+	negotiate := ntlmScheme + " " + base64.StdEncoding.EncodeToString(ntlm.Negotiate())
+	res, err := send(conn, negotiate)
+	if err != nil {
+		return conn, res, err
+	}
+
+	challenge, required := ntlmChallenge(res)
+	if !required || challenge == nil {
+		// The host doesn't require NTLM (or the handshake cannot continue),
+		// so the connection isn't kept, as it isn't authenticated.
+		return conn, res, nil
+	}
+
+	msg, err := ntlm.Authenticate(*challenge, *c.ntlm)
+	if err != nil {
+		return conn, response.Response{}, err
+	}
+
+	authenticate := ntlmScheme + " " + base64.StdEncoding.EncodeToString(msg)
+	res, err = send(conn, authenticate)
+	if err != nil {
+		return conn, res, err
+	}
+
+	return c.keepNTLMConn(host, conn, res), res, nil
+}
+
+// ntlmChallenge returns whether the given response requires NTLM authentication
+// (i.e. it's a 401 with the WWW-Authenticate: NTLM header) and, if so, the challenge
+// (type 2) message from that header, if any (i.e. a handshake is ongoing) and valid.
+func ntlmChallenge(res response.Response) (*ntlm.Challenge, bool) {
+	if res.Code != http.StatusUnauthorized {
+		return nil, false
+	}
+
+	var required bool
+	for _, value := range res.Headers[textproto.CanonicalMIMEHeaderKey("WWW-Authenticate")] {
+		scheme, token, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if !strings.EqualFold(scheme, ntlmScheme) {
+			continue
+		}
+
+		required = true
+
+		msg, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+		if err != nil || len(msg) == 0 {
+			continue
+		}
+
+		if challenge, err := ntlm.ParseChallenge(msg); err == nil {
+			return &challenge, true
+		}
+	}
+
+	return nil, required
+}
+
+// takeNTLMConn returns (and removes) the connection authenticated for
+// the given host, if any. So, it's never used by two requests at once.
+func (c *Client) takeNTLMConn(host string) net.Conn {
+	c.ntlmMu.Lock()
+	defer c.ntlmMu.Unlock()
+
+	conn := c.ntlmConns[host]
+	delete(c.ntlmConns, host)
+
+	return conn
+}
+
+// keepNTLMConn keeps the given (authenticated) connection for the next request
+// to the given host, unless the server closes it, or the response body isn't
+// delimited (i.e. it's read until the connection is closed), or it hasn't been
+// read entirely (i.e. truncated once decompressed). It returns the connection
+// if it hasn't been kept, so it can be closed.
+func (c *Client) keepNTLMConn(host string, conn net.Conn, res response.Response) net.Conn {
+	delimited := contentLength(res.Headers) >= 0 || transferEncoding(res.Headers) == "chunked"
+	closed := strings.EqualFold(strings.Join(res.Headers[connectionHeader], ","), "close")
+
+	if res.Code == http.StatusUnauthorized || res.Proto != defaultProto || closed || !delimited || res.DecompressionTruncated {
+		return conn
+	}
+
+	c.ntlmMu.Lock()
+	defer c.ntlmMu.Unlock()
+
+	if c.ntlmConns == nil {
+		c.ntlmConns = make(map[string]net.Conn)
+	}
+
+	// There's one connection kept per host, as clients are used
+	// by one request at a time, so any previous one is replaced.
+	if prev, ok := c.ntlmConns[host]; ok {
+		_ = c.closeConn(prev)
+	}
+
+	c.ntlmConns[host] = conn
+
+	return nil
+}
+
+// withHeader returns a copy of the given headers (and keys) with the given header set
+// (case-insensitive), in the same position, if present, so the request isn't modified.
+func withHeader(headers http.Header, headerKeys []string, key, value string) (http.Header, []string) {
+	cloned := make(http.Header, len(headers)+1)
+	for k, v := range headers {
+		if !strings.EqualFold(k, key) {
+			cloned[k] = v
+		}
+	}
+
+	cloned[key] = []string{value}
+
+	keys := make([]string, 0, len(headerKeys)+1)
+	found := false
+	for _, k := range headerKeys {
+		if !strings.EqualFold(k, key) {
+			keys = append(keys, k)
+		} else if !found {
+			keys = append(keys, key)
+			found = true
+		}
+	}
+
+	if !found {
+		keys = append(keys, key)
+	}
+
+	return cloned, keys
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httputil"
 	"net/textproto"
 	"strconv"
@@ -26,11 +27,67 @@ var (
 	ErrInvalidGZIP = errors.New("invalid gzip encoding")
 )
 
+// reader reads HTTP responses. If lenient, it also accepts HTTP/0.9-style responses
+// (i.e. the body only, without status line nor headers), loosely formed status lines
+// (e.g. HTTP/2 200) and malformed header lines, which are skipped.
+//
+// If head is set, the status line and the headers are recorded as read (i.e. verbatim,
+// including the malformed lines), so these can be inspected afterward.
+//
+// If maxDecompressedSize is set, gzipped bodies are truncated once decompressed beyond
+// it (see [decompressedReader]), so decompression bombs cannot exhaust the memory.
 type reader struct {
 	*bufio.Reader
+	lenient             bool
+	head                *bytes.Buffer
+	maxDecompressedSize int64
+}
+
+func (r *reader) ReadByte() (byte, error) {
+	c, err := r.Reader.ReadByte()
+	if err == nil && r.head != nil {
+		r.head.WriteByte(c)
+	}
+
+	return c, err
+}
+
+func (r *reader) ReadString(delim byte) (string, error) {
+	line, err := r.Reader.ReadString(delim)
+	if r.head != nil {
+		r.head.WriteString(line)
+	}
+
+	return line, err
+}
+
+func (r *reader) ReadBytes(delim byte) ([]byte, error) {
+	line, err := r.Reader.ReadBytes(delim)
+	if r.head != nil {
+		r.head.Write(line)
+	}
+
+	return line, err
+}
+
+// readMessage reads the rest of the status line (i.e. the message),
+// recording it (with the line ending), if the head is set.
+func (r *reader) readMessage() (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
 }
 
 func (r *reader) readResponse() (string, int, string, map[string][]string, io.Reader, error) {
+	if r.lenient {
+		if prefix, _ := r.Peek(len("HTTP/")); len(prefix) > 0 && !bytes.EqualFold(prefix, []byte("HTTP/")) {
+			return simpleProto, 0, "", make(map[string][]string), r, nil
+		}
+	}
+
 	proto, code, msg, err := r.readStatusLine()
 	if err != nil {
 		return "", 0, "", nil, nil, fmt.Errorf("%w: %s", ErrInvalidStatusLine, err.Error())
@@ -45,6 +102,10 @@ func (r *reader) readResponse() (string, int, string, map[string][]string, io.Re
 		)
 
 		key, value, done, err = r.readHeader()
+		if r.lenient && errors.Is(err, ErrInvalidHeader) {
+			continue
+		}
+
 		if err != nil || done {
 			break
 		}
@@ -60,11 +121,23 @@ func (r *reader) readResponse() (string, int, string, map[string][]string, io.Re
 		headers[key] = append(headers[key], value)
 	}
 
+	// The body isn't recorded, only the status line and the headers.
+	r.head = nil
+
+	// Once switched (e.g. to WebSocket), the connection no longer speaks HTTP,
+	// so there's no body to read, as it would block until the timeout.
+	if code == http.StatusSwitchingProtocols {
+		return proto, code, msg, headers, http.NoBody, err
+	}
+
+	// Chunked takes precedence over the Content-Length, if both are
+	// present (see RFC 9112, section 6.3), so chunks are never leaked. The underlying
+	// [bufio.Reader] is given, so the trailer section isn't buffered away from it.
 	var body io.Reader = r
-	if l := contentLength(headers); l >= 0 {
+	if transferEncoding(headers) == "chunked" {
+		body = &chunkedReader{reader: r, chunks: httputil.NewChunkedReader(r.Reader), headers: headers}
+	} else if l := contentLength(headers); l >= 0 {
 		body = io.LimitReader(body, l)
-	} else if transferEncoding(headers) == "chunked" {
-		body = httputil.NewChunkedReader(body)
 	}
 
 	if strings.Contains(strings.Join(headers["Content-Encoding"], " "), "gzip") {
@@ -72,11 +145,55 @@ func (r *reader) readResponse() (string, int, string, map[string][]string, io.Re
 		if err != nil {
 			return "", 0, "", nil, nil, ErrInvalidGZIP
 		}
+
+		if r.maxDecompressedSize > 0 {
+			body = &decompressedReader{reader: body, remaining: r.maxDecompressedSize}
+		}
 	}
 
 	return proto, code, msg, headers, body, err
 }
 
+// decompressedReader reads a decompressed body up to the given amount of bytes (remaining),
+// and then stops (i.e. io.EOF), so the rest isn't decompressed at all. If there was more
+// to read, it is marked as truncated, so the response can be reported as such.
+type decompressedReader struct {
+	reader    io.Reader
+	remaining int64
+	truncated bool
+}
+
+func (r *decompressedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// A single byte more is read, to determine whether the body was truncated.
+		if n, _ := io.ReadFull(r.reader, make([]byte, 1)); n > 0 {
+			r.truncated = true
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+
+	return n, err
+}
+
+// readDecompressed reads the given body, and returns whether it was truncated
+// once decompressed (see [decompressedReader]), along with the bytes read.
+func readDecompressed(body io.Reader) ([]byte, bool, error) {
+	b, err := io.ReadAll(body)
+	if dr, ok := body.(*decompressedReader); ok {
+		return b, dr.truncated, err
+	}
+
+	return b, false, err
+}
+
 func (r *reader) readProto() (string, error) {
 	var major, minor int
 
@@ -163,6 +280,10 @@ func (r *reader) readStatusCode() (int, error) {
 }
 
 func (r *reader) readStatusLine() (string, int, string, error) {
+	if r.lenient {
+		return r.readLenientStatusLine()
+	}
+
 	proto, err := r.readProto()
 	if err != nil {
 		return "", 0, "", err
@@ -173,9 +294,37 @@ func (r *reader) readStatusLine() (string, int, string, error) {
 		return "", 0, "", err
 	}
 
-	msg, _, err := r.ReadLine()
+	msg, err := r.readMessage()
 
-	return proto, code, string(msg), err
+	return proto, code, msg, err
+}
+
+// readLenientStatusLine reads the status line as whitespace-separated fields (i.e. protocol,
+// status code and message), so it accepts any protocol version (e.g. HTTP/2 or HTTP/1.1.1),
+// status codes with any amount of digits, and the absence of the message.
+func (r *reader) readLenientStatusLine() (string, int, string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", 0, "", err
+	}
+
+	const fields = 3
+	parts := strings.SplitN(strings.TrimRight(line, "\r\n"), " ", fields)
+	if len(parts) < 2 { //nolint:mnd
+		return parts[0], 0, "", nil
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return "", 0, "", fmt.Errorf("%w: %q", ErrInvalidStatusCode, parts[1])
+	}
+
+	var msg string
+	if len(parts) == fields {
+		msg = parts[2]
+	}
+
+	return parts[0], code, msg, nil
 }
 
 func (r *reader) readHeader() (string, string, bool, error) {
@@ -214,15 +363,81 @@ func contentLength(headers map[string][]string) int64 {
 	return -1
 }
 
+// transferEncoding returns the transfer coding the body is framed with: chunked, if it is
+// the last one applied (e.g. gzip, chunked), case-insensitive, or identity otherwise.
+// If there's no Transfer-Encoding header, it returns an empty string.
 func transferEncoding(headers map[string][]string) string {
-	if _, exists := headers["Transfer-Encoding"]; !exists {
+	values, exists := headers["Transfer-Encoding"]
+	if !exists {
 		return ""
 	}
 
-	switch enc := headers["Transfer-Encoding"][0]; enc {
-	case "identity", "chunked":
-		return enc
+	codings := strings.Split(strings.Join(values, ","), ",")
+	if last := strings.TrimSpace(codings[len(codings)-1]); strings.EqualFold(last, "chunked") {
+		return "chunked"
+	}
+
+	return "identity"
+}
+
+// chunkedReader reads a chunked body (see [httputil.NewChunkedReader]), which handles
+// the chunk sizes and extensions, and once the last chunk is read, it reads the trailer
+// section, if any. Trailer fields are added to the given headers (the response ones),
+// so these are available to the matchers as any other header, except those that
+// affect the message framing (see isForbiddenTrailer), which are discarded.
+type chunkedReader struct {
+	reader  *reader
+	chunks  io.Reader
+	headers map[string][]string
+	done    bool
+}
+
+func (cr *chunkedReader) Read(p []byte) (int, error) {
+	if cr.done {
+		return 0, io.EOF
+	}
+
+	n, err := cr.chunks.Read(p)
+	if errors.Is(err, io.EOF) {
+		cr.done = true
+		if err := cr.readTrailers(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+func (cr *chunkedReader) readTrailers() error {
+	for {
+		key, value, done, err := cr.reader.readHeader()
+		switch {
+		// The connection might be closed right after the last chunk.
+		case errors.Is(err, io.EOF):
+			return nil
+		case cr.reader.lenient && errors.Is(err, ErrInvalidHeader):
+			continue
+		case err != nil:
+			return err
+		case done:
+			return nil
+		case key == "":
+			return ErrInvalidHeader
+		case isForbiddenTrailer(key):
+			continue
+		}
+
+		cr.headers[key] = append(cr.headers[key], value)
+	}
+}
+
+// isForbiddenTrailer returns whether the given (canonical) header
+// key affects the message framing, so it's not allowed as trailer.
+func isForbiddenTrailer(key string) bool {
+	switch key {
+	case "Content-Length", "Transfer-Encoding", "Trailer":
+		return true
 	default:
-		return "identity"
+		return false
 	}
 }
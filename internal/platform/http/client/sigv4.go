@@ -0,0 +1,37 @@
+package client
+
+import (
+	stdurl "net/url"
+	"strings"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+// signSigV4 signs the given request (see [WithSigV4]), right before being sent, so the
+// signature covers its final bytes (e.g. once the payload has been injected, and the request
+// mutated). The signature headers are set into the given request, replacing any existing one
+// (regardless of its casing), so these are also present on the requests attached to the results.
+func (c *Client) signSigV4(req *request.Request) {
+	var host string
+	target := req.Path
+	if u, err := stdurl.Parse(req.URL); err == nil {
+		host = u.Host
+		if len(target) == 0 {
+			target = u.EscapedPath()
+			if len(u.RawQuery) > 0 {
+				target += "?" + u.RawQuery
+			}
+		}
+	}
+
+	for _, h := range c.sigv4.Sign(req.Method, host, target, req.Headers, req.Body, time.Now()) {
+		for key := range req.Headers {
+			if strings.EqualFold(key, h.Name) {
+				req.DeleteHeader(key)
+			}
+		}
+
+		req.SetHeader(h.Name, h.Value)
+	}
+}
@@ -5,27 +5,52 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/bountysecurity/gbounty/internal/request"
 )
 
 type writer struct {
 	phase
 	io.Writer
 	tmp io.Writer
+
+	// lineEndings are the original line endings of the head,
+	// if kept (see [request.Request.LineEndings]), and line
+	// is the amount of lines of the head written so far.
+	lineEndings []string
+	line        int
 }
 
-func (w *writer) writeRequest(method, path, proto string, headers map[string][]string, body io.Reader) error {
+// writeRequest writes the request, with the headers written in the order given by
+// headerKeys (see [request.Request.HeaderKeys]). If any raw headers are given, those are
+// written verbatim, in place of the (normalized) framing headers (see [request.IsFramingHeader]).
+//
+// Lines are ended with CRLF, unless any line endings are given (see [request.LineEnding]).
+func (w *writer) writeRequest(method, path, proto string, headers map[string][]string, headerKeys, rawHeaders, lineEndings []string, body io.Reader) error {
+	w.lineEndings = lineEndings
+
 	if err := w.writeRequestLine(method, path, proto); err != nil {
 		return err
 	}
 
-	for k, v := range headers {
-		for _, v := range v {
+	for _, k := range headerKeys {
+		if len(rawHeaders) > 0 && request.IsFramingHeader(k) {
+			continue
+		}
+
+		for _, v := range headers[k] {
 			if err := w.writeHeader(k, v); err != nil {
 				return err
 			}
 		}
 	}
 
+	for _, line := range rawHeaders {
+		if err := w.writeRawHeader(line); err != nil {
+			return err
+		}
+	}
+
 	if err := w.startBodyPhase(); err != nil || body == nil {
 		return err
 	}
@@ -39,12 +64,24 @@ func (w *writer) writeRequestLine(method, path, proto string) error {
 	}
 
 	w.tmp, w.Writer = w.Writer, bufio.NewWriter(w.Writer)
-	_, err := fmt.Fprintf(w, "%s %s %s\r\n", method, path, proto)
+	_, err := fmt.Fprintf(w, "%s %s %s%s", method, path, proto, w.lineEnding())
 	w.startHeadersPhase()
 
 	return err
 }
 
+// writeSimpleRequest writes an HTTP/0.9-style (simple) request, made of the request
+// line only (i.e. without the protocol version, the headers and the body).
+func (w *writer) writeSimpleRequest(method, path string) error {
+	if w.phase != requestline {
+		return &phaseError{requestline, w.phase}
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s\r\n", method, path)
+
+	return err
+}
+
 func (w *writer) startHeadersPhase() {
 	w.phase = header
 }
@@ -56,18 +93,36 @@ func (w *writer) writeHeader(key, value string) error {
 
 	var err error
 	if value != "" {
-		_, err = fmt.Fprintf(w, "%s: %s\r\n", key, value)
+		_, err = fmt.Fprintf(w, "%s: %s%s", key, value, w.lineEnding())
 	} else {
-		_, err = fmt.Fprintf(w, "%s\r\n", key)
+		_, err = fmt.Fprintf(w, "%s%s", key, w.lineEnding())
 	}
 
 	return err
 }
 
+func (w *writer) writeRawHeader(line string) error {
+	if w.phase != header {
+		return &phaseError{header, w.phase}
+	}
+
+	_, err := fmt.Fprintf(w, "%s%s", line, w.lineEnding())
+
+	return err
+}
+
+// lineEnding returns the line ending of the next line of the head (see [request.LineEnding]).
+func (w *writer) lineEnding() string {
+	eol := request.LineEnding(w.lineEndings, w.line, false)
+	w.line++
+
+	return eol
+}
+
 var errUnexpectedWriterType = errors.New("unexpected writer type")
 
 func (w *writer) startBodyPhase() error {
-	if _, err := w.Write([]byte("\r\n")); err != nil {
+	if _, err := w.Write([]byte(request.LineEnding(w.lineEndings, w.line, true))); err != nil {
 		return err
 	}
 
@@ -0,0 +1,127 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/kit/console/color"
+	"github.com/bountysecurity/gbounty/kit/console/printer"
+)
+
+// ErrInvalidBaseline is the error returned when the baseline file (see [ReadBaseline])
+// cannot be read, or when it isn't a valid scan output (JSON).
+var ErrInvalidBaseline = errors.New("invalid baseline")
+
+// baselineOutput is the subset of the [JSON] output read from the baseline file.
+type baselineOutput struct {
+	Matches *[]struct {
+		ID    string `json:"id"`
+		URL   string `json:"url"`
+		Issue struct {
+			Name       string `json:"name"`
+			Severity   string `json:"severity"`
+			Confidence string `json:"confidence"`
+			Param      string `json:"param"`
+		} `json:"issue"`
+		Type string `json:"type"`
+	} `json:"matches"`
+}
+
+// ReadBaseline reads the findings ([scan.Match]) from the baseline file at the given
+// path, which must be the output of a previous scan, written as JSON (see [JSON]).
+// Only the details written to the output (e.g. the identifier, the URL and the issue)
+// are populated, which are enough to compare them (see [scan.DiffBaseline]).
+//
+// Every finding must have its identifier, as it cannot be recomputed (see [scan.MatchID])
+// from those details, so the baseline is rejected otherwise.
+func ReadBaseline(path string) ([]scan.Match, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w(%s): %s", ErrInvalidBaseline, path, err.Error())
+	}
+
+	var out baselineOutput
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("%w(%s): %s", ErrInvalidBaseline, path, err.Error())
+	}
+
+	if out.Matches == nil {
+		return nil, fmt.Errorf(`%w(%s): no "matches" found, it must be a JSON output (-of json)`, ErrInvalidBaseline, path)
+	}
+
+	matches := make([]scan.Match, 0, len(*out.Matches))
+	for idx, m := range *out.Matches {
+		if len(m.ID) == 0 {
+			return nil, fmt.Errorf(`%w(%s): finding #%d has no "id", it must be a JSON output (-of json)`, ErrInvalidBaseline, path, idx+1)
+		}
+
+		matches = append(matches, scan.Match{
+			ID:              m.ID,
+			URL:             m.URL,
+			IssueName:       m.Issue.Name,
+			IssueSeverity:   m.Issue.Severity,
+			IssueConfidence: m.Issue.Confidence,
+			IssueParam:      m.Issue.Param,
+			ProfileType:     m.Type,
+		})
+	}
+
+	return matches, nil
+}
+
+// WriteBaselineDiff writes the [scan.BaselineDiff] to the console: the amount of new,
+// resolved and unchanged findings, followed by the list of new and resolved ones.
+func (c Console) WriteBaselineDiff(_ context.Context, diff scan.BaselineDiff) error {
+	cyan := color.Cyan()
+	lightCyan := color.LightCyan()
+	infoPrinter := printer.Info()
+
+	builder := strings.Builder{}
+	builder.WriteString(defaultSection().Sprintln("# Baseline comparison"))
+	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("New finding(s):"), lightCyan.Sprintf("%d", len(diff.New))))
+	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Resolved finding(s):"), lightCyan.Sprintf("%d", len(diff.Resolved))))
+	builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Unchanged finding(s):"), lightCyan.Sprintf("%d", len(diff.Unchanged))))
+
+	for _, group := range []struct {
+		title   string
+		matches []scan.Match
+	}{{title: "New", matches: diff.New}, {title: "Resolved", matches: diff.Resolved}} {
+		for _, m := range group.matches {
+			builder.WriteString(infoPrinter.Sprintf("%s %s %s\n", cyan.Sprintf("[%s]", group.title), lightCyan.Sprint(m.IssueName), m.URL))
+		}
+	}
+
+	if len(diff.New)+len(diff.Resolved) > 0 {
+		builder.WriteString("\n")
+	}
+
+	_, err := fmt.Fprint(c.writer, builder.String())
+
+	return err
+}
+
+// WriteBaselineDiff writes the [scan.BaselineDiff] to the [io.Writer] as a JSON object,
+// with the identifiers of the new and resolved findings, and the amount of unchanged ones.
+func (j JSON) WriteBaselineDiff(_ context.Context, diff scan.BaselineDiff) error {
+	ids := func(matches []scan.Match) string {
+		quoted := make([]string, 0, len(matches))
+		for _, m := range matches {
+			quoted = append(quoted, jsonMarshaled(m.ID))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+
+	_, err := fmt.Fprintf(j.writer, `,
+	"baseline": {
+		"new": %s,
+		"resolved": %s,
+		"unchanged": %d
+	}`, ids(diff.New), ids(diff.Resolved), len(diff.Unchanged))
+
+	return err
+}
@@ -0,0 +1,64 @@
+package writer_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+)
+
+func TestReadBaseline(t *testing.T) {
+	t.Parallel()
+
+	path := writeOutput(t, `{"matches": [
+		{"id": "a1", "url": "https://example.org/a", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm", "param": "q"}, "type": "active"},
+		{"id": "b2", "url": "https://example.org/b", "issue": {"name": "SQLi"}}
+	]}`)
+
+	matches, err := writer.ReadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, []scan.Match{
+		{ID: "a1", URL: "https://example.org/a", IssueName: "XSS", IssueSeverity: "High", IssueConfidence: "Firm", IssueParam: "q", ProfileType: "active"},
+		{ID: "b2", URL: "https://example.org/b", IssueName: "SQLi"},
+	}, matches)
+
+	// Those from a scan without findings are also valid.
+	matches, err = writer.ReadBaseline(writeOutput(t, `{"matches": []}`))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestReadBaseline_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		contents string
+		err      string
+	}{
+		"invalid json":  {contents: `{"matches": [`},
+		"not an output": {contents: `{"findings": []}`, err: `no "matches" found`},
+		// The identifier cannot be recomputed from the details read, so it'd never match.
+		"without id": {
+			contents: `{"matches": [{"id": "a1", "url": "https://example.org/a"}, {"url": "https://example.org/b"}]}`,
+			err:      `finding #2 has no "id"`,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := writer.ReadBaseline(writeOutput(t, tc.contents))
+			require.ErrorIs(t, err, writer.ErrInvalidBaseline)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	_, err := writer.ReadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, writer.ErrInvalidBaseline)
+}
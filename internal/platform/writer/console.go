@@ -44,11 +44,23 @@ func (c Console) WriteConfig(_ context.Context, cfg scan.Config) error {
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Save on stop:"), lightCyan.Sprintf("%v", cfg.SaveOnStop)))
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Memory-only:"), lightCyan.Sprintf("%v", cfg.InMemory)))
 
+	if cfg.Passive {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Mode:"), lightCyan.Sprint("passive (no payloads injected)")))
+	}
+
+	if len(cfg.ResolveAllTo) > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Sinkhole:"), lightCyan.Sprintf("%s (every host resolved to it)", cfg.ResolveAllTo)))
+	}
+
 	if len(cfg.BlindHost) > 0 {
 		builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Blind host:"), lightCyan.Sprintf("%s", cfg.BlindHost)))
 		builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Blind host key:"), lightCyan.Sprintf("%s", cfg.BlindHostKey)))
 	}
 
+	if len(cfg.Metadata) > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n\n", cyan.Sprint("Metadata:"), lightCyan.Sprintf("%s", metadataString(cfg.Metadata))))
+	}
+
 	_, err := fmt.Fprint(c.writer, builder.String())
 
 	return err
@@ -77,7 +89,37 @@ func (c Console) WriteStats(ctx context.Context, fs scan.FileSystem) error {
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Insertion point(s) found:"), lightCyan.Sprintf("%d", stats.NumOfEntrypoints)))
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Request(s) finished:"), lightCyan.Sprintf("%d", stats.NumOfPerformedRequests)))
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Request(s) failed:"), lightCyan.Sprintf("%d", stats.NumOfFailedRequests)))
-	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Match(es) found:"), lightCyan.Sprintf("%d", stats.NumOfMatches)))
+	if stats.NumOfWarmupRequests > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Warmup request(s):"), lightCyan.Sprintf("%d (%d failed), not included above", stats.NumOfWarmupRequests, stats.NumOfFailedWarmupRequests)))
+	}
+	if stats.NumOfSkippedBodies > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Response body(ies) skipped:"), lightCyan.Sprintf("%d", stats.NumOfSkippedBodies)))
+	}
+	if stats.NumOfFilteredResponses > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Response(s) filtered:"), lightCyan.Sprintf("%d", stats.NumOfFilteredResponses)))
+	}
+	if stats.NumOfMatcherTimeouts > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Matcher(s) timed out:"), lightCyan.Sprintf("%d", stats.NumOfMatcherTimeouts)))
+	}
+	if stats.NumOfDroppedByURLMatch > 0 || stats.NumOfDroppedByURLReject > 0 || stats.NumOfDroppedByExtension > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Input(s) dropped:"), lightCyan.Sprintf("%d (--url-match), %d (--url-reject), %d (--exclude-extensions)", stats.NumOfDroppedByURLMatch, stats.NumOfDroppedByURLReject, stats.NumOfDroppedByExtension)))
+	}
+	if stats.NumOfSkippedTemplates > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Template(s) skipped:"), lightCyan.Sprintf("%d (--skip-if)", stats.NumOfSkippedTemplates)))
+	}
+	if stats.NumOfFilteredTemplates > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Template(s) filtered:"), lightCyan.Sprintf("%d (--template-filter)", stats.NumOfFilteredTemplates)))
+	}
+	if stats.NumOfSkippedHosts > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Host(s) skipped as already seen:"), lightCyan.Sprintf("%d (--only-new-hosts)", stats.NumOfSkippedHosts)))
+	}
+	if len(stats.HostsTimeSpent) > 0 {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Time spent per host:"), lightCyan.Sprint(hostsTimeSpentString(stats.HostsTimeSpent))))
+	}
+	for _, line := range responseStatsLines(stats) {
+		builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint(line[0]+":"), lightCyan.Sprint(line[1])))
+	}
+	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Match(es) found:"), lightCyan.Sprint(matchesFoundString(stats))))
 	builder.WriteString(infoPrinter.Sprintf("%s %s\n", cyan.Sprint("Elapsed time:"), lightCyan.Sprintf("%s", scanDuration)))
 
 	_, err = fmt.Fprint(c.writer, builder.String())
@@ -100,6 +142,7 @@ func (c Console) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) er
 	}
 
 	profileTypes := make(map[string]string)
+	metadata := make(map[string]map[string]string)
 	byIssue := make(map[string]map[string]struct{ count int })
 
 	for match := range ch {
@@ -107,6 +150,7 @@ func (c Console) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) er
 
 		issue := fmt.Sprintf("%s\n%s\n%s", match.IssueName, match.IssueSeverity, match.IssueConfidence)
 		profileTypes[issue] = match.ProfileType
+		metadata[issue] = match.Metadata
 
 		if _, ok := byIssue[issue]; !ok {
 			byIssue[issue] = map[string]struct{ count int }{match.URL: {count: 1}}
@@ -134,6 +178,11 @@ func (c Console) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) er
 		}
 
 		builder.WriteString(urlsPrinter().Sprintln(urlsStr))
+
+		if len(metadata[issue]) > 0 {
+			builder.WriteString(metadataPrinter().Sprintln(metadataString(metadata[issue])))
+		}
+
 		builder.WriteString("\n")
 
 		_, err := fmt.Fprint(c.writer, builder.String())
@@ -248,6 +297,30 @@ func (c Console) WriteMatch(_ context.Context, m scan.Match, includeResponse boo
 		builder.WriteString(paramPrinter().Sprintln(m.IssueParam))
 	}
 
+	if len(m.ID) > 0 {
+		builder.WriteString(idPrinter().Sprintln(m.ID))
+	}
+
+	if len(m.Metadata) > 0 {
+		builder.WriteString(metadataPrinter().Sprintln(metadataString(m.Metadata)))
+	}
+
+	if len(m.RequestIDs) > 0 {
+		builder.WriteString(requestIDsPrinter().Sprintln(strings.Join(m.RequestIDs, ", ")))
+	}
+
+	if m.Origin != nil {
+		builder.WriteString(originPrinter().Sprintln(originString(m.Origin, m.Payload)))
+	}
+
+	for _, ae := range m.AffectedEntrypoints {
+		builder.WriteString(affectedPrinter().Sprintln(affectedEntrypointString(ae)))
+	}
+
+	if m.ResponseDiff != nil {
+		builder.WriteString(diffPrinter().Sprintln(responseDiffString(m.ResponseDiff)))
+	}
+
 	if m.Requests != nil {
 		for _, r := range m.Requests {
 			if r == nil {
@@ -318,6 +391,30 @@ func (c Console) WriteMatches(ctx context.Context, fs scan.FileSystem, includeRe
 			builder.WriteString(paramPrinter().Sprintln(m.IssueParam))
 		}
 
+		if len(m.ID) > 0 {
+			builder.WriteString(idPrinter().Sprintln(m.ID))
+		}
+
+		if len(m.Metadata) > 0 {
+			builder.WriteString(metadataPrinter().Sprintln(metadataString(m.Metadata)))
+		}
+
+		if len(m.RequestIDs) > 0 {
+			builder.WriteString(requestIDsPrinter().Sprintln(strings.Join(m.RequestIDs, ", ")))
+		}
+
+		if m.Origin != nil {
+			builder.WriteString(originPrinter().Sprintln(originString(m.Origin, m.Payload)))
+		}
+
+		for _, ae := range m.AffectedEntrypoints {
+			builder.WriteString(affectedPrinter().Sprintln(affectedEntrypointString(ae)))
+		}
+
+		if m.ResponseDiff != nil {
+			builder.WriteString(diffPrinter().Sprintln(responseDiffString(m.ResponseDiff)))
+		}
+
 		if m.Requests != nil {
 			for _, r := range m.Requests {
 				if r == nil {
@@ -0,0 +1,266 @@
+package writer
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// HTML must implement the [scan.Writer] interface.
+var _ scan.Writer = &HTML{}
+
+//go:embed html.tmpl
+var htmlTemplateContents string
+
+// htmlTemplate is the (embedded) template the HTML reports are rendered with, which
+// has its styles inlined, so the reports are self-contained (i.e. no external assets).
+// Everything is HTML-escaped (see [html/template]), so the evidence (e.g. a reflected
+// payload) cannot become a XSS vector when the report is opened.
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"join":     strings.Join,
+	"metadata": metadataString,
+	"inc":      func(i int) int { return i + 1 },
+}).Parse(htmlTemplateContents))
+
+// HTML is a [scan.Writer] implementation that writes the output to the given
+// [io.Writer] as a single self-contained HTML report, meant to be shared with
+// non-technical stakeholders.
+//
+// The report has a summary (i.e. the [scan.Stats]) and the findings grouped by severity
+// (see [scan.Severities]) and profile, each with its requests (and its responses, only if
+// [scan.Config.ShowResponses]) as collapsible details.
+//
+// The whole report is written at once, with the findings summary (see [HTML.WriteMatchesSummary]),
+// with the findings read once per group, so these aren't kept in memory all together. So, the
+// failed requests, and the requests and responses summaries (see [scan.Config.ShowAll]) are not written.
+type HTML struct {
+	writer io.Writer
+	cfg    scan.Config
+}
+
+// NewHTML creates a new instance of [HTML] with the given [io.Writer] and [scan.Config],
+// given in advance because [HTML.WriteConfig] is not called in silent mode.
+func NewHTML(writer io.Writer, cfg scan.Config) *HTML {
+	return &HTML{writer: writer, cfg: cfg}
+}
+
+type htmlReport struct {
+	Config   scan.Config
+	Stats    *scan.Stats
+	Duration time.Duration
+	Matches  string
+	Groups   []htmlGroup
+}
+
+// htmlGroup is a group of findings, those with the same severity and profile.
+type htmlGroup struct {
+	Severity string
+	Profile  string
+	Count    int
+	Anchor   string
+	Class    string
+}
+
+type htmlFinding struct {
+	Match     scan.Match
+	Requests  []string
+	Responses []string
+}
+
+// WriteConfig keeps the [scan.Config], so it is available within the report.
+// Nothing is written to the [io.Writer].
+func (h *HTML) WriteConfig(_ context.Context, cfg scan.Config) error {
+	h.cfg = cfg
+	return nil
+}
+
+// WriteStats does nothing, as the [scan.Stats] are written as part of
+// the report summary (see [HTML.WriteMatchesSummary]).
+func (h *HTML) WriteStats(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatchesSummary writes the whole HTML report, built from the [scan.Stats]
+// and the [scan.Match] instances found during the [scan].
+func (h *HTML) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error {
+	stats, err := fs.LoadStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	groups, err := htmlGroups(ctx, fs)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(h.writer)
+
+	report := htmlReport{
+		Config:   h.cfg,
+		Stats:    stats,
+		Duration: roundDuration(time.Since(stats.StartedAt)),
+		Matches:  matchesFoundString(stats),
+		Groups:   groups,
+	}
+
+	if err := htmlTemplate.ExecuteTemplate(w, "head", report); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if err := htmlTemplate.ExecuteTemplate(w, "group", group); err != nil {
+			return err
+		}
+
+		if err := h.writeGroup(ctx, w, fs, group); err != nil {
+			return err
+		}
+	}
+
+	if err := htmlTemplate.ExecuteTemplate(w, "foot", nil); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// writeGroup writes the findings of the given group, read from the given [scan.FileSystem].
+func (h *HTML) writeGroup(ctx context.Context, w io.Writer, fs scan.FileSystem, group htmlGroup) error {
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeIt()
+
+	for m := range ch {
+		if htmlSeverity(m.IssueSeverity) != group.Severity || m.ProfileName != group.Profile {
+			continue
+		}
+
+		if err := htmlTemplate.ExecuteTemplate(w, "finding", htmlFindingOf(m, h.cfg.ShowResponses)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// htmlGroups returns the groups of findings (see [htmlGroup]), sorted by severity
+// (from the highest to the lowest, see [scan.Severities]) and by profile name.
+func htmlGroups(ctx context.Context, fs scan.FileSystem) ([]htmlGroup, error) {
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[htmlGroup]int)
+	for m := range ch {
+		counts[htmlGroup{Severity: htmlSeverity(m.IssueSeverity), Profile: m.ProfileName}]++
+	}
+
+	closeIt()
+
+	groups := make([]htmlGroup, 0, len(counts))
+	for group, count := range counts {
+		group.Count = count
+		groups = append(groups, group)
+	}
+
+	rank := func(severity string) int {
+		for idx, s := range scan.Severities() {
+			if s == severity {
+				return idx
+			}
+		}
+		return len(scan.Severities())
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if ri, rj := rank(groups[i].Severity), rank(groups[j].Severity); ri != rj {
+			return ri < rj
+		}
+		if groups[i].Severity != groups[j].Severity {
+			return groups[i].Severity < groups[j].Severity
+		}
+		return groups[i].Profile < groups[j].Profile
+	})
+
+	for idx := range groups {
+		groups[idx].Anchor = fmt.Sprintf("group-%d", idx+1)
+		groups[idx].Class = "sev-other"
+		if rank(groups[idx].Severity) < len(scan.Severities()) {
+			groups[idx].Class = "sev-" + strings.ToLower(groups[idx].Severity)
+		}
+	}
+
+	return groups, nil
+}
+
+// htmlSeverity returns the given severity in its canonical form (see [scan.ParseSeverity]),
+// or "Unknown" if empty, so findings are grouped regardless of the severity case.
+func htmlSeverity(severity string) string {
+	if len(strings.TrimSpace(severity)) == 0 {
+		return "Unknown"
+	}
+
+	canonical, _ := scan.ParseSeverity(severity)
+
+	return canonical
+}
+
+// htmlFindingOf returns the given finding, along with its raw requests and,
+// only if includeResponses is true, its raw responses.
+func htmlFindingOf(m scan.Match, includeResponses bool) htmlFinding {
+	finding := htmlFinding{Match: m}
+
+	for _, req := range m.Requests {
+		if req != nil {
+			finding.Requests = append(finding.Requests, string(req.Bytes()))
+		}
+	}
+
+	if includeResponses {
+		for _, res := range m.Responses {
+			if res != nil {
+				finding.Responses = append(finding.Responses, string(res.Bytes()))
+			}
+		}
+	}
+
+	return finding
+}
+
+// WriteError does nothing, see [HTML].
+func (h *HTML) WriteError(context.Context, scan.Error) error {
+	return nil
+}
+
+// WriteErrors does nothing, see [HTML].
+func (h *HTML) WriteErrors(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatch does nothing, as the findings are written as part of
+// the report (see [HTML.WriteMatchesSummary]).
+func (h *HTML) WriteMatch(context.Context, scan.Match, bool) error {
+	return nil
+}
+
+// WriteMatches does nothing, as the findings are written as part of
+// the report (see [HTML.WriteMatchesSummary]).
+func (h *HTML) WriteMatches(context.Context, scan.FileSystem, bool) error {
+	return nil
+}
+
+// WriteTasks does nothing, see [HTML].
+func (h *HTML) WriteTasks(context.Context, scan.FileSystem, bool, bool) error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package writer_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestHTML_WriteMatchesSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := newTestFs(t,
+		testMatch("/a", "XSS", "Medium"),
+		testMatch("/b", "SQLi", "high"),
+		testMatch("/c", "XSS", "Medium"),
+		testMatch("/d", "Banner", ""),
+	)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writer.NewHTML(buf, scan.Config{Version: "v1.0.0"}).WriteMatchesSummary(ctx, fs))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"), out)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(out), "</html>"), out)
+	assert.Contains(t, out, "Version v1.0.0")
+
+	// Groups are sorted by severity (canonical, or unknown if empty),
+	// and each one is written along with its findings.
+	high := strings.Index(out, `<h2 id="group-1"><span class="badge sev-high">High</span> SQLi (1)</h2>`)
+	medium := strings.Index(out, `<h2 id="group-2"><span class="badge sev-medium">Medium</span> XSS (2)</h2>`)
+	unknown := strings.Index(out, `<h2 id="group-3"><span class="badge sev-other">Unknown</span> Banner (1)</h2>`)
+	require.Positive(t, high, out)
+	require.Greater(t, medium, high)
+	require.Greater(t, unknown, medium)
+
+	assert.Contains(t, out[high:medium], "https://example.org/b")
+	assert.Contains(t, out[medium:unknown], "https://example.org/a")
+	assert.Contains(t, out[medium:unknown], "https://example.org/c")
+	assert.Contains(t, out[unknown:], "https://example.org/d")
+}
+
+func TestHTML_WriteMatchesSummary_Escaped(t *testing.T) {
+	t.Parallel()
+
+	const payload = `<script>alert("gbounty")</script>`
+
+	ctx := context.Background()
+
+	m := testMatch("/search", "XSS", "High")
+	m.Payload = payload
+	m.IssueDetail = "Reflected: " + payload
+
+	req := request.Default("https://example.org/search?q=" + payload)
+	res := response.Response{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte("<html>" + payload + "</html>")}
+	m.Requests = []*request.Request{&req}
+	m.Responses = []*response.Response{&res}
+
+	fs := newTestFs(t, m)
+
+	for name, showResponses := range map[string]bool{"without responses": false, "with responses": true} {
+		buf := new(bytes.Buffer)
+		require.NoError(t, writer.NewHTML(buf, scan.Config{ShowResponses: showResponses}).WriteMatchesSummary(ctx, fs))
+
+		out := buf.String()
+		assert.NotContains(t, out, "<script>", name)
+		assert.Contains(t, out, "&lt;script&gt;alert(&#34;gbounty&#34;)&lt;/script&gt;", name)
+		assert.Equal(t, showResponses, strings.Contains(out, "Response no. 1"), name)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 	"time"
 
 	scan "github.com/bountysecurity/gbounty/internal"
@@ -34,8 +36,37 @@ func (j JSON) WriteConfig(_ context.Context, cfg scan.Config) error {
 		"saveOnStop": %v,
 		"memoryOnly": %v,
 		"blindHost": "%s",
-		"blindHostKey": "%s"
-	}`, cfg.Version, cfg.RPS, cfg.Concurrency, cfg.SaveOnStop, cfg.InMemory, cfg.BlindHost, cfg.BlindHostKey)
+		"blindHostKey": "%s"`, cfg.Version, cfg.RPS, cfg.Concurrency, cfg.SaveOnStop, cfg.InMemory, cfg.BlindHost, cfg.BlindHostKey)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Passive {
+		_, err = fmt.Fprint(j.writer, `,
+		"mode": "passive"`)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.ResolveAllTo) > 0 {
+		_, err = fmt.Fprintf(j.writer, `,
+		"sinkhole": %s`, jsonMarshaled(cfg.ResolveAllTo))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Metadata) > 0 {
+		_, err = fmt.Fprintf(j.writer, `,
+		"metadata": %s`, jsonMarshaledMap(cfg.Metadata))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprint(j.writer, `
+	}`)
 
 	return err
 }
@@ -54,17 +85,75 @@ func (j JSON) WriteStats(ctx context.Context, fs scan.FileSystem) error {
 		scanDuration = scanDuration.Round(time.Millisecond)
 	}
 
+	// The time spent per host is only accounted with a time budget per host.
+	var hostsTimeSpent string
+	if len(stats.HostsTimeSpent) > 0 {
+		spent := make(map[string]string, len(stats.HostsTimeSpent))
+		for host, d := range stats.HostsTimeSpent {
+			spent[host] = roundDuration(d).String()
+		}
+
+		encoded, err := json.Marshal(spent)
+		if err != nil {
+			return err
+		}
+
+		hostsTimeSpent = fmt.Sprintf(`
+		"hostsTimeSpent": %s,`, encoded)
+	}
+
+	// The response stats are only reported when any response was received.
+	var responses string
+	if stats.Responses != nil && stats.Responses.Sizes.Count > 0 {
+		encoded, err := json.Marshal(jsonResponseStatsFrom(stats))
+		if err != nil {
+			return err
+		}
+
+		responses = fmt.Sprintf(`
+		"responses": %s,`, encoded)
+	}
+
+	// The warmup requests are only reported when warmup is enabled, apart from the scan requests.
+	var warmup string
+	if stats.NumOfWarmupRequests > 0 {
+		warmup = fmt.Sprintf(`
+		"warmup": {
+			"requests": %d,
+			"failures": %d
+		},`, stats.NumOfWarmupRequests, stats.NumOfFailedWarmupRequests)
+	}
+
+	// The amount of grouped findings is only reported when those are grouped.
+	var groupedMatches string
+	if stats.NumOfGroupedMatches > 0 {
+		groupedMatches = fmt.Sprintf(`
+		"groupedMatches": %d,`, stats.NumOfGroupedMatches)
+	}
+
 	_, err = fmt.Fprintf(j.writer, `,
 	"results": {
 		"insertionPoints": %d,
 		"requests": %d,
 		"failures": %d,
-		"successes": %d,
-		"matches": %d,
+		"successes": %d,%s
+		"skippedBodies": %d,
+		"filteredResponses": %d,
+		"droppedInputs": {
+			"urlMatch": %d,
+			"urlReject": %d,
+			"extension": %d
+		},
+		"skippedTemplates": %d,
+		"filteredTemplates": %d,
+		"skippedHosts": %d,
+		"matcherTimeouts": %d,%s%s
+		"matches": %d,%s
 		"duration": "%s"
 	}`,
 		stats.NumOfEntrypoints, stats.NumOfPerformedRequests, stats.NumOfFailedRequests,
-		stats.NumOfSucceedRequests, stats.NumOfMatches, scanDuration,
+		stats.NumOfSucceedRequests, warmup, stats.NumOfSkippedBodies, stats.NumOfFilteredResponses,
+		stats.NumOfDroppedByURLMatch, stats.NumOfDroppedByURLReject, stats.NumOfDroppedByExtension, stats.NumOfSkippedTemplates, stats.NumOfFilteredTemplates, stats.NumOfSkippedHosts, stats.NumOfMatcherTimeouts, hostsTimeSpent, responses, stats.NumOfMatches, groupedMatches, scanDuration,
 	)
 
 	return err
@@ -85,7 +174,9 @@ func (j JSON) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error
 	}
 
 	profileTypes := make(map[string]string)
+	metadata := make(map[string]map[string]string)
 	byIssue := make(map[string]map[string]struct{ count int })
+	issues := make(map[string]summaryIssue)
 
 	for match := range ch {
 		issue := fmt.Sprintf(`{
@@ -94,7 +185,9 @@ func (j JSON) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error
 				"confidence": "%s"
 			},`, match.IssueName, match.IssueSeverity, match.IssueConfidence)
 
+		issues[issue] = summaryIssue{Name: match.IssueName, Severity: match.IssueSeverity, Confidence: match.IssueConfidence}
 		profileTypes[issue] = match.ProfileType
+		metadata[issue] = match.Metadata
 
 		if _, ok := byIssue[issue]; !ok {
 			byIssue[issue] = map[string]struct{ count int }{match.URL: {count: 1}}
@@ -105,10 +198,17 @@ func (j JSON) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error
 		}
 	}
 
+	// The issues are sorted, so the summary is stable (and equal to the merged one, see [MergeJSON]).
+	sorted := make([]string, 0, len(issues))
+	for issue := range issues {
+		sorted = append(sorted, issue)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return issues[sorted[i]].less(issues[sorted[j]]) })
+
 	first := true
 
-	for issue, mUrls := range byIssue {
-		urls, count := sortedKeys(mUrls)
+	for _, issue := range sorted {
+		urls, count := sortedKeys(byIssue[issue])
 
 		urlsStr := `[
 				` + jsonMarshaled(urls[0])
@@ -135,8 +235,21 @@ func (j JSON) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error
 			"issue": %s
 			"type": "%s",
 			"count": %d,
-			"urls": %s
-		}`, issue, profileTypes[issue], count, urlsStr)
+			"urls": %s`, issue, profileTypes[issue], count, urlsStr)
+		if err != nil {
+			return err
+		}
+
+		if len(metadata[issue]) > 0 {
+			_, err = fmt.Fprintf(j.writer, `,
+			"metadata": %s`, jsonMarshaledMap(metadata[issue]))
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = fmt.Fprint(j.writer, `
+		}`)
 		if err != nil {
 			return err
 		}
@@ -362,6 +475,7 @@ func (j JSON) WriteErrors(ctx context.Context, fs scan.FileSystem) error {
 // WriteMatch writes a [scan.Match] to the [io.Writer] as a JSON object.
 func (j JSON) WriteMatch(_ context.Context, m scan.Match, includeResponse bool) error {
 	_, err := fmt.Fprintf(j.writer, `{
+	"id": %s,
 	"url": %s,
 	"issue": {
 		"name": "%s",
@@ -369,11 +483,52 @@ func (j JSON) WriteMatch(_ context.Context, m scan.Match, includeResponse bool)
 		"confidence": "%s",
 		"param": %s
 	},
-	"type": "%s"`, jsonMarshaled(m.URL), m.IssueName, m.IssueSeverity, m.IssueConfidence, jsonMarshaled(m.IssueParam), m.ProfileType)
+	"type": "%s"`, jsonMarshaled(m.ID), jsonMarshaled(m.URL), m.IssueName, m.IssueSeverity, m.IssueConfidence, jsonMarshaled(m.IssueParam), m.ProfileType)
 	if err != nil {
 		return err
 	}
 
+	if len(m.Metadata) > 0 {
+		_, err = fmt.Fprintf(j.writer, `,
+	"metadata": %s`, jsonMarshaledMap(m.Metadata))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(m.RequestIDs) > 0 {
+		_, err = fmt.Fprintf(j.writer, `,
+	"requestIds": %s`, jsonMarshaledSlice(m.RequestIDs))
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Origin != nil {
+		_, err = fmt.Fprintf(j.writer, `,
+	"origin": {
+		"template": %d,
+		"originTemplate": %d,
+		"insertionPoint": %s,
+		"payload": %s
+	}`, m.Origin.TemplateIdx, m.Origin.OriginIdx, jsonMarshaled(m.Origin.InsertionPoint), jsonMarshaled(m.Payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(m.AffectedEntrypoints) > 0 {
+		if err = j.writeAffectedEntrypoints(m.AffectedEntrypoints, "\t"); err != nil {
+			return err
+		}
+	}
+
+	if m.ResponseDiff != nil {
+		if err = j.writeResponseDiff(m.ResponseDiff, "\t"); err != nil {
+			return err
+		}
+	}
+
 	if m.Requests != nil {
 		_, err = fmt.Fprintf(j.writer, `,
 			"requests": [`)
@@ -481,6 +636,7 @@ func (j JSON) WriteMatches(ctx context.Context, fs scan.FileSystem, includeRespo
 
 		_, err = fmt.Fprintf(j.writer, `
 		{
+			"id": %s,
 			"url": %s,
 			"issue": {
 				"name": "%s",
@@ -488,11 +644,52 @@ func (j JSON) WriteMatches(ctx context.Context, fs scan.FileSystem, includeRespo
 				"confidence": "%s",
 				"param": %s
 			},
-			"type": "%s"`, jsonMarshaled(m.URL), m.IssueName, m.IssueSeverity, m.IssueConfidence, jsonMarshaled(m.IssueParam), m.ProfileType)
+			"type": "%s"`, jsonMarshaled(m.ID), jsonMarshaled(m.URL), m.IssueName, m.IssueSeverity, m.IssueConfidence, jsonMarshaled(m.IssueParam), m.ProfileType)
 		if err != nil {
 			return err
 		}
 
+		if len(m.Metadata) > 0 {
+			_, err = fmt.Fprintf(j.writer, `,
+			"metadata": %s`, jsonMarshaledMap(m.Metadata))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(m.RequestIDs) > 0 {
+			_, err = fmt.Fprintf(j.writer, `,
+			"requestIds": %s`, jsonMarshaledSlice(m.RequestIDs))
+			if err != nil {
+				return err
+			}
+		}
+
+		if m.Origin != nil {
+			_, err = fmt.Fprintf(j.writer, `,
+			"origin": {
+				"template": %d,
+				"originTemplate": %d,
+				"insertionPoint": %s,
+				"payload": %s
+			}`, m.Origin.TemplateIdx, m.Origin.OriginIdx, jsonMarshaled(m.Origin.InsertionPoint), jsonMarshaled(m.Payload))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(m.AffectedEntrypoints) > 0 {
+			if err = j.writeAffectedEntrypoints(m.AffectedEntrypoints, "\t\t\t"); err != nil {
+				return err
+			}
+		}
+
+		if m.ResponseDiff != nil {
+			if err = j.writeResponseDiff(m.ResponseDiff, "\t\t\t"); err != nil {
+				return err
+			}
+		}
+
 		if m.Requests != nil {
 			_, err = fmt.Fprintf(j.writer, `,
 			"requests": [`)
@@ -708,3 +905,158 @@ func jsonMarshaled(s string) string {
 	}
 	return string(b)
 }
+
+func jsonMarshaledMap(m map[string]string) string {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// writeAffectedEntrypoints writes the given [scan.AffectedEntrypoint] instances as the
+// "affectedEntrypoints" JSON array of a finding, indented with the given prefix.
+func (j JSON) writeAffectedEntrypoints(aes []scan.AffectedEntrypoint, indent string) error {
+	_, err := fmt.Fprintf(j.writer, ",\n%s\"affectedEntrypoints\": [", indent)
+	if err != nil {
+		return err
+	}
+
+	for i, ae := range aes {
+		sep := ","
+		if i == len(aes)-1 {
+			sep = ""
+		}
+
+		_, err = fmt.Fprintf(j.writer, `
+%[1]s	{
+%[1]s		"id": %[2]s,
+%[1]s		"insertionPoint": %[3]s,
+%[1]s		"param": %[4]s,
+%[1]s		"payload": %[5]s,
+%[1]s		"requestIds": %[6]s,
+%[1]s		"evidence": %[7]s
+%[1]s	}%[8]s`, indent, jsonMarshaled(ae.ID), jsonMarshaled(ae.InsertionPoint), jsonMarshaled(ae.Param), jsonMarshaled(ae.Payload),
+			jsonMarshaledSlice(nonNil(ae.RequestIDs)), jsonMarshaledSlice(nonNil(ae.Evidence)), sep)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(j.writer, "\n%s]", indent)
+
+	return err
+}
+
+// writeResponseDiff writes the given [scan.ResponseDiff] as the
+// "responseDiff" JSON object of a finding, indented with the given prefix.
+func (j JSON) writeResponseDiff(d *scan.ResponseDiff, indent string) error {
+	_, err := fmt.Fprintf(j.writer, `,
+%[1]s"responseDiff": {
+%[1]s	"statusA": %[2]d,
+%[1]s	"statusB": %[3]d,
+%[1]s	"headersRemoved": %[4]s,
+%[1]s	"headersAdded": %[5]s,
+%[1]s	"body": %[6]s,
+%[1]s	"bodyLines": %[7]s
+%[1]s}`, indent, d.StatusA, d.StatusB, jsonMarshaledSlice(nonNil(d.HeadersRemoved)), jsonMarshaledSlice(nonNil(d.HeadersAdded)),
+		jsonMarshaled(d.BodySummary), jsonMarshaledSlice(nonNil(d.BodyLines)))
+
+	return err
+}
+
+// nonNil returns the given slice, or an empty one if nil, so it's marshaled as [] instead of null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func jsonMarshaledSlice(s []string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "[]"
+	}
+	return string(b)
+}
+
+// jsonResponseStats is the JSON representation of the [scan.Stats.Responses],
+// along with those per host, see [JSON.WriteStats].
+type jsonResponseStats struct {
+	Sizes     jsonDistribution                          `json:"sizes"`
+	Latencies jsonDistribution                          `json:"latencies"`
+	Hosts     map[string]jsonResponseStatsDistributions `json:"hosts,omitempty"`
+}
+
+type jsonResponseStatsDistributions struct {
+	Sizes     jsonDistribution `json:"sizes"`
+	Latencies jsonDistribution `json:"latencies"`
+}
+
+// jsonDistribution is the JSON representation of a [scan.Distribution], with the estimated
+// percentiles (e.g. p90) and the histogram buckets, each with its (upper, inclusive) bound
+// (le), except the last one, for the values above all bounds.
+type jsonDistribution struct {
+	Unit        string             `json:"unit"`
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	Percentiles map[string]float64 `json:"percentiles"`
+	Histogram   []jsonBucket       `json:"histogram"`
+}
+
+type jsonBucket struct {
+	Le    *float64 `json:"le,omitempty"`
+	Count int      `json:"count"`
+}
+
+func jsonResponseStatsFrom(stats *scan.Stats) jsonResponseStats {
+	rs := jsonResponseStats{
+		Sizes:     jsonDistributionFrom(stats.Responses.Sizes, "bytes"),
+		Latencies: jsonDistributionFrom(stats.Responses.Latencies, "ms"),
+	}
+
+	if len(stats.HostsResponses) > 0 {
+		rs.Hosts = make(map[string]jsonResponseStatsDistributions, len(stats.HostsResponses))
+		for host, hrs := range stats.HostsResponses {
+			rs.Hosts[host] = jsonResponseStatsDistributions{
+				Sizes:     jsonDistributionFrom(hrs.Sizes, "bytes"),
+				Latencies: jsonDistributionFrom(hrs.Latencies, "ms"),
+			}
+		}
+	}
+
+	return rs
+}
+
+func jsonDistributionFrom(d *scan.Distribution, unit string) jsonDistribution {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 } //nolint:mnd
+
+	jd := jsonDistribution{
+		Unit:        unit,
+		Count:       d.Count,
+		Min:         round(d.Min),
+		Max:         round(d.Max),
+		Mean:        round(d.Mean()),
+		Percentiles: make(map[string]float64, len(scan.ResponseQuantiles)),
+		Histogram:   make([]jsonBucket, 0, len(d.Buckets)),
+	}
+
+	for _, p := range scan.ResponseQuantiles {
+		if v, ok := d.Quantile(p); ok {
+			jd.Percentiles[percentileName(p)] = round(v)
+		}
+	}
+
+	for i, count := range d.Buckets {
+		bucket := jsonBucket{Count: count}
+		if i < len(d.Bounds) {
+			bucket.Le = &d.Bounds[i]
+		}
+		jd.Histogram = append(jd.Histogram, bucket)
+	}
+
+	return jd
+}
@@ -0,0 +1,73 @@
+package writer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+)
+
+func TestJSON_WriteMatchesSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := newTestFs(t,
+		testMatch("/b", "XSS", "High"),
+		testMatch("/c", "SQLi", "High"),
+		testMatch("/a", "XSS", "High"),
+		testMatch("/d", "XSS", "Medium"),
+	)
+
+	buf := bytes.NewBufferString(`{"matches": []`)
+	require.NoError(t, writer.NewJSON(buf).WriteMatchesSummary(ctx, fs))
+	buf.WriteString(`}`)
+
+	var written mergedOutput
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &written), buf.String())
+
+	require.Len(t, written.Summary, 3)
+	assert.Equal(t, "SQLi", written.Summary[0].Issue.Name)
+	assert.Equal(t, "XSS", written.Summary[1].Issue.Name)
+	assert.Equal(t, []string{"https://example.org/a", "https://example.org/b"}, written.Summary[1].URLs)
+	assert.Equal(t, "XSS", written.Summary[2].Issue.Name)
+	assert.Equal(t, []string{"https://example.org/d"}, written.Summary[2].URLs)
+}
+
+func testMatch(path, issue, severity string) scan.Match {
+	m := scan.Match{
+		URL:             "https://example.org" + path,
+		ProfileName:     issue,
+		ProfileType:     "active",
+		IssueName:       issue,
+		IssueSeverity:   severity,
+		IssueConfidence: "Firm",
+		IssueDetail:     "Found " + issue,
+	}
+	m.ID = scan.MatchID(m)
+
+	return m
+}
+
+func newTestFs(t *testing.T, matches ...scan.Match) scan.FileSystem {
+	t.Helper()
+
+	ctx := context.Background()
+
+	fs, err := filesystem.New(afero.NewMemMapFs(), "/gbounty")
+	require.NoError(t, err)
+
+	require.NoError(t, fs.StoreStats(ctx, scan.NewStats()))
+	for _, m := range matches {
+		require.NoError(t, fs.StoreMatch(ctx, m))
+	}
+
+	return fs
+}
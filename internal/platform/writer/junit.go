@@ -0,0 +1,319 @@
+package writer
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// JUnit must implement the [scan.Writer] interface.
+var _ scan.Writer = &JUnit{}
+
+// JUnit is a [scan.Writer] implementation that writes the output to the given
+// [io.Writer] as a JUnit XML report, so the results can be rendered by CI test
+// dashboards, alongside unit tests.
+//
+// There is one test suite per profile (see [scan.Config.Profiles]), along with those
+// the findings belong to (e.g. built-in ones), and one test case per target (URL) within
+// each of them. Those targets with findings for a profile are failures, with the evidence
+// as the failure contents, while the clean ones are passes. The scan summary (i.e. the
+// [scan.Stats]) is set as the properties of each test suite.
+//
+// The whole report is written at once, with the findings summary (see
+// [JUnit.WriteMatchesSummary]). So, the failed requests, and the requests and
+// responses summaries (see [scan.Config.ShowAll]) are not written.
+type JUnit struct {
+	writer io.Writer
+	cfg    scan.Config
+}
+
+// NewJUnit creates a new instance of [JUnit] with the given [io.Writer] and [scan.Config],
+// given in advance because [JUnit.WriteConfig] is not called in silent mode.
+func NewJUnit(writer io.Writer, cfg scan.Config) *JUnit {
+	return &JUnit{writer: writer, cfg: cfg}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",cdata"`
+}
+
+// WriteConfig keeps the [scan.Config], so the profiles are known (see [JUnit]).
+// Nothing is written to the [io.Writer].
+func (j *JUnit) WriteConfig(_ context.Context, cfg scan.Config) error {
+	j.cfg = cfg
+	return nil
+}
+
+// WriteStats does nothing, as the [scan.Stats] are written as the properties
+// of each test suite (see [JUnit.WriteMatchesSummary]).
+func (j *JUnit) WriteStats(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatchesSummary writes the whole JUnit XML report, built from the [scan.Stats],
+// the targets (i.e. the [scan.Template] instances) and the [scan.Match] instances found.
+func (j *JUnit) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error {
+	stats, err := fs.LoadStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Findings by profile, and by target (URL).
+	findings := make(map[string]map[string][]scan.Match)
+	targets := make(map[string]struct{})
+
+	for m := range ch {
+		if _, ok := findings[m.ProfileName]; !ok {
+			findings[m.ProfileName] = make(map[string][]scan.Match)
+		}
+		findings[m.ProfileName][m.URL] = append(findings[m.ProfileName][m.URL], m)
+		targets[m.URL] = struct{}{}
+	}
+
+	closeIt()
+
+	tplCh, err := fs.TemplatesIterator(ctx)
+	if err != nil {
+		return err
+	}
+
+	for tpl := range tplCh {
+		url := tpl.OriginalURL
+		if len(url) == 0 {
+			url = tpl.URL
+		}
+		targets[url] = struct{}{}
+	}
+
+	report := junitReport(j.cfg, stats, findings, sortedStrings(targets))
+
+	if _, err := io.WriteString(j.writer, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(j.writer)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(j.writer, "\n")
+
+	return err
+}
+
+// junitReport builds the JUnit XML report (see [JUnit]) from the given details.
+func junitReport(cfg scan.Config, stats *scan.Stats, findings map[string]map[string][]scan.Match, targets []string) junitTestSuites {
+	duration := time.Since(stats.StartedAt)
+	seconds := junitSeconds(duration)
+
+	profiles := slices.Clone(cfg.Profiles)
+	for name := range findings {
+		if !slices.Contains(profiles, name) {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles)
+
+	properties := []junitProperty{
+		{Name: "version", Value: cfg.Version},
+		{Name: "started_at", Value: stats.StartedAt.UTC().Format(time.RFC3339)},
+		{Name: "duration", Value: duration.Round(time.Millisecond).String()},
+		{Name: "total_requests", Value: strconv.Itoa(stats.NumOfTotalRequests)},
+		{Name: "performed_requests", Value: strconv.Itoa(stats.NumOfPerformedRequests)},
+		{Name: "succeed_requests", Value: strconv.Itoa(stats.NumOfSucceedRequests)},
+		{Name: "failed_requests", Value: strconv.Itoa(stats.NumOfFailedRequests)},
+		{Name: "skipped_requests", Value: strconv.Itoa(stats.NumOfSkippedRequests)},
+		{Name: "entrypoints", Value: strconv.Itoa(stats.NumOfEntrypoints)},
+		{Name: "matches", Value: strconv.Itoa(stats.NumOfMatches)},
+	}
+
+	if stats.NumOfWarmupRequests > 0 {
+		properties = append(properties,
+			junitProperty{Name: "warmup_requests", Value: strconv.Itoa(stats.NumOfWarmupRequests)},
+			junitProperty{Name: "failed_warmup_requests", Value: strconv.Itoa(stats.NumOfFailedWarmupRequests)},
+		)
+	}
+
+	report := junitTestSuites{Name: "gbounty", Time: seconds, Suites: make([]junitTestSuite, 0, len(profiles))}
+
+	for _, prof := range profiles {
+		suite := junitTestSuite{
+			Name:       prof,
+			Time:       seconds,
+			Timestamp:  stats.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+			Properties: properties,
+			Cases:      make([]junitTestCase, 0, len(targets)),
+		}
+
+		for _, target := range targets {
+			tc := junitTestCase{Name: target, ClassName: prof, Time: "0"}
+			if matches := findings[prof][target]; len(matches) > 0 {
+				tc.Failure = junitFailureOf(matches, cfg.ShowResponses)
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return report
+}
+
+// junitFailureOf returns the failure of a test case, with the given findings (of the same
+// profile and target) as evidence: a summary as message, and their details as contents,
+// including the responses only if includeResponses is true.
+func junitFailureOf(matches []scan.Match, includeResponses bool) *junitFailure {
+	issues := make([]string, 0, len(matches))
+	var contents strings.Builder
+
+	for i, m := range matches {
+		issue := fmt.Sprintf("%s (%s)", m.IssueName, m.IssueSeverity)
+		if !slices.Contains(issues, issue) {
+			issues = append(issues, issue)
+		}
+
+		if i > 0 {
+			contents.WriteString("\n")
+		}
+		junitEvidence(&contents, m, includeResponses)
+	}
+
+	return &junitFailure{
+		Message:  fmt.Sprintf("%d finding(s): %s", len(matches), strings.Join(issues, ", ")),
+		Type:     matches[0].IssueSeverity,
+		Contents: contents.String(),
+	}
+}
+
+// junitEvidence writes the details of the given finding, as evidence, into the given builder.
+func junitEvidence(b *strings.Builder, m scan.Match, includeResponses bool) {
+	fmt.Fprintf(b, "Finding: %s\n", m.ID)
+	fmt.Fprintf(b, "Issue: %s\n", m.IssueName)
+	fmt.Fprintf(b, "Severity: %s\n", m.IssueSeverity)
+	fmt.Fprintf(b, "Confidence: %s\n", m.IssueConfidence)
+
+	if len(m.IssueParam) > 0 {
+		fmt.Fprintf(b, "Param: %s\n", m.IssueParam)
+	}
+
+	if len(m.Payload) > 0 {
+		fmt.Fprintf(b, "Payload: %s\n", m.Payload)
+	}
+
+	for _, key := range sortedStrings(m.Metadata) {
+		fmt.Fprintf(b, "Metadata: %s=%s\n", key, m.Metadata[key])
+	}
+
+	if len(m.IssueDetail) > 0 {
+		fmt.Fprintf(b, "Detail: %s\n", m.IssueDetail)
+	}
+
+	for i, req := range m.Requests {
+		if req == nil {
+			continue
+		}
+
+		fmt.Fprintf(b, "\nRequest:\n%s\n", strings.TrimRight(string(req.Bytes()), "\n"))
+
+		if includeResponses && i < len(m.Responses) && m.Responses[i] != nil {
+			fmt.Fprintf(b, "\nResponse:\n%s\n", strings.TrimRight(string(m.Responses[i].Bytes()), "\n"))
+		}
+	}
+}
+
+// junitSeconds returns the given duration in seconds, as expected by JUnit (e.g. 1.234).
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// sortedStrings returns the keys of the given map, sorted.
+func sortedStrings[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteError does nothing, see [JUnit].
+func (j *JUnit) WriteError(context.Context, scan.Error) error {
+	return nil
+}
+
+// WriteErrors does nothing, see [JUnit].
+func (j *JUnit) WriteErrors(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatch does nothing, as the findings are written as part of
+// the report (see [JUnit.WriteMatchesSummary]).
+func (j *JUnit) WriteMatch(context.Context, scan.Match, bool) error {
+	return nil
+}
+
+// WriteMatches does nothing, as the findings are written as part of
+// the report (see [JUnit.WriteMatchesSummary]).
+func (j *JUnit) WriteMatches(context.Context, scan.FileSystem, bool) error {
+	return nil
+}
+
+// WriteTasks does nothing, see [JUnit].
+func (j *JUnit) WriteTasks(context.Context, scan.FileSystem, bool, bool) error {
+	return nil
+}
@@ -0,0 +1,145 @@
+package writer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func TestJUnit_WriteMatchesSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	withRequest := testMatch("/a", "XSS", "High")
+	req := request.Default("https://example.org/a")
+	withRequest.Requests = []*request.Request{&req}
+
+	fs := newTestFs(t,
+		withRequest,
+		testMatch("/b", "XSS", "High"),
+		testMatch("/a", "SQLi", "Medium"),
+	)
+
+	// A target without findings (i.e. passes on every test suite).
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, request.Default("https://example.org/c"), nil)))
+
+	stats := scan.NewStats()
+	stats.NumOfTotalRequests = 10
+	stats.NumOfFailedRequests = 1
+	stats.NumOfMatches = 3
+	require.NoError(t, fs.StoreStats(ctx, stats))
+
+	buf := new(bytes.Buffer)
+	w := writer.NewJUnit(buf, scan.Config{Version: "v1.0.0", Profiles: []string{"XSS", "Clean"}})
+	require.NoError(t, w.WriteMatchesSummary(ctx, fs))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var report junitSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report), buf.String())
+
+	// Test suites are sorted by profile, including those only found (SQLi),
+	// and test cases by target, with failures on those with findings.
+	assert.Equal(t, "gbounty", report.Name)
+	assert.Equal(t, 9, report.Tests)
+	assert.Equal(t, 3, report.Failures)
+	require.Len(t, report.Suites, 3)
+
+	expected := []struct {
+		name     string
+		failures map[string]string
+	}{
+		{name: "Clean"},
+		{name: "SQLi", failures: map[string]string{"https://example.org/a": "1 finding(s): SQLi (Medium)"}},
+		{name: "XSS", failures: map[string]string{
+			"https://example.org/a": "1 finding(s): XSS (High)",
+			"https://example.org/b": "1 finding(s): XSS (High)",
+		}},
+	}
+
+	for i, exp := range expected {
+		suite := report.Suites[i]
+		assert.Equal(t, exp.name, suite.Name)
+		assert.Equal(t, 3, suite.Tests)
+		assert.Equal(t, len(exp.failures), suite.Failures)
+
+		require.Len(t, suite.Cases, 3)
+		for j, target := range []string{"https://example.org/a", "https://example.org/b", "https://example.org/c"} {
+			tc := suite.Cases[j]
+			assert.Equal(t, target, tc.Name)
+			assert.Equal(t, exp.name, tc.ClassName)
+
+			msg, failed := exp.failures[target]
+			if !failed {
+				assert.Nil(t, tc.Failure, target)
+				continue
+			}
+
+			require.NotNil(t, tc.Failure, target)
+			assert.Equal(t, msg, tc.Failure.Message)
+		}
+	}
+
+	// The evidence is the failure contents, along with the request (if any).
+	xss := report.Suites[2].Cases[0].Failure
+	assert.Equal(t, "High", xss.Type)
+	assert.Contains(t, xss.Contents, "Finding: "+withRequest.ID+"\n")
+	assert.Contains(t, xss.Contents, "Detail: Found XSS\n")
+	assert.Contains(t, xss.Contents, "\nRequest:\nGET /a HTTP/1.1")
+
+	// The scan summary is set as the properties of each test suite.
+	properties := make(map[string]string)
+	for _, p := range report.Suites[0].Properties {
+		properties[p.Name] = p.Value
+	}
+
+	assert.Equal(t, "v1.0.0", properties["version"])
+	assert.Equal(t, "10", properties["total_requests"])
+	assert.Equal(t, "1", properties["failed_requests"])
+	assert.Equal(t, "3", properties["matches"])
+	assert.Contains(t, properties, "started_at")
+	assert.NotContains(t, properties, "warmup_requests")
+	assert.Equal(t, report.Suites[0].Properties, report.Suites[2].Properties)
+}
@@ -36,11 +36,23 @@ func (md Markdown) WriteConfig(_ context.Context, cfg scan.Config) error {
 	builder.WriteString(fmt.Sprintf("**Save on stop:** %v\n\n", cfg.SaveOnStop))
 	builder.WriteString(fmt.Sprintf("**Memory-only:** %v\n\n", cfg.InMemory))
 
+	if cfg.Passive {
+		builder.WriteString("**Mode:** passive (no payloads injected)\n\n")
+	}
+
+	if len(cfg.ResolveAllTo) > 0 {
+		builder.WriteString(fmt.Sprintf("**Sinkhole:** %s (every host resolved to it)\n\n", cfg.ResolveAllTo))
+	}
+
 	if len(cfg.BlindHost) > 0 {
 		builder.WriteString(fmt.Sprintf("**Blind host:** %v\n\n", cfg.BlindHost))
 		builder.WriteString(fmt.Sprintf("**Blind host key:** %v\n\n", cfg.BlindHostKey))
 	}
 
+	if len(cfg.Metadata) > 0 {
+		builder.WriteString(fmt.Sprintf("**Metadata:** %s\n\n", metadataString(cfg.Metadata)))
+	}
+
 	_, err := fmt.Fprintln(md.writer, builder.String())
 
 	return err
@@ -65,7 +77,37 @@ func (md Markdown) WriteStats(ctx context.Context, fs scan.FileSystem) error {
 	builder.WriteString(fmt.Sprintf("**Insertion point(s) found:** %d\n\n", stats.NumOfEntrypoints))
 	builder.WriteString(fmt.Sprintf("**Request(s) finished:** %d\n\n", stats.NumOfPerformedRequests))
 	builder.WriteString(fmt.Sprintf("**Request(s) failed:** %d\n\n", stats.NumOfFailedRequests))
-	builder.WriteString(fmt.Sprintf("**Match(es) found:** %d\n\n", stats.NumOfMatches))
+	if stats.NumOfWarmupRequests > 0 {
+		builder.WriteString(fmt.Sprintf("**Warmup request(s):** %d (%d failed), not included above\n\n", stats.NumOfWarmupRequests, stats.NumOfFailedWarmupRequests))
+	}
+	if stats.NumOfSkippedBodies > 0 {
+		builder.WriteString(fmt.Sprintf("**Response body(ies) skipped:** %d\n\n", stats.NumOfSkippedBodies))
+	}
+	if stats.NumOfFilteredResponses > 0 {
+		builder.WriteString(fmt.Sprintf("**Response(s) filtered:** %d\n\n", stats.NumOfFilteredResponses))
+	}
+	if stats.NumOfMatcherTimeouts > 0 {
+		builder.WriteString(fmt.Sprintf("**Matcher(s) timed out:** %d\n\n", stats.NumOfMatcherTimeouts))
+	}
+	if stats.NumOfDroppedByURLMatch > 0 || stats.NumOfDroppedByURLReject > 0 || stats.NumOfDroppedByExtension > 0 {
+		builder.WriteString(fmt.Sprintf("**Input(s) dropped:** %d (--url-match), %d (--url-reject), %d (--exclude-extensions)\n\n", stats.NumOfDroppedByURLMatch, stats.NumOfDroppedByURLReject, stats.NumOfDroppedByExtension))
+	}
+	if stats.NumOfSkippedTemplates > 0 {
+		builder.WriteString(fmt.Sprintf("**Template(s) skipped:** %d (--skip-if)\n\n", stats.NumOfSkippedTemplates))
+	}
+	if stats.NumOfFilteredTemplates > 0 {
+		builder.WriteString(fmt.Sprintf("**Template(s) filtered:** %d (--template-filter)\n\n", stats.NumOfFilteredTemplates))
+	}
+	if stats.NumOfSkippedHosts > 0 {
+		builder.WriteString(fmt.Sprintf("**Host(s) skipped as already seen:** %d (--only-new-hosts)\n\n", stats.NumOfSkippedHosts))
+	}
+	if len(stats.HostsTimeSpent) > 0 {
+		builder.WriteString(fmt.Sprintf("**Time spent per host:** %s\n\n", hostsTimeSpentString(stats.HostsTimeSpent)))
+	}
+	for _, line := range responseStatsLines(stats) {
+		builder.WriteString(fmt.Sprintf("**%s:** %s\n\n", line[0], line[1]))
+	}
+	builder.WriteString(fmt.Sprintf("**Match(es) found:** %s\n\n", matchesFoundString(stats)))
 	builder.WriteString(fmt.Sprintf("**Elapsed time:** %s\n\n", scanDuration))
 
 	_, err = fmt.Fprint(md.writer, builder.String())
@@ -89,6 +131,7 @@ func (md Markdown) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem)
 	}
 
 	profileTypes := make(map[string]string)
+	metadata := make(map[string]map[string]string)
 	byIssue := make(map[string]map[string]struct{ count int })
 
 	for match := range ch {
@@ -101,6 +144,7 @@ func (md Markdown) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem)
 		)
 
 		profileTypes[issue] = match.ProfileType
+		metadata[issue] = match.Metadata
 
 		if _, ok := byIssue[issue]; !ok {
 			byIssue[issue] = map[string]struct{ count int }{match.URL: {count: 1}}
@@ -126,6 +170,10 @@ func (md Markdown) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem)
 
 		builder.WriteString(fmt.Sprintf("**URL(s):** %s\n\n", urlsStr))
 
+		if len(metadata[issue]) > 0 {
+			builder.WriteString(fmt.Sprintf("**Metadata:** %s\n\n", metadataString(metadata[issue])))
+		}
+
 		_, err := fmt.Fprint(md.writer, builder.String())
 		if err != nil {
 			return err
@@ -253,8 +301,27 @@ func (md Markdown) WriteMatch(_ context.Context, m scan.Match, includeResponse b
 		builder.WriteString(fmt.Sprintf("**Param:** %s\n\n", m.IssueParam))
 	}
 
+	if len(m.ID) > 0 {
+		builder.WriteString(fmt.Sprintf("**Finding ID:** %s\n\n", m.ID))
+	}
+
 	builder.WriteString(fmt.Sprintf("**Type:** %s\n\n", m.ProfileType))
 
+	if len(m.Metadata) > 0 {
+		builder.WriteString(fmt.Sprintf("**Metadata:** %s\n\n", metadataString(m.Metadata)))
+	}
+
+	if len(m.RequestIDs) > 0 {
+		builder.WriteString(fmt.Sprintf("**Request IDs:** %s\n\n", strings.Join(m.RequestIDs, ", ")))
+	}
+
+	if m.Origin != nil {
+		builder.WriteString(fmt.Sprintf("**Origin:** %s\n\n", originString(m.Origin, m.Payload)))
+	}
+
+	builder.WriteString(affectedEntrypointsMarkdown(m.AffectedEntrypoints))
+	builder.WriteString(responseDiffMarkdown(m.ResponseDiff))
+
 	if m.Requests != nil {
 		builder.WriteString("**Requests:**\n\n")
 		for idx, r := range m.Requests {
@@ -313,8 +380,27 @@ func (md Markdown) WriteMatches(ctx context.Context, fs scan.FileSystem, include
 			builder.WriteString(fmt.Sprintf("**Param:** %s\n\n", m.IssueParam))
 		}
 
+		if len(m.ID) > 0 {
+			builder.WriteString(fmt.Sprintf("**Finding ID:** %s\n\n", m.ID))
+		}
+
 		builder.WriteString(fmt.Sprintf("**Type:** %s\n\n", m.ProfileType))
 
+		if len(m.Metadata) > 0 {
+			builder.WriteString(fmt.Sprintf("**Metadata:** %s\n\n", metadataString(m.Metadata)))
+		}
+
+		if len(m.RequestIDs) > 0 {
+			builder.WriteString(fmt.Sprintf("**Request IDs:** %s\n\n", strings.Join(m.RequestIDs, ", ")))
+		}
+
+		if m.Origin != nil {
+			builder.WriteString(fmt.Sprintf("**Origin:** %s\n\n", originString(m.Origin, m.Payload)))
+		}
+
+		builder.WriteString(affectedEntrypointsMarkdown(m.AffectedEntrypoints))
+		builder.WriteString(responseDiffMarkdown(m.ResponseDiff))
+
 		if m.Requests != nil {
 			builder.WriteString("**Requests:**\n\n")
 			for idx, r := range m.Requests {
@@ -410,3 +496,40 @@ func (md Markdown) WriteTasks(ctx context.Context, fs scan.FileSystem, allReques
 
 	return nil
 }
+
+// responseDiffMarkdown returns the given [scan.ResponseDiff] as a Markdown
+// diff code block, or an empty string if there is none (i.e. not diffed).
+func responseDiffMarkdown(d *scan.ResponseDiff) string {
+	if d == nil {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("**Response diff:** %s\n\n", d.Summary()))
+	if lines := d.Lines(); len(lines) > 0 {
+		builder.WriteString("```diff\n")
+		for _, l := range lines {
+			builder.WriteString(l + "\n")
+		}
+		builder.WriteString("```\n\n")
+	}
+
+	return builder.String()
+}
+
+// affectedEntrypointsMarkdown returns the given [scan.AffectedEntrypoint] instances
+// as a Markdown list, or an empty string if there are none (i.e. not grouped).
+func affectedEntrypointsMarkdown(aes []scan.AffectedEntrypoint) string {
+	if len(aes) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("**Affected entrypoints (%d):**\n\n", len(aes)))
+	for _, ae := range aes {
+		builder.WriteString(fmt.Sprintf("- %s\n", affectedEntrypointString(ae)))
+	}
+	builder.WriteString("\n")
+
+	return builder.String()
+}
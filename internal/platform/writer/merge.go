@@ -0,0 +1,482 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrMergeJSON is the error returned when the [JSON] outputs cannot be merged
+// (see [MergeJSON]), usually because the previous one isn't a valid scan output.
+var ErrMergeJSON = errors.New("cannot merge json outputs")
+
+// ErrIncompatibleOutputs is the error returned by [MergeFiles] when the outputs
+// cannot be merged, because any of them isn't a JSON output, or because
+// those come from different versions.
+var ErrIncompatibleOutputs = errors.New("incompatible outputs")
+
+// MergeJSON merges the given [JSON] outputs, from a previous scan and from the
+// latest one, into a single one, so findings can be collected incrementally.
+//
+// The findings (matches) of both are accumulated, deduplicated by their identifier,
+// and so the errors (if any), while the summary is re-calculated from the findings.
+// The rest (e.g. config and results) are those from the latest output.
+func MergeJSON(previous, latest []byte) ([]byte, error) {
+	prev, err := decodeObject(previous)
+	if err != nil {
+		return nil, fmt.Errorf("%w: previous output: %s", ErrMergeJSON, err.Error())
+	}
+
+	last, err := decodeObject(latest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: latest output: %s", ErrMergeJSON, err.Error())
+	}
+
+	merged := last
+	for _, key := range prev.keys {
+		if _, ok := merged.values[key]; !ok {
+			merged.set(key, prev.values[key])
+		}
+	}
+
+	if err := concatArrays(merged, "errors", nil, prev, last); err != nil {
+		return nil, err
+	}
+
+	if err := concatArrays(merged, "matches", matchID, prev, last); err != nil {
+		return nil, err
+	}
+
+	if _, ok := merged.values["matches"]; ok {
+		summary, err := summaryFromMatches(merged.values["matches"])
+		if err != nil {
+			return nil, err
+		}
+		merged.set("summary", summary)
+	}
+
+	return merged.encode()
+}
+
+// MergeFiles merges the [JSON] outputs stored into the files at the given paths, e.g.
+// those from a sharded scan (see [scan.Shard]), into a single one, in the given order.
+//
+// The findings (matches) are accumulated, deduplicated by their (stable) identifier,
+// and so the errors (if any), while the summary is re-calculated from the findings.
+// The results (i.e. the scan stats) are summed up, except the amount of findings,
+// which is that after deduplication, and the response stats, which are left out. The rest (e.g. config) is that from the first one.
+//
+// It returns an error if any of the files isn't a valid scan output, or if those
+// come from different versions (see [ErrIncompatibleOutputs]), as their schemas
+// might differ, so they would be silently merged wrong.
+func MergeFiles(paths ...string) ([]byte, error) {
+	objects := make([]*object, 0, len(paths))
+	version := ""
+	for i, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w(%s): %s", ErrMergeJSON, path, err.Error())
+		}
+
+		o, err := decodeObject(b)
+		if err != nil {
+			return nil, fmt.Errorf("%w(%s): %s", ErrMergeJSON, path, err.Error())
+		}
+
+		if _, ok := o.values["matches"]; !ok {
+			return nil, fmt.Errorf(`%w(%s): no "matches" found, it must be a JSON output (-of json)`, ErrIncompatibleOutputs, path)
+		}
+
+		v := outputVersion(o)
+		if i > 0 && v != version {
+			return nil, fmt.Errorf("%w(%s): version %s differs from %s (%s)", ErrIncompatibleOutputs, path, v, version, paths[0])
+		}
+		version = v
+
+		objects = append(objects, o)
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("%w: no outputs", ErrMergeJSON)
+	}
+
+	merged := &object{values: make(map[string]json.RawMessage)}
+	for _, o := range objects {
+		for _, key := range o.keys {
+			if _, ok := merged.values[key]; !ok {
+				merged.set(key, o.values[key])
+			}
+		}
+	}
+
+	// The baseline comparison (if any) is specific to each scan.
+	merged.remove("baseline")
+
+	if err := concatArrays(merged, "errors", nil, objects...); err != nil {
+		return nil, err
+	}
+
+	if err := concatArrays(merged, "matches", matchID, objects...); err != nil {
+		return nil, err
+	}
+
+	summary, err := summaryFromMatches(merged.values["matches"])
+	if err != nil {
+		return nil, err
+	}
+	merged.set("summary", summary)
+
+	if err := sumResults(merged, objects...); err != nil {
+		return nil, err
+	}
+
+	return merged.encode()
+}
+
+func outputVersion(o *object) string {
+	var cfg struct {
+		Version string `json:"version"`
+	}
+	_ = json.Unmarshal(o.values["config"], &cfg)
+
+	return cfg.Version
+}
+
+func matchID(raw json.RawMessage) string {
+	var m struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &m)
+
+	return m.ID
+}
+
+// concatArrays sets the given key of the merged object to the concatenation of the
+// arrays under the same key from the given objects (those with no such key are skipped),
+// skipping those elements with the same (non-empty) identifier, if an idFn is given.
+// If none of the objects has the key, the merged object is left as is.
+func concatArrays(merged *object, key string, idFn func(json.RawMessage) string, objects ...*object) error {
+	var (
+		found bool
+		items = make([]json.RawMessage, 0)
+		seen  = make(map[string]struct{})
+	)
+
+	for _, o := range objects {
+		raw, ok := o.values[key]
+		if !ok {
+			continue
+		}
+		found = true
+
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrMergeJSON, key, err.Error())
+		}
+
+		for _, elem := range elems {
+			if idFn != nil {
+				if id := idFn(elem); len(id) > 0 {
+					if _, dup := seen[id]; dup {
+						continue
+					}
+					seen[id] = struct{}{}
+				}
+			}
+
+			items = append(items, elem)
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrMergeJSON, key, err.Error())
+	}
+
+	merged.set(key, b)
+
+	return nil
+}
+
+// sumResults sets the results of the merged object to the sum of those from the
+// given objects (see [sumValues]), with the amount of matches of the merged one.
+func sumResults(merged *object, objects ...*object) error {
+	var values []json.RawMessage
+	for _, o := range objects {
+		if raw, ok := o.values["results"]; ok {
+			values = append(values, raw)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	summed, err := sumValues(values)
+	if err != nil {
+		return fmt.Errorf("%w: results: %s", ErrMergeJSON, err.Error())
+	}
+
+	results, err := decodeObject(summed)
+	if err != nil {
+		return fmt.Errorf("%w: results: %s", ErrMergeJSON, err.Error())
+	}
+
+	var matches []json.RawMessage
+	_ = json.Unmarshal(merged.values["matches"], &matches)
+	results.set("matches", json.RawMessage(strconv.Itoa(len(matches))))
+
+	// The response stats (i.e. percentiles and histograms) cannot be summed up,
+	// nor the percentiles re-calculated from those, so these aren't merged.
+	results.remove("responses")
+
+	b, err := results.encode()
+	if err != nil {
+		return err
+	}
+
+	merged.set("results", b)
+
+	return nil
+}
+
+// sumValues returns the sum of the given JSON values: numbers and durations (e.g. "1m5s")
+// are summed up, and objects are summed key by key. Otherwise, the first value is kept.
+func sumValues(values []json.RawMessage) (json.RawMessage, error) {
+	first := values[0]
+
+	switch {
+	case bytes.HasPrefix(bytes.TrimSpace(first), []byte("{")):
+		objects := make([]*object, 0, len(values))
+		for _, v := range values {
+			o, err := decodeObject(v)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, o)
+		}
+
+		summed := &object{values: make(map[string]json.RawMessage)}
+		for _, o := range objects {
+			for _, key := range o.keys {
+				if _, ok := summed.values[key]; ok {
+					continue
+				}
+
+				var byKey []json.RawMessage
+				for _, other := range objects {
+					if v, ok := other.values[key]; ok {
+						byKey = append(byKey, v)
+					}
+				}
+
+				v, err := sumValues(byKey)
+				if err != nil {
+					return nil, err
+				}
+				summed.set(key, v)
+			}
+		}
+
+		return summed.encode()
+	default:
+		var (
+			total    int64
+			duration time.Duration
+		)
+
+		for _, v := range values {
+			var s string
+			if n, err := strconv.ParseInt(string(bytes.TrimSpace(v)), 10, 64); err == nil {
+				total += n
+			} else if json.Unmarshal(v, &s) == nil {
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return first, nil
+				}
+				duration += d
+			} else {
+				return first, nil
+			}
+		}
+
+		if duration > 0 {
+			return json.Marshal(duration.String())
+		}
+
+		return json.RawMessage(strconv.FormatInt(total, 10)), nil
+	}
+}
+
+type summaryIssue struct {
+	Name       string `json:"name"`
+	Severity   string `json:"severity"`
+	Confidence string `json:"confidence"`
+}
+
+// less returns whether the issue goes before the other one in the summary,
+// sorted by name, then by severity and then by confidence.
+func (i summaryIssue) less(other summaryIssue) bool {
+	if i.Name != other.Name {
+		return i.Name < other.Name
+	}
+	if i.Severity != other.Severity {
+		return i.Severity < other.Severity
+	}
+	return i.Confidence < other.Confidence
+}
+
+type summaryEntry struct {
+	Issue    summaryIssue      `json:"issue"`
+	Type     string            `json:"type"`
+	Count    int               `json:"count"`
+	URLs     []string          `json:"urls"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// summaryFromMatches calculates the summary from the given (JSON) matches,
+// like [JSON.WriteMatchesSummary] does, so sorted by issue (see [summaryIssue.less]).
+func summaryFromMatches(raw json.RawMessage) (json.RawMessage, error) {
+	var matches []struct {
+		URL      string            `json:"url"`
+		Issue    summaryIssue      `json:"issue"`
+		Type     string            `json:"type"`
+		Metadata map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(raw, &matches); err != nil {
+		return nil, fmt.Errorf("%w: matches: %s", ErrMergeJSON, err.Error())
+	}
+
+	var (
+		entries []*summaryEntry
+		byIssue = make(map[summaryIssue]*summaryEntry)
+		urls    = make(map[summaryIssue]map[string]struct{})
+	)
+
+	for _, m := range matches {
+		entry, ok := byIssue[m.Issue]
+		if !ok {
+			entry = &summaryEntry{Issue: m.Issue}
+			byIssue[m.Issue] = entry
+			urls[m.Issue] = make(map[string]struct{})
+			entries = append(entries, entry)
+		}
+
+		entry.Type, entry.Metadata = m.Type, m.Metadata
+		entry.Count++
+
+		if _, ok := urls[m.Issue][m.URL]; !ok {
+			urls[m.Issue][m.URL] = struct{}{}
+			entry.URLs = append(entry.URLs, m.URL)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Issue.less(entries[j].Issue) })
+
+	for _, entry := range entries {
+		sort.Strings(entry.URLs)
+	}
+
+	if entries == nil {
+		entries = []*summaryEntry{}
+	}
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: summary: %s", ErrMergeJSON, err.Error())
+	}
+
+	return b, nil
+}
+
+// object is a JSON object that keeps the order of its keys.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func (o *object) set(key string, value json.RawMessage) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) remove(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func decodeObject(b []byte) (*object, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, errors.New("not a json object") //nolint:err113
+	}
+
+	o := &object{values: make(map[string]json.RawMessage)}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token: %v", tok) //nolint:err113
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		o.set(key, value)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *object) encode() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(jsonMarshaled(key) + ":")
+		buf.Write(o.values[key])
+	}
+	buf.WriteString("}")
+
+	indented := new(bytes.Buffer)
+	if err := json.Indent(indented, buf.Bytes(), "", "\t"); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMergeJSON, err.Error())
+	}
+
+	return indented.Bytes(), nil
+}
@@ -0,0 +1,208 @@
+package writer_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+)
+
+type mergedOutput struct {
+	Config struct {
+		Version string `json:"version"`
+	} `json:"config"`
+	Previous int `json:"previous"`
+	Matches  []struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	} `json:"matches"`
+	Errors  []json.RawMessage `json:"errors"`
+	Summary []struct {
+		Issue struct {
+			Name string `json:"name"`
+		} `json:"issue"`
+		Count int      `json:"count"`
+		URLs  []string `json:"urls"`
+	} `json:"summary"`
+}
+
+func TestMergeJSON(t *testing.T) {
+	t.Parallel()
+
+	previous := `{
+	"config": {"version": "1.0.0"},
+	"previous": 1,
+	"matches": [
+		{"id": "a1", "url": "https://example.org/a", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"},
+		{"id": "b2", "url": "https://example.org/b", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"}
+	],
+	"errors": [{"url": "https://example.org/down"}],
+	"summary": []
+}`
+
+	latest := `{
+	"config": {"version": "1.1.0"},
+	"matches": [
+		{"id": "b2", "url": "https://example.org/b", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"},
+		{"id": "c3", "url": "https://example.org/c", "issue": {"name": "SQLi", "severity": "High", "confidence": "Tentative"}, "type": "active"}
+	],
+	"errors": [{"url": "https://example.org/timeout"}],
+	"summary": []
+}`
+
+	b, err := writer.MergeJSON([]byte(previous), []byte(latest))
+	require.NoError(t, err)
+
+	var merged mergedOutput
+	require.NoError(t, json.Unmarshal(b, &merged))
+
+	// The latest config is kept, along with those keys only present in the previous output.
+	assert.Equal(t, "1.1.0", merged.Config.Version)
+	assert.Equal(t, 1, merged.Previous)
+
+	// The matches are deduplicated by their identifier, while errors are accumulated.
+	ids := make([]string, 0, len(merged.Matches))
+	for _, m := range merged.Matches {
+		ids = append(ids, m.ID)
+	}
+	assert.Equal(t, []string{"a1", "b2", "c3"}, ids)
+	assert.Len(t, merged.Errors, 2)
+
+	// The summary is re-calculated from the merged matches.
+	require.Len(t, merged.Summary, 2)
+	counts := make(map[string]int)
+	for _, entry := range merged.Summary {
+		counts[entry.Issue.Name] = entry.Count
+	}
+	assert.Equal(t, map[string]int{"XSS": 2, "SQLi": 1}, counts)
+}
+
+func TestMergeJSON_Invalid(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"matches": []}`
+
+	tcs := map[string]struct {
+		previous string
+		latest   string
+	}{
+		"previous isn't json":  {previous: "[+] Scan finished", latest: valid},
+		"latest isn't json":    {previous: valid, latest: "{"},
+		"matches aren't array": {previous: `{"matches": {}}`, latest: valid},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := writer.MergeJSON([]byte(tc.previous), []byte(tc.latest))
+			require.ErrorIs(t, err, writer.ErrMergeJSON)
+		})
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	t.Parallel()
+
+	first := writeOutput(t, `{
+	"config": {"version": "1.0.0", "shard": "1/2"},
+	"results": {"requests": 10, "duration": "1m", "matches": 2, "responses": {"p50": 12}, "errors": {"total": 1}},
+	"baseline": {"new": 2},
+	"matches": [
+		{"id": "a1", "url": "https://example.org/a", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"},
+		{"id": "b2", "url": "https://example.org/b", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"}
+	],
+	"errors": [{"url": "https://example.org/down"}],
+	"summary": []
+}`)
+
+	second := writeOutput(t, `{
+	"config": {"version": "1.0.0", "shard": "2/2"},
+	"results": {"requests": 5, "duration": "30s", "matches": 2, "errors": {"total": 2}},
+	"matches": [
+		{"id": "b2", "url": "https://example.org/b", "issue": {"name": "XSS", "severity": "High", "confidence": "Firm"}, "type": "active"},
+		{"id": "c3", "url": "https://example.org/c", "issue": {"name": "SQLi", "severity": "High", "confidence": "Tentative"}, "type": "active"}
+	],
+	"summary": []
+}`)
+
+	b, err := writer.MergeFiles(first, second)
+	require.NoError(t, err)
+
+	var merged struct {
+		mergedOutput
+		Config struct {
+			Shard string `json:"shard"`
+		} `json:"config"`
+		Results  map[string]json.RawMessage `json:"results"`
+		Baseline json.RawMessage            `json:"baseline"`
+	}
+	require.NoError(t, json.Unmarshal(b, &merged), string(b))
+
+	// The config is that from the first output, and the baseline comparison is left out.
+	assert.Equal(t, "1/2", merged.Config.Shard)
+	assert.Nil(t, merged.Baseline)
+
+	// The matches are deduplicated, and the errors accumulated.
+	require.Len(t, merged.Matches, 3)
+	assert.Len(t, merged.Errors, 1)
+
+	// The results are summed up, except the amount of matches (after deduplication),
+	// and the response stats, which are left out.
+	assert.JSONEq(t, `15`, string(merged.Results["requests"]))
+	assert.JSONEq(t, `"1m30s"`, string(merged.Results["duration"]))
+	assert.JSONEq(t, `{"total": 3}`, string(merged.Results["errors"]))
+	assert.JSONEq(t, `3`, string(merged.Results["matches"]))
+	assert.NotContains(t, merged.Results, "responses")
+
+	// The summary is re-calculated, sorted by issue.
+	require.Len(t, merged.Summary, 2)
+	assert.Equal(t, "SQLi", merged.Summary[0].Issue.Name)
+	assert.Equal(t, 1, merged.Summary[0].Count)
+	assert.Equal(t, "XSS", merged.Summary[1].Issue.Name)
+	assert.Equal(t, 2, merged.Summary[1].Count)
+	assert.Equal(t, []string{"https://example.org/a", "https://example.org/b"}, merged.Summary[1].URLs)
+}
+
+func TestMergeFiles_Incompatible(t *testing.T) {
+	t.Parallel()
+
+	v1 := writeOutput(t, `{"config": {"version": "1.0.0"}, "matches": []}`)
+	v2 := writeOutput(t, `{"config": {"version": "2.0.0"}, "matches": []}`)
+	text := writeOutput(t, `{"config": {"version": "1.0.0"}}`)
+
+	tcs := map[string]struct {
+		paths []string
+		err   error
+	}{
+		"different versions": {paths: []string{v1, v2}, err: writer.ErrIncompatibleOutputs},
+		"not a json output":  {paths: []string{v1, text}, err: writer.ErrIncompatibleOutputs},
+		"missing file":       {paths: []string{v1, filepath.Join(t.TempDir(), "missing.json")}, err: writer.ErrMergeJSON},
+		"no outputs":         {err: writer.ErrMergeJSON},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := writer.MergeFiles(tc.paths...)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func writeOutput(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "output.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
@@ -0,0 +1,115 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// NDJSON must implement the [scan.Writer] interface.
+var _ scan.Writer = NDJSON{}
+
+// NDJSON is a [scan.Writer] implementation that writes the output to the
+// given [io.Writer] as newline-delimited JSON: one (compact) JSON object per
+// line, for each finding and failed request, with the same shape as [JSON].
+// So, it can be easily streamed and processed line by line (e.g. with jq).
+//
+// The scan configuration, stats, summary and tasks aren't written, as those
+// aren't part of the results stream.
+type NDJSON struct {
+	writer io.Writer
+}
+
+// NewNDJSON creates a new instance of [NDJSON] with the given [io.Writer].
+func NewNDJSON(writer io.Writer) NDJSON {
+	return NDJSON{writer: writer}
+}
+
+// WriteConfig does nothing, see [NDJSON].
+func (n NDJSON) WriteConfig(context.Context, scan.Config) error {
+	return nil
+}
+
+// WriteStats does nothing, see [NDJSON].
+func (n NDJSON) WriteStats(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatchesSummary does nothing, see [NDJSON].
+func (n NDJSON) WriteMatchesSummary(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteError writes a [scan.Error] to the [io.Writer] as a single-line JSON object.
+func (n NDJSON) WriteError(ctx context.Context, scanError scan.Error) error {
+	buf := new(bytes.Buffer)
+	if err := NewJSON(buf).WriteError(ctx, scanError); err != nil {
+		return err
+	}
+
+	return n.writeLine(buf.Bytes())
+}
+
+// WriteErrors writes the [scan.Error] instances to the [io.Writer], one per line.
+func (n NDJSON) WriteErrors(ctx context.Context, fs scan.FileSystem) error {
+	ch, closeIt, err := fs.ErrorsIterator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeIt()
+
+	for scanError := range ch {
+		if err := n.WriteError(ctx, scanError); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteMatch writes a [scan.Match] to the [io.Writer] as a single-line JSON object.
+func (n NDJSON) WriteMatch(ctx context.Context, m scan.Match, includeResponse bool) error {
+	buf := new(bytes.Buffer)
+	if err := NewJSON(buf).WriteMatch(ctx, m, includeResponse); err != nil {
+		return err
+	}
+
+	return n.writeLine(buf.Bytes())
+}
+
+// WriteMatches writes the [scan.Match] instances to the [io.Writer], one per line.
+func (n NDJSON) WriteMatches(ctx context.Context, fs scan.FileSystem, includeResponses bool) error {
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeIt()
+
+	for m := range ch {
+		if err := n.WriteMatch(ctx, m, includeResponses); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTasks does nothing, see [NDJSON].
+func (n NDJSON) WriteTasks(context.Context, scan.FileSystem, bool, bool) error {
+	return nil
+}
+
+func (n NDJSON) writeLine(obj []byte) error {
+	line := new(bytes.Buffer)
+	if err := json.Compact(line, obj); err != nil {
+		return err
+	}
+
+	line.WriteByte('\n')
+	_, err := n.writer.Write(line.Bytes())
+
+	return err
+}
@@ -39,11 +39,23 @@ func (p Plain) WriteConfig(_ context.Context, cfg scan.Config) error {
 	builder.WriteString(fmt.Sprintf("    Memory-only: %v\n", cfg.InMemory))
 	builder.WriteString(fmt.Sprintf("     Blind host: %v\n", cfg.InMemory))
 
+	if cfg.Passive {
+		builder.WriteString("           Mode: passive (no payloads injected)\n")
+	}
+
+	if len(cfg.ResolveAllTo) > 0 {
+		builder.WriteString(fmt.Sprintf("       Sinkhole: %s (every host resolved to it)\n", cfg.ResolveAllTo))
+	}
+
 	if len(cfg.BlindHost) > 0 {
 		builder.WriteString(fmt.Sprintf("     Blind host: %v\n", cfg.BlindHost))
 		builder.WriteString(fmt.Sprintf(" Blind host key: %v\n", cfg.BlindHostKey))
 	}
 
+	if len(cfg.Metadata) > 0 {
+		builder.WriteString(fmt.Sprintf("       Metadata: %s\n", metadataString(cfg.Metadata)))
+	}
+
 	_, err := fmt.Fprintln(p.writer, builder.String())
 
 	return err
@@ -68,7 +80,37 @@ func (p Plain) WriteStats(ctx context.Context, fs scan.FileSystem) error {
 	builder.WriteString(fmt.Sprintf("Insertion point(s) found: %d\n", stats.NumOfEntrypoints))
 	builder.WriteString(fmt.Sprintf("Request(s) finished: %d\n", stats.NumOfPerformedRequests))
 	builder.WriteString(fmt.Sprintf("  Request(s) failed: %d\n", stats.NumOfFailedRequests))
-	builder.WriteString(fmt.Sprintf("    Match(es) found: %d\n", stats.NumOfMatches))
+	if stats.NumOfWarmupRequests > 0 {
+		builder.WriteString(fmt.Sprintf("Warmup request(s): %d (%d failed), not included above\n", stats.NumOfWarmupRequests, stats.NumOfFailedWarmupRequests))
+	}
+	if stats.NumOfSkippedBodies > 0 {
+		builder.WriteString(fmt.Sprintf("  Body(ies) skipped: %d\n", stats.NumOfSkippedBodies))
+	}
+	if stats.NumOfFilteredResponses > 0 {
+		builder.WriteString(fmt.Sprintf("  Response(s) filtered: %d\n", stats.NumOfFilteredResponses))
+	}
+	if stats.NumOfMatcherTimeouts > 0 {
+		builder.WriteString(fmt.Sprintf("  Matcher(s) timed out: %d\n", stats.NumOfMatcherTimeouts))
+	}
+	if stats.NumOfDroppedByURLMatch > 0 || stats.NumOfDroppedByURLReject > 0 || stats.NumOfDroppedByExtension > 0 {
+		builder.WriteString(fmt.Sprintf("  Input(s) dropped: %d (--url-match), %d (--url-reject), %d (--exclude-extensions)\n", stats.NumOfDroppedByURLMatch, stats.NumOfDroppedByURLReject, stats.NumOfDroppedByExtension))
+	}
+	if stats.NumOfSkippedTemplates > 0 {
+		builder.WriteString(fmt.Sprintf("  Template(s) skipped: %d (--skip-if)\n", stats.NumOfSkippedTemplates))
+	}
+	if stats.NumOfFilteredTemplates > 0 {
+		builder.WriteString(fmt.Sprintf("  Template(s) filtered: %d (--template-filter)\n", stats.NumOfFilteredTemplates))
+	}
+	if stats.NumOfSkippedHosts > 0 {
+		builder.WriteString(fmt.Sprintf("  Host(s) skipped as already seen: %d (--only-new-hosts)\n", stats.NumOfSkippedHosts))
+	}
+	if len(stats.HostsTimeSpent) > 0 {
+		builder.WriteString(fmt.Sprintf("  Time spent per host: %s\n", hostsTimeSpentString(stats.HostsTimeSpent)))
+	}
+	for _, line := range responseStatsLines(stats) {
+		builder.WriteString(fmt.Sprintf("  %s: %s\n", line[0], line[1]))
+	}
+	builder.WriteString(fmt.Sprintf("    Match(es) found: %s\n", matchesFoundString(stats)))
 	builder.WriteString(fmt.Sprintf("       Elapsed time: %s\n\n", scanDuration))
 
 	_, err = fmt.Fprint(p.writer, builder.String())
@@ -92,6 +134,7 @@ func (p Plain) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) erro
 	}
 
 	profileTypes := make(map[string]string)
+	metadata := make(map[string]map[string]string)
 	byIssue := make(map[string]map[string]struct{ count int })
 
 	for match := range ch {
@@ -99,6 +142,7 @@ func (p Plain) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) erro
 
 		issue := fmt.Sprintf("%s\n%s\n%s", match.IssueName, match.IssueSeverity, match.IssueConfidence)
 		profileTypes[issue] = match.ProfileType
+		metadata[issue] = match.Metadata
 
 		if _, ok := byIssue[issue]; !ok {
 			byIssue[issue] = map[string]struct{ count int }{match.URL: {count: 1}}
@@ -126,6 +170,11 @@ func (p Plain) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) erro
 		}
 
 		builder.WriteString(printer.Plain(urlsPrinter()).Sprintln(urlsStr))
+
+		if len(metadata[issue]) > 0 {
+			builder.WriteString(printer.Plain(metadataPrinter()).Sprintln(metadataString(metadata[issue])))
+		}
+
 		builder.WriteString("\n")
 
 		_, err := fmt.Fprint(p.writer, builder.String())
@@ -239,6 +288,30 @@ func (p Plain) WriteMatch(_ context.Context, m scan.Match, includeResponse bool)
 		builder.WriteString(printer.Plain(paramPrinter()).Sprintln(m.IssueParam))
 	}
 
+	if len(m.ID) > 0 {
+		builder.WriteString(printer.Plain(idPrinter()).Sprintln(m.ID))
+	}
+
+	if len(m.Metadata) > 0 {
+		builder.WriteString(printer.Plain(metadataPrinter()).Sprintln(metadataString(m.Metadata)))
+	}
+
+	if len(m.RequestIDs) > 0 {
+		builder.WriteString(printer.Plain(requestIDsPrinter()).Sprintln(strings.Join(m.RequestIDs, ", ")))
+	}
+
+	if m.Origin != nil {
+		builder.WriteString(printer.Plain(originPrinter()).Sprintln(originString(m.Origin, m.Payload)))
+	}
+
+	for _, ae := range m.AffectedEntrypoints {
+		builder.WriteString(printer.Plain(affectedPrinter()).Sprintln(affectedEntrypointString(ae)))
+	}
+
+	if m.ResponseDiff != nil {
+		builder.WriteString(printer.Plain(diffPrinter()).Sprintln(responseDiffString(m.ResponseDiff)))
+	}
+
 	if m.Requests != nil {
 		for _, r := range m.Requests {
 			if r == nil {
@@ -288,6 +361,30 @@ func (p Plain) WriteMatches(ctx context.Context, fs scan.FileSystem, includeResp
 			builder.WriteString(printer.Plain(paramPrinter()).Sprintln(m.IssueParam))
 		}
 
+		if len(m.ID) > 0 {
+			builder.WriteString(printer.Plain(idPrinter()).Sprintln(m.ID))
+		}
+
+		if len(m.Metadata) > 0 {
+			builder.WriteString(printer.Plain(metadataPrinter()).Sprintln(metadataString(m.Metadata)))
+		}
+
+		if len(m.RequestIDs) > 0 {
+			builder.WriteString(printer.Plain(requestIDsPrinter()).Sprintln(strings.Join(m.RequestIDs, ", ")))
+		}
+
+		if m.Origin != nil {
+			builder.WriteString(printer.Plain(originPrinter()).Sprintln(originString(m.Origin, m.Payload)))
+		}
+
+		for _, ae := range m.AffectedEntrypoints {
+			builder.WriteString(printer.Plain(affectedPrinter()).Sprintln(affectedEntrypointString(ae)))
+		}
+
+		if m.ResponseDiff != nil {
+			builder.WriteString(printer.Plain(diffPrinter()).Sprintln(responseDiffString(m.ResponseDiff)))
+		}
+
 		if m.Requests != nil {
 			for _, r := range m.Requests {
 				if r == nil {
@@ -87,6 +87,13 @@ func paramPrinter() pterm.PrefixPrinter {
 	}
 }
 
+func idPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: "    ID    "},
+	}
+}
+
 func countPrinter() pterm.PrefixPrinter {
 	return pterm.PrefixPrinter{
 		MessageStyle: color.BoldGreen(),
@@ -114,3 +121,38 @@ func durationPrinter() pterm.PrefixPrinter {
 		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: " DURATION "},
 	}
 }
+
+func metadataPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: " METADATA "},
+	}
+}
+
+func originPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: "  ORIGIN  "},
+	}
+}
+
+func requestIDsPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: " REQ. IDS "},
+	}
+}
+
+func affectedPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: " AFFECTED "},
+	}
+}
+
+func diffPrinter() pterm.PrefixPrinter {
+	return pterm.PrefixPrinter{
+		MessageStyle: color.Gray(),
+		Prefix:       pterm.Prefix{Style: color.BoldYellow(), Text: "   DIFF   "},
+	}
+}
@@ -0,0 +1,260 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+// Template must implement the [scan.Writer] interface.
+var _ scan.Writer = &Template{}
+
+const (
+	// TemplateFinding is the name of the (optional) template executed
+	// once per finding, with a [scan.Match] as data.
+	TemplateFinding = "finding"
+	// TemplateError is the name of the (optional) template executed
+	// once per failed request, with a [scan.Error] as data.
+	TemplateError = "error"
+)
+
+// ErrInvalidTemplate is the error returned when the output template (see [ParseTemplate])
+// cannot be parsed, or when it doesn't define anything to be written.
+var ErrInvalidTemplate = errors.New("invalid output template")
+
+// TemplateReport is the data model the root template (i.e. the contents of the
+// template file outside any {{define}} block) is executed with, once per scan,
+// before the findings. Its fields are:
+//   - Config: the [scan.Config], e.g. {{.Config.Version}}.
+//   - Stats: the [scan.Stats], e.g. {{.Stats.NumOfPerformedRequests}}.
+//   - Duration: the scan duration, already rounded, e.g. {{.Duration}}.
+//   - Matches: all the findings ([scan.Match]), e.g. {{range .Matches}}{{.URL}}{{end}}.
+//
+// The findings are streamed (i.e. read while ranged over, never loaded all at once), so
+// these can only be ranged over once. Use {{.Stats.NumOfMatches}} to get how many there are.
+//
+// Each finding (see [TemplateFinding]) has the [scan.Match] fields, like ID, URL,
+// IssueName, IssueSeverity, IssueConfidence, IssueDetail, IssueParam, ProfileName,
+// ProfileType, Payload, Metadata, RequestIDs, Origin, ResponseDiff, At, Requests and Responses.
+type TemplateReport struct {
+	Config   scan.Config
+	Stats    *scan.Stats
+	Duration time.Duration
+	Matches  <-chan scan.Match
+}
+
+// templateFuncs are the functions available within the output templates,
+// in addition to the Go's [text/template] predefined global functions.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"bytes": func(b interface{ Bytes() []byte }) string { return string(b.Bytes()) },
+}
+
+// ParseTemplate parses the Go [text/template] file at the given path, used to
+// write the output with a custom format (see [Template]), and returns it, or an
+// error if it cannot be parsed, or if it neither has a root template (see
+// [TemplateReport]) nor defines a [TemplateFinding] template.
+func ParseTemplate(path string) (*template.Template, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w(%s): %s", ErrInvalidTemplate, path, err.Error())
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTemplate, err.Error())
+	}
+
+	if !hasRoot(tmpl) && tmpl.Lookup(TemplateFinding) == nil {
+		return nil, fmt.Errorf(`%w(%s): neither has content nor defines a "%s" template`, ErrInvalidTemplate, path, TemplateFinding)
+	}
+
+	return tmpl, nil
+}
+
+// Template is a [scan.Writer] implementation that writes the output
+// to the given [io.Writer], with a custom format defined by a Go [text/template]
+// (see [ParseTemplate]).
+//
+// The root template is executed once, with a [TemplateReport], while the [TemplateFinding]
+// and [TemplateError] ones, if defined, are executed once per finding and per failed request.
+// The requests and responses summaries (see [scan.Config.ShowAll]) are not written.
+type Template struct {
+	writer io.Writer
+	tmpl   *template.Template
+	cfg    scan.Config
+}
+
+// NewTemplate creates a new instance of [Template] with the given [io.Writer]
+// and the given (already parsed, see [ParseTemplate]) [template.Template].
+func NewTemplate(writer io.Writer, tmpl *template.Template) *Template {
+	return &Template{writer: writer, tmpl: tmpl}
+}
+
+// WriteConfig keeps the [scan.Config], so it is available within the [TemplateReport].
+// Nothing is written to the [io.Writer].
+func (t *Template) WriteConfig(_ context.Context, cfg scan.Config) error {
+	t.cfg = cfg
+	return nil
+}
+
+// WriteStats does nothing, as the [scan.Stats] are written as part of the [TemplateReport]
+// (see [Template.WriteMatchesSummary]).
+func (t *Template) WriteStats(context.Context, scan.FileSystem) error {
+	return nil
+}
+
+// WriteMatchesSummary executes the root template with the [TemplateReport],
+// built from the [scan.Stats] and the [scan.Match] instances found during the [scan].
+func (t *Template) WriteMatchesSummary(ctx context.Context, fs scan.FileSystem) error {
+	if !hasRoot(t.tmpl) {
+		return nil
+	}
+
+	stats, err := fs.LoadStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	scanDuration := time.Since(stats.StartedAt)
+	if scanDuration > time.Second {
+		scanDuration = scanDuration.Round(time.Second)
+	} else {
+		scanDuration = scanDuration.Round(time.Millisecond)
+	}
+
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The findings are forwarded while the template ranges over them, if it does. Once executed,
+	// the rest (if any) are drained, so the iterator is never left blocked.
+	done := make(chan struct{})
+	matches := make(chan scan.Match)
+
+	go func() {
+		defer close(matches)
+		for m := range ch {
+			select {
+			case matches <- m:
+			case <-done:
+				for range ch { //nolint:revive
+				}
+				return
+			}
+		}
+	}()
+
+	err = t.tmpl.Execute(t.writer, TemplateReport{
+		Config:   t.cfg,
+		Stats:    stats,
+		Duration: scanDuration,
+		Matches:  matches,
+	})
+
+	closeIt()
+	close(done)
+
+	return err
+}
+
+// WriteError executes the [TemplateError] template, if defined, with the given [scan.Error].
+func (t *Template) WriteError(_ context.Context, scanError scan.Error) error {
+	if t.tmpl.Lookup(TemplateError) == nil {
+		return nil
+	}
+
+	return t.tmpl.ExecuteTemplate(t.writer, TemplateError, scanError)
+}
+
+// WriteErrors executes the [TemplateError] template, if defined, once per
+// [scan.Error] found during the [scan].
+func (t *Template) WriteErrors(ctx context.Context, fs scan.FileSystem) error {
+	if t.tmpl.Lookup(TemplateError) == nil {
+		return nil
+	}
+
+	ch, closeIt, err := fs.ErrorsIterator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeIt()
+
+	for scanError := range ch {
+		if err := t.WriteError(ctx, scanError); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteMatch executes the [TemplateFinding] template, if defined, with the given [scan.Match].
+// The responses are removed from the [scan.Match] unless includeResponse is true.
+func (t *Template) WriteMatch(_ context.Context, m scan.Match, includeResponse bool) error {
+	if t.tmpl.Lookup(TemplateFinding) == nil {
+		return nil
+	}
+
+	if !includeResponse {
+		m.Responses = nil
+	}
+
+	return t.tmpl.ExecuteTemplate(t.writer, TemplateFinding, m)
+}
+
+// WriteMatches executes the [TemplateFinding] template, if defined, once per
+// [scan.Match] found during the [scan].
+func (t *Template) WriteMatches(ctx context.Context, fs scan.FileSystem, includeResponses bool) error {
+	if t.tmpl.Lookup(TemplateFinding) == nil {
+		return nil
+	}
+
+	ch, closeIt, err := fs.MatchesIterator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeIt()
+
+	for m := range ch {
+		if err := t.WriteMatch(ctx, m, includeResponses); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTasks does nothing, as the requests and responses summaries are not
+// available within the output templates.
+func (t *Template) WriteTasks(context.Context, scan.FileSystem, bool, bool) error {
+	return nil
+}
+
+// hasRoot returns whether the given [template.Template] has any content
+// outside the {{define}} blocks, other than spaces.
+func hasRoot(tmpl *template.Template) bool {
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return false
+	}
+
+	for _, node := range tmpl.Tree.Root.Nodes {
+		if text := strings.TrimSpace(node.String()); len(text) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
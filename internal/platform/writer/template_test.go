@@ -0,0 +1,97 @@
+package writer_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/writer"
+)
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		template string
+		expected string
+	}{
+		"root": {
+			template: `# {{.Config.Version}}: {{.Stats.NumOfMatches}} finding(s)
+{{range .Matches}}- [{{.IssueSeverity}}] {{.IssueName}}: {{.URL}}
+{{end}}`,
+			expected: "# 1.2.3: 2 finding(s)\n" +
+				"- [High] XSS: https://example.org/a\n" +
+				"- [Medium] SQLi: https://example.org/b\n",
+		},
+		"root, not ranging over the matches": {
+			template: `# {{.Config.Version}}{{"\n"}}`,
+			expected: "# 1.2.3\n",
+		},
+		"finding": {
+			template: `{{define "finding"}}{{upper .IssueName}} ({{.ID}}): {{.IssueDetail}}{{"\n"}}{{end}}`,
+			expected: "XSS (" + testMatch("/a", "XSS", "High").ID + "): Found XSS\n" +
+				"SQLI (" + testMatch("/b", "SQLi", "Medium").ID + "): Found SQLi\n",
+		},
+		"root and finding": {
+			template: `{{range .Matches}}{{.URL}}{{"\n"}}{{end}}{{define "finding"}}* {{.IssueName}}{{"\n"}}{{end}}`,
+			expected: "https://example.org/a\nhttps://example.org/b\n* XSS\n* SQLi\n",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			path := filepath.Join(t.TempDir(), "report.tmpl")
+			require.NoError(t, os.WriteFile(path, []byte(tc.template), 0o600))
+
+			tmpl, err := writer.ParseTemplate(path)
+			require.NoError(t, err)
+
+			stats := scan.NewStats()
+			stats.NumOfMatches = 2
+
+			fs := newTestFs(t, testMatch("/a", "XSS", "High"), testMatch("/b", "SQLi", "Medium"))
+			require.NoError(t, fs.StoreStats(ctx, stats))
+
+			buf := new(bytes.Buffer)
+			w := writer.NewTemplate(buf, tmpl)
+
+			require.NoError(t, w.WriteConfig(ctx, scan.Config{Version: "1.2.3"}))
+			require.NoError(t, w.WriteStats(ctx, fs))
+			require.NoError(t, w.WriteMatchesSummary(ctx, fs))
+			require.NoError(t, w.WriteMatches(ctx, fs, false))
+
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"syntax error": `{{range .Matches}}`,
+		"nothing":      `{{define "other"}}x{{end}}  `,
+	} {
+		path := filepath.Join(dir, name+".tmpl")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := writer.ParseTemplate(path)
+		require.ErrorIs(t, err, writer.ErrInvalidTemplate, name)
+	}
+
+	_, err := writer.ParseTemplate(filepath.Join(dir, "missing.tmpl"))
+	require.ErrorIs(t, err, writer.ErrInvalidTemplate)
+}
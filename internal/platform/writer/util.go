@@ -1,6 +1,14 @@
 package writer
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
 
 func sortedKeys(m map[string]struct{ count int }) ([]string, int) {
 	total := 0
@@ -12,3 +20,194 @@ func sortedKeys(m map[string]struct{ count int }) ([]string, int) {
 	sort.Strings(keys)
 	return keys, total
 }
+
+// metadataString returns the metadata pairs as a single string,
+// sorted by key, with the form: key1=value1, key2=value2.
+func metadataString(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for k, v := range metadata {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// affectedEntrypointString returns the given [scan.AffectedEntrypoint] as a human-readable
+// string (e.g. insertion point Param URL Value, param id, payload ' OR 1=1, evidence "SQL syntax"),
+// followed by the identifier of the finding it comes from.
+func affectedEntrypointString(ae scan.AffectedEntrypoint) string {
+	var parts []string
+	if len(ae.InsertionPoint) > 0 {
+		parts = append(parts, "insertion point "+ae.InsertionPoint)
+	}
+	if len(ae.Param) > 0 {
+		parts = append(parts, "param "+ae.Param)
+	}
+	if len(ae.Payload) > 0 {
+		parts = append(parts, "payload "+ae.Payload)
+	}
+	if len(ae.Evidence) > 0 {
+		evidence := make([]string, 0, len(ae.Evidence))
+		for _, e := range ae.Evidence {
+			evidence = append(evidence, strconv.Quote(e))
+		}
+		parts = append(parts, "evidence "+strings.Join(evidence, ", "))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no entrypoint")
+	}
+	return fmt.Sprintf("%s (id: %s)", strings.Join(parts, ", "), ae.ID)
+}
+
+// matchesFoundString returns the amount of matches found, along with the amount
+// of grouped findings, if grouped (see [scan.Stats.NumOfGroupedMatches]).
+func matchesFoundString(stats *scan.Stats) string {
+	if stats.NumOfGroupedMatches > 0 {
+		return fmt.Sprintf("%d (grouped into %d)", stats.NumOfMatches, stats.NumOfGroupedMatches)
+	}
+	return strconv.Itoa(stats.NumOfMatches)
+}
+
+// hostsTimeSpentString returns the time spent per host as a single string, sorted
+// by the time spent (longest first), with the form: host1 (1m30s), host2 (45s).
+func hostsTimeSpentString(spent map[string]time.Duration) string {
+	hosts := make([]string, 0, len(spent))
+	for host := range spent {
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if spent[hosts[i]] != spent[hosts[j]] {
+			return spent[hosts[i]] > spent[hosts[j]]
+		}
+		return hosts[i] < hosts[j]
+	})
+
+	parts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		parts = append(parts, fmt.Sprintf("%s (%s)", host, roundDuration(spent[host])))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// roundDuration rounds the given duration to seconds, or to milliseconds if shorter than a second.
+func roundDuration(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// originString returns the given [scan.MatchOrigin] as a human-readable string, along
+// with the given payload, if any (e.g. template 8 (origin 7), insertion point
+// Param URL Value, payload ' OR 1=1).
+func originString(origin *scan.MatchOrigin, payload string) string {
+	parts := []string{fmt.Sprintf("template %d (origin %d)", origin.TemplateIdx, origin.OriginIdx)}
+	if len(origin.InsertionPoint) > 0 {
+		parts = append(parts, "insertion point "+origin.InsertionPoint)
+	}
+	if len(payload) > 0 {
+		parts = append(parts, "payload "+payload)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// responseDiffString returns the given [scan.ResponseDiff] as a human-readable string: its
+// summary (see [scan.ResponseDiff.Summary]), followed by the lines that differ, one per line.
+func responseDiffString(d *scan.ResponseDiff) string {
+	return strings.Join(append([]string{d.Summary()}, d.Lines()...), "\n")
+}
+
+// responseStatsLines returns the (label, value) pairs that summarize the [scan.Stats.Responses]
+// (i.e. the percentiles and histograms of the response sizes and latencies), followed by those
+// per host (sorted), or none if no response was received.
+func responseStatsLines(stats *scan.Stats) [][2]string {
+	if stats.Responses == nil || stats.Responses.Sizes.Count == 0 {
+		return nil
+	}
+
+	lines := [][2]string{
+		{"Response size(s)", distributionString(stats.Responses.Sizes, sizeString)},
+		{"Response size(s) histogram", histogramString(stats.Responses.Sizes, sizeString)},
+		{"Response latency(ies)", distributionString(stats.Responses.Latencies, latencyString)},
+		{"Response latency(ies) histogram", histogramString(stats.Responses.Latencies, latencyString)},
+	}
+
+	// The per-host stats are only worth it when there's more than one host.
+	if len(stats.HostsResponses) < 2 { //nolint:mnd
+		return lines
+	}
+
+	hosts := make([]string, 0, len(stats.HostsResponses))
+	for host := range stats.HostsResponses {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		rs := stats.HostsResponses[host]
+		lines = append(lines, [2]string{
+			"Response(s) from " + host,
+			fmt.Sprintf("size(s) %s; latency(ies) %s", distributionString(rs.Sizes, sizeString), distributionString(rs.Latencies, latencyString)),
+		})
+	}
+
+	return lines
+}
+
+// distributionString returns the given [scan.Distribution] as a single string, with the given
+// formatter for its values, with the form: p50 1.2KB, p90 4.0KB, p99 9.8KB (min 0B, max 1.0MB).
+func distributionString(d *scan.Distribution, format func(float64) string) string {
+	parts := make([]string, 0, len(scan.ResponseQuantiles))
+	for _, p := range scan.ResponseQuantiles {
+		if v, ok := d.Quantile(p); ok {
+			parts = append(parts, fmt.Sprintf("%s %s", percentileName(p), format(v)))
+		}
+	}
+
+	return fmt.Sprintf("%s (min %s, max %s, count %d)", strings.Join(parts, ", "), format(d.Min), format(d.Max), d.Count)
+}
+
+// histogramString returns the non-empty buckets of the histogram of the given [scan.Distribution]
+// as a single string, with the given formatter for its bounds, with the form: <=128B 3, >1.0MB 1.
+func histogramString(d *scan.Distribution, format func(float64) string) string {
+	parts := make([]string, 0, len(d.Buckets))
+	for i, count := range d.Buckets {
+		if count == 0 {
+			continue
+		}
+
+		if i < len(d.Bounds) {
+			parts = append(parts, fmt.Sprintf("<=%s %d", format(d.Bounds[i]), count))
+		} else {
+			parts = append(parts, fmt.Sprintf(">%s %d", format(d.Bounds[len(d.Bounds)-1]), count))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// percentileName returns the name of the given quantile as a percentile, e.g. p90 for 0.9.
+func percentileName(p float64) string {
+	return "p" + strconv.FormatFloat(p*100, 'f', -1, 64) //nolint:mnd
+}
+
+// sizeString returns the given size (in bytes) as a human-readable string, e.g. 1.2KB.
+func sizeString(b float64) string {
+	const unit = 1024
+
+	switch {
+	case b < unit:
+		return fmt.Sprintf("%.0fB", b)
+	case b < unit*unit:
+		return fmt.Sprintf("%.1fKB", b/unit)
+	default:
+		return fmt.Sprintf("%.1fMB", b/(unit*unit))
+	}
+}
+
+// latencyString returns the given latency (in milliseconds) as a human-readable string, e.g. 120ms.
+func latencyString(ms float64) string {
+	return roundDuration(time.Duration(ms * float64(time.Millisecond))).String()
+}
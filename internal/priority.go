@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"container/heap"
+	"context"
+
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/panics"
+)
+
+// prioritize takes a channel of [Template] (e.g. [FileSystemTemplates.TemplatesIterator])
+// and returns another channel that yields the same templates, but ordered by their
+// [Template.Priority], from higher to lower.
+//
+// Templates with equal priority are yielded in the same order they were received,
+// so the ordering is stable, and deterministic across different executions (e.g. resume).
+//
+// Note that, in order to determine the order, all the templates need to be received first,
+// so it's only used when enabled (see [Config.Prioritized]).
+func prioritize(ctx context.Context, in chan Template) chan Template {
+	out := make(chan Template)
+
+	go func() {
+		defer panics.Log(ctx)
+		defer close(out)
+
+		var (
+			pq  = make(templatesQueue, 0)
+			seq int
+		)
+
+		for tpl := range in {
+			heap.Push(&pq, queuedTemplate{tpl: tpl, seq: seq})
+			seq++
+		}
+
+		logger.For(ctx).Debugf("Templates prioritized: %d", len(pq))
+
+		for pq.Len() > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- heap.Pop(&pq).(queuedTemplate).tpl: //nolint:forcetypeassert
+			}
+		}
+	}()
+
+	return out
+}
+
+type queuedTemplate struct {
+	tpl Template
+	seq int
+}
+
+// templatesQueue implements [heap.Interface], as a priority queue
+// of templates, sorted by priority (desc) and arrival order (asc).
+type templatesQueue []queuedTemplate
+
+func (q templatesQueue) Len() int { return len(q) }
+
+func (q templatesQueue) Less(i, j int) bool {
+	if q[i].tpl.Priority != q[j].tpl.Priority {
+		return q[i].tpl.Priority > q[j].tpl.Priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q templatesQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *templatesQueue) Push(x any) {
+	*q = append(*q, x.(queuedTemplate)) //nolint:forcetypeassert
+}
+
+func (q *templatesQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
@@ -3,19 +3,42 @@ package profile
 import (
 	"errors"
 	"fmt"
+	"net/textproto"
+	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/slices"
+	"github.com/bountysecurity/gbounty/kit/strings/reflection"
 )
 
 var (
+	ErrInvalidGrepFormat    = errors.New("invalid grep format")
 	ErrInvalidGrepEnabled   = errors.New("invalid grep enabled")
 	ErrInvalidGrepOperator  = errors.New("invalid grep operator")
 	ErrInvalidGrepType      = errors.New("invalid grep type")
 	ErrInvalidGrepOption    = errors.New("invalid grep option")
+	ErrInvalidRegex         = errors.New("invalid regex")
 	ErrInvalidStatusCode    = errors.New("invalid status code")
 	ErrInvalidTimeDelay     = errors.New("invalid time delay")
 	ErrInvalidContentLength = errors.New("invalid content length")
 	ErrInvalidURLExtension  = errors.New("invalid url extension")
+	ErrInvalidJWTWeakness   = errors.New("invalid jwt weakness")
+	ErrInvalidReflectionCtx = errors.New("invalid reflection context")
+	ErrInvalidRedirectHost  = errors.New("invalid redirect host")
+	ErrInvalidComputedValue = errors.New("invalid computed payload template")
+	ErrInvalidCORSOrigin    = errors.New("invalid cors origin")
+	ErrInvalidExposure      = errors.New("invalid exposure")
+	ErrInvalidSignatureName = errors.New("invalid signature name")
+	ErrInvalidJSONError     = errors.New("invalid json error")
+	ErrInvalidHeaderName    = errors.New("invalid header name")
+	ErrInvalidMismatchCond  = errors.New("invalid content type mismatch condition")
+	ErrInvalidBodyFormat    = errors.New("invalid body format")
+	ErrInvalidSubprotocol   = errors.New("invalid websocket subprotocol")
+	ErrInvalidCookieFlag    = errors.New("invalid cookie flag")
+	ErrInvalidURLScheme     = errors.New("invalid url scheme")
+	ErrInvalidDOMSink       = errors.New("invalid dom sink")
+	ErrInvalidGraphQLTypes  = errors.New("invalid graphql minimum types")
 )
 
 // Grep represents a Grep directive, used to identify matches
@@ -45,6 +68,9 @@ func GrepFromString(s string, rr map[string]string, includeWhere bool) (Grep, er
 	}
 
 	chunks := strings.SplitN(s, ",", nChunks)
+	if len(chunks) < nChunks {
+		return Grep{}, fmt.Errorf("%w: %s", ErrInvalidGrepFormat, s)
+	}
 
 	var (
 		enabled bool
@@ -204,6 +230,27 @@ const (
 	GrepTypeURLExtension      GrepType = "URL Extension"
 	GrepTypePayload           GrepType = "Payload"
 	GrepTypePreEncodedPayload GrepType = "Pre-Encoded Payload"
+	GrepTypeJWTWeakness       GrepType = "JWT Weakness"
+	GrepTypeReflectionContext GrepType = "Reflection Context"
+	GrepTypeOpenRedirect      GrepType = "Open Redirect"
+	GrepTypeComputedPayload   GrepType = "Computed Payload"
+	GrepTypeCORSMisconfig     GrepType = "CORS Misconfiguration"
+	GrepTypeExposedContent    GrepType = "Exposed Content"
+	GrepTypeSensitiveData     GrepType = "Sensitive Data"
+	GrepTypeJSONError         GrepType = "JSON Error"
+	GrepTypeCRLFInjection     GrepType = "CRLF Injection"
+	GrepTypeContentMismatch   GrepType = "Content Type Mismatch"
+	GrepTypeFileRead          GrepType = "File Read"
+	GrepTypeMalformedBody     GrepType = "Malformed Body"
+	GrepTypeTechnology        GrepType = "Technology"
+	GrepTypeWebSocketUpgrade  GrepType = "WebSocket Upgrade"
+	GrepTypeDOMSink           GrepType = "DOM Sink"
+	GrepTypeCloudExposure     GrepType = "Cloud Exposure"
+	GrepTypeDebugExposure     GrepType = "Debug Exposure"
+	GrepTypeGraphQLIntrospect GrepType = "GraphQL Introspection"
+	GrepTypeDeserialization   GrepType = "Deserialization"
+	GrepTypeCookieFlags       GrepType = "Cookie Flags"
+	GrepTypeURLScheme         GrepType = "URL Scheme"
 )
 
 // GrepType represents a Grep type, used to determine the
@@ -270,6 +317,111 @@ func (gt GrepType) PreEncodedPayload() bool {
 	return gt == GrepTypePreEncodedPayload
 }
 
+// JWTWeakness returns whether the GrepType is JWTWeakness.
+func (gt GrepType) JWTWeakness() bool {
+	return gt == GrepTypeJWTWeakness
+}
+
+// ReflectionContext returns whether the GrepType is ReflectionContext.
+func (gt GrepType) ReflectionContext() bool {
+	return gt == GrepTypeReflectionContext
+}
+
+// OpenRedirect returns whether the GrepType is OpenRedirect.
+func (gt GrepType) OpenRedirect() bool {
+	return gt == GrepTypeOpenRedirect
+}
+
+// ComputedPayload returns whether the GrepType is ComputedPayload.
+func (gt GrepType) ComputedPayload() bool {
+	return gt == GrepTypeComputedPayload
+}
+
+// CORSMisconfig returns whether the GrepType is CORSMisconfig.
+func (gt GrepType) CORSMisconfig() bool {
+	return gt == GrepTypeCORSMisconfig
+}
+
+// ExposedContent returns whether the GrepType is ExposedContent.
+func (gt GrepType) ExposedContent() bool {
+	return gt == GrepTypeExposedContent
+}
+
+// SensitiveData returns whether the GrepType is SensitiveData.
+func (gt GrepType) SensitiveData() bool {
+	return gt == GrepTypeSensitiveData
+}
+
+// JSONError returns whether the GrepType is JSONError.
+func (gt GrepType) JSONError() bool {
+	return gt == GrepTypeJSONError
+}
+
+// CRLFInjection returns whether the GrepType is CRLFInjection.
+func (gt GrepType) CRLFInjection() bool {
+	return gt == GrepTypeCRLFInjection
+}
+
+// ContentMismatch returns whether the GrepType is ContentMismatch.
+func (gt GrepType) ContentMismatch() bool {
+	return gt == GrepTypeContentMismatch
+}
+
+// FileRead returns whether the GrepType is FileRead.
+func (gt GrepType) FileRead() bool {
+	return gt == GrepTypeFileRead
+}
+
+// MalformedBody returns whether the GrepType is MalformedBody.
+func (gt GrepType) MalformedBody() bool {
+	return gt == GrepTypeMalformedBody
+}
+
+// Technology returns whether the GrepType is Technology.
+func (gt GrepType) Technology() bool {
+	return gt == GrepTypeTechnology
+}
+
+// WebSocketUpgrade returns whether the GrepType is WebSocketUpgrade.
+func (gt GrepType) WebSocketUpgrade() bool {
+	return gt == GrepTypeWebSocketUpgrade
+}
+
+// DOMSink returns whether the GrepType is DOMSink.
+func (gt GrepType) DOMSink() bool {
+	return gt == GrepTypeDOMSink
+}
+
+// CloudExposure returns whether the GrepType is CloudExposure.
+func (gt GrepType) CloudExposure() bool {
+	return gt == GrepTypeCloudExposure
+}
+
+// DebugExposure returns whether the GrepType is DebugExposure.
+func (gt GrepType) DebugExposure() bool {
+	return gt == GrepTypeDebugExposure
+}
+
+// GraphQLIntrospection returns whether the GrepType is GraphQLIntrospection.
+func (gt GrepType) GraphQLIntrospection() bool {
+	return gt == GrepTypeGraphQLIntrospect
+}
+
+// Deserialization returns whether the GrepType is Deserialization.
+func (gt GrepType) Deserialization() bool {
+	return gt == GrepTypeDeserialization
+}
+
+// CookieFlags returns whether the GrepType is CookieFlags.
+func (gt GrepType) CookieFlags() bool {
+	return gt == GrepTypeCookieFlags
+}
+
+// URLScheme returns whether the GrepType is URLScheme.
+func (gt GrepType) URLScheme() bool {
+	return gt == GrepTypeURLScheme
+}
+
 func parseGrepType(s string) (GrepType, error) {
 	switch GrepType(s) {
 	case GrepTypeSimpleString:
@@ -294,6 +446,48 @@ func parseGrepType(s string) (GrepType, error) {
 		return GrepTypePayload, nil
 	case GrepTypePreEncodedPayload:
 		return GrepTypePreEncodedPayload, nil
+	case GrepTypeJWTWeakness:
+		return GrepTypeJWTWeakness, nil
+	case GrepTypeReflectionContext:
+		return GrepTypeReflectionContext, nil
+	case GrepTypeOpenRedirect:
+		return GrepTypeOpenRedirect, nil
+	case GrepTypeComputedPayload:
+		return GrepTypeComputedPayload, nil
+	case GrepTypeCORSMisconfig:
+		return GrepTypeCORSMisconfig, nil
+	case GrepTypeExposedContent:
+		return GrepTypeExposedContent, nil
+	case GrepTypeSensitiveData:
+		return GrepTypeSensitiveData, nil
+	case GrepTypeJSONError:
+		return GrepTypeJSONError, nil
+	case GrepTypeCRLFInjection:
+		return GrepTypeCRLFInjection, nil
+	case GrepTypeContentMismatch:
+		return GrepTypeContentMismatch, nil
+	case GrepTypeFileRead:
+		return GrepTypeFileRead, nil
+	case GrepTypeMalformedBody:
+		return GrepTypeMalformedBody, nil
+	case GrepTypeTechnology:
+		return GrepTypeTechnology, nil
+	case GrepTypeWebSocketUpgrade:
+		return GrepTypeWebSocketUpgrade, nil
+	case GrepTypeDOMSink:
+		return GrepTypeDOMSink, nil
+	case GrepTypeCloudExposure:
+		return GrepTypeCloudExposure, nil
+	case GrepTypeDebugExposure:
+		return GrepTypeDebugExposure, nil
+	case GrepTypeGraphQLIntrospect:
+		return GrepTypeGraphQLIntrospect, nil
+	case GrepTypeDeserialization:
+		return GrepTypeDeserialization, nil
+	case GrepTypeCookieFlags:
+		return GrepTypeCookieFlags, nil
+	case GrepTypeURLScheme:
+		return GrepTypeURLScheme, nil
 	default:
 		return GrepType(s), fmt.Errorf("%w: %s", ErrInvalidGrepType, s)
 	}
@@ -364,6 +558,15 @@ func (v GrepValue) AsContentLength() int {
 	return int(length)
 }
 
+// AsGraphQLMinTypes returns the GrepValue as the minimum amount of types
+// an introspection result must expose to be reported (see [GrepTypeGraphQLIntrospect]).
+// An empty value means any (i.e. 1).
+func (v GrepValue) AsGraphQLMinTypes() int {
+	// Already checked
+	minTypes, _ := strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+	return max(1, int(minTypes))
+}
+
 // AsPayload returns the GrepValue as a string.
 func (v GrepValue) AsPayload() string {
 	return string(v)
@@ -386,6 +589,371 @@ func (v GrepValue) AsURLExtensions() []string {
 	return extensions
 }
 
+const (
+	// JWTWeaknessNone is used to look for JWTs with the "none" algorithm (i.e. unsigned).
+	JWTWeaknessNone = "none"
+	// JWTWeaknessWeakSecret is used to look for JWTs signed with a well-known (weak) secret.
+	JWTWeaknessWeakSecret = "weak"
+)
+
+// AsJWTWeaknesses returns the GrepValue as a slice of
+// JWT weaknesses (strings) to look for.
+// An empty value means all the known weaknesses.
+func (v GrepValue) AsJWTWeaknesses() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return []string{JWTWeaknessNone, JWTWeaknessWeakSecret}
+	}
+
+	chunks := strings.Split(string(v), ";")
+	weaknesses := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		weaknesses = append(weaknesses, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return weaknesses
+}
+
+// AsReflectionContexts returns the GrepValue as a slice of
+// reflection contexts (see [reflection.Context]) to look for.
+// An empty value means any context.
+func (v GrepValue) AsReflectionContexts() []reflection.Context {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return reflection.Contexts()
+	}
+
+	chunks := strings.Split(string(v), ";")
+	contexts := make([]reflection.Context, 0, len(chunks))
+	for _, c := range chunks {
+		contexts = append(contexts, reflection.Context(strings.ToLower(strings.TrimSpace(c))))
+	}
+
+	return contexts
+}
+
+// AsRedirectHosts returns the GrepValue as a slice of
+// (lowercase) hosts considered as attacker-controlled.
+// An empty value means the host is derived from the payload.
+func (v GrepValue) AsRedirectHosts() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	hosts := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		hosts = append(hosts, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return hosts
+}
+
+// AsHeaderNames returns the GrepValue as a slice of (canonical) header names,
+// expected to be injected (e.g. through CRLF sequences) into the response.
+// An empty value means the header names are derived from the payload.
+func (v GrepValue) AsHeaderNames() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	names := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		names = append(names, textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(c)))
+	}
+
+	return names
+}
+
+// AsCookieFlags returns the GrepValue as a slice of cookie flags (i.e. attribute names,
+// like Secure or HttpOnly), all of which are expected to be set on every cookie.
+func (v GrepValue) AsCookieFlags() []string {
+	chunks := strings.Split(string(v), ";")
+	flags := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		flags = append(flags, strings.TrimSpace(c))
+	}
+
+	return flags
+}
+
+// AsURLSchemes returns the GrepValue as a slice of URL schemes (e.g. https).
+func (v GrepValue) AsURLSchemes() []string {
+	chunks := strings.Split(string(v), ";")
+	schemes := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		schemes = append(schemes, strings.TrimSpace(c))
+	}
+
+	return schemes
+}
+
+// AsWebSocketProtocols returns the GrepValue as a slice of WebSocket subprotocols
+// (e.g. chat or graphql-ws), one of which is expected to be negotiated on the upgrade.
+// An empty value means any subprotocol (or none) is accepted.
+func (v GrepValue) AsWebSocketProtocols() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	protocols := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		protocols = append(protocols, strings.TrimSpace(c))
+	}
+
+	return protocols
+}
+
+// AsDOMSinks returns the GrepValue as a slice of DOM sinks (see [reflection.Sink])
+// the reflections are expected to flow into. An empty value means any sink.
+func (v GrepValue) AsDOMSinks() []reflection.Sink {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return reflection.Sinks()
+	}
+
+	chunks := strings.Split(string(v), ";")
+	sinks := make([]reflection.Sink, 0, len(chunks))
+	for _, c := range chunks {
+		if sink, ok := domSink(c); ok {
+			sinks = append(sinks, sink)
+		}
+	}
+
+	return sinks
+}
+
+// AsCORSOrigins returns the GrepValue as a slice of (lowercase) origins
+// considered as attacker-controlled (e.g. https://evil.example or null).
+// An empty value means the origin is taken from the request's Origin header.
+func (v GrepValue) AsCORSOrigins() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	origins := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		origins = append(origins, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return origins
+}
+
+const (
+	// ExposureListing is used to look for directory listings (e.g. Apache's or nginx's autoindex).
+	ExposureListing = "listing"
+	// ExposureGit is used to look for exposed git repositories (i.e. .git/config).
+	ExposureGit = "git"
+	// ExposureEnv is used to look for exposed dotenv files (i.e. .env).
+	ExposureEnv = "env"
+	// ExposureBackup is used to look for exposed backup files (e.g. *.bak, *.old or *~).
+	ExposureBackup = "backup"
+	// ExposureSwap is used to look for exposed editor swap files (i.e. Vim's *.swp).
+	ExposureSwap = "swap"
+)
+
+// Exposures returns all the known exposures (see [GrepValue.AsExposures]).
+func Exposures() []string {
+	return []string{ExposureListing, ExposureGit, ExposureEnv, ExposureBackup, ExposureSwap}
+}
+
+// AsExposures returns the GrepValue as a slice of
+// exposures (strings) to look for (see [Exposures]).
+// An empty value means all the known exposures.
+func (v GrepValue) AsExposures() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return Exposures()
+	}
+
+	chunks := strings.Split(string(v), ";")
+	exposures := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		exposures = append(exposures, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return exposures
+}
+
+const (
+	// MismatchReflected is used to only consider the mismatches where the payload is reflected within the body.
+	MismatchReflected = "reflected"
+	// MismatchRenderable is used to only consider the mismatches where the body would be rendered as HTML
+	// (e.g. declared as text/html, or sniffed as HTML by the browser, if there's no nosniff).
+	MismatchRenderable = "renderable"
+)
+
+// MismatchConditions returns all the known content type
+// mismatch conditions (see [GrepValue.AsMismatchConditions]).
+func MismatchConditions() []string {
+	return []string{MismatchReflected, MismatchRenderable}
+}
+
+// AsMismatchConditions returns the GrepValue as a slice of the conditions
+// (strings) the content type mismatches must meet (see [MismatchConditions]).
+// An empty value means any mismatch (so, it returns nil).
+func (v GrepValue) AsMismatchConditions() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	conditions := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		conditions = append(conditions, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return conditions
+}
+
+const (
+	// BodyFormatJSON is used to check the well-formedness of the bodies declared as JSON
+	// (e.g. application/json or application/problem+json).
+	BodyFormatJSON = "json"
+	// BodyFormatXML is used to check the well-formedness of the bodies declared as XML
+	// (e.g. application/xml or image/svg+xml).
+	BodyFormatXML = "xml"
+)
+
+// BodyFormats returns all the known body formats (see [GrepValue.AsBodyFormats]).
+func BodyFormats() []string {
+	return []string{BodyFormatJSON, BodyFormatXML}
+}
+
+// AsBodyFormats returns the GrepValue as a slice of the body
+// formats (strings) to check the well-formedness of (see [BodyFormats]).
+// An empty value means all the known formats.
+func (v GrepValue) AsBodyFormats() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return BodyFormats()
+	}
+
+	chunks := strings.Split(string(v), ";")
+	formats := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		formats = append(formats, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return formats
+}
+
+// signatureNameRegex is the format of the signature names (see [IsSignatureName]).
+var signatureNameRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// IsSignatureName returns whether the given string is a valid signature name,
+// used to identify the patterns looked for by the Sensitive Data grep (e.g. aws-access-key),
+// the File Read grep (e.g. etc-passwd), the Technology grep (e.g. nginx), the Cloud Exposure
+// grep (e.g. aws-credentials), the Debug Exposure grep (e.g. go-pprof) and the Deserialization
+// grep (e.g. java), which must be lowercase alphanumeric (plus - and _).
+func IsSignatureName(s string) bool {
+	return signatureNameRegex.MatchString(s)
+}
+
+// AsSignatures returns the GrepValue as a slice of the names of the sensitive data (or file read,
+// technology, cloud or debug exposure, or deserialization) signatures to look for (e.g. aws-access-key or nginx).
+// An empty value means all the known signatures (so, it returns nil).
+func (v GrepValue) AsSignatures() []string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return nil
+	}
+
+	chunks := strings.Split(string(v), ";")
+	names := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		names = append(names, strings.ToLower(strings.TrimSpace(c)))
+	}
+
+	return names
+}
+
+const (
+	// JSONErrorCode is used to look for the given error code (e.g. code=E1001).
+	JSONErrorCode = "code"
+	// JSONErrorMessage is used to look for the given (partial) error message (e.g. message=not found).
+	JSONErrorMessage = "message"
+	// JSONErrorCodePath is used to define where the error code is (e.g. code-path=error.errno).
+	JSONErrorCodePath = "code-path"
+	// JSONErrorMessagePath is used to define where the error message is (e.g. message-path=fault.detail).
+	JSONErrorMessagePath = "message-path"
+)
+
+// JSONError is the error (envelope) looked for by the JSON Error grep, within the
+// JSON response bodies: the error codes and (partial) messages expected, if any,
+// and the (dot-separated) paths where these are, with arrays traversed implicitly.
+type JSONError struct {
+	Codes        []string
+	Messages     []string
+	CodePaths    []string
+	MessagePaths []string
+}
+
+// DefaultJSONErrorCodePaths returns the paths where the error code is
+// usually found, within the common error envelopes (see [GrepValue.AsJSONError]).
+func DefaultJSONErrorCodePaths() []string {
+	return []string{"error.code", "errors.code", "error_code", "errorCode", "fault.code"}
+}
+
+// DefaultJSONErrorMessagePaths returns the paths where the error message is
+// usually found, within the common error envelopes (see [GrepValue.AsJSONError]).
+func DefaultJSONErrorMessagePaths() []string {
+	return []string{
+		"error.message", "errors.message", "errors.detail", "error",
+		"error_description", "errorMessage", "fault.message", "fault.faultstring",
+	}
+}
+
+// AsJSONError returns the GrepValue as the [JSONError] to look for, from
+// terms separated by semicolons (e.g. code=E1001;message=not found).
+// The paths not defined are the defaults (see [DefaultJSONErrorCodePaths]
+// and [DefaultJSONErrorMessagePaths]). An empty value means any error.
+func (v GrepValue) AsJSONError() JSONError {
+	var jsonErr JSONError
+
+	for _, c := range strings.Split(string(v), ";") {
+		key, value, found := strings.Cut(c, "=")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case JSONErrorCode:
+			jsonErr.Codes = append(jsonErr.Codes, value)
+		case JSONErrorMessage:
+			jsonErr.Messages = append(jsonErr.Messages, value)
+		case JSONErrorCodePath:
+			jsonErr.CodePaths = append(jsonErr.CodePaths, value)
+		case JSONErrorMessagePath:
+			jsonErr.MessagePaths = append(jsonErr.MessagePaths, value)
+		}
+	}
+
+	if len(jsonErr.CodePaths) == 0 {
+		jsonErr.CodePaths = DefaultJSONErrorCodePaths()
+	}
+
+	if len(jsonErr.MessagePaths) == 0 {
+		jsonErr.MessagePaths = DefaultJSONErrorMessagePaths()
+	}
+
+	return jsonErr
+}
+
+// ComputedResultLabel is the label replaced by the computed value within
+// the template of a [GrepTypeComputedPayload] grep (see [GrepValue.AsComputedTemplate]).
+const ComputedResultLabel = "{RESULT}"
+
+// AsComputedTemplate returns the GrepValue as the template of the value
+// expected in the response, where the [ComputedResultLabel] is replaced
+// by the result of the expression injected. An empty value means the
+// result alone (i.e. {RESULT}).
+func (v GrepValue) AsComputedTemplate() string {
+	if len(strings.TrimSpace(string(v))) == 0 {
+		return ComputedResultLabel
+	}
+
+	return string(v)
+}
+
 func parseGrepValue(t GrepType, s string, rr map[string]string) (GrepValue, error) {
 	// First, we apply the replacements.
 	for label, value := range rr {
@@ -413,6 +981,48 @@ func parseGrepValue(t GrepType, s string, rr map[string]string) (GrepValue, erro
 		return GrepValue(s), nil
 	case GrepTypePreEncodedPayload:
 		return GrepValue(s), nil
+	case GrepTypeJWTWeakness:
+		return parseJWTWeaknesses(s)
+	case GrepTypeReflectionContext:
+		return parseReflectionContexts(s)
+	case GrepTypeOpenRedirect:
+		return parseRedirectHosts(s)
+	case GrepTypeComputedPayload:
+		return parseComputedTemplate(s)
+	case GrepTypeCORSMisconfig:
+		return parseCORSOrigins(s)
+	case GrepTypeExposedContent:
+		return parseExposures(s)
+	case GrepTypeSensitiveData:
+		return parseSignatureNames(s)
+	case GrepTypeJSONError:
+		return parseJSONError(s)
+	case GrepTypeCRLFInjection:
+		return parseHeaderNames(s)
+	case GrepTypeContentMismatch:
+		return parseMismatchConditions(s)
+	case GrepTypeFileRead:
+		return parseSignatureNames(s)
+	case GrepTypeMalformedBody:
+		return parseBodyFormats(s)
+	case GrepTypeTechnology:
+		return parseSignatureNames(s)
+	case GrepTypeWebSocketUpgrade:
+		return parseWebSocketProtocols(s)
+	case GrepTypeDOMSink:
+		return parseDOMSinks(s)
+	case GrepTypeCloudExposure:
+		return parseSignatureNames(s)
+	case GrepTypeDebugExposure:
+		return parseSignatureNames(s)
+	case GrepTypeGraphQLIntrospect:
+		return parseGraphQLMinTypes(s)
+	case GrepTypeDeserialization:
+		return parseSignatureNames(s)
+	case GrepTypeCookieFlags:
+		return parseCookieFlags(s)
+	case GrepTypeURLScheme:
+		return parseURLSchemes(s)
 	}
 
 	return GrepValue(s), fmt.Errorf("%w: %s", ErrInvalidGrepType, t)
@@ -455,6 +1065,19 @@ func parseContentLength(s string) (GrepValue, error) {
 	return GrepValue(s), nil
 }
 
+func parseGraphQLMinTypes(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	minTypes, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || minTypes < 1 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidGraphQLTypes, s)
+	}
+
+	return GrepValue(s), nil
+}
+
 func parseURLExtensions(s string) (GrepValue, error) {
 	for _, s := range strings.Split(s, ";") {
 		// An extension must start with dot (e.g.; .php)
@@ -466,6 +1089,241 @@ func parseURLExtensions(s string) (GrepValue, error) {
 	return GrepValue(s), nil
 }
 
+func parseJWTWeaknesses(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case JWTWeaknessNone, JWTWeaknessWeakSecret:
+		default:
+			return "", fmt.Errorf("%w: %s", ErrInvalidJWTWeakness, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseReflectionContexts(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if !slices.In(reflection.Contexts(), reflection.Context(strings.ToLower(strings.TrimSpace(s)))) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidReflectionCtx, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseRedirectHosts(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		host := strings.TrimSpace(s)
+		if len(host) == 0 || strings.ContainsAny(host, " /\\") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidRedirectHost, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseHeaderNames(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		name := strings.TrimSpace(s)
+		if len(name) == 0 || strings.ContainsAny(name, " \t\r\n:") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidHeaderName, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseWebSocketProtocols(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		// Subprotocols are tokens (see RFC 6455, section 4.1).
+		protocol := strings.TrimSpace(s)
+		if len(protocol) == 0 || strings.ContainsAny(protocol, " \t\r\n()<>@,;:\\\"/[]?={}") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSubprotocol, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseCookieFlags(s string) (GrepValue, error) {
+	for _, s := range strings.Split(s, ";") {
+		// Flags are cookie attribute names (see RFC 6265, section 4.1.1).
+		flag := strings.TrimSpace(s)
+		if len(flag) == 0 || strings.ContainsAny(flag, " \t\r\n=;,") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidCookieFlag, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseURLSchemes(s string) (GrepValue, error) {
+	for _, s := range strings.Split(s, ";") {
+		scheme := strings.TrimSpace(s)
+		if len(scheme) == 0 || strings.ContainsAny(scheme, " \t\r\n:/") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidURLScheme, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseDOMSinks(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if _, ok := domSink(s); !ok {
+			return "", fmt.Errorf("%w: %s", ErrInvalidDOMSink, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+// domSink returns the [reflection.Sink] with the given name (case-insensitive), if any.
+func domSink(s string) (reflection.Sink, bool) {
+	for _, sink := range reflection.Sinks() {
+		if strings.EqualFold(strings.TrimSpace(s), string(sink)) {
+			return sink, true
+		}
+	}
+
+	return "", false
+}
+
+func parseJSONError(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, c := range strings.Split(s, ";") {
+		key, value, found := strings.Cut(c, "=")
+		value = strings.TrimSpace(value)
+		if !found || len(value) == 0 {
+			return "", fmt.Errorf("%w (must be key=value): %s", ErrInvalidJSONError, c)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case JSONErrorCode, JSONErrorMessage:
+		case JSONErrorCodePath, JSONErrorMessagePath:
+			if slices.In(strings.Split(value, "."), "") {
+				return "", fmt.Errorf("%w (empty path segment): %s", ErrInvalidJSONError, c)
+			}
+		default:
+			return "", fmt.Errorf("%w (unknown key): %s", ErrInvalidJSONError, c)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseComputedTemplate(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) > 0 && strings.Count(s, ComputedResultLabel) != 1 {
+		return "", fmt.Errorf("%w (must contain %s once): %s", ErrInvalidComputedValue, ComputedResultLabel, s)
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseExposures(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if !slices.In(Exposures(), strings.ToLower(strings.TrimSpace(s))) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidExposure, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseMismatchConditions(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if !slices.In(MismatchConditions(), strings.ToLower(strings.TrimSpace(s))) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidMismatchCond, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseBodyFormats(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if !slices.In(BodyFormats(), strings.ToLower(strings.TrimSpace(s))) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidBodyFormat, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseSignatureNames(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		if !IsSignatureName(strings.ToLower(strings.TrimSpace(s))) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSignatureName, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
+func parseCORSOrigins(s string) (GrepValue, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return GrepValue(s), nil
+	}
+
+	for _, s := range strings.Split(s, ";") {
+		origin := strings.TrimSpace(s)
+		if strings.EqualFold(origin, "null") {
+			continue
+		}
+
+		// An origin is just scheme://host[:port], with no path.
+		scheme, host, found := strings.Cut(origin, "://")
+		if !found || len(scheme) == 0 || len(host) == 0 || strings.ContainsAny(host, " /\\?#") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidCORSOrigin, s)
+		}
+	}
+
+	return GrepValue(s), nil
+}
+
 const (
 	GrepOptionNone          GrepOption = ""
 	GrepOptionCaseSensitive GrepOption = "Case sensitive"
@@ -1,5 +1,15 @@
 package profile
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownInsertionPointType is the error returned when the insertion
+// point type cannot be parsed (see [ParseInsertionPointType]).
+var ErrUnknownInsertionPointType = errors.New("unknown insertion point type")
+
 // InsertionPointType represents the type of insertion point.
 type InsertionPointType string
 
@@ -70,6 +80,10 @@ func (i InsertionPointType) String() string {
 		return "Entire Body JSON"
 	case EntireBodyMulti:
 		return "Entire Body Multi"
+	case ParamJWTClaim:
+		return "Param JWT Claim"
+	case ParamEncodedValue:
+		return "Param Encoded Value"
 	default:
 		return unknown
 	}
@@ -107,8 +121,36 @@ const (
 	EntireBody            InsertionPointType = "entire_body"
 	EntireBodyJSON        InsertionPointType = "entire_body_json"
 	EntireBodyMulti       InsertionPointType = "entire_body_multipart"
+	ParamJWTClaim         InsertionPointType = "param_jwt_claim"
+	ParamEncodedValue     InsertionPointType = "param_encoded"
 )
 
+// InsertionPointTypes are all the known insertion point types.
+var InsertionPointTypes = []InsertionPointType{
+	ParamURLValue, ParamBodyValue, CookieValue, ParamXMLValue, ParamXMLAttrValue, ParamMultiAttrValue,
+	ParamJSONValue, CookieName, ParamXMLName, URLPathFolder, ParamURLName, ParamBodyName, EntireBodyXML,
+	URLPathFile, ParamXMLAttrName, ParamMultiAttrName, ParamJSONName, MultiplePathDiscovery,
+	SinglePathDiscovery, HeaderUserAgent, HeaderReferer, HeaderOrigin, HeaderHost, HeaderContentType,
+	HeaderAccept, HeaderAcceptLanguage, HeaderAcceptEncoding, HeaderNew, EntireBody, EntireBodyJSON,
+	EntireBodyMulti, ParamJWTClaim, ParamEncodedValue,
+}
+
+// ParseInsertionPointType parses the given string as an [InsertionPointType], either
+// as its value (e.g. param_url), or as its name (see [InsertionPointType.String]),
+// case-insensitive and with or without spaces (e.g. ParamURLValue).
+func ParseInsertionPointType(s string) (InsertionPointType, error) {
+	s = strings.TrimSpace(s)
+	name := strings.ReplaceAll(s, " ", "")
+
+	for _, ipt := range InsertionPointTypes {
+		if strings.EqualFold(s, string(ipt)) || strings.EqualFold(name, strings.ReplaceAll(ipt.String(), " ", "")) {
+			return ipt, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownInsertionPointType, s)
+}
+
 const (
 	InsertionPointModeAny  InsertionPointMode = "any"
 	InsertionPointModeSame InsertionPointMode = "same"
@@ -10,6 +10,8 @@ var (
 	ErrInvalidPayloadFormat = errors.New("invalid payload format")
 
 	ErrInvalidGrepIdx = errors.New("invalid grep index")
+
+	ErrInvalidStepRequest = errors.New("invalid step request")
 )
 
 // Profile represents the behavior expected from a scan profile.
@@ -32,3 +34,38 @@ type IssueInformation interface {
 	GetRemediationDetail() string
 	GetRemediationBackground() string
 }
+
+// HasGrepType returns whether any of the enabled greps of the
+// given [Profile] (from any of its steps, if active) is of the given [GrepType].
+func HasGrepType(p Profile, t GrepType) bool {
+	return len(GrepsOfType(p, t)) > 0
+}
+
+// GrepsOfType returns the enabled greps of the given [Profile] with the
+// given [GrepType], if any, with no raw replacements applied.
+func GrepsOfType(p Profile, t GrepType) []Grep {
+	var (
+		greps        []string
+		includeWhere bool
+	)
+
+	switch prof := p.(type) {
+	case *Active:
+		for _, step := range prof.Steps {
+			greps = append(greps, step.Greps...)
+		}
+	case *Request:
+		greps, includeWhere = prof.Greps, true
+	case *Response:
+		greps = prof.Greps
+	}
+
+	var found []Grep
+	for _, s := range greps {
+		if g, err := GrepFromString(s, nil, includeWhere); err == nil && g.Enabled && g.Type == t {
+			found = append(found, g)
+		}
+	}
+
+	return found
+}
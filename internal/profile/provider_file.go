@@ -59,50 +59,105 @@ func returnErr(err error) error {
 
 // NewFileProvider creates a new FileProvider instance.
 func NewFileProvider(locations ...string) (FileProvider, error) {
-	data := data{
-		actives:     make([]*Active, 0),
-		passiveReqs: make([]*Request, 0),
-		passiveRes:  make([]*Response, 0),
-		tags:        make(map[string]struct{}),
-	}
+	data := newData()
 
 	for _, location := range locations {
-		err := filepath.Walk(location,
-			func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
+		err := walkProfileFiles(location, func(path string, fileBytes []byte) error {
+			if err := readBB2Profiles(&data, fileBytes); err != nil {
+				return fmt.Errorf("%w(%s): %s", ErrProfilePath, path, err.Error())
+			}
 
-				// It only looks for *.bb and *.bb2 files
-				ext := filepath.Ext(info.Name())
-				if info.IsDir() || ext != FileExtension {
-					return nil
-				}
+			return nil
+		})
+		if err != nil {
+			return FileProvider{}, returnErr(err)
+		}
+	}
 
-				fileBytes, err := os.ReadFile(path)
-				if err != nil {
-					return returnErr(err)
-				}
+	return FileProvider{
+		data:      data,
+		locations: locations,
+	}, nil
+}
 
-				if ext == FileExtension {
-					err = readBB2Profiles(&data, fileBytes)
-				}
+// CheckFiles reads the profiles from the given file system locations, like
+// [NewFileProvider], but instead of failing on the first error, it keeps
+// going and returns all the errors found (joined), including those profiles
+// that are not well-formed (e.g. see [Active.Validate]).
+//
+// It also returns the amount of profiles read.
+func CheckFiles(locations ...string) (int, error) {
+	var (
+		n    int
+		errs []error
+	)
+
+	for _, location := range locations {
+		err := walkProfileFiles(location, func(path string, fileBytes []byte) error {
+			data := newData()
+			if err := readBB2Profiles(&data, fileBytes); err != nil {
+				errs = append(errs, fmt.Errorf("%w(%s): %s", ErrProfilePath, path, err.Error()))
+				return nil
+			}
 
+			check := func(name string, err error) {
+				n++
 				if err != nil {
-					return fmt.Errorf("%w(%s): %s", ErrProfilePath, path, err.Error())
+					errs = append(errs, fmt.Errorf("invalid profile(%s) at %s: %w", name, path, err))
 				}
+			}
 
-				return nil
-			})
+			for _, p := range data.actives {
+				check(p.Name, p.Validate())
+			}
+			for _, p := range data.passiveReqs {
+				check(p.Name, p.Validate())
+			}
+			for _, p := range data.passiveRes {
+				check(p.Name, p.Validate())
+			}
+
+			return nil
+		})
 		if err != nil {
-			return FileProvider{}, returnErr(err)
+			errs = append(errs, returnErr(err))
 		}
 	}
 
-	return FileProvider{
-		data:      data,
-		locations: locations,
-	}, nil
+	return n, errors.Join(errs...)
+}
+
+func newData() data {
+	return data{
+		actives:     make([]*Active, 0),
+		passiveReqs: make([]*Request, 0),
+		passiveRes:  make([]*Response, 0),
+		tags:        make(map[string]struct{}),
+	}
+}
+
+// walkProfileFiles walks the given location, and calls fn with
+// the path and contents of every profile file (see [FileExtension]).
+func walkProfileFiles(location string, fn func(path string, fileBytes []byte) error) error {
+	return filepath.Walk(location,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// It only looks for *.bb and *.bb2 files
+			ext := filepath.Ext(info.Name())
+			if info.IsDir() || ext != FileExtension {
+				return nil
+			}
+
+			fileBytes, err := os.ReadFile(path)
+			if err != nil {
+				return returnErr(err)
+			}
+
+			return fn(path, fileBytes)
+		})
 }
 
 // readBB2Profiles takes the given fileBytes and tries to unmarshal them into
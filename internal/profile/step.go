@@ -16,12 +16,14 @@ type Step struct {
 	RequestType          RequestType          `json:"request_type"`
 	InsertionPoint       InsertionPointMode   `json:"insertion_point"`
 	RawRequest           string               `json:"raw_request"`
+	Request              StepRequest          `json:"request"`
 	Payloads             []string             `json:"payloads"`
 	PayloadPosition      PayloadPosition      `json:"payload_position"`
 	ChangeHTTPMethod     bool                 `json:"change_http_request"`
 	ChangeHTTPMethodType ChangeHTTPMethodType `json:"change_http_request_type"`
 	InsertionPoints      []InsertionPointType `json:"insertion_points"`
 	CustomHeaders        []string             `json:"new_headers"`
+	RemoveHeaders        []string             `json:"remove_headers"`
 	MatchAndReplaces     []MatchAndReplace    `json:"match_replace"`
 	Encoder              []string             `json:"encoder"`
 	URLEncode            bool                 `json:"url_encode"`
@@ -99,7 +101,7 @@ func (s Step) InsertionPointEnabled(ipt InsertionPointType, method string) bool
 // enabled or not. In case the index is out of range, or the format is invalid,
 // an error is returned.
 func (s Step) PayloadAt(idx int) (bool, string, error) {
-	if s.RequestType.RawRequest() {
+	if s.RequestType.RawRequest() || s.RequestType.HostRequest() {
 		return false, "", nil
 	}
 
@@ -123,7 +125,7 @@ func (s Step) PayloadAt(idx int) (bool, string, error) {
 // PayloadAtEncoded is the equivalent of PayloadAt,
 // but it returns the Payload encoded, if so.
 func (s Step) PayloadAtEncoded(idx int) (bool, string, error) {
-	if s.RequestType.RawRequest() {
+	if s.RequestType.RawRequest() || s.RequestType.HostRequest() {
 		return false, "", nil
 	}
 
@@ -288,6 +290,9 @@ func (s Step) GetRemediationBackground() string {
 const (
 	OriginalRequest RequestType = "original"
 	RawRequest      RequestType = "raw_request"
+	// HostRequest is the type of those steps that send their own request (see [StepRequest]),
+	// once per host, regardless of the request templates (e.g. a probe for /.git/HEAD).
+	HostRequest RequestType = "host_request"
 )
 
 // RequestType represents the type of request.
@@ -302,3 +307,20 @@ func (rt RequestType) OriginalRequest() bool {
 func (rt RequestType) RawRequest() bool {
 	return rt == RawRequest
 }
+
+// HostRequest returns true if the request type is host (see [HostRequest]).
+func (rt RequestType) HostRequest() bool {
+	return rt == HostRequest
+}
+
+// StepRequest is the request sent by a step whose request type is [HostRequest], to
+// each host scanned, built on top of the request template (e.g. the Host header).
+//
+// The Method defaults to GET, the Path must be absolute (e.g. /.git/HEAD), and each
+// header has the form: Name: value, replacing the one from the request template, if any.
+type StepRequest struct {
+	Method  string   `json:"method"`
+	Path    string   `json:"path"`
+	Headers []string `json:"headers"`
+	Body    string   `json:"body"`
+}
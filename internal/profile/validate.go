@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validate checks that the payloads, the greps and the request (only for [HostRequest]
+// steps, see [StepRequest]) of every step of the active profile are well-formed (see
+// [Step.PayloadAt] and [Step.GrepAt]), and returns all the errors found (joined), if any.
+func (a Active) Validate() error {
+	var errs []error
+
+	for stepIdx, step := range a.Steps {
+		if step.RequestType.HostRequest() {
+			if err := step.Request.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("step %d, request: %w", stepIdx+1, err))
+			}
+		}
+
+		for idx := range step.Payloads {
+			if _, _, err := step.PayloadAt(idx); err != nil {
+				errs = append(errs, fmt.Errorf("step %d, payload %d: %w", stepIdx+1, idx+1, err))
+			}
+		}
+
+		for idx := range step.Greps {
+			if err := validateGrep(step.GrepAt(idx, nil)); err != nil {
+				errs = append(errs, fmt.Errorf("step %d, grep %d: %w", stepIdx+1, idx+1, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks that the greps of the request profile are
+// well-formed (see [Request.GrepAt]), and returns all the errors
+// found (joined), if any.
+func (r Request) Validate() error {
+	var errs []error
+
+	for idx := range r.Greps {
+		if err := validateGrep(r.GrepAt(idx, nil)); err != nil {
+			errs = append(errs, fmt.Errorf("grep %d: %w", idx+1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks that the greps of the response profile are
+// well-formed (see [Response.GrepAt]), and returns all the errors
+// found (joined), if any.
+func (p Response) Validate() error {
+	var errs []error
+
+	for idx := range p.Greps {
+		if err := validateGrep(p.GrepAt(idx, nil)); err != nil {
+			errs = append(errs, fmt.Errorf("grep %d: %w", idx+1, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that the path is absolute (e.g. /.git/HEAD), that neither the
+// path nor the method have spaces, and that every header has the form: Name: value.
+func (r StepRequest) validate() error {
+	if !strings.HasPrefix(r.Path, "/") || strings.ContainsAny(r.Path, " \t\r\n") {
+		return fmt.Errorf("%w: path must start with /, with no spaces: %q", ErrInvalidStepRequest, r.Path)
+	}
+
+	if strings.ContainsAny(r.Method, " \t\r\n") {
+		return fmt.Errorf("%w: method: %q", ErrInvalidStepRequest, r.Method)
+	}
+
+	for _, h := range r.Headers {
+		if name, _, found := strings.Cut(h, ":"); !found || len(strings.TrimSpace(name)) == 0 {
+			return fmt.Errorf("%w: header must have the form Name: value: %q", ErrInvalidStepRequest, h)
+		}
+	}
+
+	return nil
+}
+
+// validateGrep takes the result of GrepAt, and additionally checks
+// that regex values do compile, as those are only compiled when matching.
+func validateGrep(g Grep, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if g.Type.Regex() {
+		if _, err := regexp.Compile(g.Value.AsRegex()); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRegex, err.Error())
+		}
+	}
+
+	return nil
+}
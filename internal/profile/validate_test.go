@@ -0,0 +1,86 @@
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+func TestActive_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		active := profile.Active{
+			Steps: []profile.Step{{
+				Payloads: []string{"true,<script>alert(1)</script>"},
+				Greps:    []string{"true,,Regex,,<script>.*</script>", "true,AND,Status Code,,200;302"},
+			}},
+		}
+
+		require.NoError(t, active.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		active := profile.Active{
+			Steps: []profile.Step{
+				{Payloads: []string{"true,ok"}, Greps: []string{"true,,Simple String,,ok"}},
+				{
+					Payloads: []string{"no-comma"},
+					Greps:    []string{"true,,Regex,,(unclosed", "true,,Status Code", "true,,Status Code,,999"},
+				},
+			},
+		}
+
+		err := active.Validate()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, profile.ErrInvalidPayloadFormat)
+		assert.ErrorIs(t, err, profile.ErrInvalidRegex)
+		assert.ErrorIs(t, err, profile.ErrInvalidGrepFormat)
+		assert.ErrorIs(t, err, profile.ErrInvalidStatusCode)
+		assert.Contains(t, err.Error(), "step 2, grep 1")
+	})
+
+	t.Run("host request", func(t *testing.T) {
+		t.Parallel()
+
+		active := profile.Active{
+			Steps: []profile.Step{{
+				RequestType: profile.HostRequest,
+				Request:     profile.StepRequest{Path: "/.git/HEAD", Headers: []string{"Accept: */*"}},
+				Greps:       []string{"true,,Simple String,,ref: refs/"},
+			}},
+		}
+		require.NoError(t, active.Validate())
+
+		active.Steps[0].Request = profile.StepRequest{Path: ".git/HEAD", Headers: []string{"no-colon"}}
+
+		err := active.Validate()
+		require.ErrorIs(t, err, profile.ErrInvalidStepRequest)
+		assert.Contains(t, err.Error(), "step 1, request")
+	})
+}
+
+func TestRequest_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, profile.Request{Greps: []string{"true,,Simple String,All request,,token"}}.Validate())
+
+	err := profile.Request{Greps: []string{"true,,Simple String,,token"}}.Validate()
+	require.ErrorIs(t, err, profile.ErrInvalidGrepFormat)
+}
+
+func TestResponse_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, profile.Response{Greps: []string{"true,,Content Length,,100"}}.Validate())
+
+	err := profile.Response{Greps: []string{"yes,,Content Length,,100"}}.Validate()
+	require.ErrorIs(t, err, profile.ErrInvalidGrepEnabled)
+}
@@ -0,0 +1,224 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+)
+
+const (
+	// MetadataRateLimit is the [Match.Metadata] key that summarizes the behavior observed
+	// while probing the rate limit (see [RateLimitCfg]), e.g. throttled after 5 request(s).
+	MetadataRateLimit = "rate_limit"
+	// MetadataRateLimitThreshold is the [Match.Metadata] key that holds the amount of
+	// requests answered before being throttled, only set when throttling is observed.
+	MetadataRateLimitThreshold = "rate_limit_threshold"
+)
+
+// RateLimitTag is the tag of the active profiles that ask for the rate limit to be probed
+// (see [RateLimitCfg.Applies]), also the one of the matches reported while probing it.
+const RateLimitTag = "rate-limit"
+
+// RateLimitCfg defines whether a burst of requests is sent to those templates whose URL
+// matches, and how many, to detect whether the endpoint is rate-limited (see [CharacterizeRateLimit]),
+// e.g. a login form. With no regular expression defined, the burst is only sent if any of
+// the active profiles asks for it (see [RateLimitTag]), so not every template gets a burst.
+//
+// Requests within the burst are sent one after the other, as fast as possible, regardless
+// of the rate limit per URL (see [Config.RPS]), so the endpoint's own limits are observed.
+type RateLimitCfg struct {
+	Burst int
+	Match *regexp.Regexp
+}
+
+// Enabled returns whether the rate limit is probed or not.
+func (c RateLimitCfg) Enabled() bool {
+	return c.Burst > 0
+}
+
+// Applies returns whether the rate limit is probed for the given [Template], according to its URL,
+// or, if no regular expression is defined, whether any of the given active profiles asks for it.
+func (c RateLimitCfg) Applies(tpl Template, actives []*profile.Active) bool {
+	if !c.Enabled() || tpl.Response != nil {
+		return false
+	}
+
+	if c.Match != nil {
+		return c.Match.MatchString(requestURL(&tpl.Request))
+	}
+
+	return RateLimitRequested(actives)
+}
+
+// RateLimitRequested returns whether any of the given active profiles asks
+// for the rate limit to be probed, which is, whether it's tagged [RateLimitTag].
+func RateLimitRequested(actives []*profile.Active) bool {
+	for _, a := range actives {
+		if slices.Contains(a.GetTags(), RateLimitTag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RateLimitResult is the behavior observed on the responses to a burst of requests.
+type RateLimitResult struct {
+	// Sent is the amount of requests sent (and answered).
+	Sent int
+	// Throttled is true if any of the responses was throttled, either
+	// 429 Too Many Requests, or 403 Forbidden when the first one wasn't.
+	Throttled bool
+	// Threshold is the amount of requests answered before the first throttled response.
+	Threshold int
+	// Status is the status code of the first throttled response, if any.
+	Status int
+	// Statuses are the status codes of all the responses, in order.
+	Statuses []int
+}
+
+// CharacterizeRateLimit returns the [RateLimitResult] for the given status codes, which are those
+// of the responses to a burst of requests, in order. Responses are considered throttled once these
+// degrade to 429 Too Many Requests, or to 403 Forbidden (e.g. an account lockout), unless the
+// endpoint already responded with 403 since the first request.
+func CharacterizeRateLimit(statuses []int) RateLimitResult {
+	result := RateLimitResult{Sent: len(statuses), Threshold: len(statuses), Statuses: statuses}
+
+	for i, code := range statuses {
+		if code == http.StatusTooManyRequests || (code == http.StatusForbidden && statuses[0] != http.StatusForbidden) {
+			result.Throttled = true
+			result.Threshold = i
+			result.Status = code
+			break
+		}
+	}
+
+	return result
+}
+
+// Summary returns a single-line summary of the behavior observed, e.g. throttled after 5
+// request(s) (429), out of 20 sent, or not throttled: 20 request(s) sent, responded with 200 (x20).
+func (r RateLimitResult) Summary() string {
+	if r.Throttled {
+		return fmt.Sprintf("throttled after %d request(s) (%d), out of %d sent", r.Threshold, r.Status, r.Sent)
+	}
+
+	return fmt.Sprintf("not throttled: %d request(s) sent, responded with %s", r.Sent, r.statusesString())
+}
+
+// statusesString returns the status codes of the responses, along with
+// how many times each one was observed, e.g. 200 (x18), 302 (x2).
+func (r RateLimitResult) statusesString() string {
+	counts := make(map[int]int)
+	for _, code := range r.Statuses {
+		counts[code]++
+	}
+
+	codes := make([]int, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%d (x%d)", code, counts[code]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// rateLimitKey is the [context.Context] key for the [RateLimitResult] of a match.
+type rateLimitKey struct{}
+
+// withRateLimit returns a copy of the given [context.Context] with the given
+// [RateLimitResult], so it can be attached to the match reported (see [RateLimitOf]).
+func withRateLimit(ctx context.Context, r RateLimitResult) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, r)
+}
+
+// RateLimitOf returns the [RateLimitResult] of a match reported with the given
+// [context.Context], if any, or nil if it isn't a rate limit match.
+func RateLimitOf(ctx context.Context) *RateLimitResult {
+	r, ok := ctx.Value(rateLimitKey{}).(RateLimitResult)
+	if !ok {
+		return nil
+	}
+	return &r
+}
+
+// rateLimitMetadata returns the given metadata along with the [RateLimitResult]
+// from the given [context.Context] (see [MetadataRateLimit]), if any.
+func rateLimitMetadata(ctx context.Context, metadata map[string]string) map[string]string {
+	r := RateLimitOf(ctx)
+	if r == nil {
+		return metadata
+	}
+
+	metadata = withMetadata(metadata, MetadataRateLimit, []string{r.Summary()})
+	if r.Throttled {
+		metadata = withMetadata(metadata, MetadataRateLimitThreshold, []string{strconv.Itoa(r.Threshold)})
+	}
+
+	return metadata
+}
+
+// rateLimitProfile is the (built-in) profile the matches reported while
+// probing the rate limit (see [RateLimitCfg]) belong to, either because
+// the responses were throttled, or because these weren't at all.
+type rateLimitProfile struct {
+	result RateLimitResult
+}
+
+var (
+	_ profile.Profile          = rateLimitProfile{}
+	_ profile.IssueInformation = rateLimitProfile{}
+)
+
+func (rateLimitProfile) GetName() string       { return "Rate limit" }
+func (rateLimitProfile) GetType() profile.Type { return profile.TypeActive }
+func (rateLimitProfile) IsEnabled() bool       { return true }
+func (rateLimitProfile) GetTags() []string     { return []string{RateLimitTag} }
+
+func (p rateLimitProfile) GetIssueName() string {
+	if p.result.Throttled {
+		return "Rate limiting detected"
+	}
+	return "No rate limiting detected"
+}
+
+func (p rateLimitProfile) GetIssueSeverity() string {
+	if p.result.Throttled {
+		return "Information"
+	}
+	return "Low"
+}
+
+func (rateLimitProfile) GetIssueConfidence() string { return "Firm" }
+
+func (p rateLimitProfile) GetIssueDetail() string {
+	if p.result.Throttled {
+		return fmt.Sprintf("The endpoint throttled a burst of %d requests after %d request(s), with status %d.", p.result.Sent, p.result.Threshold, p.result.Status)
+	}
+	return fmt.Sprintf("The endpoint answered a burst of %d requests with no throttling (i.e. no 429 nor 403 responses).", p.result.Sent)
+}
+
+func (rateLimitProfile) GetIssueBackground() string {
+	return "Endpoints with no rate limiting (nor account lockout), like authentication ones, are exposed to brute-force and credential stuffing attacks."
+}
+
+func (p rateLimitProfile) GetRemediationDetail() string {
+	if p.result.Throttled {
+		return ""
+	}
+	return "Limit the rate of requests per client (and per account), e.g. with 429 Too Many Requests responses, or lock the account out after several failed attempts."
+}
+
+func (rateLimitProfile) GetRemediationBackground() string { return "" }
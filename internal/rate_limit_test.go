@@ -0,0 +1,97 @@
+package scan_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestCharacterizeRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		statuses  []int
+		throttled bool
+		threshold int
+		status    int
+		summary   string
+	}{
+		"too many requests": {
+			statuses:  []int{200, 200, 200, 429, 429},
+			throttled: true,
+			threshold: 3,
+			status:    429,
+			summary:   "throttled after 3 request(s) (429), out of 5 sent",
+		},
+		"account lockout": {
+			statuses:  []int{401, 401, 403},
+			throttled: true,
+			threshold: 2,
+			status:    403,
+			summary:   "throttled after 2 request(s) (403), out of 3 sent",
+		},
+		"always forbidden": {
+			statuses:  []int{403, 403, 403},
+			threshold: 3,
+			summary:   "not throttled: 3 request(s) sent, responded with 403 (x3)",
+		},
+		"not throttled": {
+			statuses:  []int{200, 302, 200, 200},
+			threshold: 4,
+			summary:   "not throttled: 4 request(s) sent, responded with 200 (x3), 302 (x1)",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result := scan.CharacterizeRateLimit(tc.statuses)
+			assert.Equal(t, tc.throttled, result.Throttled)
+			assert.Equal(t, tc.threshold, result.Threshold)
+			assert.Equal(t, tc.status, result.Status)
+			assert.Equal(t, len(tc.statuses), result.Sent)
+			assert.Equal(t, tc.summary, result.Summary())
+		})
+	}
+}
+
+func TestRateLimitCfg_Applies(t *testing.T) {
+	t.Parallel()
+
+	var (
+		login  = scan.NewTemplate(context.Background(), 0, request.Default("http://example.com/login"), nil)
+		home   = scan.NewTemplate(context.Background(), 1, request.Default("http://example.com/"), nil)
+		tagged = []*profile.Active{{Name: "Brute-force", Tags: []string{scan.RateLimitTag}}}
+		others = []*profile.Active{{Name: "XSS", Tags: []string{"xss"}}}
+	)
+
+	tcs := map[string]struct {
+		cfg     scan.RateLimitCfg
+		tpl     scan.Template
+		actives []*profile.Active
+		want    bool
+	}{
+		"disabled":                    {cfg: scan.RateLimitCfg{}, tpl: login, actives: tagged, want: false},
+		"matching url":                {cfg: scan.RateLimitCfg{Burst: 5, Match: regexp.MustCompile(`/login$`)}, tpl: login, want: true},
+		"non-matching url":            {cfg: scan.RateLimitCfg{Burst: 5, Match: regexp.MustCompile(`/login$`)}, tpl: home, actives: tagged, want: false},
+		"no match, profile asks":      {cfg: scan.RateLimitCfg{Burst: 5}, tpl: home, actives: tagged, want: true},
+		"no match, no profile asks":   {cfg: scan.RateLimitCfg{Burst: 5}, tpl: home, actives: others, want: false},
+		"no match, no active profile": {cfg: scan.RateLimitCfg{Burst: 5}, tpl: home, want: false},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, tc.cfg.Applies(tc.tpl, tc.actives))
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// RedactSensitiveData returns the given responses with the sensitive data found
+// (i.e. the occurrences within their bodies) partially masked (see [match.Redact]),
+// if the given [profile.Profile] looks for sensitive data (see [profile.GrepTypeSensitiveData]).
+// So, secrets are never stored nor reported as is. Otherwise, it returns them untouched.
+//
+// The responses are copied, as these might be shared with other matches. Occurrences
+// are expected in the same order (i.e. occ[i] are those from res[i]).
+func RedactSensitiveData(prof profile.Profile, res []*response.Response, occ [][]occurrence.Occurrence) []*response.Response {
+	if prof == nil || !profile.HasGrepType(prof, profile.GrepTypeSensitiveData) {
+		return res
+	}
+
+	redacted := make([]*response.Response, len(res))
+	for i, r := range res {
+		redacted[i] = r
+		if r == nil || i >= len(occ) || len(occ[i]) == 0 {
+			continue
+		}
+
+		offset := r.BodyOffset()
+		body := bytes.Clone(r.Body)
+
+		for _, o := range occ[i] {
+			start, end := o[0]-offset, o[1]-offset
+			if start < 0 || end > len(body) || start >= end {
+				continue
+			}
+
+			copy(body[start:end], match.Redact(string(body[start:end])))
+		}
+
+		cp := *r
+		cp.Body = body
+		redacted[i] = &cp
+	}
+
+	return redacted
+}
+
+// RedactedValue is the value that replaces those redacted from the outputs (see [Redaction]).
+const RedactedValue = "***REDACTED***"
+
+// Redaction defines which values are masked (see [RedactedValue]) from the requests and
+// responses written to the scan outputs: the values of the given headers (case-insensitive),
+// and those matching any of the given patterns (within the URL, path, headers and body).
+//
+// It is applied when the outputs are written (see [WithRedaction]), so the scan itself
+// (i.e. the matchers) and its storage still rely on the real values.
+type Redaction struct {
+	Headers  []string
+	Patterns []*regexp.Regexp
+}
+
+// IsEmpty returns whether the [Redaction] has neither headers nor patterns.
+func (r Redaction) IsEmpty() bool {
+	return len(r.Headers) == 0 && len(r.Patterns) == 0
+}
+
+// Clone returns a deep copy of the [Redaction] instance.
+func (r Redaction) Clone() Redaction {
+	var patterns []*regexp.Regexp
+	if r.Patterns != nil {
+		patterns = append([]*regexp.Regexp{}, r.Patterns...)
+	}
+
+	return Redaction{
+		Headers:  cloneStrings(r.Headers),
+		Patterns: patterns,
+	}
+}
+
+// Match returns a copy of the given [Match], with its URL, requests and responses redacted.
+// The occurrences are shifted accordingly, so these still point to the same (redacted) data.
+func (r Redaction) Match(m Match) Match {
+	if r.IsEmpty() {
+		return m
+	}
+
+	m.URL, _ = r.redactText(m.URL, 0)
+	m.Requests = r.requests(m.Requests)
+
+	responses := make([]*response.Response, len(m.Responses))
+	occurrences := make([][]occurrence.Occurrence, len(m.Occurrences))
+	copy(occurrences, m.Occurrences)
+
+	for i, res := range m.Responses {
+		var edits []redactionEdit
+		responses[i], edits = r.response(res)
+
+		if i < len(occurrences) {
+			occurrences[i] = shiftOccurrences(occurrences[i], edits)
+		}
+	}
+
+	m.Responses, m.Occurrences = responses, occurrences
+
+	return m
+}
+
+// Error returns a copy of the given [Error], with its URL, requests and responses redacted.
+func (r Redaction) Error(e Error) Error {
+	if r.IsEmpty() {
+		return e
+	}
+
+	e.URL, _ = r.redactText(e.URL, 0)
+	e.Requests, e.Responses = r.requests(e.Requests), r.responses(e.Responses)
+
+	return e
+}
+
+// TaskSummary returns a copy of the given [TaskSummary], with its URL, requests and responses redacted.
+func (r Redaction) TaskSummary(ts TaskSummary) TaskSummary {
+	if r.IsEmpty() {
+		return ts
+	}
+
+	ts.URL, _ = r.redactText(ts.URL, 0)
+	ts.Requests, ts.Responses = r.requests(ts.Requests), r.responses(ts.Responses)
+
+	return ts
+}
+
+// Request returns a copy of the given [request.Request], redacted.
+func (r Redaction) Request(req *request.Request) *request.Request {
+	if req == nil || r.IsEmpty() {
+		return req
+	}
+
+	cp := req.Clone()
+	cp.URL, _ = r.redactText(req.URL, 0)
+	cp.Path, _ = r.redactText(req.Path, 0)
+
+	for key, values := range req.Headers {
+		if value, edits := r.redactHeader(key, strings.Join(values, ", "), 0); len(edits) > 0 {
+			cp.Headers[key] = []string{value}
+		}
+	}
+
+	if body, edits := r.redactText(string(req.Body), 0); len(edits) > 0 {
+		cp.Body = []byte(body)
+	}
+
+	return &cp
+}
+
+// Response returns a copy of the given [response.Response], redacted.
+func (r Redaction) Response(res *response.Response) *response.Response {
+	redacted, _ := r.response(res)
+	return redacted
+}
+
+// redactionEdit is a replacement made while redacting, from start to end (i.e. the
+// original span, within [response.Response.Bytes]), with a value of the given length.
+type redactionEdit struct {
+	start, end, length int
+}
+
+func (r Redaction) requests(reqs []*request.Request) []*request.Request {
+	redacted := make([]*request.Request, len(reqs))
+	for i, req := range reqs {
+		redacted[i] = r.Request(req)
+	}
+
+	return redacted
+}
+
+func (r Redaction) responses(res []*response.Response) []*response.Response {
+	redacted := make([]*response.Response, len(res))
+	for i, rr := range res {
+		redacted[i] = r.Response(rr)
+	}
+
+	return redacted
+}
+
+// response redacts the given [response.Response], and returns the edits made, with
+// the same layout as [response.Response.Bytes], so the occurrences can be shifted.
+func (r Redaction) response(res *response.Response) (*response.Response, []redactionEdit) {
+	if res == nil || res.IsEmpty() || r.IsEmpty() {
+		return res, nil
+	}
+
+	cp := *res
+	cp.Headers = make(map[string][]string, len(res.Headers))
+
+	keys := make([]string, 0, len(res.Headers))
+	for key := range res.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var edits []redactionEdit
+
+	// Status line, followed by each header line (i.e. key: value).
+	offset := len(res.Proto + " " + strconv.Itoa(res.Code) + " " + res.Status + "\r\n")
+	for _, key := range keys {
+		offset += len(key + ": ")
+
+		original := strings.Join(res.Headers[key], ", ")
+		value, headerEdits := r.redactHeader(key, original, offset)
+
+		cp.Headers[key] = res.Headers[key]
+		if len(headerEdits) > 0 {
+			cp.Headers[key] = []string{value}
+			edits = append(edits, headerEdits...)
+		}
+
+		offset += len(original + "\r\n")
+	}
+
+	offset += len("\r\n")
+	if body, bodyEdits := r.redactText(string(res.Body), offset); len(bodyEdits) > 0 {
+		cp.Body = []byte(body)
+		edits = append(edits, bodyEdits...)
+	}
+
+	return &cp, edits
+}
+
+// redactHeader redacts the whole value, if the header is one of the [Redaction.Headers],
+// or the parts matching the [Redaction.Patterns] otherwise (see [Redaction.redactText]).
+func (r Redaction) redactHeader(key, value string, offset int) (string, []redactionEdit) {
+	for _, header := range r.Headers {
+		if strings.EqualFold(header, key) && len(value) > 0 {
+			return RedactedValue, []redactionEdit{{start: offset, end: offset + len(value), length: len(RedactedValue)}}
+		}
+	}
+
+	return r.redactText(value, offset)
+}
+
+// redactText replaces the parts of the given text matching any of the [Redaction.Patterns]
+// (overlapping ones are merged), and returns the edits made, shifted by the given offset.
+func (r Redaction) redactText(text string, offset int) (string, []redactionEdit) {
+	var spans [][]int
+	for _, pattern := range r.Patterns {
+		for _, span := range pattern.FindAllStringIndex(text, -1) {
+			if span[0] < span[1] {
+				spans = append(spans, span)
+			}
+		}
+	}
+
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var (
+		builder strings.Builder
+		edits   []redactionEdit
+		last    int
+	)
+
+	for _, span := range spans {
+		if n := len(edits); n > 0 && span[0] < edits[n-1].end-offset {
+			// Overlapping spans are merged into the previous edit.
+			if end := span[1] + offset; end > edits[n-1].end {
+				edits[n-1].end = end
+				last = span[1]
+			}
+
+			continue
+		}
+
+		builder.WriteString(text[last:span[0]])
+		builder.WriteString(RedactedValue)
+		edits = append(edits, redactionEdit{start: span[0] + offset, end: span[1] + offset, length: len(RedactedValue)})
+		last = span[1]
+	}
+
+	builder.WriteString(text[last:])
+
+	return builder.String(), edits
+}
+
+// shiftOccurrences returns the given occurrences, shifted according to the given
+// (sorted) edits. Those (partially) within a redacted span point to the whole value.
+func shiftOccurrences(occ []occurrence.Occurrence, edits []redactionEdit) []occurrence.Occurrence {
+	if len(edits) == 0 || occ == nil {
+		return occ
+	}
+
+	shifted := make([]occurrence.Occurrence, len(occ))
+	for i, o := range occ {
+		shifted[i] = occurrence.Occurrence{shiftOffset(o[0], edits, false), shiftOffset(o[1], edits, true)}
+	}
+
+	return shifted
+}
+
+func shiftOffset(pos int, edits []redactionEdit, isEnd bool) int {
+	var delta int
+
+	for _, e := range edits {
+		switch {
+		case pos <= e.start:
+			return pos + delta
+		case pos >= e.end:
+			delta += e.length - (e.end - e.start)
+		case isEnd:
+			return e.start + delta + e.length
+		default:
+			return e.start + delta
+		}
+	}
+
+	return pos + delta
+}
+
+// WithRedaction decorates the given [FileSystem], so the [Match], [Error] and [TaskSummary]
+// instances loaded from it are redacted (see [Redaction]), while the stored ones are kept
+// as is. So, it's meant to be used to write the scan outputs. If the given [Redaction] is
+// empty, the given [FileSystem] is returned as is.
+func WithRedaction(fs FileSystem, r Redaction) FileSystem {
+	if r.IsEmpty() {
+		return fs
+	}
+
+	return redactingFS{FileSystem: fs, redaction: r}
+}
+
+type redactingFS struct {
+	FileSystem
+	redaction Redaction
+}
+
+func (fs redactingFS) LoadMatches(ctx context.Context) ([]Match, error) {
+	matches, err := fs.FileSystem.LoadMatches(ctx)
+	for i := range matches {
+		matches[i] = fs.redaction.Match(matches[i])
+	}
+
+	return matches, err
+}
+
+func (fs redactingFS) LoadMatch(ctx context.Context, id string) (*Match, error) {
+	m, err := fs.FileSystem.LoadMatch(ctx, id)
+	if m != nil {
+		redacted := fs.redaction.Match(*m)
+		m = &redacted
+	}
+
+	return m, err
+}
+
+func (fs redactingFS) MatchesIterator(ctx context.Context) (chan Match, CloseFunc, error) {
+	ch, closeFn, err := fs.FileSystem.MatchesIterator(ctx)
+	if err != nil {
+		return ch, closeFn, err
+	}
+
+	return redactAll(ch, fs.redaction.Match), closeFn, nil
+}
+
+func (fs redactingFS) LoadErrors(ctx context.Context) ([]Error, error) {
+	errs, err := fs.FileSystem.LoadErrors(ctx)
+	for i := range errs {
+		errs[i] = fs.redaction.Error(errs[i])
+	}
+
+	return errs, err
+}
+
+func (fs redactingFS) ErrorsIterator(ctx context.Context) (chan Error, CloseFunc, error) {
+	ch, closeFn, err := fs.FileSystem.ErrorsIterator(ctx)
+	if err != nil {
+		return ch, closeFn, err
+	}
+
+	return redactAll(ch, fs.redaction.Error), closeFn, nil
+}
+
+func (fs redactingFS) LoadTasksSummaries(ctx context.Context) ([]TaskSummary, error) {
+	summaries, err := fs.FileSystem.LoadTasksSummaries(ctx)
+	for i := range summaries {
+		summaries[i] = fs.redaction.TaskSummary(summaries[i])
+	}
+
+	return summaries, err
+}
+
+func (fs redactingFS) TasksSummariesIterator(ctx context.Context) (chan TaskSummary, CloseFunc, error) {
+	ch, closeFn, err := fs.FileSystem.TasksSummariesIterator(ctx)
+	if err != nil {
+		return ch, closeFn, err
+	}
+
+	return redactAll(ch, fs.redaction.TaskSummary), closeFn, nil
+}
+
+// redactAll returns a channel with the elements from the given one, redacted with the given function.
+func redactAll[T any](ch chan T, redact func(T) T) chan T {
+	redacted := make(chan T)
+
+	go func() {
+		defer close(redacted)
+
+		for v := range ch {
+			redacted <- redact(v)
+		}
+	}()
+
+	return redacted
+}
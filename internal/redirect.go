@@ -0,0 +1,152 @@
+package scan
+
+import (
+	"net/http"
+	stdurl "net/url"
+	"strings"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// sensitiveHeaders are the request headers dropped on cross-host redirects,
+// unless [RedirectPolicy.KeepSensitiveHeaders] is set, as those may carry
+// credentials that only belong to the original host.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Cookie2"}
+
+// RedirectPolicy defines how requests are updated when following redirects,
+// from one hop to the next one (see [profile.Step.RedirectType]).
+//
+// The cookies set by each response (i.e. Set-Cookie) are always propagated to
+// the next hop, as long as they apply to its host. Additionally, SendReferer sets
+// the Referer header to the previous hop's URL, and KeepSensitiveHeaders keeps the
+// Authorization and Cookie headers on cross-host redirects, dropped otherwise.
+type RedirectPolicy struct {
+	SendReferer          bool
+	KeepSensitiveHeaders bool
+}
+
+// apply updates the given [request.Request] (i.e. the next hop), according to
+// the [RedirectPolicy], once redirected from the given previous URL, with the
+// given [response.Response] (i.e. the redirect).
+func (p RedirectPolicy) apply(req *request.Request, res *response.Response, prevURL string) {
+	prev, errPrev := stdurl.Parse(prevURL)
+	next, errNext := stdurl.Parse(requestURL(req))
+	if errPrev != nil || errNext != nil {
+		return
+	}
+
+	if !p.KeepSensitiveHeaders && !strings.EqualFold(prev.Hostname(), next.Hostname()) {
+		for _, key := range sensitiveHeaders {
+			req.DeleteHeader(key)
+		}
+	}
+
+	propagateCookies(req, res, prev, next)
+
+	if p.SendReferer {
+		// Like browsers do, no referer is sent on downgrades (i.e. from https to http).
+		if prev.Scheme == "https" && next.Scheme != "https" {
+			req.DeleteHeader("Referer")
+		} else {
+			prev.User, prev.Fragment, prev.RawFragment = nil, "", ""
+			req.SetHeader("Referer", prev.String())
+		}
+	}
+}
+
+// propagateCookies sets the cookies from the given [response.Response] into the
+// Cookie header of the given [request.Request], replacing those with the same name,
+// and removing those expired. Only those cookies that apply to the next host are
+// propagated: those with a matching Domain attribute, or set by the same host.
+func propagateCookies(req *request.Request, res *response.Response, prev, next *stdurl.URL) {
+	if res == nil || len(res.Headers["Set-Cookie"]) == 0 {
+		return
+	}
+
+	setCookies := (&http.Response{Header: http.Header{"Set-Cookie": res.Headers["Set-Cookie"]}}).Cookies()
+	if len(setCookies) == 0 {
+		return
+	}
+
+	var (
+		cookies = req.Cookies()
+		changed bool
+	)
+
+	for _, c := range setCookies {
+		if !cookieApplies(c, prev.Hostname(), next.Hostname()) {
+			continue
+		}
+
+		expired := c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(time.Now()))
+
+		idx := -1
+		for i := range cookies {
+			if cookies[i].Name == c.Name {
+				idx = i
+				break
+			}
+		}
+
+		switch {
+		case expired && idx >= 0:
+			cookies, changed = append(cookies[:idx], cookies[idx+1:]...), true
+		case expired:
+		case idx >= 0:
+			cookies[idx].Value, changed = c.Value, true
+		default:
+			cookies, changed = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value}), true
+		}
+	}
+
+	// The Cookie header is kept as is, unless there's any change.
+	if !changed {
+		return
+	}
+
+	if len(cookies) == 0 {
+		req.DeleteHeader("Cookie")
+		return
+	}
+
+	pairs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+
+	req.SetHeader("Cookie", strings.Join(pairs, "; "))
+}
+
+// cookieApplies returns whether the given cookie, set by the prev host,
+// must be sent to the next host, according to its Domain attribute.
+func cookieApplies(c *http.Cookie, prev, next string) bool {
+	prev, next = strings.ToLower(prev), strings.ToLower(next)
+
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if len(domain) == 0 {
+		return prev == next
+	}
+
+	// The domain must be the one that set the cookie, or a parent.
+	if prev != domain && !strings.HasSuffix(prev, "."+domain) {
+		return false
+	}
+
+	return next == domain || strings.HasSuffix(next, "."+domain)
+}
+
+// requestURL returns the absolute URL the given [request.Request] is sent to.
+func requestURL(req *request.Request) string {
+	if !strings.HasPrefix(req.Path, "/") {
+		return req.URL
+	}
+
+	u, err := stdurl.Parse(req.URL)
+	if err != nil {
+		return req.URL
+	}
+
+	return u.Scheme + "://" + u.Host + req.Path
+}
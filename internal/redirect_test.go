@@ -0,0 +1,91 @@
+//nolint:testpackage
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func Test_shouldFollowRedirect_RedirectPolicy(t *testing.T) {
+	t.Parallel()
+
+	newReq := func() request.Request {
+		req := request.Default("https://example.com/login")
+		req.RedirectType = profile.RedirectAlways
+		req.MaxRedirects = 5
+		req.FollowedRedirects = 1
+		req.SetHeader("Authorization", "Bearer secret")
+		req.SetHeader("Cookie", "session=old; theme=dark")
+		return req
+	}
+
+	redirect := func(location string, setCookies ...string) *response.Response {
+		return &response.Response{
+			Code: 302,
+			Headers: map[string][]string{
+				"Location":   {location},
+				"Set-Cookie": setCookies,
+			},
+		}
+	}
+
+	t.Run("same host", func(t *testing.T) {
+		t.Parallel()
+
+		req := newReq()
+		res := redirect("/home", "session=new; Path=/; HttpOnly", "theme=; Max-Age=0", "csrf=abc")
+
+		require.True(t, shouldFollowRedirect(context.Background(), &req, res, RedirectPolicy{SendReferer: true}))
+		assert.Equal(t, "/home", req.Path)
+		assert.Equal(t, "Bearer secret", req.Header("Authorization"))
+		assert.Equal(t, "session=new; csrf=abc", req.Header("Cookie"))
+		assert.Equal(t, "https://example.com/login", req.Header("Referer"))
+	})
+
+	t.Run("cross host", func(t *testing.T) {
+		t.Parallel()
+
+		req := newReq()
+		res := redirect("https://auth.example.org/sso", "session=new", "sso=1; Domain=example.org")
+
+		require.True(t, shouldFollowRedirect(context.Background(), &req, res, RedirectPolicy{}))
+		assert.Equal(t, "https://auth.example.org/sso", req.URL)
+		assert.NotContains(t, req.Headers, "Authorization")
+		assert.NotContains(t, req.Headers, "Cookie")
+		assert.NotContains(t, req.Headers, "Referer")
+		assert.NotContains(t, req.HeaderOrder, "Authorization")
+	})
+
+	t.Run("cross host keeping sensitive headers", func(t *testing.T) {
+		t.Parallel()
+
+		req := newReq()
+		res := redirect("http://other.com/")
+
+		require.True(t, shouldFollowRedirect(context.Background(), &req, res, RedirectPolicy{SendReferer: true, KeepSensitiveHeaders: true}))
+		assert.Equal(t, "Bearer secret", req.Header("Authorization"))
+		assert.Equal(t, "session=old; theme=dark", req.Header("Cookie"))
+		// No referer on downgrades (from https to http).
+		assert.NotContains(t, req.Headers, "Referer")
+	})
+
+	t.Run("parent domain cookie", func(t *testing.T) {
+		t.Parallel()
+
+		req := request.Default("https://www.example.com/")
+		req.RedirectType = profile.RedirectAlways
+		req.MaxRedirects = 5
+		req.FollowedRedirects = 1
+		res := redirect("https://api.example.com/", "sso=1; Domain=.example.com", "local=1")
+
+		require.True(t, shouldFollowRedirect(context.Background(), &req, res, RedirectPolicy{}))
+		assert.Equal(t, "sso=1", req.Header("Cookie"))
+	})
+}
@@ -0,0 +1,50 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// MetadataReflectionContext is the [Match.Metadata] key that identifies the context(s) the
+// payload is reflected in (e.g. js_string), only set when the [profile.Profile] looks for
+// reflections in context (see [profile.GrepTypeReflectionContext]), and any is found.
+const MetadataReflectionContext = "reflection_context"
+
+// ReflectionContexts returns the contexts (e.g. html_attribute) the given payload is
+// reflected in within the given responses, according to the Reflection Context greps of the
+// given [profile.Profile] (see [match.ReflectionContexts]), if any. Each response is looked
+// into along with the canary of its request (see [request.Request.Canary]), if any. So, these
+// can be reported along with the match (see [MetadataReflectionContext]).
+func ReflectionContexts(ctx context.Context, prof profile.Profile, reqs []*request.Request, res []*response.Response, payload string) []string {
+	if prof == nil || len(payload) == 0 {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeReflectionContext)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var contexts []string
+	for i, r := range res {
+		canaried := payload
+		if i < len(reqs) && reqs[i] != nil {
+			canaried = reqs[i].Canary + payload
+		}
+
+		for _, g := range greps {
+			for _, c := range match.ReflectionContexts(ctx, g, r, &canaried) {
+				if !slices.In(contexts, string(c)) {
+					contexts = append(contexts, string(c))
+				}
+			}
+		}
+	}
+
+	return contexts
+}
@@ -0,0 +1,42 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestReflectionContexts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	res := []*response.Response{
+		nil,
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte(`<p>gb1<x></p><a title="gb2<x>">`)},
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Body: []byte(`<script>var s = "<x>";</script>`)},
+	}
+
+	reflected := &profile.Active{Type: profile.TypeActive, Steps: []profile.Step{{Greps: []string{"true,,Reflection Context,,"}}}}
+	assert.Equal(t, []string{"html_body", "html_attribute", "js_string"}, scan.ReflectionContexts(ctx, reflected, nil, res, "<x>"))
+
+	// Each response is only looked into along with the canary of its request.
+	reqs := []*request.Request{nil, {Canary: "gb2"}, {Canary: "gb3"}}
+	assert.Equal(t, []string{"html_attribute"}, scan.ReflectionContexts(ctx, reflected, reqs, res, "<x>"))
+
+	// Only the expected contexts are reported.
+	inJS := &profile.Active{Type: profile.TypeActive, Steps: []profile.Step{{Greps: []string{"true,,Reflection Context,,js_string;url"}}}}
+	metadata := scan.MatchMetadata(ctx, map[string]string{"team": "red"}, inJS, nil, res, "<x>")
+	assert.Equal(t, map[string]string{"team": "red", scan.MetadataReflectionContext: "js_string"}, metadata)
+
+	// Only profiles looking for reflections in context report those.
+	other := &profile.Active{Type: profile.TypeActive, Steps: []profile.Step{{Greps: []string{"true,,Payload,,"}}}}
+	assert.Empty(t, scan.ReflectionContexts(ctx, other, nil, res, "<x>"))
+	assert.Empty(t, scan.ReflectionContexts(ctx, reflected, nil, res, ""))
+}
@@ -0,0 +1,60 @@
+package scan
+
+import (
+	"context"
+	"time"
+
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// MatchReplayHeader is the header added to the requests replayed once a [Match] is stored
+// (see [WithMatchesReplay]), with the identifier of the match and its issue name,
+// so these can be easily found (e.g. in the proxy history of an intercepting proxy).
+const MatchReplayHeader = "X-Gbounty-Match"
+
+// matchReplayTimeout is the timeout used to replay those requests stored with no timeout.
+const matchReplayTimeout = 20 * time.Second
+
+// WithMatchesReplay decorates the given [FileSystem], so the requests of every [Match]
+// stored into it are re-sent, as is, with the given [Requester] (e.g. one that proxies
+// requests to an intercepting proxy), tagged with the [MatchReplayHeader]. The requests
+// are replayed once the [Match] has been stored, in the order these were sent.
+//
+// The responses are ignored, and any error while replaying is logged, but
+// never returned, so the [Match] is stored (and the scan goes on) regardless.
+func WithMatchesReplay(fs FileSystem, requester Requester) FileSystem {
+	return replayingFS{FileSystem: fs, requester: requester}
+}
+
+type replayingFS struct {
+	FileSystem
+	requester Requester
+}
+
+func (fs replayingFS) StoreMatch(ctx context.Context, m Match) error {
+	if err := fs.FileSystem.StoreMatch(ctx, m); err != nil {
+		return err
+	}
+
+	for _, req := range m.Requests {
+		if req == nil {
+			continue
+		}
+
+		// The stored request is cloned, as it's shared with the stored match.
+		replayed := req.Clone()
+		replayed.SetHeader(MatchReplayHeader, m.ID+" ("+m.IssueName+")")
+		if replayed.Timeout <= 0 {
+			replayed.Timeout = matchReplayTimeout
+		}
+
+		if _, err := fs.requester.Do(ctx, &replayed); err != nil {
+			logger.For(ctx).Errorf("Error while replaying request of scan match (id=%s): %s", m.ID, err.Error())
+			continue
+		}
+
+		logger.For(ctx).Debugf("Request of scan match (id=%s) replayed: %s %s", m.ID, replayed.Method, replayed.URL)
+	}
+
+	return nil
+}
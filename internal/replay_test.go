@@ -0,0 +1,68 @@
+package scan_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestWithMatchesReplay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	login := request.WithOptions("http://example.org/login")
+	search := request.WithOptions("http://example.org/search?q='")
+
+	requester := &replayRequester{err: errors.New("connection refused")}
+	m := scan.Match{
+		ID:        "3f2a",
+		URL:       "http://example.org/search",
+		IssueName: "SQL Injection",
+		Requests:  []*request.Request{&login, nil, &search},
+	}
+
+	// Errors while replaying are ignored, so the match is stored anyway.
+	require.NoError(t, scan.WithMatchesReplay(fs, requester).StoreMatch(ctx, m))
+
+	require.Len(t, requester.reqs, 2)
+	for i, want := range []string{login.URL, search.URL} {
+		assert.Equal(t, want, requester.reqs[i].URL)
+		assert.Equal(t, []string{"3f2a (SQL Injection)"}, requester.reqs[i].Headers[scan.MatchReplayHeader])
+		assert.Positive(t, requester.reqs[i].Timeout)
+	}
+
+	// The stored requests are kept as is.
+	assert.NotContains(t, search.Headers, scan.MatchReplayHeader)
+
+	stored, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, m.ID, stored[0].ID)
+}
+
+type replayRequester struct {
+	sync.Mutex
+	err  error
+	reqs []request.Request
+}
+
+func (rr *replayRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	rr.Lock()
+	defer rr.Unlock()
+	rr.reqs = append(rr.reqs, *req)
+	return response.Response{}, rr.err
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"strings"
 	"time"
 )
 
@@ -68,7 +69,22 @@ func WithHeaders(headers map[string][]string) Option {
 func WithHeader(key, value string) Option {
 	return func(req Request) Request {
 		newReq := req.Clone()
-		newReq.Headers[key] = []string{value}
+		newReq.SetHeader(key, value)
+		return newReq
+	}
+}
+
+// WithoutHeader removes the given header (case-insensitive), if present, no matter
+// whether it is one of the default ones (see [Default]), or it was added later on.
+// Removing a header that is not present is a no-op.
+func WithoutHeader(key string) Option {
+	return func(req Request) Request {
+		newReq := req.Clone()
+		for k := range newReq.Headers {
+			if strings.EqualFold(k, key) {
+				newReq.DeleteHeader(k)
+			}
+		}
 		return newReq
 	}
 }
@@ -87,7 +103,7 @@ func WithData(data []byte) Option {
 	return func(req Request) Request {
 		newReq := req.Clone()
 		newReq.SetBody(data)
-		newReq.Headers["Content-Type"] = []string{"application/x-www-form-urlencoded"}
+		newReq.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 		return newReq
 	}
 }
@@ -100,3 +116,14 @@ func WithTimeout(timeout time.Duration) Option {
 		return newReq
 	}
 }
+
+// WithLineEndings keeps the original line endings of the head of the given raw request (see
+// [ParseRequest]) as [Request.LineEndings], so the request line and the headers are sent with
+// those (e.g. bare LFs), instead of being normalized to CRLF. Useful for exact-byte replays.
+func WithLineEndings(raw []byte) Option {
+	return func(req Request) Request {
+		newReq := req.Clone()
+		newReq.LineEndings = lineEndings(raw)
+		return newReq
+	}
+}
@@ -63,6 +63,21 @@ func TestOption(t *testing.T) {
 		assert.NotEqual(t, req.Headers, newReq.Headers)
 	})
 
+	t.Run("WithoutHeader", func(t *testing.T) {
+		t.Parallel()
+		newReq := request.WithoutHeader("accept-encoding")(req)
+		assert.NotContains(t, newReq.Headers, "Accept-Encoding")
+		assert.NotContains(t, newReq.HeaderOrder, "Accept-Encoding")
+		assert.Contains(t, req.Headers, "Accept-Encoding")
+		assert.Len(t, newReq.Headers, len(req.Headers)-1)
+	})
+
+	t.Run("WithoutHeader not present", func(t *testing.T) {
+		t.Parallel()
+		newReq := request.WithoutHeader("Authorization")(req)
+		assert.Equal(t, req, newReq)
+	})
+
 	t.Run("WithBody", func(t *testing.T) {
 		t.Parallel()
 		newBody := []byte(`<xml><node name="nodename1">nodetext1</node><node name="nodename2">nodetext2</node></xml>
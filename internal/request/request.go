@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/textproto"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,7 +22,12 @@ import (
 	internalurl "github.com/bountysecurity/gbounty/kit/url"
 )
 
-const defaultTimeout = 20 * time.Second
+const (
+	defaultTimeout = 20 * time.Second
+
+	crlf = "\r\n"
+	lf   = "\n"
+)
 
 var (
 	// ErrInvalidHost is returned when building/parsing a request
@@ -41,12 +47,17 @@ type Request struct {
 	Path              string
 	Proto             string
 	Headers           map[string][]string
+	HeaderOrder       []string // Header keys, in insertion order, see [Request.HeaderKeys]
+	RawHeaders        []string // Verbatim framing headers, see [ParseRequest]
+	LineEndings       []string // Original line endings of the head, see [WithLineEndings]
 	Body              []byte
 	Timeout           time.Duration
 	RedirectType      profile.Redirect
 	MaxRedirects      int
 	FollowedRedirects int
 	Modifications     map[string]string
+	Mutations         []string // Mutations applied before being sent, see [scan.WithRequestMutators]
+	Canary            string   // Canary prepended to the payload injected, see [scan.Config.CanaryPrefix]
 }
 
 // Default is a named constructor to instantiate a new [Request] with the given
@@ -68,6 +79,7 @@ func Default(remote string) Request {
 			"User-Agent":      {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/89.0.4389.90 Safari/537.36"},
 			"Connection":      {"close"},
 		},
+		HeaderOrder: []string{"Host", "User-Agent", "Accept", "Accept-Language", "Accept-Encoding", "Connection"},
 		// Default values
 		Timeout:      defaultTimeout,
 		RedirectType: profile.RedirectNever,
@@ -84,8 +96,75 @@ func (r *Request) IsEmpty() bool {
 func (r *Request) SetBody(body []byte) {
 	r.Body = body
 	if len(r.Body) > 0 {
-		r.Headers["Content-Length"] = []string{strconv.Itoa(len(r.Body))}
+		r.SetHeader("Content-Length", strconv.Itoa(len(r.Body)))
+	}
+}
+
+// SetHeader sets the values of the given header, replacing any existing ones.
+// If the header is not present yet, it is appended to the [Request.HeaderOrder].
+func (r *Request) SetHeader(key string, values ...string) {
+	if r.Headers == nil {
+		r.Headers = make(map[string][]string)
+	}
+
+	if _, ok := r.Headers[key]; !ok && !slices.Contains(r.HeaderOrder, key) {
+		r.HeaderOrder = append(r.HeaderOrder, key)
+	}
+
+	r.Headers[key] = values
+}
+
+// DeleteHeader removes the given header, if present,
+// including its key from the [Request.HeaderOrder].
+func (r *Request) DeleteHeader(key string) {
+	delete(r.Headers, key)
+
+	if idx := slices.Index(r.HeaderOrder, key); idx >= 0 {
+		r.HeaderOrder = slices.Delete(r.HeaderOrder, idx, idx+1)
+	}
+}
+
+// HeaderKeys returns the keys of the [Request.Headers] in the order those are sent.
+// That is, first those present in the given order (case-insensitive), then those
+// tracked by the [Request.HeaderOrder] (i.e. in insertion order) and finally any
+// other header (sorted), like those set directly into the [Request.Headers] map.
+func (r *Request) HeaderKeys(order ...string) []string {
+	sorted := make([]string, 0, len(r.Headers))
+	for key := range r.Headers {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	keys := make([]string, 0, len(r.Headers))
+	seen := make(map[string]struct{}, len(r.Headers))
+	add := func(key string) {
+		if _, ok := seen[key]; ok {
+			return
+		}
+		if _, ok := r.Headers[key]; !ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	for _, name := range order {
+		for _, key := range sorted {
+			if strings.EqualFold(key, name) {
+				add(key)
+			}
+		}
+	}
+
+	for _, key := range r.HeaderOrder {
+		add(key)
+	}
+
+	for _, key := range sorted {
+		add(key)
 	}
+
+	return keys
 }
 
 // HasJSONBody returns whether the request body is a valid JSON.
@@ -193,11 +272,16 @@ func (r *Request) Clone() Request {
 		Path:          r.Path,
 		Proto:         r.Proto,
 		Headers:       copyHeaders(r.Headers),
+		HeaderOrder:   copyStrings(r.HeaderOrder),
+		RawHeaders:    copyStrings(r.RawHeaders),
+		LineEndings:   copyStrings(r.LineEndings),
 		Body:          copyBody(r.Body),
 		Timeout:       r.Timeout,
 		RedirectType:  r.RedirectType,
 		MaxRedirects:  r.MaxRedirects,
 		Modifications: copyModifications(r.Modifications),
+		Mutations:     copyStrings(r.Mutations),
+		Canary:        r.Canary,
 	}
 }
 
@@ -214,6 +298,16 @@ func copyHeaders(headers map[string][]string) map[string][]string {
 	return result
 }
 
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	result := make([]string, len(s))
+	copy(result, s)
+	return result
+}
+
 func copyBody(body []byte) []byte {
 	if body == nil {
 		return nil
@@ -269,6 +363,46 @@ func (r *Request) Bytes() []byte {
 	return []byte(ret)
 }
 
+// RawBytes returns the request as a byte slice, as it is sent. That is, with the headers
+// in order (see [Request.HeaderKeys]), one line per value, and the raw framing headers, if
+// any (see [Request.RawHeaders]). So, unlike [Request.Bytes], it can be parsed back into
+// the same request (see [ParseRequest]).
+//
+// Lines are ended with CRLF, unless the original line endings are kept (see [WithLineEndings]),
+// while the body is written as is.
+func (r *Request) RawBytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(r.Method + " " + r.Path + " " + r.Proto + LineEnding(r.LineEndings, 0, false))
+
+	line := 1
+	writeLine := func(s string) {
+		buf.WriteString(s + LineEnding(r.LineEndings, line, false))
+		line++
+	}
+
+	var rawWritten bool
+	for _, key := range r.HeaderKeys() {
+		if len(r.RawHeaders) > 0 && IsFramingHeader(key) {
+			if !rawWritten {
+				for _, raw := range r.RawHeaders {
+					writeLine(raw)
+				}
+				rawWritten = true
+			}
+			continue
+		}
+
+		for _, value := range r.Headers[key] {
+			writeLine(key + ": " + value)
+		}
+	}
+
+	buf.WriteString(LineEnding(r.LineEndings, line, true))
+	buf.Write(r.Body)
+
+	return buf.Bytes()
+}
+
 // EscapedBytes returns the request as a byte slice, with the body
 // escaped (i.e. JSON encoded).
 func (r *Request) EscapedBytes() []byte {
@@ -284,6 +418,15 @@ func (r *Request) EscapedBytes() []byte {
 
 // ParseRequest parses a request from a byte slice.
 // If a host is given (variadic arg), it is used as the request URL.
+//
+// Line endings of the request line and the headers are normalized, so either CRLF or bare LF
+// (or a mix of both) are accepted, and the requests are sent with CRLF, unless the original ones
+// are kept (see [WithLineEndings]). The body, instead, is kept byte by byte, bare LFs included.
+//
+// Headers are normalized (see [textproto.Reader.ReadMIMEHeader]), but their
+// original order is kept as [Request.HeaderOrder]. Additionally, if the framing headers (i.e. Content-Length and Transfer-Encoding) are
+// ambiguous (e.g. duplicated, conflicting or obfuscated), their verbatim
+// lines are also kept as [Request.RawHeaders], so they can be sent as is.
 func ParseRequest(b []byte, hh ...string) (Request, error) {
 	var hostStr string
 
@@ -326,6 +469,12 @@ func ParseRequest(b []byte, hh ...string) (Request, error) {
 		return Request{}, fmt.Errorf("%w: %s", ErrInvalidPayload, "wrong format")
 	}
 
+	var headerOrder, rawHeaders []string
+	if idx := bytes.Index(b, []byte("\n")); idx >= 0 {
+		headerOrder = headerKeysOrder(b[idx+len("\n"):])
+		rawHeaders = rawFramingHeaders(b[idx+len("\n"):])
+	}
+
 	headers, err := tp.ReadMIMEHeader()
 	if err != nil && !errors.Is(err, io.EOF) {
 		return Request{}, errors.Join(ErrInvalidPayload, err)
@@ -356,18 +505,112 @@ func ParseRequest(b []byte, hh ...string) (Request, error) {
 	}
 
 	return Request{
-		URL:     hostStr,
-		Method:  method,
-		Path:    path,
-		Proto:   proto,
-		Headers: headers,
-		Body:    body,
+		URL:         hostStr,
+		Method:      method,
+		Path:        path,
+		Proto:       proto,
+		Headers:     headers,
+		HeaderOrder: headerOrder,
+		RawHeaders:  rawHeaders,
+		Body:        body,
 		// Default values
 		Timeout:      defaultTimeout,
 		RedirectType: profile.RedirectNever,
 	}, nil
 }
 
+// framingHeaders are those headers that determine
+// where the request's body (and the request) ends.
+var framingHeaders = []string{"Content-Length", "Transfer-Encoding"}
+
+// IsFramingHeader returns whether the given header key is any of
+// the framing headers (i.e. Content-Length and Transfer-Encoding).
+func IsFramingHeader(key string) bool {
+	key = strings.TrimSpace(key)
+	for _, h := range framingHeaders {
+		if strings.EqualFold(key, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawFramingHeaders returns the verbatim lines of the framing headers (see [IsFramingHeader])
+// present in the given headers section, but only if those are ambiguous. That is, if they
+// are duplicated, conflicting or non-canonical (e.g. "Transfer-Encoding : chunked"), thus
+// their normalization would change the way the request is framed.
+func rawFramingHeaders(b []byte) []string {
+	var (
+		lines     []string
+		ambiguous bool
+		framing   bool
+	)
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) == 0 {
+			break
+		}
+
+		// Obsolete line folding (continuation of the previous header).
+		if line[0] == ' ' || line[0] == '\t' {
+			if framing {
+				lines = append(lines, line)
+				ambiguous = true
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		if framing = IsFramingHeader(key); !framing {
+			continue
+		}
+
+		if line != textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))+": "+strings.TrimSpace(value) {
+			ambiguous = true
+		}
+
+		lines = append(lines, line)
+	}
+
+	if !ambiguous && len(lines) < 2 {
+		return nil
+	}
+
+	return lines
+}
+
+// headerKeysOrder returns the (canonical) keys of the headers present
+// in the given headers section, in the same order they appear, with no
+// duplicates.
+func headerKeysOrder(b []byte) []string {
+	var keys []string
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) == 0 {
+			break
+		}
+
+		// Obsolete line folding (continuation of the previous header).
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		key, _, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		// Same as [textproto.Reader.ReadMIMEHeader] does.
+		if key = textproto.CanonicalMIMEHeaderKey(key); !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 func parseRequestLine(line string) (method, requestURI, proto string, ok bool) {
 	s1 := strings.Index(line, " ")
 	s2 := strings.Index(line[s1+1:], " ")
@@ -382,23 +625,73 @@ func parseRequestLine(line string) (method, requestURI, proto string, ok bool) {
 }
 
 func readBody(tp *textproto.Reader) ([]byte, error) {
-	var reqBytes []byte
+	// The remaining bytes are read as a whole, as a single read may return
+	// less bytes than available (e.g. only those buffered), and so truncate it.
+	body, err := io.ReadAll(tp.R)
+	if len(body) == 0 {
+		return nil, err
+	}
 
-	for {
-		const buffSize = 1024
-		recvBuf := make([]byte, buffSize)
+	return body, err
+}
 
-		n, err := tp.R.Read(recvBuf)
-		if err != nil {
-			return nil, err
-		}
+// LineEnding returns the line ending of the i-th line of a request's head (i.e. 0 is the request
+// line, and the following ones are the headers), or the one of the blank line that ends the head,
+// if last, from the given line endings (see [Request.LineEndings]). Those lines with no original
+// line ending (e.g. the headers added afterwards), or if there are none, are ended with CRLF.
+func LineEnding(endings []string, i int, last bool) string {
+	if len(endings) == 0 {
+		return crlf
+	}
 
-		reqBytes = append(reqBytes, recvBuf[:n]...)
+	if last {
+		return endings[len(endings)-1]
+	}
+
+	// The last one is reserved for the blank line.
+	if i < len(endings)-1 {
+		return endings[i]
+	}
 
-		if n < len(recvBuf) {
+	return crlf
+}
+
+// lineEndings returns the line endings of the head of the given raw request, as parsed by
+// [ParseRequest], in order: those of the request line, of every header line, and of the blank
+// line that ends the head. If there is no such blank line, the last line ending is repeated.
+func lineEndings(b []byte) []string {
+	// The first line is skipped if it is the URL, see [ParseRequest].
+	if idx := bytes.IndexByte(b, '\n'); idx >= 0 && strings.HasPrefix(strings.TrimSpace(string(b[:idx])), "http") {
+		b = b[idx+1:]
+	}
+
+	var endings []string
+
+	for {
+		idx := bytes.IndexByte(b, '\n')
+		if idx < 0 {
 			break
 		}
+
+		line := b[:idx]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+			endings = append(endings, crlf)
+		} else {
+			endings = append(endings, lf)
+		}
+
+		// The blank line ends the head.
+		if len(line) == 0 {
+			return endings
+		}
+
+		b = b[idx+1:]
+	}
+
+	if len(endings) > 0 {
+		endings = append(endings, endings[len(endings)-1])
 	}
 
-	return reqBytes, nil
+	return endings
 }
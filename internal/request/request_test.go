@@ -1,7 +1,9 @@
 package request_test
 
 import (
+	"encoding/base64"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,6 +42,10 @@ func Test_ParseRequest(t *testing.T) {
 				"Referer":                   {"http://testphp.vulnweb.com/search.php?test=query"},
 				"Upgrade-Insecure-Requests": {"1"},
 			},
+			HeaderOrder: []string{
+				"Accept", "Accept-Encoding", "Accept-Language", "Connection", "Content-Length", "Content-Type",
+				"Dnt", "Host", "Origin", "Referer", "Upgrade-Insecure-Requests", "User-Agent",
+			},
 			Timeout:      20 * time.Second,
 			RedirectType: profile.RedirectNever,
 			MaxRedirects: 0,
@@ -71,6 +77,10 @@ func Test_ParseRequest(t *testing.T) {
 				"Referer":                   {"http://testphp.vulnweb.com/search.php?test=query"},
 				"Upgrade-Insecure-Requests": {"1"},
 			},
+			HeaderOrder: []string{
+				"Accept", "Accept-Encoding", "Accept-Language", "Connection", "Content-Length", "Content-Type",
+				"Dnt", "Host", "Origin", "Referer", "Upgrade-Insecure-Requests", "User-Agent",
+			},
 			Timeout:      20 * time.Second,
 			RedirectType: profile.RedirectNever,
 			MaxRedirects: 0,
@@ -102,6 +112,10 @@ func Test_ParseRequest(t *testing.T) {
 				"Referer":                   {"http://testphp.vulnweb.com/search.php?test=query"},
 				"Upgrade-Insecure-Requests": {"1"},
 			},
+			HeaderOrder: []string{
+				"Accept", "Accept-Encoding", "Accept-Language", "Connection", "Content-Length", "Content-Type",
+				"Dnt", "Host", "Origin", "Referer", "Upgrade-Insecure-Requests", "User-Agent",
+			},
 			Timeout:      20 * time.Second,
 			RedirectType: profile.RedirectNever,
 			MaxRedirects: 0,
@@ -136,6 +150,10 @@ func Test_ParseRequest(t *testing.T) {
 			Body: []byte(`searchFor=test&goButton=go
 
 `),
+			HeaderOrder: []string{
+				"Accept", "Accept-Encoding", "Accept-Language", "Connection", "Content-Length", "Content-Type",
+				"Dnt", "Host", "Origin", "Referer", "Upgrade-Insecure-Requests", "User-Agent",
+			},
 			Timeout:      20 * time.Second,
 			RedirectType: profile.RedirectNever,
 			MaxRedirects: 0,
@@ -143,6 +161,178 @@ func Test_ParseRequest(t *testing.T) {
 	})
 }
 
+func Test_ParseRequest_RawHeaders(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		headers string
+		exp     []string
+	}{
+		"canonical": {
+			headers: "Content-Length: 6\r\n",
+			exp:     nil,
+		},
+		"conflicting": {
+			headers: "Content-Length: 6\r\nTransfer-Encoding: chunked\r\n",
+			exp:     []string{"Content-Length: 6", "Transfer-Encoding: chunked"},
+		},
+		"duplicated": {
+			headers: "Content-Length: 6\r\nContent-Length: 0\r\n",
+			exp:     []string{"Content-Length: 6", "Content-Length: 0"},
+		},
+		"obfuscated": {
+			headers: "Transfer-Encoding : chunked\r\n",
+			exp:     []string{"Transfer-Encoding : chunked"},
+		},
+		"folded": {
+			headers: "Transfer-Encoding: x\r\n chunked\r\n",
+			exp:     []string{"Transfer-Encoding: x", " chunked"},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			raw := "POST / HTTP/1.1\r\nHost: localhost:8080\r\n" + tc.headers + "\r\n0\r\n\r\nG"
+
+			req, err := request.ParseRequest([]byte(raw))
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, req.RawHeaders)
+
+			clone := req.Clone()
+			assert.Equal(t, req.RawHeaders, clone.RawHeaders)
+		})
+	}
+}
+
+func Test_ParseRequest_LineEndings(t *testing.T) {
+	t.Parallel()
+
+	largeBody := strings.Repeat("key=value\n", 1000)
+
+	tcs := map[string]struct {
+		raw      string
+		expBody  string
+		expBytes string
+	}{
+		"crlf": {
+			raw:      "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nbody",
+			expBody:  "body",
+			expBytes: "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nbody",
+		},
+		"bare lf": {
+			raw:      "POST / HTTP/1.1\nHost: localhost\nX-Test: a\n\nbody",
+			expBody:  "body",
+			expBytes: "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nbody",
+		},
+		"mixed endings": {
+			raw:      "POST / HTTP/1.1\nHost: localhost\r\nX-Test: a\n\r\nbody",
+			expBody:  "body",
+			expBytes: "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nbody",
+		},
+		"body with bare lfs": {
+			raw:      "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nline1\nline2\r\nline3\n",
+			expBody:  "line1\nline2\r\nline3\n",
+			expBytes: "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\nline1\nline2\r\nline3\n",
+		},
+		"large body with bare lfs": {
+			raw:      "POST / HTTP/1.1\nHost: localhost\nX-Test: a\n\n" + largeBody,
+			expBody:  largeBody,
+			expBytes: "POST / HTTP/1.1\r\nHost: localhost\r\nX-Test: a\r\n\r\n" + largeBody,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := request.ParseRequest([]byte(tc.raw))
+			require.NoError(t, err)
+
+			assert.Equal(t, "POST", req.Method)
+			assert.Equal(t, "HTTP/1.1", req.Proto)
+			assert.Equal(t, "a", req.Header("X-Test"))
+			assert.Equal(t, tc.expBody, string(req.Body))
+			assert.Empty(t, req.LineEndings)
+			assert.Equal(t, tc.expBytes, string(req.RawBytes()))
+
+			// The original line endings are kept, so the request is sent byte by byte.
+			preserved := request.WithLineEndings([]byte(tc.raw))(req)
+			assert.Equal(t, tc.raw, string(preserved.RawBytes()))
+
+			clone := preserved.Clone()
+			assert.Equal(t, preserved.LineEndings, clone.LineEndings)
+		})
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	t.Parallel()
+
+	raw := "https://example.org\nGET / HTTP/1.1\nHost: example.org\r\n\n"
+
+	req, err := request.ParseRequest([]byte(raw))
+	require.NoError(t, err)
+
+	req = request.WithLineEndings([]byte(raw))(req)
+	assert.Equal(t, []string{"\n", "\r\n", "\n"}, req.LineEndings)
+
+	// Headers added afterwards are ended with CRLF.
+	req.SetHeader("X-Added", "1")
+	assert.Equal(t, "GET / HTTP/1.1\nHost: example.org\r\nX-Added: 1\r\n\n", string(req.RawBytes()))
+
+	assert.Equal(t, "\r\n", request.LineEnding(nil, 0, false))
+	assert.Equal(t, "\r\n", request.LineEnding(nil, 0, true))
+}
+
+func TestRequest_HeaderKeys(t *testing.T) {
+	t.Parallel()
+
+	raw := "GET / HTTP/1.1\r\nuser-agent: gbounty\r\nHost: localhost\r\nX-Custom: a\r\nX-Custom: b\r\nAccept: */*\r\n\r\n"
+
+	req, err := request.ParseRequest([]byte(raw))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"User-Agent", "Host", "X-Custom", "Accept"}, req.HeaderKeys())
+
+	req.SetHeader("Cookie", "session=1")
+	req.Headers["Authorization"] = []string{"Bearer token"}
+	delete(req.Headers, "X-Custom")
+	assert.Equal(t, []string{"User-Agent", "Host", "Accept", "Cookie", "Authorization"}, req.HeaderKeys())
+
+	assert.Equal(t,
+		[]string{"Host", "Accept", "User-Agent", "Cookie", "Authorization"},
+		req.HeaderKeys("host", "ACCEPT", "Missing"),
+	)
+}
+
+func TestRequest_SetWebSocketUpgrade(t *testing.T) {
+	t.Parallel()
+
+	req, err := request.ParseRequest(rawReqWithBody())
+	require.NoError(t, err)
+
+	req.SetHeader("Connection", "keep-alive")
+	req.SetHeader("Sec-Websocket-Version", "8")
+	req.SetWebSocketUpgrade("graphql-ws", "chat")
+
+	assert.Equal(t, "GET", req.Method)
+	assert.Empty(t, req.Body)
+	assert.Empty(t, req.Header("Content-Length"))
+
+	assert.Equal(t, "websocket", req.Header("Upgrade"))
+	assert.Equal(t, "Upgrade", req.Header("Connection"))
+	assert.Equal(t, "13", req.Header("Sec-WebSocket-Version"))
+	assert.Equal(t, "graphql-ws, chat", req.Header("Sec-WebSocket-Protocol"))
+	assert.NotContains(t, req.Headers, "Sec-Websocket-Version")
+
+	key, err := base64.StdEncoding.DecodeString(req.Header("Sec-WebSocket-Key"))
+	require.NoError(t, err)
+	assert.Len(t, key, 16)
+}
+
 func TestRequest_Clone(t *testing.T) {
 	t.Parallel()
 
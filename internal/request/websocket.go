@@ -0,0 +1,53 @@
+package request
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// webSocketVersion is the only WebSocket protocol version defined (see RFC 6455, section 4.1).
+const webSocketVersion = "13"
+
+// SetWebSocketUpgrade turns the request into a WebSocket opening handshake (see RFC 6455,
+// section 4.1): a GET request, with no body, and the Upgrade, Connection, Sec-WebSocket-Key
+// (random) and Sec-WebSocket-Version headers, plus the Sec-WebSocket-Protocol one with
+// the given subprotocols, if any, offered to the server.
+func (r *Request) SetWebSocketUpgrade(protocols ...string) {
+	r.Method = http.MethodGet
+	r.Body = nil
+	r.DeleteHeader("Content-Length")
+	r.DeleteHeader("Content-Type")
+	r.DeleteHeader("Transfer-Encoding")
+
+	r.setWebSocketHeader("Upgrade", "websocket")
+	r.setWebSocketHeader("Connection", "Upgrade")
+	r.setWebSocketHeader("Sec-WebSocket-Key", webSocketKey())
+	r.setWebSocketHeader("Sec-WebSocket-Version", webSocketVersion)
+
+	if len(protocols) > 0 {
+		r.setWebSocketHeader("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+}
+
+// setWebSocketHeader sets the given header, with its usual casing (e.g. Sec-WebSocket-Key),
+// replacing the existing one, if any, which is stored in its canonical form when parsed.
+func (r *Request) setWebSocketHeader(key, value string) {
+	if canonical := textproto.CanonicalMIMEHeaderKey(key); canonical != key {
+		r.DeleteHeader(canonical)
+	}
+
+	r.SetHeader(key, value)
+}
+
+// webSocketKey returns a random, base64-encoded, 16-byte nonce, used as the Sec-WebSocket-Key.
+func webSocketKey() string {
+	const nonceSize = 16
+
+	nonce := make([]byte, nonceSize)
+	_, _ = rand.Read(nonce)
+
+	return base64.StdEncoding.EncodeToString(nonce)
+}
@@ -0,0 +1,173 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+var (
+	// ErrRequestSkipped is the error returned by a [Requester] when a request is deliberately not sent
+	// (e.g. see [RequestHookSkip]), so it is reported as skipped, instead of as a failed request.
+	ErrRequestSkipped = errors.New("request skipped")
+	// ErrRequestHookFailed is the error returned when the [RequestHook] command fails (e.g. it
+	// exits with a non-zero status, times out, or writes an invalid request), so the request is skipped.
+	ErrRequestHookFailed = errors.New("request hook failed")
+	// ErrUnknownRequestHookFailureMode is the error returned by [RequestHookFailureModeFrom]
+	// when the given name doesn't correspond to any of the available failure modes.
+	ErrUnknownRequestHookFailureMode = errors.New("unknown request hook failure mode")
+)
+
+// DefaultRequestHookTimeout is the maximum duration of each [RequestHook] command execution.
+const DefaultRequestHookTimeout = 10 * time.Second
+
+// requestHookWaitDelay is the time waited for the output of the [RequestHook] command to be
+// closed once it has been killed (e.g. timed out), in case it was inherited by any subprocess.
+const requestHookWaitDelay = time.Second
+
+// RequestHookFailureMode determines what happens to a request when its [RequestHook] fails,
+// either [RequestHookSkip] (i.e. it isn't sent) or [RequestHookSend] (i.e. it's sent unmodified).
+type RequestHookFailureMode string
+
+const (
+	RequestHookSkip RequestHookFailureMode = "skip"
+	RequestHookSend RequestHookFailureMode = "send"
+)
+
+// RequestHookFailureModeFrom returns the [RequestHookFailureMode] with the given name,
+// either "skip" (see [RequestHookSkip]) or "send" (see [RequestHookSend]).
+func RequestHookFailureModeFrom(name string) (RequestHookFailureMode, error) {
+	switch mode := RequestHookFailureMode(name); mode {
+	case RequestHookSkip, RequestHookSend:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %s (valid ones are: %s, %s)", ErrUnknownRequestHookFailureMode, name, RequestHookSkip, RequestHookSend)
+	}
+}
+
+// RequestHook is an external command every request is piped through right before being sent,
+// for custom per-request logic (e.g. re-signing it, or solving a challenge). The command is
+// executed directly (i.e. not through a shell), with the given arguments, once per request.
+//
+// The request is written into the command's standard input as a raw request, preceded by
+// its URL on the first line (the same format accepted for request files), and the command
+// is expected to write the request to actually send into its standard output, with the
+// same format (the URL line is optional, the original one is kept if missing).
+//
+// The command is killed once the Timeout is exceeded, if positive. If the command fails
+// (see [ErrRequestHookFailed]), the request is either skipped or sent unmodified, depending
+// on the FailureMode. Use [WithRequestHook] to apply it to the requests sent.
+type RequestHook struct {
+	Command     string
+	Args        []string
+	Timeout     time.Duration
+	FailureMode RequestHookFailureMode
+}
+
+// Apply runs the hook command with the given request, and replaces the request's
+// line, headers and body with those written by the command. The request is left
+// untouched if the command fails, so it can still be sent unmodified.
+func (h RequestHook) Apply(ctx context.Context, req *request.Request) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	//nolint:gosec // The command is explicitly given by the user (see --request-hook).
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Stdin = bytes.NewReader(hookInput(req))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = requestHookWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("%w: %s: %s", ErrRequestHookFailed, err.Error(), msg)
+		}
+		return fmt.Errorf("%w: %s", ErrRequestHookFailed, err.Error())
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return fmt.Errorf("%w: empty output", ErrRequestHookFailed)
+	}
+
+	hooked, err := request.ParseRequest(stdout.Bytes(), req.URL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid output: %s", ErrRequestHookFailed, err.Error())
+	}
+
+	req.URL = hooked.URL
+	req.Method = hooked.Method
+	req.Path = hooked.Path
+	req.Proto = hooked.Proto
+	req.Headers = hooked.Headers
+	req.HeaderOrder = hooked.HeaderOrder
+	req.RawHeaders = hooked.RawHeaders
+	req.LineEndings = nil
+	req.Body = hooked.Body
+
+	return nil
+}
+
+// hookInput returns the given request as written into the [RequestHook] command's standard
+// input. Redirects followed to absolute locations have no path (see [shouldFollowRedirect]),
+// as it is taken from the URL when sent, so it is taken from the URL here too.
+func hookInput(req *request.Request) []byte {
+	raw := req.Clone()
+	if len(raw.Path) == 0 {
+		if u, err := url.Parse(raw.URL); err == nil {
+			raw.Path = u.RequestURI()
+		}
+	}
+
+	return append([]byte(raw.URL+"\n"), raw.RawBytes()...)
+}
+
+// WithRequestHook decorates the given [RequesterBuilder], so every request sent through the
+// built [Requester] (redirects followed included) is piped through the given [RequestHook]
+// right before being sent. If the hook fails, the failure is logged and the request is either
+// skipped (i.e. the error is returned) or sent unmodified, depending on its [RequestHookFailureMode].
+func WithRequestHook(fn RequesterBuilder, hook RequestHook) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return hookRequester{Requester: requester, hook: hook}, nil
+	}
+}
+
+type hookRequester struct {
+	Requester
+	hook RequestHook
+}
+
+func (r hookRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	if err := r.hook.Apply(ctx, req); err != nil {
+		if r.hook.FailureMode == RequestHookSend {
+			logger.For(ctx).Warnf("Request hook failed, request to %s sent unmodified: %s", req.URL, err.Error())
+			return r.Requester.Do(ctx, req)
+		}
+
+		logger.For(ctx).Warnf("Request hook failed, request to %s skipped: %s", req.URL, err.Error())
+		return response.Response{}, fmt.Errorf("%w: %w", ErrRequestSkipped, err)
+	}
+
+	return r.Requester.Do(ctx, req)
+}
@@ -0,0 +1,154 @@
+package scan_test
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestRequestHookFailureModeFrom(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"skip", "send"} {
+		mode, err := scan.RequestHookFailureModeFrom(name)
+		require.NoError(t, err)
+		assert.Equal(t, scan.RequestHookFailureMode(name), mode)
+	}
+
+	_, err := scan.RequestHookFailureModeFrom("retry")
+	require.ErrorIs(t, err, scan.ErrUnknownRequestHookFailureMode)
+}
+
+func TestRequestHook_Apply(t *testing.T) {
+	t.Parallel()
+
+	sh := shellOrSkip(t)
+
+	tcs := map[string]struct {
+		script  string
+		timeout time.Duration
+		err     bool
+		url     string
+		path    string
+		header  string
+		body    string
+	}{
+		"signed": {
+			script: `sed 's/^User-Agent: .*/X-Signature: signed\r/'`,
+			url:    "http://example.org/path?a=1", path: "/path?a=1", header: "signed",
+		},
+		"url line dropped": {
+			script: `sed '1d; s/^User-Agent: .*/X-Signature: signed\r/'`,
+			url:    "http://example.org/path?a=1", path: "/path?a=1", header: "signed",
+		},
+		"url and body replaced": {
+			script: `cat >/dev/null; printf 'http://example.com\nPOST /signed HTTP/1.1\r\nHost: example.com\r\nContent-Length: 4\r\n\r\nbody'`,
+			url:    "http://example.com", path: "/signed", body: "body",
+		},
+		"non-zero exit":  {script: `echo "invalid key" >&2; exit 3`, err: true},
+		"empty output":   {script: `cat >/dev/null`, err: true},
+		"invalid output": {script: `cat >/dev/null; echo not-a-request`, err: true},
+		"timed out":      {script: `sleep 5`, timeout: 100 * time.Millisecond, err: true},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hook := scan.RequestHook{Command: sh, Args: []string{"-c", tc.script}, Timeout: tc.timeout}
+			if hook.Timeout == 0 {
+				hook.Timeout = 5 * time.Second
+			}
+
+			req := request.Default("http://example.org/path?a=1")
+			orig := req.Clone()
+
+			start := time.Now()
+			err := hook.Apply(context.Background(), &req)
+			assert.Less(t, time.Since(start), 4*time.Second)
+
+			if tc.err {
+				require.ErrorIs(t, err, scan.ErrRequestHookFailed)
+				assert.Equal(t, orig, req)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.url, req.URL)
+			assert.Equal(t, tc.path, req.Path)
+			assert.Equal(t, tc.header, req.Header("X-Signature"))
+			assert.Equal(t, tc.body, string(req.Body))
+		})
+	}
+}
+
+func TestWithRequestHook(t *testing.T) {
+	t.Parallel()
+
+	sh := shellOrSkip(t)
+
+	tcs := map[string]struct {
+		mode     scan.RequestHookFailureMode
+		script   string
+		sent     bool
+		expected string
+	}{
+		"applied":            {mode: scan.RequestHookSkip, script: `sed 's#^http://example.org/#http://example.com/#'`, sent: true, expected: "http://example.com/"},
+		"failed, skipped":    {mode: scan.RequestHookSkip, script: `exit 1`},
+		"failed, sent as is": {mode: scan.RequestHookSend, script: `exit 1`, sent: true, expected: "http://example.org/"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			requester := &recordingRequester{}
+			builder := scan.WithRequestHook(func() (scan.Requester, error) {
+				return requester, nil
+			}, scan.RequestHook{Command: sh, Args: []string{"-c", tc.script}, Timeout: 5 * time.Second, FailureMode: tc.mode})
+
+			r, err := builder()
+			require.NoError(t, err)
+
+			req := request.Default("http://example.org/")
+			_, err = r.Do(context.Background(), &req)
+
+			if !tc.sent {
+				require.ErrorIs(t, err, scan.ErrRequestHookFailed)
+				require.ErrorIs(t, err, scan.ErrRequestSkipped)
+				assert.Empty(t, requester.urls)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{tc.expected}, requester.urls)
+		})
+	}
+}
+
+// shellOrSkip returns the path of the POSIX shell used to run
+// the request hooks under test, or skips the test if there's none.
+func shellOrSkip(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("request hooks are tested with a POSIX shell")
+	}
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("request hooks are tested with a POSIX shell")
+	}
+
+	return sh
+}
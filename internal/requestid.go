@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// ErrUnknownRequestIDGenerator is the error returned by [RequestIDGeneratorFrom]
+// when the given name doesn't correspond to any of the available generators.
+var ErrUnknownRequestIDGenerator = errors.New("unknown request id generator")
+
+// RequestIDGenerator is a function that returns a new value every time it is called,
+// used to identify each of the requests sent during a [scan] (see [WithRequestID]).
+//
+// As requests are sent concurrently, it must be safe for concurrent use.
+type RequestIDGenerator func() string
+
+// RequestIDGeneratorFrom returns the [RequestIDGenerator] with the given name,
+// either "sequence" (see [SequenceRequestIDs]), "uuid" (see [UUIDRequestIDs])
+// or "timestamp" (see [TimestampRequestIDs]).
+func RequestIDGeneratorFrom(name string) (RequestIDGenerator, error) {
+	switch name {
+	case "sequence":
+		return SequenceRequestIDs(), nil
+	case "uuid":
+		return UUIDRequestIDs(), nil
+	case "timestamp":
+		return TimestampRequestIDs(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s (valid ones are: sequence, uuid or timestamp)", ErrUnknownRequestIDGenerator, name)
+	}
+}
+
+// SequenceRequestIDs returns a [RequestIDGenerator] that yields
+// an incrementing sequence number (1, 2, 3...).
+func SequenceRequestIDs() RequestIDGenerator {
+	var seq atomic.Uint64
+
+	return func() string {
+		return strconv.FormatUint(seq.Add(1), 10)
+	}
+}
+
+// UUIDRequestIDs returns a [RequestIDGenerator] that yields a random (v4) UUID.
+func UUIDRequestIDs() RequestIDGenerator {
+	return func() string {
+		return uuid.NewString()
+	}
+}
+
+// TimestampRequestIDs returns a [RequestIDGenerator] that yields the current
+// Unix time, in nanoseconds. Values are strictly increasing, so when two
+// requests are sent at the same nanosecond, the latter gets the next one.
+func TimestampRequestIDs() RequestIDGenerator {
+	var last atomic.Int64
+
+	return func() string {
+		for {
+			prev, now := last.Load(), time.Now().UnixNano()
+			if now <= prev {
+				now = prev + 1
+			}
+
+			if last.CompareAndSwap(prev, now) {
+				return strconv.FormatInt(now, 10)
+			}
+		}
+	}
+}
+
+// WithRequestID decorates the given [RequesterBuilder], so every request sent
+// through the built [Requester] carries the given header, set to a new value from
+// the given [RequestIDGenerator]. So, requests can be correlated with server logs.
+//
+// The header is set into the given request, so it is also present on the requests
+// attached to the scan results, e.g. [Match.Requests]. Each redirect followed
+// is a different request, so it gets a different value.
+func WithRequestID(fn RequesterBuilder, header string, gen RequestIDGenerator) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return requestIDRequester{Requester: requester, header: header, gen: gen}, nil
+	}
+}
+
+type requestIDRequester struct {
+	Requester
+	header string
+	gen    RequestIDGenerator
+}
+
+func (r requestIDRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	req.SetHeader(r.header, r.gen())
+
+	return r.Requester.Do(ctx, req)
+}
+
+// RequestIDs returns the values of the given (request id) header from the
+// given requests, in the same order, skipping those with no such header.
+func RequestIDs(reqs []*request.Request, header string) []string {
+	var ids []string
+	for _, req := range reqs {
+		if req == nil || req.Headers == nil {
+			continue
+		}
+
+		if values := req.Headers[header]; len(values) > 0 {
+			ids = append(ids, values[0])
+		}
+	}
+
+	return ids
+}
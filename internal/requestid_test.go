@@ -0,0 +1,86 @@
+package scan_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestRequestIDGeneratorFrom(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"sequence", "uuid", "timestamp"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gen, err := scan.RequestIDGeneratorFrom(name)
+			require.NoError(t, err)
+
+			// Values must be unique, even when generated concurrently.
+			const workers, perWorker = 8, 250
+
+			var (
+				mu   sync.Mutex
+				seen = make(map[string]struct{}, workers*perWorker)
+				wg   sync.WaitGroup
+			)
+
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perWorker; j++ {
+						id := gen()
+						mu.Lock()
+						seen[id] = struct{}{}
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Len(t, seen, workers*perWorker)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := scan.RequestIDGeneratorFrom("random")
+		require.ErrorIs(t, err, scan.ErrUnknownRequestIDGenerator)
+	})
+}
+
+func TestWithRequestID(t *testing.T) {
+	t.Parallel()
+
+	requester := &recordingRequester{}
+	builder := scan.WithRequestID(func() (scan.Requester, error) {
+		return requester, nil
+	}, "X-Req-Id", scan.SequenceRequestIDs())
+
+	reqs := make([]*request.Request, 0, 3)
+	for i := 0; i < 3; i++ {
+		req := request.Default("http://example.org/")
+
+		r, err := builder()
+		require.NoError(t, err)
+
+		_, err = r.Do(context.Background(), &req)
+		require.NoError(t, err)
+
+		reqs = append(reqs, &req)
+	}
+
+	// The header is kept in the requests sent, so it can be attached to the findings.
+	assert.Equal(t, []string{"1", "2", "3"}, scan.RequestIDs(reqs, "X-Req-Id"))
+	assert.Equal(t, "X-Req-Id", reqs[0].HeaderOrder[len(reqs[0].HeaderOrder)-1])
+	assert.Empty(t, scan.RequestIDs(reqs, "X-Other-Id"))
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"net/textproto"
 	"sort"
 	"strconv"
@@ -33,6 +34,15 @@ type Response struct {
 	Headers map[string][]string
 	Body    []byte
 	Time    time.Duration
+
+	// DecompressionTruncated determines whether the body was truncated while being decompressed,
+	// as it exceeded the maximum decompressed size (e.g. a decompression bomb), so only its first
+	// bytes (up to that size) are kept.
+	DecompressionTruncated bool `json:",omitempty"`
+
+	// RawHeaders is the status line and the headers, as received on the wire (i.e. before
+	// being parsed), so malformed (or split) lines can be inspected. It is not persisted.
+	RawHeaders []byte `json:"-"`
 }
 
 // Location returns the Location header value.
@@ -82,6 +92,13 @@ func (r Response) EscapedBytes() []byte {
 	return escaped
 }
 
+// BodyOffset returns the offset of the response body within [Response.Bytes]: the
+// body is at the end of the response, so that's where the occurrences found on it
+// (e.g. by the body-only matchers) start from.
+func (r Response) BodyOffset() int {
+	return len(r.Bytes()) - len(r.Body)
+}
+
 // BytesWithoutHeaders returns the response as a byte slice, without headers.
 func (r Response) BytesWithoutHeaders() []byte {
 	if r.IsEmpty() {
@@ -156,6 +173,23 @@ func (r Response) ContentType() string {
 	return strings.Split(h[0], ";")[0]
 }
 
+// MediaType returns the (lower-cased) media type of the response (e.g. image/png).
+// It uses the Content-Type header to determine the type, and, if not set, it sniffs
+// the type from the body (see [http.DetectContentType]).
+// If the response is empty, or it has neither Content-Type header nor body,
+// it returns an empty string.
+func (r Response) MediaType() string {
+	if ct := strings.TrimSpace(r.ContentType()); len(ct) > 0 {
+		return strings.ToLower(ct)
+	}
+
+	if len(r.Body) == 0 {
+		return ""
+	}
+
+	return strings.Split(http.DetectContentType(r.Body), ";")[0]
+}
+
 // InferredType returns the inferred type of the response.
 // It uses the Content-Type header to determine the type.
 // Some types are inferred are:
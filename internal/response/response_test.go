@@ -104,3 +104,48 @@ Content-Length: 0
 		}, res)
 	})
 }
+
+func TestResponse_MediaType(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		res response.Response
+		exp string
+	}{
+		"empty": {
+			res: response.Response{},
+			exp: "",
+		},
+		"content type header": {
+			res: response.Response{
+				Headers: map[string][]string{"Content-Type": {"Text/HTML; charset=utf-8"}},
+				Body:    []byte("{}"),
+			},
+			exp: "text/html",
+		},
+		"sniffed from body": {
+			res: response.Response{
+				Headers: map[string][]string{"Content-Length": {"8"}},
+				Body:    []byte("\x89PNG\x0D\x0A\x1A\x0A"),
+			},
+			exp: "image/png",
+		},
+		"no header nor body": {
+			res: response.Response{
+				Proto:   "HTTP/1.1",
+				Code:    204,
+				Status:  "No Content",
+				Headers: map[string][]string{},
+			},
+			exp: "",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.exp, tc.res.MediaType())
+		})
+	}
+}
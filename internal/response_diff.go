@@ -0,0 +1,248 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/textproto"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/strings/diff"
+)
+
+// VariantPlaceholder is the placeholder, within the template's request (i.e. path, headers
+// and body), that is replaced with the payload of each [ResponseVariant] (e.g. id=1{{variant}}).
+const VariantPlaceholder = "{{variant}}"
+
+// ResponseVariant is each of the two variants of every request sent while diffing
+// responses (see [ResponseDiffCfg]), defined by the headers set on the request (e.g.
+// X-Role: admin), and the payload the [VariantPlaceholder] is replaced with, if any.
+type ResponseVariant struct {
+	Headers [][2]string
+	Payload string
+}
+
+// Apply returns a copy of the given [request.Request] with the variant applied.
+func (v ResponseVariant) Apply(req request.Request) request.Request {
+	req = req.Clone()
+
+	req.Path = strings.ReplaceAll(req.Path, VariantPlaceholder, v.Payload)
+	for key, values := range req.Headers {
+		for i := range values {
+			values[i] = strings.ReplaceAll(values[i], VariantPlaceholder, v.Payload)
+		}
+		req.Headers[key] = values
+	}
+
+	if bytes.Contains(req.Body, []byte(VariantPlaceholder)) {
+		req.SetBody(bytes.ReplaceAll(req.Body, []byte(VariantPlaceholder), []byte(v.Payload)))
+	}
+
+	for _, h := range v.Headers {
+		req.SetHeader(h[0], h[1])
+	}
+
+	return req
+}
+
+// ResponseDiffCfg defines whether the responses to two variants (A and B) of every
+// request are diffed (see [DiffResponses]), and how these variants are built. Those
+// requests whose responses differ are reported as a [Match] (see [ResponseDiffOf]).
+type ResponseDiffCfg struct {
+	Enabled bool
+	A, B    ResponseVariant
+}
+
+// Clone returns a deep copy of the [ResponseDiffCfg] instance.
+func (c ResponseDiffCfg) Clone() ResponseDiffCfg {
+	return ResponseDiffCfg{
+		Enabled: c.Enabled,
+		A:       ResponseVariant{Headers: slices.Clone(c.A.Headers), Payload: c.A.Payload},
+		B:       ResponseVariant{Headers: slices.Clone(c.B.Headers), Payload: c.B.Payload},
+	}
+}
+
+// ResponseDiff is the (structured) difference between the responses to two
+// variants (A and B) of the same request (see [DiffResponses]).
+type ResponseDiff struct {
+	// StatusA and StatusB are the status codes of both responses.
+	StatusA, StatusB int
+	// HeadersRemoved and HeadersAdded are the headers (e.g. X-Role: admin)
+	// only present on the response to A, and to B, respectively.
+	HeadersRemoved []string
+	HeadersAdded   []string
+	// BodySummary summarizes the differences between both bodies, if any,
+	// either by the amount of lines changed (text) or by their size (binary).
+	BodySummary string
+	// BodyLines are the lines only present on either of the bodies, prefixed
+	// with - (A) or + (B), in order. Only for text bodies, up to maxResponseDiffLines.
+	BodyLines []string
+}
+
+// maxResponseDiffLines is the maximum amount of [ResponseDiff.BodyLines].
+const maxResponseDiffLines = 200
+
+// volatileHeaders are those headers ignored while diffing responses, as these are
+// likely to differ between any two responses, or are already covered by the body diff.
+var volatileHeaders = []string{"Age", "Content-Length", "Date", "Expires"}
+
+// DiffResponses returns the [ResponseDiff] between the given responses, to the variants A
+// and B of the same request. Headers are compared by key and value (e.g. a header changed
+// is both removed and added), with the exception of the volatile ones (e.g. Date). Bodies
+// are compared line by line if both are text, or by their size and hash otherwise.
+func DiffResponses(a, b *response.Response) ResponseDiff {
+	d := ResponseDiff{StatusA: a.Code, StatusB: b.Code}
+
+	headersA, headersB := headerLines(a), headerLines(b)
+	for _, h := range headersA {
+		if !slices.Contains(headersB, h) {
+			d.HeadersRemoved = append(d.HeadersRemoved, h)
+		}
+	}
+	for _, h := range headersB {
+		if !slices.Contains(headersA, h) {
+			d.HeadersAdded = append(d.HeadersAdded, h)
+		}
+	}
+
+	if bytes.Equal(a.Body, b.Body) {
+		return d
+	}
+
+	if isBinary(a.Body) || isBinary(b.Body) {
+		d.BodySummary = fmt.Sprintf("binary bodies differ: %s vs %s", bodyFingerprint(a.Body), bodyFingerprint(b.Body))
+		return d
+	}
+
+	var removed, added int
+	for _, l := range diff.Lines(string(a.Body), string(b.Body)) {
+		if l.Op == diff.Removed || l.Op == diff.Changed {
+			removed++
+			d.BodyLines = append(d.BodyLines, "- "+l.Left)
+		}
+		if l.Op == diff.Added || l.Op == diff.Changed {
+			added++
+			d.BodyLines = append(d.BodyLines, "+ "+l.Right)
+		}
+	}
+
+	if len(d.BodyLines) > maxResponseDiffLines {
+		more := len(d.BodyLines) - maxResponseDiffLines
+		d.BodyLines = append(d.BodyLines[:maxResponseDiffLines], fmt.Sprintf("... (%d more line(s))", more))
+	}
+
+	d.BodySummary = fmt.Sprintf("%d line(s) removed, %d line(s) added", removed, added)
+
+	return d
+}
+
+// IsEmpty returns whether both responses are equal (i.e. there are no differences).
+func (d ResponseDiff) IsEmpty() bool {
+	return d.StatusA == d.StatusB && len(d.HeadersRemoved) == 0 && len(d.HeadersAdded) == 0 && len(d.BodySummary) == 0
+}
+
+// Summary returns a single-line summary of the differences (e.g. status 200 -> 403,
+// 1 header(s) removed, 0 header(s) added, body: 3 line(s) removed, 1 line(s) added).
+func (d ResponseDiff) Summary() string {
+	parts := []string{fmt.Sprintf("status %d -> %d", d.StatusA, d.StatusB)}
+	if len(d.HeadersRemoved) > 0 || len(d.HeadersAdded) > 0 {
+		parts = append(parts, fmt.Sprintf("%d header(s) removed, %d header(s) added", len(d.HeadersRemoved), len(d.HeadersAdded)))
+	}
+	if len(d.BodySummary) > 0 {
+		parts = append(parts, "body: "+d.BodySummary)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Lines returns the differences as lines, in the form of a diff: the headers removed
+// and added, prefixed with - and +, respectively, followed by the body lines, if any.
+func (d ResponseDiff) Lines() []string {
+	lines := make([]string, 0, len(d.HeadersRemoved)+len(d.HeadersAdded)+len(d.BodyLines))
+	for _, h := range d.HeadersRemoved {
+		lines = append(lines, "- "+h)
+	}
+	for _, h := range d.HeadersAdded {
+		lines = append(lines, "+ "+h)
+	}
+	return append(lines, d.BodyLines...)
+}
+
+// headerLines returns the (non-volatile) headers of the given response,
+// as sorted lines (e.g. X-Role: admin), one per value.
+func headerLines(res *response.Response) []string {
+	var lines []string
+	for key, values := range res.Headers {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		if slices.Contains(volatileHeaders, key) {
+			continue
+		}
+		for _, v := range values {
+			lines = append(lines, key+": "+v)
+		}
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+func isBinary(b []byte) bool {
+	return !utf8.Valid(b) || bytes.IndexByte(b, 0) >= 0
+}
+
+// bodyFingerprint returns the size and the (truncated) hash of the given body.
+func bodyFingerprint(b []byte) string {
+	const hashLength = 12
+
+	sum := sha256.Sum256(b)
+
+	return fmt.Sprintf("%d bytes (sha256: %s)", len(b), hex.EncodeToString(sum[:])[:hashLength])
+}
+
+// responseDiffKey is the [context.Context] key for the [ResponseDiff] of a match.
+type responseDiffKey struct{}
+
+// withResponseDiff returns a copy of the given [context.Context] with the given
+// [ResponseDiff], so it can be attached to the match reported (see [ResponseDiffOf]).
+func withResponseDiff(ctx context.Context, d ResponseDiff) context.Context {
+	return context.WithValue(ctx, responseDiffKey{}, d)
+}
+
+// ResponseDiffOf returns the [ResponseDiff] of a match reported with the given
+// [context.Context], if any, or nil if it isn't a response diff match.
+func ResponseDiffOf(ctx context.Context) *ResponseDiff {
+	d, ok := ctx.Value(responseDiffKey{}).(ResponseDiff)
+	if !ok {
+		return nil
+	}
+	return &d
+}
+
+// responseDiffProfile is the (built-in) profile the matches
+// reported while diffing responses (see [ResponseDiffCfg]) belong to.
+type responseDiffProfile struct{}
+
+var (
+	_ profile.Profile          = responseDiffProfile{}
+	_ profile.IssueInformation = responseDiffProfile{}
+)
+
+func (responseDiffProfile) GetName() string       { return "Response diff" }
+func (responseDiffProfile) GetType() profile.Type { return profile.TypeActive }
+func (responseDiffProfile) IsEnabled() bool       { return true }
+func (responseDiffProfile) GetTags() []string     { return []string{"diff"} }
+
+func (responseDiffProfile) GetIssueName() string       { return "Response differs between variants" }
+func (responseDiffProfile) GetIssueSeverity() string   { return "Information" }
+func (responseDiffProfile) GetIssueConfidence() string { return "Certain" }
+func (responseDiffProfile) GetIssueDetail() string {
+	return "The responses to both variants (A and B) of the request differ. See the response diff for further details."
+}
+func (responseDiffProfile) GetIssueBackground() string       { return "" }
+func (responseDiffProfile) GetRemediationDetail() string     { return "" }
+func (responseDiffProfile) GetRemediationBackground() string { return "" }
@@ -0,0 +1,90 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestDiffResponses(t *testing.T) {
+	t.Parallel()
+
+	t.Run("equal responses", func(t *testing.T) {
+		t.Parallel()
+
+		a := &response.Response{Code: 200, Headers: map[string][]string{"Date": {"Mon"}}, Body: []byte("ok")}
+		b := &response.Response{Code: 200, Headers: map[string][]string{"Date": {"Tue"}}, Body: []byte("ok")}
+
+		d := scan.DiffResponses(a, b)
+		assert.True(t, d.IsEmpty())
+	})
+
+	t.Run("status, headers and text body", func(t *testing.T) {
+		t.Parallel()
+
+		a := &response.Response{
+			Code:    200,
+			Headers: map[string][]string{"X-Role": {"user"}, "Content-Type": {"text/plain"}},
+			Body:    []byte("hello\nuser\nbye"),
+		}
+		b := &response.Response{
+			Code:    403,
+			Headers: map[string][]string{"X-Role": {"admin"}, "Content-Type": {"text/plain"}},
+			Body:    []byte("hello\nadmin\nbye"),
+		}
+
+		d := scan.DiffResponses(a, b)
+		require.False(t, d.IsEmpty())
+
+		assert.Equal(t, 200, d.StatusA)
+		assert.Equal(t, 403, d.StatusB)
+		assert.Equal(t, []string{"X-Role: user"}, d.HeadersRemoved)
+		assert.Equal(t, []string{"X-Role: admin"}, d.HeadersAdded)
+		assert.Equal(t, []string{"- user", "+ admin"}, d.BodyLines)
+		assert.Equal(t, "1 line(s) removed, 1 line(s) added", d.BodySummary)
+		assert.Equal(t, "status 200 -> 403, 1 header(s) removed, 1 header(s) added, body: 1 line(s) removed, 1 line(s) added", d.Summary())
+		assert.Equal(t, []string{"- X-Role: user", "+ X-Role: admin", "- user", "+ admin"}, d.Lines())
+	})
+
+	t.Run("binary body", func(t *testing.T) {
+		t.Parallel()
+
+		a := &response.Response{Code: 200, Body: []byte{0x00, 0x01}}
+		b := &response.Response{Code: 200, Body: []byte{0x00, 0x02, 0x03}}
+
+		d := scan.DiffResponses(a, b)
+		assert.False(t, d.IsEmpty())
+		assert.Empty(t, d.BodyLines)
+		assert.Contains(t, d.BodySummary, "binary bodies differ: 2 bytes")
+		assert.Contains(t, d.BodySummary, "vs 3 bytes")
+	})
+}
+
+func TestResponseVariant_Apply(t *testing.T) {
+	t.Parallel()
+
+	req, err := request.ParseRequest([]byte("POST /items?id=1{{variant}} HTTP/1.1\r\n"+
+		"Host: example.org\r\n"+
+		"X-Ref: {{variant}}\r\n"+
+		"Content-Length: 14\r\n\r\n"+
+		"q=a{{variant}}"), "http://example.org")
+	require.NoError(t, err)
+
+	v := scan.ResponseVariant{Headers: [][2]string{{"X-Role", "admin"}}, Payload: "'"}
+	got := v.Apply(req)
+
+	assert.Equal(t, "/items?id=1'", got.Path)
+	assert.Equal(t, "'", got.Header("X-Ref"))
+	assert.Equal(t, "admin", got.Header("X-Role"))
+	assert.Equal(t, "q=a'", string(got.Body))
+	assert.Equal(t, "4", got.Header("Content-Length"))
+
+	// The original request is left untouched.
+	assert.Equal(t, "/items?id=1{{variant}}", req.Path)
+	assert.Empty(t, req.Header("X-Role"))
+}
@@ -0,0 +1,128 @@
+package scan
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// ResponseFilter defines which responses are evaluated by the matchers (and recorded)
+// at all, before the match pipeline, so the filtered ones save the matching work.
+// Unlike [MimeFilter], filtered responses are neither analyzed nor recorded.
+//
+// Both, Allow and Deny, are lists of status codes (e.g. 302) or classes (e.g. 4xx).
+// If Allow is non-empty, only those responses with an allowed status are evaluated.
+// Those with a denied status are never evaluated, even if allowed.
+//
+// If OnlyDiff, only those responses that differ from the per-URL baseline (i.e. the
+// first response received for the same URL) by status code or length are evaluated.
+type ResponseFilter struct {
+	Allow    []string
+	Deny     []string
+	OnlyDiff bool
+}
+
+// IsEmpty returns whether the [ResponseFilter] neither filters by status nor by baseline.
+func (f ResponseFilter) IsEmpty() bool {
+	return len(f.Allow) == 0 && len(f.Deny) == 0 && !f.OnlyDiff
+}
+
+// SkipsStatus returns whether the given [response.Response] must be skipped
+// (i.e. neither evaluated nor recorded) according to its status code.
+func (f ResponseFilter) SkipsStatus(res *response.Response) bool {
+	if res == nil {
+		return false
+	}
+
+	for _, status := range f.Deny {
+		if matchesStatus(status, res.Code) {
+			return true
+		}
+	}
+
+	if len(f.Allow) == 0 {
+		return false
+	}
+
+	for _, status := range f.Allow {
+		if matchesStatus(status, res.Code) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone returns a deep copy of the [ResponseFilter] instance.
+func (f ResponseFilter) Clone() ResponseFilter {
+	return ResponseFilter{
+		Allow:    cloneStrings(f.Allow),
+		Deny:     cloneStrings(f.Deny),
+		OnlyDiff: f.OnlyDiff,
+	}
+}
+
+// IsStatusFilter returns whether the given string is either
+// a status code (e.g. 302) or a status class (e.g. 4xx).
+func IsStatusFilter(s string) bool {
+	s = strings.ToLower(s)
+	if len(s) != len("200") {
+		return false
+	}
+
+	if class, wildcard := strings.CutSuffix(s, "xx"); wildcard {
+		return class >= "1" && class <= "5"
+	}
+
+	code, err := strconv.Atoi(s)
+	return err == nil && code >= 100 && code <= 599
+}
+
+func matchesStatus(status string, code int) bool {
+	status = strings.ToLower(status)
+	if class, wildcard := strings.CutSuffix(status, "xx"); wildcard {
+		return strconv.Itoa(code/100) == class //nolint:mnd
+	}
+
+	return status == strconv.Itoa(code)
+}
+
+// responseBaselines keeps the per-URL baseline responses (see [ResponseFilter.OnlyDiff]),
+// as their status code and length, so the responses are compared against those.
+type responseBaselines struct {
+	mu        sync.Mutex
+	baselines map[string]baselineResponse
+}
+
+type baselineResponse struct {
+	code, length int
+}
+
+func newResponseBaselines() *responseBaselines {
+	return &responseBaselines{baselines: make(map[string]baselineResponse)}
+}
+
+// differs returns whether the given [response.Response] differs from the baseline of
+// the given URL: either the status code is different, or the length differs by more
+// than 10%. The first response received for each URL is the baseline, so it differs.
+func (b *responseBaselines) differs(url string, res *response.Response) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	baseline, ok := b.baselines[url]
+	if !ok {
+		b.baselines[url] = baselineResponse{code: res.Code, length: res.Length()}
+		return true
+	}
+
+	const tenPercent = 10
+
+	diff := res.Length() - baseline.length
+	if diff < 0 {
+		diff *= -1
+	}
+
+	return res.Code != baseline.code || diff > baseline.length/tenPercent
+}
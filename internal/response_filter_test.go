@@ -0,0 +1,51 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestResponseFilter_SkipsStatus(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		filter scan.ResponseFilter
+		code   int
+		exp    bool
+	}{
+		"empty filter":        {filter: scan.ResponseFilter{}, code: 200, exp: false},
+		"allowed class":       {filter: scan.ResponseFilter{Allow: []string{"4xx", "5xx"}}, code: 503, exp: false},
+		"not allowed class":   {filter: scan.ResponseFilter{Allow: []string{"4xx", "5xx"}}, code: 200, exp: true},
+		"allowed code":        {filter: scan.ResponseFilter{Allow: []string{"4xx", "302"}}, code: 302, exp: false},
+		"not allowed code":    {filter: scan.ResponseFilter{Allow: []string{"302"}}, code: 301, exp: true},
+		"denied class":        {filter: scan.ResponseFilter{Deny: []string{"2xx"}}, code: 204, exp: true},
+		"not denied class":    {filter: scan.ResponseFilter{Deny: []string{"2xx"}}, code: 404, exp: false},
+		"allowed but denied":  {filter: scan.ResponseFilter{Allow: []string{"2xx"}, Deny: []string{"204"}}, code: 204, exp: true},
+		"upper case class":    {filter: scan.ResponseFilter{Allow: []string{"5XX"}}, code: 500, exp: false},
+		"only diff by status": {filter: scan.ResponseFilter{OnlyDiff: true}, code: 200, exp: false},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.exp, tc.filter.SkipsStatus(&response.Response{Proto: "HTTP/1.1", Code: tc.code}))
+		})
+	}
+}
+
+func TestIsStatusFilter(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"200", "404", "599", "1xx", "4xx", "5XX"} {
+		assert.True(t, scan.IsStatusFilter(s), s)
+	}
+
+	for _, s := range []string{"", "20", "2000", "600", "099", "6xx", "xxx", "4x", "abc"} {
+		assert.False(t, scan.IsStatusFilter(s), s)
+	}
+}
@@ -0,0 +1,255 @@
+package scan
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+// ResponseSizeBounds are the (upper, inclusive) bounds of the buckets of the response
+// sizes' histogram (see [ResponseStats.Sizes]), in bytes. Larger ones go into the last bucket.
+var ResponseSizeBounds = []float64{128, 512, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// ResponseLatencyBounds are the (upper, inclusive) bounds of the buckets of the response
+// latencies' histogram (see [ResponseStats.Latencies]), in milliseconds. Slower ones go
+// into the last bucket.
+var ResponseLatencyBounds = []float64{50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// ResponseQuantiles are the quantiles estimated by every [Distribution] (i.e. p50, p90 and p99).
+var ResponseQuantiles = []float64{0.5, 0.9, 0.99}
+
+// ResponseStats are the distributions of the sizes (i.e. body length, in bytes) and latencies
+// (in milliseconds) of the responses received, aggregated as these arrive, so they are helpful
+// to pick the thresholds of the size and timing matchers (e.g. Content Length or Time Delay).
+type ResponseStats struct {
+	Sizes     *Distribution `json:"sizes"`
+	Latencies *Distribution `json:"latencies"`
+}
+
+// NewResponseStats creates a new, empty instance of [ResponseStats].
+func NewResponseStats() *ResponseStats {
+	return &ResponseStats{
+		Sizes:     NewDistribution(ResponseSizeBounds),
+		Latencies: NewDistribution(ResponseLatencyBounds),
+	}
+}
+
+// Add accounts the given [response.Response] into the [ResponseStats].
+func (rs *ResponseStats) Add(res *response.Response) {
+	rs.Sizes.Add(float64(len(res.Body)))
+	rs.Latencies.Add(float64(res.Time) / 1e6) //nolint:mnd
+}
+
+// Distribution is a memory-bounded summary of a stream of observations, with their count, min,
+// max and sum, a histogram (i.e. the count per bucket, see [Distribution.Bounds]) and the estimation
+// of some quantiles (see [ResponseQuantiles]), streamed with the P² algorithm (see [QuantileEstimator]).
+type Distribution struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Sum   float64 `json:"sum"`
+
+	// Bounds are the (upper, inclusive) bounds of each bucket, and Buckets the count of
+	// observations within each of them, plus an additional one for those above all bounds.
+	Bounds  []float64 `json:"bounds"`
+	Buckets []int     `json:"buckets"`
+
+	Quantiles []*QuantileEstimator `json:"quantiles"`
+}
+
+// NewDistribution creates a new, empty instance of [Distribution],
+// with the given (sorted) bounds for its histogram buckets.
+func NewDistribution(bounds []float64) *Distribution {
+	quantiles := make([]*QuantileEstimator, 0, len(ResponseQuantiles))
+	for _, p := range ResponseQuantiles {
+		quantiles = append(quantiles, NewQuantileEstimator(p))
+	}
+
+	return &Distribution{
+		Bounds:    bounds,
+		Buckets:   make([]int, len(bounds)+1),
+		Quantiles: quantiles,
+	}
+}
+
+// Add accounts the given observation into the [Distribution].
+func (d *Distribution) Add(x float64) {
+	if d.Count == 0 || x < d.Min {
+		d.Min = x
+	}
+	if d.Count == 0 || x > d.Max {
+		d.Max = x
+	}
+	d.Count++
+	d.Sum += x
+
+	d.Buckets[sort.SearchFloat64s(d.Bounds, x)]++
+
+	for _, q := range d.Quantiles {
+		q.Add(x)
+	}
+}
+
+// Mean returns the arithmetic mean of the observations, or zero if there's none.
+func (d *Distribution) Mean() float64 {
+	if d.Count == 0 {
+		return 0
+	}
+	return d.Sum / float64(d.Count)
+}
+
+// Quantile returns the estimation of the given quantile (e.g. 0.9 for p90), and
+// whether it is estimated at all (see [ResponseQuantiles]) and there's any observation.
+func (d *Distribution) Quantile(p float64) (float64, bool) {
+	for _, q := range d.Quantiles {
+		if q.P == p && q.Count > 0 {
+			return q.Value(), true
+		}
+	}
+	return 0, false
+}
+
+// p2Markers is the amount of markers used by the [QuantileEstimator].
+const p2Markers = 5
+
+// QuantileEstimator estimates a quantile (e.g. the median) of a stream of observations, with
+// constant memory, with the P² algorithm (see Jain & Chlamtac, 1985), that keeps five markers
+// whose heights approximate the minimum, the p/2, p and (1+p)/2 quantiles and the maximum,
+// adjusted with a piecewise-parabolic prediction on every observation.
+//
+// It's exported (along with its state), so it can be persisted along with the [Stats].
+type QuantileEstimator struct {
+	P     float64 `json:"p"`
+	Count int     `json:"count"`
+	// Heights are the markers' heights, and Positions and Desired the
+	// actual and desired positions (1-based) of the markers.
+	Heights   [p2Markers]float64 `json:"heights"`
+	Positions [p2Markers]float64 `json:"positions"`
+	Desired   [p2Markers]float64 `json:"desired"`
+}
+
+// NewQuantileEstimator creates a new [QuantileEstimator] for the given quantile, within (0, 1).
+func NewQuantileEstimator(p float64) *QuantileEstimator {
+	return &QuantileEstimator{
+		P:         p,
+		Positions: [p2Markers]float64{1, 2, 3, 4, 5},
+		Desired:   [p2Markers]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+	}
+}
+
+// Add accounts the given observation into the [QuantileEstimator].
+func (q *QuantileEstimator) Add(x float64) {
+	// The first observations are kept (sorted) as the markers' heights.
+	if q.Count < p2Markers {
+		q.Heights[q.Count] = x
+		q.Count++
+		sort.Float64s(q.Heights[:q.Count])
+		return
+	}
+	q.Count++
+
+	// Find the cell the observation falls into, adjusting the extreme markers, if needed.
+	var k int
+	switch {
+	case x < q.Heights[0]:
+		q.Heights[0] = x
+	case x >= q.Heights[p2Markers-1]:
+		q.Heights[p2Markers-1] = x
+		k = p2Markers - 2
+	default:
+		for k < p2Markers-2 && x >= q.Heights[k+1] {
+			k++
+		}
+	}
+
+	for i := k + 1; i < p2Markers; i++ {
+		q.Positions[i]++
+	}
+
+	increments := [p2Markers]float64{0, q.P / 2, q.P, (1 + q.P) / 2, 1}
+	for i := range q.Desired {
+		q.Desired[i] += increments[i]
+	}
+
+	// Adjust the heights of the middle markers, if these are off their desired positions.
+	for i := 1; i < p2Markers-1; i++ {
+		d := q.Desired[i] - q.Positions[i]
+		if (d >= 1 && q.Positions[i+1]-q.Positions[i] > 1) || (d <= -1 && q.Positions[i-1]-q.Positions[i] < -1) {
+			sign := math.Copysign(1, d)
+
+			height := q.parabolic(i, sign)
+			if height <= q.Heights[i-1] || height >= q.Heights[i+1] {
+				height = q.linear(i, sign)
+			}
+
+			q.Heights[i] = height
+			q.Positions[i] += sign
+		}
+	}
+}
+
+func (q *QuantileEstimator) parabolic(i int, d float64) float64 {
+	n, h := q.Positions, q.Heights
+	return h[i] + d/(n[i+1]-n[i-1])*((n[i]-n[i-1]+d)*(h[i+1]-h[i])/(n[i+1]-n[i])+(n[i+1]-n[i]-d)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (q *QuantileEstimator) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return q.Heights[i] + d*(q.Heights[j]-q.Heights[i])/(q.Positions[j]-q.Positions[i])
+}
+
+// Value returns the estimation of the quantile, or zero if there's no observation.
+// With no more observations than markers, it is the (nearest-rank) exact quantile.
+func (q *QuantileEstimator) Value() float64 {
+	switch {
+	case q.Count == 0:
+		return 0
+	case q.Count <= p2Markers:
+		idx := int(math.Ceil(q.P*float64(q.Count))) - 1
+		return q.Heights[max(idx, 0)]
+	default:
+		return q.Heights[2]
+	}
+}
+
+// withResponseStats decorates the given [RequesterBuilder], so every response received
+// through the built [Requester] is accounted into the given [Stats] (see [Stats.Responses]).
+func withResponseStats(fn RequesterBuilder, stats *Stats) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return responseStatsRequester{Requester: requester, stats: stats}, nil
+	}
+}
+
+type responseStatsRequester struct {
+	Requester
+	stats *Stats
+}
+
+func (r responseStatsRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	res, err := r.Requester.Do(ctx, req)
+	if err == nil && !res.IsEmpty() {
+		r.stats.addResponse(requestHost(req), &res)
+	}
+
+	return res, err
+}
+
+// requestHost returns the host (lowercased, without port) of the given request, like [templateHost].
+func requestHost(req *request.Request) string {
+	u, err := url.Parse(req.URL)
+	if err != nil || len(u.Hostname()) == 0 {
+		return strings.ToLower(req.URL)
+	}
+
+	return strings.ToLower(u.Hostname())
+}
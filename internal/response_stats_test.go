@@ -0,0 +1,150 @@
+package scan_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestQuantileEstimator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("few observations", func(t *testing.T) {
+		t.Parallel()
+
+		q := scan.NewQuantileEstimator(0.5)
+		assert.Zero(t, q.Value())
+
+		for _, x := range []float64{30, 10, 20} {
+			q.Add(x)
+		}
+		assert.InDelta(t, 20, q.Value(), 0)
+	})
+
+	t.Run("many observations", func(t *testing.T) {
+		t.Parallel()
+
+		const n = 10000
+
+		rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+		values := rnd.Perm(n)
+
+		for _, p := range []float64{0.5, 0.9, 0.99} {
+			q := scan.NewQuantileEstimator(p)
+			for _, v := range values {
+				q.Add(float64(v))
+			}
+
+			assert.Equal(t, n, q.Count)
+			assert.InDelta(t, p*n, q.Value(), 0.02*n, "p=%v", p)
+		}
+	})
+
+	t.Run("persisted", func(t *testing.T) {
+		t.Parallel()
+
+		q := scan.NewQuantileEstimator(0.9)
+		for i := 0; i < 100; i++ {
+			q.Add(float64(i))
+		}
+
+		b, err := json.Marshal(q)
+		require.NoError(t, err)
+
+		var restored scan.QuantileEstimator
+		require.NoError(t, json.Unmarshal(b, &restored))
+
+		for i := 100; i < 200; i++ {
+			q.Add(float64(i))
+			restored.Add(float64(i))
+		}
+		assert.InDelta(t, q.Value(), restored.Value(), 0)
+	})
+}
+
+func TestResponseStats_Add(t *testing.T) {
+	t.Parallel()
+
+	rs := scan.NewResponseStats()
+	rs.Add(&response.Response{Code: 200, Body: make([]byte, 100), Time: 20 * time.Millisecond})
+	rs.Add(&response.Response{Code: 200, Body: make([]byte, 2000), Time: 300 * time.Millisecond})
+	rs.Add(&response.Response{Code: 404, Body: make([]byte, 2<<20), Time: 20 * time.Second})
+
+	assert.Equal(t, 3, rs.Sizes.Count)
+	assert.InDelta(t, 100, rs.Sizes.Min, 0)
+	assert.InDelta(t, 2<<20, rs.Sizes.Max, 0)
+	assert.Equal(t, []int{1, 0, 0, 1, 0, 0, 0, 0, 1}, rs.Sizes.Buckets)
+
+	assert.InDelta(t, 20, rs.Latencies.Min, 0)
+	assert.InDelta(t, 20000, rs.Latencies.Max, 0)
+	assert.Equal(t, []int{1, 0, 0, 1, 0, 0, 0, 0, 1}, rs.Latencies.Buckets)
+
+	p50, ok := rs.Latencies.Quantile(0.5)
+	require.True(t, ok)
+	assert.InDelta(t, 300, p50, 0)
+
+	_, ok = rs.Latencies.Quantile(0.75)
+	assert.False(t, ok)
+}
+
+func TestRunner_ResponseStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for i, u := range []string{"http://example.com/", "http://example.com/about", "http://example.org/"} {
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, i, request.WithOptions(u), nil)))
+	}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return &gitRequester{exposed: "example.com"}, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithActiveProfiles([]*profile.Active{
+			{
+				Name:    "Exposed .git",
+				Enabled: true,
+				Type:    profile.TypeActive,
+				Steps: []profile.Step{{
+					RequestType: profile.HostRequest,
+					Request:     profile.StepRequest{Path: "/.git/HEAD"},
+					Greps:       []string{"true,,Simple String,,ref: refs/"},
+				}},
+			},
+		}))
+	require.NoError(t, r.Start())
+
+	stats, err := fs.LoadStats(ctx)
+	require.NoError(t, err)
+
+	// Every response received is accounted, globally and per host.
+	require.NotNil(t, stats.Responses)
+	assert.Equal(t, 2, stats.Responses.Sizes.Count)
+	assert.Equal(t, 2, stats.Responses.Latencies.Count)
+	assert.InDelta(t, 0, stats.Responses.Sizes.Min, 0)
+	assert.InDelta(t, len("ref: refs/heads/main\n"), stats.Responses.Sizes.Max, 0)
+
+	require.Len(t, stats.HostsResponses, 2)
+	assert.Equal(t, 1, stats.HostsResponses["example.com"].Sizes.Count)
+	assert.Equal(t, 1, stats.HostsResponses["example.org"].Sizes.Count)
+}
@@ -0,0 +1,123 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// ErrInvalidRetryStatus is the error returned when the status codes
+// that warrant a retry cannot be parsed (see [ParseRetryStatuses]).
+var ErrInvalidRetryStatus = errors.New("invalid retry status")
+
+// DefaultRetryBackoff is the pause before the first retry (see [StatusRetryCfg]),
+// doubled on every following one, unless the response has a Retry-After header.
+const DefaultRetryBackoff = time.Second
+
+// StatusRetryCfg determines which responses are retried, by status code (e.g. 429 or 503), as
+// these are considered transient (i.e. application-level retries), and up to how many times (per
+// request). The pause before each retry is the one requested by the Retry-After header, if any,
+// or the Backoff otherwise, doubled on every retry, both bounded by one minute.
+//
+// The final response (i.e. the one got after the latest retry) is the one returned (and thus, the one
+// matched). Network errors aren't retried, as those aren't responses. Use [WithStatusRetry] to apply
+// it to the requests sent.
+type StatusRetryCfg struct {
+	Statuses   []int
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// ParseRetryStatuses parses the given comma-separated list of status codes (e.g. 429,503),
+// which must be between 100 and 599, with no duplicates. It returns nil if it is empty.
+func ParseRetryStatuses(s string) ([]int, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+
+	var statuses []int
+	for _, v := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRetryStatus, strings.TrimSpace(v))
+		}
+
+		if slices.Contains(statuses, code) {
+			return nil, fmt.Errorf("%w: duplicated: %d", ErrInvalidRetryStatus, code)
+		}
+
+		statuses = append(statuses, code)
+	}
+
+	return statuses, nil
+}
+
+// Enabled returns whether any response is retried, which requires
+// both status codes to retry and a positive amount of retries.
+func (cfg StatusRetryCfg) Enabled() bool {
+	return len(cfg.Statuses) > 0 && cfg.MaxRetries > 0
+}
+
+// wait returns the pause before the given retry (starting from 1) of the given response.
+func (cfg StatusRetryCfg) wait(res response.Response, retry int) time.Duration {
+	if pause := retryAfter(res); pause > 0 {
+		return pause
+	}
+
+	backoff := cfg.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	return min(backoff<<(retry-1), maxRetryAfter)
+}
+
+// WithStatusRetry decorates the given [RequesterBuilder], so every request sent through the
+// built [Requester] is retried while its response status is any of those of the given
+// [StatusRetryCfg], up to its maximum retries. Each retry is a new request sent through
+// the given [RequesterBuilder], so it is throttled (see [WithAdaptiveThrottle]), if so.
+func WithStatusRetry(fn RequesterBuilder, cfg StatusRetryCfg) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return retryingRequester{Requester: requester, cfg: cfg}, nil
+	}
+}
+
+type retryingRequester struct {
+	Requester
+	cfg StatusRetryCfg
+}
+
+func (r retryingRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	res, err := r.Requester.Do(ctx, req)
+
+	for retry := 1; err == nil && retry <= r.cfg.MaxRetries && slices.Contains(r.cfg.Statuses, res.Code); retry++ {
+		wait := r.cfg.wait(res, retry)
+		logger.For(ctx).Infof("Retrying request (%d/%d) to %s%s: status %d, waiting %s", retry, r.cfg.MaxRetries, throttleHost(req), req.Path, res.Code, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			// The latest response is the final one, as the scan is stopping.
+			timer.Stop()
+			return res, nil
+		}
+
+		res, err = r.Requester.Do(ctx, req)
+	}
+
+	return res, err
+}
@@ -0,0 +1,126 @@
+package scan_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestParseRetryStatuses(t *testing.T) {
+	t.Parallel()
+
+	statuses, err := scan.ParseRetryStatuses(" 429, 503 ")
+	require.NoError(t, err)
+	assert.Equal(t, []int{429, 503}, statuses)
+
+	statuses, err = scan.ParseRetryStatuses("")
+	require.NoError(t, err)
+	assert.Nil(t, statuses)
+
+	for _, invalid := range []string{"429,", "too-many", "99", "600", "503,503"} {
+		_, err := scan.ParseRetryStatuses(invalid)
+		require.ErrorIs(t, err, scan.ErrInvalidRetryStatus, invalid)
+	}
+}
+
+func TestWithStatusRetry(t *testing.T) {
+	t.Parallel()
+
+	cfg := scan.StatusRetryCfg{Statuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, MaxRetries: 2, Backoff: time.Millisecond}
+
+	tcs := map[string]struct {
+		codes    []int
+		expCode  int
+		expCalls int
+	}{
+		"not retried":               {codes: []int{200}, expCode: 200, expCalls: 1},
+		"status not retried":        {codes: []int{500, 200}, expCode: 500, expCalls: 1},
+		"retried until success":     {codes: []int{429, 503, 200}, expCode: 200, expCalls: 3},
+		"retried up to max retries": {codes: []int{503, 503, 503, 200}, expCode: 503, expCalls: 3},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			requester := &sequenceRequester{codes: tc.codes}
+			fn := scan.WithStatusRetry(func() (scan.Requester, error) { return requester, nil }, cfg)
+
+			r, err := fn()
+			require.NoError(t, err)
+
+			res, err := r.Do(context.Background(), &request.Request{URL: "http://example.com/", Path: "/"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expCode, res.Code)
+			assert.Equal(t, tc.expCalls, requester.calls)
+		})
+	}
+
+	t.Run("honors retry-after", func(t *testing.T) {
+		t.Parallel()
+
+		requester := &sequenceRequester{codes: []int{429, 200}, retryAfter: "1"}
+		fn := scan.WithStatusRetry(func() (scan.Requester, error) { return requester, nil }, cfg)
+
+		r, err := fn()
+		require.NoError(t, err)
+
+		start := time.Now()
+		res, err := r.Do(context.Background(), &request.Request{URL: "http://example.com/", Path: "/"})
+		require.NoError(t, err)
+		assert.Equal(t, 200, res.Code)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops waiting on cancel", func(t *testing.T) {
+		t.Parallel()
+
+		requester := &sequenceRequester{codes: []int{429, 200}, retryAfter: "30"}
+		fn := scan.WithStatusRetry(func() (scan.Requester, error) { return requester, nil }, cfg)
+
+		r, err := fn()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		res, err := r.Do(ctx, &request.Request{URL: "http://example.com/", Path: "/"})
+		require.NoError(t, err)
+		assert.Equal(t, 429, res.Code)
+		assert.Equal(t, 1, requester.calls)
+	})
+}
+
+// sequenceRequester returns responses with the given status codes, in order,
+// repeating the latest one, with the given Retry-After header, if any.
+type sequenceRequester struct {
+	sync.Mutex
+	codes      []int
+	retryAfter string
+	calls      int
+}
+
+func (sr *sequenceRequester) Do(_ context.Context, _ *request.Request) (response.Response, error) {
+	sr.Lock()
+	defer sr.Unlock()
+
+	code := sr.codes[min(sr.calls, len(sr.codes)-1)]
+	sr.calls++
+
+	res := response.Response{Code: code}
+	if len(sr.retryAfter) > 0 {
+		res.Headers = map[string][]string{"Retry-After": {sr.retryAfter}}
+	}
+
+	return res, nil
+}
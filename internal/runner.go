@@ -5,16 +5,29 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/bountysecurity/gbounty/internal/match"
 	"github.com/bountysecurity/gbounty/internal/platform/metrics"
+	"github.com/bountysecurity/gbounty/internal/response"
 	"github.com/bountysecurity/gbounty/kit/logger"
 	"github.com/bountysecurity/gbounty/kit/panics"
 	"github.com/bountysecurity/gbounty/kit/pool"
 )
 
+// backlogFactor determines how many templates (times the concurrency) can be held
+// back by the dispatcher, when these are read from the file system (see [dispatch]).
+const backlogFactor = 10
+
 // Runner is the main component responsible for orchestrating `scan` executions.
 type Runner struct {
-	opts  *RunnerOpts
-	stats *Stats
+	opts      *RunnerOpts
+	stats     *Stats
+	baselines *responseBaselines
+	warmups   *hostWarmups
+
+	// hostRequests (and countedHostRequests, when calculating the tasks) keep
+	// track of the host requests already prepared (see [hostRequests]).
+	hostRequests        *hostRequests
+	countedHostRequests *hostRequests
 }
 
 // NewRunner constructs a new [Runner] instance.
@@ -28,8 +41,13 @@ func NewRunner(opts *RunnerOpts) *Runner {
 	}
 
 	return &Runner{
-		opts:  opts,
-		stats: NewStats(),
+		opts:      opts,
+		stats:     NewStats(),
+		baselines: newResponseBaselines(),
+		warmups:   newHostWarmups(),
+
+		hostRequests:        newHostRequests(),
+		countedHostRequests: newHostRequests(),
 	}
 }
 
@@ -71,12 +89,26 @@ func (r *Runner) Start() (err error) {
 
 // run is the main function to trigger the scan execution.
 // It should never return an error, other than [context.Canceled]
-// in case the ctx ([context.Context]) from Runner.opts is cancelled.
+// in case the ctx ([context.Context]) from Runner.opts is cancelled,
+// or [ErrStoppedOnMatch] (wrapping it) in case it is stopped on match,
+// or [TooManyErrorsError] (wrapping it) in case it is aborted on errors.
 func (r *Runner) run() error {
+	// Templates are read as there's room to scan them (i.e. back-pressure), so the
+	// dispatcher never holds back more than a bounded amount of them, regardless of
+	// how slow any host is. Streamed ones are held back no more than the concurrency,
+	// while those stored are held back up to backlogFactor times it, so those targeting
+	// other hosts can still be dispatched first when any host is at its cap, or paused.
+	backlog := r.opts.cfg.Concurrency * backlogFactor
+	if r.opts.streamed() {
+		backlog = r.opts.cfg.Concurrency
+	}
+
 	// Global execution variables
 	var (
-		p  = pool.New(r.opts.ctx, r.opts.cfg.Concurrency)
-		ch = make(chan update)
+		p    = pool.New(r.opts.ctx, r.opts.cfg.Concurrency)
+		d    = dispatch(r.opts.ctx, r.opts.templatesIt, r.opts.cfg.ConcurrencyPerHost, r.opts.cfg.TimeBudgetPerHost, backlog)
+		ch   = make(chan update)
+		once = new(sync.Once)
 	)
 
 	logger.For(r.opts.ctx).Info("Launching stats collector...")
@@ -87,15 +119,25 @@ func (r *Runner) run() error {
 		r.stats = stats
 		logger.For(r.opts.ctx).Info("Scan stats from a previous execution loaded successfully")
 	} else {
-		logger.For(r.opts.ctx).Info("Dispatching scan tasks calculation...")
-		go r.calculateTasks(r.opts.ctx)
+		r.stats.NumOfDroppedByURLMatch = r.opts.droppedByURLMatch
+		r.stats.NumOfDroppedByURLReject = r.opts.droppedByURLReject
+		r.stats.NumOfDroppedByExtension = r.opts.droppedByExtension
+		r.stats.NumOfSkippedTemplates = r.opts.skippedTemplates
+		r.stats.NumOfSkippedHosts = r.opts.skippedHosts
+
+		// Streamed templates' tasks are calculated as these arrive (see below).
+		if !r.opts.streamed() {
+			logger.For(r.opts.ctx).Info("Dispatching scan tasks calculation...")
+			go r.calculateTasks(r.opts.ctx)
+		}
 	}
 
-	for tpl := range r.opts.templatesIt {
+	for tpl := range d.templates() {
 		// Check for context cancellation
 		select {
 		case <-r.opts.ctx.Done():
 			logger.For(r.opts.ctx).Debugf("Scan template (idx=%d): context cancelled", tpl.Idx)
+			d.done(tpl)
 			continue
 		default:
 		}
@@ -103,11 +145,26 @@ func (r *Runner) run() error {
 		// Check for finished templates
 		if r.stats.isTemplateEnded(tpl) {
 			logger.For(r.opts.ctx).Debugf("Skipping (ended) template with idx: %d", tpl.Idx)
+			d.done(tpl)
+			continue
+		}
+
+		// Check for filtered templates, marked as ended, so these aren't counted twice once resumed
+		if !r.opts.cfg.TemplateFilter.Allows(r.opts.ctx, tpl) {
+			logger.For(r.opts.ctx).Debugf("Scan template (idx=%d) skipped by filter (--template-filter): %s", tpl.Idx, r.opts.cfg.TemplateFilter)
+			r.stats.incrementFilteredTemplates(1)
+			r.stats.markTemplateAsEnded(tpl.Idx)
+			d.done(tpl)
 			continue
 		}
 
 		tpl := tpl
 
+		// There's no total amount of tasks upfront, as templates are streamed.
+		if r.opts.streamed() {
+			r.calculateTemplateTasks(r.opts.ctx, tpl, once)
+		}
+
 		// This is a blocking operation, based on the maximum concurrency set
 		// at the pool.Pool initialization. It will block until a worker is
 		// available, or just early return if the given context is cancelled.
@@ -117,7 +174,11 @@ func (r *Runner) run() error {
 		//
 		// In order to ensure that all the tasks are finished, we need to call
 		// p.Close() and wait for the internal WaitGroup to be done.
+		//
+		// Once finished (or discarded), the template is reported back to the
+		// dispatcher, so the next template targeting the same host can be dispatched.
 		p.BareRun(r.opts.ctx, func() {
+			defer d.done(tpl)
 			defer panics.Log(r.opts.ctx)
 
 			// Account the number of concurrent templates.
@@ -126,12 +187,16 @@ func (r *Runner) run() error {
 
 			logger.For(r.opts.ctx).Debugf("Starting scan template with idx: %d", tpl.Idx)
 
+			// Warm up the template's host, if enabled, before its first template is scanned.
+			r.warmup(tpl)
+
 			// Initialize line of work.
 			lineOfWork := &LineOfWork{Template: tpl, Matches: make(map[string]struct{})}
 
 			// Find and update entrypoints.
-			// ONLY for those templates with no response.
-			if tpl.Response == nil {
+			// ONLY for those templates with no response,
+			// and when entrypoints are not disabled.
+			if tpl.Response == nil && !r.opts.cfg.NoEntrypoints {
 				for _, f := range r.opts.entrypointFinders {
 					entrypointsFound := f.Find(lineOfWork.Template.Request)
 					lineOfWork.appendEntrypoints(entrypointsFound)
@@ -139,7 +204,13 @@ func (r *Runner) run() error {
 			}
 
 			// Prepare tasks within the line of work.
-			if tpl.Response == nil {
+			switch {
+			case tpl.Response == nil && r.opts.cfg.NoEntrypoints:
+				// Prepare a single raw task.
+				// ONLY for those templates with no response,
+				// when entrypoints are disabled.
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindRaw, lineOfWork))
+			case tpl.Response == nil:
 				// Prepare tasks for all active profiles.
 				// ONLY for those templates with no response.
 				for _, prof := range r.opts.activeProfiles {
@@ -148,28 +219,66 @@ func (r *Runner) run() error {
 						prof,
 						len(r.opts.cfg.BlindHost) > 0,
 						r.opts.cfg.EmailAddress,
+						r.hostRequests,
 					)
 				}
-			} else {
+			default:
 				// Prepare a single task.
 				// ONLY for those templates with response.
 				lineOfWork.Tasks = append(lineOfWork.Tasks, &Task{IsBase: true, StepIdx: -1, PayloadIdx: -1, LoW: lineOfWork})
 			}
 
+			// Prepare a response diff task, if enabled.
+			// ONLY for those templates with no response.
+			if tpl.Response == nil && r.opts.cfg.ResponseDiff.Enabled {
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindDiff, lineOfWork))
+			}
+
+			// Prepare a rate limit (burst) task, if enabled and the template's URL matches
+			// (or any active profile asks for it). ONLY for those templates with no response.
+			if r.opts.cfg.RateLimit.Applies(tpl, r.opts.activeProfiles) {
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindBurst, lineOfWork))
+			}
+
+			// Prepare a mass assignment task, if enabled.
+			// ONLY for those templates with no response.
+			if r.opts.cfg.MassAssignment.Applies(tpl) {
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindMassAssignment, lineOfWork))
+			}
+
+			// Prepare a cache poisoning task, if enabled.
+			// ONLY for those templates with no response.
+			if r.opts.cfg.CachePoisoning.Applies(tpl) {
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindCachePoisoning, lineOfWork))
+			}
+
+			// Prepare a GraphQL introspection task, if enabled.
+			// ONLY for those templates with no response.
+			if tpl.Response == nil && r.opts.cfg.GraphQLIntrospection {
+				lineOfWork.Tasks = append(lineOfWork.Tasks, newKindTask(TaskKindGraphQL, lineOfWork))
+			}
+
 			// Execute all the tasks within the line of work
 			r.performRequests(ch, lineOfWork)
 
 			// If it hasn't been cancelled, mark it as finished
-			// Otherwise, undo it and update stats accordingly.
-			if r.opts.ctx.Err() == nil {
+			// Otherwise, undo it and update stats accordingly, unless
+			// stopped on match (or aborted), as it won't be continued.
+			switch {
+			case r.opts.ctx.Err() == nil:
 				r.stats.markTemplateAsEnded(tpl.Idx)
-			} else {
+			case r.opts.stoppedOnPurpose():
+				logger.For(r.opts.ctx).Debugf("Scan template (idx=%d) interrupted: %s", tpl.Idx, context.Cause(r.opts.ctx))
+			default:
 				lineOfWork.reset()
 				r.stats.incrementMatches(-lineOfWork.numOfMatches())
 				r.stats.incrementFailedRequests(-lineOfWork.numOfFailedTasks())
 				r.stats.incrementSucceedRequests(-lineOfWork.numOfSucceedTasks())
 			}
-		}, func() { logger.For(r.opts.ctx).Debugf("Scan template (idx=%d) discarded: context cancelled", tpl.Idx) })
+		}, func() {
+			logger.For(r.opts.ctx).Debugf("Scan template (idx=%d) discarded: context cancelled", tpl.Idx)
+			d.done(tpl)
+		})
 	}
 
 	logger.For(r.opts.ctx).Info("The scan templates iteration has reached its latest template")
@@ -183,6 +292,15 @@ func (r *Runner) run() error {
 	close(ch)
 	wg.Wait()
 
+	if r.opts.cfg.TimeBudgetPerHost > 0 {
+		r.stats.addHostsTimeSpent(d.timeSpent())
+	}
+
+	// The cause wraps [context.Canceled], so it's handled as any other cancellation.
+	if r.opts.stoppedOnPurpose() {
+		return context.Cause(r.opts.ctx)
+	}
+
 	return r.opts.ctx.Err()
 }
 
@@ -204,14 +322,27 @@ func (r *Runner) launchStatsCollector(ctx context.Context, ch chan update) *sync
 }
 
 func (r *Runner) performRequests(ch chan update, lineOfWork *LineOfWork) {
-	lineOfWork.executeTasks(
-		r.opts.ctx, r.opts.reqBuilder, r.opts.bhPoller,
-		func(n int) { r.stats.incrementTotalRequests(n) },
-		func(n int) {
+	// Matchers are bounded by the configured timeout, if any, and timeouts are counted.
+	ctx := match.WithTimeout(r.opts.ctx, r.opts.cfg.MatchTimeout, func() { r.stats.incrementMatcherTimeouts(1) })
+	ctx = match.WithSignatures(ctx, r.opts.cfg.Signatures)
+	ctx = match.WithFileSignatures(ctx, r.opts.cfg.FileSignatures)
+	ctx = match.WithCloudSignatures(ctx, r.opts.cfg.CloudSignatures)
+	ctx = match.WithDebugSignatures(ctx, r.opts.cfg.DebugSignatures)
+	ctx = match.WithTechnologySignatures(ctx, r.opts.cfg.TechSignatures)
+	ctx = withCanaryPrefix(ctx, r.opts.cfg.CanaryPrefix)
+
+	// Every response received is accounted into the response stats (i.e. sizes and latencies).
+	reqBuilder := withResponseStats(r.opts.reqBuilder, r.stats)
+
+	lineOfWork.executeTasks(ctx, r.opts.cfg.RPS, taskEnv{
+		fn:                  reqBuilder,
+		bhPoller:            r.opts.bhPoller,
+		onRequestsScheduled: func(n int) { r.stats.incrementTotalRequests(n) },
+		onRequestsSkipped: func(n int) {
 			r.stats.incrementTotalRequests(-n)
 			r.stats.incrementSkippedRequests(n)
 		},
-		func(matched, success, failed bool) {
+		onUpdate: func(matched, success, failed bool) {
 			select {
 			case <-r.opts.ctx.Done():
 				return
@@ -225,19 +356,72 @@ func (r *Runner) performRequests(ch chan update, lineOfWork *LineOfWork) {
 				newSuccess: success,
 				newErr:     failed,
 			}:
+				// Recorded once sent, so the stats account it even if the scan is aborted.
+				// Failures are recorded along with their error (see [RunnerOpts.setupOnErrorFn]).
+				if success {
+					r.opts.recordOutcome(nil)
+				}
 			}
 		},
-		r.opts.onErrorFn, r.opts.onMatchFn, r.opts.onTaskFn,
-		r.opts.cfg.RPS,
-		r.opts.saveAllRequests,
-		r.opts.saveResponses,
-		r.opts.saveAllResponses,
-		r.opts.modifiers,
-		r.opts.passiveReqProfiles,
-		r.opts.passiveResProfiles,
-		r.opts.cfg.CustomTokens,
-		r.opts.cfg.PayloadStrategy,
-	)
+		onErrorFn:          r.opts.onErrorFn,
+		onMatchFn:          r.opts.onMatchFn,
+		onTaskFn:           r.opts.onTaskFn,
+		saveAllRequests:    r.opts.saveAllRequests,
+		saveResponses:      r.opts.saveResponses,
+		saveAllResponses:   r.opts.saveAllResponses,
+		baseModifiers:      r.opts.modifiers,
+		passiveReqProfiles: r.opts.passiveReqProfiles,
+		passiveResProfiles: r.opts.passiveResProfiles,
+		customTokens:       r.opts.cfg.CustomTokens,
+		payloadStrategy:    r.opts.cfg.PayloadStrategy,
+		filterBody:         r.filterBody,
+		filterResponse:     r.filterResponse,
+		redirects:          r.opts.cfg.Redirects,
+		modes: taskModes{
+			responseDiff:   r.opts.cfg.ResponseDiff,
+			rateLimit:      r.opts.cfg.RateLimit,
+			massAssignment: r.opts.cfg.MassAssignment,
+			cachePoisoning: r.opts.cfg.CachePoisoning,
+		},
+	})
+}
+
+// filterBody returns the given [response.Response] with no body if its media type
+// is filtered out (see [Config.MimeFilter]), so the matchers only analyze its headers.
+// Otherwise, it returns the same response.
+func (r *Runner) filterBody(res *response.Response) *response.Response {
+	if !r.opts.cfg.MimeFilter.Skips(res) {
+		return res
+	}
+
+	logger.For(r.opts.ctx).Debugf("Skipping response body for matching, media type filtered: %s", res.MediaType())
+	r.stats.incrementSkippedBodies(1)
+
+	return withoutBody(res)
+}
+
+// filterResponse returns whether the given [response.Response], received for the given
+// [Template], must be skipped (i.e. neither evaluated by the matchers nor recorded),
+// either because of its status code, or because it doesn't differ from the per-URL
+// baseline (see [Config.ResponseFilter]). The filtered responses are counted.
+func (r *Runner) filterResponse(tpl Template, res *response.Response) bool {
+	filter := r.opts.cfg.ResponseFilter
+	if filter.IsEmpty() || res == nil || res.IsEmpty() {
+		return false
+	}
+
+	switch {
+	case filter.SkipsStatus(res):
+		logger.For(r.opts.ctx).Debugf("Skipping response for matching, status code filtered: %d", res.Code)
+	case filter.OnlyDiff && !r.baselines.differs(tpl.URL, res):
+		logger.For(r.opts.ctx).Debugf("Skipping response for matching, same as the baseline of: %s", tpl.URL)
+	default:
+		return false
+	}
+
+	r.stats.incrementFilteredResponses(1)
+
+	return true
 }
 
 func (r *Runner) statsCollector(ch chan update, onUpdatedFn func(*Stats)) {
@@ -265,6 +449,8 @@ func (r *Runner) statsCollector(ch chan update, onUpdatedFn func(*Stats)) {
 			NumOfPerformedRequests: r.stats.NumOfPerformedRequests,
 			NumOfSucceedRequests:   r.stats.NumOfSucceedRequests,
 			NumOfFailedRequests:    r.stats.NumOfFailedRequests,
+			NumOfSkippedBodies:     r.stats.NumOfSkippedBodies,
+			NumOfFilteredResponses: r.stats.NumOfFilteredResponses,
 			TemplatesEnded:         r.stats.TemplatesEnded,
 			NumOfEntrypoints:       r.stats.NumOfEntrypoints,
 			NumOfMatches:           r.stats.NumOfMatches,
@@ -284,66 +470,64 @@ func (r *Runner) calculateTasks(ctx context.Context) {
 		return
 	}
 
-	for tpl := range templates {
-		tpl := tpl
-		if tpl.Response != nil { // Is passive? (analyze only)
-			if !tpl.Request.IsEmpty() {
-				r.stats.incrementRequestsToAnalyze(1)
-			}
-			if !tpl.Response.IsEmpty() {
-				r.stats.incrementResponsesToAnalyze(1)
-			}
+	for tpl := range shard(ctx, templates, r.opts.cfg.Shard) {
+		// Filtered templates are skipped during dispatch, so no requests are sent.
+		if !r.opts.cfg.TemplateFilter.Allows(ctx, tpl) {
 			continue
 		}
 
+		tpl := tpl
+
 		wg.Add(1)
 
 		go func() {
 			defer panics.Log(ctx)
+			defer wg.Done()
 
-			lineOfWork := &LineOfWork{Template: tpl, Matches: make(map[string]struct{})}
+			r.calculateTemplateTasks(ctx, tpl, once)
+		}()
+	}
 
-			for _, finder := range r.opts.entrypointFinders {
-				entrypointsFound := finder.Find(lineOfWork.Template.Request)
+	wg.Wait()
+}
 
-				logger.For(ctx).Debugf(
-					"Entrypoints found for template (idx=%d) and finder(%T): %d",
-					tpl.Idx, finder, len(entrypointsFound),
-				)
+// calculateTemplateTasks calculates the amount of tasks (i.e. requests) of the given
+// template, and increments the stats accordingly. The given [sync.Once] is used to
+// warn only once about skipped requests.
+func (r *Runner) calculateTemplateTasks(ctx context.Context, tpl Template, once *sync.Once) {
+	if tpl.Response != nil { // Is passive? (analyze only)
+		if !tpl.Request.IsEmpty() {
+			r.stats.incrementRequestsToAnalyze(1)
+		}
+		if !tpl.Response.IsEmpty() {
+			r.stats.incrementResponsesToAnalyze(1)
+		}
+		return
+	}
 
-				lineOfWork.appendEntrypoints(entrypointsFound)
-				r.stats.incrementEntrypoints(len(entrypointsFound))
+	entrypoints := countTemplateRequests(
+		ctx,
+		r.opts.cfg,
+		tpl,
+		r.opts.entrypointFinders,
+		r.opts.activeProfiles,
+		r.countedHostRequests,
+		func(profileName string, numTasksPrepared int, skipped bool) {
+			if skipped {
+				once.Do(func() {
+					logger.For(ctx).Warn("Some requests have been skipped because they contain one of the following labels: {IH}, {BH}, {BC}, {EMAIL}.")
+					logger.For(ctx).Warn("But either no blind host or email have been defined.")
+					logger.For(ctx).Warn("Please, try again with the --blind-host/-bh and --email-address/email flags.")
+				})
 			}
 
-			for _, prof := range r.opts.activeProfiles {
-				numTasksPrepared, skipped := lineOfWork.prepareTasks(
-					ctx,
-					prof,
-					len(r.opts.cfg.BlindHost) > 0,
-					r.opts.cfg.EmailAddress,
-				)
-
-				if skipped {
-					once.Do(func() {
-						// Q: Do we really want this???
-						// fmt.Println() //nolint:forbidigo
-						// pterm.Warning.Println("Some requests have been skipped because they contain one of the following labels: {IH}, {BH}, {BC}, {EMAIL}.")
-						// pterm.Warning.Println("But either no blind host or email have been defined.")
-						// pterm.Warning.Println("Please, try again with the --blind-host/-bh and --email-address/email flags.")
-						logger.For(ctx).Warn("Some requests have been skipped because they contain one of the following labels: {IH}, {BH}, {BC}, {EMAIL}.")
-						logger.For(ctx).Warn("But either no blind host or email have been defined.")
-						logger.For(ctx).Warn("Please, try again with the --blind-host/-bh and --email-address/email flags.")
-					})
-				}
-
+			if len(profileName) > 0 {
 				logger.For(ctx).Debugf("Tasks prepared for template (idx=%d): %d", tpl.Idx, numTasksPrepared)
-
-				r.stats.incrementTotalRequests(numTasksPrepared)
 			}
 
-			wg.Done()
-		}()
-	}
+			r.stats.incrementTotalRequests(numTasksPrepared)
+		},
+	)
 
-	wg.Wait()
+	r.stats.incrementEntrypoints(entrypoints)
 }
@@ -3,6 +3,7 @@ package scan
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/bountysecurity/gbounty/internal/entrypoint"
@@ -24,6 +25,9 @@ var (
 	ErrMissingFileSystemAbstraction = errors.New("missing file system abstraction")
 	// ErrMissingContext is the error returned when the `scan` cannot be started because there is no [context.Context].
 	ErrMissingContext = errors.New("missing context")
+	// ErrStoppedOnMatch is the error returned when the `scan` has been stopped on purpose, as soon as a [Match]
+	// was found (see [RunnerOpts.WithStopOnMatch]). It is always wrapped along with [context.Canceled].
+	ErrStoppedOnMatch = errors.New("scan stopped on match")
 )
 
 // RunnerOpts is the structure that holds the configuration for the [Runner] to start a `scan`.
@@ -46,8 +50,18 @@ type RunnerOpts struct {
 	saveResponses      bool
 	saveAllResponses   bool
 	fileSystem         FileSystem
+	droppedByURLMatch  int
+	droppedByURLReject int
+	droppedByExtension int
+	skippedTemplates   int
+	skippedHosts       int
+	stopOnMatch        func(Match) bool
+	errorThreshold     ErrorThreshold
+	errorBudget        *errorBudget
+	templatesStream    chan Template
 
 	templatesIt chan Template
+	stop        context.CancelCauseFunc
 }
 
 // DefaultRunnerOpts constructs an empty instance of [RunnerOpts].
@@ -165,12 +179,80 @@ func (opts *RunnerOpts) WithFileSystem(fileSystem FileSystem) *RunnerOpts {
 	return opts
 }
 
+// WithDroppedInputs sets the amount of inputs (i.e. templates) dropped by the url filters,
+// before the scan started, to the [RunnerOpts] instance, so they are part of the [Stats].
+func (opts *RunnerOpts) WithDroppedInputs(byURLMatch, byURLReject, byExtension int) *RunnerOpts {
+	opts.droppedByURLMatch = byURLMatch
+	opts.droppedByURLReject = byURLReject
+	opts.droppedByExtension = byExtension
+	return opts
+}
+
+// WithSkippedTemplates sets the amount of templates skipped by the skip predicate, before
+// the scan started, to the [RunnerOpts] instance, so they are part of the [Stats].
+func (opts *RunnerOpts) WithSkippedTemplates(n int) *RunnerOpts {
+	opts.skippedTemplates = n
+	return opts
+}
+
+// WithSkippedHosts sets the amount of hosts skipped as already seen in previous runs, before
+// the scan started, to the [RunnerOpts] instance, so they are part of the [Stats].
+func (opts *RunnerOpts) WithSkippedHosts(n int) *RunnerOpts {
+	opts.skippedHosts = n
+	return opts
+}
+
+// WithStopOnMatch sets the function that determines whether the scan must be stopped as soon as
+// the given [Match] is found (e.g. see [SeverityAtLeast]), to the [RunnerOpts] instance. If so, the
+// scan is cancelled (see [ErrStoppedOnMatch]) once the match has been stored (and streamed, if so),
+// and the matches found so far are kept, as there's no intention to continue the scan.
+func (opts *RunnerOpts) WithStopOnMatch(fn func(Match) bool) *RunnerOpts {
+	opts.stopOnMatch = fn
+	return opts
+}
+
+// WithErrorThreshold sets the [ErrorThreshold] that determines when the scan must be aborted because
+// too many requests failed (e.g. due to a misconfiguration), to the [RunnerOpts] instance. If so, the
+// scan is cancelled (see [TooManyErrorsError]), and the matches found so far are kept, as there's no
+// intention to continue the scan.
+func (opts *RunnerOpts) WithErrorThreshold(threshold ErrorThreshold) *RunnerOpts {
+	opts.errorThreshold = threshold
+	return opts
+}
+
+// WithTemplatesStream sets the channel the templates are received through, as these are built (e.g.
+// from the standard input), to the [RunnerOpts] instance, instead of reading them from the file system
+// once all of them are stored. So, templates are scanned as these arrive, in arrival order (i.e. not
+// prioritized), until the channel is closed, and their tasks are calculated as these arrive too.
+//
+// Templates are only received when there's room to scan them (see [dispatch]), so the sender is
+// blocked otherwise (i.e. back-pressure), and the memory used stays bounded.
+func (opts *RunnerOpts) WithTemplatesStream(ch chan Template) *RunnerOpts {
+	opts.templatesStream = ch
+	return opts
+}
+
+// streamed returns whether the templates are streamed (see [RunnerOpts.WithTemplatesStream]).
+func (opts *RunnerOpts) streamed() bool {
+	return opts.templatesStream != nil
+}
+
 func (opts *RunnerOpts) prepare() error {
 	logger.For(opts.ctx).Debug("Validating scan options...")
 	if err := opts.validate(); err != nil {
 		return err
 	}
 
+	// The scan context is cancelled from within, once stopped on match (or aborted
+	// on errors), so everything set up from here on (e.g. the iterator) is cancelled too.
+	if opts.stopOnMatch != nil || opts.errorThreshold.Enabled() {
+		opts.ctx, opts.stop = context.WithCancelCause(opts.ctx)
+	}
+
+	if opts.errorThreshold.Enabled() {
+		opts.errorBudget = newErrorBudget(opts.errorThreshold)
+	}
+
 	if err := opts.setupTemplatesIt(); err != nil {
 		return err
 	}
@@ -209,18 +291,36 @@ func (opts *RunnerOpts) validate() error {
 }
 
 func (opts *RunnerOpts) setupTemplatesIt() error {
+	// Streamed templates cannot be prioritized, as these are scanned as these arrive.
+	if opts.streamed() {
+		opts.templatesIt = shard(opts.ctx, opts.templatesStream, opts.cfg.Shard)
+		return nil
+	}
+
 	it, err := opts.fileSystem.TemplatesIterator(opts.ctx)
 	if err != nil {
 		return err
 	}
 
-	opts.templatesIt = it
+	opts.templatesIt = shard(opts.ctx, it, opts.cfg.Shard)
+
+	// Templates are dispatched by priority (see [Template.Priority]), if enabled,
+	// once those not belonging to the shard (see [Config.Shard]) are skipped.
+	if opts.cfg.Prioritized {
+		opts.templatesIt = prioritize(opts.ctx, opts.templatesIt)
+	}
+
 	return nil
 }
 
 func (opts *RunnerOpts) setupOnErrorFn() {
 	onErrorFn := opts.onErrorFn
 	opts.onErrorFn = func(ctx context.Context, url string, reqs []*request.Request, res []*response.Response, err error) {
+		// Requests deliberately not sent aren't errors, but reported as skipped (see [taskEnv.report]).
+		if errors.Is(err, ErrRequestSkipped) {
+			return
+		}
+
 		storeErr := opts.fileSystem.StoreError(ctx, Error{
 			URL:       url,
 			Requests:  reqs,
@@ -235,6 +335,32 @@ func (opts *RunnerOpts) setupOnErrorFn() {
 		if onErrorFn != nil {
 			onErrorFn(ctx, url, reqs, res, err)
 		}
+
+		opts.recordOutcome(err)
+	}
+}
+
+// recordOutcome records the outcome of a request, either successful (nil error) or failed,
+// and aborts the scan if the [ErrorThreshold] has been exceeded (see [RunnerOpts.WithErrorThreshold]).
+// Only transport-level failures (see [IsTransportError]) are recorded, along with their kind, so the
+// dominant one can be reported. Requests failed once the scan is cancelled (e.g. interrupted) aren't
+// recorded either, as those are failures caused by the cancellation. Only the first cause is kept.
+func (opts *RunnerOpts) recordOutcome(err error) {
+	if opts.errorBudget == nil || opts.ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		if !IsTransportError(err) {
+			return
+		}
+
+		opts.errorBudget.classify(err)
+	}
+
+	if diagnostic := opts.errorBudget.record(err != nil); diagnostic != nil && opts.ctx.Err() == nil {
+		logger.For(opts.ctx).Errorf("Aborting the scan, too many errors: %s", diagnostic.Error())
+		opts.stop(diagnostic)
 	}
 }
 
@@ -253,19 +379,22 @@ func (opts *RunnerOpts) setupOnMatchFn() {
 			logger.For(ctx).Warn("Your profile has an issue without confidence. This issue might be ignored")
 		}
 
+		// Sensitive data is redacted before being stored, and before being streamed.
+		res = RedactSensitiveData(prof, res, occ)
+
 		var param string
 		if ep != nil {
 			param = ep.Param(payload)
 		}
 
-		err := opts.fileSystem.StoreMatch(ctx, Match{
+		match := Match{
 			URL:                   url,
 			Requests:              reqs,
 			Responses:             res,
 			ProfileName:           prof.GetName(),
 			ProfileTags:           prof.GetTags(),
 			IssueName:             issue.GetIssueName(),
-			IssueSeverity:         issue.GetIssueSeverity(),
+			IssueSeverity:         opts.cfg.SeverityOverrides.Severity(prof.GetName(), issue.GetIssueSeverity()),
 			IssueConfidence:       issue.GetIssueConfidence(),
 			IssueDetail:           issue.GetIssueDetail(),
 			IssueBackground:       issue.GetIssueBackground(),
@@ -275,8 +404,18 @@ func (opts *RunnerOpts) setupOnMatchFn() {
 			ProfileType:           prof.GetType().String(),
 			Payload:               payload,
 			Occurrences:           occ,
+			Metadata:              MatchMetadata(ctx, opts.cfg.Metadata, prof, reqs, res, payload),
+			Origin:                MatchOriginOf(ctx, ep),
+			ResponseDiff:          ResponseDiffOf(ctx),
 			At:                    time.Now().UTC(),
-		})
+		}
+		match.ID = MatchID(match)
+
+		if len(opts.cfg.RequestIDHeader) > 0 {
+			match.RequestIDs = RequestIDs(reqs, opts.cfg.RequestIDHeader)
+		}
+
+		err := opts.fileSystem.StoreMatch(ctx, match)
 		if err != nil {
 			logger.For(ctx).Errorf("Error while storing scan match: %s", err.Error())
 		}
@@ -284,9 +423,23 @@ func (opts *RunnerOpts) setupOnMatchFn() {
 		if onMatchFn != nil {
 			onMatchFn(ctx, url, reqs, res, prof, issue, ep, payload, occ)
 		}
+
+		// The scan is stopped once the match has been stored (and streamed), so it isn't lost.
+		// Only the first cause is kept, so concurrent matches don't override the one that stopped it.
+		if opts.stopOnMatch != nil && opts.stopOnMatch(match) && opts.ctx.Err() == nil {
+			logger.For(ctx).Infof("Stopping the scan on match (id=%s): %s", match.ID, match.IssueName)
+			opts.stop(fmt.Errorf("%w: %s (id: %s): %w", ErrStoppedOnMatch, match.IssueName, match.ID, context.Canceled))
+		}
 	}
 }
 
+// stoppedOnPurpose returns whether the scan has been either stopped on match (see [RunnerOpts.WithStopOnMatch])
+// or aborted on errors (see [RunnerOpts.WithErrorThreshold]), so it won't be continued.
+func (opts *RunnerOpts) stoppedOnPurpose() bool {
+	cause := context.Cause(opts.ctx)
+	return errors.Is(cause, ErrStoppedOnMatch) || errors.Is(cause, ErrTooManyErrors)
+}
+
 func (opts *RunnerOpts) setupOnTaskFn() {
 	onTaskFn := opts.onTaskFn
 	opts.onTaskFn = func(ctx context.Context, url string, reqs []*request.Request, res []*response.Response) {
@@ -2,8 +2,20 @@ package scan_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
@@ -15,6 +27,7 @@ import (
 	"github.com/bountysecurity/gbounty/internal/profile/profilefakes"
 	"github.com/bountysecurity/gbounty/internal/request"
 	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
 	"github.com/bountysecurity/gbounty/kit/ulid"
 )
 
@@ -77,6 +90,926 @@ func TestRunner(t *testing.T) {
 			}))
 		require.NoError(t, r.Start())
 	})
+
+	t.Run("NoEntrypoints", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		aferoFs, basePath := initializeFsTest()
+		fs, err := filesystem.New(aferoFs, basePath)
+		require.NoError(t, err)
+
+		req := request.WithOptions("http://example.com/?id=1")
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, req, nil)))
+
+		requester := &countingRequester{res: response.Response{Code: 200, Body: []byte("Powered by gbounty")}}
+
+		var matches atomic.Int32
+
+		r := scan.NewRunner((&scan.RunnerOpts{}).
+			WithContext(ctx).
+			WithConfiguration(scan.Config{RPS: 10, Concurrency: 1, NoEntrypoints: true}).
+			WithRequesterBuilder(func() (scan.Requester, error) {
+				return requester, nil
+			}).
+			WithFileSystem(fs).
+			WithEntrypointFinders(entrypoint.Finders()).
+			WithActiveProfiles([]*profile.Active{
+				profilefakes.SQLiTimeBased(),
+			}).
+			WithPassiveResProfiles([]*profile.Response{{
+				Name:    "Powered by",
+				Enabled: true,
+				Type:    profile.TypePassiveRes,
+				Greps:   []string{"true,,Simple String,,Powered by"},
+			}}).
+			WithOnMatch(func(context.Context, string, []*request.Request, []*response.Response, profile.Profile, profile.IssueInformation, entrypoint.Entrypoint, string, [][]occurrence.Occurrence) {
+				matches.Add(1)
+			}))
+		require.NoError(t, r.Start())
+
+		// The template request must be sent as is (only once),
+		// and the response matchers must still be checked.
+		require.Equal(t, int32(1), requester.count.Load())
+		require.Equal(t, int32(1), matches.Load())
+	})
+}
+
+func TestRunner_Priority(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	priorities := map[string]int{
+		"http://example.com/low":     0,
+		"http://example.com/high":    10,
+		"http://example.com/medium1": 5,
+		"http://example.com/medium2": 5,
+	}
+
+	for idx, u := range []string{
+		"http://example.com/low",
+		"http://example.com/medium1",
+		"http://example.com/high",
+		"http://example.com/medium2",
+	} {
+		tpl := scan.NewTemplate(ctx, idx, request.WithOptions(u), nil)
+		tpl.Priority = priorities[u]
+		require.NoError(t, fs.StoreTemplate(ctx, tpl))
+	}
+
+	requester := &recordingRequester{}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 10, Concurrency: 1, NoEntrypoints: true, Prioritized: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}))
+	require.NoError(t, r.Start())
+
+	// Higher priorities first, equal priorities in their original order.
+	require.Equal(t, []string{
+		"http://example.com/high",
+		"http://example.com/medium1",
+		"http://example.com/medium2",
+		"http://example.com/low",
+	}, requester.urls)
+}
+
+func TestRunner_NotPrioritized(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	// The templates iterator is kept open until the first template is dispatched,
+	// so the scan would never start if the templates were all read first.
+	release := make(chan struct{})
+	blocking := &blockingTemplatesFS{
+		FileSystem: fs,
+		tpl:        scan.NewTemplate(ctx, 0, request.WithOptions("http://example.com/first"), nil),
+		release:    release,
+	}
+
+	requester := &recordingRequester{}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 10, Concurrency: 1, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(blocking).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}))
+
+	done := make(chan error, 1)
+	go func() { done <- r.Start() }()
+
+	require.Eventually(t, func() bool {
+		requester.Lock()
+		defer requester.Unlock()
+		return len(requester.urls) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"http://example.com/first"}, requester.urls)
+}
+
+// blockingTemplatesFS is a [scan.FileSystem] whose templates iterator
+// yields the given template, and then is kept open until released.
+type blockingTemplatesFS struct {
+	scan.FileSystem
+	tpl     scan.Template
+	release chan struct{}
+}
+
+func (fs *blockingTemplatesFS) TemplatesIterator(ctx context.Context) (chan scan.Template, error) {
+	ch := make(chan scan.Template)
+
+	go func() {
+		defer close(ch)
+
+		select {
+		case <-ctx.Done():
+			return
+		case ch <- fs.tpl:
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-fs.release:
+		}
+	}()
+
+	return ch, nil
+}
+
+func TestRunner_StopOnMatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for idx := 0; idx < 10; idx++ {
+		req := request.WithOptions(fmt.Sprintf("http://example.com/%d", idx))
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, req, nil)))
+	}
+
+	// Only the fourth response discloses the admin panel.
+	requester := &bodyRequester{bodies: map[string]string{"http://example.com/3": "Powered by gbounty, admin panel"}, fallback: "Powered by gbounty"}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{
+			{
+				Name:          "Powered by",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,Powered by"},
+				IssueName:     "Powered by",
+				IssueSeverity: "Low",
+			},
+			{
+				Name:          "Admin panel",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,admin panel"},
+				IssueName:     "Admin panel",
+				IssueSeverity: "High",
+			},
+		}).
+		WithStopOnMatch(func(m scan.Match) bool { return scan.SeverityAtLeast(m.IssueSeverity, "Medium") }).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+
+	err = r.Start()
+	require.ErrorIs(t, err, scan.ErrStoppedOnMatch)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The scan must be stopped right after the fourth request (concurrency is 1).
+	require.Equal(t, []string{"http://example.com/0", "http://example.com/1", "http://example.com/2", "http://example.com/3"}, requester.urls)
+
+	// The matches found so far, including the one that stopped the scan, must be kept.
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 5)
+	require.Equal(t, 5, stats.NumOfMatches)
+	require.Equal(t, "Admin panel", matches[len(matches)-1].IssueName)
+}
+
+func TestRunner_ErrorThreshold(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		threshold string
+		window    int
+		failing   func(idx int) bool
+		err       error
+		requests  int
+		aborted   bool
+	}{
+		"count":                 {threshold: "3", failing: func(int) bool { return true }, requests: 3, aborted: true},
+		"rate":                  {threshold: "50%", window: 4, failing: func(idx int) bool { return idx%2 == 1 }, requests: 4, aborted: true},
+		"rate below":            {threshold: "75%", window: 4, failing: func(idx int) bool { return idx%2 == 1 }, requests: 10},
+		"non-2xx aren't errors": {threshold: "1", failing: func(int) bool { return false }, requests: 10},
+		"non-transport errors":  {threshold: "1", failing: func(int) bool { return true }, err: errors.New("something unexpected"), requests: 10},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			aferoFs, basePath := initializeFsTest()
+			fs, err := filesystem.New(aferoFs, basePath)
+			require.NoError(t, err)
+
+			failing := make(map[string]bool)
+			for idx := 0; idx < 10; idx++ {
+				target := fmt.Sprintf("http://example.com/%d", idx)
+				failing[target] = tc.failing(idx)
+				require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, request.WithOptions(target), nil)))
+			}
+
+			threshold, err := scan.ParseErrorThreshold(tc.threshold, tc.window)
+			require.NoError(t, err)
+
+			requester := &failingRequester{failing: failing, err: tc.err}
+
+			var stats *scan.Stats
+
+			r := scan.NewRunner((&scan.RunnerOpts{}).
+				WithContext(ctx).
+				WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, NoEntrypoints: true}).
+				WithRequesterBuilder(func() (scan.Requester, error) {
+					return requester, nil
+				}).
+				WithFileSystem(fs).
+				WithEntrypointFinders(entrypoint.Finders()).
+				WithPassiveResProfiles([]*profile.Response{{
+					Name:    "Server error",
+					Enabled: true,
+					Type:    profile.TypePassiveRes,
+					Greps:   []string{"true,,Simple String,,Internal Server Error"},
+				}}).
+				WithErrorThreshold(threshold).
+				WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+
+			err = r.Start()
+
+			// The scan must be aborted right after the threshold is exceeded (concurrency is 1).
+			require.Len(t, requester.urls, tc.requests)
+			require.Equal(t, tc.requests, stats.NumOfPerformedRequests)
+
+			if !tc.aborted {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, scan.ErrTooManyErrors)
+			require.ErrorIs(t, err, context.Canceled)
+
+			var diagnostic *scan.TooManyErrorsError
+			require.ErrorAs(t, err, &diagnostic)
+			require.Equal(t, stats.NumOfFailedRequests, diagnostic.Failed)
+			require.Equal(t, scan.ErrorKindRefused, diagnostic.Kind)
+			require.Equal(t, diagnostic.Failed, diagnostic.OfKind)
+			require.Contains(t, diagnostic.Error(), "mostly due to connection refused")
+		})
+	}
+}
+
+func TestRunner_SkippedRequests(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	failing := make(map[string]bool)
+	for idx := 0; idx < 5; idx++ {
+		target := fmt.Sprintf("http://example.com/%d", idx)
+		failing[target] = true
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, request.WithOptions(target), nil)))
+	}
+
+	// Requests skipped (e.g. by the request hook) are neither failed nor errors.
+	requester := &failingRequester{failing: failing, err: fmt.Errorf("%w: %w", scan.ErrRequestSkipped, scan.ErrRequestHookFailed)}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Server error",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Internal Server Error"},
+		}}).
+		WithErrorThreshold(scan.ErrorThreshold{Count: 1}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+
+	require.NoError(t, r.Start())
+	require.Len(t, requester.urls, 5)
+	require.Equal(t, 5, stats.NumOfSkippedRequests)
+	require.Zero(t, stats.NumOfPerformedRequests)
+	require.Zero(t, stats.NumOfFailedRequests)
+
+	errs, err := fs.LoadErrors(ctx)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+}
+
+func TestRunner_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for idx, path := range []string{"/login", "/other"} {
+		req := request.WithOptions("http://example.com" + path)
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, req, nil)))
+	}
+
+	// The login endpoint throttles after the third request. There are
+	// no active profiles, so only the burst requests are sent.
+	requester := &throttlingRequester{limit: 3, counts: make(map[string]int)}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{
+			RPS:         100,
+			Concurrency: 1,
+			RateLimit:   scan.RateLimitCfg{Burst: 10, Match: regexp.MustCompile(`/login$`)},
+		}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{
+			{
+				Name:          "Admin panel",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,admin panel"},
+				IssueName:     "Admin panel",
+				IssueSeverity: "High",
+			},
+		}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+
+	require.NoError(t, r.Start())
+
+	// The rest of the burst isn't sent once throttled, and the other endpoint isn't probed.
+	require.Equal(t, map[string]int{"http://example.com/login": 4}, requester.counts)
+	require.Equal(t, 4, stats.NumOfPerformedRequests)
+
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "Rate limiting detected", matches[0].IssueName)
+	require.Equal(t, "3", matches[0].Metadata[scan.MetadataRateLimitThreshold])
+	require.Equal(t, "throttled after 3 request(s) (429), out of 4 sent", matches[0].Metadata[scan.MetadataRateLimit])
+	require.Len(t, matches[0].Requests, 2)
+}
+
+func TestRunner_MassAssignment(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	req := request.WithOptions("http://example.com/users?id=1")
+	require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, 0, req, nil)))
+
+	// The endpoint redirects when the role parameter is added,
+	// and there are no active profiles, so only the baselines
+	// and the variants (4 params and 1 duplicate) are sent.
+	requester := &massAssignmentRequester{}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{
+			RPS:            100,
+			Concurrency:    1,
+			MassAssignment: scan.MassAssignmentCfg{Enabled: true, Params: scan.DefaultMassAssignmentParams},
+		}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{
+			{
+				Name:          "Admin panel",
+				Enabled:       true,
+				Type:          profile.TypePassiveRes,
+				Greps:         []string{"true,,Simple String,,admin panel"},
+				IssueName:     "Admin panel",
+				IssueSeverity: "High",
+			},
+		}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+
+	require.NoError(t, r.Start())
+
+	require.Equal(t, 7, int(requester.count.Load()))
+	require.Equal(t, 7, stats.NumOfPerformedRequests)
+
+	matches, err := fs.LoadMatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "Mass assignment", matches[0].IssueName)
+	require.Equal(t, "role=admin", matches[0].Payload)
+	require.Equal(t, "role=admin (query): status 200 -> 302, body: 1 line(s) removed, 1 line(s) added", matches[0].Metadata[scan.MetadataMassAssignment])
+	require.NotNil(t, matches[0].ResponseDiff)
+	require.Len(t, matches[0].Requests, 2)
+	require.Equal(t, "/users?id=1&role=admin", matches[0].Requests[1].Path)
+}
+
+func TestRunner_Warmup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := templatesFs(t, ctx, map[string]int{"a.example.com": 3, "b.example.com": 2})
+
+	requester := &bodyRequester{fallback: "Powered by gbounty"}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 4, NoEntrypoints: true, Warmup: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+	require.NoError(t, r.Start())
+
+	// A single warmup request is sent per host, before any other request to it,
+	// and it is neither accounted as a scan request, nor analyzed (no findings).
+	require.Len(t, requester.urls, 7)
+	require.Equal(t, 2, stats.NumOfWarmupRequests)
+	require.Equal(t, 0, stats.NumOfFailedWarmupRequests)
+	require.Equal(t, 5, stats.NumOfPerformedRequests)
+	require.Equal(t, 5, stats.NumOfMatches)
+
+	seen := make(map[string]int)
+	for _, u := range requester.urls {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		seen[parsed.Host]++
+	}
+	require.Equal(t, map[string]int{"a.example.com": 4, "b.example.com": 3}, seen)
+
+	// The warmup request is a HEAD request to the origin's root, not the template's one.
+	var warmups []string
+	for i, u := range requester.urls {
+		if requester.methods[i] == http.MethodHead {
+			warmups = append(warmups, u)
+		}
+	}
+	require.ElementsMatch(t, []string{"http://a.example.com/", "http://b.example.com/"}, warmups)
+}
+
+func TestRunner_TemplateFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := templatesFs(t, ctx, map[string]int{"a.example.com": 3, "b.example.com": 2})
+
+	filter, err := scan.ParseTemplateFilter(`{{request.method}} == GET && {{request.host}} matches '^a\.'`)
+	require.NoError(t, err)
+
+	requester := &bodyRequester{fallback: "Powered by gbounty"}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 4, NoEntrypoints: true, TemplateFilter: filter}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+	require.NoError(t, r.Start())
+
+	// Only the templates the filter holds for are scanned, the rest are counted as filtered.
+	require.Len(t, requester.urls, 3)
+	require.Equal(t, 2, stats.NumOfFilteredTemplates)
+	require.Equal(t, 3, stats.NumOfPerformedRequests)
+	require.Len(t, stats.TemplatesEnded, 5)
+
+	for _, u := range requester.urls {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		require.Equal(t, "a.example.com", parsed.Host)
+	}
+}
+
+func TestRunner_ConcurrencyPerHost(t *testing.T) {
+	t.Parallel()
+
+	const (
+		concurrency = 4
+		perHost     = 2
+	)
+
+	ctx := context.Background()
+	fs := templatesFs(t, ctx, map[string]int{"a.example.com": 8, "b.example.com": 4, "c.example.com": 4})
+
+	requester := &inFlightRequester{delay: 10 * time.Millisecond, hosts: make(map[string]int), maxPerHost: make(map[string]int)}
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 10, Concurrency: concurrency, ConcurrencyPerHost: perHost, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}))
+	require.NoError(t, r.Start())
+
+	// All the templates must be scanned, with no more than
+	// perHost (nor concurrency) requests in flight at any time.
+	require.Equal(t, 16, requester.count)
+	require.LessOrEqual(t, requester.maxInFlight, concurrency)
+	require.Greater(t, requester.maxInFlight, 1)
+	for host, max := range requester.maxPerHost {
+		require.LessOrEqual(t, max, perHost, host)
+	}
+}
+
+func TestRunner_TimeBudgetPerHost(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	// All the templates targeting a.example.com come first.
+	urls := []string{
+		"http://a.example.com/0", "http://a.example.com/1", "http://a.example.com/2",
+		"http://a.example.com/3", "http://a.example.com/4", "http://a.example.com/5",
+		"http://b.example.com/0", "http://b.example.com/1",
+	}
+	for idx, u := range urls {
+		require.NoError(t, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, request.WithOptions(u), nil)))
+	}
+
+	requester := &recordingRequester{delay: 20 * time.Millisecond}
+
+	var stats *scan.Stats
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, TimeBudgetPerHost: 30 * time.Millisecond, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+	require.NoError(t, r.Start())
+
+	// All the templates must be scanned, but b.example.com must not wait
+	// for a.example.com to be completed, as it's paused once over budget.
+	require.ElementsMatch(t, urls, requester.urls)
+	require.Less(t, slices.Index(requester.urls, "http://b.example.com/1"), slices.Index(requester.urls, "http://a.example.com/5"))
+
+	require.Len(t, stats.HostsTimeSpent, 2)
+	require.Greater(t, stats.HostsTimeSpent["a.example.com"], stats.HostsTimeSpent["b.example.com"])
+}
+
+func TestRunner_TemplatesStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	requester := &recordingRequester{delay: 10 * time.Millisecond}
+
+	var (
+		stream = make(chan scan.Template)
+		urls   []string
+		ahead  int
+		stats  *scan.Stats
+	)
+
+	go func() {
+		defer close(stream)
+
+		for idx := 0; idx < 10; idx++ {
+			u := fmt.Sprintf("http://example.com/%d", idx)
+			urls = append(urls, u)
+
+			requester.Lock()
+			ahead = max(ahead, idx-len(requester.urls))
+			requester.Unlock()
+
+			stream <- scan.NewTemplate(ctx, idx, request.WithOptions(u), nil)
+		}
+	}()
+
+	r := scan.NewRunner((&scan.RunnerOpts{}).
+		WithContext(ctx).
+		WithConfiguration(scan.Config{RPS: 100, Concurrency: 1, NoEntrypoints: true}).
+		WithRequesterBuilder(func() (scan.Requester, error) {
+			return requester, nil
+		}).
+		WithFileSystem(fs).
+		WithTemplatesStream(stream).
+		WithEntrypointFinders(entrypoint.Finders()).
+		WithPassiveResProfiles([]*profile.Response{{
+			Name:    "Powered by",
+			Enabled: true,
+			Type:    profile.TypePassiveRes,
+			Greps:   []string{"true,,Simple String,,Powered by"},
+		}}).
+		WithOnFinished(func(s *scan.Stats, _ error) { stats = s }))
+	require.NoError(t, r.Start())
+
+	// All the templates must be scanned, in arrival order, with
+	// their tasks calculated as these arrive.
+	require.Equal(t, urls, requester.urls)
+	require.Equal(t, 10, stats.NumOfTotalRequests)
+	require.Equal(t, 10, stats.NumOfPerformedRequests)
+
+	// The stream must not be read much further than the templates
+	// scanned (i.e. back-pressure), as concurrency is 1.
+	require.LessOrEqual(t, ahead, 4)
+}
+
+func BenchmarkRunner_ConcurrencyPerHost(b *testing.B) {
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		fs := templatesFs(b, ctx, map[string]int{"a.example.com": 100, "b.example.com": 50, "c.example.com": 50, "d.example.com": 50})
+		requester := &inFlightRequester{delay: time.Millisecond, hosts: make(map[string]int), maxPerHost: make(map[string]int)}
+		b.StartTimer()
+
+		r := scan.NewRunner((&scan.RunnerOpts{}).
+			WithContext(ctx).
+			WithConfiguration(scan.Config{RPS: 1000, Concurrency: 20, ConcurrencyPerHost: 5, NoEntrypoints: true}).
+			WithRequesterBuilder(func() (scan.Requester, error) {
+				return requester, nil
+			}).
+			WithFileSystem(fs).
+			WithEntrypointFinders(entrypoint.Finders()).
+			WithPassiveResProfiles([]*profile.Response{{
+				Name:    "Powered by",
+				Enabled: true,
+				Type:    profile.TypePassiveRes,
+				Greps:   []string{"true,,Simple String,,Powered by"},
+			}}))
+		require.NoError(b, r.Start())
+	}
+}
+
+func templatesFs(tb testing.TB, ctx context.Context, templatesPerHost map[string]int) scan.FileSystem {
+	tb.Helper()
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(tb, err)
+
+	var idx int
+	for host, n := range templatesPerHost {
+		for i := 0; i < n; i++ {
+			req := request.WithOptions(fmt.Sprintf("http://%s/%d", host, i))
+			require.NoError(tb, fs.StoreTemplate(ctx, scan.NewTemplate(ctx, idx, req, nil)))
+			idx++
+		}
+	}
+
+	return fs
+}
+
+// inFlightRequester keeps track of the maximum amount
+// of requests in flight, both in total and per host.
+type inFlightRequester struct {
+	sync.Mutex
+	delay       time.Duration
+	count       int
+	inFlight    int
+	maxInFlight int
+	hosts       map[string]int
+	maxPerHost  map[string]int
+}
+
+func (ir *inFlightRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return response.Response{}, err
+	}
+
+	ir.Lock()
+	ir.count++
+	ir.inFlight++
+	ir.hosts[u.Host]++
+	ir.maxInFlight = max(ir.maxInFlight, ir.inFlight)
+	ir.maxPerHost[u.Host] = max(ir.maxPerHost[u.Host], ir.hosts[u.Host])
+	ir.Unlock()
+
+	time.Sleep(ir.delay)
+
+	ir.Lock()
+	ir.inFlight--
+	ir.hosts[u.Host]--
+	ir.Unlock()
+
+	return response.Response{}, nil
+}
+
+type recordingRequester struct {
+	sync.Mutex
+	delay time.Duration
+	urls  []string
+}
+
+func (rr *recordingRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	rr.Lock()
+	rr.urls = append(rr.urls, req.URL)
+	rr.Unlock()
+
+	time.Sleep(rr.delay)
+
+	return response.Response{}, nil
+}
+
+type bodyRequester struct {
+	sync.Mutex
+	bodies   map[string]string
+	fallback string
+	urls     []string
+	methods  []string
+}
+
+func (br *bodyRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	br.Lock()
+	defer br.Unlock()
+	br.urls = append(br.urls, req.URL)
+	br.methods = append(br.methods, req.Method)
+
+	body, ok := br.bodies[req.URL]
+	if !ok {
+		body = br.fallback
+	}
+
+	return response.Response{Code: 200, Body: []byte(body)}, nil
+}
+
+type failingRequester struct {
+	sync.Mutex
+	failing map[string]bool
+	err     error
+	urls    []string
+}
+
+func (fr *failingRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	fr.Lock()
+	defer fr.Unlock()
+	fr.urls = append(fr.urls, req.URL)
+
+	if fr.failing[req.URL] {
+		if fr.err != nil {
+			return response.Response{}, fr.err
+		}
+
+		return response.Response{}, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+
+	return response.Response{Code: 500, Body: []byte("Internal Server Error")}, nil
+}
+
+type throttlingRequester struct {
+	sync.Mutex
+	limit  int
+	counts map[string]int
+}
+
+func (tr *throttlingRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	tr.Lock()
+	defer tr.Unlock()
+
+	tr.counts[req.URL]++
+	if tr.counts[req.URL] > tr.limit {
+		return response.Response{Code: 429}, nil
+	}
+
+	return response.Response{Code: 200}, nil
+}
+
+type massAssignmentRequester struct {
+	count atomic.Int32
+}
+
+func (mr *massAssignmentRequester) Do(_ context.Context, req *request.Request) (response.Response, error) {
+	mr.count.Add(1)
+	if strings.Contains(req.Path, "role=admin") {
+		return response.Response{Code: 302}, nil
+	}
+
+	return response.Response{Code: 200, Body: []byte("ok")}, nil
+}
+
+type countingRequester struct {
+	count atomic.Int32
+	res   response.Response
+}
+
+func (cr *countingRequester) Do(context.Context, *request.Request) (response.Response, error) {
+	cr.count.Add(1)
+	return cr.res, nil
 }
 
 type fakeRequester struct{}
@@ -44,6 +44,25 @@ func (low *LineOfWork) appendEntrypoints(entrypoints []entrypoint.Entrypoint) {
 	low.Entrypoints = append(low.Entrypoints, entrypoints...)
 }
 
+// findEntrypoints finds the entrypoints of the [LineOfWork]'s template with
+// the given finders, appends them and returns the amount of entrypoints found.
+func (low *LineOfWork) findEntrypoints(ctx context.Context, finders []entrypoint.Finder) int {
+	var total int
+	for _, finder := range finders {
+		entrypointsFound := finder.Find(low.Template.Request)
+
+		logger.For(ctx).Debugf(
+			"Entrypoints found for template (idx=%d) and finder(%T): %d",
+			low.Template.Idx, finder, len(entrypointsFound),
+		)
+
+		low.appendEntrypoints(entrypointsFound)
+		total += len(entrypointsFound)
+	}
+
+	return total
+}
+
 func (low *LineOfWork) registerMatch(matchId string) {
 	low.Lock()
 	defer low.Unlock()
@@ -70,6 +89,7 @@ func (low *LineOfWork) prepareTasks(
 	prof *profile.Active,
 	blindHostDefined bool,
 	emailAddressDefined bool,
+	hostReqs *hostRequests,
 ) (totalTasks int, skipped bool) {
 	// We first check if the profile should be skipped.
 	// For instance, in case any of its steps is a raw request that contains an undefined label.
@@ -88,6 +108,16 @@ func (low *LineOfWork) prepareTasks(
 		return 1, false
 	}
 
+	// If it is a host request, then we just add one task, but only for the first template
+	// of each host (see [hostRequests]), as its request doesn't depend on the template.
+	if step.RequestType.HostRequest() {
+		if !hostReqs.first(prof, low.Template) {
+			return 0, false
+		}
+		low.Tasks = append(low.Tasks, &Task{Profile: prof, StepIdx: sIdx, PayloadIdx: -1, LoW: low})
+		return 1, false
+	}
+
 	// Otherwise, there'll be one for each payload in the first step of the profile,
 	// and for each LineOfWork entrypoint, plus the ones entrypoint.From step.
 	stepEntrypoints := entrypoint.From(step)
@@ -155,23 +185,7 @@ func profileShouldBeSkipped(
 }
 
 //nolint:nolintlint,gocyclo
-func (low *LineOfWork) executeTasks(
-	ctx context.Context,
-	fn RequesterBuilder,
-	bhPoller BlindHostPoller,
-	onRequestsScheduled, onRequestsSkipped func(int),
-	onUpdate func(bool, bool, bool),
-	onErrorFn onErrorFunc,
-	onMatchFn onMatchFunc,
-	onTaskFn onTaskFunc,
-	rps int,
-	saveAllRequests, saveResponses, saveAllResponses bool,
-	baseModifiers []Modifier,
-	passiveReqProfiles []*profile.Request,
-	passiveResProfiles []*profile.Response,
-	customTokens CustomTokens,
-	payloadStrategy PayloadStrategy,
-) {
+func (low *LineOfWork) executeTasks(ctx context.Context, rps int, env taskEnv) {
 	// We set the throttle to the desired rate of requests per second.
 	// It is important to prevent flooding the endpoint.
 	throttle := time.NewTicker(time.Duration(1e6/(rps)) * time.Microsecond) //nolint:mnd
@@ -240,26 +254,7 @@ func (low *LineOfWork) executeTasks(
 
 			// Finally, we actually trigger the task execution.
 			// Which might be either a base request, or an injected request.
-			task.run(
-				ctx,
-				low.Template,
-				fn,
-				bhPoller,
-				onRequestsScheduled,
-				onRequestsSkipped,
-				onMatchFn,
-				onErrorFn,
-				onTaskFn,
-				onUpdate,
-				saveAllRequests,
-				saveResponses,
-				saveAllResponses,
-				baseModifiers,
-				passiveReqProfiles,
-				passiveResProfiles,
-				customTokens,
-				payloadStrategy,
-			)
+			task.run(ctx, low.Template, env)
 		}()
 	}
 
@@ -302,7 +297,7 @@ func (low *LineOfWork) numOfSucceedTasks() (n int) {
 	return
 }
 
-func shouldFollowRedirect(ctx context.Context, req *request.Request, res *response.Response) bool {
+func shouldFollowRedirect(ctx context.Context, req *request.Request, res *response.Response, policy RedirectPolicy) bool {
 	if ctx.Err() != nil {
 		return false
 	}
@@ -326,6 +321,9 @@ func shouldFollowRedirect(ctx context.Context, req *request.Request, res *respon
 
 	req.FollowedRedirects++
 
+	prevURL := requestURL(req)
+	defer policy.apply(req, res, prevURL)
+
 	if strings.HasPrefix(loc, "/") {
 		req.Path = loc
 		return true
@@ -344,7 +342,7 @@ func shouldFollowRedirect(ctx context.Context, req *request.Request, res *respon
 	}
 
 	if u != nil {
-		req.Headers["Host"] = []string{u.Host}
+		req.SetHeader("Host", u.Host)
 	}
 
 	return true
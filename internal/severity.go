@@ -0,0 +1,99 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// ErrUnknownOverriddenProfile is the error returned by [SeverityOverrides.Check]
+// when the severity of any unknown (i.e. not loaded) profile is overridden.
+var ErrUnknownOverriddenProfile = errors.New("unknown profile with overridden severity")
+
+// Severities returns the valid issue severities, from the highest to the lowest.
+func Severities() []string {
+	return []string{"High", "Medium", "Low", "Information"}
+}
+
+// ParseSeverity returns the given severity in its canonical form (e.g. High for
+// high), see [Severities], and whether it is valid or not.
+func ParseSeverity(s string) (string, bool) {
+	for _, severity := range Severities() {
+		if strings.EqualFold(strings.TrimSpace(s), severity) {
+			return severity, true
+		}
+	}
+
+	return s, false
+}
+
+// SeverityAtLeast returns whether the given severity is, at least, as high as the given minimum
+// one (see [Severities]), in a case-insensitive manner. Unknown severities are lower than any,
+// so any severity is, at least, as high as an empty (or unknown) minimum.
+func SeverityAtLeast(severity, minimum string) bool {
+	rank := func(s string) int {
+		severities := Severities()
+		for idx, severity := range severities {
+			if strings.EqualFold(strings.TrimSpace(s), severity) {
+				return len(severities) - idx
+			}
+		}
+		return 0
+	}
+
+	return rank(severity) >= rank(minimum)
+}
+
+// SeverityOverrides maps profile names to the severity the issues found by those are
+// reported with, instead of the one defined by the profile. So, severities can be aligned
+// with each team's own risk model, with no need to fork the profiles.
+//
+// Overrides are applied when the [Match] instances are created, so these are reflected
+// in every output, as well as in the baseline comparison.
+type SeverityOverrides map[string]string
+
+// Severity returns the severity the issue found by the given profile is
+// reported with: the overridden one, if any, or the given one otherwise.
+func (o SeverityOverrides) Severity(profileName, severity string) string {
+	if overridden, ok := o[profileName]; ok {
+		return overridden
+	}
+
+	return severity
+}
+
+// Check returns an error if the severity of any profile other than
+// the given ones (i.e. those loaded) is overridden, likely a typo.
+func (o SeverityOverrides) Check(profileNames []string) error {
+	var unknown []string
+	for name := range o {
+		if !slices.In(profileNames, name) {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("%w: %s", ErrUnknownOverriddenProfile, strings.Join(unknown, ", "))
+}
+
+// Clone returns a copy of the [SeverityOverrides] instance.
+func (o SeverityOverrides) Clone() SeverityOverrides {
+	if o == nil {
+		return nil
+	}
+
+	cloned := make(SeverityOverrides, len(o))
+	for k, v := range o {
+		cloned[k] = v
+	}
+
+	return cloned
+}
@@ -0,0 +1,67 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		severity string
+		exp      string
+		valid    bool
+	}{
+		"canonical": {severity: "High", exp: "High", valid: true},
+		"lowercase": {severity: "information", exp: "Information", valid: true},
+		"padded":    {severity: " low ", exp: "Low", valid: true},
+		"unknown":   {severity: "Critical", exp: "Critical", valid: false},
+		"empty":     {severity: "", exp: "", valid: false},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			severity, valid := scan.ParseSeverity(tc.severity)
+			assert.Equal(t, tc.exp, severity)
+			assert.Equal(t, tc.valid, valid)
+		})
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, scan.SeverityAtLeast("High", "medium"))
+	assert.True(t, scan.SeverityAtLeast("medium", "Medium"))
+	assert.False(t, scan.SeverityAtLeast("Low", "Medium"))
+	assert.False(t, scan.SeverityAtLeast("Critical", "Information"))
+	assert.True(t, scan.SeverityAtLeast("Information", ""))
+	assert.True(t, scan.SeverityAtLeast("", ""))
+}
+
+func TestSeverityOverrides(t *testing.T) {
+	t.Parallel()
+
+	overrides := scan.SeverityOverrides{"Email disclosure": "Low", "Open Redirect": "High"}
+
+	assert.Equal(t, "Low", overrides.Severity("Email disclosure", "Medium"))
+	assert.Equal(t, "Medium", overrides.Severity("SQL Injection", "Medium"))
+	assert.Equal(t, "Medium", scan.SeverityOverrides(nil).Severity("Email disclosure", "Medium"))
+
+	assert.NoError(t, overrides.Check([]string{"Open Redirect", "Email disclosure", "SQL Injection"}))
+
+	err := overrides.Check([]string{"SQL Injection"})
+	assert.ErrorIs(t, err, scan.ErrUnknownOverriddenProfile)
+	assert.ErrorContains(t, err, "Email disclosure, Open Redirect")
+
+	cloned := overrides.Clone()
+	cloned["SQL Injection"] = "Information"
+	assert.Len(t, overrides, 2)
+}
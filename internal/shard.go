@@ -0,0 +1,84 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/panics"
+)
+
+// Shard defines the portion of the templates scanned by a runner, so the same
+// scan can be split across multiple runners (e.g. machines), with no overlaps.
+//
+// A runner only scans those templates whose key (see [Shard.Includes]) modulo
+// Count equals Index. So, Index must be in the range [0, Count).
+// A Count lower than two means no sharding (i.e. all the templates are scanned).
+type Shard struct {
+	Index int
+	Count int
+}
+
+// IsEmpty returns whether the [Shard] is not set, so all the templates are scanned.
+func (s Shard) IsEmpty() bool {
+	return s.Count < 2
+}
+
+// Includes returns whether the given [Template] belongs to the [Shard].
+//
+// The template's key is a hash of its method, url and body, instead of its index,
+// so the sharding is deterministic and stable regardless of the order the inputs
+// (e.g. urls, requests or wordlist lines) are given in.
+func (s Shard) Includes(tpl Template) bool {
+	if s.IsEmpty() {
+		return true
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(tpl.Method))
+	_, _ = h.Write([]byte{' '})
+	_, _ = h.Write([]byte(tpl.OriginalURL))
+	_, _ = h.Write([]byte{'\n'})
+	_, _ = h.Write(tpl.Body)
+
+	return h.Sum64()%uint64(s.Count) == uint64(s.Index)
+}
+
+// String returns the string representation of the [Shard], as i/n.
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// shard takes a channel of [Template] (e.g. [FileSystemTemplates.TemplatesIterator])
+// and returns another channel that only yields those included by the given [Shard].
+func shard(ctx context.Context, in chan Template, s Shard) chan Template {
+	if s.IsEmpty() {
+		return in
+	}
+
+	out := make(chan Template)
+
+	go func() {
+		defer panics.Log(ctx)
+		defer close(out)
+
+		var skipped int
+		for tpl := range in {
+			if !s.Includes(tpl) {
+				skipped++
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- tpl:
+			}
+		}
+
+		logger.For(ctx).Debugf("Templates skipped by shard (%s): %d", s, skipped)
+	}()
+
+	return out
+}
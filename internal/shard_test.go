@@ -0,0 +1,67 @@
+package scan_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestShard_Includes(t *testing.T) {
+	t.Parallel()
+
+	templates := make([]scan.Template, 0, 100)
+	for i := 0; i < 100; i++ {
+		url := fmt.Sprintf("https://example.com/word%d", i)
+		templates = append(templates, scan.Template{
+			Idx:         i,
+			OriginalURL: url,
+			Request:     request.Request{Method: "GET", URL: url},
+		})
+	}
+
+	t.Run("no sharding", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tpl := range templates {
+			assert.True(t, scan.Shard{}.Includes(tpl))
+			assert.True(t, scan.Shard{Index: 0, Count: 1}.Includes(tpl))
+		}
+	})
+
+	t.Run("each template belongs to exactly one shard", func(t *testing.T) {
+		t.Parallel()
+
+		const count = 3
+		perShard := make([]int, count)
+
+		for _, tpl := range templates {
+			var n int
+			for i := 0; i < count; i++ {
+				if (scan.Shard{Index: i, Count: count}).Includes(tpl) {
+					perShard[i]++
+					n++
+				}
+			}
+			assert.Equal(t, 1, n, tpl.OriginalURL)
+		}
+
+		for i, n := range perShard {
+			assert.Positive(t, n, "shard %d/%d is empty", i, count)
+		}
+	})
+
+	t.Run("stable regardless of the index", func(t *testing.T) {
+		t.Parallel()
+
+		s := scan.Shard{Index: 1, Count: 4}
+		for _, tpl := range templates {
+			moved := tpl
+			moved.Idx = len(templates) - tpl.Idx
+			assert.Equal(t, s.Includes(tpl), s.Includes(moved))
+		}
+	})
+}
@@ -3,6 +3,8 @@ package scan
 import (
 	"sync"
 	"time"
+
+	"github.com/bountysecurity/gbounty/internal/response"
 )
 
 // Stats is a structure that holds multiple stats about the [scan] process,
@@ -17,11 +19,44 @@ type Stats struct {
 	NumOfRequestsToAnalyze  int
 	NumOfResponsesToAnalyze int
 
+	NumOfSkippedBodies     int
+	NumOfFilteredResponses int
+
+	NumOfDroppedByURLMatch  int
+	NumOfDroppedByURLReject int
+	NumOfDroppedByExtension int
+	NumOfSkippedTemplates   int
+	NumOfFilteredTemplates  int
+
+	// NumOfSkippedHosts is the amount of hosts skipped as already
+	// seen in previous runs, before the scan started.
+	NumOfSkippedHosts int
+
+	NumOfMatcherTimeouts int
+
+	// NumOfWarmupRequests is the amount of warmup requests sent (see [Config.Warmup]),
+	// not accounted as scan requests, and NumOfFailedWarmupRequests those that failed.
+	NumOfWarmupRequests       int
+	NumOfFailedWarmupRequests int
+
+	// HostsTimeSpent is the wall-clock time each host has been scanned
+	// for, only accounted when a time budget per host is set.
+	HostsTimeSpent map[string]time.Duration
+
+	// Responses are the distributions (i.e. histograms and percentiles) of the sizes and
+	// latencies of the responses received, and HostsResponses those per host.
+	Responses      *ResponseStats
+	HostsResponses map[string]*ResponseStats
+
 	TemplatesEnded map[int]struct{}
 
 	NumOfEntrypoints int
 	NumOfMatches     int
 
+	// NumOfGroupedMatches is the amount of findings once grouped, only
+	// reported when those are (see [WithFindingsGrouping]).
+	NumOfGroupedMatches int
+
 	StartedAt time.Time
 
 	sync.Mutex
@@ -61,6 +96,73 @@ func (s *Stats) incrementSkippedRequests(n int) {
 	s.Unlock()
 }
 
+func (s *Stats) incrementSkippedBodies(n int) {
+	s.Lock()
+	s.NumOfSkippedBodies += n
+	s.Unlock()
+}
+
+func (s *Stats) incrementFilteredResponses(n int) {
+	s.Lock()
+	s.NumOfFilteredResponses += n
+	s.Unlock()
+}
+
+func (s *Stats) incrementMatcherTimeouts(n int) {
+	s.Lock()
+	s.NumOfMatcherTimeouts += n
+	s.Unlock()
+}
+
+func (s *Stats) incrementWarmupRequests(failed bool) {
+	s.Lock()
+	s.NumOfWarmupRequests++
+	if failed {
+		s.NumOfFailedWarmupRequests++
+	}
+	s.Unlock()
+}
+
+func (s *Stats) addHostsTimeSpent(spent map[string]time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.HostsTimeSpent == nil {
+		s.HostsTimeSpent = make(map[string]time.Duration, len(spent))
+	}
+
+	for host, d := range spent {
+		s.HostsTimeSpent[host] += d
+	}
+}
+
+func (s *Stats) addResponse(host string, res *response.Response) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Responses == nil {
+		s.Responses = NewResponseStats()
+	}
+	s.Responses.Add(res)
+
+	if s.HostsResponses == nil {
+		s.HostsResponses = make(map[string]*ResponseStats)
+	}
+
+	hostStats, ok := s.HostsResponses[host]
+	if !ok {
+		hostStats = NewResponseStats()
+		s.HostsResponses[host] = hostStats
+	}
+	hostStats.Add(res)
+}
+
+func (s *Stats) incrementFilteredTemplates(n int) {
+	s.Lock()
+	s.NumOfFilteredTemplates += n
+	s.Unlock()
+}
+
 func (s *Stats) markTemplateAsEnded(i int) {
 	s.Lock()
 	s.TemplatesEnded[i] = struct{}{}
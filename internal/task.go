@@ -18,12 +18,47 @@ import (
 	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
 )
 
+// TaskKind determines what a [Task] does with the template's request.
+//
+// Tasks of any kind other than [TaskKindStep] aren't associated to any profile.
+// Thus, like base tasks, these do not have a step nor a payload, nor an entrypoint,
+// and the equivalent matches aren't looked for (see [PayloadStrategy]).
+type TaskKind int
+
+const (
+	// TaskKindStep tasks run a step of the associated profile or, if base tasks
+	// (see [Task.IsBase]), only perform passive scans on the template's request.
+	TaskKindStep TaskKind = iota
+	// TaskKindRaw tasks send the template's request as is (see [Config.NoEntrypoints]),
+	// with no injection, and only passive scans are performed on both request & response.
+	TaskKindRaw
+	// TaskKindDiff tasks send two variants of the template's request, and compare
+	// their responses (see [ResponseDiffCfg]).
+	TaskKindDiff
+	// TaskKindBurst tasks send a burst of the template's request, and aggregate
+	// the responses (see [RateLimitCfg]).
+	TaskKindBurst
+	// TaskKindMassAssignment tasks send the template's request as is (twice), and with
+	// extra parameters, and compare the responses (see [MassAssignmentCfg]).
+	TaskKindMassAssignment
+	// TaskKindGraphQL tasks send the template's request as an introspection query (see
+	// [GraphQLIntrospectionRequest]), and the response is only analyzed looking for an
+	// introspection result (see [GraphQLIntrospectionProfile]).
+	TaskKindGraphQL
+	// TaskKindCachePoisoning tasks send the template's request with a poisoned header, and then
+	// with no such header (clean), per header, and the clean responses are looked for the poisoned
+	// values (see [CachePoisoningCfg]).
+	TaskKindCachePoisoning
+)
+
 // Task is an atomic unit of work within a `scan`, which is what composes a [Template].
 type Task struct {
 	// IsBase is true if the task is a base task.
 	// In such case, the task is not associated to a profile.
 	// Thus, does not have a step nor a payload, nor an entrypoint.
 	IsBase bool
+	// Kind is the kind of the task (see [TaskKind]).
+	Kind TaskKind
 
 	// Profile is the profile associated with the task. If defined, always as profile.ActiveProfile.
 	Profile *profile.Active
@@ -51,6 +86,12 @@ type Task struct {
 	Entrypoint entrypoint.Entrypoint
 }
 
+// newKindTask returns a new [Task] of the given [TaskKind] (other than [TaskKindStep]),
+// within the given [LineOfWork].
+func newKindTask(kind TaskKind, low *LineOfWork) *Task {
+	return &Task{Kind: kind, StepIdx: -1, PayloadIdx: -1, LoW: low}
+}
+
 func (t *Task) matchId() string {
 	return fmt.Sprintf("%s/%d/%d", t.Profile.Name, t.StepIdx, t.EntrypointIdx)
 }
@@ -69,6 +110,7 @@ func (t *Task) clone() *Task {
 
 	return &Task{
 		IsBase:        t.IsBase,
+		Kind:          t.Kind,
 		Profile:       t.Profile,
 		StepIdx:       t.StepIdx,
 		PayloadIdx:    t.PayloadIdx,
@@ -84,6 +126,26 @@ func (t *Task) clone() *Task {
 	}
 }
 
+// entrypoint returns the [entrypoint.Entrypoint] the task's payload is injected into (see [Task.runStep]),
+// either the one attached to the task or the one referred from the [LineOfWork], if any. That is, nil
+// for raw and host request steps, as these are sent as defined.
+func (t *Task) entrypoint() entrypoint.Entrypoint {
+	step := t.Profile.Steps[t.StepIdx]
+	if step.RequestType.RawRequest() || step.RequestType.HostRequest() {
+		return nil
+	}
+
+	if t.Entrypoint != nil {
+		return t.Entrypoint
+	}
+
+	if t.EntrypointIdx >= 0 && t.EntrypointIdx < len(t.LoW.Entrypoints) {
+		return t.LoW.Entrypoints[t.EntrypointIdx]
+	}
+
+	return nil
+}
+
 func (t *Task) payloadEncoded() string {
 	_, payload, _ := t.Profile.Steps[t.StepIdx].PayloadAtEncoded(t.PayloadIdx)
 
@@ -96,33 +158,79 @@ func (t *Task) payloadDecoded() string {
 	return payload
 }
 
+// taskEnv is what tasks need to run: the requester builder, the callbacks the results
+// are reported through, and the scan settings the tasks (of any [TaskKind]) depend on.
+type taskEnv struct {
+	fn                                               RequesterBuilder
+	bhPoller                                         BlindHostPoller
+	onRequestsScheduled, onRequestsSkipped           func(int)
+	onMatchFn                                        onMatchFunc
+	onErrorFn                                        onErrorFunc
+	onTaskFn                                         onTaskFunc
+	onUpdate                                         func(bool, bool, bool)
+	saveAllRequests, saveResponses, saveAllResponses bool
+	baseModifiers                                    []Modifier
+	passiveReqProfiles                               []*profile.Request
+	passiveResProfiles                               []*profile.Response
+	customTokens                                     CustomTokens
+	payloadStrategy                                  PayloadStrategy
+	filterBody                                       filterBodyFunc
+	filterResponse                                   filterResponseFunc
+	redirects                                        RedirectPolicy
+	modes                                            taskModes
+}
+
+// taskModes holds the settings of the tasks not associated to any profile (see [TaskKind]).
+type taskModes struct {
+	responseDiff   ResponseDiffCfg
+	rateLimit      RateLimitCfg
+	massAssignment MassAssignmentCfg
+	cachePoisoning CachePoisoningCfg
+}
+
 //nolint:nolintlint,gocyclo
-func (t *Task) run(
-	ctx context.Context,
-	tpl Template,
-	fn RequesterBuilder,
-	bhPoller BlindHostPoller,
-	onRequestsScheduled, onRequestsSkipped func(int),
-	onMatchFn onMatchFunc,
-	onErrorFn onErrorFunc,
-	onTaskFn onTaskFunc,
-	onUpdate func(bool, bool, bool),
-	saveAllRequests, saveResponses, saveAllResponses bool,
-	baseModifiers []Modifier,
-	passiveReqProfiles []*profile.Request,
-	passiveResProfiles []*profile.Response,
-	customTokens CustomTokens,
-	payloadStrategy PayloadStrategy,
-) {
+func (t *Task) run(ctx context.Context, tpl Template, env taskEnv) {
+	// The matches found are traced back to the template (see MatchOrigin).
+	ctx = withTemplateOrigin(ctx, tpl)
+
+	// Only step tasks look for equivalent matches (see [TaskKind]).
+	//nolint:exhaustive
+	switch t.Kind {
+	// If it is a diff task, we send both variants and compare their responses.
+	case TaskKindDiff:
+		t.runDiff(ctx, tpl, env)
+		return
+	// If it is a burst task, we send the burst and aggregate the responses.
+	case TaskKindBurst:
+		t.runBurst(ctx, tpl, env)
+		return
+	// If it is a mass assignment task, we send the baselines and every variant, and compare their responses.
+	case TaskKindMassAssignment:
+		t.runMassAssignment(ctx, tpl, env)
+		return
+	// If it is a cache poisoning task, we send both the poisoned and the clean requests, and compare their responses.
+	case TaskKindCachePoisoning:
+		t.runCachePoisoning(ctx, tpl, env)
+		return
+	// If it is a GraphQL introspection task, we send the introspection query and analyze the response.
+	case TaskKindGraphQL:
+		t.runGraphQL(ctx, tpl, env)
+		return
+	// If it is a raw task, we just send the request as is.
+	case TaskKindRaw:
+		t.runRaw(ctx, tpl, env)
+		return
+	}
+
 	// Do we really need to run the task? Eventually, a task could be "skipped" because
 	// there's already an equivalent match (same profile, step and entrypoint) with a
 	// different payload. It depends on the PayloadStrategy given.
-	if payloadStrategy.IsOnlyOnce() && t.LoW.isThereAnyEquivalentMatch(t.matchId()) {
+	if env.payloadStrategy.IsOnlyOnce() && t.LoW.isThereAnyEquivalentMatch(t.matchId()) {
 		logger.For(ctx).Infof(
 			"Skipping task, payload strategy is 'only_once' and we already found an equivalent match: %s",
 			t.matchId(),
 		)
-		onRequestsSkipped(1)
+		env.onRequestsSkipped(1)
 		return
 	}
 
@@ -130,12 +238,12 @@ func (t *Task) run(
 	// Base tasks only perform passive scans on request & response,
 	// so there's no much to do beyond running the passive scans.
 	if t.IsBase {
-		t.runBase(ctx, tpl, onMatchFn, onUpdate, passiveReqProfiles, passiveResProfiles, customTokens)
+		t.runBase(ctx, tpl, env.onMatchFn, env.onUpdate, env.passiveReqProfiles, env.passiveResProfiles, env.customTokens, env.filterBody, env.filterResponse)
 		return
 	}
 
 	// Otherwise, we run the corresponding step.
-	req, res, isMatch, occ, err := t.runStep(ctx, tpl, fn, bhPoller, baseModifiers, onMatchFn, onUpdate, passiveReqProfiles, passiveResProfiles, customTokens)
+	req, res, isMatch, occ, filtered, err := t.runStep(ctx, tpl, env.fn, env.bhPoller, env.baseModifiers, env.onMatchFn, env.onUpdate, env.passiveReqProfiles, env.passiveResProfiles, env.customTokens, env.filterBody, env.filterResponse, env.redirects)
 	if err != nil {
 		// If the step failed, we log the error.
 		// However, we log it as .Warn because a failed step is not necessarily an execution error.
@@ -146,7 +254,7 @@ func (t *Task) run(
 	}
 
 	// In case of match, we report it to the LineOfWork (see PayloadStrategy)
-	if payloadStrategy.IsOnlyOnce() && isMatch {
+	if env.payloadStrategy.IsOnlyOnce() && isMatch {
 		logger.For(ctx).Infof(
 			"Registering match (for future equivalents): profile=%s, stepIdx=%d, entrypointIdx=%d, payloadIdx=%d",
 			t.Profile.Name, t.StepIdx, t.EntrypointIdx, t.PayloadIdx,
@@ -155,12 +263,12 @@ func (t *Task) run(
 	}
 
 	// We update the task with the request & response, depending on the result.
-	if (saveAllRequests || saveAllResponses) || isMatch || err != nil {
-		if saveAllRequests || isMatch || err != nil {
+	if (env.saveAllRequests || env.saveAllResponses) || isMatch || err != nil {
+		if env.saveAllRequests || isMatch || err != nil {
 			t.Requests = append(t.Requests, &req)
 		}
 
-		if saveAllResponses || ((isMatch || err != nil) && saveResponses) {
+		if env.saveAllResponses && !filtered || ((isMatch || err != nil) && env.saveResponses) {
 			t.Responses = append(t.Responses, &res)
 			t.Occurrences = append(t.Occurrences, occ)
 		}
@@ -183,25 +291,22 @@ func (t *Task) run(
 		// TODO: Use 'show_alert' for passive profiles
 		if t.Profile.Steps[t.StepIdx].ShowAlert.Enabled() {
 			matched = true
-			onUpdate(true, false, false) // Report the match, the request will be reported later.
-			if onMatchFn != nil {
-				onMatchFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, t.Profile, t.Profile.Steps[t.StepIdx], entrypoint.Entrypoint(nil), t.payloadEncoded(), t.Occurrences)
+			env.onUpdate(true, false, false) // Report the match, the request will be reported later.
+			if env.onMatchFn != nil {
+				env.onMatchFn(withStepOrigin(ctx, t.StepIdx), tpl.OriginalURL, t.Requests, t.Responses, t.Profile, t.Profile.Steps[t.StepIdx], t.entrypoint(), t.payloadEncoded(), t.Occurrences)
 			}
 		}
 
 		// If there are more steps to take, we schedule them:
 		if t.StepIdx < len(t.Profile.Steps)-1 {
-			t.scheduleNextStep(ctx, onRequestsScheduled)
+			t.scheduleNextStep(ctx, env.onRequestsScheduled)
 		}
 	// The current step isn't a match:
 	// - We do nothing. Nothing to report, nor to schedule.
 	case err == nil && !isMatch:
 	// The current step is an error:
-	// - We report the error.
+	// - We report the error, right after the request (see below).
 	case err != nil:
-		if onErrorFn != nil {
-			onErrorFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, err)
-		}
 	// There shouldn't exist any other scenario, if so, we just panic.
 	// In the worst case, it will be caught and reported by the runner.
 	default:
@@ -209,15 +314,19 @@ func (t *Task) run(
 		panic("scan: unknown status after step execution")
 	}
 
-	// We report the request, either successful or not.
-	onUpdate(false, err == nil, err != nil)
+	// We report the request, either successful or not, and then the error, if any,
+	// so the request is accounted even if the scan is aborted on errors.
+	env.report(err)
+	if err != nil && env.onErrorFn != nil {
+		env.onErrorFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, err)
+	}
 
 	// If there's no more requests to do:
 	// - isMatch and last step (matched!)
 	// - !isMatch (don't continue)
 	// - err != nil (failed)
-	if (matched || !isMatch || err != nil) && onTaskFn != nil {
-		onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	if (matched || !isMatch || err != nil) && env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
 	}
 }
 
@@ -229,6 +338,8 @@ func (t *Task) runBase(
 	passiveReqProfiles []*profile.Request,
 	passiveResProfiles []*profile.Response,
 	customTokens CustomTokens,
+	filterBody filterBodyFunc,
+	filterResponse filterResponseFunc,
 ) {
 	// We prepare a [sync.WaitGroup] to wait for the passive scans to finish.
 	wg := new(sync.WaitGroup)
@@ -251,8 +362,8 @@ func (t *Task) runBase(
 	}
 
 	// And, we trigger the passive response scan.
-	// Only when the response is non-empty.
-	if tpl.Response != nil && !tpl.Response.IsEmpty() {
+	// Only when the response is non-empty, and not filtered out.
+	if tpl.Response != nil && !tpl.Response.IsEmpty() && !filterResponse(tpl, tpl.Response) {
 		wg.Add(1)
 		notifyResMatch := func(prof *profile.Response, occ []occurrence.Occurrence) {
 			var reqs []*request.Request
@@ -268,7 +379,7 @@ func (t *Task) runBase(
 		go func() {
 			defer panics.Log(ctx)
 			defer wg.Done()
-			passiveResponseScan(ctx, passiveResProfiles, &tpl.Request, tpl.Response, notifyResMatch, customTokens)
+			passiveResponseScan(ctx, passiveResProfiles, &tpl.Request, filterBody(tpl.Response), notifyResMatch, customTokens)
 		}()
 	}
 
@@ -276,6 +387,437 @@ func (t *Task) runBase(
 	wg.Wait()
 }
 
+// send sends the given request with a requester built from the [taskEnv],
+// following the redirects (see [RedirectPolicy]), and returns the last response.
+func (env taskEnv) send(ctx context.Context, req *request.Request) (res response.Response, err error) {
+	for err == nil && shouldFollowRedirect(ctx, req, &res, env.redirects) {
+		var requester Requester
+		if requester, err = env.fn(); err != nil {
+			break
+		}
+
+		res, err = requester.Do(ctx, req)
+	}
+
+	return res, err
+}
+
+// report reports the outcome of the given request sent (see [taskEnv.send]), either successful or not,
+// unless it was deliberately not sent (see [ErrRequestSkipped]), so it is reported as skipped instead.
+func (env taskEnv) report(err error) {
+	if errors.Is(err, ErrRequestSkipped) {
+		env.onRequestsSkipped(1)
+		return
+	}
+
+	env.onUpdate(false, err == nil, err != nil)
+}
+
+// sendAll sends the given requests one after the other (see [taskEnv.send]), so their
+// responses are as comparable as possible, reporting each one, either successful or not.
+// If any fails, the rest aren't sent, but reported as skipped, and the failure is recorded
+// into the task (see [Task.fail]), so there's nothing left to do but return when it's false.
+func (t *Task) sendAll(ctx context.Context, tpl Template, env taskEnv, reqs []request.Request) ([]response.Response, bool) {
+	var (
+		res  = make([]response.Response, len(reqs))
+		sent int
+		err  error
+	)
+
+	for sent < len(reqs) && err == nil {
+		res[sent], err = env.send(ctx, &reqs[sent])
+		sent++
+
+		// We report the request, either successful or not.
+		env.report(err)
+	}
+
+	if sent < len(reqs) {
+		env.onRequestsSkipped(len(reqs) - sent)
+	}
+
+	t.Performed = true
+	t.Error = err
+
+	if err != nil {
+		t.fail(ctx, tpl, env, &reqs[sent-1], &res[sent-1], err)
+		return nil, false
+	}
+
+	return res, true
+}
+
+// fail records the given failed request (and its response, if saved) into the
+// task, and reports both the error and the task, as there's nothing else to do.
+func (t *Task) fail(ctx context.Context, tpl Template, env taskEnv, req *request.Request, res *response.Response, err error) {
+	t.Requests = append(t.Requests, req)
+	if env.saveResponses {
+		t.Responses = append(t.Responses, res)
+	}
+
+	if env.onErrorFn != nil {
+		env.onErrorFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, err)
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runRaw(ctx context.Context, tpl Template, env taskEnv) {
+	// We prepare a [sync.WaitGroup] to wait for the passive scans to finish.
+	wg := new(sync.WaitGroup)
+
+	// The request is sent exactly as built from the template,
+	// so there are neither injections nor modifiers involved.
+	req := tpl.Request.Clone()
+
+	// We trigger the passive request scan.
+	{
+		wg.Add(1)
+		reqToScan := req.Clone()
+		notifyReqMatch := func(prof *profile.Request, occ []occurrence.Occurrence) {
+			env.onUpdate(true, false, false)
+			if env.onMatchFn != nil {
+				env.onMatchFn(ctx, tpl.OriginalURL, []*request.Request{&reqToScan}, nil, prof, prof, nil, "", [][]occurrence.Occurrence{occ})
+			}
+		}
+		go func() {
+			defer panics.Log(ctx)
+			defer wg.Done()
+			passiveRequestScan(ctx, env.passiveReqProfiles, &reqToScan, notifyReqMatch, env.customTokens)
+		}()
+	}
+
+	res, err := env.send(ctx, &req)
+
+	// We trigger the passive response scan.
+	// Only when the request succeeded, and the response is not filtered out.
+	filtered := err == nil && env.filterResponse(tpl, &res)
+	if err == nil && !filtered {
+		resToScan := env.filterBody(&res)
+		wg.Add(1)
+		notifyResMatch := func(prof *profile.Response, occ []occurrence.Occurrence) {
+			env.onUpdate(true, false, false)
+			if env.onMatchFn != nil {
+				env.onMatchFn(ctx, tpl.OriginalURL, []*request.Request{&req}, []*response.Response{&res}, prof, prof, nil, "", [][]occurrence.Occurrence{occ})
+			}
+		}
+		go func() {
+			defer panics.Log(ctx)
+			defer wg.Done()
+			passiveResponseScan(ctx, env.passiveResProfiles, &req, resToScan, notifyResMatch, env.customTokens)
+		}()
+	}
+
+	// Before reporting, we wait for both passive scans to finish.
+	wg.Wait()
+
+	if env.saveAllRequests || err != nil {
+		t.Requests = append(t.Requests, &req)
+	}
+
+	if env.saveAllResponses && !filtered || (err != nil && env.saveResponses) {
+		t.Responses = append(t.Responses, &res)
+	}
+
+	t.Performed = true
+	t.Error = err
+
+	// We report the request, either successful or not, and then the error, if any.
+	env.report(err)
+	if err != nil && env.onErrorFn != nil {
+		env.onErrorFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, err)
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runDiff(ctx context.Context, tpl Template, env taskEnv) {
+	responseDiff := env.modes.responseDiff
+	reqs := []request.Request{responseDiff.A.Apply(tpl.Request), responseDiff.B.Apply(tpl.Request)}
+
+	// If any variant failed, the rest aren't sent, as there is nothing to compare with.
+	res, ok := t.sendAll(ctx, tpl, env, reqs)
+	if !ok {
+		return
+	}
+
+	d := DiffResponses(&res[0], &res[1])
+	t.Match = !d.IsEmpty()
+
+	for i := range reqs {
+		if t.Match || env.saveAllRequests {
+			t.Requests = append(t.Requests, &reqs[i])
+		}
+		if t.Match && env.saveResponses || env.saveAllResponses {
+			t.Responses = append(t.Responses, &res[i])
+		}
+	}
+
+	if t.Match {
+		logger.For(ctx).Debugf("Responses differ between variants of template (idx=%d): %s", tpl.Idx, d.Summary())
+
+		env.onUpdate(true, false, false)
+		if env.onMatchFn != nil {
+			prof := responseDiffProfile{}
+			env.onMatchFn(withResponseDiff(ctx, d), tpl.OriginalURL, t.Requests, t.Responses, prof, prof, nil, "", nil)
+		}
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runBurst(ctx context.Context, tpl Template, env taskEnv) {
+	var (
+		burst    = env.modes.rateLimit.Burst
+		statuses = make([]int, 0, burst)
+		req      request.Request
+		res      response.Response
+		first    request.Request
+		firstRes response.Response
+		err      error
+	)
+
+	// Requests are sent one after the other, with no throttling, so the endpoint's own limits are observed.
+	for len(statuses) < burst && err == nil {
+		req = tpl.Request.Clone()
+		res, err = env.send(ctx, &req)
+
+		// We report the request, either successful or not.
+		env.report(err)
+
+		if err != nil {
+			break
+		}
+
+		if len(statuses) == 0 {
+			first, firstRes = req, res
+		}
+
+		statuses = append(statuses, res.Code)
+
+		// Once throttled, the rest of the burst isn't sent, as the threshold is already known.
+		if CharacterizeRateLimit(statuses).Throttled {
+			break
+		}
+	}
+
+	sent := len(statuses)
+	if err != nil {
+		sent++
+	}
+
+	if sent < burst {
+		env.onRequestsSkipped(burst - sent)
+	}
+
+	t.Performed = true
+	t.Error = err
+
+	if err != nil {
+		t.fail(ctx, tpl, env, &req, &res, err)
+		return
+	}
+
+	// Both the first and the last request (i.e. the throttled one, if any) are attached.
+	result := CharacterizeRateLimit(statuses)
+	t.Match = true
+	t.Requests = append(t.Requests, &first)
+	t.Responses = append(t.Responses, &firstRes)
+	if sent > 1 {
+		t.Requests = append(t.Requests, &req)
+		t.Responses = append(t.Responses, &res)
+	}
+
+	if !env.saveResponses && !env.saveAllResponses {
+		t.Responses = nil
+	}
+
+	logger.For(ctx).Debugf("Rate limit of template (idx=%d): %s", tpl.Idx, result.Summary())
+
+	env.onUpdate(true, false, false)
+	if env.onMatchFn != nil {
+		prof := rateLimitProfile{result: result}
+		env.onMatchFn(withRateLimit(ctx, result), tpl.OriginalURL, t.Requests, t.Responses, prof, prof, nil, "", nil)
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runMassAssignment(ctx context.Context, tpl Template, env taskEnv) {
+	variants := env.modes.massAssignment.Variants(tpl.Request)
+
+	// The request is sent as is twice (baseline and control), to tell
+	// the changes caused by the variants from those that aren't stable.
+	reqs := []request.Request{tpl.Request.Clone(), tpl.Request.Clone()}
+	for _, v := range variants {
+		reqs = append(reqs, v.Request)
+	}
+
+	// If any request failed, the rest aren't sent, as the comparison isn't reliable anymore.
+	res, ok := t.sendAll(ctx, tpl, env, reqs)
+	if !ok {
+		return
+	}
+
+	if env.saveAllRequests {
+		t.Requests = append(t.Requests, &reqs[0])
+	}
+	if env.saveAllResponses {
+		t.Responses = append(t.Responses, &res[0])
+	}
+
+	for i, v := range variants {
+		req, vRes := &reqs[i+2], &res[i+2]
+
+		result := EvaluateMassAssignment(v, &res[0], &res[1], vRes)
+		if !result.Accepted() {
+			if env.saveAllRequests {
+				t.Requests = append(t.Requests, req)
+			}
+			if env.saveAllResponses {
+				t.Responses = append(t.Responses, vRes)
+			}
+			continue
+		}
+
+		// The task is reported as a match once, like any other task, regardless of how many variants are accepted.
+		if !t.Match {
+			env.onUpdate(true, false, false)
+		}
+
+		t.Match = true
+		t.Requests = append(t.Requests, req)
+		if env.saveResponses || env.saveAllResponses {
+			t.Responses = append(t.Responses, vRes)
+		}
+
+		logger.For(ctx).Debugf("Mass assignment on template (idx=%d): %s", tpl.Idx, result.Summary())
+
+		// Both the baseline and the variant are attached to the match.
+		matchReqs := []*request.Request{&reqs[0], req}
+		var matchRes []*response.Response
+		if env.saveResponses || env.saveAllResponses {
+			matchRes = []*response.Response{&res[0], vRes}
+		}
+
+		if env.onMatchFn != nil {
+			prof := massAssignmentProfile{result: result}
+			env.onMatchFn(withMassAssignment(ctx, result), tpl.OriginalURL, matchReqs, matchRes, prof, prof, nil, v.Param, nil)
+		}
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runCachePoisoning(ctx context.Context, tpl Template, env taskEnv) {
+	probes := env.modes.cachePoisoning.Probes(tpl.Request, newCachePoisoningNonce())
+
+	// Each poisoned request is followed by its clean one, so the
+	// latter is answered by the cache, if the former was cached.
+	reqs := make([]request.Request, 0, 2*len(probes)) //nolint:mnd
+	for _, p := range probes {
+		reqs = append(reqs, p.Poisoned, p.Clean)
+	}
+
+	// If any request failed, the rest aren't sent, as the host is likely unreachable.
+	res, ok := t.sendAll(ctx, tpl, env, reqs)
+	if !ok {
+		return
+	}
+
+	for i, p := range probes {
+		poisonedReq, cleanReq := &reqs[2*i], &reqs[2*i+1]
+		poisonedRes, cleanRes := &res[2*i], &res[2*i+1]
+
+		result := EvaluateCachePoisoning(p, poisonedRes, cleanRes)
+		if !result.Cached {
+			if env.saveAllRequests {
+				t.Requests = append(t.Requests, poisonedReq, cleanReq)
+			}
+			if env.saveAllResponses {
+				t.Responses = append(t.Responses, poisonedRes, cleanRes)
+			}
+			continue
+		}
+
+		// The task is reported as a match once, like any other task, regardless of how many headers are cached.
+		if !t.Match {
+			env.onUpdate(true, false, false)
+		}
+
+		t.Match = true
+		t.Requests = append(t.Requests, poisonedReq, cleanReq)
+		if env.saveResponses || env.saveAllResponses {
+			t.Responses = append(t.Responses, poisonedRes, cleanRes)
+		}
+
+		logger.For(ctx).Debugf("Cache poisoning on template (idx=%d): %s", tpl.Idx, result.Summary())
+
+		// Both the poisoned and the clean requests are attached to the match.
+		matchReqs := []*request.Request{poisonedReq, cleanReq}
+		var matchRes []*response.Response
+		if env.saveResponses || env.saveAllResponses {
+			matchRes = []*response.Response{poisonedRes, cleanRes}
+		}
+
+		if env.onMatchFn != nil {
+			prof := cachePoisoningProfile{result: result}
+			env.onMatchFn(withCachePoisoning(ctx, result), tpl.OriginalURL, matchReqs, matchRes, prof, prof, nil, p.Header+": "+p.Value, nil)
+		}
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
+func (t *Task) runGraphQL(ctx context.Context, tpl Template, env taskEnv) {
+	req := GraphQLIntrospectionRequest(tpl.Request)
+	res, err := env.send(ctx, &req)
+
+	t.Performed = true
+	t.Error = err
+
+	if err == nil {
+		prof := GraphQLIntrospectionProfile()
+		passiveResponseScan(ctx, []*profile.Response{prof}, &req, &res, func(prof *profile.Response, occ []occurrence.Occurrence) {
+			t.Match = true
+			env.onUpdate(true, false, false)
+			if env.onMatchFn != nil {
+				env.onMatchFn(ctx, tpl.OriginalURL, []*request.Request{&req}, []*response.Response{&res}, prof, prof, nil, "", [][]occurrence.Occurrence{occ})
+			}
+		}, env.customTokens)
+	}
+
+	if env.saveAllRequests || t.Match || err != nil {
+		t.Requests = append(t.Requests, &req)
+	}
+
+	if env.saveAllResponses || ((t.Match || err != nil) && env.saveResponses) {
+		t.Responses = append(t.Responses, &res)
+	}
+
+	// We report the request, either successful or not, and then the error, if any.
+	env.report(err)
+	if err != nil && env.onErrorFn != nil {
+		env.onErrorFn(ctx, tpl.OriginalURL, t.Requests, t.Responses, err)
+	}
+
+	if env.onTaskFn != nil {
+		env.onTaskFn(ctx, tpl.OriginalURL, t.Requests, t.Responses)
+	}
+}
+
 func (t *Task) runStep(
 	ctx context.Context,
 	tpl Template,
@@ -287,7 +829,10 @@ func (t *Task) runStep(
 	passiveReqProfiles []*profile.Request,
 	passiveResProfiles []*profile.Response,
 	customTokens CustomTokens,
-) (injectedReq request.Request, res response.Response, isMatch bool, occ []occurrence.Occurrence, err error) {
+	filterBody filterBodyFunc,
+	filterResponse filterResponseFunc,
+	redirects RedirectPolicy,
+) (injectedReq request.Request, res response.Response, isMatch bool, occ []occurrence.Occurrence, filtered bool, err error) {
 	// We prepare a [sync.WaitGroup] to wait for the passive scans to finish.
 	wg := new(sync.WaitGroup)
 	//
@@ -301,6 +846,11 @@ func (t *Task) runStep(
 	case step.RequestType.RawRequest():
 		injectedReq = rawRequestFromStep(tpl, step)
 
+	// If the current step is a host request, we use the request
+	// from the step definition, built on top of the template's one.
+	case step.RequestType.HostRequest():
+		injectedReq = hostRequestFromStep(tpl, step)
+
 	// Otherwise, we inject the payload to the entrypoint, which might be
 	// - the entrypoint attached to the task
 	// - the entrypoint referred from the LineOfWork
@@ -309,11 +859,16 @@ func (t *Task) runStep(
 			ep = t.LoW.Entrypoints[t.EntrypointIdx]
 		}
 
+		// The payload is prepended with a unique canary, if enabled,
+		// so its reflections can be attributed to this request.
+		canary := newCanary(ctx)
+
 		injectedReq = ep.InjectPayload(
 			tpl.Request,
 			step.PayloadPosition,
-			t.payloadEncoded(),
+			canary+t.payloadEncoded(),
 		)
+		injectedReq.Canary = canary
 	}
 
 	// Now, we prepare the modifiers, and modify the injected request
@@ -344,8 +899,16 @@ func (t *Task) runStep(
 		}()
 	}
 
+	// The response analyzed by the matchers, which might have no body (see [MimeFilter]).
+	resToScan := &res
+
+	// The headers the payload is injected into are left untouched
+	// by the request mutators, if any (see [WithRequestMutators]).
+	// It's a different context, as ctx is shared with the goroutines.
+	reqCtx := withEntrypoint(ctx, ep)
+
 	req := injectedReq.Clone()
-	for shouldFollowRedirect(ctx, &req, &res) {
+	for shouldFollowRedirect(ctx, &req, &res, redirects) {
 		if err != nil {
 			return
 		}
@@ -355,19 +918,27 @@ func (t *Task) runStep(
 			return
 		}
 
-		res, err = requester.Do(ctx, &req)
+		res, err = requester.Do(reqCtx, &req)
 		if errors.Is(err, context.Canceled) {
 			return
 		}
 
-		isMatch, occ = isActiveMatch(ctx, t, step, req, res, bhPoller, customTokens)
+		// Filtered responses are neither evaluated by the matchers, nor recorded.
+		if filtered = err == nil && filterResponse(tpl, &res); filtered {
+			isMatch, occ = false, nil
+			continue
+		}
+
+		resToScan = filterBody(&res)
+		isMatch, occ = isActiveMatch(ctx, t, step, req, *resToScan, bhPoller, customTokens)
 		if isMatch {
 			break
 		}
 	}
 
 	// We trigger the passive response scan.
-	{
+	// Only when the response is not filtered out.
+	if !filtered {
 		wg.Add(1)
 		notifyResMatch := func(prof *profile.Response, occ []occurrence.Occurrence) {
 			onUpdate(true, false, false)
@@ -378,7 +949,7 @@ func (t *Task) runStep(
 		go func() {
 			defer panics.Log(ctx)
 			defer wg.Done()
-			passiveResponseScan(ctx, passiveResProfiles, &req, &res, notifyResMatch, customTokens)
+			passiveResponseScan(ctx, passiveResProfiles, &req, resToScan, notifyResMatch, customTokens)
 		}()
 	}
 
@@ -535,6 +1106,7 @@ func isActiveMatch(ctx context.Context, task *Task, step profile.Step, req reque
 				Request:       &req,
 				Response:      &res,
 				CustomTokens:  customTokens,
+				Canary:        req.Canary,
 			},
 		)
 	}()
@@ -579,6 +1151,23 @@ func isActiveMatch(ctx context.Context, task *Task, step profile.Step, req reque
 	return isMatch || isInteraction, occ
 }
 
+// MatchPayloads returns the payload and the decoded payload the given active profile step is
+// matched with (see [match.Data]), for the given [Match.Payload] (i.e. the encoded one), with
+// the given request's modifications (e.g. {RANDOM}) replaced, the same as [isActiveMatch] does,
+// so a stored [Match] can be matched again (e.g. once replayed). If the payload isn't found among
+// the step's payloads (e.g. the profile changed), it is used as is for both.
+func MatchPayloads(step profile.Step, payload string, req request.Request) (string, string) {
+	decoded := payload
+	for idx := range step.Payloads {
+		if _, encoded, err := step.PayloadAtEncoded(idx); err == nil && encoded == payload {
+			_, decoded, _ = step.PayloadAt(idx)
+			break
+		}
+	}
+
+	return applyReplacements(payload, req.Modifications), applyReplacements(decoded, req.Modifications)
+}
+
 func applyReplacements(payload string, replacements map[string]string) string {
 	for label, replacement := range replacements {
 		payload = strings.ReplaceAll(payload, label, replacement)
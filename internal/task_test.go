@@ -0,0 +1,55 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestMatchPayloads(t *testing.T) {
+	t.Parallel()
+
+	step := profile.Step{
+		Payloads: []string{"true,<b>", "true,<script>alert({RANDOM})</script>"},
+		Encoder:  []string{"Base64-encode"},
+	}
+
+	req := request.Request{Modifications: map[string]string{"{RANDOM}": "3NS4CHD9"}}
+
+	tcs := map[string]struct {
+		payload     string
+		wantPayload string
+		wantDecoded string
+	}{
+		"encoded payload": {
+			payload:     "PGI+",
+			wantPayload: "PGI+",
+			wantDecoded: "<b>",
+		},
+		"with modifications": {
+			payload:     "PHNjcmlwdD5hbGVydCh7UkFORE9NfSk8L3NjcmlwdD4=",
+			wantPayload: "PHNjcmlwdD5hbGVydCh7UkFORE9NfSk8L3NjcmlwdD4=",
+			wantDecoded: "<script>alert(3NS4CHD9)</script>",
+		},
+		"unknown payload": {
+			payload:     "{RANDOM}",
+			wantPayload: "3NS4CHD9",
+			wantDecoded: "3NS4CHD9",
+		},
+	}
+
+	for name, tc := range tcs {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			payload, decoded := scan.MatchPayloads(step, tc.payload, req)
+			assert.Equal(t, tc.wantPayload, payload)
+			assert.Equal(t, tc.wantDecoded, decoded)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+const (
+	// MetadataTechnology is the [Match.Metadata] key that identifies the technologies
+	// found (e.g. nginx 1.18.0, php 8.1.2), only set when the [profile.Profile] fingerprints
+	// the technology stack (see [profile.GrepTypeTechnology]), and any is found.
+	MetadataTechnology = "technology"
+
+	// MetadataTechnologySignatures is the [Match.Metadata] key that identifies the version of
+	// the technology signatures (see [match.TechnologySignaturesVersion]) the technologies
+	// were found with (see [MetadataTechnology]), so findings can be compared across scans.
+	MetadataTechnologySignatures = "technology_signatures"
+)
+
+// Technologies returns the technologies identified by the given responses, according to the
+// Technology greps of the given [profile.Profile] (see [match.Technologies]), if any. So, these
+// can be reported along with the match (see [MetadataTechnology]).
+func Technologies(ctx context.Context, prof profile.Profile, res []*response.Response) []string {
+	if prof == nil {
+		return nil
+	}
+
+	greps := profile.GrepsOfType(prof, profile.GrepTypeTechnology)
+	if len(greps) == 0 {
+		return nil
+	}
+
+	var technologies []string
+	for _, g := range greps {
+		for _, r := range res {
+			for _, tech := range match.Technologies(ctx, g, r) {
+				if !slices.In(technologies, tech) {
+					technologies = append(technologies, tech)
+				}
+			}
+		}
+	}
+
+	return technologies
+}
@@ -0,0 +1,38 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestTechnologies(t *testing.T) {
+	t.Parallel()
+
+	res := []*response.Response{
+		nil,
+		{Proto: "HTTP/1.1", Code: 200, Status: "OK", Headers: map[string][]string{"Server": {"nginx/1.25.3"}}},
+		{Proto: "HTTP/1.1", Code: 302, Status: "Found", Headers: map[string][]string{"Server": {"nginx/1.25.3"}, "Set-Cookie": {"laravel_session=abc; path=/"}}},
+	}
+
+	fingerprint := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Technology,,"}}
+	assert.Equal(t, []string{"nginx 1.25.3", "laravel"}, scan.Technologies(context.Background(), fingerprint, res))
+
+	// Only the signatures looked for, if any, are reported.
+	onlyPHP := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Technology,,php"}}
+	assert.Empty(t, scan.Technologies(context.Background(), onlyPHP, res))
+
+	// Only profiles fingerprinting the technology stack report those.
+	other := &profile.Response{Type: profile.TypePassiveRes, Greps: []string{"true,,Simple String,,nginx"}}
+	assert.Empty(t, scan.Technologies(context.Background(), other, res))
+
+	// Along with the version of the signatures.
+	metadata := scan.MatchMetadata(context.Background(), nil, fingerprint, nil, res, "")
+	assert.Equal(t, "nginx 1.25.3, laravel", metadata[scan.MetadataTechnology])
+	assert.Equal(t, "builtin-1", metadata[scan.MetadataTechnologySignatures])
+}
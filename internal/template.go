@@ -16,9 +16,18 @@ import (
 // Template is an abstraction that represents a request and response pair
 // used for scanning. It also contains the original URL and the unique
 // index within the entire scan.
+//
+// Templates with higher Priority are scanned first (see [Runner]),
+// while those with equal Priority keep their original order.
+//
+// OriginIdx is the index of the template it was generated from (see
+// [ParamsCfg.AlterEach]), or the same as Idx if it wasn't generated,
+// so findings can be traced back to their origin (see [MatchOrigin]).
 type Template struct {
 	Idx         int
+	OriginIdx   int
 	OriginalURL string
+	Priority    int
 	request.Request
 	Response *response.Response
 }
@@ -26,63 +35,104 @@ type Template struct {
 // TemplatesFromZipBytes initializes a slice of [Template] with the given [ParamsCfg], a slice of [request.Option]
 // and interpreting the slice of bytes as the contents of a zipped (.zip) file that contains one or more files,
 // each containing a raw HTTP request.
+//
+// See [EachTemplateFromZipBytes] for a lazy alternative.
 func TemplatesFromZipBytes(ctx context.Context, pCfg ParamsCfg, fileBytes []byte, opts ...request.Option) ([]Template, error) {
-	zipReader, err := zip.NewReader(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	var templates []Template
+
+	err := EachTemplateFromZipBytes(ctx, pCfg, fileBytes, func(tpl Template) error {
+		templates = append(templates, tpl)
+		return nil
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var tplIdx int
-	templates := make([]Template, 0, len(zipReader.File))
+	return templates, nil
+}
 
+// EachTemplateFromZipBytes is like [TemplatesFromZipBytes], but instead of building the whole set
+// of [Template] at once, it builds them one by one (see [ParamsCfg.AlterEach]), and calls fn with
+// each of them, in order. It stops as soon as either a request cannot be parsed or fn returns an
+// error, and returns it.
+func EachTemplateFromZipBytes(ctx context.Context, pCfg ParamsCfg, fileBytes []byte, fn func(Template) error, opts ...request.Option) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	if err != nil {
+		return err
+	}
+
+	var tplIdx int
 	for _, zipFile := range zipReader.File {
 		file, err := zipFile.Open()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		fileBytes, err := io.ReadAll(file)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		req, err := request.ParseRequest(fileBytes)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		for _, opt := range opts {
 			req = opt(req)
 		}
 
-		expanded := pCfg.Alter(NewTemplate(ctx, tplIdx, req, nil))
-		templates = append(templates, expanded...)
-		tplIdx += len(expanded)
+		err = pCfg.AlterEach(ctx, NewTemplate(ctx, tplIdx, req, nil), func(tpl Template) error {
+			tplIdx++
+			return fn(tpl)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return templates, nil
+	return nil
 }
 
 // TemplateFromRawBytes initializes a slice of [Template] with the given [ParamsCfg], a slice of [request.Option]
 // and interpreting the slice of bytes as a file that contains a raw HTTP request.
+//
+// See [EachTemplateFromRawBytes] for a lazy alternative.
 func TemplateFromRawBytes(ctx context.Context, idx int, pCfg ParamsCfg, fileBytes []byte, opts ...request.Option) ([]Template, error) {
-	req, err := request.ParseRequest(fileBytes)
+	templates := make([]Template, 0, pCfg.numVariants())
+
+	err := EachTemplateFromRawBytes(ctx, idx, pCfg, fileBytes, func(tpl Template) error {
+		templates = append(templates, tpl)
+		return nil
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	return templates, nil
+}
+
+// EachTemplateFromRawBytes is like [TemplateFromRawBytes], but instead of building the whole set
+// of [Template] at once, it builds them one by one (see [ParamsCfg.AlterEach]), and calls fn with
+// each of them, in order. It stops as soon as fn returns an error, and returns it.
+func EachTemplateFromRawBytes(ctx context.Context, idx int, pCfg ParamsCfg, fileBytes []byte, fn func(Template) error, opts ...request.Option) error {
+	req, err := request.ParseRequest(fileBytes)
+	if err != nil {
+		return err
+	}
+
 	for _, opt := range opts {
 		req = opt(req)
 	}
 
-	return pCfg.Alter(NewTemplate(ctx, idx, req, nil)), nil
+	return pCfg.AlterEach(ctx, NewTemplate(ctx, idx, req, nil), fn)
 }
 
 // NewTemplate instantiates a new [Template] with the given [request.Request], the [response.Response],
 // if any, and the given index. So, similar to manually populating the [Template] fields but with some
 // validations in place.
 func NewTemplate(ctx context.Context, idx int, req request.Request, res *response.Response) Template {
-	defaultReturn := Template{Idx: idx, OriginalURL: req.URL, Request: req, Response: res}
+	defaultReturn := Template{Idx: idx, OriginIdx: idx, OriginalURL: req.URL, Request: req, Response: res}
 
 	if strings.Contains(req.URL, req.Path) {
 		return defaultReturn
@@ -100,14 +150,15 @@ func NewTemplate(ctx context.Context, idx int, req request.Request, res *respons
 		return defaultReturn
 	}
 
-	return Template{Idx: idx, OriginalURL: baseURL.ResolveReference(urlPath).String(), Request: req, Response: res}
+	return Template{Idx: idx, OriginIdx: idx, OriginalURL: baseURL.ResolveReference(urlPath).String(), Request: req, Response: res}
 }
 
-// Clone returns a clone (copy) of the [Template], keeping the same index, URL,
-// and deep-copying the [request.Request].
+// Clone returns a clone (copy) of the [Template], with the given index, keeping
+// the same origin index and URL, and deep-copying the [request.Request].
 func (tpl Template) Clone(idx int) Template {
 	return Template{
 		Idx:         idx,
+		OriginIdx:   tpl.OriginIdx,
 		OriginalURL: tpl.OriginalURL,
 		Request:     tpl.Request.Clone(),
 	}
@@ -0,0 +1,48 @@
+package scan
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+)
+
+// WriteTemplateBundle writes the templates stored in the given [FileSystemTemplates] into the given
+// [io.Writer], as a zipped (.zip) file that contains one file per template, with its URL and its raw
+// HTTP request (see [request.Request.RawBytes]), in order. That's the same format read by
+// [TemplatesFromZipBytes], so the bundle can be imported back, with no params (see [ParamsCfg]),
+// as these were already applied. Responses (from passive templates), if any, aren't included.
+//
+// It returns the amount of templates written.
+func WriteTemplateBundle(ctx context.Context, fs FileSystemTemplates, w io.Writer) (int, error) {
+	templates, err := fs.TemplatesIterator(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	zw := zip.NewWriter(w)
+
+	var written int
+	for tpl := range templates {
+		fw, err := zw.Create(fmt.Sprintf("%06d.req", written))
+		if err != nil {
+			return written, err
+		}
+
+		if _, err := io.WriteString(fw, tpl.URL+"\n"); err != nil {
+			return written, err
+		}
+
+		if _, err := fw.Write(tpl.Request.RawBytes()); err != nil {
+			return written, err
+		}
+
+		written++
+	}
+
+	if err := ctx.Err(); err != nil {
+		return written, err
+	}
+
+	return written, zw.Close()
+}
@@ -0,0 +1,64 @@
+package scan_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/platform/filesystem"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestWriteTemplateBundle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	pCfg := scan.ParamsCfg{
+		Params:   []string{"query", "order"},
+		Size:     1,
+		Method:   http.MethodPost,
+		Encoding: "json",
+	}
+
+	templates, err := scan.TemplatesFromCSV(ctx, pCfg, []byte(`method,url,body,content-type,X-Api-Key
+POST,https://example.org:8443/login,"{""user"": ""admin""}",application/json,abc
+GET,http://example.com/search?q=1,,,
+`))
+	require.NoError(t, err)
+	require.Len(t, templates, 4)
+
+	// Multiple values, and ambiguous framing headers, are kept as well.
+	raw, err := request.ParseRequest([]byte("POST /upload HTTP/1.1\r\nHost: example.net\r\nAccept: text/html\r\nAccept: */*\r\nContent-Length: 3\r\nContent-Length : 3\r\n\r\na=b"))
+	require.NoError(t, err)
+	require.NotEmpty(t, raw.RawHeaders)
+	templates = append(templates, scan.NewTemplate(ctx, len(templates), raw, nil))
+
+	aferoFs, basePath := initializeFsTest()
+	fs, err := filesystem.New(aferoFs, basePath)
+	require.NoError(t, err)
+
+	for _, tpl := range templates {
+		require.NoError(t, fs.StoreTemplate(ctx, tpl))
+	}
+
+	buf := new(bytes.Buffer)
+	written, err := scan.WriteTemplateBundle(ctx, fs, buf)
+	require.NoError(t, err)
+	assert.Equal(t, len(templates), written)
+
+	// The bundle re-imports identically, with no params, as these were already applied.
+	imported, err := scan.TemplatesFromZipBytes(ctx, scan.ParamsCfg{}, buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, imported, len(templates))
+
+	for i := range templates {
+		assert.Equal(t, templates[i].Idx, imported[i].Idx)
+		assert.Equal(t, templates[i].Request, imported[i].Request)
+	}
+}
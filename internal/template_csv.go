@@ -0,0 +1,296 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/url"
+)
+
+// ErrInvalidCSVRow is the error wrapped by [CSVRowError], returned when a
+// CSV row (see [EachTemplateFromCSV]) cannot be parsed, or it isn't valid.
+var ErrInvalidCSVRow = errors.New("invalid csv row")
+
+// CSV columns (see [EachTemplateFromCSV]), and their default order.
+const (
+	CSVColumnMethod      = "method"
+	CSVColumnURL         = "url"
+	CSVColumnBody        = "body"
+	CSVColumnContentType = "content-type"
+	CSVColumnHeaders     = "headers"
+)
+
+var defaultCSVColumns = []string{CSVColumnMethod, CSVColumnURL, CSVColumnBody, CSVColumnContentType, CSVColumnHeaders}
+
+// CSVRowError is the error returned for each of the CSV rows that cannot be
+// parsed, or aren't valid, along with its line number (1-based).
+type CSVRowError struct {
+	Line int
+	Err  error
+}
+
+// Error returns the error message, along with the line number
+// (e.g. invalid csv row (line 3): no url defined).
+func (e *CSVRowError) Error() string {
+	return fmt.Sprintf("%s (line %d): %s", ErrInvalidCSVRow.Error(), e.Line, e.Err.Error())
+}
+
+// Unwrap returns both [ErrInvalidCSVRow] and the underlying error.
+func (e *CSVRowError) Unwrap() []error {
+	return []error{ErrInvalidCSVRow, e.Err}
+}
+
+// TemplatesFromCSV initializes a slice of [Template] with the given [ParamsCfg], a slice of [request.Option]
+// and interpreting the slice of bytes as a CSV file, with one request per row (see [EachTemplateFromCSV]).
+// Malformed rows are skipped.
+//
+// See [EachTemplateFromCSV] for a lazy alternative.
+func TemplatesFromCSV(ctx context.Context, pCfg ParamsCfg, data []byte, opts ...request.Option) ([]Template, error) {
+	var templates []Template
+
+	_, err := EachTemplateFromCSV(ctx, pCfg, data, func(tpl Template) error {
+		templates = append(templates, tpl)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// EachTemplateFromCSV is like [TemplatesFromCSV], but instead of building the whole set of [Template]
+// at once, it builds them one by one (see [ParamsCfg.AlterEach]), and calls fn with each of them, in
+// order. It stops as soon as fn returns an error, and returns it.
+//
+// Each row defines a request by its method (GET by default), URL, body and content type, in that
+// order, unless the first row is a header row (i.e. with a url column), which defines the columns
+// (in any order). The headers are defined either by a headers column, as a JSON object (e.g.
+// {"X-Api-Key": "abc"}) or a JSON array (e.g. ["X-Api-Key: abc"]), or by any other column of the
+// header row, named as the header (e.g. X-Api-Key). Blank lines and those starting with # are skipped.
+//
+// Malformed rows (e.g. with an invalid URL) are skipped, and returned as [CSVRowError], with their
+// line numbers, so the caller can decide whether to fail or to go on.
+func EachTemplateFromCSV(ctx context.Context, pCfg ParamsCfg, data []byte, fn func(Template) error, opts ...request.Option) ([]*CSVRowError, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var (
+		columns []string
+		skipped []*CSVRowError
+		tplIdx  int
+	)
+
+	skip := func(line int, err error) {
+		rowErr := &CSVRowError{Line: line, Err: err}
+		logger.For(ctx).Warnf("Skipping %s", rowErr.Error())
+		skipped = append(skipped, rowErr)
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			skip(parseErr.StartLine, parseErr.Err)
+			continue
+		}
+
+		if err != nil {
+			return skipped, err
+		}
+
+		line, _ := r.FieldPos(0)
+
+		if columns == nil {
+			if columns = csvHeaderRow(record); columns != nil {
+				continue
+			}
+			columns = defaultCSVColumns
+		}
+
+		req, err := requestFromCSVRow(columns, record)
+		if err != nil {
+			skip(line, err)
+			continue
+		}
+
+		for _, opt := range opts {
+			req = opt(req)
+		}
+
+		err = pCfg.AlterEach(ctx, NewTemplate(ctx, tplIdx, req, nil), func(tpl Template) error {
+			tplIdx++
+			return fn(tpl)
+		})
+		if err != nil {
+			return skipped, err
+		}
+	}
+
+	return skipped, nil
+}
+
+// csvHeaderRow returns the (lower-cased) columns defined by the given record, if it's
+// a header row (i.e. it has a url column), or nil otherwise. Unknown columns are kept
+// as is, as those are header names (e.g. X-Api-Key).
+func csvHeaderRow(record []string) []string {
+	columns := make([]string, 0, len(record))
+
+	var hasURL bool
+	for _, c := range record {
+		c = strings.TrimSpace(c)
+
+		switch lower := strings.ToLower(c); lower {
+		case CSVColumnMethod, CSVColumnBody, CSVColumnContentType, CSVColumnHeaders:
+			columns = append(columns, lower)
+		case CSVColumnURL:
+			hasURL = true
+			columns = append(columns, lower)
+		default:
+			columns = append(columns, c)
+		}
+	}
+
+	if !hasURL {
+		return nil
+	}
+
+	return columns
+}
+
+// requestFromCSVRow builds the [request.Request] defined by the given CSV record,
+// according to the given columns (see [EachTemplateFromCSV]).
+func requestFromCSVRow(columns, record []string) (request.Request, error) {
+	if len(record) > len(columns) {
+		return request.Request{}, fmt.Errorf("too many fields, expected up to %d, got %d", len(columns), len(record)) //nolint:err113
+	}
+
+	var (
+		method, rawURL, body, contentType string
+		headers                           [][2]string
+	)
+
+	for i, value := range record {
+		switch columns[i] {
+		case CSVColumnMethod:
+			method = strings.ToUpper(strings.TrimSpace(value))
+		case CSVColumnURL:
+			rawURL = strings.TrimSpace(value)
+		case CSVColumnBody:
+			body = value
+		case CSVColumnContentType:
+			contentType = strings.TrimSpace(value)
+		case CSVColumnHeaders:
+			parsed, err := parseCSVHeaders(value)
+			if err != nil {
+				return request.Request{}, err
+			}
+			headers = append(headers, parsed...)
+		default:
+			if key := columns[i]; len(strings.TrimSpace(value)) > 0 {
+				if !isHeaderKey(key) {
+					return request.Request{}, fmt.Errorf("invalid header column: %q", key) //nolint:err113
+				}
+				headers = append(headers, [2]string{key, strings.TrimSpace(value)})
+			}
+		}
+	}
+
+	if len(rawURL) == 0 {
+		return request.Request{}, errors.New("no url defined") //nolint:err113
+	}
+
+	if err := url.Validate(&rawURL); err != nil {
+		return request.Request{}, err
+	}
+
+	if strings.IndexFunc(method, unicode.IsSpace) >= 0 {
+		return request.Request{}, fmt.Errorf("invalid method: %q", method) //nolint:err113
+	}
+
+	req := request.Default(rawURL)
+	if len(method) > 0 {
+		req.Method = method
+	}
+
+	for _, h := range headers {
+		req.SetHeader(h[0], h[1])
+	}
+
+	if len(contentType) > 0 {
+		req.SetHeader("Content-Type", contentType)
+	}
+
+	if len(body) > 0 {
+		req.SetBody([]byte(body))
+	}
+
+	return req, nil
+}
+
+// parseCSVHeaders parses the headers column (see [EachTemplateFromCSV]), either
+// as a JSON object (e.g. {"X-Api-Key": "abc"}) or as a JSON array (e.g. ["X-Api-Key: abc"]).
+func parseCSVHeaders(value string) ([][2]string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var (
+		headers [][2]string
+		object  map[string]string
+		array   []string
+	)
+
+	switch {
+	case json.Unmarshal([]byte(value), &object) == nil:
+		// Object keys are unordered, so headers are sorted to be deterministic.
+		keys := make([]string, 0, len(object))
+		for k := range object {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			headers = append(headers, [2]string{strings.TrimSpace(k), strings.TrimSpace(object[k])})
+		}
+	case json.Unmarshal([]byte(value), &array) == nil:
+		for _, h := range array {
+			k, v, found := strings.Cut(h, ":")
+			if !found {
+				return nil, fmt.Errorf("invalid header, it must be Key: Value: %s", h) //nolint:err113
+			}
+			headers = append(headers, [2]string{strings.TrimSpace(k), strings.TrimSpace(v)})
+		}
+	default:
+		return nil, fmt.Errorf("invalid headers, it must be a JSON object or array: %s", value) //nolint:err113
+	}
+
+	for _, h := range headers {
+		if !isHeaderKey(h[0]) {
+			return nil, fmt.Errorf("invalid header key: %q", h[0]) //nolint:err113
+		}
+	}
+
+	return headers, nil
+}
+
+func isHeaderKey(key string) bool {
+	return len(key) > 0 && strings.IndexFunc(key, unicode.IsSpace) < 0 && !strings.Contains(key, ":")
+}
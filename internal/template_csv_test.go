@@ -0,0 +1,118 @@
+package scan_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestEachTemplateFromCSV(t *testing.T) {
+	t.Parallel()
+
+	t.Run("positional columns", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte(`# method, url, body, content-type, headers
+GET,http://example.org/search?q=1
+post,http://example.org/login,"{""user"": ""admin""}",application/json,"{""X-Api-Key"": ""abc"", ""Accept"": ""*/*""}"
+
+PUT,http://example.org/items/1,a=b,,"[""X-Api-Key: def""]"
+`)
+
+		templates, err := scan.TemplatesFromCSV(context.Background(), scan.ParamsCfg{}, data)
+		require.NoError(t, err)
+		require.Len(t, templates, 3)
+
+		assert.Equal(t, http.MethodGet, templates[0].Method)
+		assert.Equal(t, "/search?q=1", templates[0].Path)
+		assert.Empty(t, templates[0].Body)
+
+		assert.Equal(t, http.MethodPost, templates[1].Method)
+		assert.Equal(t, `{"user": "admin"}`, string(templates[1].Body))
+		assert.Equal(t, "application/json", templates[1].ContentType())
+		assert.Equal(t, "abc", templates[1].Header("X-Api-Key"))
+		assert.Equal(t, "*/*", templates[1].Header("Accept"))
+
+		assert.Equal(t, http.MethodPut, templates[2].Method)
+		assert.Equal(t, "def", templates[2].Header("X-Api-Key"))
+
+		for i, tpl := range templates {
+			assert.Equal(t, i, tpl.Idx)
+		}
+	})
+
+	t.Run("header row", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte(`URL,X-Api-Key,Method
+http://example.org/a,abc,DELETE
+http://example.org/b,,
+`)
+
+		templates, err := scan.TemplatesFromCSV(context.Background(), scan.ParamsCfg{}, data)
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+
+		assert.Equal(t, http.MethodDelete, templates[0].Method)
+		assert.Equal(t, "abc", templates[0].Header("X-Api-Key"))
+
+		assert.Equal(t, http.MethodGet, templates[1].Method)
+		assert.Empty(t, templates[1].Header("X-Api-Key"))
+	})
+
+	t.Run("malformed rows", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte(`GET,http://example.org/ok
+GET,
+GET,http://example.org/,,,{not json}
+GET,http://example.org/"quoted
+GET,http://example.org/also-ok
+`)
+
+		var urls []string
+		skipped, err := scan.EachTemplateFromCSV(context.Background(), scan.ParamsCfg{}, data, func(tpl scan.Template) error {
+			urls = append(urls, tpl.Path)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/ok", "/also-ok"}, urls)
+
+		require.Len(t, skipped, 3)
+		lines := make([]int, 0, len(skipped))
+		for _, rowErr := range skipped {
+			require.ErrorIs(t, rowErr, scan.ErrInvalidCSVRow)
+			lines = append(lines, rowErr.Line)
+		}
+		assert.Equal(t, []int{2, 3, 4}, lines)
+		assert.Contains(t, skipped[0].Error(), "(line 2): no url defined")
+	})
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+
+		pCfg := scan.ParamsCfg{
+			Params: []string{"query", "order"},
+			Size:   1,
+			Method: http.MethodGet,
+		}
+
+		data := []byte("GET,http://example.org/a\nGET,http://example.org/b\n")
+
+		templates, err := scan.TemplatesFromCSV(context.Background(), pCfg, data)
+		require.NoError(t, err)
+		require.Len(t, templates, 4)
+
+		for i, tpl := range templates {
+			assert.Equal(t, i, tpl.Idx)
+		}
+
+		assert.Equal(t, "/a?query=query", templates[0].Path)
+		assert.Equal(t, "/b?order=order", templates[3].Path)
+	})
+}
@@ -0,0 +1,153 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/expr"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// ErrInvalidTemplateFilter is the error returned by [ParseTemplateFilter]
+// when the given expression is malformed, or references unknown variables.
+var ErrInvalidTemplateFilter = errors.New("invalid template filter")
+
+// Template variables, available to the template filter (see [TemplateFilter]).
+const (
+	filterVarMethod   = "request.method"
+	filterVarURL      = "request.url"
+	filterVarHost     = "request.host"
+	filterVarPath     = "request.path"
+	filterVarProto    = "request.proto"
+	filterVarHeaders  = "request.headers"
+	filterVarBody     = "request.body"
+	filterVarPriority = "template.priority"
+	filterVarPassive  = "template.passive"
+
+	// filterVarHeader is the prefix of the variables that hold the value of
+	// each request header, by its lowercase name (e.g. request.header.content-type).
+	filterVarHeader = "request.header."
+)
+
+// filterVars are the names of the template variables (see [TemplateFilter]),
+// but the request headers, whose names are only known once evaluated.
+var filterVars = []string{
+	filterVarMethod, filterVarURL, filterVarHost, filterVarPath, filterVarProto,
+	filterVarHeaders, filterVarBody, filterVarPriority, filterVarPassive,
+}
+
+// TemplateFilter is the expression (see [expr.Parse]) evaluated against each template
+// during dispatch, over the template variables (e.g. {{request.method}} == POST), so
+// those it doesn't hold for are skipped (i.e. no requests sent) and counted as filtered.
+//
+// Unlike the url filters, it is evaluated over the whole template, including the
+// request headers (e.g. {{request.header.content-type}}) and body. Templates
+// that lack any of the headers referenced are skipped as well.
+type TemplateFilter struct {
+	Expr *expr.Expr
+}
+
+// ParseTemplateFilter parses the given expression into a [TemplateFilter],
+// so it fails early when it is malformed, or references unknown variables.
+func ParseTemplateFilter(s string) (TemplateFilter, error) {
+	e, err := expr.Parse(s)
+	if err != nil {
+		return TemplateFilter{}, fmt.Errorf("%w: %s", ErrInvalidTemplateFilter, err.Error())
+	}
+
+	// Otherwise, it'd be constant (e.g. method == POST, with no braces), so
+	// either all templates or none of them would be scanned, likely by mistake.
+	if len(e.Vars()) == 0 {
+		return TemplateFilter{}, fmt.Errorf("%w: no variables referenced, e.g. {{%s}} == POST", ErrInvalidTemplateFilter, filterVarMethod)
+	}
+
+	for _, name := range e.Vars() {
+		if !isTemplateFilterVar(name) {
+			return TemplateFilter{}, fmt.Errorf("%w: unknown variable %q (available: %s and %s<name>)",
+				ErrInvalidTemplateFilter, name, strings.Join(filterVars, ", "), filterVarHeader)
+		}
+	}
+
+	return TemplateFilter{Expr: e}, nil
+}
+
+// Enabled returns whether the [TemplateFilter] is set or not.
+func (f TemplateFilter) Enabled() bool {
+	return f.Expr != nil
+}
+
+// Allows returns whether the given [Template] is scanned, according to the [TemplateFilter].
+func (f TemplateFilter) Allows(ctx context.Context, tpl Template) bool {
+	if !f.Enabled() {
+		return true
+	}
+
+	ok, err := f.Expr.Eval(templateFilterVariables(tpl))
+	if err != nil {
+		logger.For(ctx).Debugf("Template filter (--template-filter) for template (idx=%d): %s", tpl.Idx, err.Error())
+		return false
+	}
+
+	return ok
+}
+
+// String returns the string representation of the [TemplateFilter], as the original expression.
+func (f TemplateFilter) String() string {
+	if !f.Enabled() {
+		return ""
+	}
+
+	return f.Expr.String()
+}
+
+func isTemplateFilterVar(name string) bool {
+	if strings.HasPrefix(name, filterVarHeader) {
+		return len(name) > len(filterVarHeader)
+	}
+
+	for _, v := range filterVars {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// templateFilterVariables returns the variables describing the given template (see [TemplateFilter]).
+func templateFilterVariables(tpl Template) map[string]string {
+	vars := map[string]string{
+		filterVarMethod:   tpl.Method,
+		filterVarURL:      tpl.OriginalURL,
+		filterVarPath:     tpl.Path,
+		filterVarProto:    tpl.Proto,
+		filterVarBody:     string(tpl.Body),
+		filterVarPriority: strconv.Itoa(tpl.Priority),
+		filterVarPassive:  strconv.FormatBool(tpl.Response != nil),
+	}
+
+	if u, err := url.Parse(tpl.URL); err == nil {
+		vars[filterVarHost] = strings.ToLower(u.Hostname())
+	}
+
+	names := make([]string, 0, len(tpl.Headers))
+	for name := range tpl.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, name := range names {
+		value := strings.Join(tpl.Headers[name], ", ")
+		vars[filterVarHeader+strings.ToLower(name)] = value
+		headers.WriteString(name + ": " + value + "\n")
+	}
+	vars[filterVarHeaders] = headers.String()
+
+	return vars
+}
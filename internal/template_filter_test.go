@@ -0,0 +1,100 @@
+package scan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestParseTemplateFilter(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range []string{
+		"{{request.method}} == POST",
+		"{{request.header.content-type}} =~ json || {{template.passive}}",
+		"!({{request.body}} matches 'password=')",
+	} {
+		filter, err := scan.ParseTemplateFilter(src)
+		require.NoError(t, err, src)
+		assert.True(t, filter.Enabled())
+		assert.Equal(t, src, filter.String())
+	}
+
+	for _, src := range []string{
+		"",
+		"{{request.method}} ==",
+		"method == POST", // No variables referenced.
+		"{{request.verb}} == POST",
+		"{{request.header.}} == json",
+		"{{request.path}} matches '('",
+	} {
+		_, err := scan.ParseTemplateFilter(src)
+		require.ErrorIs(t, err, scan.ErrInvalidTemplateFilter, src)
+	}
+}
+
+func TestTemplateFilter_Allows(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	post := scan.Template{
+		Idx:         0,
+		OriginalURL: "https://api.example.com/v1/users",
+		Priority:    2,
+		Request: request.Request{
+			URL:     "https://API.example.com/v1/users",
+			Method:  "POST",
+			Path:    "/v1/users",
+			Proto:   "HTTP/1.1",
+			Headers: map[string][]string{"Content-Type": {"application/json"}},
+			Body:    []byte(`{"role":"admin"}`),
+		},
+	}
+
+	get := scan.Template{
+		Idx:         1,
+		OriginalURL: "https://www.example.com/",
+		Request: request.Request{
+			URL:    "https://www.example.com/",
+			Method: "GET",
+			Path:   "/",
+			Proto:  "HTTP/1.1",
+		},
+		Response: &response.Response{Code: 200},
+	}
+
+	tcs := map[string]struct {
+		post, get bool
+	}{
+		"{{request.method}} == POST && {{request.host}} matches '.*api.*'": {post: true},
+		"{{request.url}} =~ '/v1/'":                                        {post: true},
+		"{{request.path}} == /":                                            {get: true},
+		"{{request.proto}} == HTTP/1.1":                                    {post: true, get: true},
+		"{{request.header.content-type}} =~ json":                          {post: true},
+		"{{request.headers}} =~ 'Content-Type: application/'":              {post: true},
+		"{{request.body}} =~ admin":                                        {post: true},
+		"{{template.priority}} > 1":                                        {post: true},
+		"{{template.passive}}":                                             {get: true},
+		// Templates lacking the headers referenced are skipped.
+		"!({{request.header.content-type}} =~ json)": {},
+	}
+
+	for src, tc := range tcs {
+		filter, err := scan.ParseTemplateFilter(src)
+		require.NoError(t, err, src)
+
+		assert.Equal(t, tc.post, filter.Allows(ctx, post), src)
+		assert.Equal(t, tc.get, filter.Allows(ctx, get), src)
+	}
+
+	// No filter means all the templates are scanned.
+	assert.True(t, scan.TemplateFilter{}.Allows(ctx, post))
+	assert.True(t, scan.TemplateFilter{}.Allows(ctx, get))
+}
@@ -0,0 +1,267 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/logger"
+	"github.com/bountysecurity/gbounty/kit/url"
+)
+
+// PCAPSkipped holds the amount of TCP streams skipped while reading
+// the HTTP requests from a capture file (see [EachTemplateFromPCAP]).
+type PCAPSkipped struct {
+	// Encrypted is the amount of connections skipped because of being encrypted (e.g. HTTPS).
+	Encrypted int
+	// Malformed is the amount of streams skipped because of being either partial (e.g. with
+	// missing segments) or malformed (i.e. not HTTP/1.x). The requests read before are kept.
+	Malformed int
+}
+
+// Total returns the total amount of TCP streams skipped.
+func (s PCAPSkipped) Total() int {
+	return s.Encrypted + s.Malformed
+}
+
+// TemplatesFromPCAP initializes a slice of [Template] with the given [ParamsCfg], a slice of [request.Option]
+// and interpreting the slice of bytes as a capture file, with one template per HTTP request captured
+// (see [EachTemplateFromPCAP]). Encrypted, partial and malformed streams are skipped.
+//
+// See [EachTemplateFromPCAP] for a lazy alternative.
+func TemplatesFromPCAP(ctx context.Context, pCfg ParamsCfg, data []byte, opts ...request.Option) ([]Template, error) {
+	var templates []Template
+
+	_, err := EachTemplateFromPCAP(ctx, pCfg, data, func(tpl Template) error {
+		templates = append(templates, tpl)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// EachTemplateFromPCAP is like [TemplatesFromPCAP], but instead of building the whole set of [Template]
+// at once, it builds them one by one (see [ParamsCfg.AlterEach]), and calls fn with each of them, in
+// order. It stops as soon as fn returns an error, and returns it.
+//
+// The capture file can be either a pcap or a pcapng file, whose TCP streams are reassembled (i.e.
+// segments are reordered, and retransmissions discarded), and parsed as HTTP/1.x requests, in the
+// order the streams were opened, with their target URL built from the Host header (or from the
+// destination address, if missing), and always over http. So, the responses are ignored.
+//
+// Encrypted streams (i.e. TLS) are skipped, as well as those partial (e.g. with missing segments, or
+// truncated) or malformed, and returned as [PCAPSkipped], so the caller can decide whether to go on.
+func EachTemplateFromPCAP(ctx context.Context, pCfg ParamsCfg, data []byte, fn func(Template) error, opts ...request.Option) (PCAPSkipped, error) {
+	var (
+		streams []*tcpStream
+		open    = make(map[string]*tcpStream)
+	)
+
+	err := readPCAP(data, func(seg tcpSegment) {
+		key := seg.src + ">" + seg.dst
+
+		stream, ok := open[key]
+		switch {
+		// A new connection on the same address pair starts a new stream.
+		case seg.syn && (!ok || len(stream.segments) > 0):
+			stream = &tcpStream{src: seg.src, dst: seg.dst, base: seg.seq + 1, hasBase: true}
+			streams = append(streams, stream)
+			open[key] = stream
+		case !ok:
+			stream = &tcpStream{src: seg.src, dst: seg.dst}
+			streams = append(streams, stream)
+			open[key] = stream
+		}
+
+		if len(seg.payload) > 0 {
+			stream.segments = append(stream.segments, seg)
+		}
+	})
+	if err != nil {
+		return PCAPSkipped{}, err
+	}
+
+	var (
+		skipped   PCAPSkipped
+		encrypted = make(map[string]struct{})
+		tplIdx    int
+	)
+
+	for _, stream := range streams {
+		b, partial := stream.reassemble()
+
+		switch {
+		case len(b) == 0, bytes.HasPrefix(b, []byte("HTTP/")):
+			// Empty and server-to-client (i.e. responses) streams are ignored.
+			continue
+		case isTLSRecord(b):
+			// Both directions of the connection are encrypted, but it's counted once.
+			if _, ok := encrypted[stream.connection()]; !ok {
+				logger.For(ctx).Warnf("Skipping encrypted tcp stream (%s -> %s)", stream.src, stream.dst)
+				encrypted[stream.connection()] = struct{}{}
+				skipped.Encrypted++
+			}
+			continue
+		}
+
+		reqs, err := requestsFromStream(b, stream.dst)
+		if err == nil && partial {
+			err = errors.New("missing tcp segments") //nolint:err113
+		}
+		if err != nil {
+			logger.For(ctx).Warnf("Skipping malformed tcp stream (%s -> %s), after %d request(s): %s", stream.src, stream.dst, len(reqs), err.Error())
+			skipped.Malformed++
+		}
+
+		for _, req := range reqs {
+			for _, opt := range opts {
+				req = opt(req)
+			}
+
+			err = pCfg.AlterEach(ctx, NewTemplate(ctx, tplIdx, req, nil), func(tpl Template) error {
+				tplIdx++
+				return fn(tpl)
+			})
+			if err != nil {
+				return skipped, err
+			}
+		}
+	}
+
+	return skipped, nil
+}
+
+// tcpStream is the (one-way) stream of TCP segments sent from src to dst.
+type tcpStream struct {
+	src, dst string
+	// base is the sequence number of the first byte of the stream,
+	// only known (i.e. hasBase) if the SYN segment was captured.
+	base     uint32
+	hasBase  bool
+	segments []tcpSegment
+}
+
+// connection returns the key that identifies the connection the
+// stream belongs to, regardless of the direction of the stream.
+func (s *tcpStream) connection() string {
+	if s.src < s.dst {
+		return s.src + "-" + s.dst
+	}
+	return s.dst + "-" + s.src
+}
+
+// reassemble returns the bytes of the stream, in order, with no retransmissions. If there
+// are missing segments, it returns the bytes before the first gap, and partial is true.
+//
+// If the SYN segment wasn't captured (see [tcpStream.hasBase]), the stream is assumed to
+// start at the lowest sequence number captured.
+func (s *tcpStream) reassemble() (b []byte, partial bool) {
+	if len(s.segments) == 0 {
+		return nil, false
+	}
+
+	base := s.base
+	if !s.hasBase {
+		base = s.segments[0].seq
+		for _, seg := range s.segments[1:] {
+			// Sequence numbers wrap around, so these are compared by their difference.
+			if int32(seg.seq-base) < 0 { //nolint:gosec
+				base = seg.seq
+			}
+		}
+	}
+
+	segments := make([]tcpSegment, len(s.segments))
+	copy(segments, s.segments)
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].seq-base < segments[j].seq-base
+	})
+
+	for _, seg := range segments {
+		off := int(seg.seq - base)
+		switch {
+		case off > len(b):
+			return b, true
+		case off+len(seg.payload) <= len(b):
+			// Retransmitted (or overlapping) data is discarded.
+			continue
+		default:
+			b = append(b, seg.payload[len(b)-off:]...)
+		}
+	}
+
+	return b, false
+}
+
+// isTLSRecord returns whether the given bytes start with a TLS record
+// header (i.e. any of the content types, followed by the major version).
+func isTLSRecord(b []byte) bool {
+	const (
+		minContentType = 0x14 // change_cipher_spec
+		maxContentType = 0x17 // application_data
+		majorVersion   = 0x03
+	)
+
+	return len(b) > 1 && b[0] >= minContentType && b[0] <= maxContentType && b[1] == majorVersion
+}
+
+// requestsFromStream parses the given stream as a sequence of (pipelined) HTTP/1.x requests,
+// sent to the given destination address (ip:port), which is used as the target URL for those
+// requests with no Host header. It returns the requests parsed before the first error, if any.
+func requestsFromStream(b []byte, dst string) ([]request.Request, error) {
+	var (
+		reqs  []request.Request
+		r     = bytes.NewReader(b)
+		br    = bufio.NewReader(r)
+		start int
+	)
+
+	for start < len(b) {
+		httpReq, err := http.ReadRequest(br)
+		if err != nil {
+			return reqs, err
+		}
+
+		if httpReq.ProtoMajor != 1 {
+			return reqs, fmt.Errorf("unsupported protocol: %s", httpReq.Proto) //nolint:err113
+		}
+
+		// The body is read to find where the request ends, either by its
+		// Content-Length or its chunked encoding, but kept as is (i.e. raw).
+		if _, err := io.Copy(io.Discard, httpReq.Body); err != nil {
+			return reqs, err
+		}
+
+		end := len(b) - r.Len() - br.Buffered()
+		raw := b[start:end]
+		start = end
+
+		host := httpReq.Host
+		if len(host) == 0 {
+			host = dst
+		}
+
+		rawURL := "http://" + host
+		if err := url.Validate(&rawURL); err != nil {
+			return reqs, err
+		}
+
+		req, err := request.ParseRequest(raw, rawURL)
+		if err != nil {
+			return reqs, err
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, nil
+}
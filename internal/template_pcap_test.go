@@ -0,0 +1,253 @@
+package scan_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+)
+
+func TestEachTemplateFromPCAP(t *testing.T) {
+	t.Parallel()
+
+	const (
+		client = "10.0.0.2:51000"
+		server = "10.0.0.1:8080"
+	)
+
+	t.Run("reassembled and pipelined requests", func(t *testing.T) {
+		t.Parallel()
+
+		get := "GET /search?q=1 HTTP/1.1\r\nHost: example.org:8080\r\nX-Api-Key: abc\r\n\r\n"
+		post := "POST /login HTTP/1.1\r\nHost: example.org:8080\r\nContent-Type: application/json\r\nContent-Length: 17\r\n\r\n{\"user\": \"admin\"}"
+		stream := get + post
+
+		data := pcapFile(
+			tcpPacket(client, server, 999, true, ""),
+			// Out of order, and with retransmissions.
+			tcpPacket(client, server, 1000+30, false, stream[30:80]),
+			tcpPacket(client, server, 1000, false, stream[:30]),
+			tcpPacket(client, server, 1000+30, false, stream[30:80]),
+			tcpPacket(client, server, 1000+80, false, stream[80:]),
+			// Responses are ignored.
+			tcpPacket(server, client, 5000, false, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"),
+		)
+
+		var templates []scan.Template
+		skipped, err := scan.EachTemplateFromPCAP(context.Background(), scan.ParamsCfg{}, data, func(tpl scan.Template) error {
+			templates = append(templates, tpl)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Zero(t, skipped.Total())
+		require.Len(t, templates, 2)
+
+		assert.Equal(t, "http://example.org:8080", templates[0].URL)
+		assert.Equal(t, http.MethodGet, templates[0].Method)
+		assert.Equal(t, "/search?q=1", templates[0].Path)
+		assert.Equal(t, "abc", templates[0].Header("X-Api-Key"))
+
+		assert.Equal(t, http.MethodPost, templates[1].Method)
+		assert.Equal(t, "/login", templates[1].Path)
+		assert.Equal(t, `{"user": "admin"}`, string(templates[1].Body))
+
+		for i, tpl := range templates {
+			assert.Equal(t, i, tpl.Idx)
+		}
+	})
+
+	t.Run("encrypted and malformed streams", func(t *testing.T) {
+		t.Parallel()
+
+		req := "GET /ok HTTP/1.1\r\nHost: example.org\r\n\r\n"
+		data := pcapFile(
+			// Encrypted connection (both directions).
+			tcpPacket("10.0.0.3:51001", "10.0.0.1:443", 100, false, "\x16\x03\x01\x00\x05hello"),
+			tcpPacket("10.0.0.1:443", "10.0.0.3:51001", 200, false, "\x16\x03\x03\x00\x05hello"),
+			// Partial stream: the first request is kept, but the rest is missing.
+			tcpPacket(client, server, 1000, false, req),
+			tcpPacket(client, server, 1000+uint32(len(req))+10, false, "GET /lost HTTP/1.1\r\n\r\n"),
+			// Malformed stream.
+			tcpPacket("10.0.0.4:51002", server, 300, false, "NOT AN HTTP REQUEST\r\n\r\n"),
+		)
+
+		var paths []string
+		skipped, err := scan.EachTemplateFromPCAP(context.Background(), scan.ParamsCfg{}, data, func(tpl scan.Template) error {
+			paths = append(paths, tpl.Path)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"/ok"}, paths)
+		assert.Equal(t, scan.PCAPSkipped{Encrypted: 1, Malformed: 2}, skipped)
+	})
+
+	t.Run("pcapng", func(t *testing.T) {
+		t.Parallel()
+
+		templates, err := scan.TemplatesFromPCAP(context.Background(), scan.ParamsCfg{}, pcapngFile(
+			tcpPacket(client, server, 1000, false, "GET /ng HTTP/1.1\r\nHost: example.org\r\n\r\n"),
+		))
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, "http://example.org", templates[0].URL)
+		assert.Equal(t, "/ng", templates[0].Path)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := scan.TemplatesFromPCAP(context.Background(), scan.ParamsCfg{}, []byte("GET / HTTP/1.1\r\n\r\n"))
+		require.ErrorIs(t, err, scan.ErrInvalidPCAP)
+	})
+}
+
+func FuzzEachTemplateFromPCAP(f *testing.F) {
+	const (
+		client = "10.0.0.2:51000"
+		server = "10.0.0.1:8080"
+	)
+
+	req := "GET /search?q=1 HTTP/1.1\r\nHost: example.org:8080\r\n\r\n"
+	f.Add(pcapFile(tcpPacket(client, server, 999, true, ""), tcpPacket(client, server, 1000, false, req)))
+	f.Add(pcapngFile(tcpPacket(client, server, 1000, false, req)))
+	f.Add(pcapFile(tcpPacket(client, server, 100, false, "\x16\x03\x01\x00\x05hello")))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Any capture file, no matter how malformed, must be either rejected
+		// as such, or read (skipping those streams that cannot be parsed).
+		var idx int
+		_, err := scan.EachTemplateFromPCAP(context.Background(), scan.ParamsCfg{}, data, func(tpl scan.Template) error {
+			assert.Equal(t, idx, tpl.Idx)
+			assert.True(t, strings.HasPrefix(tpl.URL, "http://"), tpl.URL)
+			idx++
+			return nil
+		})
+		if err != nil {
+			require.ErrorIs(t, err, scan.ErrInvalidPCAP)
+		}
+	})
+}
+
+func FuzzEachTemplateFromPCAP_Stream(f *testing.F) {
+	const (
+		client = "10.0.0.2:51000"
+		server = "10.0.0.1:8080"
+	)
+
+	f.Add(uint32(1000), "GET / HTTP/1.1\r\nHost: example.org\r\n\r\n", uint32(1000+38), "GET /b HTTP/1.1\r\n\r\n")
+	f.Add(uint32(1000), "POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n", uint32(1000+48), "5\r\nhello\r\n0\r\n\r\n")
+	f.Add(^uint32(0)-4, "GET / HTTP/1.1\r\n", uint32(11), "Host: a\r\n\r\n")
+
+	f.Fuzz(func(t *testing.T, seq1 uint32, payload1 string, seq2 uint32, payload2 string) {
+		// Any pair of segments (e.g. overlapping, with gaps, or wrapping around
+		// the sequence numbers) must be reassembled and parsed with no error.
+		data := pcapFile(
+			tcpPacket(client, server, seq1, false, payload1),
+			tcpPacket(client, server, seq2, false, payload2),
+		)
+
+		_, err := scan.EachTemplateFromPCAP(context.Background(), scan.ParamsCfg{}, data, func(tpl scan.Template) error {
+			assert.True(t, strings.HasPrefix(tpl.URL, "http://"), tpl.URL)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+// tcpPacket builds an Ethernet frame with an IPv4 packet with a TCP segment,
+// sent from src to dst (ip:port), with the given sequence number and payload.
+func tcpPacket(src, dst string, seq uint32, syn bool, payload string) []byte {
+	srcIP, srcPort := splitAddr(src)
+	dstIP, dstPort := splitAddr(dst)
+
+	tcp := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	tcp[12] = 5 << 4
+	tcp[13] = 0x18 // PSH, ACK
+	if syn {
+		tcp[13] = 0x02
+	}
+	tcp = append(tcp, payload...)
+
+	ip := make([]byte, 20, 20+len(tcp))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], srcIP)
+	copy(ip[16:20], dstIP)
+	ip = append(ip, tcp...)
+
+	eth := make([]byte, 14, 14+len(ip))
+	binary.BigEndian.PutUint16(eth[12:], 0x0800)
+
+	return append(eth, ip...)
+}
+
+func splitAddr(addr string) (net.IP, uint16) {
+	host, port, _ := net.SplitHostPort(addr)
+	p, _ := net.LookupPort("tcp", port)
+	return net.ParseIP(host).To4(), uint16(p)
+}
+
+// pcapFile builds a (little-endian) pcap file with the given Ethernet frames.
+func pcapFile(frames ...[]byte) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, 0xa1b2c3d4)
+	b = binary.LittleEndian.AppendUint16(b, 2)
+	b = binary.LittleEndian.AppendUint16(b, 4)
+	b = append(b, make([]byte, 8)...)
+	b = binary.LittleEndian.AppendUint32(b, 65535)
+	b = binary.LittleEndian.AppendUint32(b, 1)
+
+	for _, f := range frames {
+		b = append(b, make([]byte, 8)...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(f)))
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(f)))
+		b = append(b, f...)
+	}
+
+	return b
+}
+
+// pcapngFile builds a (little-endian) pcapng file with the given Ethernet frames,
+// as enhanced packet blocks of a single interface.
+func pcapngFile(frames ...[]byte) []byte {
+	block := func(b []byte, typ uint32, body []byte) []byte {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+		b = binary.LittleEndian.AppendUint32(b, typ)
+		b = binary.LittleEndian.AppendUint32(b, uint32(12+len(body)))
+		b = append(b, body...)
+		return binary.LittleEndian.AppendUint32(b, uint32(12+len(body)))
+	}
+
+	shb := binary.LittleEndian.AppendUint32(nil, 0x1a2b3c4d)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)
+	shb = binary.LittleEndian.AppendUint16(shb, 0)
+	shb = binary.LittleEndian.AppendUint64(shb, ^uint64(0))
+	b := block(nil, 0x0a0d0d0a, shb)
+
+	idb := binary.LittleEndian.AppendUint16(nil, 1)
+	idb = append(idb, make([]byte, 6)...)
+	b = block(b, 1, idb)
+
+	for _, f := range frames {
+		epb := make([]byte, 12)
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(len(f)))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(len(f)))
+		b = block(b, 6, append(epb, f...))
+	}
+
+	return b
+}
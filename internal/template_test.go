@@ -0,0 +1,76 @@
+package scan_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+)
+
+func TestEachTemplateFromZipBytes(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, path := range []string{"/search.php", "/login.php"} {
+		w, err := zw.Create(path)
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("GET " + path + " HTTP/1.1\r\nHost: testphp.vulnweb.com\r\n\r\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	pCfg := scan.ParamsCfg{
+		Params: []string{"query", "order", "limit"},
+		Size:   1,
+		Method: http.MethodGet,
+	}
+
+	var streamed []scan.Template
+	err := scan.EachTemplateFromZipBytes(context.Background(), pCfg, buf.Bytes(), func(tpl scan.Template) error {
+		streamed = append(streamed, tpl)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Same templates, in the same order, as if materialized at once.
+	templates, err := scan.TemplatesFromZipBytes(context.Background(), pCfg, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, templates, streamed)
+
+	require.Len(t, streamed, 6)
+	for i, tpl := range streamed {
+		assert.Equal(t, i, tpl.Idx)
+	}
+
+	assert.Equal(t, "/search.php?query=query", streamed[0].Path)
+	assert.Equal(t, "/login.php?limit=limit", streamed[5].Path)
+}
+
+func TestTemplateFromRawBytes_LineEndings(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte("POST /login HTTP/1.1\nHost: example.org\r\nContent-Type: text/plain\n\nuser=admin\npass=admin\r\n")
+
+	templates, err := scan.TemplateFromRawBytes(context.Background(), 0, scan.ParamsCfg{}, raw)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+
+	tpl := templates[0]
+	assert.Equal(t, "text/plain", tpl.Header("Content-Type"))
+	assert.Equal(t, "user=admin\npass=admin\r\n", string(tpl.Body))
+	assert.Equal(t, "POST /login HTTP/1.1\r\nHost: example.org\r\nContent-Type: text/plain\r\n\r\nuser=admin\npass=admin\r\n", string(tpl.RawBytes()))
+
+	templates, err = scan.TemplateFromRawBytes(context.Background(), 0, scan.ParamsCfg{}, raw, request.WithLineEndings(raw))
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, string(raw), string(templates[0].RawBytes()))
+}
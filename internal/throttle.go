@@ -0,0 +1,208 @@
+package scan
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+const (
+	// latencyWeight is the weight of the latest response time
+	// on the (exponentially weighted) moving average latency.
+	latencyWeight = 0.3
+	// slowDownFactor is the factor the rate is multiplied by when the latency is too high.
+	slowDownFactor = 0.75
+	// backOffFactor is the factor the rate is multiplied by on errors and 429 (or 503).
+	backOffFactor = 0.5
+	// maxRetryAfter is the maximum pause honored from the Retry-After header.
+	maxRetryAfter = time.Minute
+)
+
+// AdaptiveThrottle bounds the rate of requests sent to each host, adjusted dynamically
+// according to the host's health: it backs off (halving the rate) on network errors and
+// on 429 (or 503) responses, slows down when the (rolling) response latency exceeds the
+// target, and ramps up (by one request per second) while the host is healthy.
+//
+// The rate of each host is kept between the minimum and maximum requests per second,
+// starting from the minimum. It is an additional bound to the rate limit per URL (see
+// [Config.RPS]), so it never sends requests faster than that. Use [WithAdaptiveThrottle]
+// to apply it to the requests sent.
+type AdaptiveThrottle struct {
+	targetLatency  time.Duration
+	minRPS, maxRPS float64
+
+	mu    sync.Mutex
+	hosts map[string]*hostThrottle
+}
+
+type hostThrottle struct {
+	rps     float64
+	next    time.Time
+	latency time.Duration
+}
+
+// NewAdaptiveThrottle is a constructor function that creates a new instance of [AdaptiveThrottle]
+// with the given target latency, and the minimum and maximum requests per second per host.
+func NewAdaptiveThrottle(targetLatency time.Duration, minRPS, maxRPS int) *AdaptiveThrottle {
+	if minRPS < 1 {
+		minRPS = 1
+	}
+
+	if maxRPS < minRPS {
+		maxRPS = minRPS
+	}
+
+	return &AdaptiveThrottle{
+		targetLatency: targetLatency,
+		minRPS:        float64(minRPS),
+		maxRPS:        float64(maxRPS),
+		hosts:         make(map[string]*hostThrottle),
+	}
+}
+
+// RPS returns the current rate (requests per second) of the given host.
+func (t *AdaptiveThrottle) RPS(host string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.host(host).rps
+}
+
+// wait blocks until the next request to the given host can be sent, according to
+// its current rate, or until the given [context.Context] is done.
+func (t *AdaptiveThrottle) wait(ctx context.Context, host string) error {
+	t.mu.Lock()
+	h := t.host(host)
+	at := time.Now()
+	if h.next.After(at) {
+		at = h.next
+	}
+	h.next = at.Add(time.Duration(float64(time.Second) / h.rps))
+	t.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe adjusts the rate of the given host according to the given response, or error.
+func (t *AdaptiveThrottle) observe(ctx context.Context, host string, res response.Response, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := t.host(host)
+	prev := h.rps
+
+	switch {
+	case err != nil || res.Code == http.StatusTooManyRequests || res.Code == http.StatusServiceUnavailable:
+		h.rps = max(t.minRPS, h.rps*backOffFactor)
+		if pause := retryAfter(res); pause > 0 {
+			h.next = time.Now().Add(pause)
+		}
+	default:
+		if h.latency == 0 {
+			h.latency = res.Time
+		} else {
+			h.latency = time.Duration(latencyWeight*float64(res.Time) + (1-latencyWeight)*float64(h.latency))
+		}
+
+		if h.latency > t.targetLatency {
+			h.rps = max(t.minRPS, h.rps*slowDownFactor)
+		} else {
+			h.rps = min(t.maxRPS, h.rps+1)
+		}
+	}
+
+	if h.rps < prev {
+		logger.For(ctx).Debugf("Adaptive throttle backing off for host %s: %.2f req/s (latency: %s)", host, h.rps, h.latency)
+	}
+}
+
+func (t *AdaptiveThrottle) host(host string) *hostThrottle {
+	h, ok := t.hosts[host]
+	if !ok {
+		h = &hostThrottle{rps: t.minRPS}
+		t.hosts[host] = h
+	}
+
+	return h
+}
+
+// retryAfter returns the pause requested by the Retry-After header (in seconds) of
+// the given response, if any, bounded by [maxRetryAfter]. HTTP dates aren't supported.
+func retryAfter(res response.Response) time.Duration {
+	values := res.Headers["Retry-After"]
+	if len(values) == 0 {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return min(time.Duration(seconds)*time.Second, maxRetryAfter)
+}
+
+// WithAdaptiveThrottle decorates the given [RequesterBuilder], so every request sent
+// through the built [Requester] is throttled by the given [AdaptiveThrottle], per host.
+// Each redirect followed is a different request, so it is throttled too.
+func WithAdaptiveThrottle(fn RequesterBuilder, t *AdaptiveThrottle) RequesterBuilder {
+	return func() (Requester, error) {
+		requester, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		return throttledRequester{Requester: requester, throttle: t}, nil
+	}
+}
+
+type throttledRequester struct {
+	Requester
+	throttle *AdaptiveThrottle
+}
+
+func (r throttledRequester) Do(ctx context.Context, req *request.Request) (response.Response, error) {
+	host := throttleHost(req)
+	if err := r.throttle.wait(ctx, host); err != nil {
+		return response.Response{}, err
+	}
+
+	// Warmup requests are throttled, but their timing isn't observed, as it isn't representative.
+	res, err := r.Requester.Do(ctx, req)
+	if ctx.Err() == nil && !isWarmup(ctx) {
+		r.throttle.observe(ctx, host, res, err)
+	}
+
+	return res, err
+}
+
+// throttleHost returns the host (lowercase) the given request is sent to, with the port, if any.
+func throttleHost(req *request.Request) string {
+	u, err := url.Parse(req.URL)
+	if err != nil || len(u.Host) == 0 {
+		return strings.ToLower(req.URL)
+	}
+
+	return strings.ToLower(u.Host)
+}
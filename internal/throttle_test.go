@@ -0,0 +1,76 @@
+package scan_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scan "github.com/bountysecurity/gbounty/internal"
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/internal/response"
+)
+
+func TestWithAdaptiveThrottle(t *testing.T) {
+	t.Parallel()
+
+	const (
+		minRPS, maxRPS = 50, 1000
+		healthy        = 20
+	)
+
+	healthyRes := response.Response{Code: http.StatusOK, Time: time.Millisecond}
+
+	tcs := map[string]struct {
+		res      response.Response
+		expected float64
+	}{
+		"back off on too many requests": {
+			res:      response.Response{Code: http.StatusTooManyRequests},
+			expected: minRPS, // (50+20) * 0.5 = 35, but never below the minimum
+		},
+		"slow down on high latency": {
+			res:      response.Response{Code: http.StatusOK, Time: 10 * time.Second},
+			expected: (minRPS + healthy) * 0.75,
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			requester := &countingRequester{res: healthyRes}
+			throttle := scan.NewAdaptiveThrottle(time.Second, minRPS, maxRPS)
+			builder := scan.WithAdaptiveThrottle(func() (scan.Requester, error) {
+				return requester, nil
+			}, throttle)
+
+			r, err := builder()
+			require.NoError(t, err)
+
+			req := request.Default("http://example.org:8080/")
+			assert.InDelta(t, float64(minRPS), throttle.RPS("example.org:8080"), 0)
+
+			// Healthy responses ramp up the rate.
+			for i := 0; i < healthy; i++ {
+				_, err = r.Do(context.Background(), &req)
+				require.NoError(t, err)
+			}
+			assert.InDelta(t, float64(minRPS+healthy), throttle.RPS("example.org:8080"), 0)
+
+			requester.res = tc.res
+			_, err = r.Do(context.Background(), &req)
+			require.NoError(t, err)
+
+			assert.InDelta(t, tc.expected, throttle.RPS("example.org:8080"), 0.01)
+			assert.Equal(t, int32(healthy+1), requester.count.Load())
+
+			// Other hosts are throttled independently.
+			assert.InDelta(t, float64(minRPS), throttle.RPS("example.com"), 0)
+		})
+	}
+}
@@ -2,9 +2,14 @@ package scan
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bountysecurity/gbounty/internal/entrypoint"
+	"github.com/bountysecurity/gbounty/internal/match"
 	"github.com/bountysecurity/gbounty/internal/profile"
 	"github.com/bountysecurity/gbounty/internal/request"
 	"github.com/bountysecurity/gbounty/internal/response"
@@ -31,6 +36,7 @@ type TaskSummary struct {
 // There can be multiple [Match] per scan.
 // See the `internal/match` package for further details.
 type Match struct {
+	ID                    string
 	URL                   string
 	Requests              []*request.Request
 	Responses             []*response.Response
@@ -48,7 +54,115 @@ type Match struct {
 	Payload               string
 	Occurrences           [][]occurrence.Occurrence
 	Grep                  string
+	Metadata              map[string]string
+	RequestIDs            []string
+	Origin                *MatchOrigin
 	At                    time.Time
+	AffectedEntrypoints   []AffectedEntrypoint
+	ResponseDiff          *ResponseDiff
+}
+
+// MatchID returns a stable identifier for the given [Match], derived from
+// the details that make a finding unique: the URL, the profile, the issue,
+// the affected parameter, the payload and, if any, the profile step and the
+// insertion point (see [MatchOrigin]), so those found with the same payload
+// at different steps or entrypoints don't collide. So, the same finding
+// found on different scans gets the same identifier.
+func MatchID(m Match) string {
+	keys := []string{m.URL, m.ProfileName, m.IssueName, m.IssueParam, m.Payload}
+	if m.Origin != nil && m.Origin.Step > 0 {
+		keys = append(keys, "step:"+strconv.Itoa(m.Origin.Step))
+	}
+	if m.Origin != nil && len(m.Origin.InsertionPoint) > 0 {
+		keys = append(keys, "insertion point:"+m.Origin.InsertionPoint)
+	}
+
+	h := sha256.New()
+	for _, s := range keys {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	const idLength = 12
+
+	return hex.EncodeToString(h.Sum(nil))[:idLength]
+}
+
+// MatchMetadata returns the [Match.Metadata] for the given [profile.Profile], requests and
+// responses: the given metadata along with those keys set by the scanner itself, if any:
+//   - [MetadataMutations]: the mutations applied to the requests.
+//   - [MetadataCanary]: the canaries prepended to the payloads.
+//   - [MetadataFileRead]: the files read.
+//   - [MetadataReflectionContext]: the contexts the payload is reflected in.
+//   - [MetadataCloudProvider] and [MetadataCloudField]: the cloud metadata or buckets exposed.
+//   - [MetadataDebugSurface] and [MetadataDebugEndpoint]: the debug surfaces exposed.
+//   - [MetadataDeserialization]: the serialization formats detected.
+//   - [MetadataParseError]: the body parse errors.
+//   - [MetadataDecompressionTruncated]: the responses whose body was truncated while decompressed.
+//   - [MetadataWebSocketProtocol]: the WebSocket subprotocol negotiated.
+//   - [MetadataGraphQLTypes]: the GraphQL types exposed.
+//   - [MetadataRateLimit] and [MetadataRateLimitThreshold]: the rate limit observed.
+//   - [MetadataMassAssignment]: the evidence of the parameters accepted.
+//   - [MetadataCachePoisoning] and [MetadataCacheHeaders]: the unkeyed headers cached.
+//   - [MetadataTechnology] and [MetadataTechnologySignatures]: the technologies found.
+func MatchMetadata(
+	ctx context.Context,
+	metadata map[string]string,
+	prof profile.Profile,
+	reqs []*request.Request,
+	res []*response.Response,
+	payload string,
+) map[string]string {
+	metadata = withMetadata(metadata, MetadataMutations, Mutations(reqs))
+	metadata = withMetadata(metadata, MetadataCanary, Canaries(reqs))
+	metadata = withMetadata(metadata, MetadataFileRead, FilesRead(ctx, prof, res, payload))
+	metadata = withMetadata(metadata, MetadataReflectionContext, ReflectionContexts(ctx, prof, reqs, res, payload))
+	metadata = cloudMetadata(metadata, CloudExposures(ctx, prof, res, payload))
+	metadata = debugMetadata(ctx, metadata, prof, reqs, res)
+	metadata = withMetadata(metadata, MetadataDeserialization, DeserializationFormats(prof, res, payload))
+	metadata = withMetadata(metadata, MetadataParseError, ParseErrors(prof, res))
+	metadata = withMetadata(metadata, MetadataDecompressionTruncated, DecompressionTruncated(res))
+	metadata = withMetadata(metadata, MetadataWebSocketProtocol, WebSocketProtocols(prof, res))
+	metadata = withMetadata(metadata, MetadataGraphQLTypes, GraphQLTypes(prof, res))
+	metadata = rateLimitMetadata(ctx, metadata)
+	metadata = massAssignmentMetadata(ctx, metadata)
+	metadata = cachePoisoningMetadata(ctx, metadata)
+
+	if technologies := Technologies(ctx, prof, res); len(technologies) > 0 {
+		metadata = withMetadata(metadata, MetadataTechnology, technologies)
+		metadata = withMetadata(metadata, MetadataTechnologySignatures, []string{match.TechnologySignaturesVersion(ctx)})
+	}
+
+	return metadata
+}
+
+// withMetadata returns the given metadata along with the given key and values (comma-separated),
+// if any, as a copy, as it might be shared with other matches.
+func withMetadata(metadata map[string]string, key string, values []string) map[string]string {
+	if len(values) == 0 {
+		return metadata
+	}
+
+	cp := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		cp[k] = v
+	}
+	cp[key] = strings.Join(values, ", ")
+
+	return cp
+}
+
+// MetadataKeys returns the keys of the [Match.Metadata] set by the scanner itself (e.g. [MetadataCanary]),
+// so these can be told apart from those defined by the user (see [Config.Metadata]).
+func MetadataKeys() []string {
+	return []string{
+		MetadataCachePoisoning, MetadataCacheHeaders, MetadataCanary, MetadataCloudProvider,
+		MetadataCloudField, MetadataDebugSurface, MetadataDebugEndpoint, MetadataDecompressionTruncated,
+		MetadataDeserialization, MetadataSource, MetadataFileRead, MetadataGraphQLTypes,
+		MetadataParseError, MetadataMassAssignment, MetadataMutations, MetadataRateLimit,
+		MetadataRateLimitThreshold, MetadataReflectionContext, MetadataTechnology,
+		MetadataTechnologySignatures, MetadataWebSocketProtocol,
+	}
 }
 
 // Error represents an error that occurred during a [scan], containing the URL,
@@ -69,6 +183,14 @@ type (
 	onMatchFunc func(context.Context, string, []*request.Request, []*response.Response, profile.Profile, profile.IssueInformation, entrypoint.Entrypoint, string, [][]occurrence.Occurrence)
 	onErrorFunc func(context.Context, string, []*request.Request, []*response.Response, error)
 	onTaskFunc  func(context.Context, string, []*request.Request, []*response.Response)
+
+	// filterBodyFunc returns the response to be analyzed by the matchers,
+	// either the given one or a copy with no body (see [MimeFilter]).
+	filterBodyFunc func(*response.Response) *response.Response
+
+	// filterResponseFunc returns whether the response must be skipped, so neither
+	// evaluated by the matchers nor recorded (see [ResponseFilter]).
+	filterResponseFunc func(Template, *response.Response) bool
 )
 
 type update struct {
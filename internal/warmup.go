@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bountysecurity/gbounty/internal/request"
+	"github.com/bountysecurity/gbounty/kit/logger"
+)
+
+// hostWarmups keeps track of the hosts (origins, actually) already warmed up (see
+// [Config.Warmup]), so a single throwaway request is sent to each of them, before
+// the first template targeting it is scanned.
+type hostWarmups struct {
+	mu    sync.Mutex
+	hosts map[string]*sync.Once
+}
+
+func newHostWarmups() *hostWarmups {
+	return &hostWarmups{hosts: make(map[string]*sync.Once)}
+}
+
+// once returns the [sync.Once] of the given host, so the templates targeting the same
+// host that are scanned concurrently wait until the warmup request has been sent.
+func (w *hostWarmups) once(host string) *sync.Once {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	o, ok := w.hosts[host]
+	if !ok {
+		o = new(sync.Once)
+		w.hosts[host] = o
+	}
+
+	return o
+}
+
+// warmup sends a throwaway request to the origin targeted by the given [Template], if
+// warmup is enabled (see [Config.Warmup]) and it hasn't been warmed up yet, so the DNS
+// resolution cost isn't paid by the first request scanned, which skews timing-based
+// detections. Connections aren't reused (i.e. every request opens its own), so it's only
+// the DNS resolution that is primed, as long as it is cached (see --dns-cache-ttl).
+//
+// The request is a harmless HEAD request to the origin's root, instead of the template's
+// one, which might change the target's state (e.g. a POST request). Its response is neither
+// analyzed nor recorded, and its timing isn't observed (see [isWarmup]). It is accounted
+// on its own (see [Stats.NumOfWarmupRequests]), apart from the scan requests.
+func (r *Runner) warmup(tpl Template) {
+	if !r.opts.cfg.Warmup || tpl.Response != nil {
+		return
+	}
+
+	r.warmups.once(warmupHost(tpl)).Do(func() {
+		ctx := withWarmup(r.opts.ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		req, ok := warmupRequest(tpl)
+		if !ok {
+			logger.For(ctx).Debugf("Skipping host warmup, origin cannot be determined: %s", tpl.URL)
+			return
+		}
+
+		logger.For(ctx).Debugf("Warming up host with request: %s %s", req.Method, req.URL)
+
+		requester, err := r.opts.reqBuilder()
+		if err == nil {
+			_, err = requester.Do(ctx, &req)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			logger.For(ctx).Warnf("Warmup request (%s) failed: %s", req.URL, err)
+		}
+
+		r.stats.incrementWarmupRequests(err != nil)
+	})
+}
+
+// warmupHost returns the origin (lowercase scheme and host, with the port, if any) targeted
+// by the given [Template], as the warmup request is sent once per origin (see [warmupRequest]).
+// If the origin cannot be determined, it falls back to the template's host.
+func warmupHost(tpl Template) string {
+	u, err := url.Parse(tpl.URL)
+	if err != nil || len(u.Host) == 0 {
+		return templateHost(tpl)
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// warmupRequest returns the HEAD request to the root of the origin targeted by the
+// given [Template] (see [Runner.warmup]), or false if it cannot be determined.
+func warmupRequest(tpl Template) (request.Request, bool) {
+	u, err := url.Parse(tpl.URL)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return request.Request{}, false
+	}
+
+	req := request.Default(u.Scheme + "://" + u.Host + "/")
+	req.Method = http.MethodHead
+
+	return req, true
+}
+
+type warmupKey struct{}
+
+// withWarmup returns a copy of the given [context.Context] that marks
+// the requests sent with it as warmup ones (see [Runner.warmup]).
+func withWarmup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warmupKey{}, true)
+}
+
+// isWarmup returns whether the given [context.Context] belongs to a warmup request,
+// so its timing is excluded from any analysis (e.g. [AdaptiveThrottle]).
+func isWarmup(ctx context.Context) bool {
+	v, _ := ctx.Value(warmupKey{}).(bool)
+	return v
+}
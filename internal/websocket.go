@@ -0,0 +1,36 @@
+package scan
+
+import (
+	"github.com/bountysecurity/gbounty/internal/match"
+	"github.com/bountysecurity/gbounty/internal/profile"
+	"github.com/bountysecurity/gbounty/internal/response"
+	"github.com/bountysecurity/gbounty/kit/slices"
+)
+
+// MetadataWebSocketProtocol is the [Match.Metadata] key that identifies the WebSocket
+// subprotocol negotiated (i.e. the Sec-WebSocket-Protocol header), only set when the
+// [profile.Profile] looks for WebSocket upgrades (see [profile.GrepTypeWebSocketUpgrade]),
+// and the server negotiates any.
+const MetadataWebSocketProtocol = "websocket_protocol"
+
+// WebSocketProtocols returns the WebSocket subprotocols negotiated by the given (upgraded)
+// responses (see [match.WebSocketProtocol]), if the given [profile.Profile] looks for
+// WebSocket upgrades. So, these can be reported along with the match (see [MetadataWebSocketProtocol]).
+func WebSocketProtocols(prof profile.Profile, res []*response.Response) []string {
+	if prof == nil || len(profile.GrepsOfType(prof, profile.GrepTypeWebSocketUpgrade)) == 0 {
+		return nil
+	}
+
+	var protocols []string
+	for _, r := range res {
+		if !match.WebSocketUpgraded(r) {
+			continue
+		}
+
+		if protocol := match.WebSocketProtocol(r); len(protocol) > 0 && !slices.In(protocols, protocol) {
+			protocols = append(protocols, protocol)
+		}
+	}
+
+	return protocols
+}
@@ -0,0 +1,199 @@
+// Package arith provides a tiny integer arithmetic evaluator, used to compute
+// the value an (injected) expression is expected to produce once evaluated
+// by the target, like 49 for {{7*7}}.
+package arith
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidExpression is returned when the expression cannot be parsed.
+	ErrInvalidExpression = errors.New("invalid arithmetic expression")
+	// ErrDivisionByZero is returned when the expression divides by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrInexactDivision is returned when the expression divides two integers
+	// with a non-zero remainder, as its result depends on the target's language.
+	ErrInexactDivision = errors.New("inexact division")
+)
+
+// Find looks for the first arithmetic expression within the given string, like
+// 7*7 within {{7*7}}, and returns it. An expression is a sequence of integers,
+// parentheses and operators (+, -, *, / and %), with at least one operator.
+//
+// The second return value reports whether any valid expression was found.
+func Find(s string) (string, bool) {
+	for start := 0; start < len(s); start++ {
+		if !isDigit(s[start]) && s[start] != '(' && s[start] != '-' {
+			continue
+		}
+
+		end := start
+		for end < len(s) && isExprChar(s[end]) {
+			end++
+		}
+
+		// We shrink the candidate from the right, until it is a valid
+		// expression, to skip trailing characters like unbalanced ')'.
+		for stop := end; stop > start; stop-- {
+			candidate := strings.TrimSpace(s[start:stop])
+			if !strings.ContainsAny(candidate[1:], "+-*/%") {
+				break
+			}
+
+			if _, err := Eval(candidate); err == nil {
+				return candidate, true
+			}
+		}
+
+		start = end
+	}
+
+	return "", false
+}
+
+// Eval evaluates the given integer arithmetic expression, honoring the usual
+// precedence rules and parentheses, and returns its result.
+func Eval(expr string) (int64, error) {
+	p := &parser{s: expr}
+
+	v, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.skipSpaces(); p.pos != len(p.s) {
+		return 0, fmt.Errorf("%w: unexpected '%c' at %d", ErrInvalidExpression, p.s[p.pos], p.pos)
+	}
+
+	return v, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+// parseSum parses: product (('+' | '-') product)*
+func (p *parser) parseSum() (int64, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.s) || (p.s[p.pos] != '+' && p.s[p.pos] != '-') {
+			return left, nil
+		}
+
+		op := p.s[p.pos]
+		p.pos++
+
+		right, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+// parseProduct parses: unary (('*' | '/' | '%') unary)*
+func (p *parser) parseProduct() (int64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.s) || !strings.ContainsRune("*/%", rune(p.s[p.pos])) {
+			return left, nil
+		}
+
+		op := p.s[p.pos]
+		p.pos++
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case op == '*':
+			left *= right
+		case right == 0:
+			return 0, ErrDivisionByZero
+		case op == '%':
+			left %= right
+		case left%right != 0:
+			return 0, fmt.Errorf("%w: %d/%d", ErrInexactDivision, left, right)
+		default:
+			left /= right
+		}
+	}
+}
+
+// parseUnary parses: '-' unary | '(' sum ')' | integer
+func (p *parser) parseUnary() (int64, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.s) {
+		return 0, fmt.Errorf("%w: unexpected end", ErrInvalidExpression)
+	}
+
+	switch c := p.s[p.pos]; {
+	case c == '-':
+		p.pos++
+		v, err := p.parseUnary()
+		return -v, err
+	case c == '(':
+		p.pos++
+		v, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+
+		if p.skipSpaces(); p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return 0, fmt.Errorf("%w: missing ')'", ErrInvalidExpression)
+		}
+		p.pos++
+
+		return v, nil
+	case isDigit(c):
+		start := p.pos
+		for p.pos < len(p.s) && isDigit(p.s[p.pos]) {
+			p.pos++
+		}
+
+		v, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidExpression, err.Error())
+		}
+
+		return v, nil
+	default:
+		return 0, fmt.Errorf("%w: unexpected '%c' at %d", ErrInvalidExpression, c, p.pos)
+	}
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isExprChar(c byte) bool {
+	return isDigit(c) || strings.IndexByte("+-*/%() ", c) >= 0
+}
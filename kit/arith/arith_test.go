@@ -0,0 +1,77 @@
+package arith_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/arith"
+)
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		in    string
+		expr  string
+		found bool
+	}{
+		"jinja-like":           {in: "{{7*7}}", expr: "7*7", found: true},
+		"expression language":  {in: "${1337*3}", expr: "1337*3", found: true},
+		"with parentheses":     {in: "<%= (2+3)*4 %>", expr: "(2+3)*4", found: true},
+		"unbalanced":           {in: "#{7*7})", expr: "7*7", found: true},
+		"no operator":          {in: "{{1337}}", found: false},
+		"no expression at all": {in: "<script>alert(1)</script>", found: false},
+		"empty":                {in: "", found: false},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, found := arith.Find(tc.in)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expr, expr)
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]int64{
+		"7*7":         49,
+		"1+2*3":       7,
+		"(1+2)*3":     9,
+		"10-4-3":      3,
+		"-3*-3":       9,
+		"17 % 5":      2,
+		"144/12":      12,
+		"2*(3+(4-1))": 12,
+	}
+
+	for expr, expected := range tcs {
+		expr, expected := expr, expected
+		t.Run(expr, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := arith.Eval(expr)
+			require.NoError(t, err)
+			assert.Equal(t, expected, v)
+		})
+	}
+
+	_, err := arith.Eval("1/0")
+	require.ErrorIs(t, err, arith.ErrDivisionByZero)
+
+	_, err = arith.Eval("7/2")
+	require.ErrorIs(t, err, arith.ErrInexactDivision)
+
+	_, err = arith.Eval("7*")
+	require.ErrorIs(t, err, arith.ErrInvalidExpression)
+
+	_, err = arith.Eval("(7*7")
+	require.ErrorIs(t, err, arith.ErrInvalidExpression)
+}
@@ -0,0 +1,161 @@
+package dotenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidLine is returned when a line of a dotenv file cannot be parsed.
+// It is always wrapped with the line number.
+var ErrInvalidLine = errors.New("invalid dotenv line")
+
+// Parse reads a dotenv document from the given [io.Reader], and returns
+// the variables defined on it, as pairs of (key, value).
+//
+// Supported syntax:
+//   - KEY=VALUE pairs, one per line, with optional `export ` prefix.
+//   - Empty lines and lines starting with '#' (comments) are ignored.
+//   - Unquoted values are trimmed, and anything after ` #` is considered a comment.
+//   - Single-quoted values are taken literally.
+//   - Double-quoted values support the \n, \r, \t, \" and \\ escape sequences.
+func Parse(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+
+	var lineNo int
+	for scanner.Scan() {
+		lineNo++
+
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w (line %d): %s", ErrInvalidLine, lineNo, err.Error())
+		}
+
+		vars[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// ParseFile is like [Parse], but reading the dotenv document from the file at the given path.
+func ParseFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+var keyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func parseLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+
+	key, raw, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", errors.New("missing '=' separator") //nolint:err113
+	}
+
+	key = strings.TrimSpace(key)
+	if !keyRegex.MatchString(key) {
+		return "", "", fmt.Errorf("invalid key: %q", key) //nolint:err113
+	}
+
+	value, err := parseValue(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, value, nil
+}
+
+func parseValue(raw string) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	switch quote := raw[0]; quote {
+	case '\'', '"':
+		end := closingQuote(raw, quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value: %s", raw) //nolint:err113
+		}
+
+		if rest := strings.TrimSpace(raw[end+1:]); len(rest) > 0 && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected characters after quoted value: %s", rest) //nolint:err113
+		}
+
+		value := raw[1:end]
+		if quote == '"' {
+			value = unescape(value)
+		}
+
+		return value, nil
+	default:
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+func closingQuote(raw string, quote byte) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(
+		`\n`, "\n",
+		`\r`, "\r",
+		`\t`, "\t",
+		`\"`, `"`,
+		`\\`, `\`,
+	).Replace(s)
+}
+
+var varRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// Expand replaces the ${VAR} references in the given string with the
+// corresponding values from vars. References to undefined variables
+// are left untouched.
+func Expand(s string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(s, "${") {
+		return s
+	}
+
+	return varRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := vars[ref[2:len(ref)-1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
@@ -0,0 +1,72 @@
+package dotenv_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	const doc = `
+# Comment line
+TOKEN=abc123
+export HOST = example.com # inline comment
+EMPTY=
+SINGLE='literal \n # not a comment'
+DOUBLE="line1\nline2 \"quoted\""
+HASH=value#nospace
+`
+
+	vars, err := dotenv.Parse(strings.NewReader(doc))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"TOKEN":  "abc123",
+		"HOST":   "example.com",
+		"EMPTY":  "",
+		"SINGLE": `literal \n # not a comment`,
+		"DOUBLE": "line1\nline2 \"quoted\"",
+		"HASH":   "value#nospace",
+	}, vars)
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		doc  string
+		line string
+	}{
+		"missing separator": {doc: "A=1\nINVALID\n", line: "line 2"},
+		"invalid key":       {doc: "1A=1", line: "line 1"},
+		"unterminated":      {doc: "\n\nA=\"open", line: "line 3"},
+		"trailing garbage":  {doc: "A='x' y", line: "line 1"},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := dotenv.Parse(strings.NewReader(tc.doc))
+			require.ErrorIs(t, err, dotenv.ErrInvalidLine)
+			assert.Contains(t, err.Error(), tc.line)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{"HOST": "example.com", "TOKEN": "abc"}
+
+	assert.Equal(t, "https://example.com/?t=abc", dotenv.Expand("https://${HOST}/?t=${TOKEN}", vars))
+	assert.Equal(t, "${UNDEFINED} stays", dotenv.Expand("${UNDEFINED} stays", vars))
+	assert.Equal(t, "$HOST is not expanded", dotenv.Expand("$HOST is not expanded", vars))
+}
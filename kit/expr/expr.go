@@ -0,0 +1,380 @@
+// Package expr provides a small boolean expression evaluator over a set of (string)
+// variables, referenced as {{name}}, like: {{logged_in}} == false && {{role}} != admin.
+//
+// Supported syntax:
+//   - Comparisons: ==, !=, <, <=, >, >= (numeric, when both sides are numbers),
+//     plus =~ (or matches) and !~ (regular expression match, with the pattern on the right side).
+//   - Logical operators: && and ||, negation (!) and parentheses, with the usual precedence.
+//   - Operands: variables ({{name}}), quoted strings ('...' or "...") and bare words (e.g. false or 200).
+//   - A single operand is true, unless it is empty, false or zero (e.g. {{logged_in}}).
+//
+// Booleans are compared in a case-insensitive manner (e.g. "False" == false), as well as numbers
+// by their value (e.g. "200.0" == 200). Otherwise, operands are compared as strings.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax is returned by [Parse] when the given expression is malformed.
+	// It is always wrapped with the details.
+	ErrSyntax = errors.New("invalid expression")
+
+	// ErrUndefined is returned by [Expr.Eval] when the expression references a variable
+	// that isn't defined. It is always wrapped with the variable name, so callers can
+	// decide how to treat the undefined variables.
+	ErrUndefined = errors.New("undefined variable")
+)
+
+// Expr is a parsed boolean expression, ready to be evaluated (see [Expr.Eval]).
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses the given expression, see the package's docs for the supported syntax.
+func Parse(s string) (*Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, p.peek().value)
+	}
+
+	return &Expr{src: s, root: root}, nil
+}
+
+// Eval evaluates the expression with the given variables. Logical operators short-circuit,
+// so undefined variables (see [ErrUndefined]) are only reported when they're evaluated.
+func (e *Expr) Eval(vars map[string]string) (bool, error) {
+	return e.root.eval(vars)
+}
+
+// Vars returns the names of the variables referenced by the expression, in order.
+func (e *Expr) Vars() []string {
+	var names []string
+	seen := make(map[string]struct{})
+
+	e.root.walk(func(o operand) {
+		if !o.isVar {
+			return
+		}
+		if _, ok := seen[o.value]; !ok {
+			seen[o.value] = struct{}{}
+			names = append(names, o.value)
+		}
+	})
+
+	return names
+}
+
+// String returns the expression, as given.
+func (e *Expr) String() string {
+	return e.src
+}
+
+type node interface {
+	eval(vars map[string]string) (bool, error)
+	walk(fn func(operand))
+}
+
+type (
+	orNode    struct{ left, right node }
+	andNode   struct{ left, right node }
+	notNode   struct{ x node }
+	truthNode struct{ x operand }
+	cmpNode   struct {
+		op          string
+		left, right operand
+		re          *regexp.Regexp // Pre-compiled, when the pattern is a literal.
+	}
+)
+
+func (n orNode) eval(vars map[string]string) (bool, error) {
+	ok, err := n.left.eval(vars)
+	if err != nil || ok {
+		return ok, err
+	}
+	return n.right.eval(vars)
+}
+
+func (n orNode) walk(fn func(operand)) { n.left.walk(fn); n.right.walk(fn) }
+
+func (n andNode) eval(vars map[string]string) (bool, error) {
+	ok, err := n.left.eval(vars)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return n.right.eval(vars)
+}
+
+func (n andNode) walk(fn func(operand)) { n.left.walk(fn); n.right.walk(fn) }
+
+func (n notNode) eval(vars map[string]string) (bool, error) {
+	ok, err := n.x.eval(vars)
+	return !ok, err
+}
+
+func (n notNode) walk(fn func(operand)) { n.x.walk(fn) }
+
+func (n truthNode) eval(vars map[string]string) (bool, error) {
+	v, err := n.x.resolve(vars)
+	if err != nil {
+		return false, err
+	}
+
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b, nil
+	}
+
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f != 0, nil
+	}
+
+	return len(v) > 0, nil
+}
+
+func (n truthNode) walk(fn func(operand)) { fn(n.x) }
+
+func (n cmpNode) eval(vars map[string]string) (bool, error) {
+	l, err := n.left.resolve(vars)
+	if err != nil {
+		return false, err
+	}
+
+	r, err := n.right.resolve(vars)
+	if err != nil {
+		return false, err
+	}
+
+	switch n.op {
+	case "=~", "!~":
+		re := n.re
+		if re == nil {
+			if re, err = regexp.Compile(r); err != nil {
+				return false, fmt.Errorf("%w: invalid regex %q: %s", ErrSyntax, r, err.Error())
+			}
+		}
+		return re.MatchString(l) == (n.op == "=~"), nil
+	}
+
+	c := compare(l, r)
+
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default: // >=
+		return c >= 0, nil
+	}
+}
+
+func (n cmpNode) walk(fn func(operand)) { fn(n.left); fn(n.right) }
+
+// compare compares the given values, either as numbers, as booleans or as strings,
+// and returns an integer comparing them, like [strings.Compare].
+func compare(l, r string) int {
+	lf, lErr := strconv.ParseFloat(l, 64)
+	rf, rErr := strconv.ParseFloat(r, 64)
+	if lErr == nil && rErr == nil {
+		switch {
+		case lf < rf:
+			return -1
+		case lf > rf:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	lb, lErr := strconv.ParseBool(l)
+	rb, rErr := strconv.ParseBool(r)
+	if lErr == nil && rErr == nil && lb == rb {
+		return 0
+	}
+
+	return strings.Compare(l, r)
+}
+
+type operand struct {
+	value string
+	isVar bool
+}
+
+func (o operand) resolve(vars map[string]string) (string, error) {
+	if !o.isVar {
+		return o.value, nil
+	}
+
+	v, ok := vars[o.value]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUndefined, o.value)
+	}
+
+	return v, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) accept(kind tokenKind, values ...string) (token, bool) {
+	tok := p.peek()
+	if p.done() || tok.kind != kind {
+		return tok, false
+	}
+
+	for _, v := range values {
+		if tok.value == v {
+			p.pos++
+			return tok, true
+		}
+	}
+
+	if len(values) == 0 {
+		p.pos++
+		return tok, true
+	}
+
+	return tok, false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept(tokOperator, "||"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept(tokOperator, "&&"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.accept(tokOperator, "!"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+
+	if _, ok := p.accept(tokOperator, "("); ok {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := p.accept(tokOperator, ")"); !ok {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrSyntax)
+		}
+
+		return x, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept(tokOperator, "==", "!=", "<", "<=", ">", ">=", "=~", "!~")
+	if !ok {
+		return truthNode{x: left}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	n := cmpNode{op: op.value, left: left, right: right}
+	if (n.op == "=~" || n.op == "!~") && !right.isVar {
+		if n.re, err = regexp.Compile(right.value); err != nil {
+			return nil, fmt.Errorf("%w: invalid regex %q: %s", ErrSyntax, right.value, err.Error())
+		}
+	}
+
+	return n, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	if tok, ok := p.accept(tokVariable); ok {
+		return operand{value: tok.value, isVar: true}, nil
+	}
+
+	if tok, ok := p.accept(tokLiteral); ok {
+		return operand{value: tok.value}, nil
+	}
+
+	if p.done() {
+		return operand{}, fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	}
+
+	return operand{}, fmt.Errorf("%w: unexpected %q", ErrSyntax, p.peek().value)
+}
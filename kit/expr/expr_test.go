@@ -0,0 +1,102 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/expr"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{
+		"logged_in":    "False",
+		"role":         "admin user",
+		"status":       "200",
+		"empty":        "",
+		"request.path": "/admin/users",
+	}
+
+	tcs := map[string]bool{
+		"{{logged_in}} == false":                         true,
+		"{{logged_in}}":                                  false,
+		"!{{logged_in}}":                                 true,
+		"{{empty}}":                                      false,
+		"{{role}} == 'admin user'":                       true,
+		`{{role}} != "admin user"`:                       false,
+		"{{status}} == 200.0":                            true,
+		"{{status}} >= 400":                              false,
+		"{{status}} < 300 && {{status}} > 199":           true,
+		"{{request.path}} =~ ^/admin":                    true,
+		"{{request.path}} !~ '^/admin'":                  false,
+		"{{logged_in}} || {{role}} =~ admin":             true,
+		"{{request.path}} matches '.*/users$'":           true,
+		"{{role}} == 'matches'":                          false,
+		"!({{logged_in}} == false && {{status}} == 200)": false,
+		// Short-circuit: the undefined variable is never evaluated.
+		"{{logged_in}} == false || {{missing}}": true,
+		"{{logged_in}} == true && {{missing}}":  false,
+	}
+
+	for src, want := range tcs {
+		src, want := src, want
+
+		t.Run(src, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := expr.Parse(src)
+			require.NoError(t, err)
+
+			got, err := e.Eval(vars)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestExpr_Eval_Undefined(t *testing.T) {
+	t.Parallel()
+
+	e, err := expr.Parse("{{logged_in}} == false")
+	require.NoError(t, err)
+
+	_, err = e.Eval(map[string]string{})
+	require.ErrorIs(t, err, expr.ErrUndefined)
+	assert.Contains(t, err.Error(), "logged_in")
+}
+
+func TestExpr_Vars(t *testing.T) {
+	t.Parallel()
+
+	e, err := expr.Parse("{{a}} == 1 && ({{b}} || {{ a }} != {{c}})")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, e.Vars())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range []string{
+		"",
+		"{{logged_in",
+		"{{logged in}} == 1",
+		"{{a}} ==",
+		"({{a}} == 1",
+		"{{a}} == 1 )",
+		"{{a}} == 'unclosed",
+		"{{a}} =~ '('",
+		"{{a}} && || {{b}}",
+	} {
+		src := src
+
+		t.Run(src, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := expr.Parse(src)
+			require.ErrorIs(t, err, expr.ErrSyntax)
+		})
+	}
+}
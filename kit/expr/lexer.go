@@ -0,0 +1,132 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokOperator tokenKind = iota + 1
+	tokVariable
+	tokLiteral
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// operators are sorted so the longest ones are matched first (e.g. <= before <).
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+// varNameRegex is the format of the variable names (e.g. logged_in or request.host).
+var varNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// matchesKeyword is the bare word alias of the =~ operator.
+const matchesKeyword = "matches"
+
+// wordDelimiters are the characters that end a bare word.
+const wordDelimiters = "&|=!<>()'\"~"
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case unicode.IsSpace(rune(s[i])):
+			i++
+
+		case strings.HasPrefix(rest, "{{"):
+			end := strings.Index(rest, "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed variable at %d", ErrSyntax, i)
+			}
+
+			name := strings.TrimSpace(rest[len("{{"):end])
+			if !varNameRegex.MatchString(name) {
+				return nil, fmt.Errorf("%w: invalid variable name %q", ErrSyntax, name)
+			}
+
+			tokens = append(tokens, token{kind: tokVariable, value: name})
+			i += end + len("}}")
+
+		case s[i] == '\'' || s[i] == '"':
+			value, n, err := quoted(rest)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokLiteral, value: value})
+			i += n
+
+		default:
+			if op := operator(rest); len(op) > 0 {
+				tokens = append(tokens, token{kind: tokOperator, value: op})
+				i += len(op)
+				continue
+			}
+
+			n := strings.IndexFunc(rest, func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(wordDelimiters, r)
+			})
+			if n < 0 {
+				n = len(rest)
+			}
+
+			if n == 0 || strings.HasPrefix(rest[:n], "{{") {
+				return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, rest[:1], i)
+			}
+
+			// The "matches" keyword is an alias of the =~ operator, so it
+			// must be quoted to be used as a (bare word) literal.
+			if word := rest[:n]; word == matchesKeyword {
+				tokens = append(tokens, token{kind: tokOperator, value: "=~"})
+			} else {
+				tokens = append(tokens, token{kind: tokLiteral, value: word})
+			}
+			i += n
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrSyntax)
+	}
+
+	return tokens, nil
+}
+
+func operator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// quoted reads the quoted string at the beginning of s, and returns its value
+// (with the \ escape sequences resolved), and the amount of bytes read.
+func quoted(s string) (string, int, error) {
+	quote := s[0]
+
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case c == quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w: unclosed quoted string", ErrSyntax)
+}
@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/jsonmap"
+)
+
+// ErrInvalidToken is returned when the given string
+// cannot be parsed as a JSON Web Token (JWT).
+var ErrInvalidToken = errors.New("invalid jwt")
+
+const numOfSegments = 3
+
+// Token represents a decoded JSON Web Token (JWT), in its
+// compact serialization form (i.e. header.payload.signature).
+//
+// Both, the header and the payload, are kept as [jsonmap.Ordered],
+// so the order of the claims is preserved when the token is re-encoded.
+type Token struct {
+	Header    jsonmap.Ordered
+	Payload   jsonmap.Ordered
+	Signature string
+
+	raw string
+}
+
+// LooksLike returns whether the given string looks like a JWT,
+// without fully decoding it. So, it is a cheap check that can be
+// used as a pre-filter, before calling [Parse].
+func LooksLike(s string) bool {
+	segments := strings.Split(s, ".")
+	if len(segments) != numOfSegments {
+		return false
+	}
+
+	// All JWTs headers are JSON objects, whose base64 (url) encoding
+	// starts with "eyJ" (i.e. `{"`).
+	return strings.HasPrefix(segments[0], "eyJ") && len(segments[1]) > 0
+}
+
+// bearerScheme is the (case-insensitive) scheme of the Authorization
+// header values that carry bearer tokens (see RFC 6750, section 2.1).
+const bearerScheme = "bearer "
+
+// Bearer splits the given Authorization header value into the scheme prefix,
+// as written (e.g. "Bearer "), and the token, only if the latter looks like a JWT.
+func Bearer(val string) (prefix, token string, ok bool) {
+	trimmed := strings.TrimSpace(val)
+	if len(trimmed) <= len(bearerScheme) || !strings.EqualFold(trimmed[:len(bearerScheme)], bearerScheme) {
+		return "", "", false
+	}
+
+	token = strings.TrimSpace(trimmed[len(bearerScheme):])
+	if !LooksLike(token) {
+		return "", "", false
+	}
+
+	return trimmed[:len(trimmed)-len(token)], token, true
+}
+
+// Parse decodes the given string as a JWT.
+// The signature is not verified, see [Token.VerifyHMAC] for that.
+func Parse(s string) (Token, error) {
+	if !LooksLike(s) {
+		return Token{}, ErrInvalidToken
+	}
+
+	segments := strings.Split(s, ".")
+
+	header, err := decodeSegment(segments[0])
+	if err != nil {
+		return Token{}, errors.Join(ErrInvalidToken, err)
+	}
+
+	payload, err := decodeSegment(segments[1])
+	if err != nil {
+		return Token{}, errors.Join(ErrInvalidToken, err)
+	}
+
+	return Token{
+		Header:    header,
+		Payload:   payload,
+		Signature: segments[2],
+		raw:       s,
+	}, nil
+}
+
+// String returns the original (raw) representation of the token.
+func (t Token) String() string {
+	return t.raw
+}
+
+// Alg returns the value of the "alg" header claim, if any.
+func (t Token) Alg() string {
+	alg, _ := t.Header.Data["alg"].(string)
+	return alg
+}
+
+// Unsigned returns whether the token is not signed, either because
+// the "alg" header claim is "none" (in any casing), or because the
+// signature segment is empty.
+func (t Token) Unsigned() bool {
+	return strings.EqualFold(t.Alg(), "none") || t.Signature == ""
+}
+
+// Clone returns a deep copy of the token's claims,
+// so they can be modified without side effects.
+func (t Token) Clone() Token {
+	return Token{
+		Header:    cloneOrdered(t.Header),
+		Payload:   cloneOrdered(t.Payload),
+		Signature: t.Signature,
+		raw:       t.raw,
+	}
+}
+
+// Encode re-encodes the token, with its current claims.
+//
+// If the "alg" header claim is "none", the signature is dropped.
+// Otherwise, if a non-empty key is given and the algorithm is one of the
+// supported HMAC-based ones (HS256, HS384, HS512), the token is re-signed
+// with it. In any other case, the original signature is kept.
+func (t Token) Encode(key string) (string, error) {
+	header, err := encodeSegment(t.Header)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := encodeSegment(t.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := header + "." + payload
+
+	if strings.EqualFold(t.Alg(), "none") {
+		return signingInput + ".", nil
+	}
+
+	if h := hmacHash(t.Alg()); h != nil && len(key) > 0 {
+		return signingInput + "." + sign(h, signingInput, key), nil
+	}
+
+	return signingInput + "." + t.Signature, nil
+}
+
+// VerifyHMAC returns whether the token's signature is valid for the given key.
+// It always returns false for non HMAC-based algorithms.
+func (t Token) VerifyHMAC(key string) bool {
+	h := hmacHash(t.Alg())
+	if h == nil {
+		return false
+	}
+
+	idx := strings.LastIndex(t.raw, ".")
+	if idx < 0 {
+		return false
+	}
+
+	expected := sign(h, t.raw[:idx], key)
+
+	return hmac.Equal([]byte(expected), []byte(t.Signature))
+}
+
+// WeakSecrets is a list of commonly used (weak) secrets
+// used to sign HMAC-based JWTs, see [Token.WeakSecret].
+//
+//nolint:gochecknoglobals
+var WeakSecrets = []string{
+	"", "secret", "secretkey", "secret_key", "secret-key", "password", "changeme",
+	"jwt", "jwt_secret", "jwtsecret", "key", "private", "test", "admin",
+	"123456", "12345678", "qwerty", "your-256-bit-secret", "your_jwt_secret",
+}
+
+// WeakSecret returns the first secret from [WeakSecrets] that produces
+// a valid signature for the token, if any.
+func (t Token) WeakSecret() (string, bool) {
+	for _, secret := range WeakSecrets {
+		if t.VerifyHMAC(secret) {
+			return secret, true
+		}
+	}
+
+	return "", false
+}
+
+func hmacHash(alg string) func() hash.Hash {
+	switch strings.ToUpper(alg) {
+	case "HS256":
+		return sha256.New
+	case "HS384":
+		return sha512.New384
+	case "HS512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+func sign(h func() hash.Hash, input, key string) string {
+	mac := hmac.New(h, []byte(key))
+	mac.Write([]byte(input))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeSegment(s string) (jsonmap.Ordered, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return jsonmap.Ordered{}, err
+	}
+
+	var o jsonmap.Ordered
+	if err := json.Unmarshal(b, &o); err != nil {
+		return jsonmap.Ordered{}, err
+	}
+
+	return o, nil
+}
+
+func encodeSegment(o jsonmap.Ordered) (string, error) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func cloneOrdered(o jsonmap.Ordered) jsonmap.Ordered {
+	cloned := jsonmap.Ordered{
+		Order: make([]string, len(o.Order)),
+		Data:  make(map[string]interface{}, len(o.Data)),
+	}
+
+	copy(cloned.Order, o.Order)
+
+	for k, v := range o.Data {
+		cloned.Data[k] = v
+	}
+
+	return cloned
+}
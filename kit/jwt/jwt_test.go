@@ -0,0 +1,108 @@
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/jwt"
+)
+
+// Signed (HS256) with "secret".
+const token = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"XbPfbIHMI6arZ3Y922BhjWgQzWXcXNrz0ogtVhfEd2o"
+
+func TestLooksLike(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, jwt.LooksLike(token))
+	assert.False(t, jwt.LooksLike("abc.def.ghi"))
+	assert.False(t, jwt.LooksLike("eyJhbGciOiJIUzI1NiJ9"))
+	assert.False(t, jwt.LooksLike(""))
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tok, err := jwt.Parse(token)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HS256", tok.Alg())
+	assert.Equal(t, []string{"alg", "typ"}, tok.Header.Order)
+	assert.Equal(t, []string{"sub", "name", "iat"}, tok.Payload.Order)
+	assert.Equal(t, "John Doe", tok.Payload.Data["name"])
+	assert.False(t, tok.Unsigned())
+
+	_, err = jwt.Parse("eyJ.!!.x")
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
+
+func TestToken_Encode(t *testing.T) {
+	t.Parallel()
+
+	tok, err := jwt.Parse(token)
+	require.NoError(t, err)
+
+	t.Run("unmodified keeps signature", func(t *testing.T) {
+		t.Parallel()
+
+		encoded, err := tok.Encode("")
+		require.NoError(t, err)
+		assert.Equal(t, token, encoded)
+	})
+
+	t.Run("re-signed with key", func(t *testing.T) {
+		t.Parallel()
+
+		encoded, err := tok.Encode("secret")
+		require.NoError(t, err)
+		assert.Equal(t, token, encoded)
+	})
+
+	t.Run("alg none drops signature", func(t *testing.T) {
+		t.Parallel()
+
+		cloned := tok.Clone()
+		cloned.Header.Data["alg"] = "none"
+
+		encoded, err := cloned.Encode("secret")
+		require.NoError(t, err)
+		assert.Equal(t,
+			"eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0."+
+				"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ.",
+			encoded,
+		)
+		assert.Equal(t, "HS256", tok.Alg())
+	})
+}
+
+func TestToken_WeakSecret(t *testing.T) {
+	t.Parallel()
+
+	tok, err := jwt.Parse(token)
+	require.NoError(t, err)
+
+	secret, ok := tok.WeakSecret()
+	assert.True(t, ok)
+	assert.Equal(t, "secret", secret)
+
+	assert.True(t, tok.VerifyHMAC("secret"))
+	assert.False(t, tok.VerifyHMAC("not-the-secret"))
+}
+
+func TestBearer(t *testing.T) {
+	t.Parallel()
+
+	prefix, tok, ok := jwt.Bearer("  bearer  " + token + " ")
+	require.True(t, ok)
+	assert.Equal(t, "bearer  ", prefix)
+	assert.Equal(t, token, tok)
+
+	_, _, ok = jwt.Bearer("Basic " + token)
+	assert.False(t, ok)
+
+	_, _, ok = jwt.Bearer("Bearer opaque-token")
+	assert.False(t, ok)
+}
@@ -0,0 +1,263 @@
+package ntlm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4" //nolint:staticcheck
+)
+
+var (
+	// ErrInvalidCredentials is returned when the given string
+	// cannot be parsed as NTLM credentials (see [ParseCredentials]).
+	ErrInvalidCredentials = errors.New("invalid ntlm credentials")
+	// ErrInvalidChallenge is returned when the given message
+	// cannot be parsed as an NTLM challenge (type 2) message.
+	ErrInvalidChallenge = errors.New("invalid ntlm challenge")
+)
+
+const (
+	negotiateMessage    uint32 = 1
+	challengeMessage    uint32 = 2
+	authenticateMessage uint32 = 3
+)
+
+const (
+	flagUnicode                 uint32 = 0x00000001
+	flagOEM                     uint32 = 0x00000002
+	flagRequestTarget           uint32 = 0x00000004
+	flagNTLM                    uint32 = 0x00000200
+	flagAlwaysSign              uint32 = 0x00008000
+	flagExtendedSessionSecurity uint32 = 0x00080000
+	flagTargetInfo              uint32 = 0x00800000
+	flag128                     uint32 = 0x20000000
+	flag56                      uint32 = 0x80000000
+
+	negotiateFlags = flagUnicode | flagOEM | flagRequestTarget | flagNTLM |
+		flagAlwaysSign | flagExtendedSessionSecurity | flag128 | flag56
+)
+
+const (
+	// avTimestamp is the identifier of the MsvAvTimestamp pair,
+	// within the target info of the challenge message.
+	avTimestamp uint16 = 7
+	// avEOL is the identifier of the MsvAvEOL pair,
+	// that ends the list of pairs within the target info.
+	avEOL uint16 = 0
+
+	negotiateLen    = 32
+	challengeMinLen = 32
+	targetInfoLen   = 48
+)
+
+var signature = []byte("NTLMSSP\x00")
+
+// Credentials are the details used to authenticate through NTLM.
+type Credentials struct {
+	Domain   string
+	User     string
+	Password string
+}
+
+// ParseCredentials parses the given string as NTLM credentials,
+// in the form of domain\user:password, where the domain is optional.
+func ParseCredentials(s string) (Credentials, error) {
+	account, password, ok := strings.Cut(s, ":")
+	if !ok || len(account) == 0 {
+		return Credentials{}, ErrInvalidCredentials
+	}
+
+	var domain string
+	if d, user, found := strings.Cut(account, `\`); found {
+		domain, account = d, user
+	}
+
+	if len(account) == 0 {
+		return Credentials{}, ErrInvalidCredentials
+	}
+
+	return Credentials{Domain: domain, User: account, Password: password}, nil
+}
+
+// Challenge is the information relevant for the authentication,
+// sent by the server within the challenge (type 2) message.
+type Challenge struct {
+	Flags           uint32
+	ServerChallenge [8]byte
+	TargetInfo      []byte
+}
+
+// Negotiate returns the negotiate (type 1) message,
+// that initiates the NTLM handshake.
+func Negotiate() []byte {
+	msg := make([]byte, negotiateLen)
+	copy(msg, signature)
+	binary.LittleEndian.PutUint32(msg[8:], negotiateMessage)
+	binary.LittleEndian.PutUint32(msg[12:], negotiateFlags)
+
+	// Both, the domain and the workstation (security buffers),
+	// are empty, so these point to the end of the message.
+	binary.LittleEndian.PutUint32(msg[20:], negotiateLen)
+	binary.LittleEndian.PutUint32(msg[28:], negotiateLen)
+
+	return msg
+}
+
+// ParseChallenge decodes the given bytes as a challenge (type 2) message.
+func ParseChallenge(msg []byte) (Challenge, error) {
+	if len(msg) < challengeMinLen || !bytes.Equal(msg[:8], signature) ||
+		binary.LittleEndian.Uint32(msg[8:]) != challengeMessage {
+		return Challenge{}, ErrInvalidChallenge
+	}
+
+	ch := Challenge{Flags: binary.LittleEndian.Uint32(msg[20:])}
+	copy(ch.ServerChallenge[:], msg[24:32])
+
+	if ch.Flags&flagTargetInfo != 0 && len(msg) >= targetInfoLen {
+		l := int(binary.LittleEndian.Uint16(msg[40:]))
+		offset := int(binary.LittleEndian.Uint32(msg[44:]))
+		if offset+l > len(msg) {
+			return Challenge{}, ErrInvalidChallenge
+		}
+
+		ch.TargetInfo = msg[offset : offset+l]
+	}
+
+	return ch, nil
+}
+
+// Authenticate returns the authenticate (type 3) message, in response to
+// the given challenge, with the given credentials, using NTLMv2.
+func Authenticate(ch Challenge, creds Credentials) ([]byte, error) {
+	var clientChallenge [8]byte
+	if _, err := rand.Read(clientChallenge[:]); err != nil {
+		return nil, err
+	}
+
+	return authenticate(ch, creds, clientChallenge, time.Now()), nil
+}
+
+func authenticate(ch Challenge, creds Credentials, clientChallenge [8]byte, now time.Time) []byte {
+	key := responseKey(creds)
+
+	// If the server sent a timestamp, it must be used instead of the
+	// client's one, and then the LMv2 response must be omitted (zeroed).
+	timestamp, fromServer := serverTimestamp(ch.TargetInfo)
+	if !fromServer {
+		timestamp = fileTime(now)
+	}
+
+	blob := make([]byte, 0, 28+len(ch.TargetInfo)+4) //nolint:mnd
+	blob = append(blob, 0x01, 0x01, 0, 0, 0, 0, 0, 0)
+	blob = append(blob, timestamp[:]...)
+	blob = append(blob, clientChallenge[:]...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, ch.TargetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+
+	ntResponse := append(hmacMD5(key, ch.ServerChallenge[:], blob), blob...)
+
+	lmResponse := make([]byte, 24) //nolint:mnd
+	if !fromServer {
+		lmResponse = append(hmacMD5(key, ch.ServerChallenge[:], clientChallenge[:]), clientChallenge[:]...)
+	}
+
+	domain, user, workstation := encode(creds.Domain), encode(creds.User), []byte{}
+
+	const headerLen = 64
+
+	msg := make([]byte, headerLen)
+	copy(msg, signature)
+	binary.LittleEndian.PutUint32(msg[8:], authenticateMessage)
+	binary.LittleEndian.PutUint32(msg[60:], ch.Flags&negotiateFlags|flagUnicode)
+
+	for _, field := range []struct {
+		at    int
+		value []byte
+	}{
+		{12, lmResponse},
+		{20, ntResponse},
+		{28, domain},
+		{36, user},
+		{44, workstation},
+		{52, nil}, // Encrypted random session key
+	} {
+		binary.LittleEndian.PutUint16(msg[field.at:], uint16(len(field.value)))
+		binary.LittleEndian.PutUint16(msg[field.at+2:], uint16(len(field.value)))
+		binary.LittleEndian.PutUint32(msg[field.at+4:], uint32(len(msg)))
+		msg = append(msg, field.value...)
+	}
+
+	return msg
+}
+
+// responseKey returns the NTLMv2 response key (i.e. ResponseKeyNT),
+// derived from the NT hash (i.e. the MD4 of the password).
+func responseKey(creds Credentials) []byte {
+	h := md4.New()
+	h.Write(encode(creds.Password))
+
+	return hmacMD5(h.Sum(nil), encode(strings.ToUpper(creds.User)+creds.Domain))
+}
+
+// serverTimestamp returns the MsvAvTimestamp pair from
+// the given target info (i.e. AV pairs), if any.
+func serverTimestamp(targetInfo []byte) ([8]byte, bool) {
+	var timestamp [8]byte
+
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		l := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == avEOL || 4+l > len(targetInfo) {
+			break
+		}
+
+		if id == avTimestamp && l == len(timestamp) {
+			copy(timestamp[:], targetInfo[4:])
+			return timestamp, true
+		}
+
+		targetInfo = targetInfo[4+l:]
+	}
+
+	return timestamp, false
+}
+
+// fileTime returns the given time as a Windows FILETIME, which is
+// the amount of 100-nanosecond intervals since January 1, 1601.
+func fileTime(t time.Time) [8]byte {
+	const epochDiff = 116444736000000000
+
+	var ft [8]byte
+	binary.LittleEndian.PutUint64(ft[:], uint64(t.UnixNano()/100+epochDiff)) //nolint:mnd
+
+	return ft
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	h := hmac.New(md5.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
+
+// encode returns the given string encoded as UTF-16 (little-endian).
+func encode(s string) []byte {
+	runes := utf16.Encode([]rune(s))
+
+	b := make([]byte, 2*len(runes)) //nolint:mnd
+	for i, r := range runes {
+		binary.LittleEndian.PutUint16(b[2*i:], r)
+	}
+
+	return b
+}
@@ -0,0 +1,162 @@
+package ntlm_test
+
+import (
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/ntlm"
+)
+
+func TestParseCredentials(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		input    string
+		expected ntlm.Credentials
+		err      error
+	}{
+		"with domain":       {input: `CORP\john:s3cr:et`, expected: ntlm.Credentials{Domain: "CORP", User: "john", Password: "s3cr:et"}},
+		"without domain":    {input: `john:secret`, expected: ntlm.Credentials{User: "john", Password: "secret"}},
+		"without password":  {input: `CORP\john`, err: ntlm.ErrInvalidCredentials},
+		"without user":      {input: `CORP\:secret`, err: ntlm.ErrInvalidCredentials},
+		"with empty string": {input: ``, err: ntlm.ErrInvalidCredentials},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			creds, err := ntlm.ParseCredentials(tc.input)
+			require.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, creds)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	msg := ntlm.Negotiate()
+	require.Len(t, msg, 32)
+	assert.Equal(t, "NTLMSSP\x00", string(msg[:8]))
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(msg[8:]))
+}
+
+func TestParseChallenge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		ch, err := ntlm.ParseChallenge(challenge(t))
+		require.NoError(t, err)
+		assert.Equal(t, [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}, ch.ServerChallenge)
+		assert.Equal(t, targetInfo(t), ch.TargetInfo)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ntlm.ParseChallenge(ntlm.Negotiate())
+		require.ErrorIs(t, err, ntlm.ErrInvalidChallenge)
+
+		_, err = ntlm.ParseChallenge([]byte("NTLMSSP\x00"))
+		require.ErrorIs(t, err, ntlm.ErrInvalidChallenge)
+	})
+}
+
+// TestAuthenticate verifies the NTLMv2 responses with the response key (ResponseKeyNT)
+// from the NTLMv2 authentication example of the specification ([MS-NLMP] 4.2.4).
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	ch, err := ntlm.ParseChallenge(challenge(t))
+	require.NoError(t, err)
+
+	msg, err := ntlm.Authenticate(ch, ntlm.Credentials{Domain: "Domain", User: "User", Password: "Password"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "NTLMSSP\x00", string(msg[:8]))
+	assert.Equal(t, uint32(3), binary.LittleEndian.Uint32(msg[8:]))
+
+	key := decodeHex(t, "0c868a403bfd7a93a3001ef22ef02e3f")
+
+	lm := field(msg, 12)
+	require.Len(t, lm, 24)
+	assert.Equal(t, hmacMD5(key, ch.ServerChallenge[:], lm[16:]), lm[:16])
+
+	nt := field(msg, 20)
+	blob := nt[16:]
+	assert.Equal(t, hmacMD5(key, ch.ServerChallenge[:], blob), nt[:16])
+	assert.Equal(t, lm[16:], blob[16:24], "the client challenge must be the same in both responses")
+	assert.Equal(t, targetInfo(t), blob[28:len(blob)-4])
+
+	assert.Equal(t, utf16le("Domain"), field(msg, 28))
+	assert.Equal(t, utf16le("User"), field(msg, 36))
+}
+
+func challenge(t *testing.T) []byte {
+	t.Helper()
+
+	info := targetInfo(t)
+
+	msg := make([]byte, 48)
+	copy(msg, "NTLMSSP\x00")
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], 0x00800000|0x00000001|0x00000200)
+	copy(msg[24:], []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef})
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(info)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(info)))
+	binary.LittleEndian.PutUint32(msg[44:], uint32(len(msg)))
+
+	return append(msg, info...)
+}
+
+// targetInfo returns the AV pairs from the specification example:
+// MsvAvNbDomainName (Domain), MsvAvNbComputerName (Server) and MsvAvEOL.
+func targetInfo(t *testing.T) []byte {
+	t.Helper()
+
+	return decodeHex(t, "02000c0044006f006d00610069006e00"+"01000c00530065007200760065007200"+"00000000")
+}
+
+func field(msg []byte, at int) []byte {
+	l := binary.LittleEndian.Uint16(msg[at:])
+	offset := binary.LittleEndian.Uint32(msg[at+4:])
+
+	return msg[offset : offset+uint32(l)]
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	h := hmac.New(md5.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
+
+func utf16le(s string) []byte {
+	b := make([]byte, 0, 2*len(s))
+	for _, r := range s {
+		b = append(b, byte(r), 0)
+	}
+
+	return b
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
@@ -0,0 +1,37 @@
+package osext
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomically writes the file at the given path with the given write function,
+// through a temporary file next to it (so, in the same file system) that is renamed once
+// written, so the file is never left partially written, neither seen as such by readers.
+//
+// If anything fails, the temporary file is removed, and the file at the given path (if any)
+// is left untouched. Otherwise, the file is replaced, with the given permissions.
+func WriteFileAtomically(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,62 @@
+package osext_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/osext"
+)
+
+func TestWriteFileAtomically(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.txt")
+
+	write := func(contents string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, contents)
+			return err
+		}
+	}
+
+	require.NoError(t, osext.WriteFileAtomically(path, 0o600, write("first")))
+	require.NoError(t, osext.WriteFileAtomically(path, 0o600, write("second")))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(b))
+
+	// If writing fails, the existing file is left untouched.
+	errWrite := errors.New("write failed")
+	err = osext.WriteFileAtomically(path, 0o600, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return errWrite
+	})
+	require.ErrorIs(t, err, errWrite)
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(b))
+
+	// Neither the temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "output.txt", entries[0].Name())
+}
+
+func TestWriteFileAtomically_MissingDir(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing", "output.txt")
+
+	err := osext.WriteFileAtomically(path, 0o600, func(io.Writer) error { return nil })
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,299 @@
+// Package sigv4 implements the AWS Signature Version 4 (SigV4) signing process, used to
+// authenticate requests against AWS services (e.g. API Gateway with IAM authorization).
+//
+// Unlike the official SDKs, requests are signed as these are sent (i.e. raw request target,
+// headers and body), so the signature covers the final bytes, even when those are malformed.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidScope is returned when the given string cannot
+	// be parsed as a region:service pair (see [ParseScope]).
+	ErrInvalidScope = errors.New("invalid sigv4 scope")
+	// ErrInvalidCredentials is returned when the given string cannot
+	// be parsed as AWS credentials (see [ParseCredentials]).
+	ErrInvalidCredentials = errors.New("invalid aws credentials")
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	terminator = "aws4_request"
+
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+
+	// HeaderAuthorization is the header that holds the signature.
+	HeaderAuthorization = "Authorization"
+	// HeaderDate is the header that holds the signing time.
+	HeaderDate = "X-Amz-Date"
+	// HeaderSecurityToken is the header that holds the session token, if any.
+	HeaderSecurityToken = "X-Amz-Security-Token"
+	// HeaderContentSHA256 is the header that holds the payload hash, only set for S3.
+	HeaderContentSHA256 = "X-Amz-Content-Sha256"
+
+	serviceS3 = "s3"
+)
+
+// ignoredHeaders are those never signed, as these are either the signature
+// itself, or likely to be altered by intermediaries (e.g. proxies).
+var ignoredHeaders = []string{"authorization", "user-agent", "x-amzn-trace-id", "expect"}
+
+// Credentials are the AWS credentials used to sign the requests.
+// The SessionToken is only required for temporary credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ParseCredentials parses the given string as AWS credentials, in
+// the form of access_key_id:secret_access_key[:session_token].
+func ParseCredentials(s string) (Credentials, error) {
+	const maxParts = 3
+
+	parts := strings.SplitN(s, ":", maxParts)
+	if len(parts) < 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Credentials{}, ErrInvalidCredentials
+	}
+
+	creds := Credentials{AccessKeyID: parts[0], SecretAccessKey: parts[1]}
+	if len(parts) == maxParts {
+		creds.SessionToken = parts[2]
+	}
+
+	return creds, nil
+}
+
+// IsEmpty returns whether the [Credentials] are not set.
+func (c Credentials) IsEmpty() bool {
+	return len(c.AccessKeyID) == 0 || len(c.SecretAccessKey) == 0
+}
+
+// Signer signs requests with the given [Credentials], for the given region and service.
+type Signer struct {
+	Region      string
+	Service     string
+	Credentials Credentials
+}
+
+// ParseScope parses the given string as a region:service pair (e.g. us-east-1:execute-api),
+// and returns a [Signer] for those, with no credentials set.
+func ParseScope(s string) (Signer, error) {
+	region, service, ok := strings.Cut(strings.TrimSpace(s), ":")
+	region, service = strings.TrimSpace(region), strings.TrimSpace(service)
+	if !ok || len(region) == 0 || len(service) == 0 || strings.ContainsAny(region+service, ":/ ") {
+		return Signer{}, ErrInvalidScope
+	}
+
+	return Signer{Region: strings.ToLower(region), Service: strings.ToLower(service)}, nil
+}
+
+// Header is a header name and value pair, set on the request once signed.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Sign signs the request made of the given method, host (i.e. from the url, only used when there's
+// no Host header), request target (path and query, as sent), headers and body, at the given time.
+//
+// It returns the headers that must be set on the request (replacing any existing one, regardless of
+// its casing), in order: [HeaderDate], [HeaderSecurityToken] (if any), [HeaderContentSHA256] (only
+// for S3) and [HeaderAuthorization]. All the given headers, but the ignored ones (e.g. User-Agent),
+// are signed along with those, so headers must not be modified once signed.
+func (s Signer) Sign(method, host, target string, headers map[string][]string, body []byte, t time.Time) []Header {
+	t = t.UTC()
+	amzDate := t.Format(amzDateFormat)
+	payloadHash := hashHex(body)
+
+	set := []Header{{Name: HeaderDate, Value: amzDate}}
+	if len(s.Credentials.SessionToken) > 0 {
+		set = append(set, Header{Name: HeaderSecurityToken, Value: s.Credentials.SessionToken})
+	}
+	if s.Service == serviceS3 {
+		set = append(set, Header{Name: HeaderContentSHA256, Value: payloadHash})
+	}
+
+	names, canonical := canonicalHeaders(host, headers, set)
+	signedHeaders := strings.Join(names, ";")
+
+	path, query, _ := strings.Cut(target, "?")
+	canonicalRequest := strings.Join([]string{
+		method,
+		s.canonicalURI(path),
+		canonicalQuery(query),
+		canonical,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{t.Format(shortDateFormat), s.Region, s.Service, terminator}, "/")
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.Credentials.SecretAccessKey), t.Format(shortDateFormat))
+	for _, part := range []string{s.Region, s.Service, terminator} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return append(set, Header{
+		Name: HeaderAuthorization,
+		Value: algorithm + " Credential=" + s.Credentials.AccessKeyID + "/" + scope +
+			", SignedHeaders=" + signedHeaders + ", Signature=" + signature,
+	})
+}
+
+// canonicalURI returns the canonical form of the given path, as sent. As the services,
+// but S3, decode and re-encode it once received, it is encoded again (i.e. twice).
+func (s Signer) canonicalURI(path string) string {
+	if len(path) == 0 {
+		return "/"
+	}
+
+	if s.Service != serviceS3 {
+		return escape(path, false)
+	}
+
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+
+	return escape(path, false)
+}
+
+// canonicalQuery returns the canonical form of the given query string, as sent:
+// each name and value encoded, and the pairs sorted by name and then value.
+func canonicalQuery(query string) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, strings.Count(query, "&")+1)
+	for _, param := range strings.Split(query, "&") {
+		if len(param) == 0 {
+			continue
+		}
+
+		name, value, _ := strings.Cut(param, "=")
+		pairs = append(pairs, escape(unescape(name), true)+"="+escape(unescape(value), true))
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders returns the (sorted, lowercase) names of the signed headers, and their
+// canonical form: one name:value per line, where the values of the same header (regardless
+// of its casing) are joined by commas, with the sequential spaces collapsed.
+func canonicalHeaders(host string, headers map[string][]string, set []Header) ([]string, string) {
+	values := make(map[string][]string, len(headers)+len(set)+1)
+	for name, vv := range headers {
+		lower := strings.ToLower(strings.TrimSpace(name))
+		if isIgnored(lower) || isSet(lower, set) {
+			continue
+		}
+		values[lower] = append(values[lower], vv...)
+	}
+
+	for _, h := range set {
+		values[strings.ToLower(h.Name)] = []string{h.Value}
+	}
+
+	if _, ok := values["host"]; !ok {
+		values["host"] = []string{host}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		vv := make([]string, 0, len(values[name]))
+		for _, v := range values[name] {
+			vv = append(vv, strings.Join(strings.Fields(v), " "))
+		}
+
+		b.WriteString(name + ":" + strings.Join(vv, ",") + "\n")
+	}
+
+	return names, b.String()
+}
+
+func isIgnored(name string) bool {
+	for _, ignored := range ignoredHeaders {
+		if name == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+func isSet(name string, set []Header) bool {
+	for _, h := range set {
+		if strings.EqualFold(name, h.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// escape encodes the given string as expected by SigV4 (RFC 3986), where all
+// the characters but the unreserved ones are encoded, including the slashes
+// only when encodeSlash is true (e.g. the query string).
+func escape(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hexChars[c>>4])
+		b.WriteByte(hexChars[c&0xf])
+	}
+
+	return b.String()
+}
+
+// unescape decodes the given query component (e.g. %20 or +), or returns it
+// as is when it is malformed, as it is sent (and likely received) that way.
+func unescape(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,156 @@
+package sigv4_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/sigv4"
+)
+
+func TestParseScope(t *testing.T) {
+	t.Parallel()
+
+	signer, err := sigv4.ParseScope(" us-east-1:Execute-API ")
+	require.NoError(t, err)
+	assert.Equal(t, sigv4.Signer{Region: "us-east-1", Service: "execute-api"}, signer)
+
+	for _, s := range []string{"", "us-east-1", "us-east-1:", ":s3", "us-east-1:s3:extra", "us east:s3"} {
+		_, err := sigv4.ParseScope(s)
+		require.ErrorIs(t, err, sigv4.ErrInvalidScope, s)
+	}
+}
+
+func TestParseCredentials(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		input    string
+		expected sigv4.Credentials
+		err      error
+	}{
+		"long-term":         {input: "AKID:secret", expected: sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}},
+		"temporary":         {input: "ASIA:secret:to:ken", expected: sigv4.Credentials{AccessKeyID: "ASIA", SecretAccessKey: "secret", SessionToken: "to:ken"}},
+		"without secret":    {input: "AKID", err: sigv4.ErrInvalidCredentials},
+		"with empty secret": {input: "AKID:", err: sigv4.ErrInvalidCredentials},
+		"with empty string": {input: "", err: sigv4.ErrInvalidCredentials},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			creds, err := sigv4.ParseCredentials(tc.input)
+			require.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, creds)
+		})
+	}
+}
+
+// The test cases below come from the AWS Signature Version 4 test suite.
+func TestSigner_Sign(t *testing.T) {
+	t.Parallel()
+
+	signer := sigv4.Signer{
+		Region:  "us-east-1",
+		Service: "service",
+		Credentials: sigv4.Credentials{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+	}
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	const credential = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "
+
+	tcs := map[string]struct {
+		method  string
+		target  string
+		headers map[string][]string
+		body    string
+		auth    string
+	}{
+		"get-vanilla": {
+			method:  "GET",
+			target:  "/",
+			headers: map[string][]string{"Host": {"example.amazonaws.com"}},
+			auth:    credential + "SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		"get-vanilla-query-order-key-case": {
+			method:  "GET",
+			target:  "/?Param2=value2&Param1=value1",
+			headers: map[string][]string{"Host": {"example.amazonaws.com"}},
+			auth:    credential + "SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		"post-vanilla": {
+			method:  "POST",
+			target:  "/",
+			headers: map[string][]string{"Host": {"example.amazonaws.com"}},
+			auth:    credential + "SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		"post-x-www-form-urlencoded": {
+			method: "POST",
+			target: "/",
+			headers: map[string][]string{
+				"Host":         {"example.amazonaws.com"},
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			},
+			body: "Param1=value1",
+			auth: credential + "SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			headers := signer.Sign(tc.method, "ignored.example.com", tc.target, tc.headers, []byte(tc.body), now)
+			assert.Equal(t, []sigv4.Header{
+				{Name: sigv4.HeaderDate, Value: "20150830T123600Z"},
+				{Name: sigv4.HeaderAuthorization, Value: tc.auth},
+			}, headers)
+		})
+	}
+}
+
+func TestSigner_Sign_Headers(t *testing.T) {
+	t.Parallel()
+
+	signer := sigv4.Signer{
+		Region:      "eu-west-1",
+		Service:     "s3",
+		Credentials: sigv4.Credentials{AccessKeyID: "ASIA", SecretAccessKey: "secret", SessionToken: "token"},
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	headers := signer.Sign("PUT", "bucket.s3.amazonaws.com", "/key", map[string][]string{
+		"User-Agent":    {"gbounty"},
+		"authorization": {"Bearer stale"},
+		"x-amz-date":    {"20000101T000000Z"},
+	}, []byte("body"), now)
+
+	require.Len(t, headers, 4)
+	assert.Equal(t, sigv4.Header{Name: sigv4.HeaderDate, Value: "20240102T030405Z"}, headers[0])
+	assert.Equal(t, sigv4.Header{Name: sigv4.HeaderSecurityToken, Value: "token"}, headers[1])
+	assert.Equal(t, sigv4.Header{Name: sigv4.HeaderContentSHA256, Value: "230d8358dc8e8890b4c58deeb62912ee2f20357ae92a5cc861b98e68fe31acb5"}, headers[2])
+	assert.Equal(t, sigv4.HeaderAuthorization, headers[3].Name)
+
+	// The host comes from the url (no Host header), and neither the User-Agent, nor
+	// the stale Authorization and X-Amz-Date headers (replaced once signed) are signed.
+	assert.Contains(t, headers[3].Value, "Credential=ASIA/20240102/eu-west-1/s3/aws4_request, ")
+	assert.Contains(t, headers[3].Value, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, ")
+
+	// The same request signed at the same time, has the same signature, while
+	// any change on the final bytes (e.g. an injected payload) changes it.
+	again := signer.Sign("PUT", "bucket.s3.amazonaws.com", "/key", nil, []byte("body"), now)
+	assert.Equal(t, headers[3], again[3])
+
+	injected := signer.Sign("PUT", "bucket.s3.amazonaws.com", "/key", nil, []byte("body'"), now)
+	assert.NotEqual(t, headers[3], injected[3])
+}
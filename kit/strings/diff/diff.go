@@ -0,0 +1,159 @@
+package diff
+
+import "strings"
+
+// Op represents the kind of operation of a diff [Line].
+type Op int
+
+const (
+	// Equal means the line is present on both sides.
+	Equal Op = iota
+	// Removed means the line is only present on the left side.
+	Removed
+	// Added means the line is only present on the right side.
+	Added
+	// Changed means the line on the left side was replaced
+	// by the one on the right side.
+	Changed
+)
+
+// Line represents a row of a side-by-side diff, with the
+// left (old) and right (new) values, and the operation.
+type Line struct {
+	Op    Op
+	Left  string
+	Right string
+}
+
+// maxCells is the maximum amount of cells (len(a)*len(b)) the LCS table
+// can have. Beyond that, lines are compared position by position.
+const maxCells = 4_000_000
+
+// Lines computes a line-based, side-by-side diff between a and b.
+//
+// It relies on the longest common subsequence (LCS) of both sets of lines,
+// and consecutive removals followed by additions are paired as [Changed].
+func Lines(a, b string) []Line {
+	left := strings.Split(strings.ReplaceAll(a, "\r\n", "\n"), "\n")
+	right := strings.Split(strings.ReplaceAll(b, "\r\n", "\n"), "\n")
+
+	if len(left)*len(right) > maxCells {
+		return positional(left, right)
+	}
+
+	return pair(lcs(left, right))
+}
+
+// AllEqual returns whether the diff has no differences at all.
+func AllEqual(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != Equal {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lcs(left, right []string) []Line {
+	n, m := len(left), len(right)
+
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case left[i] == right[j]:
+			lines = append(lines, Line{Op: Equal, Left: left[i], Right: right[j]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			lines = append(lines, Line{Op: Removed, Left: left[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Added, Right: right[j]})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		lines = append(lines, Line{Op: Removed, Left: left[i]})
+	}
+
+	for ; j < m; j++ {
+		lines = append(lines, Line{Op: Added, Right: right[j]})
+	}
+
+	return lines
+}
+
+// pair merges blocks of consecutive removals and additions
+// into [Changed] lines, so they are displayed side by side.
+func pair(lines []Line) []Line {
+	paired := make([]Line, 0, len(lines))
+
+	for i := 0; i < len(lines); {
+		if lines[i].Op != Removed {
+			paired = append(paired, lines[i])
+			i++
+			continue
+		}
+
+		var removed, added []string
+		for ; i < len(lines) && lines[i].Op == Removed; i++ {
+			removed = append(removed, lines[i].Left)
+		}
+		for ; i < len(lines) && lines[i].Op == Added; i++ {
+			added = append(added, lines[i].Right)
+		}
+
+		for k := 0; k < len(removed) || k < len(added); k++ {
+			switch {
+			case k < len(removed) && k < len(added):
+				paired = append(paired, Line{Op: Changed, Left: removed[k], Right: added[k]})
+			case k < len(removed):
+				paired = append(paired, Line{Op: Removed, Left: removed[k]})
+			default:
+				paired = append(paired, Line{Op: Added, Right: added[k]})
+			}
+		}
+	}
+
+	return paired
+}
+
+func positional(left, right []string) []Line {
+	lines := make([]Line, 0, len(left))
+
+	for k := 0; k < len(left) || k < len(right); k++ {
+		switch {
+		case k < len(left) && k < len(right) && left[k] == right[k]:
+			lines = append(lines, Line{Op: Equal, Left: left[k], Right: right[k]})
+		case k < len(left) && k < len(right):
+			lines = append(lines, Line{Op: Changed, Left: left[k], Right: right[k]})
+		case k < len(left):
+			lines = append(lines, Line{Op: Removed, Left: left[k]})
+		default:
+			lines = append(lines, Line{Op: Added, Right: right[k]})
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,58 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/strings/diff"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[string]struct {
+		a, b  string
+		exp   []diff.Line
+		equal bool
+	}{
+		"equal": {
+			a: "HTTP/1.1 200 OK\nServer: nginx",
+			b: "HTTP/1.1 200 OK\r\nServer: nginx",
+			exp: []diff.Line{
+				{Op: diff.Equal, Left: "HTTP/1.1 200 OK", Right: "HTTP/1.1 200 OK"},
+				{Op: diff.Equal, Left: "Server: nginx", Right: "Server: nginx"},
+			},
+			equal: true,
+		},
+		"changed": {
+			a: "HTTP/1.1 200 OK\nServer: nginx",
+			b: "HTTP/1.1 403 Forbidden\nServer: nginx",
+			exp: []diff.Line{
+				{Op: diff.Changed, Left: "HTTP/1.1 200 OK", Right: "HTTP/1.1 403 Forbidden"},
+				{Op: diff.Equal, Left: "Server: nginx", Right: "Server: nginx"},
+			},
+		},
+		"added and removed": {
+			a: "a\nb\nc",
+			b: "a\nc\nd",
+			exp: []diff.Line{
+				{Op: diff.Equal, Left: "a", Right: "a"},
+				{Op: diff.Removed, Left: "b"},
+				{Op: diff.Equal, Left: "c", Right: "c"},
+				{Op: diff.Added, Right: "d"},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := diff.Lines(tc.a, tc.b)
+			require.Equal(t, tc.exp, lines)
+			require.Equal(t, tc.equal, diff.AllEqual(lines))
+		})
+	}
+}
@@ -61,3 +61,18 @@ func FindRegexp(s, regex string) []Occurrence {
 
 	return positions
 }
+
+// ToLower returns a copy of the string with its ASCII letters lower-cased, while the rest
+// of bytes are kept as is. So, unlike [strings.ToLower], the positions found within the
+// returned string are also valid within the given one, as its byte length is preserved
+// (e.g. "İ" would be lower-cased into a longer sequence otherwise).
+func ToLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+
+	return string(b)
+}
@@ -57,3 +57,17 @@ func TestFindRegexp(t *testing.T) {
 		})
 	}
 }
+
+func TestToLower(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "", occurrence.ToLower(""))
+	require.Equal(t, "<script>alert(1)</script>", occurrence.ToLower("<SCRIPT>alert(1)</Script>"))
+
+	// Non-ASCII letters are kept as is, so the byte length is preserved.
+	s := "İstanbul <b>GBOUNTY</b>"
+	lower := occurrence.ToLower(s)
+	require.Equal(t, "İstanbul <b>gbounty</b>", lower)
+	require.Len(t, lower, len(s))
+	require.Equal(t, []occurrence.Occurrence{{13, 20}}, occurrence.Find(lower, "gbounty"))
+}
@@ -0,0 +1,264 @@
+package reflection
+
+import (
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// Context represents the (lightweight parsed) context
+// a reflection lands in, within an HTML document.
+type Context string
+
+const (
+	// ContextHTMLBody is used for reflections within the HTML text (i.e. between tags).
+	ContextHTMLBody Context = "html_body"
+	// ContextHTMLComment is used for reflections within an HTML comment.
+	ContextHTMLComment Context = "html_comment"
+	// ContextHTMLTag is used for reflections within a tag, but out of any attribute value
+	// (e.g. as the tag or the attribute name).
+	ContextHTMLTag Context = "html_tag"
+	// ContextHTMLAttribute is used for reflections within an attribute value.
+	ContextHTMLAttribute Context = "html_attribute"
+	// ContextURL is used for reflections within an attribute value that holds a URL
+	// (e.g. href, src, action).
+	ContextURL Context = "url"
+	// ContextJS is used for reflections within JavaScript code, but out of any string literal
+	// (e.g. within a script tag or an event handler attribute).
+	ContextJS Context = "js"
+	// ContextJSString is used for reflections within a JavaScript string literal.
+	ContextJSString Context = "js_string"
+)
+
+// Contexts returns the list of all the known [Context].
+func Contexts() []Context {
+	return []Context{
+		ContextHTMLBody,
+		ContextHTMLComment,
+		ContextHTMLTag,
+		ContextHTMLAttribute,
+		ContextURL,
+		ContextJS,
+		ContextJSString,
+	}
+}
+
+// Reflection represents a single reflection of the canary,
+// with its position (see [occurrence.Occurrence]) and its [Context].
+type Reflection struct {
+	occurrence.Occurrence
+	Context Context
+}
+
+// Analyze looks for all the reflections of the canary within the given document,
+// and classifies each one with the [Context] it lands in.
+//
+// The document is parsed with a lightweight, forgiving, single-pass tokenizer,
+// so it does not need to be a well-formed HTML document.
+func Analyze(doc, canary string) []Reflection {
+	occurrences := occurrence.Find(doc, canary)
+	if len(occurrences) == 0 {
+		return []Reflection{}
+	}
+
+	reflections := make([]Reflection, 0, len(occurrences))
+
+	t := tokenizer{doc: doc}
+	for _, occ := range occurrences {
+		t.advance(occ[0])
+		reflections = append(reflections, Reflection{Occurrence: occ, Context: t.context()})
+	}
+
+	return reflections
+}
+
+type state int
+
+const (
+	stateText state = iota
+	stateComment
+	stateTag
+	stateAttrValue
+	stateScript
+	stateScriptString
+)
+
+// tokenizer keeps track of the HTML (and JavaScript) state
+// while advancing through the document.
+type tokenizer struct {
+	doc   string
+	pos   int
+	state state
+
+	tag     string
+	attr    string
+	quote   byte
+	closing bool
+
+	// jsStart is the position where the current JavaScript
+	// code (i.e. a script tag or an event handler) starts.
+	jsStart int
+}
+
+// urlAttributes are those attributes whose values are (usually) URLs.
+var urlAttributes = map[string]struct{}{
+	"href": {}, "src": {}, "action": {}, "formaction": {}, "data": {},
+	"poster": {}, "background": {}, "cite": {}, "srcset": {}, "xlink:href": {},
+}
+
+func (t *tokenizer) context() Context {
+	switch t.state {
+	case stateComment:
+		return ContextHTMLComment
+	case stateTag:
+		return ContextHTMLTag
+	case stateAttrValue:
+		if strings.HasPrefix(t.attr, "on") {
+			return ContextJS
+		}
+		if _, ok := urlAttributes[t.attr]; ok {
+			return ContextURL
+		}
+		return ContextHTMLAttribute
+	case stateScript:
+		return ContextJS
+	case stateScriptString:
+		return ContextJSString
+	default:
+		return ContextHTMLBody
+	}
+}
+
+// inJS returns whether the tokenizer is within JavaScript code
+// (i.e. [ContextJS] or [ContextJSString]).
+func (t *tokenizer) inJS() bool {
+	switch t.state {
+	case stateScript, stateScriptString:
+		return true
+	case stateAttrValue:
+		return strings.HasPrefix(t.attr, "on")
+	default:
+		return false
+	}
+}
+
+// advance moves the tokenizer forward, up to the given position.
+func (t *tokenizer) advance(to int) {
+	for t.pos < to {
+		t.step()
+	}
+}
+
+//nolint:gocyclo
+func (t *tokenizer) step() {
+	c := t.doc[t.pos]
+
+	switch t.state {
+	case stateText:
+		switch {
+		case strings.HasPrefix(t.doc[t.pos:], "<!--"):
+			t.state = stateComment
+			t.pos += len("<!--")
+			return
+		case c == '<' && t.pos+1 < len(t.doc) && (isLetter(t.doc[t.pos+1]) || t.doc[t.pos+1] == '/'):
+			t.openTag()
+			return
+		}
+	case stateComment:
+		if strings.HasPrefix(t.doc[t.pos:], "-->") {
+			t.state = stateText
+			t.pos += len("-->")
+			return
+		}
+	case stateTag:
+		switch {
+		case c == '>':
+			t.state = stateText
+			if t.tag == "script" && !t.closing {
+				t.state = stateScript
+				t.jsStart = t.pos + 1
+			}
+		case c == '=':
+			t.pos++
+			t.openAttrValue()
+			return
+		case isSpace(c) || c == '/':
+			t.attr = ""
+		default:
+			t.attr += strings.ToLower(string(c))
+		}
+	case stateAttrValue:
+		if (t.quote != 0 && c == t.quote) || (t.quote == 0 && (isSpace(c) || c == '>')) {
+			t.state = stateTag
+			t.attr = ""
+			if t.quote == 0 {
+				// Let the tag state handle the closing character.
+				return
+			}
+		}
+	case stateScript:
+		switch {
+		case len(t.doc)-t.pos >= len("</script") && strings.EqualFold(t.doc[t.pos:t.pos+len("</script")], "</script"):
+			t.openTag()
+			return
+		case c == '"' || c == '\'' || c == '`':
+			t.state = stateScriptString
+			t.quote = c
+		}
+	case stateScriptString:
+		switch c {
+		case '\\':
+			t.pos++
+		case t.quote:
+			t.state = stateScript
+			t.quote = 0
+		}
+	}
+
+	t.pos++
+}
+
+// openTag consumes the opening of a tag (either opening or closing),
+// including its name, and moves the tokenizer into the tag state.
+func (t *tokenizer) openTag() {
+	t.pos++ // '<'
+	t.closing = false
+	if t.pos < len(t.doc) && t.doc[t.pos] == '/' {
+		t.closing = true
+		t.pos++
+	}
+
+	start := t.pos
+	for t.pos < len(t.doc) && !isSpace(t.doc[t.pos]) && t.doc[t.pos] != '>' && t.doc[t.pos] != '/' {
+		t.pos++
+	}
+
+	t.tag = strings.ToLower(t.doc[start:t.pos])
+	t.attr = ""
+	t.state = stateTag
+}
+
+// openAttrValue moves the tokenizer into the attribute value state,
+// consuming the opening quote, if any.
+func (t *tokenizer) openAttrValue() {
+	for t.pos < len(t.doc) && isSpace(t.doc[t.pos]) {
+		t.pos++
+	}
+
+	t.quote = 0
+	if t.pos < len(t.doc) && (t.doc[t.pos] == '"' || t.doc[t.pos] == '\'') {
+		t.quote = t.doc[t.pos]
+		t.pos++
+	}
+
+	t.state = stateAttrValue
+	t.jsStart = t.pos
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,149 @@
+package reflection_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bountysecurity/gbounty/kit/strings/reflection"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	const canary = "gb7x9q"
+
+	tcs := map[string]struct {
+		doc string
+		exp []reflection.Context
+	}{
+		"none": {
+			doc: `<html><body>Nothing here</body></html>`,
+			exp: []reflection.Context{},
+		},
+		"html body": {
+			doc: `<html><body><p>Hello gb7x9q</p></body></html>`,
+			exp: []reflection.Context{reflection.ContextHTMLBody},
+		},
+		"html comment": {
+			doc: `<div><!-- debug: gb7x9q --></div>`,
+			exp: []reflection.Context{reflection.ContextHTMLComment},
+		},
+		"html attribute": {
+			doc: `<input type="text" value="gb7x9q"><input value='gb7x9q'><input value=gb7x9q>`,
+			exp: []reflection.Context{reflection.ContextHTMLAttribute, reflection.ContextHTMLAttribute, reflection.ContextHTMLAttribute},
+		},
+		"html tag": {
+			doc: `<div gb7x9q="1">`,
+			exp: []reflection.Context{reflection.ContextHTMLTag},
+		},
+		"url": {
+			doc: `<a href="/search?q=gb7x9q">link</a><img src=gb7x9q.png>`,
+			exp: []reflection.Context{reflection.ContextURL, reflection.ContextURL},
+		},
+		"js": {
+			doc: `<script>var x = gb7x9q;</script><button onclick="go(gb7x9q)">`,
+			exp: []reflection.Context{reflection.ContextJS, reflection.ContextJS},
+		},
+		"js string": {
+			doc: `<script>var a = "x\"gb7x9q"; var b = 'gb7x9q'; var c = ` + "`gb7x9q`" + `;</script>`,
+			exp: []reflection.Context{reflection.ContextJSString, reflection.ContextJSString, reflection.ContextJSString},
+		},
+		"multiple": {
+			doc: `<title>gb7x9q</title><script>s = "gb7x9q";</script><a href="gb7x9q" title="gb7x9q">gb7x9q</a>`,
+			exp: []reflection.Context{
+				reflection.ContextHTMLBody,
+				reflection.ContextJSString,
+				reflection.ContextURL,
+				reflection.ContextHTMLAttribute,
+				reflection.ContextHTMLBody,
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reflections := reflection.Analyze(tc.doc, canary)
+
+			contexts := make([]reflection.Context, 0, len(reflections))
+			for _, r := range reflections {
+				require.Equal(t, canary, tc.doc[r.Occurrence[0]:r.Occurrence[1]])
+				contexts = append(contexts, r.Context)
+			}
+
+			require.Equal(t, tc.exp, contexts)
+		})
+	}
+}
+
+func TestAnalyzeSinks(t *testing.T) {
+	t.Parallel()
+
+	const canary = "gb7x9q"
+
+	type sink struct {
+		sink reflection.Sink
+		code string
+	}
+
+	tcs := map[string]struct {
+		doc string
+		exp []sink
+	}{
+		"none": {
+			doc: `<p>gb7x9q</p><script>var x = "gb7x9q";</script>`,
+			exp: []sink{},
+		},
+		"inner html": {
+			doc: `<script>el.innerHTML = "<b>" + "gb7x9q" + "</b>"; foo();</script>`,
+			exp: []sink{{sink: reflection.SinkInnerHTML, code: `.innerHTML = "<b>" + "gb7x9q" + "</b>";`}},
+		},
+		"document write": {
+			doc: "<script>\ndocument.write('<img src=x?q=gb7x9q>')\nvar y = 1;</script>",
+			exp: []sink{{sink: reflection.SinkDocumentWrite, code: `document.write('<img src=x?q=gb7x9q>')`}},
+		},
+		"location in event handler": {
+			doc: `<button onclick="location = '/next?to=gb7x9q'">Go</button>`,
+			exp: []sink{{sink: reflection.SinkLocation, code: `location = '/next?to=gb7x9q'`}},
+		},
+		"closest sink": {
+			doc: `<script>eval(x); location.href = "gb7x9q"; setTimeout(gb7x9q)</script>`,
+			exp: []sink{
+				{sink: reflection.SinkLocation, code: `location.href = "gb7x9q";`},
+				{sink: reflection.SinkSetTimeout, code: `setTimeout(gb7x9q)`},
+			},
+		},
+		"sink in a previous statement": {
+			doc: `<script>el.innerHTML = "a;b"; var x = "gb7x9q";</script>`,
+			exp: []sink{},
+		},
+		"sink out of script": {
+			doc: `<p>el.innerHTML = gb7x9q</p><input value="eval(gb7x9q)">`,
+			exp: []sink{},
+		},
+		"comparison is not an assignment": {
+			doc: `<script>if (location == "gb7x9q") {}</script>`,
+			exp: []sink{},
+		},
+	}
+
+	for name, tc := range tcs {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reflections := reflection.AnalyzeSinks(tc.doc, canary)
+
+			sinks := make([]sink, 0, len(reflections))
+			for _, r := range reflections {
+				require.Equal(t, canary, tc.doc[r.Occurrence[0]:r.Occurrence[1]])
+				sinks = append(sinks, sink{sink: r.Sink, code: tc.doc[r.Code[0]:r.Code[1]]})
+			}
+
+			require.Equal(t, tc.exp, sinks)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package reflection
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bountysecurity/gbounty/kit/strings/occurrence"
+)
+
+// Sink represents a DOM XSS sink, that is: a JavaScript property or function
+// that, when given attacker-controlled data, might lead to code execution.
+type Sink string
+
+const (
+	// SinkInnerHTML is used for assignments to the innerHTML property.
+	SinkInnerHTML Sink = "innerHTML"
+	// SinkOuterHTML is used for assignments to the outerHTML property.
+	SinkOuterHTML Sink = "outerHTML"
+	// SinkInsertAdjacentHTML is used for calls to the insertAdjacentHTML function.
+	SinkInsertAdjacentHTML Sink = "insertAdjacentHTML"
+	// SinkDocumentWrite is used for calls to the document.write (and document.writeln) functions.
+	SinkDocumentWrite Sink = "document.write"
+	// SinkEval is used for calls to the eval function.
+	SinkEval Sink = "eval"
+	// SinkSetTimeout is used for calls to the setTimeout function.
+	SinkSetTimeout Sink = "setTimeout"
+	// SinkSetInterval is used for calls to the setInterval function.
+	SinkSetInterval Sink = "setInterval"
+	// SinkFunction is used for calls to the Function constructor (i.e. new Function).
+	SinkFunction Sink = "Function"
+	// SinkLocation is used for assignments to the location (or location.href) property,
+	// as well as for calls to the location.assign and location.replace functions.
+	SinkLocation Sink = "location"
+)
+
+// Sinks returns the list of all the known [Sink].
+func Sinks() []Sink {
+	return []Sink{
+		SinkInnerHTML,
+		SinkOuterHTML,
+		SinkInsertAdjacentHTML,
+		SinkDocumentWrite,
+		SinkEval,
+		SinkSetTimeout,
+		SinkSetInterval,
+		SinkFunction,
+		SinkLocation,
+	}
+}
+
+// sinkPatterns are the patterns used to locate each [Sink] within JavaScript code.
+// These are case-insensitive, as documents might be lower-cased (e.g. to look for
+// case-insensitive reflections).
+var sinkPatterns = []struct {
+	sink Sink
+	re   *regexp.Regexp
+}{
+	{sink: SinkInnerHTML, re: regexp.MustCompile(`(?i)\.innerHTML\s*\+?=`)},
+	{sink: SinkOuterHTML, re: regexp.MustCompile(`(?i)\.outerHTML\s*\+?=`)},
+	{sink: SinkInsertAdjacentHTML, re: regexp.MustCompile(`(?i)\.insertAdjacentHTML\s*\(`)},
+	{sink: SinkDocumentWrite, re: regexp.MustCompile(`(?i)\bdocument\.write(?:ln)?\s*\(`)},
+	{sink: SinkEval, re: regexp.MustCompile(`(?i)\beval\s*\(`)},
+	{sink: SinkSetTimeout, re: regexp.MustCompile(`(?i)\bsetTimeout\s*\(`)},
+	{sink: SinkSetInterval, re: regexp.MustCompile(`(?i)\bsetInterval\s*\(`)},
+	{sink: SinkFunction, re: regexp.MustCompile(`(?i)\bnew\s+Function\s*\(`)},
+	{sink: SinkLocation, re: regexp.MustCompile(`(?i)\blocation(?:\.href)?\s*=[^=]|\blocation\.(?:assign|replace)\s*\(`)},
+}
+
+// maxStatementLen is the maximum length of the code looked (backwards and forwards)
+// around each reflection, so large (e.g. minified) scripts are never fully included.
+const maxStatementLen = 512
+
+// SinkReflection represents a single reflection of the canary within JavaScript
+// code (see [ContextJS] and [ContextJSString]), that flows into a [Sink]. The Code
+// is the position of the surrounding code, from the sink to the end of the statement.
+type SinkReflection struct {
+	Reflection
+	Sink Sink
+	Code occurrence.Occurrence
+}
+
+// AnalyzeSinks looks for all the reflections of the canary within JavaScript code
+// (i.e. within a script tag or an event handler attribute) of the given document,
+// and returns those that land in the same statement as a [Sink] (e.g. innerHTML),
+// after it. If there's more than one sink, the closest one to the reflection is used.
+//
+// Like [Analyze], the document is parsed with a lightweight, forgiving, single-pass
+// tokenizer, and statements are delimited by semicolons and braces (out of string
+// literals), so it's a heuristic, not a JavaScript parser.
+func AnalyzeSinks(doc, canary string) []SinkReflection {
+	occurrences := occurrence.Find(doc, canary)
+	if len(occurrences) == 0 {
+		return []SinkReflection{}
+	}
+
+	sinks := make([]SinkReflection, 0)
+
+	t := tokenizer{doc: doc}
+	for _, occ := range occurrences {
+		t.advance(occ[0])
+		if !t.inJS() {
+			continue
+		}
+
+		start := t.jsStart + statementStart(doc[t.jsStart:occ[0]])
+		if occ[0]-start > maxStatementLen {
+			start = occ[0] - maxStatementLen
+		}
+
+		sink, at, found := lastSink(doc[start:occ[0]])
+		if !found {
+			continue
+		}
+
+		sinks = append(sinks, SinkReflection{
+			Reflection: Reflection{Occurrence: occ, Context: t.context()},
+			Sink:       sink,
+			Code:       occurrence.Occurrence{start + at, t.statementEnd(occ[1])},
+		})
+	}
+
+	return sinks
+}
+
+// statementStart returns the position where the last statement of the given
+// JavaScript code starts, that is: right after the last semicolon or brace
+// out of any string literal, or zero if there's none.
+func statementStart(code string) int {
+	var (
+		start int
+		quote byte
+	)
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case ';', '{', '}':
+			start = i + 1
+		}
+	}
+
+	return start
+}
+
+// statementEnd returns the position where the statement that contains the given
+// position ends (including the semicolon, if any), within the current JavaScript
+// code (i.e. up to the closing script tag, or the closing attribute quote).
+func (t *tokenizer) statementEnd(from int) int {
+	rest := t.doc[from:]
+	if len(rest) > maxStatementLen {
+		rest = rest[:maxStatementLen]
+	}
+
+	switch {
+	case t.state == stateAttrValue && t.quote != 0:
+		if idx := strings.IndexByte(rest, t.quote); idx >= 0 {
+			rest = rest[:idx]
+		}
+	case t.state == stateAttrValue:
+		if idx := strings.IndexAny(rest, " \t\r\n\f>"); idx >= 0 {
+			rest = rest[:idx]
+		}
+	default:
+		if idx := strings.Index(strings.ToLower(rest), "</script"); idx >= 0 {
+			rest = rest[:idx]
+		}
+	}
+
+	if idx := strings.IndexAny(rest, ";\n"); idx >= 0 {
+		if rest[idx] == ';' {
+			idx++
+		}
+		rest = rest[:idx]
+	}
+
+	return from + len(strings.TrimRight(rest, " \t\r\n"))
+}
+
+// lastSink returns the [Sink] found the latest within the given code,
+// along with its position, if any.
+func lastSink(code string) (Sink, int, bool) {
+	var (
+		sink  Sink
+		at    = -1
+		found bool
+	)
+
+	for _, p := range sinkPatterns {
+		for _, loc := range p.re.FindAllStringIndex(code, -1) {
+			if loc[0] > at {
+				sink, at, found = p.sink, loc[0], true
+			}
+		}
+	}
+
+	return sink, at, found
+}